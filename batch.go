@@ -0,0 +1,279 @@
+package genji
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// defaultBatchFlushInterval is the maximum time a write waits for its batch
+// to be full, unless set with WithBatchFlushInterval.
+const defaultBatchFlushInterval = 10 * time.Millisecond
+
+// errBatchWriterClosed is returned by the writes queued after the database was closed.
+var errBatchWriterClosed = errors.New("batch writer closed")
+
+// BatchWriterStats holds the statistics of the batch writer.
+type BatchWriterStats struct {
+	// Batches is the number of transactions run by the batch writer.
+	Batches int
+	// Writes is the number of writes that were committed.
+	Writes int
+}
+
+// WithBatchWriter makes BatchExec group the queued writes in batches of up to batchSize writes,
+// each batch running in a single transaction. A batch is run once it is full, or once
+// the flush interval expired after its first write, see WithBatchFlushInterval.
+// The batch writer is stopped by Close, after running the pending writes.
+func WithBatchWriter(batchSize int) Option {
+	return func(db *DB) error {
+		if batchSize < 1 {
+			return errors.New("batch size must be greater than 0")
+		}
+
+		db.batch = &batchWriter{
+			db:     db,
+			size:   batchSize,
+			writes: make(chan *batchWrite, batchSize),
+			stop:   make(chan struct{}),
+			done:   make(chan struct{}),
+		}
+		return nil
+	}
+}
+
+// WithBatchFlushInterval sets the maximum time a write queued with BatchExec
+// waits for its batch to be full before the batch is run. Defaults to 10ms.
+func WithBatchFlushInterval(d time.Duration) Option {
+	return func(db *DB) error {
+		if d <= 0 {
+			return errors.New("batch flush interval must be positive")
+		}
+
+		db.batchFlushInterval = d
+		return nil
+	}
+}
+
+// BatchExec queues a write query and returns immediately. If the batch writer is enabled
+// with WithBatchWriter, the query runs in the same transaction as other queued writes,
+// otherwise it is run immediately in its own transaction.
+// The returned WriteResult is resolved once the transaction was committed, or once the query failed.
+// A query that fails doesn't prevent the other writes of its batch from being committed.
+func (db *DB) BatchExec(q string, args ...interface{}) *WriteResult {
+	res := WriteResult{done: make(chan struct{})}
+
+	if db.batch == nil {
+		res.resolve(db.Exec(q, args...))
+		return &res
+	}
+
+	pq, err := parser.ParseQuery(q)
+	if err != nil {
+		res.resolve(err)
+		return &res
+	}
+
+	err = db.batch.send(&batchWrite{q: pq, params: argsToParams(args), res: &res})
+	if err != nil {
+		res.resolve(err)
+	}
+
+	return &res
+}
+
+// BatchWriterStats returns the statistics of the batch writer enabled with WithBatchWriter.
+// If it is disabled, it returns empty statistics.
+func (db *DB) BatchWriterStats() BatchWriterStats {
+	if db.batch == nil {
+		return BatchWriterStats{}
+	}
+
+	db.batch.statsMu.Lock()
+	defer db.batch.statsMu.Unlock()
+
+	return db.batch.stats
+}
+
+// A WriteResult is the result of a write queued with BatchExec.
+type WriteResult struct {
+	done chan struct{}
+	err  error
+}
+
+// Done returns a channel that is closed once the write is committed or failed.
+func (r *WriteResult) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait until the write is committed or failed, and return its error.
+func (r *WriteResult) Wait() error {
+	<-r.done
+	return r.err
+}
+
+func (r *WriteResult) resolve(err error) {
+	r.err = err
+	close(r.done)
+}
+
+type batchWrite struct {
+	q      query.Query
+	params []expr.Param
+	res    *WriteResult
+}
+
+type batchWriter struct {
+	db     *DB
+	size   int
+	writes chan *batchWrite
+	stop   chan struct{}
+	done   chan struct{}
+	start  sync.Once
+
+	// protects closed and started.
+	// writes are sent while holding a read lock.
+	mu      sync.RWMutex
+	closed  bool
+	started bool
+
+	statsMu sync.Mutex
+	stats   BatchWriterStats
+}
+
+// send queues w, starting the batch writer on the first call.
+func (b *batchWriter) send(w *batchWrite) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return errBatchWriterClosed
+	}
+
+	b.start.Do(func() {
+		interval := b.db.batchFlushInterval
+		if interval == 0 {
+			interval = defaultBatchFlushInterval
+		}
+
+		b.started = true
+		go b.run(interval)
+	})
+
+	b.writes <- w
+	return nil
+}
+
+func (b *batchWriter) run(interval time.Duration) {
+	defer close(b.done)
+
+	var batch []*batchWrite
+	var timer *time.Timer
+	var timeout <-chan time.Time
+
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, timeout = nil, nil
+		}
+
+		b.flush(batch)
+		batch = nil
+	}
+
+	for {
+		select {
+		case w := <-b.writes:
+			batch = append(batch, w)
+			if len(batch) >= b.size {
+				flush()
+			} else if timer == nil {
+				timer = time.NewTimer(interval)
+				timeout = timer.C
+			}
+		case <-timeout:
+			flush()
+		case <-b.stop:
+			// no write can be sent anymore,
+			// run the ones left in the channel.
+			for {
+				select {
+				case w := <-b.writes:
+					batch = append(batch, w)
+					if len(batch) >= b.size {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush runs the batch in a single transaction. If a query fails, its write is resolved
+// with the error and the transaction is run again without it.
+// If the commit fails, all the writes are resolved with the error.
+func (b *batchWriter) flush(batch []*batchWrite) {
+	for len(batch) > 0 {
+		failed := -1
+
+		err := b.db.Update(func(tx *Tx) error {
+			failed = -1
+			for i, w := range batch {
+				res, err := w.q.Exec(tx.Transaction, w.params)
+				if err == nil {
+					err = res.Close()
+				}
+				if err != nil {
+					failed = i
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		b.statsMu.Lock()
+		b.stats.Batches++
+		if err == nil {
+			b.stats.Writes += len(batch)
+		}
+		b.statsMu.Unlock()
+
+		if failed >= 0 {
+			batch[failed].res.resolve(err)
+			batch = append(batch[:failed:failed], batch[failed+1:]...)
+			continue
+		}
+
+		for _, w := range batch {
+			w.res.resolve(err)
+		}
+		return
+	}
+}
+
+// close stops the batch writer once the queued writes are committed.
+func (b *batchWriter) close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	started := b.started
+	b.mu.Unlock()
+
+	if !started {
+		return
+	}
+
+	close(b.stop)
+	<-b.done
+}
@@ -0,0 +1,193 @@
+package genji_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/stretchr/testify/require"
+)
+
+// failingEngine fails the commits of its transactions while fail is set.
+type failingEngine struct {
+	engine.Engine
+
+	fail int32
+}
+
+func (ng *failingEngine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	tx, err := ng.Engine.Begin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &failingTransaction{Transaction: tx, ng: ng}, nil
+}
+
+type failingTransaction struct {
+	engine.Transaction
+
+	ng *failingEngine
+}
+
+var errCommit = errors.New("commit failed")
+
+func (tx *failingTransaction) Commit() error {
+	if atomic.LoadInt32(&tx.ng.fail) == 1 {
+		tx.Transaction.Rollback()
+		return errCommit
+	}
+
+	return tx.Transaction.Commit()
+}
+
+func countDocuments(t *testing.T, db *genji.DB) int {
+	d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM test")
+	require.NoError(t, err)
+
+	var n int
+	require.NoError(t, document.Scan(d, &n))
+	return n
+}
+
+func TestBatchWriter(t *testing.T) {
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := genji.Open(":memory:", genji.WithBatchWriter(0))
+		require.Error(t, err)
+
+		_, err = genji.Open(":memory:", genji.WithBatchWriter(10), genji.WithBatchFlushInterval(0))
+		require.Error(t, err)
+	})
+
+	t.Run("Concurrent writes", func(t *testing.T) {
+		db, err := genji.Open(":memory:", genji.WithBatchWriter(100), genji.WithBatchFlushInterval(time.Minute))
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test")
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 1000)
+		for i := 0; i < 1000; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- db.BatchExec("INSERT INTO test (a) VALUES (?)", i).Wait()
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, 1000, countDocuments(t, db))
+		require.Equal(t, genji.BatchWriterStats{Batches: 10, Writes: 1000}, db.BatchWriterStats())
+	})
+
+	t.Run("Failing write", func(t *testing.T) {
+		db, err := genji.Open(":memory:", genji.WithBatchWriter(3), genji.WithBatchFlushInterval(time.Minute))
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test(a INTEGER NOT NULL)")
+		require.NoError(t, err)
+
+		r1 := db.BatchExec("INSERT INTO test (a) VALUES (1)")
+		r2 := db.BatchExec("INSERT INTO test (b) VALUES (2)")
+		r3 := db.BatchExec("INSERT INTO test (a) VALUES (3)")
+
+		require.NoError(t, r1.Wait())
+		var cerr *database.ConstraintViolationError
+		require.True(t, errors.As(r2.Wait(), &cerr))
+		require.NoError(t, r3.Wait())
+
+		require.Equal(t, 2, countDocuments(t, db))
+		require.Equal(t, 2, db.BatchWriterStats().Writes)
+	})
+
+	t.Run("Commit error", func(t *testing.T) {
+		ng := failingEngine{Engine: memoryengine.NewEngine()}
+		db, err := genji.New(context.Background(), &ng, genji.WithBatchWriter(2), genji.WithBatchFlushInterval(time.Minute))
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test")
+		require.NoError(t, err)
+
+		atomic.StoreInt32(&ng.fail, 1)
+		r1 := db.BatchExec("INSERT INTO test (a) VALUES (1)")
+		r2 := db.BatchExec("INSERT INTO test (a) VALUES (2)")
+		require.Equal(t, errCommit, r1.Wait())
+		require.Equal(t, errCommit, r2.Wait())
+		atomic.StoreInt32(&ng.fail, 0)
+
+		require.Equal(t, 0, countDocuments(t, db))
+	})
+
+	t.Run("Flush interval", func(t *testing.T) {
+		db, err := genji.Open(":memory:", genji.WithBatchWriter(100), genji.WithBatchFlushInterval(10*time.Millisecond))
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test")
+		require.NoError(t, err)
+
+		results := []*genji.WriteResult{
+			db.BatchExec("INSERT INTO test (a) VALUES (1)"),
+			db.BatchExec("INSERT INTO test (a) VALUES (2)"),
+			db.BatchExec("INSERT INTO test (a) VALUES (3)"),
+		}
+
+		for _, r := range results {
+			select {
+			case <-r.Done():
+				require.NoError(t, r.Wait())
+			case <-time.After(5 * time.Second):
+				t.Fatal("batch not flushed")
+			}
+		}
+
+		require.Equal(t, 3, countDocuments(t, db))
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		db, err := genji.Open(":memory:", genji.WithBatchWriter(100), genji.WithBatchFlushInterval(time.Hour))
+		require.NoError(t, err)
+
+		err = db.Exec("CREATE TABLE test")
+		require.NoError(t, err)
+
+		r := db.BatchExec("INSERT INTO test (a) VALUES (1)")
+
+		err = db.Close()
+		require.NoError(t, err)
+		require.NoError(t, r.Wait())
+
+		require.Error(t, db.BatchExec("INSERT INTO test (a) VALUES (2)").Wait())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test")
+		require.NoError(t, err)
+
+		require.NoError(t, db.BatchExec("INSERT INTO test (a) VALUES (1)").Wait())
+		require.Error(t, db.BatchExec("INSERT INTO unknown (a) VALUES (1)").Wait())
+		require.Equal(t, 1, countDocuments(t, db))
+		require.Zero(t, db.BatchWriterStats())
+	})
+}
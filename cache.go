@@ -0,0 +1,34 @@
+package genji
+
+import (
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/cachedengine"
+)
+
+// WithPageCache keeps up to size values read from the engine in an LRU cache,
+// shared by all the transactions.
+// Writes go directly to the engine and the values they modify are removed
+// from the cache once their transaction is committed.
+func WithPageCache(size int) Option {
+	return func(db *DB) error {
+		return db.DB.WrapEngine(func(ng engine.Engine) (engine.Engine, error) {
+			c, err := cachedengine.NewEngine(ng, size)
+			if err != nil {
+				return nil, err
+			}
+
+			db.cache = c
+			return c, nil
+		})
+	}
+}
+
+// CacheStats returns the statistics of the cache enabled with WithPageCache.
+// If the cache is disabled, it returns empty statistics.
+func (db *DB) CacheStats() cachedengine.Stats {
+	if db.cache == nil {
+		return cachedengine.Stats{}
+	}
+
+	return db.cache.Stats()
+}
@@ -0,0 +1,111 @@
+package genji_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine/cachedengine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageCache(t *testing.T) {
+	t.Run("Invalid size", func(t *testing.T) {
+		_, err := genji.Open(":memory:", genji.WithPageCache(0))
+		require.Error(t, err)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		require.Zero(t, db.CacheStats())
+	})
+
+	newDB := func(t *testing.T, size int) *genji.DB {
+		db, err := genji.Open(":memory:", genji.WithPageCache(size))
+		require.NoError(t, err)
+
+		err = db.Exec("CREATE TABLE test")
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			err = db.Exec("INSERT INTO test (a) VALUES (?)", i)
+			require.NoError(t, err)
+		}
+
+		return db
+	}
+
+	// readAll reads every document of the test table n times using its key,
+	// and returns the difference of the cache statistics.
+	readAll := func(t *testing.T, db *genji.DB, n int) (diff cachedengine.Stats) {
+		err := db.View(func(tx *genji.Tx) error {
+			tb, err := tx.GetTable("test")
+			require.NoError(t, err)
+
+			var keys [][]byte
+			err = tb.Iterate(func(d document.Document) error {
+				keys = append(keys, append([]byte(nil), d.(document.Keyer).Key()...))
+				return nil
+			})
+			require.NoError(t, err)
+			require.Len(t, keys, 10)
+
+			before := db.CacheStats()
+			for i := 0; i < n; i++ {
+				for _, k := range keys {
+					_, err := tb.GetDocument(k)
+					require.NoError(t, err)
+				}
+			}
+			after := db.CacheStats()
+
+			diff.Hits = after.Hits - before.Hits
+			diff.Misses = after.Misses - before.Misses
+			diff.Evictions = after.Evictions - before.Evictions
+			return nil
+		})
+		require.NoError(t, err)
+		return diff
+	}
+
+	t.Run("Hits", func(t *testing.T) {
+		db := newDB(t, 100)
+		defer db.Close()
+
+		diff := readAll(t, db, 100)
+		require.Equal(t, cachedengine.Stats{Hits: 990, Misses: 10}, diff)
+	})
+
+	t.Run("Eviction", func(t *testing.T) {
+		db := newDB(t, 5)
+		defer db.Close()
+
+		// documents are always read in the same order,
+		// so each one is evicted before being read again.
+		diff := readAll(t, db, 2)
+		require.Zero(t, diff.Hits)
+		require.Equal(t, uint64(20), diff.Misses)
+		require.GreaterOrEqual(t, diff.Evictions, uint64(15))
+	})
+
+	t.Run("Invalidation", func(t *testing.T) {
+		db := newDB(t, 100)
+		defer db.Close()
+
+		readAll(t, db, 1)
+
+		err := db.Exec("UPDATE test SET a = a + 100")
+		require.NoError(t, err)
+
+		diff := readAll(t, db, 1)
+		require.Equal(t, uint64(10), diff.Misses)
+
+		d, err := db.QueryDocument("SELECT MIN(a) AS a FROM test")
+		require.NoError(t, err)
+		v, err := d.GetByField("a")
+		require.NoError(t, err)
+		require.EqualValues(t, 100, v.V)
+	})
+}
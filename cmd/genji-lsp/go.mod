@@ -0,0 +1,10 @@
+module github.com/genjidb/genji/cmd/genji-lsp
+
+go 1.15
+
+require (
+	github.com/genjidb/genji v0.10.0
+	github.com/stretchr/testify v1.6.1
+)
+
+replace github.com/genjidb/genji v0.10.0 => ../../
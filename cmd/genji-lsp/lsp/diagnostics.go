@@ -0,0 +1,56 @@
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+// Diagnostics parses text and returns the syntax error it contains, if any.
+// The parser stops at the first error, so at most one diagnostic is returned.
+func Diagnostics(text string) []Diagnostic {
+	diags := []Diagnostic{}
+
+	_, err := parser.ParseQuery(text)
+	if err == nil {
+		return diags
+	}
+
+	d := Diagnostic{
+		Severity: SeverityError,
+		Source:   "genji",
+		Message:  err.Error(),
+	}
+
+	var perr *parser.ParseError
+	if errors.As(err, &perr) {
+		d.Message = perr.Message
+		if d.Message == "" {
+			d.Message = fmt.Sprintf("found %s, expected %s", perr.Found, strings.Join(perr.Expected, ", "))
+		}
+
+		// the error covers the token found at its position, if any
+		start := Position{Line: perr.Pos.Line, Character: perr.Pos.Char}
+		d.Range = Range{Start: start, End: start}
+		tokens, _ := scanner.Tokenise(text)
+		for _, ti := range tokens {
+			if ti.Pos == perr.Pos {
+				d.Range = tokenRange(ti)
+				break
+			}
+		}
+	} else {
+		// errors without position are reported on the first line
+		line := text
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			line = text[:i]
+		}
+		d.Range.End.Character = utf8.RuneCountInString(line)
+	}
+
+	return append(diags, d)
+}
@@ -0,0 +1,44 @@
+package lsp_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/cmd/genji-lsp/lsp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnostics(t *testing.T) {
+	rng := func(line, start, end int) lsp.Range {
+		return lsp.Range{
+			Start: lsp.Position{Line: line, Character: start},
+			End:   lsp.Position{Line: line, Character: end},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected []lsp.Diagnostic
+	}{
+		{"empty", "", []lsp.Diagnostic{}},
+		{"valid", "CREATE TABLE foo;\nSELECT a FROM foo WHERE b > 10", []lsp.Diagnostic{}},
+		{"unexpected token", "SELECT a FROM foo WHERE b >", []lsp.Diagnostic{
+			{Range: rng(0, 27, 27), Severity: lsp.SeverityError, Source: "genji", Message: "found EOF, expected identifier, string, number, bool"},
+		}},
+		{"second line", "SELECT a FROM foo;\nSELEC b FROM foo", []lsp.Diagnostic{
+			{Range: rng(1, 0, 5), Severity: lsp.SeverityError, Source: "genji", Message: "found SELEC, expected ALTER, BEGIN, COMMIT, SELECT, DELETE, DESCRIBE, UPDATE, INSERT, CREATE, DROP, EXPLAIN, REINDEX, ROLLBACK, SHOW, TRUNCATE, WITH, VALUES"},
+		}},
+		{"message", "INSERT INTO foo VALUES {a: 1, null: 2}", []lsp.Diagnostic{
+			{Range: rng(0, 30, 34), Severity: lsp.SeverityError, Source: "genji", Message: "NULL is a reserved word: quote it with backquotes to use it as a field name"},
+		}},
+		{"string", "SELECT a FROM foo WHERE b = 'foo' 'bar'", []lsp.Diagnostic{
+			{Range: rng(0, 34, 39), Severity: lsp.SeverityError, Source: "genji", Message: "found bar, expected ;"},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, lsp.Diagnostics(test.text))
+		})
+	}
+}
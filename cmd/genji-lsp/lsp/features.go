@@ -0,0 +1,165 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+// hover describes the table or the fields referenced by the identifier found at pos.
+func (s *Server) hover(text string, pos Position) *Hover {
+	names, r, ok := pathAt(text, pos)
+	if !ok {
+		return nil
+	}
+
+	var parts []string
+	if len(names) == 1 {
+		if t := s.schema.Table(names[0]); t != nil {
+			parts = append(parts, describeTable(t))
+		}
+	}
+
+	for _, ref := range s.schema.lookupField(names) {
+		parts = append(parts, fmt.Sprintf("```sql\n%s\n```\nfield of table `%s`", ref.field, ref.table.Name))
+	}
+
+	if len(parts) == 0 {
+		return nil
+	}
+
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: strings.Join(parts, "\n\n---\n\n")},
+		Range:    &r,
+	}
+}
+
+func describeTable(t *Table) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "```sql\nTABLE %s", t.Name)
+	if len(t.Fields) > 0 {
+		sb.WriteString(" (")
+		for i := range t.Fields {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString("\n    " + t.Fields[i].String())
+		}
+		sb.WriteString("\n)")
+	}
+	sb.WriteString("\n```")
+
+	return sb.String()
+}
+
+// definition returns the location of the CREATE TABLE statement of the table whose
+// name is found at pos. Tables created by the document itself take precedence over
+// the ones of the schema.
+func (s *Server) definition(uri, text string, pos Position) []Location {
+	names, _, ok := pathAt(text, pos)
+	if !ok || len(names) != 1 {
+		return nil
+	}
+
+	if loc, ok := definitions(uri, text)[names[0]]; ok {
+		return []Location{loc}
+	}
+
+	if t := s.schema.Table(names[0]); t != nil && t.Definition != nil {
+		return []Location{*t.Definition}
+	}
+
+	return nil
+}
+
+// completion suggests the fields nested under the path preceding pos if the identifier
+// being typed follows a dot, or the tables, their top-level fields and the keywords otherwise.
+func (s *Server) completion(text string, pos Position) *CompletionList {
+	items := []CompletionItem{}
+
+	prefix := strings.TrimRightFunc(linePrefix(text, pos), isIdentChar)
+
+	if strings.HasSuffix(prefix, ".") {
+		prefix = strings.TrimSuffix(prefix, ".")
+		i := strings.LastIndexFunc(prefix, func(r rune) bool {
+			return !isIdentChar(r) && r != '.'
+		})
+		path := strings.Trim(prefix[i+1:], ".")
+
+		if path != "" {
+			for _, name := range s.schema.subfields(strings.Split(path, ".")) {
+				items = append(items, CompletionItem{Label: name, Kind: CompletionField})
+			}
+		}
+
+		return &CompletionList{Items: items}
+	}
+
+	for _, t := range s.schema.Tables {
+		items = append(items, CompletionItem{Label: t.Name, Kind: CompletionClass, Detail: "table"})
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range s.schema.Tables {
+		for _, f := range t.Fields {
+			name := f.Path[0].FieldName
+			if !seen[name] {
+				seen[name] = true
+				items = append(items, CompletionItem{Label: name, Kind: CompletionField, Detail: "field of " + t.Name})
+			}
+		}
+	}
+
+	for _, kw := range scanner.Keywords() {
+		items = append(items, CompletionItem{Label: kw, Kind: CompletionKeyword})
+	}
+
+	return &CompletionList{Items: items}
+}
+
+// pathAt returns the field names of the path whose last name is the identifier found at pos,
+// along with the range of that identifier. Names following that identifier are ignored.
+func pathAt(text string, pos Position) ([]string, Range, bool) {
+	tokens, _ := scanner.Tokenise(text)
+
+	for i, ti := range tokens {
+		if ti.Tok != scanner.IDENT {
+			continue
+		}
+
+		r := tokenRange(ti)
+		if r.Start.Line != pos.Line || pos.Character < r.Start.Character || pos.Character > r.End.Character {
+			continue
+		}
+
+		names := []string{ti.Lit}
+		for j := i; j >= 2 && tokens[j-1].Tok == scanner.DOT && tokens[j-2].Tok == scanner.IDENT; j -= 2 {
+			names = append([]string{tokens[j-2].Lit}, names...)
+		}
+
+		return names, r, true
+	}
+
+	return nil, Range{}, false
+}
+
+// linePrefix returns the characters of the line of pos that precede it.
+func linePrefix(text string, pos Position) string {
+	lines := strings.Split(text, "\n")
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return ""
+	}
+
+	line := []rune(lines[pos.Line])
+	if pos.Character < len(line) {
+		line = line[:pos.Character]
+	}
+
+	return string(line)
+}
+
+func isIdentChar(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
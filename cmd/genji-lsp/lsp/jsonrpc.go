@@ -0,0 +1,152 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+	codeInternalError  = -32603
+)
+
+// request is either a request, when it has an id, or a notification.
+type request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  *json.RawMessage `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+// conn reads and writes JSON-RPC messages, each of them being preceded by
+// a Content-Length header, like HTTP messages.
+type conn struct {
+	r *textproto.Reader
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{
+		r: textproto.NewReader(bufio.NewReader(r)),
+		w: w,
+	}
+}
+
+// read returns the next message. It returns io.EOF if there is none.
+func (c *conn) read() (*request, error) {
+	h, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF && len(h) == 0 {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	l, err := strconv.Atoi(h.Get("Content-Length"))
+	if err != nil || l < 0 {
+		return nil, errors.New("invalid Content-Length header")
+	}
+
+	body := make([]byte, l)
+	_, err = io.ReadFull(c.r.R, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var req request
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		return nil, &responseError{Code: codeParseError, Message: err.Error()}
+	}
+
+	return &req, nil
+}
+
+// reply sends the result of the request identified by id, or err if it is not nil.
+func (c *conn) reply(id *json.RawMessage, result interface{}, err error) error {
+	res := response{
+		ID: id,
+	}
+
+	if err != nil {
+		rerr, ok := err.(*responseError)
+		if !ok {
+			rerr = &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+		res.Error = rerr
+	} else {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		raw := json.RawMessage(data)
+		res.Result = &raw
+	}
+
+	return c.write(&res)
+}
+
+// notify sends a notification to the client.
+func (c *conn) notify(method string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return c.write(&request{
+		Method: method,
+		Params: data,
+	})
+}
+
+func (c *conn) write(msg interface{}) error {
+	switch m := msg.(type) {
+	case *request:
+		m.JSONRPC = "2.0"
+	case *response:
+		m.JSONRPC = "2.0"
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(data))
+	if err != nil {
+		return err
+	}
+
+	_, err = c.w.Write(data)
+	return err
+}
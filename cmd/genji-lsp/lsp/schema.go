@@ -0,0 +1,273 @@
+package lsp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+// Schema describes the tables known by the server.
+type Schema struct {
+	Tables []*Table
+}
+
+// A Table of the schema.
+type Table struct {
+	Name string
+	// Fields with a constraint, in the order they were declared.
+	Fields []Field
+	// Definition is the location of the name of the table in the
+	// CREATE TABLE statement of the schema file, if any.
+	Definition *Location
+}
+
+// A Field of a table, which has a constraint.
+type Field struct {
+	Path         document.Path
+	Type         document.ValueType
+	IsPrimaryKey bool
+	IsNotNull    bool
+	// SQL expression of a generated field.
+	GeneratedExpr string
+}
+
+// String returns the path of the field followed by its type and constraints.
+func (f *Field) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(f.Path.String())
+	if f.Type != 0 {
+		sb.WriteString(" " + strings.ToUpper(f.Type.String()))
+	}
+	if f.IsPrimaryKey {
+		sb.WriteString(" PRIMARY KEY")
+	}
+	if f.IsNotNull {
+		sb.WriteString(" NOT NULL")
+	}
+	if f.GeneratedExpr != "" {
+		sb.WriteString(" AS (" + f.GeneratedExpr + ")")
+	}
+
+	return sb.String()
+}
+
+// LoadSchema executes the statements of the file found at path on db, then returns
+// the tables of db. The tables created by the file point to their CREATE TABLE statement.
+func LoadSchema(db *genji.DB, path string) (*Schema, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Exec(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	s, err := ReadSchema(db)
+	if err != nil {
+		return nil, err
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+
+	defs := definitions(uri, string(data))
+	for _, t := range s.Tables {
+		if loc, ok := defs[t.Name]; ok {
+			t.Definition = &loc
+		}
+	}
+
+	return s, nil
+}
+
+// ReadSchema returns the tables of db, sorted by name.
+func ReadSchema(db *genji.DB) (*Schema, error) {
+	var s Schema
+
+	res, err := db.Query("SELECT * FROM __genji_tables ORDER BY table_name")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	err = res.Iterate(func(d document.Document) error {
+		v, err := d.GetByField("table_name")
+		if err != nil {
+			return err
+		}
+
+		var info database.TableInfo
+		err = info.ScanDocument(d)
+		if err != nil {
+			return err
+		}
+
+		t := Table{Name: v.V.(string)}
+		for _, fc := range info.FieldConstraints {
+			t.Fields = append(t.Fields, Field{
+				Path:          fc.Path,
+				Type:          fc.Type,
+				IsPrimaryKey:  fc.IsPrimaryKey,
+				IsNotNull:     fc.IsNotNull,
+				GeneratedExpr: fc.GeneratedExpr,
+			})
+		}
+
+		s.Tables = append(s.Tables, &t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Table returns the table called name, or nil if there is none.
+func (s *Schema) Table(name string) *Table {
+	for _, t := range s.Tables {
+		if t.Name == name {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// A fieldRef is a field and the table it belongs to.
+type fieldRef struct {
+	table *Table
+	field *Field
+}
+
+// lookupField returns the fields whose path is made of the given field names.
+// If none is found, the first name is considered as the name or alias of a table.
+func (s *Schema) lookupField(names []string) []fieldRef {
+	var refs []fieldRef
+
+	p := namesToPath(names)
+	for _, t := range s.Tables {
+		for i := range t.Fields {
+			if t.Fields[i].Path.IsEqual(p) {
+				refs = append(refs, fieldRef{t, &t.Fields[i]})
+			}
+		}
+	}
+
+	if len(refs) > 0 || len(names) < 2 {
+		return refs
+	}
+
+	p = p[1:]
+	tables := s.Tables
+	if t := s.Table(names[0]); t != nil {
+		tables = []*Table{t}
+	}
+	for _, t := range tables {
+		for i := range t.Fields {
+			if t.Fields[i].Path.IsEqual(p) {
+				refs = append(refs, fieldRef{t, &t.Fields[i]})
+			}
+		}
+	}
+
+	return refs
+}
+
+// subfields returns the names of the fields nested under the given field names.
+// If none is found, the first name is considered as the name or alias of a table.
+func (s *Schema) subfields(names []string) []string {
+	var subs []string
+	seen := make(map[string]bool)
+
+	add := func(fields []Field, prefix document.Path) {
+		for _, f := range fields {
+			if len(f.Path) <= len(prefix) || !f.Path[:len(prefix)].IsEqual(prefix) {
+				continue
+			}
+
+			name := f.Path[len(prefix)].FieldName
+			if name != "" && !seen[name] {
+				seen[name] = true
+				subs = append(subs, name)
+			}
+		}
+	}
+
+	p := namesToPath(names)
+	for _, t := range s.Tables {
+		add(t.Fields, p)
+	}
+
+	if len(subs) > 0 {
+		return subs
+	}
+
+	tables := s.Tables
+	if t := s.Table(names[0]); t != nil {
+		tables = []*Table{t}
+	}
+	for _, t := range tables {
+		add(t.Fields, p[1:])
+	}
+
+	return subs
+}
+
+func namesToPath(names []string) document.Path {
+	p := make(document.Path, len(names))
+	for i := range names {
+		p[i].FieldName = names[i]
+	}
+
+	return p
+}
+
+// definitions returns the location of the name of the tables created by
+// the CREATE TABLE statements of text.
+func definitions(uri, text string) map[string]Location {
+	defs := make(map[string]Location)
+
+	tokens, _ := scanner.Tokenise(text)
+	for i := 0; i+2 < len(tokens); i++ {
+		if tokens[i].Tok != scanner.CREATE || tokens[i+1].Tok != scanner.TABLE {
+			continue
+		}
+
+		j := i + 2
+		if j+2 < len(tokens) && tokens[j].Tok == scanner.IF && tokens[j+1].Tok == scanner.NOT && tokens[j+2].Tok == scanner.EXISTS {
+			j += 3
+		}
+
+		if j < len(tokens) && tokens[j].Tok == scanner.IDENT {
+			if _, ok := defs[tokens[j].Lit]; !ok {
+				defs[tokens[j].Lit] = Location{URI: uri, Range: tokenRange(tokens[j])}
+			}
+		}
+	}
+
+	return defs
+}
+
+// tokenRange returns the range of a token written on a single line.
+func tokenRange(ti scanner.TokenInfo) Range {
+	start := Position{Line: ti.Pos.Line, Character: ti.Pos.Char}
+	end := start
+	end.Character += utf8.RuneCountInString(ti.Raw)
+
+	return Range{Start: start, End: end}
+}
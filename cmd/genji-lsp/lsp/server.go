@@ -0,0 +1,174 @@
+// Package lsp implements a Language Server Protocol server for the Genji SQL dialect.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"strings"
+)
+
+// Server provides syntax error diagnostics, hover information, go-to-definition
+// and completion for the SQL documents opened by an editor, using the tables of a schema.
+type Server struct {
+	schema *Schema
+	conn   *conn
+
+	// content of the open documents, by URI
+	docs     map[string]string
+	shutdown bool
+}
+
+// NewServer creates a server for the tables of schema.
+func NewServer(schema *Schema) *Server {
+	if schema == nil {
+		schema = new(Schema)
+	}
+
+	return &Server{
+		schema: schema,
+		docs:   make(map[string]string),
+	}
+}
+
+// Serve reads the messages of the client from r and writes the responses and notifications
+// of the server to w, until r is closed or the exit notification is received.
+// It returns an error if the client exits without requesting a shutdown first.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+
+	for {
+		req, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var rerr *responseError
+			if errors.As(err, &rerr) {
+				err = s.conn.reply(nil, nil, rerr)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit notification received before shutdown")
+			}
+			return nil
+		}
+
+		result, err := s.handle(req)
+
+		// notifications don't have a response
+		if req.ID == nil {
+			if err != nil {
+				log.Printf("genji-lsp: %s: %v", req.Method, err)
+			}
+			continue
+		}
+
+		err = s.conn.reply(req.ID, result, err)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(req *request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		var res initializeResult
+		res.ServerInfo.Name = "genji-lsp"
+		res.Capabilities.TextDocumentSync = syncFull
+		res.Capabilities.HoverProvider = true
+		res.Capabilities.DefinitionProvider = true
+		res.Capabilities.CompletionProvider.TriggerCharacters = []string{"."}
+		return &res, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		s.docs[params.TextDocument.URI] = params.TextDocument.Text
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+	case "textDocument/didChange":
+		var params didChangeTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+
+		// the whole content of the document is sent on each change
+		s.docs[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+	case "textDocument/didClose":
+		var params didCloseTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.conn.notify("textDocument/publishDiagnostics", &publishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		return s.hover(s.docs[params.TextDocument.URI], params.Position), nil
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		uri := params.TextDocument.URI
+		return s.definition(uri, s.docs[uri], params.Position), nil
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		return s.completion(s.docs[params.TextDocument.URI], params.Position), nil
+	}
+
+	// notifications that are not supported must be ignored
+	if req.ID == nil || strings.HasPrefix(req.Method, "$/") {
+		return nil, nil
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+func (s *Server) publishDiagnostics(uri string) error {
+	return s.conn.notify("textDocument/publishDiagnostics", &publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: Diagnostics(s.docs[uri]),
+	})
+}
+
+func unmarshalParams(req *request, params interface{}) error {
+	err := json.Unmarshal(req.Params, params)
+	if err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/cmd/genji-lsp/lsp"
+	"github.com/stretchr/testify/require"
+)
+
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+type client struct {
+	in     bytes.Buffer
+	nextID int
+}
+
+func (c *client) send(method string, params interface{}, notification bool) {
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+	}
+	if params != nil {
+		msg["params"] = params
+	}
+	if !notification {
+		c.nextID++
+		msg["id"] = c.nextID
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(&c.in, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+func position(uri string, line, char int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     map[string]interface{}{"line": line, "character": char},
+	}
+}
+
+func readMessages(t *testing.T, r io.Reader) []message {
+	var msgs []message
+
+	tr := textproto.NewReader(bufio.NewReader(r))
+	for {
+		h, err := tr.ReadMIMEHeader()
+		if err == io.EOF {
+			return msgs
+		}
+		require.NoError(t, err)
+
+		l, err := strconv.Atoi(h.Get("Content-Length"))
+		require.NoError(t, err)
+		body := make([]byte, l)
+		_, err = io.ReadFull(tr.R, body)
+		require.NoError(t, err)
+
+		var msg message
+		require.NoError(t, json.Unmarshal(body, &msg))
+		msgs = append(msgs, msg)
+	}
+}
+
+func TestServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genji-lsp")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	schemaPath := filepath.Join(dir, "schema.sql")
+	err = ioutil.WriteFile(schemaPath, []byte(`
+CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, address.city TEXT);
+CREATE TABLE IF NOT EXISTS posts (title TEXT);
+`), 0644)
+	require.NoError(t, err)
+
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	schema, err := lsp.LoadSchema(db, schemaPath)
+	require.NoError(t, err)
+
+	uri := "file:///query.sql"
+	text := "CREATE TABLE tmp;\nSELECT name, address.city FROM users WHERE u.\nSELECT * FROM tmp WHERE"
+
+	var c client
+	c.send("initialize", map[string]interface{}{}, false)
+	c.send("initialized", map[string]interface{}{}, true)
+	c.send("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "sql", "version": 1, "text": text},
+	}, true)
+	c.send("textDocument/hover", position(uri, 1, 9), false)
+	c.send("textDocument/hover", position(uri, 1, 23), false)
+	c.send("textDocument/hover", position(uri, 1, 1), false)
+	c.send("textDocument/definition", position(uri, 1, 33), false)
+	c.send("textDocument/definition", position(uri, 2, 15), false)
+	c.send("textDocument/completion", position(uri, 1, 45), false)
+	c.send("textDocument/completion", position(uri, 2, 0), false)
+	c.send("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []interface{}{map[string]interface{}{"text": "SELECT 1"}},
+	}, true)
+	c.send("unknown", nil, false)
+	c.send("shutdown", nil, false)
+	c.send("exit", nil, true)
+
+	var out bytes.Buffer
+	err = lsp.NewServer(schema).Serve(&c.in, &out)
+	require.NoError(t, err)
+
+	msgs := readMessages(t, &out)
+	require.Len(t, msgs, 12)
+
+	t.Run("Initialize", func(t *testing.T) {
+		require.Equal(t, 1, *msgs[0].ID)
+		require.JSONEq(t, `{
+			"capabilities": {
+				"textDocumentSync": 1,
+				"hoverProvider": true,
+				"definitionProvider": true,
+				"completionProvider": {"triggerCharacters": ["."]}
+			},
+			"serverInfo": {"name": "genji-lsp"}
+		}`, string(msgs[0].Result))
+	})
+
+	t.Run("Diagnostics", func(t *testing.T) {
+		require.Equal(t, "textDocument/publishDiagnostics", msgs[1].Method)
+		require.JSONEq(t, `{
+			"uri": "file:///query.sql",
+			"diagnostics": [{
+				"range": {"start": {"line": 1, "character": 45}, "end": {"line": 1, "character": 45}},
+				"severity": 1,
+				"source": "genji",
+				"message": "found \n, expected identifier"
+			}]
+		}`, string(msgs[1].Params))
+
+		require.Equal(t, "textDocument/publishDiagnostics", msgs[9].Method)
+		require.JSONEq(t, `{"uri": "file:///query.sql", "diagnostics": []}`, string(msgs[9].Params))
+	})
+
+	t.Run("Hover", func(t *testing.T) {
+		var h lsp.Hover
+		require.NoError(t, json.Unmarshal(msgs[2].Result, &h))
+		require.Equal(t, "markdown", h.Contents.Kind)
+		require.Equal(t, "```sql\nname TEXT NOT NULL\n```\nfield of table `users`", h.Contents.Value)
+		require.Equal(t, &lsp.Range{Start: lsp.Position{Line: 1, Character: 7}, End: lsp.Position{Line: 1, Character: 11}}, h.Range)
+
+		h = lsp.Hover{}
+		require.NoError(t, json.Unmarshal(msgs[3].Result, &h))
+		require.Equal(t, "```sql\naddress.city TEXT\n```\nfield of table `users`", h.Contents.Value)
+
+		// keywords
+		require.Equal(t, "null", string(msgs[4].Result))
+	})
+
+	t.Run("Definition", func(t *testing.T) {
+		abs, err := filepath.Abs(schemaPath)
+		require.NoError(t, err)
+
+		var locs []lsp.Location
+		require.NoError(t, json.Unmarshal(msgs[5].Result, &locs))
+		require.Equal(t, []lsp.Location{{
+			URI:   "file://" + filepath.ToSlash(abs),
+			Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 13}, End: lsp.Position{Line: 1, Character: 18}},
+		}}, locs)
+
+		// tables created by the document
+		locs = nil
+		require.NoError(t, json.Unmarshal(msgs[6].Result, &locs))
+		require.Equal(t, []lsp.Location{{
+			URI:   uri,
+			Range: lsp.Range{Start: lsp.Position{Line: 0, Character: 13}, End: lsp.Position{Line: 0, Character: 16}},
+		}}, locs)
+	})
+
+	t.Run("Completion", func(t *testing.T) {
+		// fields of an alias
+		var list lsp.CompletionList
+		require.NoError(t, json.Unmarshal(msgs[7].Result, &list))
+		var labels []string
+		for _, it := range list.Items {
+			labels = append(labels, it.Label)
+		}
+		require.Equal(t, []string{"title", "id", "name", "address"}, labels)
+
+		list = lsp.CompletionList{}
+		require.NoError(t, json.Unmarshal(msgs[8].Result, &list))
+		require.Equal(t, lsp.CompletionItem{Label: "posts", Kind: lsp.CompletionClass, Detail: "table"}, list.Items[0])
+		require.Equal(t, lsp.CompletionItem{Label: "users", Kind: lsp.CompletionClass, Detail: "table"}, list.Items[1])
+		require.Equal(t, lsp.CompletionItem{Label: "title", Kind: lsp.CompletionField, Detail: "field of posts"}, list.Items[2])
+		require.Contains(t, list.Items, lsp.CompletionItem{Label: "SELECT", Kind: lsp.CompletionKeyword})
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		require.Equal(t, -32601, msgs[10].Error.Code)
+		require.Equal(t, 10, *msgs[11].ID)
+		require.Equal(t, "null", string(msgs[11].Result))
+	})
+}
+
+func TestServerExitWithoutShutdown(t *testing.T) {
+	var c client
+	c.send("initialize", map[string]interface{}{}, false)
+	c.send("exit", nil, true)
+
+	var out bytes.Buffer
+	err := lsp.NewServer(nil).Serve(&c.in, &out)
+	require.Error(t, err)
+}
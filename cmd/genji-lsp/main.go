@@ -0,0 +1,50 @@
+// Command genji-lsp is a Language Server Protocol server for the Genji SQL dialect.
+// It communicates with editors over its standard input and output.
+//
+// The tables used for hover information, go-to-definition and completion are created
+// in an in-memory database by the SQL file passed with the -schema flag:
+//
+//	$ genji-lsp -schema schema.sql
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/cmd/genji-lsp/lsp"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "", "path of a SQL file creating the tables of the database")
+	flag.Parse()
+
+	// stdout is used by the protocol
+	log.SetOutput(os.Stderr)
+
+	err := run(*schemaPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath string) error {
+	db, err := genji.Open(":memory:")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	schema := new(lsp.Schema)
+	if schemaPath != "" {
+		schema, err = lsp.LoadSchema(db, schemaPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return lsp.NewServer(schema).Serve(os.Stdin, os.Stdout)
+}
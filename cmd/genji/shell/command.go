@@ -242,6 +242,14 @@ func dumpTable(tx *genji.Tx, tableName string, w io.Writer) error {
 		if fc.IsNotNull {
 			buf.WriteString(" NOT NULL")
 		}
+
+		if fc.IsGenerated() {
+			buf.WriteString(" AS (" + fc.GeneratedExpr + ")")
+		}
+
+		if len(ti.TTLPath) > 0 && fc.Path.IsEqual(ti.TTLPath) {
+			buf.WriteString(" TTL")
+		}
 	}
 
 	// Fields constraints close parenthesis.
@@ -287,6 +295,24 @@ func dumpTable(tx *genji.Tx, tableName string, w io.Writer) error {
 	return res.Iterate(func(d document.Document) error {
 		buf.WriteString(insert)
 
+		// generated fields are computed again when the documents are inserted
+		for _, fc := range fcs {
+			if !fc.IsGenerated() {
+				continue
+			}
+
+			fb := document.NewFieldBuffer()
+			err := fb.Copy(d)
+			if err != nil {
+				return err
+			}
+			err = fb.DeletePath(fc.Path)
+			if err != nil && err != document.ErrFieldNotFound {
+				return err
+			}
+			d = fb
+		}
+
 		data, err := document.MarshalJSON(d)
 		if err != nil {
 			return err
@@ -101,6 +101,8 @@ func TestRunDumpCmd(t *testing.T) {
 		{"Values / With columns", `INSERT INTO test (a, b, c) VALUES ('a', 'b', 'c')`, ``, `INSERT INTO test VALUES {"a": "a", "b": "b", "c": "c"};`, false, nil},
 		{"text / not null with type constraint", `INSERT INTO test (a, b, c) VALUES ('a', 'b', 'c')`, `TEXT NOT NULL`, `INSERT INTO test VALUES {"a": "a", "b": "b", "c": "c"};`, false, nil},
 		{"text / pk and not null with type constraint", `INSERT INTO test (a, b, c) VALUES ('a', 'b', 'c')`, `TEXT PRIMARY KEY NOT NULL`, `INSERT INTO test VALUES {"a": "a", "b": "b", "c": "c"};`, false, nil},
+		{"double / generated", `INSERT INTO test (b, c) VALUES (1, 'c')`, `DOUBLE AS (b + 1)`, `INSERT INTO test VALUES {"b": 1, "c": "c"};`, false, nil},
+		{"double / ttl", `INSERT INTO test (a) VALUES (1.5)`, `DOUBLE TTL`, `INSERT INTO test VALUES {"a": 1.5};`, false, nil},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,16 @@
+package genji
+
+import "github.com/genjidb/genji/database"
+
+// A Codec transforms the encoded documents of a table
+// just before they are written to the engine and just after they are read from it.
+type Codec = database.TableCodec
+
+// SetCodec uses c to store the documents of the given table, for example to encrypt them.
+// It applies to every read and write of the table, but not to its indexes:
+// indexed values and primary keys are stored in plaintext.
+// The codec is not persisted and must be set every time the database is opened,
+// before the table is accessed. If c is nil, the codec of the table is removed.
+func (db *DB) SetCodec(table string, c Codec) {
+	db.DB.SetTableCodec(table, c)
+}
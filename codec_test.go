@@ -0,0 +1,114 @@
+package genji_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+type gcmCodec struct {
+	aead cipher.AEAD
+}
+
+func newGCMCodec(t *testing.T) *gcmCodec {
+	block, err := aes.NewCipher(bytes.Repeat([]byte("k"), 32))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return &gcmCodec{aead: aead}
+}
+
+func (c *gcmCodec) Encode(data []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+func (c *gcmCodec) Decode(data []byte) ([]byte, error) {
+	n := c.aead.NonceSize()
+	if len(data) < n {
+		return nil, errors.New("invalid data")
+	}
+
+	return c.aead.Open(nil, data[:n], data[n:], nil)
+}
+
+func TestCodec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genji")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.db")
+
+	db, err := genji.Open(path)
+	require.NoError(t, err)
+	db.SetCodec("users", newGCMCodec(t))
+
+	err = db.Exec(`
+		CREATE TABLE users(id INTEGER PRIMARY KEY);
+		CREATE INDEX idx_users_age ON users(age);
+		CREATE TABLE public;
+		INSERT INTO users (id, name, age) VALUES (1, 'secretname1', 10), (2, 'secretname2', 20);
+		INSERT INTO public (name) VALUES ('publicname');
+		UPDATE users SET name = 'secretname3' WHERE id = 2;
+	`)
+	require.NoError(t, err)
+
+	readNames := func(t *testing.T, db *genji.DB, q string, args ...interface{}) []string {
+		res, err := db.Query(q, args...)
+		require.NoError(t, err)
+		defer res.Close()
+
+		var names []string
+		err = res.Iterate(func(d document.Document) error {
+			v, err := d.GetByField("name")
+			if err != nil {
+				return err
+			}
+			names = append(names, v.V.(string))
+			return nil
+		})
+		require.NoError(t, err)
+		return names
+	}
+
+	check := func(t *testing.T, db *genji.DB) {
+		// table scan
+		require.Equal(t, []string{"secretname1", "secretname3"}, readNames(t, db, "SELECT name FROM users"))
+		// primary key lookup
+		require.Equal(t, []string{"secretname3"}, readNames(t, db, "SELECT name FROM users WHERE id = ?", 2))
+		// index lookup
+		require.Equal(t, []string{"secretname1"}, readNames(t, db, "SELECT name FROM users WHERE age = ?", 10))
+		require.Equal(t, []string{"publicname"}, readNames(t, db, "SELECT name FROM public"))
+	}
+
+	check(t, db)
+	err = db.Close()
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(data, []byte("secretname")))
+	require.True(t, bytes.Contains(data, []byte("publicname")))
+
+	db, err = genji.Open(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	db.SetCodec("users", newGCMCodec(t))
+	check(t, db)
+}
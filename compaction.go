@@ -0,0 +1,129 @@
+package genji
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// compactionInterval is the interval at which the compactor checks the ratio of dead bytes.
+const compactionInterval = time.Second
+
+// CompactionStats holds the statistics of the automatic compaction.
+type CompactionStats struct {
+	// Compactions is the number of compactions run since the database was opened.
+	Compactions int
+	// LastCompaction is the time at which the last compaction ended.
+	// It is zero if no compaction ran.
+	LastCompaction time.Time
+	// BytesReclaimed is the number of bytes reclaimed by the last compaction.
+	BytesReclaimed int64
+}
+
+// compacter is implemented by engines that can compact their storage,
+// like the BoltDB engine.
+type compacter interface {
+	DeadRatio() (float64, error)
+	Compact() (int64, error)
+}
+
+// WithCompactionThreshold starts a background goroutine that compacts the storage
+// once the ratio of bytes left by deleted or overwritten data exceeds ratio.
+// A ratio of 0.5 compacts the storage when half of its bytes are dead.
+// Reads are not blocked during the compaction, other transactions are only paused
+// while the files are swapped. The engine must support compaction: this option
+// must be given before the options that wrap the engine, like WithPageCache.
+// The compactor is stopped by Close.
+func WithCompactionThreshold(ratio float64) Option {
+	return func(db *DB) error {
+		if ratio <= 0 || ratio >= 1 {
+			return errors.New("compaction threshold must be between 0 and 1")
+		}
+
+		return db.DB.WrapEngine(func(ng engine.Engine) (engine.Engine, error) {
+			c, ok := ng.(compacter)
+			if !ok {
+				return nil, errors.New("engine doesn't support compaction")
+			}
+
+			db.compactor = &compactor{
+				c:     c,
+				ratio: ratio,
+				stop:  make(chan struct{}),
+				done:  make(chan struct{}),
+			}
+			go db.compactor.run()
+			return ng, nil
+		})
+	}
+}
+
+// CompactionStats returns the statistics of the compaction enabled with WithCompactionThreshold.
+// If it is disabled, it returns empty statistics.
+func (db *DB) CompactionStats() CompactionStats {
+	if db.compactor == nil {
+		return CompactionStats{}
+	}
+
+	db.compactor.mu.Lock()
+	defer db.compactor.mu.Unlock()
+
+	return db.compactor.stats
+}
+
+type compactor struct {
+	c     compacter
+	ratio float64
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	mu    sync.Mutex
+	stats CompactionStats
+}
+
+func (c *compactor) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(compactionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			// errors are not fatal, the next tick will try again.
+			_ = c.compact()
+		}
+	}
+}
+
+func (c *compactor) close() {
+	c.once.Do(func() {
+		close(c.stop)
+	})
+	<-c.done
+}
+
+// compact compacts the storage if the ratio of dead bytes exceeds the threshold.
+func (c *compactor) compact() error {
+	r, err := c.c.DeadRatio()
+	if err != nil || r < c.ratio {
+		return err
+	}
+
+	n, err := c.c.Compact()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.stats.Compactions++
+	c.stats.LastCompaction = time.Now()
+	c.stats.BytesReclaimed = n
+	c.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,83 @@
+package genji_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactionThreshold(t *testing.T) {
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := genji.Open(":memory:", genji.WithCompactionThreshold(0.5))
+		require.Error(t, err)
+
+		_, err = genji.Open(":memory:", genji.WithCompactionThreshold(1.5))
+		require.Error(t, err)
+	})
+
+	t.Run("Compaction", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "genji")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "test.db")
+		db, err := genji.Open(path, genji.WithCompactionThreshold(0.5))
+		require.NoError(t, err)
+		defer db.Close()
+
+		require.Zero(t, db.CompactionStats())
+
+		err = db.Update(func(tx *genji.Tx) error {
+			err := tx.Exec("CREATE TABLE test(id INTEGER PRIMARY KEY)")
+			if err != nil {
+				return err
+			}
+			for i := 0; i < 1000; i++ {
+				err = tx.Exec("INSERT INTO test (id, v) VALUES (?, ?)", i, strings.Repeat("v", 512))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		err = db.Exec("DELETE FROM test WHERE id % 5 != 0")
+		require.NoError(t, err)
+
+		before, err := os.Stat(path)
+		require.NoError(t, err)
+
+		// reads succeed until the compaction ends
+		deadline := time.Now().Add(10 * time.Second)
+		for db.CompactionStats().Compactions == 0 {
+			require.True(t, time.Now().Before(deadline), "compaction not triggered")
+
+			d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM test")
+			require.NoError(t, err)
+			n, err := d.GetByField("n")
+			require.NoError(t, err)
+			require.EqualValues(t, 200, n.V)
+		}
+
+		stats := db.CompactionStats()
+		require.False(t, stats.LastCompaction.IsZero())
+		require.Greater(t, stats.BytesReclaimed, int64(0))
+
+		after, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Less(t, after.Size(), before.Size())
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM test")
+		require.NoError(t, err)
+		n, err := d.GetByField("n")
+		require.NoError(t, err)
+		require.EqualValues(t, 200, n.V)
+	})
+}
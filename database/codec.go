@@ -0,0 +1,91 @@
+package database
+
+import (
+	"io"
+
+	"github.com/genjidb/genji/document"
+)
+
+// A TableCodec transforms the encoded documents of a table
+// just before they are written to the engine and just after they are read from it.
+// It can be used to encrypt or compress the documents of specific tables.
+// Keys and indexes are left untouched: indexed values are stored as is.
+type TableCodec interface {
+	Encode(data []byte) ([]byte, error)
+	Decode(data []byte) ([]byte, error)
+}
+
+// SetTableCodec sets the codec used to store the documents of the given table.
+// If c is nil, the codec of the table is removed.
+// The codec is not persisted: it must be set every time the database is opened,
+// before accessing the table. It is bound to the name of the table
+// and must be set again if the table is renamed.
+func (db *Database) SetTableCodec(table string, c TableCodec) {
+	db.tableCodecsMu.Lock()
+	defer db.tableCodecsMu.Unlock()
+
+	if c == nil {
+		delete(db.tableCodecs, table)
+		return
+	}
+
+	if db.tableCodecs == nil {
+		db.tableCodecs = make(map[string]TableCodec)
+	}
+	db.tableCodecs[table] = c
+}
+
+func (db *Database) tableCodec(table string) TableCodec {
+	db.tableCodecsMu.RLock()
+	defer db.tableCodecsMu.RUnlock()
+
+	return db.tableCodecs[table]
+}
+
+// maxPooledEncoderSize is the maximum size of the scratch buffer
+// of an encoder put back in the pool, to avoid retaining
+// the memory used by unusually large documents.
+const maxPooledEncoderSize = 64 << 10
+
+// encodeDocument encodes d with the codec of the database.
+// The encoder is pooled, and the returned slice is allocated
+// with the exact size of the encoded document.
+func (db *Database) encodeDocument(d document.Document) ([]byte, error) {
+	enc, ok := db.encoders.Get().(*document.Encoder)
+	if !ok {
+		enc = document.NewEncoder(func(w io.Writer) document.DocumentEncoder {
+			return db.Codec.NewEncoder(w)
+		})
+	}
+
+	scratch, err := enc.Encode(d)
+	var data []byte
+	if err == nil {
+		data = append(make([]byte, 0, len(scratch)), scratch...)
+	}
+
+	if cap(scratch) <= maxPooledEncoderSize {
+		enc.Reset()
+		db.encoders.Put(enc)
+	}
+
+	return data, err
+}
+
+// tableValueCodec applies a TableCodec to the values of a store.
+type tableValueCodec struct {
+	codec TableCodec
+}
+
+func (c tableValueCodec) Encode(k, v []byte) ([]byte, error) {
+	return c.codec.Encode(v)
+}
+
+func (c tableValueCodec) Decode(dst, k, v []byte) ([]byte, error) {
+	v, err := c.codec.Decode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(dst, v...), nil
+}
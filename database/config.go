@@ -19,12 +19,20 @@ type FieldConstraint struct {
 	IsPrimaryKey bool
 	IsNotNull    bool
 	DefaultValue document.Value
+	// GeneratedExpr is the SQL expression computing the value of the field,
+	// if the field is generated. See Database.EvalGeneratedField.
+	GeneratedExpr string
 }
 
 func (f *FieldConstraint) HasDefaultValue() bool {
 	return f.DefaultValue.Type != 0
 }
 
+// IsGenerated returns true if the value of the field is computed by the database.
+func (f *FieldConstraint) IsGenerated() bool {
+	return f.GeneratedExpr != ""
+}
+
 // ToDocument returns a document from f.
 func (f *FieldConstraint) ToDocument() document.Document {
 	buf := document.NewFieldBuffer()
@@ -36,6 +44,9 @@ func (f *FieldConstraint) ToDocument() document.Document {
 	if f.HasDefaultValue() {
 		buf.Add("default_value", f.DefaultValue)
 	}
+	if f.IsGenerated() {
+		buf.Add("generated_expr", document.NewTextValue(f.GeneratedExpr))
+	}
 	return buf
 }
 
@@ -77,14 +88,112 @@ func (f *FieldConstraint) ScanDocument(d document.Document) error {
 		f.DefaultValue = v
 	}
 
+	v, err = d.GetByField("generated_expr")
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if err == nil {
+		f.GeneratedExpr = v.V.(string)
+	}
+
 	return nil
 }
 
 // FieldConstraints is a list of field constraints.
 type FieldConstraints []FieldConstraint
 
+// overlap returns true if p overlaps the path of one of the constraints,
+// whose value may be converted, set to its default value by ValidateDocument
+// or generated.
+func (f FieldConstraints) overlap(p document.Path) bool {
+	for i := range f {
+		if f[i].Path.Overlaps(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generatedField returns the constraint of the generated field at p
+// or at one of its parents, or nil if there is none.
+func (f FieldConstraints) generatedField(p document.Path) *FieldConstraint {
+	for i := range f {
+		gp := f[i].Path
+		if f[i].IsGenerated() && len(p) >= len(gp) && gp.IsEqual(p[:len(gp)]) {
+			return &f[i]
+		}
+	}
+
+	return nil
+}
+
+// checkGeneratedFields returns an error if d contains a value for one of the generated fields.
+func (f FieldConstraints) checkGeneratedFields(d document.Document) error {
+	for _, fc := range f {
+		if !fc.IsGenerated() {
+			continue
+		}
+
+		v, err := fc.Path.GetValue(d)
+		if err == document.ErrFieldNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		return &ConstraintViolationError{
+			Kind:       GeneratedViolation,
+			Path:       fc.Path,
+			Value:      v,
+			Constraint: "GENERATED",
+		}
+	}
+
+	return nil
+}
+
+// convertGenerated converts v, the value computed for the generated field f,
+// to the type of the field and ensures it is not null if required.
+// Like with Convert, integers are converted to doubles if the field has no type.
+func (f *FieldConstraint) convertGenerated(v document.Value) (document.Value, error) {
+	if v.Type == document.NullValue {
+		if f.IsNotNull {
+			return v, &ConstraintViolationError{
+				Kind:       NotNullViolation,
+				Path:       f.Path,
+				Value:      v,
+				Constraint: "NOT NULL",
+			}
+		}
+		return v, nil
+	}
+
+	if f.Type == 0 {
+		if v.Type == document.IntegerValue {
+			return v.CastAsDouble()
+		}
+		return v, nil
+	}
+
+	cv, err := v.CastAs(f.Type)
+	if err != nil {
+		return cv, &ConstraintViolationError{
+			Kind:       TypeViolation,
+			Path:       f.Path,
+			Value:      v,
+			Constraint: f.Type.String(),
+			Err:        err,
+		}
+	}
+
+	return cv, nil
+}
+
 // ValidateDocument calls Convert then ensures the document validates against the field constraints.
-func (f FieldConstraints) ValidateDocument(d document.Document) (document.Document, error) {
+// Generated fields are ignored, they are validated once computed.
+func (f FieldConstraints) ValidateDocument(d document.Document) (*document.FieldBuffer, error) {
 	fb, err := f.Convert(d)
 	if err != nil {
 		return nil, err
@@ -92,13 +201,22 @@ func (f FieldConstraints) ValidateDocument(d document.Document) (document.Docume
 
 	// ensure no field is missing
 	for _, fc := range f {
+		if fc.IsGenerated() {
+			continue
+		}
+
 		v, err := fc.Path.GetValue(fb)
 		if err == nil {
 			// if field is found, it has already been converted
 			// to the right type above.
 			// check if it is required but null.
 			if v.Type == document.NullValue && fc.IsNotNull {
-				return nil, fmt.Errorf("field %q is required and must be not null", fc.Path)
+				return nil, &ConstraintViolationError{
+					Kind:       NotNullViolation,
+					Path:       fc.Path,
+					Value:      v,
+					Constraint: "NOT NULL",
+				}
 			}
 			continue
 		}
@@ -117,7 +235,11 @@ func (f FieldConstraints) ValidateDocument(d document.Document) (document.Docume
 			// if there is no default value
 			// check if field is required
 		} else if fc.IsNotNull {
-			return nil, fmt.Errorf("field %q is required and must be not null", fc.Path)
+			return nil, &ConstraintViolationError{
+				Kind:       NotNullViolation,
+				Path:       fc.Path,
+				Constraint: "NOT NULL",
+			}
 		}
 	}
 
@@ -147,7 +269,17 @@ func (f FieldConstraints) Convert(d document.Document) (*document.FieldBuffer, e
 			// check if the constraint enforce a particular type
 			// and if so convert the value to the new type.
 			if fc.Type != 0 {
-				return v.CastAs(fc.Type)
+				cv, err := v.CastAs(fc.Type)
+				if err != nil {
+					return cv, &ConstraintViolationError{
+						Kind:       TypeViolation,
+						Path:       append(document.Path(nil), p...),
+						Value:      v,
+						Constraint: fc.Type.String(),
+						Err:        err,
+					}
+				}
+				return cv, nil
 			}
 			break
 		}
@@ -173,6 +305,12 @@ type TableInfo struct {
 	readOnly  bool
 
 	FieldConstraints FieldConstraints
+
+	// TTLPath is the path of the field holding the expiration time
+	// of each document, expressed as a Unix time in nanoseconds.
+	// If set, expired documents are deleted by the TTL reaper.
+	// In SQL, it is set by the TTL constraint: CREATE TABLE foo(expires_at INTEGER TTL).
+	TTLPath document.Path
 }
 
 // GetPrimaryKey returns the field constraint of the primary key.
@@ -202,6 +340,10 @@ func (ti *TableInfo) ToDocument() document.Document {
 	buf.Add("field_constraints", document.NewArrayValue(vbuf))
 
 	buf.Add("read_only", document.NewBoolValue(ti.readOnly))
+
+	if len(ti.TTLPath) > 0 {
+		buf.Add("ttl_path", document.NewArrayValue(pathToArray(ti.TTLPath)))
+	}
 	return buf
 }
 
@@ -246,6 +388,18 @@ func (ti *TableInfo) ScanDocument(d document.Document) error {
 	}
 
 	ti.readOnly = v.V.(bool)
+
+	v, err = d.GetByField("ttl_path")
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if err == nil {
+		ti.TTLPath, err = arrayToPath(v.V.(document.Array))
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -392,6 +546,12 @@ type IndexConfig struct {
 
 	// If set, the index is typed and only accepts that type
 	Type document.ValueType
+
+	// If set to true, the index is being built and only contains the documents
+	// whose key is lower than or equal to BuildKey. It is maintained for these
+	// documents only and is not used by queries until the build is complete.
+	Building bool
+	BuildKey []byte
 }
 
 // ToDocument creates a document from an IndexConfig.
@@ -405,6 +565,12 @@ func (i *IndexConfig) ToDocument() document.Document {
 	if i.Type != 0 {
 		buf.Add("type", document.NewIntegerValue(int64(i.Type)))
 	}
+	if i.Building {
+		buf.Add("building", document.NewBoolValue(true))
+		if i.BuildKey != nil {
+			buf.Add("build_key", document.NewBlobValue(i.BuildKey))
+		}
+	}
 	return buf
 }
 
@@ -445,6 +611,22 @@ func (i *IndexConfig) ScanDocument(d document.Document) error {
 		i.Type = document.ValueType(v.V.(int64))
 	}
 
+	v, err = d.GetByField("building")
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if err == nil {
+		i.Building = v.V.(bool)
+	}
+
+	v, err = d.GetByField("build_key")
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if err == nil {
+		i.BuildKey = append([]byte(nil), v.V.([]byte)...)
+	}
+
 	return nil
 }
 
@@ -455,6 +637,15 @@ type Index struct {
 	Opts IndexConfig
 }
 
+// covers returns whether the index contains the entries of the document stored under key.
+func (i Index) covers(key []byte) bool {
+	if !i.Opts.Building {
+		return true
+	}
+
+	return i.Opts.BuildKey != nil && bytes.Compare(key, i.Opts.BuildKey) <= 0
+}
+
 type indexStore struct {
 	db *Database
 	st engine.Store
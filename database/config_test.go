@@ -24,7 +24,9 @@ func TestTableInfo(t *testing.T) {
 	info := &TableInfo{
 		FieldConstraints: []FieldConstraint{
 			{Path: newPath("k"), Type: document.DoubleValue, IsPrimaryKey: true},
+			{Path: newPath("total"), Type: document.DoubleValue, GeneratedExpr: "price * qty"},
 		},
+		TTLPath: newPath("expires_at"),
 	}
 
 	doc := info.ToDocument()
@@ -32,6 +34,8 @@ func TestTableInfo(t *testing.T) {
 	var res TableInfo
 	err := res.ScanDocument(doc)
 	require.NoError(t, err)
+	require.Equal(t, info.TTLPath, res.TTLPath)
+	require.Equal(t, info.FieldConstraints, res.FieldConstraints)
 }
 
 func TestTableInfoStore(t *testing.T) {
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"sync"
 
+	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/document/encoding"
 	"github.com/genjidb/genji/engine"
 )
@@ -28,8 +29,63 @@ type Database struct {
 
 	// Codec used to encode documents. Defaults to MessagePack.
 	Codec encoding.Codec
+
+	// If true, comparing values of incompatible types, like a text with an integer,
+	// returns an error instead of evaluating to false.
+	StrictTypes bool
+
+	// If true, field names of paths are matched case-insensitively against
+	// the fields of the documents, the first matching field winning.
+	// This applies to expressions, UPDATE SET targets, ORDER BY, StructScan
+	// and indexes. Documents are stored with their original field names.
+	CaseInsensitiveFields bool
+
+	// If true, inserting documents into a table that doesn't exist
+	// creates the table, without constraints, in the same transaction.
+	AutoCreateTables bool
+
+	// MemoryBudget is the approximate amount of memory, in bytes, that the operators
+	// of a statement can use to buffer documents, like ORDER BY or DISTINCT.
+	// Zero means no limit. Defaults to DefaultMemoryBudget.
+	MemoryBudget int64
+
+	// SpillEngine, if set, creates a temporary engine where operators move their buffered
+	// documents once the memory budget is exceeded. The engine is closed once the operator is done.
+	// If nil, operators return ErrMemoryBudgetExceeded instead.
+	// Except in WebAssembly, genji.New sets it to an engine stored in a temporary file.
+	SpillEngine func() (engine.Engine, error)
+
+	// RunTrigger runs the statement of a trigger in the transaction that fired it.
+	// old is nil for insertions and new is nil for deletions.
+	// If it returns an error, the change of the document fails with that error.
+	// If nil, changing the documents of a table that has triggers returns an error.
+	RunTrigger func(tx *Transaction, trigger *TriggerConfig, old, new document.Document) error
+
+	// EvalGeneratedField evaluates the expression of a generated field against d,
+	// the document being inserted or updated, in the transaction that writes it.
+	// If nil, writing to a table that has generated fields returns an error.
+	EvalGeneratedField func(tx *Transaction, fc *FieldConstraint, d document.Document) (document.Value, error)
+
+	// TransactionHooks are called in order at the steps of the lifecycle
+	// of the read/write transactions.
+	TransactionHooks []TransactionHook
+
+	// codecs of the tables, set with SetTableCodec.
+	tableCodecs   map[string]TableCodec
+	tableCodecsMu sync.RWMutex
+
+	// encoders used to store documents, reused across transactions.
+	encoders sync.Pool
+
+	// closed every time a read/write transaction is committed,
+	// created by Committed when needed.
+	committed   chan struct{}
+	committedMu sync.Mutex
 }
 
+// DefaultMemoryBudget is the default memory budget of a statement.
+const DefaultMemoryBudget = 1 << 30
+
 type Options struct {
 	Codec encoding.Codec
 }
@@ -41,8 +97,20 @@ func New(ctx context.Context, ng engine.Engine, opts Options) (*Database, error)
 	}
 
 	db := Database{
-		ng:    ng,
-		Codec: opts.Codec,
+		ng:           ng,
+		Codec:        opts.Codec,
+		MemoryBudget: DefaultMemoryBudget,
+	}
+
+	// if the internal stores already exist, there is no need
+	// for a read/write transaction, which allows opening databases
+	// on top of read-only engines.
+	ok, err := db.hasInternalStores(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return &db, nil
 	}
 
 	ntx, err := db.ng.Begin(ctx, engine.TxOptions{
@@ -66,6 +134,26 @@ func New(ctx context.Context, ng engine.Engine, opts Options) (*Database, error)
 	return &db, nil
 }
 
+func (db *Database) hasInternalStores(ctx context.Context) (bool, error) {
+	tx, err := db.ng.Begin(ctx, engine.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	for _, name := range []string{tableInfoStoreName, indexStoreName} {
+		_, err = tx.GetStore([]byte(name))
+		if err == engine.ErrStoreNotFound {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
 func (db *Database) initInternalStores(tx engine.Transaction) error {
 	_, err := tx.GetStore([]byte(tableInfoStoreName))
 	if err == engine.ErrStoreNotFound {
@@ -82,11 +170,46 @@ func (db *Database) initInternalStores(tx engine.Transaction) error {
 	return err
 }
 
+// WrapEngine replaces the engine of the database by the one returned by fn,
+// which receives the current engine. It must be called before any transaction is started.
+func (db *Database) WrapEngine(fn func(ng engine.Engine) (engine.Engine, error)) error {
+	ng, err := fn(db.ng)
+	if err != nil {
+		return err
+	}
+
+	db.ng = ng
+	return nil
+}
+
 // Close the underlying engine.
 func (db *Database) Close() error {
 	return db.ng.Close()
 }
 
+// Committed returns a channel that is closed once the next read/write transaction is committed.
+func (db *Database) Committed() <-chan struct{} {
+	db.committedMu.Lock()
+	defer db.committedMu.Unlock()
+
+	if db.committed == nil {
+		db.committed = make(chan struct{})
+	}
+
+	return db.committed
+}
+
+// notifyCommit closes the channel returned by Committed, if any.
+func (db *Database) notifyCommit() {
+	db.committedMu.Lock()
+	defer db.committedMu.Unlock()
+
+	if db.committed != nil {
+		close(db.committed)
+		db.committed = nil
+	}
+}
+
 // Begin starts a new transaction with default options.
 // The returned transaction must be closed either by calling Rollback or Commit.
 func (db *Database) Begin(writable bool) (*Transaction, error) {
@@ -123,6 +246,7 @@ func (db *Database) BeginTx(ctx context.Context, opts *TxOptions) (*Transaction,
 	tx := Transaction{
 		db:       db,
 		tx:       ntx,
+		ctx:      ctx,
 		writable: !opts.ReadOnly,
 		attached: opts.Attached,
 	}
@@ -163,3 +287,87 @@ func (db *Database) GetAttachedTx() *Transaction {
 
 	return db.attachedTransaction
 }
+
+// BuildIndex builds an index created with the Building option, indexing at most chunkSize
+// documents per transaction so that other transactions can run between chunks.
+// If the build was interrupted, it resumes after the last indexed document.
+// Documents written during the build are indexed either by the writes themselves
+// or by a subsequent chunk.
+// If progress is not nil, it is called after each chunk with the number of indexed
+// documents and the number of documents in the table when the build started.
+func (db *Database) BuildIndex(ctx context.Context, indexName string, chunkSize int, progress func(done, total int64)) error {
+	if chunkSize <= 0 {
+		return errors.New("chunk size must be positive")
+	}
+
+	done, total, err := db.indexBuildState(ctx, indexName)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		n, complete, err := tx.BuildIndex(indexName, chunkSize)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+
+		done += int64(n)
+		if done > total {
+			total = done
+		}
+		if progress != nil {
+			progress(done, total)
+		}
+
+		if complete {
+			return nil
+		}
+	}
+}
+
+// indexBuildState returns the number of documents already covered by an index being built
+// and the number of documents of its table.
+func (db *Database) indexBuildState(ctx context.Context, indexName string) (done, total int64, err error) {
+	tx, err := db.BeginTx(ctx, &TxOptions{ReadOnly: true})
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	idx, err := tx.GetIndex(indexName)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tb, err := tx.GetTable(idx.Opts.TableName)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	it := tb.Store.Iterator(engine.IteratorOptions{})
+	defer it.Close()
+
+	for it.Seek(nil); it.Valid(); it.Next() {
+		if idx.covers(it.Item().Key()) {
+			done++
+		}
+		total++
+	}
+
+	return done, total, it.Err()
+}
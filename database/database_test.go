@@ -1,10 +1,19 @@
 package database_test
 
 import (
+	"bytes"
+	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/document/encoding/msgpack"
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/genjidb/genji/index"
 	"github.com/stretchr/testify/require"
 )
 
@@ -67,3 +76,264 @@ func TestConcurrentTransactionManagement(t *testing.T) {
 		t.Fatal("deadlock")
 	}
 }
+
+func TestDatabaseBuildIndex(t *testing.T) {
+	newDB := func(t *testing.T, unique bool) (*database.Database, [][]byte) {
+		db, err := database.New(context.Background(), memoryengine.NewEngine(), database.Options{
+			Codec: msgpack.NewCodec(),
+		})
+		require.NoError(t, err)
+
+		tx, err := db.Begin(true)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		err = tx.CreateTable("test", nil)
+		require.NoError(t, err)
+		tb, err := tx.GetTable("test")
+		require.NoError(t, err)
+
+		var keys [][]byte
+		for i := int64(0); i < 10; i++ {
+			key, err := tb.Insert(document.NewFieldBuffer().Add("a", document.NewIntegerValue(i%5)))
+			require.NoError(t, err)
+			keys = append(keys, key)
+		}
+
+		err = tx.CreateIndex(database.IndexConfig{
+			IndexName: "idx", TableName: "test", Path: parsePath(t, "a"), Unique: unique, Building: true,
+		})
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		return db, keys
+	}
+
+	t.Run("Should resume an interrupted build", func(t *testing.T) {
+		db, keys := newDB(t, false)
+
+		// interrupt the build after the first chunk
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls [][2]int64
+		err := db.BuildIndex(ctx, "idx", 3, func(done, total int64) {
+			calls = append(calls, [2]int64{done, total})
+			cancel()
+		})
+		require.Equal(t, context.Canceled, err)
+		require.Equal(t, [][2]int64{{3, 10}}, calls)
+
+		// write documents on both sides of the last indexed document
+		tx, err := db.Begin(true)
+		require.NoError(t, err)
+		idx, err := tx.GetIndex("idx")
+		require.NoError(t, err)
+		require.True(t, idx.Opts.Building)
+		require.Equal(t, keys[2], idx.Opts.BuildKey)
+
+		tb, err := tx.GetTable("test")
+		require.NoError(t, err)
+		indexes, err := tb.Indexes()
+		require.NoError(t, err)
+		require.Empty(t, indexes)
+
+		require.NoError(t, tb.Delete(keys[0]))
+		require.NoError(t, tb.Replace(keys[1], document.NewFieldBuffer().Add("a", document.NewIntegerValue(20))))
+		require.NoError(t, tb.Replace(keys[5], document.NewFieldBuffer().Add("a", document.NewIntegerValue(-1))))
+		_, err = tb.Insert(document.NewFieldBuffer().Add("a", document.NewIntegerValue(10)))
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		calls = nil
+		err = db.BuildIndex(context.Background(), "idx", 3, func(done, total int64) {
+			calls = append(calls, [2]int64{done, total})
+		})
+		require.NoError(t, err)
+		require.Equal(t, [][2]int64{{5, 10}, {8, 10}, {10, 10}}, calls)
+
+		// the index must contain every document exactly once, sorted by value
+		tx, err = db.Begin(false)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		tb, err = tx.GetTable("test")
+		require.NoError(t, err)
+		indexes, err = tb.Indexes()
+		require.NoError(t, err)
+		require.Len(t, indexes, 1)
+
+		idx, err = tx.GetIndex("idx")
+		require.NoError(t, err)
+		require.False(t, idx.Opts.Building)
+
+		var values []int64
+		err = idx.AscendGreaterOrEqual(document.Value{}, func(_, k []byte, _ bool) error {
+			d, err := tb.GetDocument(k)
+			if err != nil {
+				return err
+			}
+			v, err := d.GetByField("a")
+			if err != nil {
+				return err
+			}
+			v, err = v.CastAsInteger()
+			if err != nil {
+				return err
+			}
+			values = append(values, v.V.(int64))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{-1, 1, 2, 2, 3, 3, 4, 4, 10, 20}, values)
+	})
+
+	t.Run("Should fail on duplicates of a unique index", func(t *testing.T) {
+		db, _ := newDB(t, true)
+
+		err := db.BuildIndex(context.Background(), "idx", 3, nil)
+		require.Equal(t, database.ErrDuplicateDocument, err)
+	})
+}
+
+func TestDatabaseCheckIndexes(t *testing.T) {
+	// newDB returns a database with an index whose content was removed,
+	// and whose recorded metadata is replaced by meta, or removed if nil.
+	newDB := func(t *testing.T, meta document.Document) *database.Database {
+		ng := memoryengine.NewEngine()
+		db, err := database.New(context.Background(), ng, database.Options{
+			Codec: msgpack.NewCodec(),
+		})
+		require.NoError(t, err)
+
+		tx, err := db.Begin(true)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		err = tx.CreateTable("test", nil)
+		require.NoError(t, err)
+		tb, err := tx.GetTable("test")
+		require.NoError(t, err)
+		for i := int64(0); i < 3; i++ {
+			_, err := tb.Insert(document.NewFieldBuffer().Add("a", document.NewIntegerValue(i)))
+			require.NoError(t, err)
+		}
+		err = tx.CreateIndex(database.IndexConfig{IndexName: "idx", TableName: "test", Path: parsePath(t, "a")})
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		etx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		defer etx.Rollback()
+
+		err = index.New(etx, "idx", index.Options{}).Truncate()
+		require.NoError(t, err)
+
+		if meta == nil {
+			err = etx.DropStore([]byte("__genji_meta"))
+			require.NoError(t, err)
+		} else {
+			st, err := etx.GetStore([]byte("__genji_meta"))
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = msgpack.NewCodec().NewEncoder(&buf).EncodeDocument(meta)
+			require.NoError(t, err)
+			err = st.Put([]byte("meta"), buf.Bytes())
+			require.NoError(t, err)
+		}
+		require.NoError(t, etx.Commit())
+
+		return db
+	}
+
+	countIndexed := func(t *testing.T, db *database.Database) int {
+		tx, err := db.Begin(false)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		idx, err := tx.GetIndex("idx")
+		require.NoError(t, err)
+
+		var n int
+		err = idx.AscendGreaterOrEqual(document.Value{}, func(_, _ []byte, _ bool) error {
+			n++
+			return nil
+		})
+		require.NoError(t, err)
+		return n
+	}
+
+	meta := func(version int64, caseInsensitive bool) document.Document {
+		return document.NewFieldBuffer().
+			Add("index_format", document.NewIntegerValue(version)).
+			Add("case_insensitive_fields", document.NewBoolValue(caseInsensitive))
+	}
+
+	tests := []struct {
+		name    string
+		meta    document.Document
+		rebuilt bool
+	}{
+		{"No recorded format", nil, true},
+		{"Older format", meta(1, false), true},
+		{"Other case sensitivity", meta(database.IndexFormatVersion, true), true},
+		{"Current format", meta(database.IndexFormatVersion, false), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := newDB(t, test.meta)
+
+			err := db.CheckIndexes(context.Background())
+			require.NoError(t, err)
+			if test.rebuilt {
+				require.Equal(t, 3, countIndexed(t, db))
+			} else {
+				require.Equal(t, 0, countIndexed(t, db))
+			}
+
+			// the current format is now recorded
+			tx, err := db.Begin(true)
+			require.NoError(t, err)
+			defer tx.Rollback()
+			idx, err := tx.GetIndex("idx")
+			require.NoError(t, err)
+			require.NoError(t, idx.Truncate())
+			require.NoError(t, tx.Commit())
+
+			err = db.CheckIndexes(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 0, countIndexed(t, db))
+		})
+	}
+}
+
+func TestConcurrentInsert(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE test")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 10; j++ {
+				err := db.Exec("INSERT INTO test (a, b) VALUES (?, ?)", i, j)
+				require.NoError(t, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	d, err := db.QueryDocument("SELECT COUNT(*) AS n, SUM(a) AS a, SUM(b) AS b FROM test")
+	require.NoError(t, err)
+	var res struct{ N, A, B int }
+	require.NoError(t, document.StructScan(d, &res))
+	require.Equal(t, 100, res.N)
+	require.Equal(t, 450, res.A)
+	require.Equal(t, 450, res.B)
+}
@@ -2,6 +2,9 @@ package database
 
 import (
 	"errors"
+	"fmt"
+
+	"github.com/genjidb/genji/document"
 )
 
 var (
@@ -19,10 +22,91 @@ var (
 	// same name as an existing one.
 	ErrIndexAlreadyExists = errors.New("index already exists")
 
+	// ErrTriggerNotFound is returned when the targeted trigger doesn't exist.
+	ErrTriggerNotFound = errors.New("trigger not found")
+
+	// ErrTriggerAlreadyExists is returned when attempting to create a trigger with the
+	// same name as an existing one.
+	ErrTriggerAlreadyExists = errors.New("trigger already exists")
+
 	// ErrDocumentNotFound is returned when no document is associated with the provided key.
 	ErrDocumentNotFound = errors.New("document not found")
 
 	// ErrDuplicateDocument is returned when another document is already associated with a given key, primary key,
 	// or if there is a unique index violation.
 	ErrDuplicateDocument = errors.New("duplicate document")
+
+	// ErrMemoryBudgetExceeded is returned when a statement needs more memory than its budget
+	// and no spill engine is configured.
+	ErrMemoryBudgetExceeded = errors.New("memory budget exceeded")
+)
+
+// ConstraintViolationKind is the kind of constraint violated by a document.
+type ConstraintViolationKind int
+
+const (
+	// NotNullViolation is a null or missing value on a field declared NOT NULL.
+	NotNullViolation ConstraintViolationKind = iota + 1
+	// TypeViolation is a value that cannot be converted to the type of its field.
+	TypeViolation
+	// PrimaryKeyViolation is a null or missing primary key.
+	PrimaryKeyViolation
+	// GeneratedViolation is a value written to a generated field.
+	GeneratedViolation
 )
+
+func (k ConstraintViolationKind) String() string {
+	switch k {
+	case NotNullViolation:
+		return "NOT NULL"
+	case TypeViolation:
+		return "TYPE"
+	case PrimaryKeyViolation:
+		return "PRIMARY KEY"
+	case GeneratedViolation:
+		return "GENERATED"
+	}
+
+	return "UNKNOWN"
+}
+
+// ConstraintViolationError is returned when a document doesn't satisfy
+// the field constraints of its table.
+type ConstraintViolationError struct {
+	Kind ConstraintViolationKind
+	// Table is the name of the table, if known.
+	Table string
+	// Path of the field that violates the constraint.
+	Path document.Path
+	// Value of the field. Its type is zero if the field is missing.
+	Value document.Value
+	// Constraint is the definition of the violated constraint,
+	// e.g. "NOT NULL", "PRIMARY KEY", "GENERATED" or the name of the expected type.
+	Constraint string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (e *ConstraintViolationError) Error() string {
+	switch e.Kind {
+	case NotNullViolation:
+		return fmt.Sprintf("field %q is required and must be not null", e.Path)
+	case PrimaryKeyViolation:
+		if e.Value.Type == 0 {
+			return fmt.Sprintf("missing primary key at path %q", e.Path)
+		}
+		return fmt.Sprintf("primary key at path %q must be not null", e.Path)
+	case GeneratedViolation:
+		return fmt.Sprintf("cannot write to generated field %q", e.Path)
+	}
+
+	if e.Err != nil {
+		return fmt.Sprintf("field %q: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("field %q violates constraint %s", e.Path, e.Constraint)
+}
+
+// Unwrap returns the underlying error.
+func (e *ConstraintViolationError) Unwrap() error {
+	return e.Err
+}
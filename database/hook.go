@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// A TransactionHook is a set of functions called at the steps of the lifecycle
+// of read/write transactions. Any of them can be nil.
+// Panics in the functions are recovered: a panic in BeforeCommit aborts the transaction
+// like an error does, and panics and errors in the other functions are logged,
+// the remaining hooks being called anyway.
+type TransactionHook struct {
+	// BeforeCommit is called before committing the transaction, which can still be used.
+	// If it returns an error, the transaction is rolled back and Commit returns that error.
+	BeforeCommit func(tx *Transaction) error
+	// AfterCommit is called once the transaction is committed with a read-only transaction
+	// started right after the commit, using the values of the context of the committed transaction.
+	AfterCommit func(tx *Transaction) error
+	// BeforeRollback is called before rolling back the transaction, which can still be used.
+	BeforeRollback func(tx *Transaction) error
+	// AfterRollback is called once the transaction is rolled back.
+	AfterRollback func(tx *Transaction) error
+}
+
+// runBeforeCommitHooks calls the BeforeCommit hooks in order, until one of them fails.
+func (db *Database) runBeforeCommitHooks(tx *Transaction) error {
+	for _, h := range db.TransactionHooks {
+		if h.BeforeCommit == nil {
+			continue
+		}
+
+		err := callHook(h.BeforeCommit, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runAfterCommitHooks calls the AfterCommit hooks in order with a read-only transaction.
+func (db *Database) runAfterCommitHooks(committed *Transaction) {
+	var tx *Transaction
+
+	for _, h := range db.TransactionHooks {
+		if h.AfterCommit == nil {
+			continue
+		}
+
+		if tx == nil {
+			var err error
+			tx, err = db.BeginTx(valueContext{committed.ctx}, &TxOptions{ReadOnly: true})
+			if err != nil {
+				log.Printf("genji: AfterCommit hook: %v", err)
+				return
+			}
+			defer tx.Rollback()
+		}
+
+		logHookError("AfterCommit", callHook(h.AfterCommit, tx))
+	}
+}
+
+// runRollbackHooks calls either the BeforeRollback or the AfterRollback hooks in order.
+func (db *Database) runRollbackHooks(tx *Transaction, after bool) {
+	name := "BeforeRollback"
+	if after {
+		name = "AfterRollback"
+	}
+
+	for _, h := range db.TransactionHooks {
+		fn := h.BeforeRollback
+		if after {
+			fn = h.AfterRollback
+		}
+
+		if fn != nil {
+			logHookError(name, callHook(fn, tx))
+		}
+	}
+}
+
+// callHook calls fn and turns a panic into an error.
+func callHook(fn func(tx *Transaction) error, tx *Transaction) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return fn(tx)
+}
+
+func logHookError(name string, err error) {
+	if err != nil {
+		log.Printf("genji: %s hook: %v", name, err)
+	}
+}
+
+// valueContext returns the values of its context but is never done.
+type valueContext struct {
+	context.Context
+}
+
+func (valueContext) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (valueContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (valueContext) Err() error {
+	return nil
+}
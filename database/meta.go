@@ -0,0 +1,138 @@
+package database
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+)
+
+var metaStoreName = internalPrefix + "meta"
+
+// key under which the metadata document is stored in the meta store.
+var metaKey = []byte("meta")
+
+// IndexFormatVersion is the version of the encoding of the values of the indexes.
+// It is recorded in the database when the first index is created, and CheckIndexes
+// rebuilds the indexes of databases recorded with another version.
+//   1: initial format, used by databases which didn't record a version
+//   2: document fields are ordered by name, and prefixes of arrays and documents
+//      are ordered before them
+const IndexFormatVersion = 2
+
+// CheckIndexes rebuilds all the indexes of the database if they were built
+// with another format than IndexFormatVersion, or with another CaseInsensitiveFields
+// setting than the one of db, which changes the indexed values, and records
+// the current format and setting.
+// Databases with indexes but without any recorded format are considered to use
+// the first version of the format.
+// If the indexes are up to date, CheckIndexes doesn't write anything, which allows
+// opening databases on top of read-only engines.
+func (db *Database) CheckIndexes(ctx context.Context) error {
+	tx, err := db.BeginTx(ctx, &TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+
+	ok, err := tx.indexesUpToDate()
+	tx.Rollback()
+	if err != nil || ok {
+		return err
+	}
+
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.ReIndexAll()
+	if err != nil {
+		return err
+	}
+
+	err = tx.writeMeta()
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// indexesUpToDate returns whether the indexes were built with the current format and settings.
+func (tx *Transaction) indexesUpToDate() (bool, error) {
+	d, err := tx.readMeta()
+	if err != nil {
+		return false, err
+	}
+
+	if d == nil {
+		indexes, err := tx.allIndexNames()
+		return len(indexes) == 0, err
+	}
+
+	v, err := d.GetByField("index_format")
+	if err != nil {
+		return false, err
+	}
+	if v.V.(int64) != IndexFormatVersion {
+		return false, nil
+	}
+
+	v, err = d.GetByField("case_insensitive_fields")
+	if err != nil {
+		return false, err
+	}
+
+	return v.V.(bool) == tx.db.CaseInsensitiveFields, nil
+}
+
+// readMeta returns the metadata document, or nil if it was never recorded.
+func (tx *Transaction) readMeta() (document.Document, error) {
+	st, err := tx.tx.GetStore([]byte(metaStoreName))
+	if err == engine.ErrStoreNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := st.Get(metaKey)
+	if err == engine.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.db.Codec.NewDocument(v), nil
+}
+
+// writeMeta records the current metadata of the database.
+// The store is created on first use.
+func (tx *Transaction) writeMeta() error {
+	st, err := tx.tx.GetStore([]byte(metaStoreName))
+	if err == engine.ErrStoreNotFound {
+		err = tx.tx.CreateStore([]byte(metaStoreName))
+		if err != nil {
+			return err
+		}
+		st, err = tx.tx.GetStore([]byte(metaStoreName))
+	}
+	if err != nil {
+		return err
+	}
+
+	fb := document.NewFieldBuffer().
+		Add("index_format", document.NewIntegerValue(IndexFormatVersion)).
+		Add("case_insensitive_fields", document.NewBoolValue(tx.db.CaseInsensitiveFields))
+
+	var buf bytes.Buffer
+	err = tx.db.Codec.NewEncoder(&buf).EncodeDocument(fb)
+	if err != nil {
+		return err
+	}
+
+	return st.Put(metaKey, buf.Bytes())
+}
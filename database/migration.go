@@ -0,0 +1,60 @@
+package database
+
+import (
+	"bytes"
+
+	"github.com/genjidb/genji/binarysort"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+)
+
+var migrationStoreName = internalPrefix + "migrations"
+
+// MigrationVersion returns the version of the last migration recorded
+// by SetMigrationVersion, or 0 if no migration was ever recorded.
+func (tx *Transaction) MigrationVersion() (int64, error) {
+	st, err := tx.tx.GetStore([]byte(migrationStoreName))
+	if err == engine.ErrStoreNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	it := st.Iterator(engine.IteratorOptions{Reverse: true})
+	defer it.Close()
+
+	it.Seek(nil)
+	if !it.Valid() {
+		return 0, it.Err()
+	}
+
+	return binarysort.DecodeInt64(it.Item().Key())
+}
+
+// SetMigrationVersion records in the migrations store that the migration
+// with the given version was applied.
+// The store is created on first use.
+func (tx *Transaction) SetMigrationVersion(version int64) error {
+	st, err := tx.tx.GetStore([]byte(migrationStoreName))
+	if err == engine.ErrStoreNotFound {
+		err = tx.tx.CreateStore([]byte(migrationStoreName))
+		if err != nil {
+			return err
+		}
+		st, err = tx.tx.GetStore([]byte(migrationStoreName))
+	}
+	if err != nil {
+		return err
+	}
+
+	fb := document.NewFieldBuffer().Add("version", document.NewIntegerValue(version))
+
+	var buf bytes.Buffer
+	err = tx.db.Codec.NewEncoder(&buf).EncodeDocument(fb)
+	if err != nil {
+		return err
+	}
+
+	return st.Put(binarysort.AppendInt64(nil, version), buf.Bytes())
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"io"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/readonly"
+	"github.com/genjidb/genji/index"
+)
+
+// WriteReadOnly writes every store of the database to w,
+// in the format expected by the read-only engine.
+func (tx *Transaction) WriteReadOnly(w io.Writer) error {
+	names := [][]byte{
+		[]byte(tableInfoStoreName),
+		[]byte(indexStoreName),
+	}
+
+	_, err := tx.tx.GetStore([]byte(metaStoreName))
+	if err == nil {
+		names = append(names, []byte(metaStoreName))
+	} else if err != engine.ErrStoreNotFound {
+		return err
+	}
+
+	it := tx.tableInfoStore.st.Iterator(engine.IteratorOptions{})
+	defer it.Close()
+
+	var buf []byte
+	for it.Seek(nil); it.Valid(); it.Next() {
+		buf, err = it.Item().ValueCopy(buf)
+		if err != nil {
+			return err
+		}
+
+		var ti TableInfo
+		err = ti.ScanDocument(tx.db.Codec.NewDocument(buf))
+		if err != nil {
+			return err
+		}
+
+		names = append(names, ti.storeName)
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	indexes, err := tx.allIndexNames()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range indexes {
+		names = append(names, index.New(tx.tx, name, index.Options{}).StoreName())
+	}
+
+	return readonly.Write(w, tx.tx, names)
+}
@@ -18,6 +18,9 @@ type Table struct {
 	Store     engine.Store
 	name      string
 	infoStore *tableInfoStore
+
+	// triggers of the table, loaded on first use
+	triggers []*TriggerConfig
 }
 
 // Tx returns the current transaction.
@@ -35,8 +38,30 @@ func (t *Table) Name() string {
 	return t.name
 }
 
-// Truncate deletes all the documents from the table.
+// Truncate deletes all the documents from the table and empties its indexes.
+// Triggers are not fired.
 func (t *Table) Truncate() error {
+	info, err := t.Info()
+	if err != nil {
+		return err
+	}
+
+	if info.readOnly {
+		return errors.New("cannot write to read-only table")
+	}
+
+	indexes, err := t.allIndexes()
+	if err != nil {
+		return err
+	}
+
+	for _, idx := range indexes {
+		err = idx.Truncate()
+		if err != nil {
+			return err
+		}
+	}
+
 	return t.Store.Truncate()
 }
 
@@ -50,58 +75,200 @@ func (t *Table) Insert(d document.Document) ([]byte, error) {
 		return nil, err
 	}
 
-	if info.readOnly {
-		return nil, errors.New("cannot write to read-only table")
+	triggers, err := t.tableTriggers(TriggerInsert)
+	if err != nil {
+		return nil, err
 	}
 
-	d, err = info.FieldConstraints.ValidateDocument(d)
+	key, d, err := t.storeDocument(info, triggers, d)
 	if err != nil {
 		return nil, err
 	}
 
-	key, err := t.generateKey(d)
+	indexes, err := t.indexesFor(key)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = t.Store.Get(key)
-	if err == nil {
-		return nil, ErrDuplicateDocument
+	for _, idx := range indexes {
+		v, err := t.indexedValue(idx.Opts.Path, d)
+		if err != nil {
+			v = document.NewNullValue()
+		}
+
+		err = idx.Set(v, key)
+		if err != nil {
+			if err == index.ErrDuplicate {
+				return nil, ErrDuplicateDocument
+			}
+
+			return nil, err
+		}
+	}
+
+	err = t.fireTriggers(triggers, TriggerAfter, nil, d)
+	if err != nil {
+		return nil, err
 	}
 
-	var buf bytes.Buffer
-	err = t.tx.db.Codec.NewEncoder(&buf).EncodeDocument(d)
+	return key, nil
+}
+
+// BulkInsert inserts the documents into the table like Insert and returns their keys.
+// Instead of being written for each document, index entries are buffered and written
+// in sorted batches once all the documents are stored.
+// Uniqueness is still checked for each document, taking the buffered entries into account.
+// If the table has insert triggers, entries are written after each document
+// so that the triggers see up-to-date indexes.
+// If a document cannot be inserted, the documents stored before it are indexed
+// before returning the error.
+func (t *Table) BulkInsert(docs []document.Document) ([][]byte, error) {
+	info, err := t.Info()
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode document: %w", err)
+		return nil, err
 	}
 
-	err = t.Store.Put(key, buf.Bytes())
+	triggers, err := t.tableTriggers(TriggerInsert)
 	if err != nil {
 		return nil, err
 	}
 
-	indexes, err := t.Indexes()
+	indexes, err := t.allIndexes()
 	if err != nil {
 		return nil, err
 	}
 
+	configs := make([]Index, 0, len(indexes))
+	batches := make([]*index.Batch, 0, len(indexes))
 	for _, idx := range indexes {
-		v, err := idx.Opts.Path.GetValue(d)
+		b, err := idx.NewBatch()
 		if err != nil {
-			v = document.NewNullValue()
+			return nil, err
 		}
 
-		err = idx.Set(v, key)
+		configs = append(configs, idx)
+		batches = append(batches, b)
+	}
+
+	flush := func() error {
+		for _, b := range batches {
+			err := b.Flush()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	insert := func(d document.Document) ([]byte, error) {
+		key, d, err := t.storeDocument(info, triggers, d)
 		if err != nil {
-			if err == index.ErrDuplicate {
-				return nil, ErrDuplicateDocument
+			return nil, err
+		}
+
+		for i, b := range batches {
+			if !configs[i].covers(key) {
+				continue
+			}
+
+			v, err := t.indexedValue(configs[i].Opts.Path, d)
+			if err != nil {
+				v = document.NewNullValue()
+			}
+
+			err = b.Set(v, key)
+			if err != nil {
+				if err == index.ErrDuplicate {
+					return nil, ErrDuplicateDocument
+				}
+
+				return nil, err
+			}
+		}
+
+		if len(triggers) > 0 {
+			err = flush()
+			if err != nil {
+				return nil, err
+			}
+
+			err = t.fireTriggers(triggers, TriggerAfter, nil, d)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return key, nil
+	}
+
+	keys := make([][]byte, 0, len(docs))
+	for _, d := range docs {
+		key, err := insert(d)
+		if err != nil {
+			// the documents stored before the error are kept, as with successive
+			// calls to Insert, so their buffered index entries must be written too.
+			if ferr := flush(); ferr != nil {
+				return nil, ferr
 			}
 
 			return nil, err
 		}
+
+		keys = append(keys, key)
 	}
 
-	return key, nil
+	err = flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// storeDocument validates d, fires the BEFORE triggers and stores the document
+// without indexing it. It returns the key of the document and its validated content.
+func (t *Table) storeDocument(info *TableInfo, triggers []*TriggerConfig, d document.Document) ([]byte, document.Document, error) {
+	if info.readOnly {
+		return nil, nil, errors.New("cannot write to read-only table")
+	}
+
+	err := info.FieldConstraints.checkGeneratedFields(d)
+	if err != nil {
+		return nil, nil, t.withTableName(err)
+	}
+
+	d, err = t.validateDocument(info, d)
+	if err != nil {
+		return nil, nil, t.withTableName(err)
+	}
+
+	err = t.fireTriggers(triggers, TriggerBefore, nil, d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := t.generateKey(d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = t.Store.Get(key)
+	if err == nil {
+		return nil, nil, ErrDuplicateDocument
+	}
+
+	data, err := t.tx.db.encodeDocument(d)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+
+	err = t.Store.Put(key, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, d, nil
 }
 
 // Delete a document by key.
@@ -121,13 +288,30 @@ func (t *Table) Delete(key []byte) error {
 		return err
 	}
 
-	indexes, err := t.Indexes()
+	triggers, err := t.tableTriggers(TriggerDelete)
+	if err != nil {
+		return err
+	}
+
+	if len(triggers) > 0 {
+		d, err = copyDocument(d)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = t.fireTriggers(triggers, TriggerBefore, d, nil)
+	if err != nil {
+		return err
+	}
+
+	indexes, err := t.indexesFor(key)
 	if err != nil {
 		return err
 	}
 
 	for _, idx := range indexes {
-		v, err := idx.Opts.Path.GetValue(d)
+		v, err := t.indexedValue(idx.Opts.Path, d)
 		if err != nil {
 			return err
 		}
@@ -138,13 +322,30 @@ func (t *Table) Delete(key []byte) error {
 		}
 	}
 
-	return t.Store.Delete(key)
+	err = t.Store.Delete(key)
+	if err != nil {
+		return err
+	}
+
+	return t.fireTriggers(triggers, TriggerAfter, d, nil)
 }
 
 // Replace a document by key.
 // An error is returned if the key doesn't exist.
 // Indexes are automatically updated.
 func (t *Table) Replace(key []byte, d document.Document) error {
+	return t.ReplaceChanged(key, d, nil)
+}
+
+// ReplaceChanged replaces a document by key, knowing that d was created
+// by modifying the values at the given paths of the stored document.
+// Indexes whose path doesn't overlap any of these paths are not updated,
+// unless the table constraints may modify the indexed value.
+// If paths is nil, every index is considered.
+// In any case, indexes whose value didn't change are left untouched.
+// Generated fields are computed again, and an error is returned if one of the paths
+// is a generated field.
+func (t *Table) ReplaceChanged(key []byte, d document.Document, paths []document.Path) error {
 	info, err := t.Info()
 	if err != nil {
 		return err
@@ -154,17 +355,113 @@ func (t *Table) Replace(key []byte, d document.Document) error {
 		return errors.New("cannot write to read-only table")
 	}
 
-	d, err = info.FieldConstraints.ValidateDocument(d)
+	for _, p := range paths {
+		if fc := info.FieldConstraints.generatedField(p); fc != nil {
+			return t.withTableName(&ConstraintViolationError{
+				Kind:       GeneratedViolation,
+				Path:       fc.Path,
+				Constraint: "GENERATED",
+			})
+		}
+	}
+
+	d, err = t.validateDocument(info, d)
+	if err != nil {
+		return t.withTableName(err)
+	}
+
+	triggers, err := t.tableTriggers(TriggerUpdate)
+	if err != nil {
+		return err
+	}
+
+	var old document.Document
+	if len(triggers) > 0 {
+		old, err = t.GetDocument(key)
+		if err != nil {
+			return err
+		}
+
+		old, err = copyDocument(old)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = t.fireTriggers(triggers, TriggerBefore, old, d)
+	if err != nil {
+		return err
+	}
+
+	indexes, err := t.indexesFor(key)
 	if err != nil {
 		return err
 	}
 
-	indexes, err := t.Indexes()
+	// paths are compared as is, they can't be used if the case of the fields may differ.
+	if paths != nil && !t.tx.db.CaseInsensitiveFields {
+		for name, idx := range indexes {
+			if !overlapsAny(idx.Opts.Path, paths) && !info.FieldConstraints.overlap(idx.Opts.Path) {
+				delete(indexes, name)
+			}
+		}
+	}
+
+	err = t.replace(indexes, key, d)
 	if err != nil {
 		return err
 	}
 
-	return t.replace(indexes, key, d)
+	return t.fireTriggers(triggers, TriggerAfter, old, d)
+}
+
+// validateDocument validates d against the field constraints of the table
+// then computes its generated fields, in the order of their declaration.
+// A generated field can depend on the fields generated before it.
+func (t *Table) validateDocument(info *TableInfo, d document.Document) (document.Document, error) {
+	fb, err := info.FieldConstraints.ValidateDocument(d)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range info.FieldConstraints {
+		fc := &info.FieldConstraints[i]
+		if !fc.IsGenerated() {
+			continue
+		}
+
+		if t.tx.db.EvalGeneratedField == nil {
+			return nil, fmt.Errorf("cannot compute field %q: generated fields are not supported by this database", fc.Path)
+		}
+
+		v, err := t.tx.db.EvalGeneratedField(t.tx, fc, fb)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compute field %q: %w", fc.Path, err)
+		}
+
+		v, err = fc.convertGenerated(v)
+		if err != nil {
+			return nil, err
+		}
+
+		err = fb.Set(fc.Path, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fb, nil
+}
+
+// overlapsAny returns true if p overlaps any of the given paths.
+func overlapsAny(p document.Path, paths []document.Path) bool {
+	for _, other := range paths {
+		if p.Overlaps(other) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (t *Table) replace(indexes map[string]Index, key []byte, d document.Document) error {
@@ -174,40 +471,54 @@ func (t *Table) replace(indexes map[string]Index, key []byte, d document.Documen
 		return err
 	}
 
-	// remove key from indexes
+	// remove key from the indexes whose value changed
+	changed := make([]Index, 0, len(indexes))
+	values := make([]document.Value, 0, len(indexes))
 	for _, idx := range indexes {
-		v, err := idx.Opts.Path.GetValue(old)
+		oldV, err := t.indexedValue(idx.Opts.Path, old)
 		if err != nil {
 			return err
 		}
 
-		err = idx.Delete(v, key)
+		v, err := t.indexedValue(idx.Opts.Path, d)
 		if err != nil {
 			return err
 		}
+
+		if oldV.Type == v.Type {
+			ok, err := oldV.IsEqual(v)
+			if err != nil {
+				return err
+			}
+			if ok {
+				continue
+			}
+		}
+
+		err = idx.Delete(oldV, key)
+		if err != nil {
+			return err
+		}
+
+		changed = append(changed, idx)
+		values = append(values, v)
 	}
 
 	// encode new document
-	var buf bytes.Buffer
-	err = t.tx.db.Codec.NewEncoder(&buf).EncodeDocument(d)
+	data, err := t.tx.db.encodeDocument(d)
 	if err != nil {
 		return fmt.Errorf("failed to encode document: %w", err)
 	}
 
 	// replace old document with new document
-	err = t.Store.Put(key, buf.Bytes())
+	err = t.Store.Put(key, data)
 	if err != nil {
 		return err
 	}
 
 	// update indexes
-	for _, idx := range indexes {
-		v, err := idx.Opts.Path.GetValue(d)
-		if err != nil {
-			continue
-		}
-
-		err = idx.Set(v, key)
+	for i, idx := range changed {
+		err = idx.Set(values[i], key)
 		if err != nil {
 			return err
 		}
@@ -216,8 +527,58 @@ func (t *Table) replace(indexes map[string]Index, key []byte, d document.Documen
 	return err
 }
 
+// indexedValue returns the value of d at the indexed path p.
+// Documents where p doesn't resolve to a value are indexed as null.
+func (t *Table) indexedValue(p document.Path, d document.Document) (document.Value, error) {
+	if t.tx.db.CaseInsensitiveFields {
+		p = p.Fold(d)
+	}
+
+	v, err := p.GetValue(d)
+	if err == document.ErrFieldNotFound || err == document.ErrValueNotFound {
+		return document.NewNullValue(), nil
+	}
+
+	return v, err
+}
+
 // Indexes returns a map of all the indexes of a table.
+// Indexes that are still being built are not returned.
 func (t *Table) Indexes() (map[string]Index, error) {
+	all, err := t.allIndexes()
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make(map[string]Index, len(all))
+	for p, idx := range all {
+		if !idx.Opts.Building {
+			indexes[p] = idx
+		}
+	}
+
+	return indexes, nil
+}
+
+// indexesFor returns the indexes to update when writing the document stored under key.
+// It includes the indexes being built that already cover that key.
+func (t *Table) indexesFor(key []byte) (map[string]Index, error) {
+	all, err := t.allIndexes()
+	if err != nil {
+		return nil, err
+	}
+
+	for p, idx := range all {
+		if !idx.covers(key) {
+			delete(all, p)
+		}
+	}
+
+	return all, nil
+}
+
+// allIndexes returns a map of all the indexes of a table, including the ones being built.
+func (t *Table) allIndexes() (map[string]Index, error) {
 	s, err := t.tx.tx.GetStore([]byte(indexStoreName))
 	if err != nil {
 		return nil, err
@@ -286,6 +647,7 @@ type lazilyDecodedDocument struct {
 	item  engine.Item
 	buf   []byte
 	codec encoding.Codec
+	mask  document.FieldMask
 }
 
 func (d *lazilyDecodedDocument) GetByField(field string) (v document.Value, err error) {
@@ -293,7 +655,7 @@ func (d *lazilyDecodedDocument) GetByField(field string) (v document.Value, err
 		d.copyFromItem()
 	}
 
-	return d.codec.NewDocument(d.buf).GetByField(field)
+	return d.mask.Apply(d.codec.NewDocument(d.buf)).GetByField(field)
 }
 
 func (d *lazilyDecodedDocument) Iterate(fn func(field string, value document.Value) error) error {
@@ -301,7 +663,7 @@ func (d *lazilyDecodedDocument) Iterate(fn func(field string, value document.Val
 		d.copyFromItem()
 	}
 
-	return d.codec.NewDocument(d.buf).Iterate(fn)
+	return d.mask.Apply(d.codec.NewDocument(d.buf)).Iterate(fn)
 }
 
 func (d *lazilyDecodedDocument) Key() []byte {
@@ -323,10 +685,24 @@ func (d *lazilyDecodedDocument) copyFromItem() error {
 // Iterate goes through all the documents of the table and calls the given function by passing each one of them.
 // If the given function returns an error, the iteration stops.
 func (t *Table) Iterate(fn func(d document.Document) error) error {
+	return t.iterate(nil, fn)
+}
+
+// ScanWithMask returns an iterator over the documents of the table that only contain
+// the fields selected by mask. The other fields are never decoded.
+// Selected fields that don't exist in a document are ignored.
+func (t *Table) ScanWithMask(mask document.FieldMask) document.Iterator {
+	return document.IteratorFunc(func(fn func(d document.Document) error) error {
+		return t.iterate(mask, fn)
+	})
+}
+
+func (t *Table) iterate(mask document.FieldMask, fn func(d document.Document) error) error {
 	// To avoid unnecessary allocations, we create the struct once and reuse
 	// it during each iteration.
 	d := lazilyDecodedDocument{
 		codec: t.tx.db.Codec,
+		mask:  mask,
 	}
 
 	it := t.Store.Iterator(engine.IteratorOptions{})
@@ -367,6 +743,17 @@ func (t *Table) GetDocument(key []byte) (document.Document, error) {
 	return &d, err
 }
 
+// withTableName sets the name of the table on constraint violations
+// returned by the validation of a document.
+func (t *Table) withTableName(err error) error {
+	var cerr *ConstraintViolationError
+	if errors.As(err, &cerr) {
+		cerr.Table = t.name
+	}
+
+	return err
+}
+
 // generate a key for d based on the table configuration.
 // if the table has a primary key, it extracts the field from
 // the document, converts it to the targeted type and returns
@@ -382,11 +769,25 @@ func (t *Table) generateKey(d document.Document) ([]byte, error) {
 	if pk := ti.GetPrimaryKey(); pk != nil {
 		v, err := pk.Path.GetValue(d)
 		if err == document.ErrFieldNotFound {
-			return nil, fmt.Errorf("missing primary key at path %q", pk.Path)
+			return nil, &ConstraintViolationError{
+				Kind:       PrimaryKeyViolation,
+				Table:      t.name,
+				Path:       pk.Path,
+				Constraint: "PRIMARY KEY",
+			}
 		}
 		if err != nil {
 			return nil, err
 		}
+		if v.Type == document.NullValue {
+			return nil, &ConstraintViolationError{
+				Kind:       PrimaryKeyViolation,
+				Table:      t.name,
+				Path:       pk.Path,
+				Value:      v,
+				Constraint: "PRIMARY KEY",
+			}
+		}
 
 		// if a primary key type is specified,
 		// encode the key using the optimized encoding solution
@@ -425,7 +826,7 @@ func (t *Table) ReIndex() error {
 		return errors.New("cannot write to read-only table")
 	}
 
-	indexes, err := t.Indexes()
+	indexes, err := t.allIndexes()
 	if err != nil {
 		return err
 	}
@@ -1,11 +1,13 @@
 package database_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji/binarysort"
 	"github.com/genjidb/genji/database"
@@ -97,6 +99,58 @@ func TestTableIterate(t *testing.T) {
 	})
 }
 
+func TestTableScanWithMask(t *testing.T) {
+	tb, cleanup := newTestTable(t)
+	defer cleanup()
+
+	for i := 0; i < 5; i++ {
+		fb := document.NewFieldBuffer()
+		for j := 0; j < 20; j++ {
+			fb.Add(fmt.Sprintf("field%d", j), document.NewIntegerValue(int64(i*j)))
+		}
+		_, err := tb.Insert(fb)
+		require.NoError(t, err)
+	}
+
+	scan := func(mask document.FieldMask) []string {
+		var docs []string
+		err := tb.ScanWithMask(mask).Iterate(func(d document.Document) error {
+			require.NotEmpty(t, d.(document.Keyer).Key())
+
+			data, err := document.MarshalJSON(d)
+			if err != nil {
+				return err
+			}
+			docs = append(docs, string(data))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, docs, 5)
+		return docs
+	}
+
+	t.Run("With mask", func(t *testing.T) {
+		for i, d := range scan(document.NewFieldMask("field3", "field1")) {
+			require.JSONEq(t, fmt.Sprintf(`{"field3": %d, "field1": %d}`, i*3, i), d)
+		}
+	})
+
+	t.Run("Without mask", func(t *testing.T) {
+		for _, d := range scan(nil) {
+			var fb document.FieldBuffer
+			err := fb.UnmarshalJSON([]byte(d))
+			require.NoError(t, err)
+			require.Len(t, fb.Fields(), 20)
+		}
+	})
+
+	t.Run("With unknown field", func(t *testing.T) {
+		for i, d := range scan(document.NewFieldMask("foo", "field2")) {
+			require.JSONEq(t, fmt.Sprintf(`{"field2": %d}`, i*2), d)
+		}
+	})
+}
+
 // TestTableGetDocument verifies GetDocument behaviour.
 func TestTableGetDocument(t *testing.T) {
 	t.Run("Should fail if not found", func(t *testing.T) {
@@ -342,8 +396,8 @@ func TestTableInsert(t *testing.T) {
 
 		err := tx.CreateTable("test", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), document.IntegerValue, false, false, document.Value{}},
-				{parsePath(t, "bar"), document.IntegerValue, false, false, document.Value{}},
+				{parsePath(t, "foo"), document.IntegerValue, false, false, document.Value{}, ""},
+				{parsePath(t, "bar"), document.IntegerValue, false, false, document.Value{}, ""},
 			},
 		})
 		require.NoError(t, err)
@@ -383,7 +437,7 @@ func TestTableInsert(t *testing.T) {
 
 		err := tx.CreateTable("test", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), document.DoubleValue, false, false, document.Value{}},
+				{parsePath(t, "foo"), document.DoubleValue, false, false, document.Value{}, ""},
 			},
 		})
 		require.NoError(t, err)
@@ -405,7 +459,7 @@ func TestTableInsert(t *testing.T) {
 		// no enforced type, not null
 		err := tx.CreateTable("test1", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), 0, false, true, document.Value{}},
+				{parsePath(t, "foo"), 0, false, true, document.Value{}, ""},
 			},
 		})
 		require.NoError(t, err)
@@ -415,7 +469,7 @@ func TestTableInsert(t *testing.T) {
 		// enforced type, not null
 		err = tx.CreateTable("test2", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), document.IntegerValue, false, true, document.Value{}},
+				{parsePath(t, "foo"), document.IntegerValue, false, true, document.Value{}, ""},
 			},
 		})
 		require.NoError(t, err)
@@ -460,7 +514,7 @@ func TestTableInsert(t *testing.T) {
 		// no enforced type, not null
 		err := tx.CreateTable("test1", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), 0, false, true, document.NewIntegerValue(42)},
+				{parsePath(t, "foo"), 0, false, true, document.NewIntegerValue(42), ""},
 			},
 		})
 		require.NoError(t, err)
@@ -470,7 +524,7 @@ func TestTableInsert(t *testing.T) {
 		// enforced type, not null
 		err = tx.CreateTable("test2", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo"), document.IntegerValue, false, true, document.NewIntegerValue(42)},
+				{parsePath(t, "foo"), document.IntegerValue, false, true, document.NewIntegerValue(42), ""},
 			},
 		})
 		require.NoError(t, err)
@@ -526,7 +580,7 @@ func TestTableInsert(t *testing.T) {
 
 		err := tx.CreateTable("test1", &database.TableInfo{
 			FieldConstraints: []database.FieldConstraint{
-				{parsePath(t, "foo[1]"), 0, false, true, document.Value{}},
+				{parsePath(t, "foo[1]"), 0, false, true, document.Value{}, ""},
 			},
 		})
 		require.NoError(t, err)
@@ -544,6 +598,194 @@ func TestTableInsert(t *testing.T) {
 	})
 }
 
+func TestTableConstraintViolations(t *testing.T) {
+	tx, cleanup := newTestDB(t)
+	defer cleanup()
+
+	err := tx.CreateTable("test", &database.TableInfo{
+		FieldConstraints: []database.FieldConstraint{
+			{Path: parsePath(t, "id"), Type: document.IntegerValue, IsPrimaryKey: true},
+			{Path: parsePath(t, "a"), IsNotNull: true},
+			{Path: parsePath(t, "b.c"), Type: document.IntegerValue},
+		},
+	})
+	require.NoError(t, err)
+
+	tb, err := tx.GetTable("test")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		doc      string
+		expected database.ConstraintViolationError
+	}{
+		{"Not null / missing", `{"id": 1}`, database.ConstraintViolationError{
+			Kind: database.NotNullViolation, Table: "test", Path: parsePath(t, "a"), Constraint: "NOT NULL",
+		}},
+		{"Not null / null", `{"id": 1, "a": null}`, database.ConstraintViolationError{
+			Kind: database.NotNullViolation, Table: "test", Path: parsePath(t, "a"), Value: document.NewNullValue(), Constraint: "NOT NULL",
+		}},
+		{"Type", `{"id": 1, "a": 1, "b": {"c": "foo"}}`, database.ConstraintViolationError{
+			Kind: database.TypeViolation, Table: "test", Path: parsePath(t, "b.c"), Value: document.NewTextValue("foo"), Constraint: "integer",
+		}},
+		{"Primary key / missing", `{"a": 1}`, database.ConstraintViolationError{
+			Kind: database.PrimaryKeyViolation, Table: "test", Path: parsePath(t, "id"), Constraint: "PRIMARY KEY",
+		}},
+		{"Primary key / null", `{"id": null, "a": 1}`, database.ConstraintViolationError{
+			Kind: database.PrimaryKeyViolation, Table: "test", Path: parsePath(t, "id"), Value: document.NewNullValue(), Constraint: "PRIMARY KEY",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := tb.Insert(document.NewFromJSON([]byte(test.doc)))
+
+			var cerr *database.ConstraintViolationError
+			require.True(t, errors.As(err, &cerr), "got %v", err)
+			require.Equal(t, test.expected.Kind, cerr.Kind)
+			require.Equal(t, test.expected.Table, cerr.Table)
+			require.Equal(t, test.expected.Path, cerr.Path)
+			require.Equal(t, test.expected.Value, cerr.Value)
+			require.Equal(t, test.expected.Constraint, cerr.Constraint)
+		})
+	}
+
+	t.Run("Replace", func(t *testing.T) {
+		key, err := tb.Insert(document.NewFromJSON([]byte(`{"id": 1, "a": 1}`)))
+		require.NoError(t, err)
+
+		err = tb.Replace(key, document.NewFromJSON([]byte(`{"id": 1}`)))
+		var cerr *database.ConstraintViolationError
+		require.True(t, errors.As(err, &cerr), "got %v", err)
+		require.Equal(t, database.NotNullViolation, cerr.Kind)
+		require.Equal(t, "test", cerr.Table)
+		require.EqualError(t, err, `field "a" is required and must be not null`)
+	})
+}
+
+func TestTableBulkInsert(t *testing.T) {
+	newIndexedTable := func(t *testing.T) (*database.Transaction, *database.Table, func()) {
+		tx, cleanup := newTestDB(t)
+
+		err := tx.CreateTable("test", nil)
+		require.NoError(t, err)
+		err = tx.CreateIndex(database.IndexConfig{
+			IndexName: "idxFoo", TableName: "test", Path: parsePath(t, "foo"), Unique: true,
+		})
+		require.NoError(t, err)
+		err = tx.CreateIndex(database.IndexConfig{
+			IndexName: "idxBar", TableName: "test", Path: parsePath(t, "bar"),
+		})
+		require.NoError(t, err)
+
+		tb, err := tx.GetTable("test")
+		require.NoError(t, err)
+
+		return tx, tb, cleanup
+	}
+
+	newDoc := func(foo, bar int64) document.Document {
+		return document.NewFieldBuffer().
+			Add("foo", document.NewIntegerValue(foo)).
+			Add("bar", document.NewIntegerValue(bar))
+	}
+
+	t.Run("Should insert documents and update indexes", func(t *testing.T) {
+		tx, tb, cleanup := newIndexedTable(t)
+		defer cleanup()
+
+		keys, err := tb.BulkInsert([]document.Document{newDoc(3, 1), newDoc(1, 1), newDoc(2, 0)})
+		require.NoError(t, err)
+		require.Len(t, keys, 3)
+
+		for _, key := range keys {
+			_, err := tb.GetDocument(key)
+			require.NoError(t, err)
+		}
+
+		idx, err := tx.GetIndex("idxFoo")
+		require.NoError(t, err)
+		var got [][]byte
+		err = idx.AscendGreaterOrEqual(document.Value{}, func(_, k []byte, _ bool) error {
+			got = append(got, k)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{keys[1], keys[2], keys[0]}, got)
+
+		idx, err = tx.GetIndex("idxBar")
+		require.NoError(t, err)
+		got = nil
+		err = idx.AscendGreaterOrEqual(document.Value{}, func(_, k []byte, _ bool) error {
+			got = append(got, k)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{keys[2], keys[0], keys[1]}, got)
+	})
+
+	t.Run("Should fail on duplicates within the batch", func(t *testing.T) {
+		_, tb, cleanup := newIndexedTable(t)
+		defer cleanup()
+
+		_, err := tb.BulkInsert([]document.Document{newDoc(1, 1), newDoc(2, 1), newDoc(1, 2)})
+		require.Equal(t, database.ErrDuplicateDocument, err)
+	})
+
+	t.Run("Should fail on duplicates with existing documents", func(t *testing.T) {
+		_, tb, cleanup := newIndexedTable(t)
+		defer cleanup()
+
+		_, err := tb.Insert(newDoc(1, 1))
+		require.NoError(t, err)
+
+		_, err = tb.BulkInsert([]document.Document{newDoc(2, 1), newDoc(1, 2)})
+		require.Equal(t, database.ErrDuplicateDocument, err)
+	})
+
+	t.Run("Should index the documents stored before an error", func(t *testing.T) {
+		tx, cleanup := newTestDB(t)
+		defer cleanup()
+
+		err := tx.CreateTable("test", &database.TableInfo{
+			FieldConstraints: []database.FieldConstraint{
+				{Path: parsePath(t, "bar"), Type: document.IntegerValue, IsNotNull: true},
+			},
+		})
+		require.NoError(t, err)
+		err = tx.CreateIndex(database.IndexConfig{
+			IndexName: "idxFoo", TableName: "test", Path: parsePath(t, "foo"),
+		})
+		require.NoError(t, err)
+
+		tb, err := tx.GetTable("test")
+		require.NoError(t, err)
+
+		noBar := document.NewFieldBuffer().Add("foo", document.NewIntegerValue(3))
+		_, err = tb.BulkInsert([]document.Document{newDoc(1, 1), newDoc(2, 2), noBar})
+		require.Error(t, err)
+
+		var stored int
+		err = tb.Iterate(func(d document.Document) error {
+			stored++
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, stored)
+
+		idx, err := tx.GetIndex("idxFoo")
+		require.NoError(t, err)
+		var indexed int
+		err = idx.AscendGreaterOrEqual(document.Value{}, func(_, k []byte, _ bool) error {
+			_, err := tb.GetDocument(k)
+			indexed++
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, stored, indexed)
+	})
+}
+
 // TestTableDelete verifies Delete behaviour.
 func TestTableDelete(t *testing.T) {
 	t.Run("Should fail if not found", func(t *testing.T) {
@@ -634,6 +876,101 @@ func TestTableReplace(t *testing.T) {
 	})
 }
 
+func TestTableReplaceChanged(t *testing.T) {
+	setup := func(t *testing.T) (*database.Transaction, *database.Table, []byte, func()) {
+		tx, cleanup := newTestDB(t)
+
+		err := tx.CreateTable("test", &database.TableInfo{
+			FieldConstraints: []database.FieldConstraint{
+				{Path: parsePath(t, "d"), Type: document.IntegerValue, DefaultValue: document.NewIntegerValue(10)},
+			},
+		})
+		require.NoError(t, err)
+		for _, p := range []string{"a", "b.c", "d"} {
+			err = tx.CreateIndex(database.IndexConfig{
+				IndexName: "idx_" + p,
+				TableName: "test",
+				Path:      parsePath(t, p),
+			})
+			require.NoError(t, err)
+		}
+
+		tb, err := tx.GetTable("test")
+		require.NoError(t, err)
+
+		key, err := tb.Insert(document.NewFieldBuffer().
+			Add("a", document.NewTextValue("a")).
+			Add("b", document.NewDocumentValue(document.NewFieldBuffer().
+				Add("c", document.NewTextValue("c")))))
+		require.NoError(t, err)
+
+		return tx, tb, key, cleanup
+	}
+
+	// indexed returns true if the index maps v to key.
+	indexed := func(t *testing.T, tx *database.Transaction, name string, v document.Value, key []byte) bool {
+		idx, err := tx.GetIndex(name)
+		require.NoError(t, err)
+
+		enc, err := idx.EncodeValue(v)
+		require.NoError(t, err)
+
+		var found bool
+		err = idx.AscendGreaterOrEqual(document.Value{}, func(val, k []byte, isEqual bool) error {
+			if bytes.Equal(val, enc) && bytes.Equal(k, key) {
+				found = true
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return found
+	}
+
+	t.Run("Only the indexes of the changed paths are updated", func(t *testing.T) {
+		tx, tb, key, cleanup := setup(t)
+		defer cleanup()
+
+		// a is not listed in the changed paths, its index is not updated.
+		err := tb.ReplaceChanged(key, document.NewFieldBuffer().
+			Add("a", document.NewTextValue("A")).
+			Add("b", document.NewTextValue("B")), []document.Path{parsePath(t, "b")})
+		require.NoError(t, err)
+
+		require.True(t, indexed(t, tx, "idx_a", document.NewTextValue("a"), key))
+		require.False(t, indexed(t, tx, "idx_a", document.NewTextValue("A"), key))
+		// replacing b replaces b.c
+		require.False(t, indexed(t, tx, "idx_b.c", document.NewTextValue("c"), key))
+		require.True(t, indexed(t, tx, "idx_b.c", document.NewNullValue(), key))
+		require.True(t, indexed(t, tx, "idx_d", document.NewIntegerValue(10), key))
+	})
+
+	t.Run("Constraints may modify any path", func(t *testing.T) {
+		tx, tb, key, cleanup := setup(t)
+		defer cleanup()
+
+		// d is converted to the type of its constraint
+		err := tb.ReplaceChanged(key, document.NewFieldBuffer().
+			Add("a", document.NewTextValue("a")).
+			Add("d", document.NewDoubleValue(20)), []document.Path{parsePath(t, "b")})
+		require.NoError(t, err)
+
+		require.False(t, indexed(t, tx, "idx_d", document.NewIntegerValue(10), key))
+		require.True(t, indexed(t, tx, "idx_d", document.NewIntegerValue(20), key))
+	})
+
+	t.Run("Nil paths", func(t *testing.T) {
+		tx, tb, key, cleanup := setup(t)
+		defer cleanup()
+
+		err := tb.ReplaceChanged(key, document.NewFieldBuffer().
+			Add("a", document.NewTextValue("A")), nil)
+		require.NoError(t, err)
+
+		require.True(t, indexed(t, tx, "idx_a", document.NewTextValue("A"), key))
+		require.True(t, indexed(t, tx, "idx_b.c", document.NewNullValue(), key))
+	})
+}
+
 // TestTableTruncate verifies Truncate behaviour.
 func TestTableTruncate(t *testing.T) {
 	t.Run("Should succeed if table empty", func(t *testing.T) {
@@ -725,6 +1062,14 @@ func TestTableReIndex(t *testing.T) {
 		})
 		require.NoError(t, err)
 
+		// empty the indexes, which are filled when created
+		for _, name := range []string{"test1a", "test1b", "test2a", "test2b"} {
+			idx, err := tx.GetIndex(name)
+			require.NoError(t, err)
+			err = idx.Truncate()
+			require.NoError(t, err)
+		}
+
 		err = tb1.ReIndex()
 		require.NoError(t, err)
 
@@ -812,6 +1157,98 @@ func TestTableIndexes(t *testing.T) {
 	})
 }
 
+func TestTableDeleteExpired(t *testing.T) {
+	now := time.Unix(0, 1000)
+
+	tests := []struct {
+		name  string
+		index *database.IndexConfig
+	}{
+		{"No index", nil},
+		{"Untyped index", &database.IndexConfig{IndexName: "idx_expires_at"}},
+		{"Typed index", &database.IndexConfig{IndexName: "idx_expires_at", Type: document.IntegerValue}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx, cleanup := newTestDB(t)
+			defer cleanup()
+
+			info := database.TableInfo{TTLPath: parsePath(t, "expires_at")}
+			if test.index != nil && test.index.Type != 0 {
+				// untyped numbers are stored as doubles.
+				info.FieldConstraints = []database.FieldConstraint{{Path: info.TTLPath, Type: test.index.Type}}
+			}
+			err := tx.CreateTable("test", &info)
+			require.NoError(t, err)
+
+			if test.index != nil {
+				cfg := *test.index
+				cfg.TableName = "test"
+				cfg.Path = parsePath(t, "expires_at")
+				err = tx.CreateIndex(cfg)
+				require.NoError(t, err)
+			}
+
+			tb, err := tx.GetTable("test")
+			require.NoError(t, err)
+
+			values := []document.Value{
+				document.NewIntegerValue(2000),
+				document.NewIntegerValue(500),
+				document.NewIntegerValue(-10),
+				document.NewIntegerValue(1000),
+				document.NewIntegerValue(1001),
+			}
+			untyped := test.index == nil || test.index.Type == 0
+			if untyped {
+				values = append(values, document.NewDoubleValue(20.5), document.NewTextValue("x"))
+			}
+			for _, v := range values {
+				_, err = tb.Insert(document.NewFieldBuffer().Add("expires_at", v))
+				require.NoError(t, err)
+			}
+			if untyped {
+				// never expires
+				_, err = tb.Insert(newDocument())
+				require.NoError(t, err)
+			}
+
+			expired := 3
+			if untyped {
+				expired++
+			}
+
+			n, err := tb.DeleteExpired(now, 2)
+			require.NoError(t, err)
+			require.Equal(t, 2, n)
+
+			n, err = tb.DeleteExpired(now, 100)
+			require.NoError(t, err)
+			require.Equal(t, expired-2, n)
+
+			var remaining []document.Value
+			err = tb.Iterate(func(d document.Document) error {
+				v, err := d.GetByField("expires_at")
+				if err == document.ErrFieldNotFound {
+					return nil
+				}
+				remaining = append(remaining, v)
+				return err
+			})
+			require.NoError(t, err)
+			require.Len(t, remaining, len(values)-expired)
+			for _, v := range remaining {
+				if v.Type.IsNumber() {
+					v, err = v.CastAsInteger()
+					require.NoError(t, err)
+					require.Greater(t, v.V.(int64), int64(1000))
+				}
+			}
+		})
+	}
+}
+
 // BenchmarkTableInsert benchmarks the Insert method with 1, 10, 1000 and 10000 successive insertions.
 func BenchmarkTableInsert(b *testing.B) {
 	for size := 1; size <= 10000; size *= 10 {
@@ -838,6 +1275,91 @@ func BenchmarkTableInsert(b *testing.B) {
 	}
 }
 
+// BenchmarkTableInsert100k benchmarks the allocations of inserting 100000 small documents.
+func BenchmarkTableInsert100k(b *testing.B) {
+	const size = 100000
+
+	var fb document.FieldBuffer
+	for i := int64(0); i < 3; i++ {
+		fb.Add(fmt.Sprintf("name-%d", i), document.NewIntegerValue(i))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.StopTimer()
+	for i := 0; i < b.N; i++ {
+		tb, cleanup := newTestTable(b)
+
+		b.StartTimer()
+		for j := 0; j < size; j++ {
+			tb.Insert(&fb)
+		}
+		b.StopTimer()
+		cleanup()
+	}
+}
+
+// BenchmarkTableBulkInsert compares successive calls to Insert with a single call to BulkInsert
+// on a table with 3 indexes, with 10, 100, 1000 and 10000 documents.
+func BenchmarkTableBulkInsert(b *testing.B) {
+	for size := 10; size <= 10000; size *= 10 {
+		docs := make([]document.Document, size)
+		for i := range docs {
+			var fb document.FieldBuffer
+			for j := 0; j < 3; j++ {
+				// values are not inserted in index order
+				fb.Add(fmt.Sprintf("name-%d", j), document.NewIntegerValue(int64(i*(j+7)%size)))
+			}
+			docs[i] = &fb
+		}
+
+		newTable := func(b *testing.B) (*database.Table, func()) {
+			tx, cleanup := newTestDB(b)
+
+			err := tx.CreateTable("test", nil)
+			require.NoError(b, err)
+			for j := 0; j < 3; j++ {
+				err = tx.CreateIndex(database.IndexConfig{
+					IndexName: fmt.Sprintf("idx%d", j), TableName: "test", Path: parsePath(b, fmt.Sprintf("`name-%d`", j)),
+				})
+				require.NoError(b, err)
+			}
+
+			tb, err := tx.GetTable("test")
+			require.NoError(b, err)
+			return tb, cleanup
+		}
+
+		b.Run(fmt.Sprintf("Insert/%.05d", size), func(b *testing.B) {
+			b.ResetTimer()
+			b.StopTimer()
+			for i := 0; i < b.N; i++ {
+				tb, cleanup := newTable(b)
+
+				b.StartTimer()
+				for _, d := range docs {
+					tb.Insert(d)
+				}
+				b.StopTimer()
+				cleanup()
+			}
+		})
+
+		b.Run(fmt.Sprintf("BulkInsert/%.05d", size), func(b *testing.B) {
+			b.ResetTimer()
+			b.StopTimer()
+			for i := 0; i < b.N; i++ {
+				tb, cleanup := newTable(b)
+
+				b.StartTimer()
+				tb.BulkInsert(docs)
+				b.StopTimer()
+				cleanup()
+			}
+		})
+	}
+}
+
 // BenchmarkTableScan benchmarks the Scan method with 1, 10, 1000 and 10000 successive insertions.
 func BenchmarkTableScan(b *testing.B) {
 	for size := 1; size <= 10000; size *= 10 {
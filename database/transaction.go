@@ -1,6 +1,8 @@
 package database
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -23,12 +25,18 @@ var (
 type Transaction struct {
 	db       *Database
 	tx       engine.Transaction
+	ctx      context.Context
 	writable bool
 	// if set to true, this transaction is attached to the database
 	attached bool
+	// set once the transaction is committed or rolled back
+	terminated bool
 
 	tableInfoStore *tableInfoStore
 	indexStore     *indexStore
+
+	// number of triggers currently running
+	triggerDepth int
 }
 
 // DB returns the underlying database that created the transaction.
@@ -37,42 +45,78 @@ func (tx *Transaction) DB() *Database {
 }
 
 // Rollback the transaction. Can be used safely after commit.
+// The transaction hooks of the database are called for read/write transactions,
+// unless the transaction was already terminated.
 func (tx *Transaction) Rollback() error {
+	hooks := tx.writable && !tx.terminated && len(tx.db.TransactionHooks) > 0
+	if hooks {
+		tx.db.runRollbackHooks(tx, false)
+	}
+
 	err := tx.tx.Rollback()
 	if err != nil {
 		return err
 	}
+	tx.terminated = true
 
-	if tx.attached {
-		tx.db.attachedTxMu.Lock()
-		defer tx.db.attachedTxMu.Unlock()
+	tx.detach()
 
-		if tx.db.attachedTransaction != nil {
-			tx.db.attachedTransaction = nil
-		}
+	if hooks {
+		tx.db.runRollbackHooks(tx, true)
 	}
 
 	return nil
 }
 
 // Commit the transaction.
+// For read/write transactions, the BeforeCommit hooks of the database are called first,
+// and if one of them fails, the transaction is rolled back and its error returned.
+// The AfterCommit hooks are called once the transaction is committed.
 func (tx *Transaction) Commit() error {
+	if tx.writable && !tx.terminated {
+		err := tx.db.runBeforeCommitHooks(tx)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
 	err := tx.tx.Commit()
 	if err != nil {
 		return err
 	}
+	tx.terminated = true
+
+	if tx.writable {
+		tx.db.notifyCommit()
+	}
 
-	if tx.attached {
-		tx.db.attachedTxMu.Lock()
-		defer tx.db.attachedTxMu.Unlock()
+	tx.detach()
 
-		if tx.db.attachedTransaction != nil {
-			tx.db.attachedTransaction = nil
-		}
+	if tx.writable {
+		tx.db.runAfterCommitHooks(tx)
 	}
 
 	return nil
+}
 
+// detach the transaction from the database, if it is attached.
+func (tx *Transaction) detach() {
+	if !tx.attached {
+		return
+	}
+
+	tx.db.attachedTxMu.Lock()
+	defer tx.db.attachedTxMu.Unlock()
+
+	if tx.db.attachedTransaction != nil {
+		tx.db.attachedTransaction = nil
+	}
+}
+
+// Context returns the context the transaction was started with.
+func (tx *Transaction) Context() context.Context {
+	return tx.ctx
 }
 
 // Writable indicates if the transaction is writable or not.
@@ -117,6 +161,10 @@ func (tx *Transaction) GetTable(name string) (*Table, error) {
 		return nil, err
 	}
 
+	if c := tx.db.tableCodec(name); c != nil {
+		s = engine.NewCodecStore(s, tableValueCodec{codec: c})
+	}
+
 	return &Table{
 		tx:        tx,
 		Store:     s,
@@ -183,6 +231,11 @@ func (tx *Transaction) RenameTable(oldName, newName string) error {
 		}
 	}
 
+	err = tx.renameTableTriggers(oldName, newName)
+	if err != nil {
+		return err
+	}
+
 	// Delete the old reference from the tableInfoStore.
 	return tx.tableInfoStore.Delete(tx, oldName)
 }
@@ -229,6 +282,11 @@ func (tx *Transaction) DropTable(name string) error {
 		return err
 	}
 
+	err = tx.dropTableTriggers(name)
+	if err != nil {
+		return err
+	}
+
 	err = tx.tableInfoStore.Delete(tx, name)
 	if err != nil {
 		return err
@@ -238,6 +296,8 @@ func (tx *Transaction) DropTable(name string) error {
 }
 
 // CreateIndex creates an index with the given name.
+// The documents already stored in the table are indexed, unless the index
+// is being built, in which case they are indexed by BuildIndex.
 // If it already exists, returns ErrIndexAlreadyExists.
 func (tx *Transaction) CreateIndex(opts IndexConfig) error {
 	t, err := tx.GetTable(opts.TableName)
@@ -262,7 +322,29 @@ func (tx *Transaction) CreateIndex(opts IndexConfig) error {
 		}
 	}
 
-	return tx.indexStore.Insert(opts)
+	err = tx.indexStore.Insert(opts)
+	if err != nil {
+		return err
+	}
+
+	// the format of the indexes is recorded along with the first index
+	meta, err := tx.readMeta()
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		err = tx.writeMeta()
+		if err != nil {
+			return err
+		}
+	}
+
+	// indexes being built are filled in chunks by BuildIndex
+	if opts.Building {
+		return nil
+	}
+
+	return tx.ReIndex(opts.IndexName)
 }
 
 // GetIndex returns an index by name.
@@ -302,6 +384,22 @@ func (tx *Transaction) DropIndex(name string) error {
 	return idx.Truncate()
 }
 
+// ListTables returns the names of all the tables, sorted.
+func (tx *Transaction) ListTables() ([]string, error) {
+	it := tx.tableInfoStore.st.Iterator(engine.IteratorOptions{})
+	defer it.Close()
+
+	var tables []string
+	for it.Seek(nil); it.Valid(); it.Next() {
+		tables = append(tables, string(it.Item().Key()))
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
 // ListIndexes lists all indexes.
 func (tx *Transaction) ListIndexes() ([]*IndexConfig, error) {
 	return tx.indexStore.ListAll()
@@ -324,8 +422,8 @@ func (tx *Transaction) ReIndex(indexName string) error {
 		return err
 	}
 
-	return tb.Iterate(func(d document.Document) error {
-		v, err := idx.Opts.Path.GetValue(d)
+	err = tb.Iterate(func(d document.Document) error {
+		v, err := tb.indexedValue(idx.Opts.Path, d)
 		if err == document.ErrFieldNotFound {
 			return nil
 		}
@@ -335,6 +433,92 @@ func (tx *Transaction) ReIndex(indexName string) error {
 
 		return idx.Set(v, d.(document.Keyer).Key())
 	})
+	if err != nil || !idx.Opts.Building {
+		return err
+	}
+
+	// the index now covers the whole table
+	idx.Opts.Building = false
+	idx.Opts.BuildKey = nil
+	return tx.indexStore.Replace(indexName, idx.Opts)
+}
+
+// BuildIndex indexes at most n documents of an index being built, starting after
+// the last document it covers, and records the key of the last indexed document.
+// Once all the documents of the table are indexed, the index is marked as built.
+// It returns the number of indexed documents and whether the build is complete.
+func (tx *Transaction) BuildIndex(indexName string, n int) (int, bool, error) {
+	idx, err := tx.GetIndex(indexName)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if !idx.Opts.Building {
+		return 0, true, nil
+	}
+
+	tb, err := tx.GetTable(idx.Opts.TableName)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var count int
+	var buf []byte
+	done := true
+	it := tb.Store.Iterator(engine.IteratorOptions{})
+	for it.Seek(idx.Opts.BuildKey); it.Valid(); it.Next() {
+		item := it.Item()
+		if idx.Opts.BuildKey != nil && bytes.Equal(item.Key(), idx.Opts.BuildKey) {
+			continue
+		}
+
+		if count == n {
+			done = false
+			break
+		}
+
+		buf, err = item.ValueCopy(buf[:0])
+		if err != nil {
+			break
+		}
+
+		key := append([]byte(nil), item.Key()...)
+		var v document.Value
+		v, err = tb.indexedValue(idx.Opts.Path, tx.db.Codec.NewDocument(buf))
+		if err != nil {
+			v = document.NewNullValue()
+		}
+
+		err = idx.Set(v, key)
+		if err != nil {
+			if err == index.ErrDuplicate {
+				err = ErrDuplicateDocument
+			}
+			break
+		}
+
+		idx.Opts.BuildKey = key
+		count++
+	}
+	if err == nil {
+		err = it.Err()
+	}
+	it.Close()
+	if err != nil {
+		return 0, false, err
+	}
+
+	if done {
+		idx.Opts.Building = false
+		idx.Opts.BuildKey = nil
+	}
+
+	err = tx.indexStore.Replace(indexName, idx.Opts)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return count, done, nil
 }
 
 // allIndexNames returns a list of all index names in index store.
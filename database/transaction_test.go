@@ -209,6 +209,27 @@ func TestTxTable(t *testing.T) {
 		err = tx.AddField("foo", fieldToAdd)
 		require.Error(t, err)
 	})
+
+	t.Run("List", func(t *testing.T) {
+		tx, cleanup := newTestDB(t)
+		defer cleanup()
+
+		tables, err := tx.ListTables()
+		require.NoError(t, err)
+		require.Empty(t, tables)
+
+		for _, name := range []string{"foo", "bar", "baz"} {
+			err = tx.CreateTable(name, nil)
+			require.NoError(t, err)
+		}
+
+		err = tx.DropTable("baz")
+		require.NoError(t, err)
+
+		tables, err = tx.ListTables()
+		require.NoError(t, err)
+		require.Equal(t, []string{"bar", "foo"}, tables)
+	})
 }
 
 func TestTxCreateIndex(t *testing.T) {
@@ -473,3 +494,41 @@ func TestReIndexAll(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestTxCommitted(t *testing.T) {
+	db, err := database.New(context.Background(), memoryengine.NewEngine(), database.Options{
+		Codec: msgpack.NewCodec(),
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	isClosed := func(ch <-chan struct{}) bool {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}
+
+	ch := db.Committed()
+	require.False(t, isClosed(ch))
+
+	// read-only and rolled back transactions don't close the channel
+	tx, err := db.Begin(false)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	tx, err = db.Begin(true)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	require.False(t, isClosed(ch))
+
+	tx, err = db.Begin(true)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	require.True(t, isClosed(ch))
+
+	// the next commit uses a new channel
+	ch = db.Committed()
+	require.False(t, isClosed(ch))
+}
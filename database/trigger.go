@@ -0,0 +1,349 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+)
+
+var triggerStoreName = internalPrefix + "triggers"
+
+// maxTriggerDepth is the maximum number of nested trigger executions,
+// which prevents triggers from calling each other endlessly.
+const maxTriggerDepth = 32
+
+// TriggerTiming determines if a trigger fires before or after the change of a document.
+type TriggerTiming int
+
+// List of trigger timings.
+const (
+	TriggerBefore TriggerTiming = iota + 1
+	TriggerAfter
+)
+
+func (t TriggerTiming) String() string {
+	switch t {
+	case TriggerBefore:
+		return "BEFORE"
+	case TriggerAfter:
+		return "AFTER"
+	}
+
+	return ""
+}
+
+// TriggerEvent is the kind of change that fires a trigger.
+type TriggerEvent int
+
+// List of trigger events.
+const (
+	TriggerInsert TriggerEvent = iota + 1
+	TriggerUpdate
+	TriggerDelete
+)
+
+func (e TriggerEvent) String() string {
+	switch e {
+	case TriggerInsert:
+		return "INSERT"
+	case TriggerUpdate:
+		return "UPDATE"
+	case TriggerDelete:
+		return "DELETE"
+	}
+
+	return ""
+}
+
+// TriggerConfig holds the configuration of a trigger.
+type TriggerConfig struct {
+	TriggerName string
+	TableName   string
+	Timing      TriggerTiming
+	Event       TriggerEvent
+
+	// SQL statement run every time the trigger fires.
+	Statement string
+}
+
+// ToDocument creates a document from a TriggerConfig.
+func (c *TriggerConfig) ToDocument() document.Document {
+	buf := document.NewFieldBuffer()
+
+	buf.Add("trigger_name", document.NewTextValue(c.TriggerName))
+	buf.Add("table_name", document.NewTextValue(c.TableName))
+	buf.Add("timing", document.NewIntegerValue(int64(c.Timing)))
+	buf.Add("event", document.NewIntegerValue(int64(c.Event)))
+	buf.Add("statement", document.NewTextValue(c.Statement))
+	return buf
+}
+
+// ScanDocument implements the document.Scanner interface.
+func (c *TriggerConfig) ScanDocument(d document.Document) error {
+	v, err := d.GetByField("trigger_name")
+	if err != nil {
+		return err
+	}
+	c.TriggerName = v.V.(string)
+
+	v, err = d.GetByField("table_name")
+	if err != nil {
+		return err
+	}
+	c.TableName = v.V.(string)
+
+	v, err = d.GetByField("timing")
+	if err != nil {
+		return err
+	}
+	c.Timing = TriggerTiming(v.V.(int64))
+
+	v, err = d.GetByField("event")
+	if err != nil {
+		return err
+	}
+	c.Event = TriggerEvent(v.V.(int64))
+
+	v, err = d.GetByField("statement")
+	if err != nil {
+		return err
+	}
+	c.Statement = v.V.(string)
+
+	return nil
+}
+
+// getTriggerStore returns the store of the triggers.
+// If create is true, the store is created on first use.
+func (tx *Transaction) getTriggerStore(create bool) (engine.Store, error) {
+	st, err := tx.tx.GetStore([]byte(triggerStoreName))
+	if err == engine.ErrStoreNotFound && create {
+		err = tx.tx.CreateStore([]byte(triggerStoreName))
+		if err != nil {
+			return nil, err
+		}
+		st, err = tx.tx.GetStore([]byte(triggerStoreName))
+	}
+
+	return st, err
+}
+
+// CreateTrigger creates a trigger on an existing table.
+// If a trigger with the same name exists, returns ErrTriggerAlreadyExists.
+func (tx *Transaction) CreateTrigger(cfg TriggerConfig) error {
+	t, err := tx.GetTable(cfg.TableName)
+	if err != nil {
+		return err
+	}
+
+	info, err := t.Info()
+	if err != nil {
+		return err
+	}
+
+	if info.readOnly {
+		return errors.New("cannot create a trigger on a read-only table")
+	}
+
+	st, err := tx.getTriggerStore(true)
+	if err != nil {
+		return err
+	}
+
+	key := []byte(cfg.TriggerName)
+	_, err = st.Get(key)
+	if err == nil {
+		return ErrTriggerAlreadyExists
+	}
+	if err != engine.ErrKeyNotFound {
+		return err
+	}
+
+	return tx.putTrigger(st, &cfg)
+}
+
+func (tx *Transaction) putTrigger(st engine.Store, cfg *TriggerConfig) error {
+	var buf bytes.Buffer
+	err := tx.db.Codec.NewEncoder(&buf).EncodeDocument(cfg.ToDocument())
+	if err != nil {
+		return err
+	}
+
+	return st.Put([]byte(cfg.TriggerName), buf.Bytes())
+}
+
+// DropTrigger deletes a trigger from the database.
+// If it doesn't exist, returns ErrTriggerNotFound.
+func (tx *Transaction) DropTrigger(name string) error {
+	st, err := tx.getTriggerStore(false)
+	if err == engine.ErrStoreNotFound {
+		return ErrTriggerNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	err = st.Delete([]byte(name))
+	if err == engine.ErrKeyNotFound {
+		return ErrTriggerNotFound
+	}
+	return err
+}
+
+// ListTriggers lists all the triggers of the database.
+func (tx *Transaction) ListTriggers() ([]*TriggerConfig, error) {
+	st, err := tx.getTriggerStore(false)
+	if err == engine.ErrStoreNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	it := st.Iterator(engine.IteratorOptions{})
+	defer it.Close()
+
+	var triggers []*TriggerConfig
+	var buf []byte
+	for it.Seek(nil); it.Valid(); it.Next() {
+		buf, err = it.Item().ValueCopy(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		var cfg TriggerConfig
+		err = cfg.ScanDocument(tx.db.Codec.NewDocument(buf))
+		if err != nil {
+			return nil, err
+		}
+
+		triggers = append(triggers, &cfg)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return triggers, nil
+}
+
+// renameTableTriggers attaches the triggers of a table to its new name.
+func (tx *Transaction) renameTableTriggers(oldName, newName string) error {
+	triggers, err := tx.ListTriggers()
+	if err != nil || len(triggers) == 0 {
+		return err
+	}
+
+	st, err := tx.getTriggerStore(false)
+	if err != nil {
+		return err
+	}
+
+	for _, cfg := range triggers {
+		if cfg.TableName != oldName {
+			continue
+		}
+
+		cfg.TableName = newName
+		err = tx.putTrigger(st, cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dropTableTriggers deletes the triggers of a table.
+func (tx *Transaction) dropTableTriggers(tableName string) error {
+	triggers, err := tx.ListTriggers()
+	if err != nil {
+		return err
+	}
+
+	for _, cfg := range triggers {
+		if cfg.TableName != tableName {
+			continue
+		}
+
+		err = tx.DropTrigger(cfg.TriggerName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tableTriggers returns the triggers of the table that fire for the given event.
+// They are loaded once per Table instance.
+func (t *Table) tableTriggers(event TriggerEvent) ([]*TriggerConfig, error) {
+	if t.triggers == nil {
+		all, err := t.tx.ListTriggers()
+		if err != nil {
+			return nil, err
+		}
+
+		t.triggers = make([]*TriggerConfig, 0)
+		for _, cfg := range all {
+			if cfg.TableName == t.name {
+				t.triggers = append(t.triggers, cfg)
+			}
+		}
+	}
+
+	var triggers []*TriggerConfig
+	for _, cfg := range t.triggers {
+		if cfg.Event == event {
+			triggers = append(triggers, cfg)
+		}
+	}
+
+	return triggers, nil
+}
+
+// HasTriggers returns true if the table has triggers that fire for the given event.
+func (t *Table) HasTriggers(event TriggerEvent) (bool, error) {
+	triggers, err := t.tableTriggers(event)
+	return len(triggers) > 0, err
+}
+
+// fireTriggers runs the triggers with the given timing using the Database RunTrigger function.
+// old is nil for insertions and new is nil for deletions.
+func (t *Table) fireTriggers(triggers []*TriggerConfig, timing TriggerTiming, old, new document.Document) error {
+	for _, cfg := range triggers {
+		if cfg.Timing != timing {
+			continue
+		}
+
+		if t.tx.db.RunTrigger == nil {
+			return fmt.Errorf("cannot run trigger %q: triggers are not supported by this database", cfg.TriggerName)
+		}
+
+		if t.tx.triggerDepth >= maxTriggerDepth {
+			return fmt.Errorf("cannot run trigger %q: too many nested triggers", cfg.TriggerName)
+		}
+
+		t.tx.triggerDepth++
+		err := t.tx.db.RunTrigger(t.tx, cfg, old, new)
+		t.tx.triggerDepth--
+		if err != nil {
+			return fmt.Errorf("trigger %q: %w", cfg.TriggerName, err)
+		}
+	}
+
+	return nil
+}
+
+// copyDocument returns a copy of d that remains valid after d is modified in the store.
+func copyDocument(d document.Document) (document.Document, error) {
+	var fb document.FieldBuffer
+	err := fb.Copy(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fb, nil
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+)
+
+// ListTTLTables returns the name of every table that has a TTL path configured.
+func (tx *Transaction) ListTTLTables() ([]string, error) {
+	it := tx.tableInfoStore.st.Iterator(engine.IteratorOptions{})
+	defer it.Close()
+
+	var tables []string
+	var buf []byte
+	var err error
+	for it.Seek(nil); it.Valid(); it.Next() {
+		buf, err = it.Item().ValueCopy(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		var ti TableInfo
+		err = ti.ScanDocument(tx.db.Codec.NewDocument(buf))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ti.TTLPath) > 0 {
+			tables = append(tables, ti.tableName)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+// DeleteExpired deletes at most limit documents whose expiration time,
+// stored at the TTL path of the table, is before now.
+// Documents without a numeric value at that path never expire.
+// If the TTL path is indexed, only the expired documents are read from the index.
+// Otherwise, the whole table is scanned.
+// It returns the number of deleted documents.
+func (t *Table) DeleteExpired(now time.Time, limit int) (int, error) {
+	info, err := t.Info()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(info.TTLPath) == 0 {
+		return 0, errors.New("table has no TTL path")
+	}
+
+	deadline := now.UnixNano()
+	var keys [][]byte
+
+	// collect the keys first, some engines can't iterate while deleting keys.
+	collect := func(d document.Document) error {
+		v, err := info.TTLPath.GetValue(d)
+		if err == document.ErrFieldNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !v.Type.IsNumber() {
+			return nil
+		}
+
+		v, err = v.CastAsInteger()
+		if err != nil {
+			return err
+		}
+
+		if v.V.(int64) > deadline {
+			return nil
+		}
+
+		keys = append(keys, append([]byte{}, d.(document.Keyer).Key()...))
+		if len(keys) >= limit {
+			return errStop
+		}
+
+		return nil
+	}
+
+	indexes, err := t.Indexes()
+	if err != nil {
+		return 0, err
+	}
+
+	if idx, ok := indexes[info.TTLPath.String()]; ok {
+		err = t.iterateUpTo(idx, deadline, collect)
+	} else {
+		err = t.Iterate(collect)
+	}
+	if err != nil && err != errStop {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		err = t.Delete(key)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(keys), nil
+}
+
+// iterateUpTo calls fn for every document whose value in the index
+// is a number lower than or equal to max.
+func (t *Table) iterateUpTo(idx Index, max int64, fn func(d document.Document) error) error {
+	for _, tp := range []document.ValueType{document.IntegerValue, document.DoubleValue} {
+		if idx.Opts.Type != 0 && idx.Opts.Type != tp {
+			continue
+		}
+
+		pivot, err := document.NewIntegerValue(max).CastAs(tp)
+		if err != nil {
+			return err
+		}
+
+		enc, err := idx.EncodeValue(pivot)
+		if err != nil {
+			return err
+		}
+
+		// typed indexes only contain values of their type.
+		var start document.Value
+		if idx.Opts.Type == 0 {
+			start.Type = tp
+		}
+
+		inRange := true
+		err = idx.AscendGreaterOrEqual(start, func(val, key []byte, isEqual bool) error {
+			inRange = bytes.Compare(val, enc) <= 0
+			if !inRange {
+				return errStop
+			}
+
+			d, err := t.GetDocument(key)
+			if err != nil {
+				return err
+			}
+
+			return fn(d)
+		})
+		if err == errStop && !inRange {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var errStop = errors.New("stop")
@@ -2,9 +2,14 @@ package genji
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/cachedengine"
+	"github.com/genjidb/genji/engine/encryptedengine"
 	"github.com/genjidb/genji/sql/parser"
 	"github.com/genjidb/genji/sql/query"
 )
@@ -13,19 +18,62 @@ import (
 type DB struct {
 	DB *database.Database
 
-	ctx context.Context
+	ctx        context.Context
+	reaper     *ttlReaper
+	encryption *encryptedengine.Engine
+	cache      *cachedengine.Engine
+	compactor  *compactor
+
+	// number of attempts and initial delay used by Update
+	// to retry transactions failing with a serialization failure.
+	retryAttempts int
+	retryBackoff  time.Duration
+
+	// batch writer used by BatchExec, if enabled.
+	batch              *batchWriter
+	batchFlushInterval time.Duration
 }
 
 // WithContext creates a new database handle using the given context for every operation.
 func (db *DB) WithContext(ctx context.Context) *DB {
 	return &DB{
-		DB:  db.DB,
-		ctx: ctx,
+		DB:         db.DB,
+		ctx:        ctx,
+		reaper:     db.reaper,
+		encryption: db.encryption,
+		cache:      db.cache,
+		compactor:  db.compactor,
+
+		retryAttempts: db.retryAttempts,
+		retryBackoff:  db.retryBackoff,
+
+		batch:              db.batch,
+		batchFlushInterval: db.batchFlushInterval,
 	}
 }
 
+// WithTransactionRetry creates a new database handle whose Update method runs the transaction
+// up to maxAttempts times if it fails with engine.ErrSerializationFailure.
+// See the WithTransactionRetry option.
+func (db *DB) WithTransactionRetry(maxAttempts int, backoff time.Duration) *DB {
+	ndb := db.WithContext(db.ctx)
+	ndb.retryAttempts = maxAttempts
+	ndb.retryBackoff = backoff
+	return ndb
+}
+
 // Close the database.
+// If the TTL reaper, the compactor or the batch writer are running, they are stopped first.
+// The writes queued with BatchExec are committed before closing.
 func (db *DB) Close() error {
+	if db.batch != nil {
+		db.batch.close()
+	}
+	db.StopTTLReaper()
+	if db.compactor != nil {
+		db.compactor.close()
+	}
+
 	return db.DB.Close()
 }
 
@@ -56,7 +104,40 @@ func (db *DB) View(fn func(tx *Tx) error) error {
 }
 
 // Update starts a read-write transaction, runs fn and automatically commits it.
+// If transaction retry is enabled and the transaction fails with engine.ErrSerializationFailure,
+// a new transaction is started and fn is called again, after waiting for the backoff delay,
+// which doubles after each attempt. Once all the attempts failed, the last error is returned.
 func (db *DB) Update(fn func(tx *Tx) error) error {
+	backoff := db.retryBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := db.update(fn)
+		if err == nil || attempt >= db.retryAttempts || !errors.Is(err, engine.ErrSerializationFailure) {
+			return err
+		}
+
+		err = db.wait(backoff)
+		if err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// wait for d, unless the context of the database is canceled.
+func (db *DB) wait(d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-db.ctx.Done():
+		return db.ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func (db *DB) update(fn func(tx *Tx) error) error {
 	tx, err := db.Begin(true)
 	if err != nil {
 		return err
@@ -83,13 +164,55 @@ func (db *DB) Exec(q string, args ...interface{}) error {
 
 // Query the database and return the result.
 // The returned result must always be closed after usage.
+// ExecOptions can be passed along with the arguments.
 func (db *DB) Query(q string, args ...interface{}) (*query.Result, error) {
+	opts, args, err := splitExecOptions(args)
+	if err != nil {
+		return nil, err
+	}
+
 	pq, err := parser.ParseQuery(q)
 	if err != nil {
 		return nil, err
 	}
 
-	return pq.Run(db.ctx, db.DB, argsToParams(args))
+	return db.run(pq, opts, args)
+}
+
+// run the query with the given options.
+func (db *DB) run(pq query.Query, opts *execOptions, args []interface{}) (*query.Result, error) {
+	ctx, cancel := opts.context(db.ctx)
+
+	res, err := pq.Run(ctx, db.DB, argsToParams(args))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	res.OnClose(cancel)
+	return res, nil
+}
+
+// ExecStatement runs stmt against the database without returning the result.
+func (db *DB) ExecStatement(stmt query.Statement, args ...interface{}) error {
+	res, err := db.QueryStatement(stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	return res.Close()
+}
+
+// QueryStatement runs stmt against the database and returns the result.
+// Statements can be created without any SQL using the sql/builder package.
+// The returned result must always be closed after usage.
+func (db *DB) QueryStatement(stmt query.Statement, args ...interface{}) (*query.Result, error) {
+	opts, args, err := splitExecOptions(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.run(query.New(stmt), opts, args)
 }
 
 // QueryDocument runs the query and returns the first document.
@@ -116,9 +239,49 @@ func (db *DB) QueryDocument(q string, args ...interface{}) (document.Document, e
 		return nil, err
 	}
 
+	if db.DB.CaseInsensitiveFields {
+		return document.FoldDocument(&fb), nil
+	}
+
 	return &fb, nil
 }
 
+// indexBuildChunkSize is the number of documents indexed per transaction
+// by CreateIndexWithProgress.
+const indexBuildChunkSize = 1000
+
+// CreateIndexWithProgress creates an index and indexes the documents of its table
+// in chunks, each chunk in its own transaction, so that writes are not blocked
+// during the whole build. The index is not used by queries until it is complete.
+// If the index exists and its build was interrupted, the build is resumed.
+// If progress is not nil, it is called after each chunk.
+func (db *DB) CreateIndexWithProgress(cfg database.IndexConfig, progress func(done, total int64)) error {
+	cfg.Building = true
+	cfg.BuildKey = nil
+
+	err := db.Update(func(tx *Tx) error {
+		err := tx.CreateIndex(cfg)
+		if err != database.ErrIndexAlreadyExists {
+			return err
+		}
+
+		idx, err := tx.GetIndex(cfg.IndexName)
+		if err != nil {
+			return err
+		}
+		if !idx.Opts.Building {
+			return database.ErrIndexAlreadyExists
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.DB.BuildIndex(db.ctx, cfg.IndexName, indexBuildChunkSize, progress)
+}
+
 // Tx represents a database transaction. It provides methods for managing the
 // collection of tables and the transaction itself.
 // Tx is either read-only or read/write. Read-only can be used to read tables
@@ -130,6 +293,10 @@ type Tx struct {
 // Query the database withing the transaction and returns the result.
 // Closing the returned result after usage is not mandatory.
 func (tx *Tx) Query(q string, args ...interface{}) (*query.Result, error) {
+	if opts, _, _ := splitExecOptions(args); opts != nil {
+		return nil, errTxExecOptions
+	}
+
 	pq, err := parser.ParseQuery(q)
 	if err != nil {
 		return nil, err
@@ -138,6 +305,17 @@ func (tx *Tx) Query(q string, args ...interface{}) (*query.Result, error) {
 	return pq.Exec(tx.Transaction, argsToParams(args))
 }
 
+// QueryStatement runs stmt within tx and returns the result.
+// Statements can be created without any SQL using the sql/builder package.
+// Closing the returned result after usage is not mandatory.
+func (tx *Tx) QueryStatement(stmt query.Statement, args ...interface{}) (*query.Result, error) {
+	if opts, _, _ := splitExecOptions(args); opts != nil {
+		return nil, errTxExecOptions
+	}
+
+	return query.New(stmt).Exec(tx.Transaction, argsToParams(args))
+}
+
 // QueryDocument runs the query and returns the first document.
 // If the query returns no error, QueryDocument returns database.ErrDocumentNotFound.
 func (tx *Tx) QueryDocument(q string, args ...interface{}) (document.Document, error) {
@@ -167,3 +345,13 @@ func (tx *Tx) Exec(q string, args ...interface{}) error {
 
 	return res.Close()
 }
+
+// ExecStatement runs stmt within tx without returning the result.
+func (tx *Tx) ExecStatement(stmt query.Statement, args ...interface{}) error {
+	res, err := tx.QueryStatement(stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	return res.Close()
+}
@@ -1,13 +1,20 @@
 package genji_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
 	"github.com/stretchr/testify/require"
 )
 
@@ -116,3 +123,308 @@ func TestQueryDocument(t *testing.T) {
 		require.Nil(t, r)
 	})
 }
+
+func TestCaseInsensitiveFields(t *testing.T) {
+	db, err := genji.Open(":memory:", genji.WithCaseInsensitiveFields())
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		CREATE INDEX idx_name ON test(name);
+		INSERT INTO test VALUES {Name: 'foo', Address: {City: 'Lyon'}, age: 10};
+		INSERT INTO test VALUES {name: 'bar', ADDRESS: {city: 'Paris'}, AGE: 20};
+	`)
+	require.NoError(t, err)
+
+	queryJSON := func(t *testing.T, q string) string {
+		t.Helper()
+
+		res, err := db.Query(q)
+		require.NoError(t, err)
+		defer res.Close()
+
+		var docs []string
+		err = res.Iterate(func(d document.Document) error {
+			data, err := document.MarshalJSON(d)
+			docs = append(docs, string(data))
+			return err
+		})
+		require.NoError(t, err)
+		return fmt.Sprintf("%v", docs)
+	}
+
+	t.Run("Expressions", func(t *testing.T) {
+		require.Equal(t, `[{"NAME": "bar", "address.CITY": "Paris"} {"NAME": "foo", "address.CITY": "Lyon"}]`,
+			queryJSON(t, "SELECT NAME, address.CITY FROM test WHERE Age > 5 ORDER BY ADDRESS.city DESC"))
+	})
+
+	t.Run("Index", func(t *testing.T) {
+		d, err := db.QueryDocument("EXPLAIN SELECT * FROM test WHERE NAME = 'foo'")
+		require.NoError(t, err)
+		v, err := d.GetByField("plan")
+		require.NoError(t, err)
+		require.Contains(t, v.V.(string), "Index(idx_name)")
+
+		require.Equal(t, `[{"Name": "foo", "Address": {"City": "Lyon"}, "age": 10}]`,
+			queryJSON(t, "SELECT * FROM test WHERE NAME = 'foo'"))
+	})
+
+	t.Run("Index matching several paths", func(t *testing.T) {
+		err := db.Exec("CREATE INDEX idx_b_age ON test(AGE); CREATE INDEX idx_a_age ON test(age)")
+		require.NoError(t, err)
+		defer func() {
+			err := db.Exec("DROP INDEX idx_a_age; DROP INDEX idx_b_age")
+			require.NoError(t, err)
+		}()
+
+		for i := 0; i < 10; i++ {
+			d, err := db.QueryDocument("EXPLAIN SELECT * FROM test WHERE Age = 10")
+			require.NoError(t, err)
+			v, err := d.GetByField("plan")
+			require.NoError(t, err)
+			require.Contains(t, v.V.(string), "Index(idx_a_age)")
+		}
+	})
+
+	t.Run("Reopen with another setting", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "genji")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "test.db")
+
+		db, err := genji.Open(path)
+		require.NoError(t, err)
+		err = db.Exec("CREATE TABLE test; CREATE INDEX idx_name ON test(name); INSERT INTO test VALUES {Name: 'foo'}")
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		for _, opts := range [][]genji.Option{{genji.WithCaseInsensitiveFields()}, nil} {
+			db, err = genji.Open(path, opts...)
+			require.NoError(t, err)
+
+			// the index must agree with a table scan
+			var n, scanned int
+			d, err := db.QueryDocument("SELECT COUNT(*) FROM test WHERE name = 'foo'")
+			require.NoError(t, err)
+			require.NoError(t, document.Scan(d, &n))
+			d, err = db.QueryDocument("SELECT COUNT(*) FROM test WHERE CAST(name AS TEXT) = 'foo'")
+			require.NoError(t, err)
+			require.NoError(t, document.Scan(d, &scanned))
+			require.Equal(t, scanned, n)
+			require.NoError(t, db.Close())
+		}
+	})
+
+	t.Run("StructScan", func(t *testing.T) {
+		var s struct {
+			Name    string
+			Age     int
+			Address struct {
+				City string
+			}
+		}
+
+		d, err := db.QueryDocument("SELECT * FROM test WHERE name = 'bar'")
+		require.NoError(t, err)
+		err = document.StructScan(d, &s)
+		require.NoError(t, err)
+		require.Equal(t, "bar", s.Name)
+		require.Equal(t, 20, s.Age)
+		require.Equal(t, "Paris", s.Address.City)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		err := db.Exec("UPDATE test SET NAME = 'baz', address.CITY = 'Nice' WHERE name = 'foo'")
+		require.NoError(t, err)
+
+		// the original field names are kept and the index is updated.
+		require.Equal(t, `[{"Name": "baz", "Address": {"City": "Nice"}, "age": 10}]`,
+			queryJSON(t, "SELECT * FROM test WHERE name = 'baz'"))
+		require.Equal(t, `[]`, queryJSON(t, "SELECT * FROM test WHERE name = 'foo'"))
+	})
+}
+
+func TestCreateIndexWithProgress(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE test")
+	require.NoError(t, err)
+	for i := 0; i < 2500; i++ {
+		err = db.Exec("INSERT INTO test (a) VALUES (?)", i)
+		require.NoError(t, err)
+	}
+
+	cfg := database.IndexConfig{
+		IndexName: "idx_a",
+		TableName: "test",
+		Path:      document.Path{document.PathFragment{FieldName: "a"}},
+	}
+
+	plan := func(t *testing.T) string {
+		d, err := db.QueryDocument("EXPLAIN SELECT * FROM test WHERE a = 10")
+		require.NoError(t, err)
+		v, err := d.GetByField("plan")
+		require.NoError(t, err)
+		return v.V.(string)
+	}
+
+	// interrupt the build after the first chunk
+	ctx, cancel := context.WithCancel(context.Background())
+	err = db.WithContext(ctx).CreateIndexWithProgress(cfg, func(done, total int64) {
+		require.Equal(t, int64(1000), done)
+		require.Equal(t, int64(2500), total)
+		cancel()
+	})
+	require.Equal(t, context.Canceled, err)
+	require.NotContains(t, plan(t), "Index(idx_a)")
+
+	var last [2]int64
+	err = db.CreateIndexWithProgress(cfg, func(done, total int64) {
+		last = [2]int64{done, total}
+	})
+	require.NoError(t, err)
+	require.Equal(t, [2]int64{2500, 2500}, last)
+	require.Contains(t, plan(t), "Index(idx_a)")
+
+	d, err := db.QueryDocument("SELECT COUNT(*) FROM test WHERE a >= 1500")
+	require.NoError(t, err)
+	v, err := d.GetByField("COUNT(*)")
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), v.V.(int64))
+
+	err = db.CreateIndexWithProgress(cfg, nil)
+	require.Equal(t, database.ErrIndexAlreadyExists, err)
+}
+
+func TestTransactionRetry(t *testing.T) {
+	db, err := genji.Open(":memory:", genji.WithTransactionRetry(3, time.Millisecond))
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE test")
+	require.NoError(t, err)
+
+	t.Run("Retry", func(t *testing.T) {
+		var attempts int
+		err := db.Update(func(tx *genji.Tx) error {
+			attempts++
+
+			err := tx.Exec("INSERT INTO test (a) VALUES (?)", attempts)
+			if err != nil {
+				return err
+			}
+
+			if attempts == 1 {
+				return fmt.Errorf("conflict: %w", engine.ErrSerializationFailure)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, attempts)
+
+		// the first attempt was rolled back
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n, MAX(a) AS a FROM test")
+		require.NoError(t, err)
+		var res struct{ N, A int }
+		require.NoError(t, document.StructScan(d, &res))
+		require.Equal(t, 1, res.N)
+		require.Equal(t, 2, res.A)
+	})
+
+	t.Run("Max attempts", func(t *testing.T) {
+		var attempts int
+		err := db.Update(func(tx *genji.Tx) error {
+			attempts++
+			return engine.ErrSerializationFailure
+		})
+		require.Equal(t, engine.ErrSerializationFailure, err)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("Other errors", func(t *testing.T) {
+		var attempts int
+		err := db.Update(func(tx *genji.Tx) error {
+			attempts++
+			return errors.New("foo")
+		})
+		require.EqualError(t, err, "foo")
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("Handle", func(t *testing.T) {
+		var attempts int
+		err := db.WithTransactionRetry(5, 0).Update(func(tx *genji.Tx) error {
+			attempts++
+			return engine.ErrSerializationFailure
+		})
+		require.Equal(t, engine.ErrSerializationFailure, err)
+		require.Equal(t, 5, attempts)
+	})
+
+	t.Run("Canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var attempts int
+		err := db.WithContext(ctx).Update(func(tx *genji.Tx) error {
+			attempts++
+			return engine.ErrSerializationFailure
+		})
+		require.Error(t, err)
+		require.Equal(t, 0, attempts)
+	})
+
+	t.Run("Invalid attempts", func(t *testing.T) {
+		_, err := genji.Open(":memory:", genji.WithTransactionRetry(0, time.Millisecond))
+		require.Error(t, err)
+	})
+}
+
+func TestConstraintViolationError(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE test(id INTEGER PRIMARY KEY, a TEXT NOT NULL, b INTEGER)")
+	require.NoError(t, err)
+
+	tests := []struct {
+		query string
+		kind  database.ConstraintViolationKind
+		path  string
+		value document.Value
+	}{
+		{"INSERT INTO test (id) VALUES (1)", database.NotNullViolation, "a", document.Value{}},
+		{"INSERT INTO test (id, a) VALUES (1, NULL)", database.NotNullViolation, "a", document.NewNullValue()},
+		{"INSERT INTO test (id, a, b) VALUES (1, 'foo', 'bar')", database.TypeViolation, "b", document.NewTextValue("bar")},
+		{"INSERT INTO test (a) VALUES ('foo')", database.PrimaryKeyViolation, "id", document.Value{}},
+		{"INSERT INTO test (id, a) VALUES (NULL, 'foo')", database.PrimaryKeyViolation, "id", document.NewNullValue()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			err := db.Exec(test.query)
+
+			var cerr *database.ConstraintViolationError
+			require.True(t, errors.As(err, &cerr), "got %v", err)
+			require.Equal(t, test.kind, cerr.Kind)
+			require.Equal(t, "test", cerr.Table)
+			require.Equal(t, test.path, cerr.Path.String())
+			require.Equal(t, test.value, cerr.Value)
+		})
+	}
+
+	t.Run("Update", func(t *testing.T) {
+		err := db.Exec("INSERT INTO test (id, a) VALUES (1, 'foo')")
+		require.NoError(t, err)
+
+		err = db.Exec("UPDATE test SET a = NULL")
+		var cerr *database.ConstraintViolationError
+		require.True(t, errors.As(err, &cerr), "got %v", err)
+		require.Equal(t, database.NotNullViolation, cerr.Kind)
+		require.Equal(t, "a", cerr.Path.String())
+	})
+}
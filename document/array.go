@@ -221,6 +221,16 @@ func (vb *ValueBuffer) Replace(index int, v Value) error {
 	return nil
 }
 
+// Delete removes the value at the given index and shifts the values that follow it.
+func (vb *ValueBuffer) Delete(index int) error {
+	if index < 0 || len(*vb) <= index {
+		return ErrFieldNotFound
+	}
+
+	*vb = append((*vb)[:index], (*vb)[index+1:]...)
+	return nil
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 func (vb *ValueBuffer) MarshalJSON() ([]byte, error) {
 	return jsonArray{Array: vb}.MarshalJSON()
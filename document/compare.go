@@ -3,6 +3,8 @@ package document
 import (
 	"bytes"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type operator uint8
@@ -67,7 +69,79 @@ func (v Value) IsLesserThanOrEqual(other Value) (bool, error) {
 	return compare(operatorLte, v, other)
 }
 
+// comparators registered with RegisterComparator.
+// The map is replaced on every registration, so that it can be read without locking.
+var (
+	comparators   atomic.Value // map[ValueType]func(a, b Value) int
+	comparatorsMu sync.Mutex
+)
+
+// RegisterComparator registers a function that compares values of the custom type t.
+// The function must return a negative number if a < b, zero if a == b and a positive
+// number if a > b. It is used to compare two values of type t by every comparison
+// operator and by ORDER BY.
+// Registering a nil function removes the comparator of t.
+// It panics if t is a built-in type: their values are also compared by indexes,
+// using their encoding, which would then disagree with the comparator.
+func RegisterComparator(t ValueType, cmp func(a, b Value) int) {
+	if t.String() != "" {
+		panic("document: cannot register a comparator for the built-in type " + t.String())
+	}
+
+	comparatorsMu.Lock()
+	defer comparatorsMu.Unlock()
+
+	old, _ := comparators.Load().(map[ValueType]func(a, b Value) int)
+	m := make(map[ValueType]func(a, b Value) int, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+
+	if cmp == nil {
+		delete(m, t)
+	} else {
+		m[t] = cmp
+	}
+
+	comparators.Store(m)
+}
+
+// HasComparator returns true if a comparator was registered for t.
+func HasComparator(t ValueType) bool {
+	return comparator(t) != nil
+}
+
+func comparator(t ValueType) func(a, b Value) int {
+	m, _ := comparators.Load().(map[ValueType]func(a, b Value) int)
+	return m[t]
+}
+
+func compareWithComparator(op operator, cmp func(a, b Value) int, l, r Value) bool {
+	c := cmp(l, r)
+
+	switch op {
+	case operatorEq:
+		return c == 0
+	case operatorGt:
+		return c > 0
+	case operatorGte:
+		return c >= 0
+	case operatorLt:
+		return c < 0
+	case operatorLte:
+		return c <= 0
+	}
+
+	return false
+}
+
 func compare(op operator, l, r Value) (bool, error) {
+	if l.Type == r.Type {
+		if cmp := comparator(l.Type); cmp != nil {
+			return compareWithComparator(op, cmp, l, r), nil
+		}
+	}
+
 	switch {
 	// deal with nil
 	case l.Type == NullValue || r.Type == NullValue:
@@ -372,15 +446,17 @@ func compareDocuments(op operator, l, r Document) (bool, error) {
 		}
 	}
 
+	// all the fields of the shortest document are equal to the first
+	// fields of the other document: the shortest is the lowest.
 	switch {
-	case i > j:
+	case len(lf) > len(rf):
 		switch op {
 		case operatorEq, operatorLt, operatorLte:
 			return false, nil
 		default:
 			return true, nil
 		}
-	case i < j:
+	case len(lf) < len(rf):
 		switch op {
 		case operatorEq, operatorGt, operatorGte:
 			return false, nil
@@ -1,8 +1,12 @@
 package document_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/genjidb/genji/document"
@@ -222,6 +226,10 @@ func TestCompare(t *testing.T) {
 		{"<", `{"a": 1}`, `{"a": true}`, false, jsonToDocument},
 		{">=", `{"a": 1}`, `{"a": 1}`, true, jsonToDocument},
 		{"<=", `{"a": 1}`, `{"a": 1}`, true, jsonToDocument},
+		{"=", `{"a": 1, "b": 2}`, `{"a": 1}`, false, jsonToDocument},
+		{">", `{"a": 1, "b": 2}`, `{"a": 1}`, true, jsonToDocument},
+		{"<", `{"a": 1}`, `{"a": 1, "b": 2}`, true, jsonToDocument},
+		{">", `{"a": 1}`, `{"a": 1, "b": 2}`, false, jsonToDocument},
 	}
 
 	for _, test := range tests {
@@ -249,3 +257,136 @@ func TestCompare(t *testing.T) {
 		})
 	}
 }
+
+// TestCompareMatchesValueEncoder ensures the comparison operators order values
+// like the keys of the indexes, which are encoded with a ValueEncoder.
+func TestCompareMatchesValueEncoder(t *testing.T) {
+	encode := func(v document.Value) []byte {
+		var buf bytes.Buffer
+		err := document.NewValueEncoder(&buf).Encode(v)
+		require.NoError(t, err)
+		return buf.Bytes()
+	}
+
+	check := func(a, b document.Value) {
+		t.Helper()
+
+		lt, err := a.IsLesserThan(b)
+		require.NoError(t, err)
+		eq, err := a.IsEqual(b)
+		require.NoError(t, err)
+		gt, err := a.IsGreaterThan(b)
+		require.NoError(t, err)
+
+		c := bytes.Compare(encode(a), encode(b))
+		msg := fmt.Sprintf("%v and %v", a, b)
+		require.Equal(t, c < 0, lt, msg)
+		require.Equal(t, c == 0, eq, msg)
+		require.Equal(t, c > 0, gt, msg)
+	}
+
+	t.Run("Zero", func(t *testing.T) {
+		check(document.NewDoubleValue(0), document.NewDoubleValue(math.Copysign(0, -1)))
+	})
+
+	r := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 10000; i++ {
+		a, b := randomValue(r, 0), randomValue(r, 0)
+
+		// values of different types can only be compared within arrays or documents
+		if a.Type == b.Type {
+			check(a, b)
+		}
+		check(document.NewArrayValue(document.NewValueBuffer(a)), document.NewArrayValue(document.NewValueBuffer(b)))
+		check(document.NewDocumentValue(document.NewFieldBuffer().Add("a", a)), document.NewDocumentValue(document.NewFieldBuffer().Add("a", b)))
+	}
+}
+
+// versionType is a custom type whose values are version strings like "1.10.2".
+const versionType document.ValueType = 0x10
+
+func compareVersions(a, b document.Value) int {
+	var av, bv [3]int
+	fmt.Sscanf(a.V.(string), "%d.%d.%d", &av[0], &av[1], &av[2])
+	fmt.Sscanf(b.V.(string), "%d.%d.%d", &bv[0], &bv[1], &bv[2])
+
+	for i := range av {
+		if av[i] != bv[i] {
+			return av[i] - bv[i]
+		}
+	}
+
+	return 0
+}
+
+func TestRegisterComparator(t *testing.T) {
+	v := func(s string) document.Value {
+		return document.Value{Type: versionType, V: s}
+	}
+
+	document.RegisterComparator(versionType, compareVersions)
+	defer document.RegisterComparator(versionType, nil)
+
+	t.Run("Compare", func(t *testing.T) {
+		ok, err := v("1.9.0").IsLesserThan(v("1.10.0"))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = v("1.10.0").IsGreaterThanOrEqual(v("1.10.0"))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = v("2.0.0").IsEqual(v("2.0.0"))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = v("2.0.0").IsNotEqual(v("2.0.1"))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// values of other types use the built-in rules
+		ok, err = v("1.0.0").IsEqual(document.NewTextValue("1.0.0"))
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		a := document.NewArrayValue(document.NewValueBuffer(v("1.9.0")))
+		b := document.NewArrayValue(document.NewValueBuffer(v("1.10.0")))
+
+		ok, err := a.IsLesserThan(b)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		values := []document.Value{v("1.10.0"), v("1.2.3"), v("0.9.12"), v("1.9.0")}
+		sort.Slice(values, func(i, j int) bool {
+			ok, err := values[i].IsLesserThan(values[j])
+			require.NoError(t, err)
+			return ok
+		})
+
+		require.Equal(t, []document.Value{v("0.9.12"), v("1.2.3"), v("1.9.0"), v("1.10.0")}, values)
+	})
+
+	t.Run("Built-in type", func(t *testing.T) {
+		require.Panics(t, func() {
+			document.RegisterComparator(document.TextValue, func(a, b document.Value) int { return 0 })
+		})
+
+		ok, err := document.NewTextValue("a").IsEqual(document.NewTextValue("A"))
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("Unregister", func(t *testing.T) {
+		document.RegisterComparator(versionType, nil)
+		defer document.RegisterComparator(versionType, compareVersions)
+
+		ok, err := v("1.9.0").IsLesserThan(v("1.10.0"))
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
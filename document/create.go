@@ -155,7 +155,9 @@ func newFromStruct(ref reflect.Value) (Document, error) {
 				continue
 			}
 
-			field = gtag
+			if name, _ := parseStructTag(gtag); name != "" {
+				field = name
+			}
 		}
 
 		v, err := NewValue(f.Interface())
@@ -169,6 +171,18 @@ func newFromStruct(ref reflect.Value) (Document, error) {
 	return &fb, nil
 }
 
+// parseStructTag returns the field name and the options of the "genji" key of a struct field tag.
+func parseStructTag(tag string) (name string, strict bool) {
+	opts := strings.Split(tag, ",")
+	for _, opt := range opts[1:] {
+		if opt == "strict" {
+			strict = true
+		}
+	}
+
+	return opts[0], strict
+}
+
 // NewValue creates a value whose type is infered from x.
 func NewValue(x interface{}) (Value, error) {
 	// Attempt exact matches first:
@@ -179,6 +193,8 @@ func NewValue(x interface{}) (Value, error) {
 		return NewTextValue(v.Format(time.RFC3339Nano)), nil
 	case nil:
 		return NewNullValue(), nil
+	case Value:
+		return v, nil
 	case Document:
 		return NewDocumentValue(v), nil
 	case Array:
@@ -239,6 +255,27 @@ func NewValue(x interface{}) (Value, error) {
 	return Value{}, &ErrUnsupportedType{x, ""}
 }
 
+// GoType returns the most natural Go type for values of type t,
+// which NewValue converts back to a value of type t.
+// It returns nil for null values, arrays and documents, which have
+// no single Go equivalent.
+func (t ValueType) GoType() reflect.Type {
+	switch t {
+	case BoolValue:
+		return reflect.TypeOf(false)
+	case IntegerValue:
+		return reflect.TypeOf(int64(0))
+	case DoubleValue:
+		return reflect.TypeOf(float64(0))
+	case TextValue:
+		return reflect.TypeOf("")
+	case BlobValue:
+		return reflect.TypeOf([]byte(nil))
+	}
+
+	return nil
+}
+
 type sliceArray struct {
 	ref reflect.Value
 }
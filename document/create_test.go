@@ -1,6 +1,7 @@
 package document_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/genjidb/genji/document"
@@ -141,3 +142,34 @@ func BenchmarkJSONToDocument(b *testing.B) {
 		})
 	}
 }
+
+func TestValueTypeGoType(t *testing.T) {
+	tests := []struct {
+		typ      document.ValueType
+		expected reflect.Type
+	}{
+		{document.BoolValue, reflect.TypeOf(true)},
+		{document.IntegerValue, reflect.TypeOf(int64(0))},
+		{document.DoubleValue, reflect.TypeOf(float64(0))},
+		{document.TextValue, reflect.TypeOf("")},
+		{document.BlobValue, reflect.TypeOf([]byte{})},
+		{document.NullValue, nil},
+		{document.ArrayValue, nil},
+		{document.DocumentValue, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.typ.String(), func(t *testing.T) {
+			typ := test.typ.GoType()
+			require.Equal(t, test.expected, typ)
+			if typ == nil {
+				return
+			}
+
+			// values of that type are converted back to test.typ
+			v, err := document.NewValue(reflect.New(typ).Elem().Interface())
+			require.NoError(t, err)
+			require.Equal(t, test.typ, v.Type)
+		})
+	}
+}
@@ -252,6 +252,74 @@ func (fb *FieldBuffer) Delete(field string) error {
 	return ErrFieldNotFound
 }
 
+// DeletePath removes the value at the given path from the buffer.
+// Removing an array element shifts the elements that follow it.
+// If the path doesn't resolve to a value, it returns ErrFieldNotFound.
+func (fb *FieldBuffer) DeletePath(path Path) error {
+	if len(path) == 0 || path[0].FieldName == "" {
+		return ErrFieldNotFound
+	}
+
+	if len(path) == 1 {
+		return fb.Delete(path[0].FieldName)
+	}
+
+	for i := range fb.fields {
+		if fb.fields[i].Field == path[0].FieldName {
+			va, err := deleteValueAtPath(fb.fields[i].Value, path[1:])
+			if err != nil {
+				return err
+			}
+
+			fb.fields[i].Value = va
+			return nil
+		}
+	}
+
+	return ErrFieldNotFound
+}
+
+// deleteValueAtPath returns a copy of v without the value at the given path.
+func deleteValueAtPath(v Value, p Path) (Value, error) {
+	switch {
+	case v.Type == DocumentValue && p[0].FieldName != "":
+		var buf FieldBuffer
+		err := buf.ScanDocument(v.V.(Document))
+		if err != nil {
+			return v, err
+		}
+
+		err = buf.DeletePath(p)
+		return NewDocumentValue(&buf), err
+	case v.Type == ArrayValue && p[0].FieldName == "":
+		var vb ValueBuffer
+		err := vb.ScanArray(v.V.(Array))
+		if err != nil {
+			return v, err
+		}
+
+		if len(p) == 1 {
+			err = vb.Delete(p[0].ArrayIndex)
+			return NewArrayValue(&vb), err
+		}
+
+		va, err := vb.GetByIndex(p[0].ArrayIndex)
+		if err != nil {
+			return v, err
+		}
+
+		va, err = deleteValueAtPath(va, p[1:])
+		if err != nil {
+			return v, err
+		}
+
+		err = vb.Replace(p[0].ArrayIndex, va)
+		return NewArrayValue(&vb), err
+	}
+
+	return v, ErrFieldNotFound
+}
+
 // Replace the value of the field by v.
 func (fb *FieldBuffer) Replace(field string, v Value) error {
 	for i := range fb.fields {
@@ -421,11 +489,173 @@ func (p Path) IsEqual(other Path) bool {
 	return true
 }
 
+// Overlaps returns true if one of p and other is a prefix of the other,
+// in which case modifying the value at one path may change the value at the other.
+func (p Path) Overlaps(other Path) bool {
+	if len(other) < len(p) {
+		p, other = other, p
+	}
+
+	return p.IsEqual(other[:len(p)])
+}
+
 // GetValue from a document.
 func (p Path) GetValue(d Document) (Value, error) {
 	return p.getValueFromDocument(d)
 }
 
+// GetValueFromArray returns the value at path p of the array a.
+// The path must start with an array index.
+func (p Path) GetValueFromArray(a Array) (Value, error) {
+	return p.getValueFromArray(a)
+}
+
+// Fold returns a copy of p where each field name is replaced by the name
+// of the first field of d, in iteration order, that matches it case-insensitively.
+// Once a field doesn't match any field of d, the rest of the path is kept as is.
+func (p Path) Fold(d Document) Path {
+	folded := append(Path(nil), p...)
+
+	v := NewDocumentValue(d)
+	for i := range folded {
+		var err error
+
+		switch {
+		case folded[i].FieldName != "" && v.Type == DocumentValue:
+			v, err = foldField(v.V.(Document), &folded[i].FieldName)
+		case folded[i].FieldName == "" && v.Type == ArrayValue:
+			v, err = v.V.(Array).GetByIndex(folded[i].ArrayIndex)
+		default:
+			return folded
+		}
+		if err != nil {
+			return folded
+		}
+	}
+
+	return folded
+}
+
+// FoldDocument returns a document whose GetByField method matches field names
+// case-insensitively, the first matching field of d winning.
+// Documents returned by GetByField are folded as well, which allows
+// scanning d with StructScan regardless of the case of its field names.
+func FoldDocument(d Document) Document {
+	return foldDocument{d}
+}
+
+type foldDocument struct {
+	Document
+}
+
+func (d foldDocument) GetByField(field string) (Value, error) {
+	v, err := foldField(d.Document, &field)
+	if err == nil && v.Type == DocumentValue {
+		v = NewDocumentValue(foldDocument{v.V.(Document)})
+	}
+
+	return v, err
+}
+
+// A FieldMask selects top-level fields of a document.
+// A nil FieldMask selects all the fields.
+type FieldMask []string
+
+// NewFieldMask creates a FieldMask selecting the given fields.
+// Duplicate fields are ignored.
+func NewFieldMask(fields ...string) FieldMask {
+	m := make(FieldMask, 0, len(fields))
+	for _, f := range fields {
+		if !m.Contains(f) {
+			m = append(m, f)
+		}
+	}
+
+	return m
+}
+
+// Contains reports whether m selects the given field.
+func (m FieldMask) Contains(field string) bool {
+	if m == nil {
+		return true
+	}
+
+	for _, f := range m {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply returns a document that only contains the fields of d selected by m,
+// in the order of m. Fields are read using GetByField, so that if d supports
+// random access, only the selected fields are decoded.
+// Selected fields that don't exist in d are ignored.
+func (m FieldMask) Apply(d Document) Document {
+	if m == nil {
+		return d
+	}
+
+	return maskedDocument{d: d, mask: m}
+}
+
+type maskedDocument struct {
+	d    Document
+	mask FieldMask
+}
+
+func (d maskedDocument) GetByField(field string) (Value, error) {
+	if !d.mask.Contains(field) {
+		return Value{}, ErrFieldNotFound
+	}
+
+	return d.d.GetByField(field)
+}
+
+func (d maskedDocument) Iterate(fn func(field string, value Value) error) error {
+	for _, f := range d.mask {
+		v, err := d.d.GetByField(f)
+		if err == ErrFieldNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		err = fn(f, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// foldField looks for the first field of d whose name matches field case-insensitively.
+// If found, field is replaced by its name and its value is returned.
+func foldField(d Document, field *string) (Value, error) {
+	var found Value
+
+	err := d.Iterate(func(f string, v Value) error {
+		if strings.EqualFold(f, *field) {
+			*field = f
+			found = v
+			return errStop
+		}
+		return nil
+	})
+	if err == errStop {
+		return found, nil
+	}
+	if err == nil {
+		err = ErrFieldNotFound
+	}
+
+	return Value{}, err
+}
+
 func (p Path) getValueFromDocument(d Document) (Value, error) {
 	if len(p) == 0 {
 		return Value{}, ErrFieldNotFound
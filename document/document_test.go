@@ -157,6 +157,51 @@ func TestFieldBuffer(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("DeletePath", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			path    string
+			want    string
+			wantErr bool
+		}{
+			{"Field", "a", `{"b": {"c": 1, "d": 2}, "e": [1, {"f": 2}, 3]}`, false},
+			{"Nested field", "b.c", `{"a": 1, "b": {"d": 2}, "e": [1, {"f": 2}, 3]}`, false},
+			{"Array element", "e[0]", `{"a": 1, "b": {"c": 1, "d": 2}, "e": [{"f": 2}, 3]}`, false},
+			{"Last array element", "e[2]", `{"a": 1, "b": {"c": 1, "d": 2}, "e": [1, {"f": 2}]}`, false},
+			{"Field in array", "e[1].f", `{"a": 1, "b": {"c": 1, "d": 2}, "e": [1, {}, 3]}`, false},
+			{"Unknown field", "z", "", true},
+			{"Unknown nested field", "b.z", "", true},
+			{"Out of range", "e[3]", "", true},
+			{"Index of document", "b[0]", "", true},
+			{"Field of array", "e.f", "", true},
+			{"Field of integer", "a.b", "", true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				d := document.NewFromJSON([]byte(`{"a": 1, "b": {"c": 1, "d": 2}, "e": [1, {"f": 2}, 3]}`))
+
+				var fb document.FieldBuffer
+				err := fb.Copy(d)
+				require.NoError(t, err)
+
+				p, err := parser.ParsePath(tt.path)
+				require.NoError(t, err)
+
+				err = fb.DeletePath(p)
+				if tt.wantErr {
+					require.Equal(t, document.ErrFieldNotFound, err)
+					return
+				}
+				require.NoError(t, err)
+
+				data, err := document.MarshalJSON(&fb)
+				require.NoError(t, err)
+				require.JSONEq(t, tt.want, string(data))
+			})
+		}
+	})
+
 	t.Run("Replace", func(t *testing.T) {
 		var buf document.FieldBuffer
 		buf.Add("a", document.NewIntegerValue(10))
@@ -571,6 +616,97 @@ func TestPath(t *testing.T) {
 	}
 }
 
+func TestPathFold(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		path   string
+		result string
+	}{
+		{"exact", `{"a": {"b": 1}}`, `a.b`, `a.b`},
+		{"different case", `{"Abc": {"DEF": [{"x": 1}]}}`, `aBC.def[0].X`, `Abc.DEF[0].x`},
+		{"first match wins", `{"B": 1, "a": 2, "b": 3, "A": 4}`, `A`, `a`},
+		{"unknown field", `{"a": {"b": 1}}`, `A.C.D`, `a.C.D`},
+		{"not a document", `{"a": 1}`, `A.b`, `a.b`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf document.FieldBuffer
+
+			err := json.Unmarshal([]byte(test.data), &buf)
+			require.NoError(t, err)
+			p, err := parser.ParsePath(test.path)
+			require.NoError(t, err)
+			require.Equal(t, test.result, p.Fold(&buf).String())
+			// p is not modified.
+			require.Equal(t, test.path, p.String())
+		})
+	}
+}
+
+func TestPathOverlaps(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		overlaps bool
+	}{
+		{`a`, `a`, true},
+		{`a`, `a.b`, true},
+		{`a.b[0]`, `a`, true},
+		{`a.b`, `a.c`, false},
+		{`a[0]`, `a[1]`, false},
+		{`a`, `b.a`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+"/"+test.b, func(t *testing.T) {
+			a, err := parser.ParsePath(test.a)
+			require.NoError(t, err)
+			b, err := parser.ParsePath(test.b)
+			require.NoError(t, err)
+			require.Equal(t, test.overlaps, a.Overlaps(b))
+			require.Equal(t, test.overlaps, b.Overlaps(a))
+		})
+	}
+}
+
+// getByFieldOnly is a document that can only be read using GetByField.
+type getByFieldOnly struct {
+	document.Document
+}
+
+func (d getByFieldOnly) Iterate(fn func(field string, value document.Value) error) error {
+	return errors.New("unexpected call to Iterate")
+}
+
+func TestFieldMask(t *testing.T) {
+	d := getByFieldOnly{document.NewFieldBuffer().
+		Add("a", document.NewIntegerValue(1)).
+		Add("b", document.NewIntegerValue(2)).
+		Add("c", document.NewIntegerValue(3))}
+
+	t.Run("Apply", func(t *testing.T) {
+		m := document.NewFieldMask("c", "a", "c", "d")
+		require.Equal(t, document.FieldMask{"c", "a", "d"}, m)
+
+		var fb document.FieldBuffer
+		err := fb.Copy(m.Apply(d))
+		require.NoError(t, err)
+		require.Equal(t, document.NewFieldBuffer().
+			Add("c", document.NewIntegerValue(3)).
+			Add("a", document.NewIntegerValue(1)), &fb)
+
+		_, err = m.Apply(d).GetByField("b")
+		require.Equal(t, document.ErrFieldNotFound, err)
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		var m document.FieldMask
+		require.True(t, m.Contains("foo"))
+		require.Equal(t, d, m.Apply(d))
+	})
+}
+
 func TestJSONDocument(t *testing.T) {
 	tests := []struct {
 		name     string
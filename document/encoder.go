@@ -0,0 +1,69 @@
+package document
+
+import (
+	"io"
+)
+
+// A DocumentEncoder encodes documents to an underlying writer.
+// It is implemented by the encoders of every codec.
+type DocumentEncoder interface {
+	EncodeDocument(d Document) error
+}
+
+// An Encoder encodes documents using a DocumentEncoder that is created once
+// and reused for every document. Documents are encoded in an internal scratch
+// buffer whose capacity is kept between calls, or appended to a buffer provided by the caller.
+// An Encoder is not safe for concurrent use, but can be pooled and reused with Reset.
+type Encoder struct {
+	enc     DocumentEncoder
+	w       appendWriter
+	scratch []byte
+}
+
+// NewEncoder creates an Encoder. newEncoder is called once to create the underlying
+// encoder, with a writer owned by the Encoder.
+func NewEncoder(newEncoder func(w io.Writer) DocumentEncoder) *Encoder {
+	var e Encoder
+	e.enc = newEncoder(&e.w)
+	return &e
+}
+
+// Reset empties the scratch buffer, keeping its capacity.
+func (e *Encoder) Reset() {
+	e.scratch = e.scratch[:0]
+	e.w.b = nil
+}
+
+// Encode d into the scratch buffer and return its content.
+// The returned slice is only valid until the next call to Encode or Reset.
+func (e *Encoder) Encode(d Document) ([]byte, error) {
+	var err error
+
+	e.scratch, err = e.EncodeInto(e.scratch[:0], d)
+	return e.scratch, err
+}
+
+// EncodeInto appends the encoding of d to dst and returns the extended buffer.
+// If dst has enough capacity, no allocation is made.
+func (e *Encoder) EncodeInto(dst []byte, d Document) ([]byte, error) {
+	e.w.b = dst
+	err := e.enc.EncodeDocument(d)
+	dst = e.w.b
+	e.w.b = nil
+	return dst, err
+}
+
+// appendWriter appends everything it is given to a byte slice.
+type appendWriter struct {
+	b []byte
+}
+
+func (w *appendWriter) Write(p []byte) (int, error) {
+	w.b = append(w.b, p...)
+	return len(p), nil
+}
+
+func (w *appendWriter) WriteByte(c byte) error {
+	w.b = append(w.b, c)
+	return nil
+}
@@ -0,0 +1,85 @@
+package document_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/document/encoding/msgpack"
+	"github.com/stretchr/testify/require"
+)
+
+func newMsgpackEncoder() *document.Encoder {
+	return document.NewEncoder(func(w io.Writer) document.DocumentEncoder {
+		return msgpack.NewEncoder(w)
+	})
+}
+
+func TestEncoder(t *testing.T) {
+	d := document.NewFromJSON([]byte(`{"a": 1, "b": "foo", "c": [true, null], "d": {"e": 1.5}}`))
+
+	var buf bytes.Buffer
+	err := msgpack.NewEncoder(&buf).EncodeDocument(d)
+	require.NoError(t, err)
+	expected := buf.Bytes()
+
+	t.Run("Encode", func(t *testing.T) {
+		enc := newMsgpackEncoder()
+
+		for i := 0; i < 3; i++ {
+			data, err := enc.Encode(d)
+			require.NoError(t, err)
+			require.Equal(t, expected, data)
+		}
+
+		enc.Reset()
+		data, err := enc.Encode(d)
+		require.NoError(t, err)
+		require.Equal(t, expected, data)
+	})
+
+	t.Run("EncodeInto", func(t *testing.T) {
+		enc := newMsgpackEncoder()
+
+		dst := make([]byte, 0, 3+len(expected))
+		dst = append(dst, "foo"...)
+		res, err := enc.EncodeInto(dst, d)
+		require.NoError(t, err)
+		require.Equal(t, append([]byte("foo"), expected...), res)
+		// dst was large enough
+		require.Equal(t, &dst[:1][0], &res[0])
+
+		// the scratch buffer is left untouched
+		data, err := enc.Encode(document.NewFieldBuffer())
+		require.NoError(t, err)
+		require.NotEqual(t, expected, data)
+		require.Equal(t, append([]byte("foo"), expected...), res)
+	})
+}
+
+func BenchmarkEncoder(b *testing.B) {
+	var fb document.FieldBuffer
+	for i := int64(0); i < 10; i++ {
+		fb.Add("name", document.NewIntegerValue(i))
+	}
+
+	b.Run("New", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var buf bytes.Buffer
+			msgpack.NewEncoder(&buf).EncodeDocument(&fb)
+		}
+	})
+
+	b.Run("Reused", func(b *testing.B) {
+		enc := newMsgpackEncoder()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			enc.Encode(&fb)
+			enc.Reset()
+		}
+	})
+}
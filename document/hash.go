@@ -0,0 +1,113 @@
+package document
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+
+	"github.com/genjidb/genji/binarysort"
+)
+
+// keyEnd marks the end of an array in keys generated by AppendKey.
+// It can't be confused with a type.
+const keyEnd = 0
+
+// AppendKey appends to dst a canonical binary representation of v
+// and returns the extended buffer.
+// Values that are equal according to IsEqual produce the same key,
+// and different values produce different keys. In particular, integers
+// and doubles representing the same number produce the same key,
+// except for integers that can't be represented exactly by a double.
+// As with IsEqual, this only applies to v itself: numbers nested in arrays
+// or documents must have the same type to be considered equal.
+// Fields of documents are encoded in lexicographic order.
+func AppendKey(dst []byte, v Value) ([]byte, error) {
+	if v.Type == DoubleValue {
+		f := v.V.(float64)
+		if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			v = NewIntegerValue(int64(f))
+		}
+	}
+
+	return appendKey(dst, v)
+}
+
+// Hash returns a 64 bit hash of the key of v, as returned by AppendKey.
+func Hash(v Value) (uint64, error) {
+	key, err := AppendKey(nil, v)
+	if err != nil {
+		return 0, err
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write(key)
+	return h.Sum64(), nil
+}
+
+func appendKey(dst []byte, v Value) ([]byte, error) {
+	dst = append(dst, byte(v.Type))
+
+	switch v.Type {
+	case NullValue:
+		return dst, nil
+	case BoolValue:
+		return binarysort.AppendBool(dst, v.V.(bool)), nil
+	case IntegerValue:
+		return binarysort.AppendInt64(dst, v.V.(int64)), nil
+	case DoubleValue:
+		return binarysort.AppendFloat64(dst, v.V.(float64)), nil
+	case TextValue:
+		return appendKeyBytes(dst, []byte(v.V.(string))), nil
+	case BlobValue:
+		return appendKeyBytes(dst, v.V.([]byte)), nil
+	case ArrayValue:
+		err := v.V.(Array).Iterate(func(i int, value Value) error {
+			var err error
+			dst, err = appendKey(dst, value)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return append(dst, keyEnd), nil
+	case DocumentValue:
+		d := v.V.(Document)
+		fields, err := Fields(d)
+		if err != nil {
+			return nil, err
+		}
+
+		dst = appendUvarint(dst, uint64(len(fields)))
+		for _, f := range fields {
+			value, err := d.GetByField(f)
+			if err != nil {
+				return nil, err
+			}
+
+			dst = appendKeyBytes(dst, []byte(f))
+			dst, err = appendKey(dst, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return dst, nil
+	}
+
+	return nil, errors.New("cannot generate key for type " + v.Type.String())
+}
+
+// appendKeyBytes appends b prefixed by its length.
+func appendKeyBytes(dst []byte, b []byte) []byte {
+	dst = appendUvarint(dst, uint64(len(b)))
+	return append(dst, b...)
+}
+
+func appendUvarint(dst []byte, x uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+
+	n := binary.PutUvarint(buf[:], x)
+	return append(dst, buf[:n]...)
+}
@@ -0,0 +1,118 @@
+package document_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendKey(t *testing.T) {
+	doc := func(fields ...interface{}) document.Value {
+		fb := document.NewFieldBuffer()
+		for i := 0; i < len(fields); i += 2 {
+			fb.Add(fields[i].(string), fields[i+1].(document.Value))
+		}
+		return document.NewDocumentValue(fb)
+	}
+	arr := func(values ...document.Value) document.Value {
+		return document.NewArrayValue(document.NewValueBuffer(values...))
+	}
+
+	tests := []struct {
+		name  string
+		a, b  document.Value
+		equal bool
+	}{
+		{"integer and double", document.NewIntegerValue(2), document.NewDoubleValue(2), true},
+		{"integer and fractional double", document.NewIntegerValue(2), document.NewDoubleValue(2.5), false},
+		{"zero and negative zero", document.NewDoubleValue(0), document.NewDoubleValue(-1 * 0.0), true},
+		{"text and blob", document.NewTextValue("a"), document.NewBlobValue([]byte("a")), false},
+		{"nested numbers", arr(document.NewIntegerValue(2)), arr(document.NewDoubleValue(2)), false},
+		{"array prefix", arr(document.NewTextValue("a")), arr(document.NewTextValue("a"), document.NewTextValue("")), false},
+		{"texts boundaries", arr(document.NewTextValue("ab"), document.NewTextValue("c")), arr(document.NewTextValue("a"), document.NewTextValue("bc")), false},
+		{"field order", doc("a", document.NewIntegerValue(1), "b", document.NewNullValue()), doc("b", document.NewNullValue(), "a", document.NewIntegerValue(1)), true},
+		{"empty field name", doc("", document.NewNullValue()), doc(), false},
+		{"documents", doc("a", arr()), doc("a", doc()), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ka, err := document.AppendKey(nil, test.a)
+			require.NoError(t, err)
+			kb, err := document.AppendKey(nil, test.b)
+			require.NoError(t, err)
+			require.Equal(t, test.equal, bytes.Equal(ka, kb))
+
+			ha, err := document.Hash(test.a)
+			require.NoError(t, err)
+			hb, err := document.Hash(test.b)
+			require.NoError(t, err)
+			if test.equal {
+				require.Equal(t, ha, hb)
+			}
+
+			ok, err := test.a.IsEqual(test.b)
+			require.NoError(t, err)
+			require.Equal(t, test.equal, ok)
+		})
+	}
+}
+
+// randomValue generates small values to increase the likelihood of equal values.
+func randomValue(r *rand.Rand, depth int) document.Value {
+	n := 7
+	if depth > 2 {
+		n = 5
+	}
+
+	switch r.Intn(n) {
+	case 0:
+		return document.NewNullValue()
+	case 1:
+		return document.NewBoolValue(r.Intn(2) == 0)
+	case 2:
+		return document.NewIntegerValue(int64(r.Intn(5) - 2))
+	case 3:
+		return document.NewDoubleValue(float64(r.Intn(10)-5) / 2)
+	case 4:
+		return document.NewTextValue(string(rune('a' + r.Intn(2))))
+	case 5:
+		var vb document.ValueBuffer
+		for i := r.Intn(3); i > 0; i-- {
+			vb = vb.Append(randomValue(r, depth+1))
+		}
+		return document.NewArrayValue(vb)
+	default:
+		fb := document.NewFieldBuffer()
+		for i := r.Intn(3); i > 0; i-- {
+			f := string(rune('a' + r.Intn(3)))
+			if _, err := fb.GetByField(f); err == nil {
+				continue
+			}
+			fb.Add(f, randomValue(r, depth+1))
+		}
+		return document.NewDocumentValue(fb)
+	}
+}
+
+func TestAppendKeyMatchesIsEqual(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 10000; i++ {
+		a, b := randomValue(r, 0), randomValue(r, 0)
+
+		ok, err := a.IsEqual(b)
+		require.NoError(t, err)
+
+		ka, err := document.AppendKey(nil, a)
+		require.NoError(t, err)
+		kb, err := document.AppendKey(nil, b)
+		require.NoError(t, err)
+
+		require.Equal(t, ok, bytes.Equal(ka, kb), fmt.Sprintf("%v and %v", a, b))
+	}
+}
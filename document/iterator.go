@@ -2,7 +2,6 @@ package document
 
 import (
 	"bufio"
-	"bytes"
 	"errors"
 	"io"
 )
@@ -288,12 +287,10 @@ func (s Stream) Aggregate(aggregatorBuilders ...AggregatorBuilder) Stream {
 
 		nullValue := NewNullValue()
 
-		var b bytes.Buffer
-
-		enc := NewValueEncoder(&b)
+		var key []byte
 
 		mkGroup := func(g Value) []Aggregator {
-			groupKey := b.String()
+			groupKey := string(key)
 			groupKeys = append(groupKeys, groupKey)
 			aggs := make([]Aggregator, len(aggregatorBuilders))
 			for i, builder := range aggregatorBuilders {
@@ -310,16 +307,14 @@ func (s Stream) Aggregate(aggregatorBuilders ...AggregatorBuilder) Stream {
 				group = gd.group
 			}
 
-			b.Reset()
-
-			err := enc.Encode(group)
+			var err error
+			key, err = AppendKey(key[:0], group)
 			if err != nil {
 				return err
 			}
 
-			// using b.Bytes() and cast as a string is much faster than using b.String()
-			// because of compiler optimizations.
-			aggs, ok := aggregates[string(b.Bytes())]
+			// using string(key) as a map index doesn't allocate.
+			aggs, ok := aggregates[string(key)]
 			if !ok {
 				aggs = mkGroup(group)
 			}
@@ -45,22 +45,62 @@ func Scan(d Document, targets ...interface{}) error {
 // under the "genji" key stored in the struct field's tag.
 // The content of the format string is used instead of the struct field name and passed
 // to the GetByField method.
+// Nested structs are scanned from nested documents, while the fields of embedded structs
+// without tag are scanned from d, like NewFromStruct does. Unexported fields are ignored.
+//
+// Struct fields are reset before being scanned, so that scanning into a struct used
+// by a previous scan never keeps values of the previous document: fields missing from d
+// are set to their zero value, pointers and slices being set to nil. Pointers are also set to nil
+// for null values, while other fields are set to their zero value.
+// If the format string is followed by the "strict" option, like `genji:"name,strict"` or
+// `genji:",strict"`, the field must be present in d unless it is a pointer,
+// otherwise an error wrapping ErrFieldNotFound is returned.
 func StructScan(d Document, t interface{}) error {
+	ref, err := structScanTarget(t)
+	if err != nil {
+		return err
+	}
+
+	return structScan(d, ref, nil)
+}
+
+// StructScanWithFields scans d into t like StructScan and returns the names of the fields
+// of d that were scanned, in the order of the struct fields. The names of the fields of nested
+// documents are not returned. If t implements Scanner, present is nil.
+func StructScanWithFields(d Document, t interface{}) (present []string, err error) {
+	ref, err := structScanTarget(t)
+	if err != nil {
+		return nil, err
+	}
+
+	err = structScan(d, ref, &present)
+	if err != nil {
+		return nil, err
+	}
+
+	return present, nil
+}
+
+func structScanTarget(t interface{}) (reflect.Value, error) {
 	ref := reflect.ValueOf(t)
 
-	if !ref.IsValid() || ref.Kind() != reflect.Ptr {
-		return errors.New("target must be pointer to a valid Go type")
+	if !ref.IsValid() || ref.Kind() != reflect.Ptr || ref.IsNil() {
+		return ref, errors.New("target must be pointer to a valid Go type")
 	}
 
-	if ref.IsNil() {
-		ref.Set(reflect.New(ref.Type().Elem()))
+	if ref.Elem().Kind() != reflect.Struct && !ref.Type().Implements(scannerType) {
+		return ref, &ErrUnsupportedType{t, "target must be pointer to a struct"}
 	}
 
-	return structScan(d, ref)
+	return ref, nil
 }
 
-func structScan(d Document, ref reflect.Value) error {
-	if ref.Type().Implements(reflect.TypeOf((*Scanner)(nil)).Elem()) {
+var scannerType = reflect.TypeOf((*Scanner)(nil)).Elem()
+
+// structScan scans d into the struct ref points to. If present is not nil,
+// the names of the scanned fields are appended to it.
+func structScan(d Document, ref reflect.Value, present *[]string) error {
+	if ref.Type().Implements(scannerType) && ref.CanInterface() {
 		return ref.Interface().(Scanner).ScanDocument(d)
 	}
 
@@ -70,32 +110,79 @@ func structScan(d Document, ref reflect.Value) error {
 	for i := 0; i < l; i++ {
 		f := sref.Field(i)
 		sf := stp.Field(i)
-		var name string
-		if gtag, ok := sf.Tag.Lookup("genji"); ok {
-			if gtag == "-" {
-				continue
+
+		gtag, hasTag := sf.Tag.Lookup("genji")
+		if gtag == "-" {
+			continue
+		}
+		name, strict := parseStructTag(gtag)
+
+		// embedded struct, whose fields are stored in d.
+		if sf.Anonymous && !hasTag && indirectKind(sf.Type) == reflect.Struct {
+			if f.Kind() == reflect.Ptr {
+				// an unexported embedded pointer can't be allocated
+				if f.IsNil() && !f.CanSet() {
+					continue
+				}
+				if f.IsNil() {
+					f.Set(reflect.New(sf.Type.Elem()))
+				}
+				f = f.Elem()
 			}
 
-			name = gtag
-		} else {
+			err := structScan(d, f.Addr(), present)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
 			name = strings.ToLower(sf.Name)
 		}
+
+		// never keep the value of a previous scan
+		f.Set(reflect.Zero(sf.Type))
+
 		v, err := d.GetByField(name)
 		if err == ErrFieldNotFound {
+			if strict && sf.Type.Kind() != reflect.Ptr {
+				return fmt.Errorf("%w: %q", err, name)
+			}
 			continue
 		}
 		if err != nil {
 			return err
 		}
 
-		if err := scanValue(v, f); err != nil {
-			return err
+		// pointers are left nil for null values
+		if v.Type != NullValue || sf.Type.Kind() != reflect.Ptr {
+			if err := scanValue(v, f); err != nil {
+				return err
+			}
+		}
+
+		if present != nil {
+			*present = append(*present, name)
 		}
 	}
 
 	return nil
 }
 
+// indirectKind returns the kind of tp, or of the type it points to if it is a pointer.
+func indirectKind(tp reflect.Type) reflect.Kind {
+	if tp.Kind() == reflect.Ptr {
+		return tp.Elem().Kind()
+	}
+
+	return tp.Kind()
+}
+
 // SliceScan scans a document array into a slice or fixed size array. t must be a pointer
 // to a valid slice or array.
 //
@@ -333,7 +420,7 @@ func scanValue(v Value, ref reflect.Value) error {
 			return err
 		}
 
-		return structScan(v.V.(Document), ref)
+		return structScan(v.V.(Document), ref, nil)
 	case reflect.Slice:
 		if ref.Type().Elem().Kind() == reflect.Uint8 {
 			if v.Type != TextValue && v.Type != BlobValue {
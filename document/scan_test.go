@@ -2,6 +2,7 @@ package document_test
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 	"time"
 
@@ -122,6 +123,84 @@ func TestScan(t *testing.T) {
 		},
 	}, r)
 
+	t.Run("Struct", func(t *testing.T) {
+		type address struct {
+			City    string
+			ZipCode int `genji:"zip_code"`
+		}
+
+		type base struct {
+			ID int64 `genji:"_id"`
+		}
+
+		type user struct {
+			base
+			*Meta
+			Name     string
+			Age      uint8 `genji:"user_age"`
+			Score    float32
+			Admin    bool
+			Address  address `genji:"addr"`
+			Previous *address
+			Tags     []string
+			Ignored  string `genji:"-"`
+			password string
+		}
+
+		d := document.NewFieldBuffer().
+			Add("_id", document.NewIntegerValue(1)).
+			Add("version", document.NewIntegerValue(3)).
+			Add("name", document.NewTextValue("foo")).
+			Add("user_age", document.NewIntegerValue(10)).
+			Add("score", document.NewDoubleValue(1.5)).
+			Add("admin", document.NewBoolValue(true)).
+			Add("addr", document.NewDocumentValue(document.NewFieldBuffer().
+				Add("city", document.NewTextValue("Lyon")).
+				Add("zip_code", document.NewIntegerValue(69001)))).
+			Add("previous", document.NewDocumentValue(document.NewFieldBuffer().
+				Add("city", document.NewTextValue("Paris")))).
+			Add("tags", document.NewArrayValue(document.NewValueBuffer(
+				document.NewTextValue("a"),
+				document.NewTextValue("b")))).
+			Add("ignored", document.NewTextValue("ignored")).
+			Add("password", document.NewTextValue("secret"))
+
+		var u user
+		err := document.StructScan(d, &u)
+		require.NoError(t, err)
+		require.Equal(t, user{
+			base:     base{ID: 1},
+			Meta:     &Meta{Version: 3},
+			Name:     "foo",
+			Age:      10,
+			Score:    1.5,
+			Admin:    true,
+			Address:  address{City: "Lyon", ZipCode: 69001},
+			Previous: &address{City: "Paris"},
+			Tags:     []string{"a", "b"},
+		}, u)
+
+		// documents created from structs can be scanned back
+		d2, err := document.NewFromStruct(&Meta{Version: 4})
+		require.NoError(t, err)
+		var m Meta
+		require.NoError(t, document.StructScan(d2, &m))
+		require.Equal(t, Meta{Version: 4}, m)
+	})
+
+	t.Run("Struct/Invalid target", func(t *testing.T) {
+		var u *Meta
+		require.Error(t, document.StructScan(doc, u))
+		require.Error(t, document.StructScan(doc, Meta{}))
+		var s string
+		require.Error(t, document.StructScan(doc, &s))
+
+		// type mismatch
+		var m Meta
+		d := document.NewFieldBuffer().Add("version", document.NewTextValue("foo"))
+		require.Error(t, document.StructScan(d, &m))
+	})
+
 	t.Run("DocumentScanner", func(t *testing.T) {
 		var ds documentScanner
 		ds.fn = func(d document.Document) error {
@@ -132,6 +211,132 @@ func TestScan(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("Struct/Missing fields", func(t *testing.T) {
+		type address struct {
+			City    string
+			ZipCode int `genji:"zip_code"`
+		}
+
+		type user struct {
+			*Meta
+			Name     string
+			Nickname *string
+			Address  address
+			Previous *address
+			Tags     []string
+			Counts   map[string]int
+		}
+
+		nick := "bar"
+		u := user{
+			Meta:     &Meta{Version: 1},
+			Name:     "foo",
+			Nickname: &nick,
+			Address:  address{City: "Lyon", ZipCode: 69001},
+			Previous: &address{City: "Paris"},
+			Tags:     []string{"a", "b"},
+			Counts:   map[string]int{"a": 1},
+		}
+
+		// scanning into a used struct doesn't keep the previous values
+		d := document.NewFieldBuffer().
+			Add("address", document.NewDocumentValue(document.NewFieldBuffer().
+				Add("city", document.NewTextValue("Nice")))).
+			Add("counts", document.NewDocumentValue(document.NewFieldBuffer().
+				Add("b", document.NewIntegerValue(2))))
+		err := document.StructScan(d, &u)
+		require.NoError(t, err)
+		require.Equal(t, user{
+			Meta:    &Meta{},
+			Address: address{City: "Nice"},
+			Counts:  map[string]int{"b": 2},
+		}, u)
+
+		present, err := document.StructScanWithFields(d, &u)
+		require.NoError(t, err)
+		require.Equal(t, []string{"address", "counts"}, present)
+
+		d = document.NewFieldBuffer().
+			Add("version", document.NewIntegerValue(2)).
+			Add("tags", document.NewArrayValue(document.NewValueBuffer(document.NewTextValue("c")))).
+			Add("nickname", document.NewTextValue("baz")).
+			Add("name", document.NewTextValue("foo"))
+		present, err = document.StructScanWithFields(d, &u)
+		require.NoError(t, err)
+		require.Equal(t, []string{"version", "name", "nickname", "tags"}, present)
+		baz := "baz"
+		require.Equal(t, user{
+			Meta:     &Meta{Version: 2},
+			Name:     "foo",
+			Nickname: &baz,
+			Tags:     []string{"c"},
+		}, u)
+
+		// null values set pointers to nil
+		d = document.NewFieldBuffer().
+			Add("nickname", document.NewNullValue())
+		present, err = document.StructScanWithFields(d, &u)
+		require.NoError(t, err)
+		require.Equal(t, []string{"nickname"}, present)
+		require.Equal(t, user{Meta: &Meta{}}, u)
+
+		present, err = document.StructScanWithFields(document.NewFieldBuffer(), &u)
+		require.NoError(t, err)
+		require.Empty(t, present)
+	})
+
+	t.Run("Struct/Strict", func(t *testing.T) {
+		type address struct {
+			City    string `genji:",strict"`
+			ZipCode int    `genji:"zip_code"`
+		}
+
+		type user struct {
+			Name     string   `genji:"name,strict"`
+			Nickname *string  `genji:",strict"`
+			Address  *address `genji:"addr"`
+			Tags     []string `genji:",strict"`
+		}
+
+		d := document.NewFieldBuffer().
+			Add("name", document.NewTextValue("foo")).
+			Add("tags", document.NewArrayValue(document.NewValueBuffer())).
+			Add("addr", document.NewDocumentValue(document.NewFieldBuffer().
+				Add("city", document.NewTextValue("Lyon"))))
+		var u user
+		err := document.StructScan(d, &u)
+		require.NoError(t, err)
+		require.Equal(t, user{Name: "foo", Address: &address{City: "Lyon"}}, u)
+
+		// missing non-pointer field
+		d = document.NewFieldBuffer().
+			Add("tags", document.NewArrayValue(document.NewValueBuffer()))
+		err = document.StructScan(d, &u)
+		require.True(t, errors.Is(err, document.ErrFieldNotFound))
+		require.EqualError(t, err, `field not found: "name"`)
+
+		d = document.NewFieldBuffer().
+			Add("name", document.NewTextValue("foo"))
+		_, err = document.StructScanWithFields(d, &u)
+		require.EqualError(t, err, `field not found: "tags"`)
+
+		// in nested documents
+		d = document.NewFieldBuffer().
+			Add("name", document.NewTextValue("foo")).
+			Add("tags", document.NewArrayValue(document.NewValueBuffer())).
+			Add("addr", document.NewDocumentValue(document.NewFieldBuffer().
+				Add("zip_code", document.NewIntegerValue(69001))))
+		err = document.StructScan(d, &u)
+		require.EqualError(t, err, `field not found: "city"`)
+
+		// documents created from structs use the name of the tag
+		d2, err := document.NewFromStruct(&user{Name: "bar"})
+		require.NoError(t, err)
+		err = document.StructScan(d2, &u)
+		require.NoError(t, err)
+		require.Equal(t, user{Name: "bar"}, u)
+	})
+
 	t.Run("Map", func(t *testing.T) {
 		m := make(map[string]interface{})
 		err := document.MapScan(doc, m)
@@ -174,3 +379,8 @@ type documentScanner struct {
 func (ds documentScanner) ScanDocument(d document.Document) error {
 	return ds.fn(d)
 }
+
+// Meta is exported to be embedded as a pointer by the structs of the tests.
+type Meta struct {
+	Version int
+}
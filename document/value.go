@@ -185,8 +185,14 @@ func NewZeroValue(t ValueType) Value {
 	return Value{}
 }
 
-// IsTruthy returns whether v is not equal to the zero value of its type.
-func (v Value) IsTruthy() (bool, error) {
+// IsTruthy returns whether v is considered true when used as a condition,
+// like in a WHERE clause or with the AND and OR operators.
+// Booleans are used as is, integers and doubles are true if they are not equal to zero,
+// texts and blobs are true if they are not empty, and arrays and documents are true
+// if they contain at least one value. NULL is false: since paths that don't exist
+// evaluate to NULL, they are false too.
+// An error is only returned if the content of an array or a document can't be read.
+func IsTruthy(v Value) (bool, error) {
 	if v.Type == NullValue {
 		return false, nil
 	}
@@ -195,6 +201,12 @@ func (v Value) IsTruthy() (bool, error) {
 	return !b, err
 }
 
+// IsTruthy returns whether v is considered true when used as a condition.
+// See the IsTruthy function for the rules.
+func (v Value) IsTruthy() (bool, error) {
+	return IsTruthy(v)
+}
+
 // IsZeroValue indicates if the value data is the zero value for the value type.
 // This function doesn't perform any allocation.
 func (v Value) IsZeroValue() (bool, error) {
@@ -211,12 +223,18 @@ func (v Value) IsZeroValue() (bool, error) {
 		return v.V == textZeroValue.V, nil
 	case ArrayValue:
 		// The zero value of an array is an empty array.
-		// Thus, if GetByIndex(0) returns the ErrValueNotFound
-		// it means that the array is empty.
-		_, err := v.V.(Array).GetByIndex(0)
-		if err == ErrValueNotFound {
+		// Not all implementations return the same error
+		// when an index is out of range, so iterate instead.
+		err := v.V.(Array).Iterate(func(_ int, _ Value) error {
+			// We return an error in the first iteration to stop it.
+			return errStop
+		})
+		if err == nil {
 			return true, nil
 		}
+		if err == errStop {
+			return false, nil
+		}
 		return false, err
 	case DocumentValue:
 		err := v.V.(Document).Iterate(func(_ string, _ Value) error {
@@ -297,6 +315,32 @@ func (v Value) String() string {
 	return string(d)
 }
 
+// GoString returns a representation of v including its type,
+// like int64(10) or text("hi"). It implements the fmt.GoStringer interface,
+// used by the %#v verb.
+func (v Value) GoString() string {
+	switch v.Type {
+	case NullValue:
+		return "null"
+	case BoolValue:
+		return "bool(" + v.String() + ")"
+	case IntegerValue:
+		return "int64(" + v.String() + ")"
+	case DoubleValue:
+		return "float64(" + v.String() + ")"
+	case TextValue:
+		return "text(" + v.String() + ")"
+	case BlobValue:
+		return fmt.Sprintf("blob(0x%x)", v.V.([]byte))
+	case ArrayValue:
+		return "array(" + v.String() + ")"
+	case DocumentValue:
+		return "document(" + v.String() + ")"
+	}
+
+	return fmt.Sprintf("Value{Type: %d, V: %#v}", v.Type, v.V)
+}
+
 // Append appends to buf a binary representation of v.
 // The encoded value doesn't include type information.
 func (v Value) Append(buf []byte) ([]byte, error) {
@@ -7,15 +7,22 @@ import (
 	"github.com/genjidb/genji/binarysort"
 )
 
+// delimiters are lower than any type and base64 character, and end characters
+// are lower than delimiters, so that a prefix of an array or a document is ordered
+// before the array or document.
 const (
 	arrayValueDelim    = 0x1f
 	arrayEnd           = 0x1e
-	documentValueDelim = 0x1c
-	documentEnd        = 0x1d
+	documentValueDelim = 0x1d
+	documentEnd        = 0x1c
 )
 
 // ValueEncoder encodes natural sort-ordered representations of values.
 // Type information is encoded alongside each value.
+// Arrays are ordered element by element, and documents field by field, in the order
+// of their sorted field names, comparing the names then the values of the fields.
+// Values of different types nested in arrays or documents are ordered by type.
+// This is the order of the comparison operators, for values of the same type.
 type ValueEncoder struct {
 	w io.Writer
 
@@ -67,7 +74,12 @@ func (ve *ValueEncoder) appendValue(v Value) error {
 	case IntegerValue:
 		ve.buf = binarysort.AppendInt64(ve.buf, v.V.(int64))
 	case DoubleValue:
-		ve.buf = binarysort.AppendFloat64(ve.buf, v.V.(float64))
+		f := v.V.(float64)
+		// -0 and 0 are equal: make sure they are encoded the same way
+		if f == 0 {
+			f = 0
+		}
+		ve.buf = binarysort.AppendFloat64(ve.buf, f)
 	default:
 		return errors.New("cannot encode type " + v.Type.String() + " as key")
 	}
@@ -99,11 +111,14 @@ func (ve *ValueEncoder) appendArray(a Array) error {
 }
 
 // appendDocument encodes a document into a sort-ordered binary representation.
+// Fields are encoded in the order of their names, regardless of the order of the document.
 func (ve *ValueEncoder) appendDocument(d Document) error {
-	var i int
-	err := d.Iterate(func(field string, value Value) error {
-		var err error
+	fields, err := Fields(d)
+	if err != nil {
+		return err
+	}
 
+	for i, field := range fields {
 		if i > 0 {
 			err = ve.append(documentValueDelim)
 			if err != nil {
@@ -111,6 +126,11 @@ func (ve *ValueEncoder) appendDocument(d Document) error {
 			}
 		}
 
+		value, err := d.GetByField(field)
+		if err != nil {
+			return err
+		}
+
 		ve.buf, err = binarysort.AppendBase64(ve.buf[:0], []byte(field))
 		if err != nil {
 			return err
@@ -129,12 +149,6 @@ func (ve *ValueEncoder) appendDocument(d Document) error {
 		if err != nil {
 			return err
 		}
-
-		i++
-		return nil
-	})
-	if err != nil {
-		return err
 	}
 
 	return ve.append(documentEnd)
@@ -142,6 +156,10 @@ func (ve *ValueEncoder) appendDocument(d Document) error {
 
 // decodeValue decodes a value encoded with ValueEncoder.
 func decodeValue(data []byte) (Value, error) {
+	if len(data) == 0 {
+		return Value{}, errors.New("invalid end of input")
+	}
+
 	t := ValueType(data[0])
 	data = data[1:]
 
@@ -196,6 +214,10 @@ func decodeValue(data []byte) (Value, error) {
 }
 
 func decodeValueUntil(data []byte, delim, end byte) (Value, int, error) {
+	if len(data) == 0 {
+		return Value{}, 0, errors.New("invalid end of input")
+	}
+
 	t := ValueType(data[0])
 	i := 1
 
@@ -216,9 +238,13 @@ func decodeValueUntil(data []byte, delim, end byte) (Value, int, error) {
 		return NewDocumentValue(d), i, nil
 	case NullValue:
 	case BoolValue:
+		if len(data) < 2 {
+			return Value{}, 0, errors.New("malformed " + t.String())
+		}
 		i++
 	case IntegerValue, DoubleValue:
-		if i+8 < len(data) && data[i+8] == delim {
+		// the last value of an array or a document is followed by the end character
+		if i+8 < len(data) && (data[i+8] == delim || data[i+8] == end) {
 			i += 8
 		} else {
 			return Value{}, 0, errors.New("malformed " + t.String())
@@ -248,7 +274,7 @@ func decodeArray(data []byte) (Array, int, error) {
 		vb = vb.Append(v)
 
 		// skip the delimiter
-		if data[i] == arrayValueDelim {
+		if i < len(data) && data[i] == arrayValueDelim {
 			i++
 		}
 
@@ -257,6 +283,10 @@ func decodeArray(data []byte) (Array, int, error) {
 		data = data[i:]
 	}
 
+	if len(data) == 0 {
+		return nil, readCount, errors.New("invalid end of input")
+	}
+
 	// skip the array end character
 	readCount++
 
@@ -298,7 +328,7 @@ func decodeDocument(data []byte) (Document, int, error) {
 		fb.Add(string(field), v)
 
 		// skip the delimiter
-		if data[i] == documentValueDelim {
+		if i < len(data) && data[i] == documentValueDelim {
 			i++
 		}
 
@@ -307,6 +337,10 @@ func decodeDocument(data []byte) (Document, int, error) {
 		data = data[i:]
 	}
 
+	if len(data) == 0 {
+		return nil, readCount, errors.New("invalid end of input")
+	}
+
 	// skip the document end character
 	readCount++
 
@@ -39,6 +39,11 @@ func TestValueEncoder(t *testing.T) {
 					))),
 			),
 		))},
+		{"numbers at the end", NewArrayValue(NewValueBuffer(
+			NewIntegerValue(1),
+			NewArrayValue(NewValueBuffer(NewDoubleValue(2.5))),
+			NewDocumentValue(NewFieldBuffer().Add("a", NewIntegerValue(3))),
+		))},
 		{"document", NewDocumentValue(
 			NewFieldBuffer().
 				Add("foo1", NewBoolValue(true)).
@@ -75,3 +80,37 @@ func TestValueEncoder(t *testing.T) {
 		})
 	}
 }
+
+func TestValueEncoderFieldOrder(t *testing.T) {
+	encode := func(d Document) []byte {
+		var buf bytes.Buffer
+		err := NewValueEncoder(&buf).Encode(NewDocumentValue(d))
+		require.NoError(t, err)
+		return buf.Bytes()
+	}
+
+	a := NewFieldBuffer().Add("a", NewIntegerValue(1)).Add("b", NewTextValue("foo"))
+	b := NewFieldBuffer().Add("b", NewTextValue("foo")).Add("a", NewIntegerValue(1))
+	require.Equal(t, encode(a), encode(b))
+
+	got, err := decodeValue(encode(b))
+	require.NoError(t, err)
+	require.Equal(t, NewDocumentValue(a), got)
+}
+
+func TestDecodeValueTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	err := NewValueEncoder(&buf).Encode(NewArrayValue(NewValueBuffer(
+		NewBoolValue(true),
+		NewIntegerValue(1),
+		NewDocumentValue(NewFieldBuffer().Add("a", NewTextValue("foo"))),
+	)))
+	require.NoError(t, err)
+
+	data := buf.Bytes()
+	for i := 0; i < len(data); i++ {
+		require.NotPanics(t, func() {
+			_, _ = decodeValue(data[:i])
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package document_test
 
 import (
+	"fmt"
 	"math"
 	"testing"
 	"time"
@@ -34,6 +35,31 @@ func TestValueString(t *testing.T) {
 	}
 }
 
+func TestValueGoString(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    document.Value
+		expected string
+	}{
+		{"null", document.NewNullValue(), "null"},
+		{"bytes", document.NewBlobValue([]byte("bar")), "blob(0x626172)"},
+		{"string", document.NewTextValue("hi"), `text("hi")`},
+		{"bool", document.NewBoolValue(true), "bool(true)"},
+		{"int", document.NewIntegerValue(10), "int64(10)"},
+		{"double", document.NewDoubleValue(10.1), "float64(10.1)"},
+		{"double with no decimal", document.NewDoubleValue(10), "float64(10)"},
+		{"document", document.NewDocumentValue(document.NewFieldBuffer().Add("a", document.NewIntegerValue(10))), `document({"a": 10})`},
+		{"array", document.NewArrayValue(document.NewValueBuffer(document.NewIntegerValue(10))), "array([10])"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.value.GoString())
+			require.Equal(t, test.expected, fmt.Sprintf("%#v", test.value))
+		})
+	}
+}
+
 func TestNewValue(t *testing.T) {
 	type st struct {
 		A int
@@ -98,6 +124,45 @@ func TestNewValue(t *testing.T) {
 	}
 }
 
+func TestIsTruthy(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        document.Value
+		expected bool
+	}{
+		{"null", document.NewNullValue(), false},
+		{"bool(true)", document.NewBoolValue(true), true},
+		{"bool(false)", document.NewBoolValue(false), false},
+		{"integer(0)", document.NewIntegerValue(0), false},
+		{"integer(-1)", document.NewIntegerValue(-1), true},
+		{"double(0)", document.NewDoubleValue(0), false},
+		{"double(-0)", document.NewDoubleValue(math.Copysign(0, -1)), false},
+		{"double(0.1)", document.NewDoubleValue(0.1), true},
+		{"double(NaN)", document.NewDoubleValue(math.NaN()), true},
+		{"text('')", document.NewTextValue(""), false},
+		{"text('0')", document.NewTextValue("0"), true},
+		{"blob('')", document.NewBlobValue([]byte{}), false},
+		{"blob(nil)", document.NewBlobValue(nil), false},
+		{"blob('\\x00')", document.NewBlobValue([]byte{0}), true},
+		{"array([])", document.NewArrayValue(document.NewValueBuffer()), false},
+		{"array([0])", document.NewArrayValue(document.NewValueBuffer(document.NewIntegerValue(0))), true},
+		{"document({})", document.NewDocumentValue(document.NewFieldBuffer()), false},
+		{"document({a: false})", document.NewDocumentValue(document.NewFieldBuffer().Add("a", document.NewBoolValue(false))), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok, err := document.IsTruthy(test.v)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, ok)
+
+			ok, err = test.v.IsTruthy()
+			require.NoError(t, err)
+			require.Equal(t, test.expected, ok)
+		})
+	}
+}
+
 func TestValueAdd(t *testing.T) {
 	tests := []struct {
 		name           string
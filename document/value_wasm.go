@@ -5,6 +5,8 @@ func NewValue(x interface{}) (Value, error) {
 	switch v := x.(type) {
 	case nil:
 		return NewNullValue(), nil
+	case Value:
+		return v, nil
 	case Document:
 		return NewDocumentValue(v), nil
 	case Array:
@@ -0,0 +1,91 @@
+package genji
+
+import (
+	"context"
+	"errors"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/encryptedengine"
+)
+
+// ErrMissingEncryptionKey is returned by New and Open when the database
+// is encrypted and no key was given with WithEncryptionKey.
+var ErrMissingEncryptionKey = errors.New("database is encrypted: missing encryption key")
+
+// WithEncryptionKey encrypts the values written to the engine using AES-256-GCM.
+// The key must be 32 bytes long and must be given every time the database is opened.
+// If the database was encrypted with another key, New and Open return encryptedengine.ErrDecryption.
+// Only new databases can be encrypted: databases with unencrypted tables are rejected.
+//
+// Only values are encrypted: keys are stored in clear to preserve their order.
+// Keys contain the primary keys of the documents and the indexed values,
+// so these must not hold sensitive data.
+func WithEncryptionKey(key []byte) Option {
+	return func(db *DB) error {
+		return db.DB.WrapEngine(func(ng engine.Engine) (engine.Engine, error) {
+			ok, err := encryptedengine.IsEncrypted(db.ctx, ng)
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				// the engine is not wrapped yet, tables are read unencrypted.
+				d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM __genji_tables")
+				if err != nil {
+					return nil, err
+				}
+
+				n, err := d.GetByField("n")
+				if err != nil {
+					return nil, err
+				}
+
+				if n.V.(int64) > 0 {
+					return nil, errors.New("cannot encrypt a database that contains unencrypted tables")
+				}
+			}
+
+			enc, err := encryptedengine.NewEngine(db.ctx, ng, key)
+			if err != nil {
+				return nil, err
+			}
+
+			db.encryption = enc
+			return enc, nil
+		})
+	}
+}
+
+// ReencryptWith encrypts every value of the database with newKey, which must be used
+// to open the database from now on. The database must have been opened with WithEncryptionKey.
+// It waits for the running transactions to end and blocks new ones until it returns.
+func (db *DB) ReencryptWith(newKey []byte) error {
+	if db.encryption == nil {
+		return errors.New("database is not encrypted")
+	}
+
+	return db.encryption.Rekey(db.ctx, newKey)
+}
+
+// checkEncryption makes sure that encrypted engines are only opened with a key.
+// Engines that were wrapped by the caller can be reencrypted using ReencryptWith.
+func checkEncryption(ctx context.Context, db *DB, ng engine.Engine) error {
+	if db.encryption != nil {
+		return nil
+	}
+
+	if enc, ok := ng.(*encryptedengine.Engine); ok {
+		db.encryption = enc
+		return nil
+	}
+
+	ok, err := encryptedengine.IsEncrypted(ctx, ng)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return ErrMissingEncryptionKey
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package genji_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine/encryptedengine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryption(t *testing.T) {
+	key := bytes.Repeat([]byte("k"), 32)
+	newKey := bytes.Repeat([]byte("n"), 32)
+
+	dir, err := ioutil.TempDir("", "genji")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.db")
+
+	readName := func(t *testing.T, db *genji.DB) string {
+		d, err := db.QueryDocument("SELECT name FROM test")
+		require.NoError(t, err)
+
+		var name string
+		err = document.Scan(d, &name)
+		require.NoError(t, err)
+		return name
+	}
+
+	t.Run("Invalid key", func(t *testing.T) {
+		_, err := genji.Open(":memory:", genji.WithEncryptionKey([]byte("foo")))
+		require.Equal(t, encryptedengine.ErrInvalidKey, err)
+	})
+
+	t.Run("Correct key", func(t *testing.T) {
+		db, err := genji.Open(path, genji.WithEncryptionKey(key))
+		require.NoError(t, err)
+		err = db.Exec(`
+			CREATE TABLE test(tag TEXT);
+			CREATE INDEX idx_test_tag ON test(tag);
+			INSERT INTO test (name, tag) VALUES ('supersecret', 'indexedtag')
+		`)
+		require.NoError(t, err)
+		err = db.Close()
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(data, []byte("supersecret")))
+		// keys are not encrypted: indexed values can be read from the file.
+		require.True(t, bytes.Contains(data, []byte("indexedtag")))
+
+		db, err = genji.Open(path, genji.WithEncryptionKey(key))
+		require.NoError(t, err)
+		defer db.Close()
+		require.Equal(t, "supersecret", readName(t, db))
+	})
+
+	t.Run("Wrong key", func(t *testing.T) {
+		_, err := genji.Open(path, genji.WithEncryptionKey(newKey))
+		require.True(t, errors.Is(err, encryptedengine.ErrDecryption))
+	})
+
+	t.Run("Missing key", func(t *testing.T) {
+		_, err := genji.Open(path)
+		require.Equal(t, genji.ErrMissingEncryptionKey, err)
+	})
+
+	t.Run("Reencrypt", func(t *testing.T) {
+		db, err := genji.Open(path, genji.WithEncryptionKey(key))
+		require.NoError(t, err)
+		err = db.ReencryptWith(newKey)
+		require.NoError(t, err)
+		require.Equal(t, "supersecret", readName(t, db))
+		err = db.Close()
+		require.NoError(t, err)
+
+		_, err = genji.Open(path, genji.WithEncryptionKey(key))
+		require.True(t, errors.Is(err, encryptedengine.ErrDecryption))
+
+		db, err = genji.Open(path, genji.WithEncryptionKey(newKey))
+		require.NoError(t, err)
+		defer db.Close()
+		require.Equal(t, "supersecret", readName(t, db))
+
+		d, err := db.QueryDocument("SELECT name FROM test WHERE name = 'supersecret'")
+		require.NoError(t, err)
+		require.NotNil(t, d)
+	})
+
+	t.Run("Unencrypted database", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.ReencryptWith(newKey)
+		require.Error(t, err)
+
+		path := filepath.Join(dir, "plain.db")
+		db, err = genji.Open(path)
+		require.NoError(t, err)
+		err = db.Exec("CREATE TABLE test")
+		require.NoError(t, err)
+		err = db.Close()
+		require.NoError(t, err)
+
+		_, err = genji.Open(path, genji.WithEncryptionKey(key))
+		require.Error(t, err)
+	})
+}
@@ -95,7 +95,12 @@ func (t *Transaction) Commit() error {
 	}
 
 	t.discarded = true
-	return t.tx.Commit()
+	err := t.tx.Commit()
+	if err == badger.ErrConflict {
+		return engine.ErrSerializationFailure
+	}
+
+	return err
 }
 
 func buildStoreKey(name []byte) []byte {
@@ -0,0 +1,303 @@
+package boltengine
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// pauseTimeout is the maximum duration Compact waits for the open transactions
+// to be closed before swapping the files.
+const pauseTimeout = time.Second
+
+// ErrCompactionAborted is returned by Compact when the database was modified
+// while it was being copied, or when transactions remained open for too long.
+// Nothing was changed and the compaction can be retried later.
+var ErrCompactionAborted = errors.New("compaction aborted")
+
+// DeadRatio returns the ratio of the size of the file occupied by free pages,
+// left by deleted or overwritten data.
+func (e *Engine) DeadRatio() (float64, error) {
+	e.mu.Lock()
+	db := e.DB
+	e.mu.Unlock()
+
+	fi, err := os.Stat(e.path)
+	if err != nil {
+		return 0, err
+	}
+	if fi.Size() == 0 {
+		return 0, nil
+	}
+
+	return float64(db.Stats().FreeAlloc) / float64(fi.Size()), nil
+}
+
+// Compact rewrites the database into a new file without free pages, and replaces
+// the current file with it. It returns the number of bytes reclaimed.
+// The database is copied from a read transaction: other transactions are only paused
+// while the files are swapped. If the database is modified during the copy,
+// or if transactions are still open after a second, it returns ErrCompactionAborted.
+func (e *Engine) Compact() (int64, error) {
+	e.compactMu.Lock()
+	defer e.compactMu.Unlock()
+
+	e.mu.Lock()
+	writes := e.writes
+	e.mu.Unlock()
+
+	tmp := e.path + ".compact"
+	err := e.copyTo(tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+
+	if !e.pause(pauseTimeout) {
+		os.Remove(tmp)
+		return 0, ErrCompactionAborted
+	}
+	defer e.resume()
+
+	e.mu.Lock()
+	modified := e.writes != writes
+	e.mu.Unlock()
+	if modified {
+		os.Remove(tmp)
+		return 0, ErrCompactionAborted
+	}
+
+	return e.swap(tmp)
+}
+
+// compactTxMaxSize is the size of the keys and values written by a transaction of the
+// compaction above which it is committed, to bound the memory used to copy large databases.
+// It can be changed by tests.
+var compactTxMaxSize int64 = 64 << 20
+
+// copyTo copies every bucket of the database to a new database at the given path.
+// The copy is made from a single read transaction, and written in batches
+// of at most compactTxMaxSize bytes.
+func (e *Engine) copyTo(path string) error {
+	dst, err := bolt.Open(path, e.mode, e.opts)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	return e.DB.View(func(src *bolt.Tx) error {
+		w := batchWriter{db: dst}
+		w.tx, err = dst.Begin(true)
+		if err != nil {
+			return err
+		}
+
+		err := src.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return w.copyBucket([][]byte{name}, b)
+		})
+		if err != nil {
+			w.tx.Rollback()
+			return err
+		}
+
+		return w.tx.Commit()
+	})
+}
+
+// batchWriter writes to the compacted database, committing its transaction
+// once it has written compactTxMaxSize bytes.
+type batchWriter struct {
+	db   *bolt.DB
+	tx   *bolt.Tx
+	size int64
+}
+
+// reserve commits the transaction and begins a new one if writing n more bytes
+// would exceed compactTxMaxSize.
+func (w *batchWriter) reserve(n int64) error {
+	if w.size == 0 || w.size+n <= compactTxMaxSize {
+		w.size += n
+		return nil
+	}
+
+	err := w.tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	w.tx, err = w.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	w.size = n
+
+	return nil
+}
+
+// bucket returns the bucket at the given path in the current transaction.
+func (w *batchWriter) bucket(path [][]byte) *bolt.Bucket {
+	b := w.tx.Bucket(path[0])
+	for _, name := range path[1:] {
+		b = b.Bucket(name)
+	}
+
+	return b
+}
+
+// copyBucket creates the bucket at the given path and copies src into it.
+func (w *batchWriter) copyBucket(path [][]byte, src *bolt.Bucket) error {
+	name := path[len(path)-1]
+	err := w.reserve(int64(len(name)))
+	if err != nil {
+		return err
+	}
+
+	var dst *bolt.Bucket
+	if len(path) == 1 {
+		dst, err = w.tx.CreateBucket(name)
+	} else {
+		dst, err = w.bucket(path[:len(path)-1]).CreateBucket(name)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = dst.SetSequence(src.Sequence())
+	if err != nil {
+		return err
+	}
+
+	tx := w.tx
+	return src.ForEach(func(k, v []byte) error {
+		// nested bucket
+		if v == nil {
+			return w.copyBucket(append(path[:len(path):len(path)], k), src.Bucket(k))
+		}
+
+		err := w.reserve(int64(len(k) + len(v)))
+		if err != nil {
+			return err
+		}
+		// buckets must be looked up again once their transaction is committed
+		if w.tx != tx {
+			tx = w.tx
+			dst = w.bucket(path)
+		}
+
+		return dst.Put(k, v)
+	})
+}
+
+// openDB opens the bolt database files. It can be replaced by tests.
+var openDB = bolt.Open
+
+// swap replaces the database file with the compacted one. Transactions must be paused.
+// The original file is kept until the compacted one is opened: if anything fails,
+// it is restored and reopened.
+func (e *Engine) swap(tmp string) (int64, error) {
+	before, err := os.Stat(e.path)
+	if err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+
+	err = e.DB.Close()
+	if err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+
+	old := e.path + ".old"
+	err = os.Rename(e.path, old)
+	if err != nil {
+		os.Remove(tmp)
+		return 0, e.reopen(err)
+	}
+
+	err = os.Rename(tmp, e.path)
+	if err != nil {
+		os.Remove(tmp)
+		return 0, e.restore(old, err)
+	}
+
+	db, err := openDB(e.path, e.mode, e.opts)
+	if err != nil {
+		return 0, e.restore(old, err)
+	}
+	e.mu.Lock()
+	e.DB = db
+	e.mu.Unlock()
+
+	os.Remove(old)
+
+	after, err := os.Stat(e.path)
+	if err != nil {
+		return 0, err
+	}
+
+	return before.Size() - after.Size(), nil
+}
+
+// restore moves the original file back in place of the compacted one and reopens it.
+// It returns the error that caused the restoration, or the one that prevented it.
+func (e *Engine) restore(old string, cause error) error {
+	err := os.Rename(old, e.path)
+	if err != nil {
+		return err
+	}
+
+	return e.reopen(cause)
+}
+
+// reopen opens the database file again after a failed swap.
+// It returns cause, or the error that prevented the database from being reopened.
+func (e *Engine) reopen(cause error) error {
+	db, err := openDB(e.path, e.mode, e.opts)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.DB = db
+	e.mu.Unlock()
+
+	return cause
+}
+
+// pause prevents new transactions from starting and waits for the open ones
+// to be closed. If they are still open after timeout, transactions are resumed
+// and it returns false.
+func (e *Engine) pause(timeout time.Duration) bool {
+	e.mu.Lock()
+	e.paused = make(chan struct{})
+	var drained chan struct{}
+	if e.active > 0 {
+		e.drained = make(chan struct{})
+		drained = e.drained
+	}
+	e.mu.Unlock()
+
+	if drained == nil {
+		return true
+	}
+
+	select {
+	case <-drained:
+		return true
+	case <-time.After(timeout):
+		e.resume()
+		return false
+	}
+}
+
+// resume lets the paused transactions start.
+func (e *Engine) resume() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	close(e.paused)
+	e.paused = nil
+	e.drained = nil
+}
@@ -0,0 +1,128 @@
+package boltengine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestCompactOpenFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genji")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.db")
+	ng, err := NewEngine(path, 0o600, nil)
+	require.NoError(t, err)
+	defer ng.Close()
+
+	ctx := context.Background()
+	tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	require.NoError(t, tx.CreateStore([]byte("test")))
+	st, err := tx.GetStore([]byte("test"))
+	require.NoError(t, err)
+	require.NoError(t, st.Put([]byte("a"), []byte("b")))
+	require.NoError(t, tx.Commit())
+
+	// fail to open the compacted file only.
+	errOpen := errors.New("open failed")
+	defer func() { openDB = bolt.Open }()
+	openDB = func(path string, mode os.FileMode, opts *bolt.Options) (*bolt.DB, error) {
+		openDB = bolt.Open
+		return nil, errOpen
+	}
+
+	_, err = ng.Compact()
+	require.Equal(t, errOpen, err)
+
+	// the original database is restored and usable.
+	_, err = os.Stat(path + ".old")
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(path + ".compact")
+	require.True(t, os.IsNotExist(err))
+
+	tx, err = ng.Begin(ctx, engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer tx.Rollback()
+	st, err = tx.GetStore([]byte("test"))
+	require.NoError(t, err)
+	v, err := st.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("b"), v)
+	require.NoError(t, st.Put([]byte("c"), []byte("d")))
+	require.NoError(t, tx.Commit())
+}
+
+func TestCompactBatches(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genji")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.db")
+	ng, err := NewEngine(path, 0o600, nil)
+	require.NoError(t, err)
+	defer ng.Close()
+
+	value := bytes.Repeat([]byte("v"), 100)
+	err = ng.DB.Update(func(tx *bolt.Tx) error {
+		for _, name := range []string{"a", "b"} {
+			b, err := tx.CreateBucket([]byte(name))
+			if err != nil {
+				return err
+			}
+			nested, err := b.CreateBucket([]byte("nested"))
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < 100; i++ {
+				k := []byte(fmt.Sprintf("%03d", i))
+				if err := b.Put(k, value); err != nil {
+					return err
+				}
+				if err := nested.Put(k, value); err != nil {
+					return err
+				}
+			}
+			if err := b.SetSequence(42); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	defer func(n int64) { compactTxMaxSize = n }(compactTxMaxSize)
+	compactTxMaxSize = 1000
+
+	_, err = ng.Compact()
+	require.NoError(t, err)
+
+	err = ng.DB.View(func(tx *bolt.Tx) error {
+		// every batch was committed in its own transaction
+		require.Greater(t, tx.ID(), 40)
+
+		for _, name := range []string{"a", "b"} {
+			b := tx.Bucket([]byte(name))
+			require.NotNil(t, b)
+			require.EqualValues(t, 42, b.Sequence())
+			for _, bucket := range []*bolt.Bucket{b, b.Bucket([]byte("nested"))} {
+				for i := 0; i < 100; i++ {
+					require.Equal(t, value, bucket.Get([]byte(fmt.Sprintf("%03d", i))))
+				}
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
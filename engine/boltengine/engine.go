@@ -4,6 +4,7 @@ package boltengine
 import (
 	"context"
 	"os"
+	"sync"
 
 	"github.com/genjidb/genji/engine"
 	bolt "go.etcd.io/bbolt"
@@ -15,7 +16,25 @@ const (
 
 // Engine represents a BoltDB engine. Each store is stored in a dedicated bucket.
 type Engine struct {
+	// DB is replaced by Compact: it must not be used while a compaction is running.
 	DB *bolt.DB
+
+	path string
+	mode os.FileMode
+	opts *bolt.Options
+
+	// compactMu prevents concurrent compactions.
+	compactMu sync.Mutex
+
+	mu sync.Mutex
+	// number of open transactions.
+	active int
+	// number of committed read/write transactions.
+	writes uint64
+	// paused is non-nil while new transactions are paused, and closed when they can resume.
+	paused chan struct{}
+	// drained is closed when the last open transaction is closed while transactions are paused.
+	drained chan struct{}
 }
 
 // NewEngine creates a BoltDB engine. It takes the same argument as Bolt's Open function.
@@ -26,7 +45,10 @@ func NewEngine(path string, mode os.FileMode, opts *bolt.Options) (*Engine, erro
 	}
 
 	return &Engine{
-		DB: db,
+		DB:   db,
+		path: path,
+		mode: mode,
+		opts: opts,
 	}, nil
 }
 
@@ -38,8 +60,14 @@ func (e *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Trans
 	default:
 	}
 
-	tx, err := e.DB.Begin(opts.Writable)
+	db, err := e.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin(opts.Writable)
 	if err != nil {
+		e.release(false)
 		return nil, err
 	}
 
@@ -47,9 +75,47 @@ func (e *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Trans
 		ctx:      ctx,
 		tx:       tx,
 		writable: opts.Writable,
+		e:        e,
 	}, nil
 }
 
+// acquire waits for transactions to be resumed and registers a new transaction.
+func (e *Engine) acquire(ctx context.Context) (*bolt.DB, error) {
+	for {
+		e.mu.Lock()
+		paused := e.paused
+		if paused == nil {
+			e.active++
+			db := e.DB
+			e.mu.Unlock()
+			return db, nil
+		}
+		e.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-paused:
+		}
+	}
+}
+
+// release unregisters a transaction.
+func (e *Engine) release(committed bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if committed {
+		e.writes++
+	}
+
+	e.active--
+	if e.active == 0 && e.drained != nil {
+		close(e.drained)
+		e.drained = nil
+	}
+}
+
 // Close the engine and underlying Bolt database.
 func (e *Engine) Close() error {
 	return e.DB.Close()
@@ -60,10 +126,20 @@ type Transaction struct {
 	ctx      context.Context
 	tx       *bolt.Tx
 	writable bool
+	e        *Engine
+	once     sync.Once
+}
+
+func (t *Transaction) release(committed bool) {
+	t.once.Do(func() {
+		t.e.release(committed)
+	})
 }
 
 // Rollback the transaction. Can be used safely after commit.
 func (t *Transaction) Rollback() error {
+	defer t.release(false)
+
 	err := t.tx.Rollback()
 	if err != nil && err != bolt.ErrTxClosed {
 		return err
@@ -87,7 +163,9 @@ func (t *Transaction) Commit() error {
 	default:
 	}
 
-	return t.tx.Commit()
+	err := t.tx.Commit()
+	t.release(err == nil && t.writable)
+	return err
 }
 
 // GetStore returns a store by name. The store uses a Bolt bucket.
@@ -1,6 +1,9 @@
 package boltengine_test
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -41,3 +44,127 @@ func tempDir(t require.TestingT) (string, func()) {
 		os.RemoveAll(dir)
 	}
 }
+
+func TestCompact(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	path := filepath.Join(dir, "test.db")
+	ng, err := boltengine.NewEngine(path, 0o600, nil)
+	require.NoError(t, err)
+	defer ng.Close()
+
+	ctx := context.Background()
+	value := bytes.Repeat([]byte("v"), 512)
+
+	update := func(fn func(st engine.Store) error) {
+		tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		st, err := tx.GetStore([]byte("test"))
+		if err == engine.ErrStoreNotFound {
+			err = tx.CreateStore([]byte("test"))
+			require.NoError(t, err)
+			st, err = tx.GetStore([]byte("test"))
+		}
+		require.NoError(t, err)
+
+		err = fn(st)
+		require.NoError(t, err)
+		err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	update(func(st engine.Store) error {
+		for i := 0; i < 1000; i++ {
+			_, err := st.NextSequence()
+			if err != nil {
+				return err
+			}
+			err = st.Put([]byte(fmt.Sprintf("%04d", i)), value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	update(func(st engine.Store) error {
+		for i := 0; i < 1000; i++ {
+			if i%5 == 0 {
+				continue
+			}
+			err := st.Delete([]byte(fmt.Sprintf("%04d", i)))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	ratio, err := ng.DeadRatio()
+	require.NoError(t, err)
+	require.Greater(t, ratio, 0.5)
+
+	before, err := os.Stat(path)
+	require.NoError(t, err)
+
+	// read concurrently during the compaction
+	stop := make(chan struct{})
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(readErr)
+
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			tx, err := ng.Begin(ctx, engine.TxOptions{})
+			if err != nil {
+				readErr <- err
+				return
+			}
+			st, err := tx.GetStore([]byte("test"))
+			if err == nil {
+				_, err = st.Get([]byte("0005"))
+			}
+			tx.Rollback()
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	n, err := ng.Compact()
+	close(stop)
+	require.NoError(t, err)
+	require.NoError(t, <-readErr)
+
+	after, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, before.Size()-after.Size(), n)
+	require.Greater(t, n, int64(0))
+
+	ratio, err = ng.DeadRatio()
+	require.NoError(t, err)
+	require.Less(t, ratio, 0.5)
+
+	// data and sequences are preserved
+	update(func(st engine.Store) error {
+		v, err := st.Get([]byte("0995"))
+		require.NoError(t, err)
+		require.Equal(t, value, v)
+		_, err = st.Get([]byte("0996"))
+		require.Equal(t, engine.ErrKeyNotFound, err)
+
+		seq, err := st.NextSequence()
+		require.NoError(t, err)
+		require.Equal(t, uint64(1001), seq)
+		return nil
+	})
+}
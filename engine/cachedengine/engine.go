@@ -0,0 +1,189 @@
+// Package cachedengine implements an engine that keeps the values most recently read
+// from another engine in an LRU cache, shared by all the transactions.
+//
+// Only the values read with Store.Get are cached, iterators always read the underlying engine.
+// Writes go directly to the underlying engine, and the values they modify are removed
+// from the cache once their transaction is committed. Transactions only use the cache
+// if no transaction was committed since they started, which guarantees they never read
+// values that are not part of their snapshot.
+package cachedengine
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// Stats holds the statistics of the cache.
+type Stats struct {
+	// Hits is the number of values read from the cache.
+	Hits uint64
+	// Misses is the number of values read from the underlying engine
+	// because they were not cached.
+	Misses uint64
+	// Evictions is the number of values removed from the cache to make room for new ones.
+	Evictions uint64
+}
+
+type cacheKey struct {
+	store string
+	key   string
+}
+
+type entry struct {
+	k cacheKey
+	v []byte
+}
+
+// Engine wraps an engine and caches the values read from it.
+type Engine struct {
+	ng   engine.Engine
+	size int
+
+	mu    sync.Mutex
+	lru   *list.List
+	items map[cacheKey]*list.Element
+	stats Stats
+
+	// version is incremented when a commit starts and when it ends.
+	// committing is the number of commits in progress.
+	version    uint64
+	committing int
+}
+
+// NewEngine wraps ng and caches up to size values.
+func NewEngine(ng engine.Engine, size int) (*Engine, error) {
+	if size <= 0 {
+		return nil, errors.New("cache size must be positive")
+	}
+
+	return &Engine{
+		ng:    ng,
+		size:  size,
+		lru:   list.New(),
+		items: make(map[cacheKey]*list.Element),
+	}, nil
+}
+
+// Begin starts a transaction on the underlying engine.
+func (e *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	e.mu.Lock()
+	version := e.version
+	e.mu.Unlock()
+
+	tx, err := e.ng.Begin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction{
+		Transaction: tx,
+		e:           e,
+		version:     version,
+	}, nil
+}
+
+// Close the underlying engine.
+func (e *Engine) Close() error {
+	return e.ng.Close()
+}
+
+// Stats returns the statistics of the cache.
+func (e *Engine) Stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.stats
+}
+
+// usable returns whether a transaction started at the given version can use the cache.
+// e.mu must be held.
+func (e *Engine) usable(version uint64) bool {
+	return e.committing == 0 && e.version == version
+}
+
+// get returns the cached value of k, if any.
+func (e *Engine) get(version uint64, k cacheKey) ([]byte, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.usable(version) {
+		return nil, false
+	}
+
+	elem, ok := e.items[k]
+	if !ok {
+		e.stats.Misses++
+		return nil, false
+	}
+
+	e.stats.Hits++
+	e.lru.MoveToFront(elem)
+	return append([]byte(nil), elem.Value.(*entry).v...), true
+}
+
+// add caches a copy of the value of k, evicting the least recently used values if necessary.
+func (e *Engine) add(version uint64, k cacheKey, v []byte) {
+	v = append([]byte(nil), v...)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.usable(version) {
+		return
+	}
+
+	if elem, ok := e.items[k]; ok {
+		elem.Value.(*entry).v = v
+		e.lru.MoveToFront(elem)
+		return
+	}
+
+	e.items[k] = e.lru.PushFront(&entry{k: k, v: v})
+
+	for e.lru.Len() > e.size {
+		elem := e.lru.Back()
+		e.lru.Remove(elem)
+		delete(e.items, elem.Value.(*entry).k)
+		e.stats.Evictions++
+	}
+}
+
+func (e *Engine) startCommit() {
+	e.mu.Lock()
+	e.committing++
+	e.version++
+	e.mu.Unlock()
+}
+
+// endCommit removes the values modified by a transaction from the cache.
+func (e *Engine) endCommit(written map[cacheKey]struct{}, cleared map[string]struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for k := range written {
+		e.remove(k)
+	}
+
+	if len(cleared) > 0 {
+		for k := range e.items {
+			if _, ok := cleared[k.store]; ok {
+				e.remove(k)
+			}
+		}
+	}
+
+	e.committing--
+	e.version++
+}
+
+// remove deletes k from the cache. e.mu must be held.
+func (e *Engine) remove(k cacheKey) {
+	if elem, ok := e.items[k]; ok {
+		e.lru.Remove(elem)
+		delete(e.items, k)
+	}
+}
@@ -0,0 +1,196 @@
+package cachedengine_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/boltengine"
+	"github.com/genjidb/genji/engine/cachedengine"
+	"github.com/genjidb/genji/engine/enginetest"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func builder() (engine.Engine, func()) {
+	ng, err := cachedengine.NewEngine(memoryengine.NewEngine(), 10)
+	if err != nil {
+		panic(err)
+	}
+	return ng, func() { ng.Close() }
+}
+
+func TestCachedEngine(t *testing.T) {
+	enginetest.TestSuite(t, builder)
+}
+
+// update runs fn in a read/write transaction on the "test" store, creating it if necessary.
+func update(t *testing.T, ng engine.Engine, fn func(st engine.Store)) {
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	st, err := tx.GetStore([]byte("test"))
+	if err == engine.ErrStoreNotFound {
+		err = tx.CreateStore([]byte("test"))
+		require.NoError(t, err)
+		st, err = tx.GetStore([]byte("test"))
+	}
+	require.NoError(t, err)
+
+	fn(st)
+	err = tx.Commit()
+	require.NoError(t, err)
+}
+
+func get(t *testing.T, ng engine.Engine, k string) ([]byte, error) {
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	st, err := tx.GetStore([]byte("test"))
+	require.NoError(t, err)
+
+	return st.Get([]byte(k))
+}
+
+func put(t *testing.T, ng engine.Engine, k, v string) {
+	update(t, ng, func(st engine.Store) {
+		err := st.Put([]byte(k), []byte(v))
+		require.NoError(t, err)
+	})
+}
+
+func TestEngine(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Invalid size", func(t *testing.T) {
+		_, err := cachedengine.NewEngine(memoryengine.NewEngine(), 0)
+		require.Error(t, err)
+	})
+
+	t.Run("Hits and misses", func(t *testing.T) {
+		ng, err := cachedengine.NewEngine(memoryengine.NewEngine(), 10)
+		require.NoError(t, err)
+		put(t, ng, "a", "A")
+
+		for i := 0; i < 3; i++ {
+			v, err := get(t, ng, "a")
+			require.NoError(t, err)
+			require.Equal(t, []byte("A"), v)
+		}
+
+		_, err = get(t, ng, "b")
+		require.Equal(t, engine.ErrKeyNotFound, err)
+
+		require.Equal(t, cachedengine.Stats{Hits: 2, Misses: 2}, ng.Stats())
+	})
+
+	t.Run("Eviction", func(t *testing.T) {
+		ng, err := cachedengine.NewEngine(memoryengine.NewEngine(), 2)
+		require.NoError(t, err)
+		put(t, ng, "a", "A")
+		put(t, ng, "b", "B")
+		put(t, ng, "c", "C")
+
+		for _, k := range []string{"a", "b", "a", "c", "b"} {
+			_, err := get(t, ng, k)
+			require.NoError(t, err)
+		}
+
+		// c evicts b, the least recently used, which then evicts a.
+		require.Equal(t, cachedengine.Stats{Hits: 1, Misses: 4, Evictions: 2}, ng.Stats())
+	})
+
+	t.Run("Invalidation", func(t *testing.T) {
+		ng, err := cachedengine.NewEngine(memoryengine.NewEngine(), 10)
+		require.NoError(t, err)
+		put(t, ng, "a", "A")
+		put(t, ng, "b", "B")
+
+		_, err = get(t, ng, "a")
+		require.NoError(t, err)
+		_, err = get(t, ng, "b")
+		require.NoError(t, err)
+
+		put(t, ng, "a", "AA")
+		v, err := get(t, ng, "a")
+		require.NoError(t, err)
+		require.Equal(t, []byte("AA"), v)
+
+		update(t, ng, func(st engine.Store) {
+			err := st.Delete([]byte("a"))
+			require.NoError(t, err)
+		})
+		_, err = get(t, ng, "a")
+		require.Equal(t, engine.ErrKeyNotFound, err)
+
+		update(t, ng, func(st engine.Store) {
+			err := st.Truncate()
+			require.NoError(t, err)
+		})
+		_, err = get(t, ng, "b")
+		require.Equal(t, engine.ErrKeyNotFound, err)
+	})
+
+	t.Run("Uncommitted writes", func(t *testing.T) {
+		ng, err := cachedengine.NewEngine(memoryengine.NewEngine(), 10)
+		require.NoError(t, err)
+		put(t, ng, "a", "A")
+
+		tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		err = st.Put([]byte("a"), []byte("AA"))
+		require.NoError(t, err)
+
+		// the transaction reads its own writes
+		v, err := st.Get([]byte("a"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("AA"), v)
+
+		err = tx.Rollback()
+		require.NoError(t, err)
+
+		v, err = get(t, ng, "a")
+		require.NoError(t, err)
+		require.Equal(t, []byte("A"), v)
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		// the memory engine doesn't allow writes while a transaction is running,
+		// and bolt must not remap its file while the old transaction is open.
+		dir, err := ioutil.TempDir("", "genji")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		bolt, err := boltengine.NewEngine(filepath.Join(dir, "test.db"), 0600, &bolt.Options{InitialMmapSize: 1 << 20})
+		require.NoError(t, err)
+		ng, err := cachedengine.NewEngine(bolt, 10)
+		require.NoError(t, err)
+		defer ng.Close()
+		put(t, ng, "a", "A")
+
+		tx, err := ng.Begin(ctx, engine.TxOptions{})
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		put(t, ng, "a", "AA")
+
+		// the old transaction must neither read nor cache the new value
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		v, err := st.Get([]byte("a"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("A"), v)
+
+		v, err = get(t, ng, "a")
+		require.NoError(t, err)
+		require.Equal(t, []byte("AA"), v)
+	})
+}
@@ -0,0 +1,122 @@
+package cachedengine
+
+import (
+	"github.com/genjidb/genji/engine"
+)
+
+// transaction wraps a transaction of the underlying engine.
+// It keeps track of the values it modifies, which are removed from the cache on commit.
+type transaction struct {
+	engine.Transaction
+
+	e       *Engine
+	version uint64
+
+	written map[cacheKey]struct{}
+	// stores dropped or truncated during the transaction.
+	cleared map[string]struct{}
+}
+
+func (t *transaction) Commit() error {
+	if len(t.written) == 0 && len(t.cleared) == 0 {
+		return t.Transaction.Commit()
+	}
+
+	t.e.startCommit()
+	defer t.e.endCommit(t.written, t.cleared)
+
+	return t.Transaction.Commit()
+}
+
+// GetStore returns a store that reads its values from the cache when possible.
+func (t *transaction) GetStore(name []byte) (engine.Store, error) {
+	st, err := t.Transaction.GetStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &store{Store: st, tx: t, name: string(name)}, nil
+}
+
+func (t *transaction) DropStore(name []byte) error {
+	err := t.Transaction.DropStore(name)
+	if err != nil {
+		return err
+	}
+
+	t.clear(string(name))
+	return nil
+}
+
+func (t *transaction) write(k cacheKey) {
+	if t.written == nil {
+		t.written = make(map[cacheKey]struct{})
+	}
+
+	t.written[k] = struct{}{}
+}
+
+func (t *transaction) clear(name string) {
+	if t.cleared == nil {
+		t.cleared = make(map[string]struct{})
+	}
+
+	t.cleared[name] = struct{}{}
+}
+
+// cacheable returns whether the value of k, as seen by the transaction,
+// is the one that was last committed.
+func (t *transaction) cacheable(k cacheKey) bool {
+	if _, ok := t.cleared[k.store]; ok {
+		return false
+	}
+
+	_, ok := t.written[k]
+	return !ok
+}
+
+// store caches the values read from the underlying store.
+// Writes are sent directly to the underlying store.
+type store struct {
+	engine.Store
+
+	tx   *transaction
+	name string
+}
+
+func (s *store) Get(k []byte) ([]byte, error) {
+	ck := cacheKey{store: s.name, key: string(k)}
+	if !s.tx.cacheable(ck) {
+		return s.Store.Get(k)
+	}
+
+	if v, ok := s.tx.e.get(s.tx.version, ck); ok {
+		return v, nil
+	}
+
+	v, err := s.Store.Get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	s.tx.e.add(s.tx.version, ck, v)
+	return v, nil
+}
+
+func (s *store) Put(k, v []byte) error {
+	s.tx.write(cacheKey{store: s.name, key: string(k)})
+
+	return s.Store.Put(k, v)
+}
+
+func (s *store) Delete(k []byte) error {
+	s.tx.write(cacheKey{store: s.name, key: string(k)})
+
+	return s.Store.Delete(k)
+}
+
+func (s *store) Truncate() error {
+	s.tx.clear(s.name)
+
+	return s.Store.Truncate()
+}
@@ -0,0 +1,79 @@
+package engine
+
+// A ValueCodec transforms the values of a store just before they are written to it
+// and just after they are read from it, for example to encrypt or compress them.
+// The key of each value is passed along and can be used, for example, as additional data.
+type ValueCodec interface {
+	// Encode returns the value to write under key k in place of v.
+	Encode(k, v []byte) ([]byte, error)
+	// Decode appends the original value of v, read under key k, to dst and returns it.
+	Decode(dst, k, v []byte) ([]byte, error)
+}
+
+// NewCodecStore returns a store that applies c to the values of st.
+// Keys, deletions and sequences are left untouched.
+func NewCodecStore(st Store, c ValueCodec) Store {
+	return &codecStore{Store: st, codec: c}
+}
+
+type codecStore struct {
+	Store
+
+	codec ValueCodec
+}
+
+func (s *codecStore) Get(k []byte) ([]byte, error) {
+	v, err := s.Store.Get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.codec.Decode(nil, k, v)
+}
+
+func (s *codecStore) Put(k, v []byte) error {
+	v, err := s.codec.Encode(k, v)
+	if err != nil {
+		return err
+	}
+
+	return s.Store.Put(k, v)
+}
+
+func (s *codecStore) Iterator(opts IteratorOptions) Iterator {
+	return &codecIterator{
+		Iterator: s.Store.Iterator(opts),
+		codec:    s.codec,
+	}
+}
+
+type codecIterator struct {
+	Iterator
+
+	codec ValueCodec
+	item  codecItem
+}
+
+func (it *codecIterator) Item() Item {
+	it.item.Item = it.Iterator.Item()
+	it.item.codec = it.codec
+	return &it.item
+}
+
+// codecItem decodes the value of the underlying item.
+type codecItem struct {
+	Item
+
+	codec ValueCodec
+	buf   []byte
+}
+
+func (i *codecItem) ValueCopy(buf []byte) ([]byte, error) {
+	var err error
+	i.buf, err = i.Item.ValueCopy(i.buf[:0])
+	if err != nil {
+		return nil, err
+	}
+
+	return i.codec.Decode(buf[:0], i.Item.Key(), i.buf)
+}
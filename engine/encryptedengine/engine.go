@@ -0,0 +1,366 @@
+// Package encryptedengine implements an engine that encrypts the values of another engine
+// using AES-256-GCM. A random nonce is generated for every write and prepended to the ciphertext.
+// Keys are stored unencrypted to preserve their order, and are used as additional data
+// so that a value cannot be moved to another key without being detected.
+// Since keys are readable by anyone having access to the underlying engine, data stored in keys,
+// such as primary keys and indexed values, is not protected.
+//
+// The engine stores, in the metaStoreName store of the underlying engine, a value used to verify
+// the encryption key and the list of the stores that may contain encrypted values.
+package encryptedengine
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// KeySize is the size of the encryption keys, in bytes.
+const KeySize = 32
+
+const metaStoreName = "__genji_encryption"
+
+var (
+	// checkKey holds a known value encrypted with the key of the engine.
+	checkKey   = []byte("check")
+	checkValue = []byte("genji")
+
+	// each store that may contain encrypted values is listed under a key
+	// starting with storePrefix.
+	storePrefix = []byte("store:")
+)
+
+var (
+	// ErrInvalidKey is returned when the encryption key is not KeySize bytes long.
+	ErrInvalidKey = errors.New("encryption key must be 32 bytes long")
+
+	// ErrDecryption is returned when a value cannot be decrypted, either because
+	// the encryption key is wrong or because the data is corrupted.
+	ErrDecryption = errors.New("cannot decrypt value: wrong encryption key or corrupted data")
+)
+
+// Engine wraps an engine and encrypts every value written to it.
+// Every transaction is bound to the key that was used when it started.
+type Engine struct {
+	ng engine.Engine
+
+	// held in read mode by every transaction until it is closed,
+	// and in write mode by Rekey.
+	mu   sync.RWMutex
+	aead cipher.AEAD
+
+	storesMu sync.Mutex
+	stores   map[string]struct{}
+}
+
+// NewEngine wraps ng and encrypts its values using key.
+// If ng was already encrypted, key is verified and ErrDecryption is returned
+// if it doesn't match the key used to encrypt it.
+func NewEngine(ctx context.Context, ng engine.Engine, key []byte) (*Engine, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	e := Engine{
+		ng:     ng,
+		aead:   aead,
+		stores: make(map[string]struct{}),
+	}
+
+	ok, err := e.load(ctx)
+	if err != nil || ok {
+		return &e, err
+	}
+
+	// first use of the engine: store the check value.
+	tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.CreateStore([]byte(metaStoreName))
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := tx.GetStore([]byte(metaStoreName))
+	if err != nil {
+		return nil, err
+	}
+
+	err = putCheckValue(st, aead)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// load verifies the key of the engine and loads the list of stores.
+// It returns false if the engine was never encrypted.
+func (e *Engine) load(ctx context.Context) (bool, error) {
+	tx, err := e.ng.Begin(ctx, engine.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	st, err := tx.GetStore([]byte(metaStoreName))
+	if err == engine.ErrStoreNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	v, err := st.Get(checkKey)
+	if err != nil {
+		return false, err
+	}
+
+	v, err = decrypt(nil, e.aead, checkKey, v)
+	if err != nil {
+		return false, err
+	}
+	if string(v) != string(checkValue) {
+		return false, ErrDecryption
+	}
+
+	it := st.Iterator(engine.IteratorOptions{})
+	defer it.Close()
+
+	for it.Seek(storePrefix); it.Valid(); it.Next() {
+		k := it.Item().Key()
+		if len(k) < len(storePrefix) || string(k[:len(storePrefix)]) != string(storePrefix) {
+			break
+		}
+
+		e.stores[string(k[len(storePrefix):])] = struct{}{}
+	}
+
+	return true, it.Err()
+}
+
+func putCheckValue(st engine.Store, aead cipher.AEAD) error {
+	v, err := encrypt(aead, checkKey, checkValue)
+	if err != nil {
+		return err
+	}
+
+	return st.Put(checkKey, v)
+}
+
+// IsEncrypted returns whether ng was encrypted by an Engine.
+func IsEncrypted(ctx context.Context, ng engine.Engine) (bool, error) {
+	tx, err := ng.Begin(ctx, engine.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.GetStore([]byte(metaStoreName))
+	if err == engine.ErrStoreNotFound {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Begin starts a transaction on the underlying engine.
+// It blocks while Rekey is running.
+func (e *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	e.mu.RLock()
+
+	tx, err := e.ng.Begin(ctx, opts)
+	if err != nil {
+		e.mu.RUnlock()
+		return nil, err
+	}
+
+	return &transaction{
+		Transaction: tx,
+		e:           e,
+		aead:        e.aead,
+		writable:    opts.Writable,
+	}, nil
+}
+
+// Close the underlying engine.
+func (e *Engine) Close() error {
+	return e.ng.Close()
+}
+
+// Rekey encrypts every value of the engine with key, within a single transaction
+// of the underlying engine. It waits for the other transactions to be closed
+// and blocks new ones until it returns, so it must not be called by a goroutine
+// that holds an open transaction.
+func (e *Engine) Rekey(ctx context.Context, key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tx, err := e.ng.Begin(ctx, engine.TxOptions{Writable: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	e.storesMu.Lock()
+	names := make([]string, 0, len(e.stores))
+	for name := range e.stores {
+		names = append(names, name)
+	}
+	e.storesMu.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		st, err := tx.GetStore([]byte(name))
+		if err == engine.ErrStoreNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		err = reencryptStore(st, e.aead, aead)
+		if err != nil {
+			return err
+		}
+	}
+
+	st, err := tx.GetStore([]byte(metaStoreName))
+	if err != nil {
+		return err
+	}
+
+	err = putCheckValue(st, aead)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	e.aead = aead
+	return nil
+}
+
+// reencryptStore decrypts every value of st with old and encrypts it with new.
+// The pairs are read first, to avoid modifying the store while iterating on it.
+func reencryptStore(st engine.Store, old, new cipher.AEAD) error {
+	var keys, values [][]byte
+
+	it := st.Iterator(engine.IteratorOptions{})
+	for it.Seek(nil); it.Valid(); it.Next() {
+		itm := it.Item()
+
+		v, err := itm.ValueCopy(nil)
+		if err != nil {
+			it.Close()
+			return err
+		}
+
+		keys = append(keys, append([]byte(nil), itm.Key()...))
+		values = append(values, v)
+	}
+	err := it.Err()
+	it.Close()
+	if err != nil {
+		return err
+	}
+
+	for i, k := range keys {
+		v, err := decrypt(nil, old, k, values[i])
+		if err != nil {
+			return err
+		}
+
+		v, err = encrypt(new, k, v)
+		if err != nil {
+			return err
+		}
+
+		err = st.Put(k, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// register adds the given stores to the list of stores of the engine.
+func (e *Engine) register(names []string) {
+	e.storesMu.Lock()
+	defer e.storesMu.Unlock()
+
+	for _, name := range names {
+		e.stores[name] = struct{}{}
+	}
+}
+
+func (e *Engine) isRegistered(name string) bool {
+	e.storesMu.Lock()
+	defer e.storesMu.Unlock()
+
+	_, ok := e.stores[name]
+	return ok
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns the nonce followed by the encrypted value.
+func encrypt(aead cipher.AEAD, k, v []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(v)+aead.Overhead())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, v, k), nil
+}
+
+// decrypt appends the decrypted value of v to dst.
+func decrypt(dst []byte, aead cipher.AEAD, k, v []byte) ([]byte, error) {
+	if len(v) < aead.NonceSize() {
+		return nil, ErrDecryption
+	}
+
+	nonce, ciphertext := v[:aead.NonceSize()], v[aead.NonceSize():]
+	d, err := aead.Open(dst, nonce, ciphertext, k)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+
+	return d, nil
+}
@@ -0,0 +1,164 @@
+package encryptedengine_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/encryptedengine"
+	"github.com/genjidb/genji/engine/enginetest"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	key1 = bytes.Repeat([]byte{1}, encryptedengine.KeySize)
+	key2 = bytes.Repeat([]byte{2}, encryptedengine.KeySize)
+)
+
+func builder() (engine.Engine, func()) {
+	ng, err := encryptedengine.NewEngine(context.Background(), memoryengine.NewEngine(), key1)
+	if err != nil {
+		panic(err)
+	}
+	return ng, func() { ng.Close() }
+}
+
+func TestEncryptedEngine(t *testing.T) {
+	enginetest.TestSuite(t, builder)
+}
+
+// put stores a pair in the given store, creating it if necessary.
+func put(t *testing.T, ng engine.Engine, store string, k, v []byte) {
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	st, err := tx.GetStore([]byte(store))
+	if err == engine.ErrStoreNotFound {
+		err = tx.CreateStore([]byte(store))
+		require.NoError(t, err)
+		st, err = tx.GetStore([]byte(store))
+	}
+	require.NoError(t, err)
+
+	err = st.Put(k, v)
+	require.NoError(t, err)
+	err = tx.Commit()
+	require.NoError(t, err)
+}
+
+func get(t *testing.T, ng engine.Engine, store string, k []byte) ([]byte, error) {
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	st, err := tx.GetStore([]byte(store))
+	require.NoError(t, err)
+
+	return st.Get(k)
+}
+
+func TestEngine(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Invalid key", func(t *testing.T) {
+		_, err := encryptedengine.NewEngine(ctx, memoryengine.NewEngine(), []byte("foo"))
+		require.Equal(t, encryptedengine.ErrInvalidKey, err)
+	})
+
+	t.Run("Encrypted values", func(t *testing.T) {
+		mem := memoryengine.NewEngine()
+		ng, err := encryptedengine.NewEngine(ctx, mem, key1)
+		require.NoError(t, err)
+
+		put(t, ng, "test", []byte("a"), []byte("foo"))
+		put(t, ng, "test", []byte("b"), []byte("foo"))
+
+		raw1, err := get(t, mem, "test", []byte("a"))
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(raw1, []byte("foo")))
+		raw2, err := get(t, mem, "test", []byte("b"))
+		require.NoError(t, err)
+		require.NotEqual(t, raw1[:12], raw2[:12], "nonces must differ")
+
+		v, err := get(t, ng, "test", []byte("a"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("foo"), v)
+
+		tx, err := ng.Begin(ctx, engine.TxOptions{})
+		require.NoError(t, err)
+		defer tx.Rollback()
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		it := st.Iterator(engine.IteratorOptions{})
+		defer it.Close()
+		var values []string
+		for it.Seek(nil); it.Valid(); it.Next() {
+			v, err := it.Item().ValueCopy(nil)
+			require.NoError(t, err)
+			values = append(values, string(v))
+		}
+		require.Equal(t, []string{"foo", "foo"}, values)
+	})
+
+	t.Run("Moved value", func(t *testing.T) {
+		mem := memoryengine.NewEngine()
+		ng, err := encryptedengine.NewEngine(ctx, mem, key1)
+		require.NoError(t, err)
+
+		put(t, ng, "test", []byte("a"), []byte("foo"))
+		raw, err := get(t, mem, "test", []byte("a"))
+		require.NoError(t, err)
+		put(t, mem, "test", []byte("b"), raw)
+
+		_, err = get(t, ng, "test", []byte("b"))
+		require.Equal(t, encryptedengine.ErrDecryption, err)
+	})
+
+	t.Run("Wrong key", func(t *testing.T) {
+		mem := memoryengine.NewEngine()
+		ng, err := encryptedengine.NewEngine(ctx, mem, key1)
+		require.NoError(t, err)
+		put(t, ng, "test", []byte("a"), []byte("foo"))
+
+		ok, err := encryptedengine.IsEncrypted(ctx, mem)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		_, err = encryptedengine.NewEngine(ctx, mem, key2)
+		require.Equal(t, encryptedengine.ErrDecryption, err)
+	})
+
+	t.Run("Rekey", func(t *testing.T) {
+		mem := memoryengine.NewEngine()
+		ng, err := encryptedengine.NewEngine(ctx, mem, key1)
+		require.NoError(t, err)
+		put(t, ng, "foo", []byte("a"), []byte("A"))
+		put(t, ng, "bar", []byte("b"), []byte("B"))
+
+		err = ng.Rekey(ctx, []byte("short"))
+		require.Equal(t, encryptedengine.ErrInvalidKey, err)
+
+		err = ng.Rekey(ctx, key2)
+		require.NoError(t, err)
+
+		v, err := get(t, ng, "foo", []byte("a"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("A"), v)
+
+		_, err = encryptedengine.NewEngine(ctx, mem, key1)
+		require.Equal(t, encryptedengine.ErrDecryption, err)
+
+		// the list of stores is loaded when the engine is reopened
+		ng, err = encryptedengine.NewEngine(ctx, mem, key2)
+		require.NoError(t, err)
+		err = ng.Rekey(ctx, key1)
+		require.NoError(t, err)
+
+		v, err = get(t, ng, "bar", []byte("b"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("B"), v)
+	})
+}
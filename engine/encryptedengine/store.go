@@ -0,0 +1,102 @@
+package encryptedengine
+
+import (
+	"crypto/cipher"
+	"sync"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// transaction wraps a transaction of the underlying engine.
+// It holds the read lock of the engine until it is closed.
+type transaction struct {
+	engine.Transaction
+
+	e        *Engine
+	aead     cipher.AEAD
+	writable bool
+	once     sync.Once
+
+	// stores registered during the transaction, added to the engine on commit.
+	registered []string
+}
+
+func (t *transaction) release() {
+	t.once.Do(t.e.mu.RUnlock)
+}
+
+func (t *transaction) Rollback() error {
+	defer t.release()
+
+	return t.Transaction.Rollback()
+}
+
+func (t *transaction) Commit() error {
+	defer t.release()
+
+	err := t.Transaction.Commit()
+	if err != nil {
+		return err
+	}
+
+	t.e.register(t.registered)
+	return nil
+}
+
+// GetStore returns a store that encrypts and decrypts its values.
+// In read/write transactions, the store is added to the list of stores of the engine,
+// so that Rekey can find it.
+func (t *transaction) GetStore(name []byte) (engine.Store, error) {
+	st, err := t.Transaction.GetStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.writable && string(name) != metaStoreName && !t.isRegistered(string(name)) {
+		err = t.register(string(name))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return engine.NewCodecStore(st, aeadCodec{aead: t.aead}), nil
+}
+
+func (t *transaction) isRegistered(name string) bool {
+	for _, n := range t.registered {
+		if n == name {
+			return true
+		}
+	}
+
+	return t.e.isRegistered(name)
+}
+
+func (t *transaction) register(name string) error {
+	meta, err := t.Transaction.GetStore([]byte(metaStoreName))
+	if err != nil {
+		return err
+	}
+
+	k := append(append([]byte(nil), storePrefix...), name...)
+	err = meta.Put(k, []byte{})
+	if err != nil {
+		return err
+	}
+
+	t.registered = append(t.registered, name)
+	return nil
+}
+
+// aeadCodec encrypts the values of a store, using their key as additional data.
+type aeadCodec struct {
+	aead cipher.AEAD
+}
+
+func (c aeadCodec) Encode(k, v []byte) ([]byte, error) {
+	return encrypt(c.aead, k, v)
+}
+
+func (c aeadCodec) Decode(dst, k, v []byte) ([]byte, error) {
+	return decrypt(dst, c.aead, k, v)
+}
@@ -20,6 +20,10 @@ var (
 
 	// ErrKeyNotFound is returned when the targeted key doesn't exist.
 	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrSerializationFailure is returned when a transaction conflicts with a concurrent one
+	// and can't be committed. Running the transaction again may succeed.
+	ErrSerializationFailure = errors.New("serialization failure")
 )
 
 // An Engine is responsible for storing data.
@@ -42,6 +42,7 @@ func TestSuite(t *testing.T, builder Builder) {
 		{"Store/NextSequence", TestStoreNextSequence},
 		{"TestQueries", TestQueries},
 		{"TestQueriesSameTransaction", TestQueriesSameTransaction},
+		{"TestIndexedQueriesSameTransaction", TestIndexedQueriesSameTransaction},
 	}
 
 	for _, test := range tests {
@@ -1005,6 +1006,43 @@ func TestStoreDelete(t *testing.T, builder Builder) {
 		require.Equal(t, []byte("BAR"), v)
 	})
 
+	t.Run("Should keep a key put again after deletion", func(t *testing.T) {
+		ng, cleanup := builder()
+		defer cleanup()
+		defer ng.Close()
+
+		tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		err = tx.CreateStore([]byte("test"))
+		require.NoError(t, err)
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		err = st.Put([]byte("foo"), []byte("FOO"))
+		require.NoError(t, err)
+		err = tx.Commit()
+		require.NoError(t, err)
+
+		tx, err = ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		st, err = tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		err = st.Delete([]byte("foo"))
+		require.NoError(t, err)
+		err = st.Put([]byte("foo"), []byte("BAR"))
+		require.NoError(t, err)
+		err = tx.Commit()
+		require.NoError(t, err)
+
+		tx, err = ng.Begin(context.Background(), engine.TxOptions{})
+		require.NoError(t, err)
+		defer tx.Rollback()
+		st, err = tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		v, err := st.Get([]byte("foo"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("BAR"), v)
+	})
+
 	t.Run("Should fail if context canceled", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -1050,6 +1088,40 @@ func TestStoreTruncate(t *testing.T, builder Builder) {
 		require.False(t, it.Valid())
 	})
 
+	t.Run("Should persist after commit", func(t *testing.T) {
+		ng, cleanup := builder()
+		defer cleanup()
+		defer ng.Close()
+
+		tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		err = tx.CreateStore([]byte("test"))
+		require.NoError(t, err)
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		err = st.Put([]byte("foo"), []byte("FOO"))
+		require.NoError(t, err)
+		err = tx.Commit()
+		require.NoError(t, err)
+
+		tx, err = ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		st, err = tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		err = st.Truncate()
+		require.NoError(t, err)
+		err = tx.Commit()
+		require.NoError(t, err)
+
+		tx, err = ng.Begin(context.Background(), engine.TxOptions{})
+		require.NoError(t, err)
+		defer tx.Rollback()
+		st, err = tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		_, err = st.Get([]byte("foo"))
+		require.Equal(t, engine.ErrKeyNotFound, err)
+	})
+
 	t.Run("Should fail if context canceled", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -1379,3 +1451,81 @@ func TestQueriesSameTransaction(t *testing.T, builder Builder) {
 		require.NoError(t, err)
 	})
 }
+
+func TestIndexedQueriesSameTransaction(t *testing.T, builder Builder) {
+	ng, cleanup := builder()
+	defer cleanup()
+	defer func() {
+		require.NoError(t, ng.Close())
+	}()
+
+	db, err := genji.New(context.Background(), ng)
+	require.NoError(t, err)
+
+	err = db.Exec(`
+		CREATE TABLE test(id INTEGER PRIMARY KEY);
+		CREATE INDEX idx_a ON test(a);
+		CREATE UNIQUE INDEX idx_b ON test(b);
+		INSERT INTO test (id, a, b) VALUES (1, 10, 'x');
+	`)
+	require.NoError(t, err)
+
+	query := func(t *testing.T, tx *genji.Tx, q string, expected string) {
+		t.Helper()
+
+		st, err := tx.Query(q)
+		require.NoError(t, err)
+		defer st.Close()
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, st)
+		require.NoError(t, err)
+		require.JSONEq(t, expected, buf.String(), q)
+	}
+
+	err = db.Update(func(tx *genji.Tx) error {
+		steps := []struct {
+			stmt     string
+			query    string
+			expected string
+		}{
+			{"INSERT INTO test (id, a, b) VALUES (2, 20, 'y')", "SELECT id FROM test WHERE a = 20", `[{"id": 2}]`},
+			{"", "SELECT id FROM test WHERE b = 'y'", `[{"id": 2}]`},
+			{"", "SELECT a FROM test WHERE id = 2", `[{"a": 20}]`},
+			{"", "SELECT id FROM test WHERE a > 5", `[{"id": 1}, {"id": 2}]`},
+			{"UPDATE test SET a = 30, b = 'z' WHERE id = 1", "SELECT id FROM test WHERE a = 30", `[{"id": 1}]`},
+			{"", "SELECT id FROM test WHERE a = 10", `[]`},
+			{"", "SELECT id FROM test WHERE b = 'z'", `[{"id": 1}]`},
+			{"", "SELECT id FROM test WHERE b = 'x'", `[]`},
+			{"DELETE FROM test WHERE a = 20", "SELECT id FROM test WHERE a = 20", `[]`},
+			{"", "SELECT id FROM test WHERE b = 'y'", `[]`},
+			{"", "SELECT id FROM test WHERE id = 2", `[]`},
+			{"", "SELECT id FROM test WHERE a > 5", `[{"id": 1}]`},
+			{"INSERT INTO test (id, a, b) VALUES (2, 20, 'y')", "SELECT id FROM test WHERE a IN (20, 30)", `[{"id": 2}, {"id": 1}]`},
+			{"", "SELECT COUNT(*) AS n FROM test", `[{"n": 2}]`},
+		}
+
+		for _, step := range steps {
+			if step.stmt != "" {
+				require.NoError(t, tx.Exec(step.stmt), step.stmt)
+			}
+
+			query(t, tx, step.query, step.expected)
+		}
+
+		// a query started before a write of the same transaction
+		// must be closed before writing, but later queries must see the write.
+		query(t, tx, "SELECT id FROM test WHERE b = 'z'", `[{"id": 1}]`)
+		require.NoError(t, tx.Exec("UPDATE test SET b = 'w' WHERE b = 'z'"))
+		query(t, tx, "SELECT id FROM test WHERE b = 'w'", `[{"id": 1}]`)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// once committed, the writes are visible to other transactions
+	err = db.View(func(tx *genji.Tx) error {
+		query(t, tx, "SELECT id, a, b FROM test WHERE a > 0", `[{"id": 2, "a": 20, "b": "y"}, {"id": 1, "a": 30, "b": "w"}]`)
+		return nil
+	})
+	require.NoError(t, err)
+}
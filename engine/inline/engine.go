@@ -0,0 +1,260 @@
+// Package inline implements an in-memory engine that stores every store in a Go map.
+// It is meant to test the code relying on the storage layer without opening a real
+// database, and favors simplicity over performance: read/write transactions work on
+// copies of the stores they modify, which replace the original stores on commit.
+//
+// NewFailing creates an engine whose writes start failing after a given number of them,
+// to test how errors returned by the storage layer are handled.
+package inline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// Engine is an in-memory engine. It allows multiple readers and one single writer.
+// Readers are never blocked by the writer: they see the stores as they were
+// when their transaction began.
+type Engine struct {
+	// held by the read/write transaction during its whole lifetime.
+	writer sync.Mutex
+
+	// protects stores and closed.
+	mu     sync.RWMutex
+	stores map[string]*store
+	closed bool
+
+	// number of writes allowed before failing, -1 if unlimited.
+	// only accessed by the read/write transaction.
+	writesLeft int
+}
+
+// New creates an in-memory engine.
+func New() *Engine {
+	return &Engine{
+		stores:     make(map[string]*store),
+		writesLeft: -1,
+	}
+}
+
+// NewFailing creates an in-memory engine whose writes return io.ErrUnexpectedEOF
+// once after writes were made. Writes are calls to the Put, Delete and Truncate methods
+// of the stores, whether their transaction is committed or not.
+func NewFailing(after int) *Engine {
+	ng := New()
+	ng.writesLeft = after
+	return ng
+}
+
+// Begin creates a transaction.
+func (ng *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if opts.Writable {
+		ng.writer.Lock()
+	}
+
+	ng.mu.RLock()
+	closed := ng.closed
+	stores := ng.stores
+	ng.mu.RUnlock()
+
+	if closed {
+		if opts.Writable {
+			ng.writer.Unlock()
+		}
+		return nil, errors.New("engine closed")
+	}
+
+	tx := transaction{
+		ctx:      ctx,
+		ng:       ng,
+		writable: opts.Writable,
+		stores:   stores,
+	}
+
+	if opts.Writable {
+		// the map is copied so that created and dropped stores
+		// are not visible outside of the transaction.
+		tx.stores = make(map[string]*store, len(stores))
+		for name, st := range stores {
+			tx.stores[name] = st
+		}
+		tx.owned = make(map[*store]bool)
+	}
+
+	return &tx, nil
+}
+
+// Close the engine, once the current read/write transaction, if any, is terminated.
+func (ng *Engine) Close() error {
+	ng.writer.Lock()
+	defer ng.writer.Unlock()
+
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	if ng.closed {
+		return errors.New("engine already closed")
+	}
+
+	ng.closed = true
+	return nil
+}
+
+// write returns io.ErrUnexpectedEOF if the engine doesn't allow any more writes.
+func (ng *Engine) write() error {
+	if ng.writesLeft < 0 {
+		return nil
+	}
+
+	if ng.writesLeft == 0 {
+		return io.ErrUnexpectedEOF
+	}
+
+	ng.writesLeft--
+	return nil
+}
+
+// This implements the engine.Transaction type.
+type transaction struct {
+	ctx        context.Context
+	ng         *Engine
+	writable   bool
+	terminated bool
+
+	// stores visible by the transaction.
+	stores map[string]*store
+	// stores copied by the transaction, which can be modified.
+	owned map[*store]bool
+}
+
+// Rollback discards the changes made by the transaction.
+func (tx *transaction) Rollback() error {
+	if tx.terminated {
+		return nil
+	}
+
+	tx.terminated = true
+	tx.stores = nil
+
+	if tx.writable {
+		tx.ng.writer.Unlock()
+	}
+
+	select {
+	case <-tx.ctx.Done():
+		return tx.ctx.Err()
+	default:
+	}
+
+	return nil
+}
+
+// Commit replaces the stores of the engine by the stores of the transaction.
+func (tx *transaction) Commit() error {
+	if tx.terminated {
+		return errors.New("transaction already terminated")
+	}
+
+	if !tx.writable {
+		return engine.ErrTransactionReadOnly
+	}
+
+	select {
+	case <-tx.ctx.Done():
+		return tx.Rollback()
+	default:
+	}
+
+	tx.terminated = true
+
+	tx.ng.mu.Lock()
+	tx.ng.stores = tx.stores
+	tx.ng.mu.Unlock()
+
+	tx.ng.writer.Unlock()
+
+	return nil
+}
+
+func (tx *transaction) GetStore(name []byte) (engine.Store, error) {
+	select {
+	case <-tx.ctx.Done():
+		return nil, tx.ctx.Err()
+	default:
+	}
+
+	if _, ok := tx.stores[string(name)]; !ok {
+		return nil, engine.ErrStoreNotFound
+	}
+
+	return &storeTx{tx: tx, name: string(name)}, nil
+}
+
+func (tx *transaction) CreateStore(name []byte) error {
+	select {
+	case <-tx.ctx.Done():
+		return tx.ctx.Err()
+	default:
+	}
+
+	if !tx.writable {
+		return engine.ErrTransactionReadOnly
+	}
+
+	if _, ok := tx.stores[string(name)]; ok {
+		return engine.ErrStoreAlreadyExists
+	}
+
+	st := newStore()
+	tx.stores[string(name)] = st
+	tx.owned[st] = true
+
+	return nil
+}
+
+func (tx *transaction) DropStore(name []byte) error {
+	select {
+	case <-tx.ctx.Done():
+		return tx.ctx.Err()
+	default:
+	}
+
+	if !tx.writable {
+		return engine.ErrTransactionReadOnly
+	}
+
+	if _, ok := tx.stores[string(name)]; !ok {
+		return engine.ErrStoreNotFound
+	}
+
+	delete(tx.stores, string(name))
+
+	return nil
+}
+
+// store returns the store with the given name. If writable is true,
+// the store is copied the first time it is modified by the transaction.
+func (tx *transaction) store(name string, writable bool) (*store, error) {
+	st, ok := tx.stores[name]
+	if !ok {
+		return nil, engine.ErrStoreNotFound
+	}
+
+	if writable && !tx.owned[st] {
+		st = st.clone()
+		tx.stores[name] = st
+		tx.owned[st] = true
+	}
+
+	return st, nil
+}
@@ -0,0 +1,146 @@
+package inline_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/enginetest"
+	"github.com/genjidb/genji/engine/inline"
+	"github.com/stretchr/testify/require"
+)
+
+func builder() (engine.Engine, func()) {
+	ng := inline.New()
+	return ng, func() { ng.Close() }
+}
+
+func TestInlineEngine(t *testing.T) {
+	enginetest.TestSuite(t, builder)
+}
+
+func TestSnapshot(t *testing.T) {
+	ng := inline.New()
+	defer ng.Close()
+
+	ctx := context.Background()
+
+	tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	require.NoError(t, tx.CreateStore([]byte("test")))
+	st, err := tx.GetStore([]byte("test"))
+	require.NoError(t, err)
+	require.NoError(t, st.Put([]byte("a"), []byte("1")))
+	require.NoError(t, tx.Commit())
+
+	// readers are not blocked by the writer and don't see its changes.
+	wtx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer wtx.Rollback()
+	wst, err := wtx.GetStore([]byte("test"))
+	require.NoError(t, err)
+	require.NoError(t, wst.Put([]byte("a"), []byte("2")))
+
+	rtx, err := ng.Begin(ctx, engine.TxOptions{})
+	require.NoError(t, err)
+	defer rtx.Rollback()
+	rst, err := rtx.GetStore([]byte("test"))
+	require.NoError(t, err)
+
+	v, err := rst.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+
+	require.NoError(t, wtx.Commit())
+
+	v, err = rst.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+}
+
+func TestIteratorDeleteWhileIterating(t *testing.T) {
+	ng := inline.New()
+	defer ng.Close()
+
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	require.NoError(t, tx.CreateStore([]byte("test")))
+	st, err := tx.GetStore([]byte("test"))
+	require.NoError(t, err)
+
+	for i := 1; i <= 10; i++ {
+		require.NoError(t, st.Put([]byte{uint8(i)}, []byte{uint8(i + 20)}))
+	}
+
+	for _, reverse := range []bool{false, true} {
+		it := st.Iterator(engine.IteratorOptions{Reverse: reverse})
+
+		var keys []byte
+		for it.Seek(nil); it.Valid(); it.Next() {
+			k := it.Item().Key()
+			keys = append(keys, k[0])
+			if k[0]%2 == 0 {
+				require.NoError(t, st.Delete(k))
+			}
+		}
+		require.NoError(t, it.Err())
+		require.NoError(t, it.Close())
+
+		if !reverse {
+			require.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, keys)
+		} else {
+			require.Equal(t, []byte{9, 7, 5, 3, 1}, keys)
+		}
+	}
+}
+
+func TestNewFailing(t *testing.T) {
+	t.Run("Store", func(t *testing.T) {
+		ng := inline.NewFailing(2)
+		defer ng.Close()
+
+		tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		require.NoError(t, tx.CreateStore([]byte("test")))
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+
+		require.NoError(t, st.Put([]byte("a"), []byte("1")))
+		require.NoError(t, st.Delete([]byte("a")))
+		require.Equal(t, io.ErrUnexpectedEOF, st.Put([]byte("b"), []byte("2")))
+		require.Equal(t, io.ErrUnexpectedEOF, st.Truncate())
+
+		// reads still work
+		_, err = st.Get([]byte("a"))
+		require.Equal(t, engine.ErrKeyNotFound, err)
+	})
+
+	t.Run("DB", func(t *testing.T) {
+		db, err := genji.New(context.Background(), inline.NewFailing(20))
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test")
+		require.NoError(t, err)
+
+		for err == nil {
+			err = db.Exec("INSERT INTO test (a) VALUES (1)")
+		}
+		require.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	})
+}
+
+func BenchmarkInlineEngineStorePut(b *testing.B) {
+	enginetest.BenchmarkStorePut(b, builder)
+}
+
+func BenchmarkInlineEngineStoreScan(b *testing.B) {
+	enginetest.BenchmarkStoreScan(b, builder)
+}
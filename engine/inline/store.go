@@ -0,0 +1,323 @@
+package inline
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// store holds the key-value pairs of a store.
+// once committed, a store is never modified: transactions
+// modify copies of it.
+type store struct {
+	data map[string][]byte
+	// keys of data, sorted.
+	keys []string
+	seq  uint64
+}
+
+func newStore() *store {
+	return &store{
+		data: make(map[string][]byte),
+	}
+}
+
+func (st *store) clone() *store {
+	c := store{
+		data: make(map[string][]byte, len(st.data)),
+		keys: make([]string, len(st.keys)),
+		seq:  st.seq,
+	}
+
+	for k, v := range st.data {
+		c.data[k] = v
+	}
+	copy(c.keys, st.keys)
+
+	return &c
+}
+
+// search returns the position of the first key greater than or equal to k.
+func (st *store) search(k string) int {
+	return sort.SearchStrings(st.keys, k)
+}
+
+func (st *store) put(k string, v []byte) {
+	if _, ok := st.data[k]; !ok {
+		i := st.search(k)
+		st.keys = append(st.keys, "")
+		copy(st.keys[i+1:], st.keys[i:])
+		st.keys[i] = k
+	}
+
+	st.data[k] = v
+}
+
+func (st *store) delete(k string) {
+	i := st.search(k)
+	st.keys = append(st.keys[:i], st.keys[i+1:]...)
+	delete(st.data, k)
+}
+
+// storeTx implements an engine.Store.
+type storeTx struct {
+	tx   *transaction
+	name string
+}
+
+func (s *storeTx) Put(k, v []byte) error {
+	select {
+	case <-s.tx.ctx.Done():
+		return s.tx.ctx.Err()
+	default:
+	}
+
+	if !s.tx.writable {
+		return engine.ErrTransactionReadOnly
+	}
+
+	if len(k) == 0 {
+		return errors.New("empty keys are forbidden")
+	}
+
+	st, err := s.tx.store(s.name, true)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tx.ng.write(); err != nil {
+		return err
+	}
+
+	// the caller may reuse v once Put returns.
+	st.put(string(k), append([]byte{}, v...))
+	return nil
+}
+
+func (s *storeTx) Get(k []byte) ([]byte, error) {
+	select {
+	case <-s.tx.ctx.Done():
+		return nil, s.tx.ctx.Err()
+	default:
+	}
+
+	st, err := s.tx.store(s.name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := st.data[string(k)]
+	if !ok {
+		return nil, engine.ErrKeyNotFound
+	}
+
+	return v, nil
+}
+
+func (s *storeTx) Delete(k []byte) error {
+	select {
+	case <-s.tx.ctx.Done():
+		return s.tx.ctx.Err()
+	default:
+	}
+
+	if !s.tx.writable {
+		return engine.ErrTransactionReadOnly
+	}
+
+	st, err := s.tx.store(s.name, false)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := st.data[string(k)]; !ok {
+		return engine.ErrKeyNotFound
+	}
+
+	st, err = s.tx.store(s.name, true)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tx.ng.write(); err != nil {
+		return err
+	}
+
+	st.delete(string(k))
+	return nil
+}
+
+func (s *storeTx) Truncate() error {
+	select {
+	case <-s.tx.ctx.Done():
+		return s.tx.ctx.Err()
+	default:
+	}
+
+	if !s.tx.writable {
+		return engine.ErrTransactionReadOnly
+	}
+
+	st, err := s.tx.store(s.name, true)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tx.ng.write(); err != nil {
+		return err
+	}
+
+	st.data = make(map[string][]byte)
+	st.keys = nil
+	return nil
+}
+
+// NextSequence returns a monotonically increasing integer.
+func (s *storeTx) NextSequence() (uint64, error) {
+	select {
+	case <-s.tx.ctx.Done():
+		return 0, s.tx.ctx.Err()
+	default:
+	}
+
+	if !s.tx.writable {
+		return 0, engine.ErrTransactionReadOnly
+	}
+
+	st, err := s.tx.store(s.name, true)
+	if err != nil {
+		return 0, err
+	}
+
+	st.seq++
+	return st.seq, nil
+}
+
+// Iterator creates an iterator with the given options.
+func (s *storeTx) Iterator(opts engine.IteratorOptions) engine.Iterator {
+	return &iterator{
+		s:       s,
+		reverse: opts.Reverse,
+	}
+}
+
+// iterator doesn't keep a position in the store, but the current key:
+// the store may be modified while iterating, the next key is searched
+// every time Next is called.
+type iterator struct {
+	s       *storeTx
+	reverse bool
+	item    item
+	valid   bool
+	err     error
+}
+
+func (it *iterator) Seek(pivot []byte) {
+	st, ok := it.current()
+	if !ok {
+		return
+	}
+
+	var i int
+	switch {
+	case !it.reverse:
+		i = st.search(string(pivot))
+	case len(pivot) == 0:
+		i = len(st.keys) - 1
+	default:
+		// last key lower than or equal to the pivot
+		i = sort.Search(len(st.keys), func(i int) bool { return st.keys[i] > string(pivot) }) - 1
+	}
+
+	it.move(st, i)
+}
+
+// Next moves the iterator to the key following the current one,
+// in the order of the iterator.
+func (it *iterator) Next() {
+	if !it.valid {
+		return
+	}
+
+	st, ok := it.current()
+	if !ok {
+		return
+	}
+
+	k := string(it.item.k)
+	i := st.search(k)
+	if it.reverse {
+		i--
+	} else if i < len(st.keys) && st.keys[i] == k {
+		i++
+	}
+
+	it.move(st, i)
+}
+
+// current returns the store as seen by the transaction,
+// or false if the iterator can't be used anymore.
+func (it *iterator) current() (*store, bool) {
+	it.valid = false
+
+	select {
+	case <-it.s.tx.ctx.Done():
+		it.err = it.s.tx.ctx.Err()
+		return nil, false
+	default:
+	}
+
+	st, err := it.s.tx.store(it.s.name, false)
+	if err != nil {
+		it.err = err
+		return nil, false
+	}
+
+	return st, true
+}
+
+// move positions the iterator on the i-th key of the store.
+func (it *iterator) move(st *store, i int) {
+	if i < 0 || i >= len(st.keys) {
+		return
+	}
+
+	k := st.keys[i]
+	it.item = item{k: []byte(k), v: st.data[k]}
+	it.valid = true
+}
+
+func (it *iterator) Valid() bool {
+	return it.valid && it.err == nil
+}
+
+func (it *iterator) Err() error {
+	return it.err
+}
+
+func (it *iterator) Item() engine.Item {
+	return &it.item
+}
+
+func (it *iterator) Close() error {
+	it.valid = false
+	return nil
+}
+
+// item implements an engine.Item.
+type item struct {
+	k, v []byte
+}
+
+func (i *item) Key() []byte {
+	return i.k
+}
+
+func (i *item) ValueCopy(buf []byte) ([]byte, error) {
+	if len(buf) < len(i.v) {
+		buf = make([]byte, len(i.v))
+	}
+	n := copy(buf, i.v)
+	return buf[:n], nil
+}
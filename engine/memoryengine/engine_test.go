@@ -1,11 +1,14 @@
 package memoryengine_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji/engine"
 	"github.com/genjidb/genji/engine/enginetest"
 	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/stretchr/testify/require"
 )
 
 func builder() (engine.Engine, func()) {
@@ -17,6 +20,42 @@ func TestMemoryEngine(t *testing.T) {
 	enginetest.TestSuite(t, builder)
 }
 
+func TestIteratorContextCanceled(t *testing.T) {
+	ng := memoryengine.NewEngine()
+	defer ng.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	err = tx.CreateStore([]byte("test"))
+	require.NoError(t, err)
+	st, err := tx.GetStore([]byte("test"))
+	require.NoError(t, err)
+
+	for i := 1; i <= 10; i++ {
+		err := st.Put([]byte{uint8(i)}, []byte{uint8(i + 20)})
+		require.NoError(t, err)
+	}
+
+	it := st.Iterator(engine.IteratorOptions{})
+	defer it.Close()
+
+	// canceling during the iteration must not look like the end of the store,
+	// even if the goroutine reading the tree has already stopped.
+	var i int
+	for it.Seek(nil); it.Valid(); it.Next() {
+		i++
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, context.Canceled, it.Err())
+	require.Less(t, i, 10)
+}
+
 func BenchmarkMemoryEngineStorePut(b *testing.B) {
 	enginetest.BenchmarkStorePut(b, builder)
 }
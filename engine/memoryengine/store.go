@@ -152,9 +152,12 @@ func (s *storeTx) Delete(k []byte) error {
 		i.deleted = false
 	})
 
-	// on commit, remove the item from the tree.
+	// on commit, remove the item from the tree,
+	// unless it was put again in the meantime.
 	s.tx.onCommit = append(s.tx.onCommit, func() {
-		s.tr.Delete(i)
+		if i.deleted {
+			s.tr.Delete(i)
+		}
 	})
 	return nil
 }
@@ -175,10 +178,12 @@ func (s *storeTx) Truncate() error {
 
 	old := s.tr
 	s.tr = btree.New(btreeDegree)
+	s.tx.ng.stores[s.name] = s.tr
 
 	// on rollback replace the new tree by the old one.
 	s.tx.onRollback = append(s.tx.onRollback, func() {
 		s.tr = old
+		s.tx.ng.stores[s.name] = old
 	})
 
 	return nil
@@ -297,6 +302,11 @@ func (it *iterator) Valid() bool {
 func (it *iterator) Next() {
 	select {
 	case it.item = <-it.ch:
+		// the goroutine closes the channel when it stops,
+		// which happens early if the context is canceled.
+		if it.item == nil {
+			it.err = it.tx.ctx.Err()
+		}
 	case <-it.tx.ctx.Done():
 		it.err = it.tx.ctx.Err()
 	}
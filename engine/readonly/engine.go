@@ -0,0 +1,213 @@
+// Package readonly implements a read-only engine that reads its data from an io.ReaderAt.
+// It allows to open a database embedded in a binary, or stored in any random access medium,
+// without copying it to disk first.
+//
+// The data must have been written using the Write function. The layout is the following:
+//
+//   for each store, the key-value pairs sorted by key:
+//     uvarint key length | key | uvarint value length | value
+//   followed by the offset table of the store: one uint64 per pair,
+//   containing the position of the pair from the beginning of the data.
+//
+//   the directory, for each store:
+//     uvarint name length | name | uint64 position of the offset table | uint64 number of pairs
+//
+//   the footer: uint64 position of the directory | magic
+//
+// All the uint64 are encoded in big endian.
+package readonly
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// magic is written at the end of the data to identify the format.
+var magic = []byte("genjiro1")
+
+// footerSize is the size of the directory position followed by the magic.
+const footerSize = 8 + 8
+
+var (
+	// ErrReadOnly is returned when attempting to open a read/write transaction.
+	ErrReadOnly = errors.New("engine is read-only")
+
+	// ErrInvalidFormat is returned when the data is not in the expected format.
+	ErrInvalidFormat = errors.New("invalid read-only format")
+)
+
+// Engine is a read-only engine reading the stores from an io.ReaderAt.
+// Only the directory of stores is loaded in memory when the engine is created,
+// keys and values are read on demand.
+// It allows multiple concurrent readers.
+type Engine struct {
+	r      *io.SectionReader
+	stores map[string]*storeInfo
+
+	closed bool
+	mu     sync.RWMutex
+}
+
+// storeInfo describes where the pairs of a store are located.
+type storeInfo struct {
+	// position of the offset table
+	offset int64
+	// number of key-value pairs
+	count int
+}
+
+// NewEngine creates a read-only engine reading the first size bytes of r.
+// r is not closed by the engine and must remain valid until the engine is closed.
+func NewEngine(r io.ReaderAt, size int64) (*Engine, error) {
+	if size < footerSize {
+		return nil, ErrInvalidFormat
+	}
+
+	ng := Engine{
+		r:      io.NewSectionReader(r, 0, size),
+		stores: make(map[string]*storeInfo),
+	}
+
+	footer := make([]byte, footerSize)
+	_, err := ng.r.ReadAt(footer, size-footerSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(footer[8:]) != string(magic) {
+		return nil, ErrInvalidFormat
+	}
+
+	dirOffset := int64(binary.BigEndian.Uint64(footer))
+	if dirOffset < 0 || dirOffset > size-footerSize {
+		return nil, ErrInvalidFormat
+	}
+
+	dir := make([]byte, size-footerSize-dirOffset)
+	_, err = ng.r.ReadAt(dir, dirOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	for len(dir) > 0 {
+		l, n := binary.Uvarint(dir)
+		if n <= 0 || uint64(len(dir)-n) < l+16 {
+			return nil, ErrInvalidFormat
+		}
+		dir = dir[n:]
+
+		name := string(dir[:l])
+		dir = dir[l:]
+
+		ng.stores[name] = &storeInfo{
+			offset: int64(binary.BigEndian.Uint64(dir)),
+			count:  int(binary.BigEndian.Uint64(dir[8:])),
+		}
+		dir = dir[16:]
+	}
+
+	return &ng, nil
+}
+
+// Begin creates a read-only transaction.
+// It returns ErrReadOnly if a read/write transaction is requested.
+func (ng *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if opts.Writable {
+		return nil, ErrReadOnly
+	}
+
+	ng.mu.RLock()
+	defer ng.mu.RUnlock()
+
+	if ng.closed {
+		return nil, errors.New("engine closed")
+	}
+
+	return &transaction{ctx: ctx, ng: ng}, nil
+}
+
+// Close the engine. It doesn't close the underlying reader.
+func (ng *Engine) Close() error {
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+	if ng.closed {
+		return errors.New("engine already closed")
+	}
+
+	ng.closed = true
+	return nil
+}
+
+// readUvarint reads a uvarint at the given position and returns it
+// along with the number of bytes read.
+func (ng *Engine) readUvarint(off int64) (uint64, int, error) {
+	var buf [binary.MaxVarintLen64]byte
+
+	n, err := ng.r.ReadAt(buf[:], off)
+	if n == 0 && err != nil {
+		return 0, 0, err
+	}
+
+	v, m := binary.Uvarint(buf[:n])
+	if m <= 0 {
+		return 0, 0, ErrInvalidFormat
+	}
+
+	return v, m, nil
+}
+
+// This implements the engine.Transaction type.
+type transaction struct {
+	ctx context.Context
+	ng  *Engine
+}
+
+// Rollback does nothing since the transaction is read-only.
+func (tx *transaction) Rollback() error {
+	select {
+	case <-tx.ctx.Done():
+		return tx.ctx.Err()
+	default:
+	}
+
+	return nil
+}
+
+// Commit always returns engine.ErrTransactionReadOnly.
+func (tx *transaction) Commit() error {
+	return engine.ErrTransactionReadOnly
+}
+
+func (tx *transaction) GetStore(name []byte) (engine.Store, error) {
+	select {
+	case <-tx.ctx.Done():
+		return nil, tx.ctx.Err()
+	default:
+	}
+
+	info, ok := tx.ng.stores[string(name)]
+	if !ok {
+		return nil, engine.ErrStoreNotFound
+	}
+
+	return &store{tx: tx, info: info}, nil
+}
+
+func (tx *transaction) CreateStore(name []byte) error {
+	return engine.ErrTransactionReadOnly
+}
+
+func (tx *transaction) DropStore(name []byte) error {
+	return engine.ErrTransactionReadOnly
+}
@@ -0,0 +1,127 @@
+package readonly_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/genjidb/genji/engine/readonly"
+	"github.com/stretchr/testify/require"
+)
+
+func build(t *testing.T, n int) *readonly.Engine {
+	t.Helper()
+
+	ng := memoryengine.NewEngine()
+	defer ng.Close()
+
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	require.NoError(t, tx.CreateStore([]byte("a")))
+	require.NoError(t, tx.CreateStore([]byte("empty")))
+	st, err := tx.GetStore([]byte("a"))
+	require.NoError(t, err)
+
+	for i := 0; i < n; i++ {
+		err = st.Put([]byte(fmt.Sprintf("k%02d", i*2)), []byte(fmt.Sprintf("v%d", i*2)))
+		require.NoError(t, err)
+	}
+
+	var buf bytes.Buffer
+	err = readonly.Write(&buf, tx, [][]byte{[]byte("a"), []byte("empty"), []byte("missing")})
+	require.NoError(t, err)
+
+	ro, err := readonly.NewEngine(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return ro
+}
+
+func keys(it engine.Iterator, pivot []byte) []string {
+	var ks []string
+	for it.Seek(pivot); it.Valid(); it.Next() {
+		ks = append(ks, string(it.Item().Key()))
+	}
+	return ks
+}
+
+func TestEngine(t *testing.T) {
+	ng := build(t, 5)
+	defer ng.Close()
+
+	_, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	require.Equal(t, readonly.ErrReadOnly, err)
+
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	_, err = tx.GetStore([]byte("missing"))
+	require.Equal(t, engine.ErrStoreNotFound, err)
+	require.Equal(t, engine.ErrTransactionReadOnly, tx.CreateStore([]byte("b")))
+	require.Equal(t, engine.ErrTransactionReadOnly, tx.DropStore([]byte("a")))
+
+	st, err := tx.GetStore([]byte("empty"))
+	require.NoError(t, err)
+	require.Empty(t, keys(st.Iterator(engine.IteratorOptions{}), nil))
+
+	st, err = tx.GetStore([]byte("a"))
+	require.NoError(t, err)
+
+	t.Run("Get", func(t *testing.T) {
+		v, err := st.Get([]byte("k04"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("v4"), v)
+
+		_, err = st.Get([]byte("k03"))
+		require.Equal(t, engine.ErrKeyNotFound, err)
+		_, err = st.Get([]byte("k99"))
+		require.Equal(t, engine.ErrKeyNotFound, err)
+	})
+
+	t.Run("Writes", func(t *testing.T) {
+		require.Equal(t, engine.ErrTransactionReadOnly, st.Put([]byte("k"), []byte("v")))
+		require.Equal(t, engine.ErrTransactionReadOnly, st.Delete([]byte("k00")))
+		require.Equal(t, engine.ErrTransactionReadOnly, st.Truncate())
+		_, err := st.NextSequence()
+		require.Equal(t, engine.ErrTransactionReadOnly, err)
+	})
+
+	t.Run("Iterator", func(t *testing.T) {
+		it := st.Iterator(engine.IteratorOptions{})
+		defer it.Close()
+
+		require.Equal(t, []string{"k00", "k02", "k04", "k06", "k08"}, keys(it, nil))
+		require.Equal(t, []string{"k04", "k06", "k08"}, keys(it, []byte("k03")))
+		require.Equal(t, []string{"k04", "k06", "k08"}, keys(it, []byte("k04")))
+		require.Empty(t, keys(it, []byte("k09")))
+
+		it.Seek([]byte("k06"))
+		v, err := it.Item().ValueCopy(nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v6"), v)
+	})
+
+	t.Run("Reverse iterator", func(t *testing.T) {
+		it := st.Iterator(engine.IteratorOptions{Reverse: true})
+		defer it.Close()
+
+		require.Equal(t, []string{"k08", "k06", "k04", "k02", "k00"}, keys(it, nil))
+		require.Equal(t, []string{"k02", "k00"}, keys(it, []byte("k03")))
+		require.Equal(t, []string{"k04", "k02", "k00"}, keys(it, []byte("k04")))
+		require.Empty(t, keys(it, []byte("a")))
+	})
+}
+
+func TestNewEngineInvalidFormat(t *testing.T) {
+	_, err := readonly.NewEngine(bytes.NewReader(nil), 0)
+	require.Equal(t, readonly.ErrInvalidFormat, err)
+
+	data := []byte("not a genji read-only database")
+	_, err = readonly.NewEngine(bytes.NewReader(data), int64(len(data)))
+	require.Equal(t, readonly.ErrInvalidFormat, err)
+}
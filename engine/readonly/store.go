@@ -0,0 +1,225 @@
+package readonly
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// item implements an engine.Item.
+// The value is read from the engine when copied.
+type item struct {
+	ng       *Engine
+	k        []byte
+	valueOff int64
+	valueLen int
+}
+
+func (i *item) Key() []byte {
+	return i.k
+}
+
+func (i *item) ValueCopy(buf []byte) ([]byte, error) {
+	if len(buf) < i.valueLen {
+		buf = make([]byte, i.valueLen)
+	}
+	buf = buf[:i.valueLen]
+
+	_, err := i.ng.r.ReadAt(buf, i.valueOff)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// store implements an engine.Store.
+type store struct {
+	tx   *transaction
+	info *storeInfo
+}
+
+// readItem reads the i-th key-value pair of the store.
+func (s *store) readItem(i int) (*item, error) {
+	ng := s.tx.ng
+
+	var buf [8]byte
+	_, err := ng.r.ReadAt(buf[:], s.info.offset+int64(i)*8)
+	if err != nil {
+		return nil, err
+	}
+	off := int64(binary.BigEndian.Uint64(buf[:]))
+
+	kl, n, err := ng.readUvarint(off)
+	if err != nil {
+		return nil, err
+	}
+	off += int64(n)
+
+	k := make([]byte, kl)
+	_, err = ng.r.ReadAt(k, off)
+	if err != nil {
+		return nil, err
+	}
+	off += int64(kl)
+
+	vl, n, err := ng.readUvarint(off)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item{
+		ng:       ng,
+		k:        k,
+		valueOff: off + int64(n),
+		valueLen: int(vl),
+	}, nil
+}
+
+// search returns the position of the first pair whose key is greater than k,
+// or equal to k if inclusive is true.
+func (s *store) search(k []byte, inclusive bool) (int, error) {
+	var err error
+
+	i := sort.Search(s.info.count, func(i int) bool {
+		if err != nil {
+			return true
+		}
+
+		var it *item
+		it, err = s.readItem(i)
+		if err != nil {
+			return true
+		}
+
+		cmp := bytes.Compare(it.k, k)
+		if inclusive {
+			return cmp >= 0
+		}
+		return cmp > 0
+	})
+
+	return i, err
+}
+
+func (s *store) Get(k []byte) ([]byte, error) {
+	select {
+	case <-s.tx.ctx.Done():
+		return nil, s.tx.ctx.Err()
+	default:
+	}
+
+	i, err := s.search(k, true)
+	if err != nil {
+		return nil, err
+	}
+	if i == s.info.count {
+		return nil, engine.ErrKeyNotFound
+	}
+
+	it, err := s.readItem(i)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(it.k, k) {
+		return nil, engine.ErrKeyNotFound
+	}
+
+	return it.ValueCopy(nil)
+}
+
+func (s *store) Put(k, v []byte) error {
+	return engine.ErrTransactionReadOnly
+}
+
+func (s *store) Delete(k []byte) error {
+	return engine.ErrTransactionReadOnly
+}
+
+func (s *store) Truncate() error {
+	return engine.ErrTransactionReadOnly
+}
+
+func (s *store) NextSequence() (uint64, error) {
+	return 0, engine.ErrTransactionReadOnly
+}
+
+// Iterator creates an iterator with the given options.
+func (s *store) Iterator(opts engine.IteratorOptions) engine.Iterator {
+	return &iterator{
+		s:       s,
+		reverse: opts.Reverse,
+	}
+}
+
+// iterator reads the pairs of the store on demand.
+type iterator struct {
+	s       *store
+	reverse bool
+	pos     int
+	item    *item // current item
+	err     error
+}
+
+func (it *iterator) Seek(pivot []byte) {
+	it.err = nil
+
+	switch {
+	case !it.reverse:
+		it.pos, it.err = it.s.search(pivot, true)
+	case len(pivot) == 0:
+		it.pos = it.s.info.count - 1
+	default:
+		// move to the last key lower than or equal to the pivot
+		it.pos, it.err = it.s.search(pivot, false)
+		it.pos--
+	}
+
+	it.read()
+}
+
+func (it *iterator) Next() {
+	if it.reverse {
+		it.pos--
+	} else {
+		it.pos++
+	}
+
+	it.read()
+}
+
+// read loads the item at the current position.
+func (it *iterator) read() {
+	it.item = nil
+
+	if it.err != nil || it.pos < 0 || it.pos >= it.s.info.count {
+		return
+	}
+
+	select {
+	case <-it.s.tx.ctx.Done():
+		it.err = it.s.tx.ctx.Err()
+		return
+	default:
+	}
+
+	it.item, it.err = it.s.readItem(it.pos)
+}
+
+func (it *iterator) Valid() bool {
+	return it.item != nil && it.err == nil
+}
+
+func (it *iterator) Err() error {
+	return it.err
+}
+
+func (it *iterator) Item() engine.Item {
+	return it.item
+}
+
+func (it *iterator) Close() error {
+	return nil
+}
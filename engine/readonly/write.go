@@ -0,0 +1,110 @@
+package readonly
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// Write reads the given stores using tx and writes them to w in the format expected by NewEngine.
+// Stores that don't exist are ignored.
+func Write(w io.Writer, tx engine.Transaction, storeNames [][]byte) error {
+	cw := countWriter{w: bufio.NewWriter(w)}
+
+	type dirEntry struct {
+		name   []byte
+		offset int64
+		count  int
+	}
+	var dir []dirEntry
+	var offsets []int64
+	var buf []byte
+
+	for _, name := range storeNames {
+		st, err := tx.GetStore(name)
+		if err == engine.ErrStoreNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		offsets = offsets[:0]
+		it := st.Iterator(engine.IteratorOptions{})
+		for it.Seek(nil); it.Valid(); it.Next() {
+			item := it.Item()
+			buf, err = item.ValueCopy(buf)
+			if err != nil {
+				it.Close()
+				return err
+			}
+
+			offsets = append(offsets, cw.n)
+			cw.writeBytes(item.Key())
+			cw.writeBytes(buf)
+		}
+		err = it.Err()
+		it.Close()
+		if err != nil {
+			return err
+		}
+
+		entry := dirEntry{name: name, offset: cw.n, count: len(offsets)}
+		for _, off := range offsets {
+			cw.writeUint64(uint64(off))
+		}
+
+		dir = append(dir, entry)
+	}
+
+	dirOffset := cw.n
+	for _, entry := range dir {
+		cw.writeBytes(entry.name)
+		cw.writeUint64(uint64(entry.offset))
+		cw.writeUint64(uint64(entry.count))
+	}
+
+	cw.writeUint64(uint64(dirOffset))
+	cw.write(magic)
+	if cw.err != nil {
+		return cw.err
+	}
+
+	return cw.w.Flush()
+}
+
+// countWriter counts the bytes written and keeps the first error
+// encountered, in which case subsequent writes are ignored.
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countWriter) write(p []byte) {
+	if c.err != nil {
+		return
+	}
+
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+}
+
+// writeBytes writes p prefixed by its length.
+func (c *countWriter) writeBytes(p []byte) {
+	var buf [binary.MaxVarintLen64]byte
+
+	n := binary.PutUvarint(buf[:], uint64(len(p)))
+	c.write(buf[:n])
+	c.write(p)
+}
+
+func (c *countWriter) writeUint64(x uint64) {
+	var buf [8]byte
+
+	binary.BigEndian.PutUint64(buf[:], x)
+	c.write(buf[:])
+}
@@ -0,0 +1,77 @@
+package genji
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// An ExecOption configures the execution of a single query.
+// Options are passed along with the arguments of the query to the Exec, Query,
+// QueryDocument, ExecStatement and QueryStatement methods of DB.
+// They are not used as parameters of the query.
+type ExecOption func(o *execOptions) error
+
+type execOptions struct {
+	timeout       time.Duration
+	watchInterval time.Duration
+}
+
+// QueryTimeout runs the query with a context derived from the context of the database,
+// which is canceled after d. It doesn't affect the database or the other queries.
+// If the query doesn't finish in time, it returns context.DeadlineExceeded.
+// For queries returning documents, the timeout applies until the result is closed.
+// d must be positive.
+func QueryTimeout(d time.Duration) ExecOption {
+	return func(o *execOptions) error {
+		if d <= 0 {
+			return errors.New("query timeout must be positive")
+		}
+
+		o.timeout = d
+		return nil
+	}
+}
+
+// splitExecOptions separates the options from the arguments of a query.
+// It returns nil options if there are none.
+func splitExecOptions(args []interface{}) (*execOptions, []interface{}, error) {
+	n := 0
+	for _, arg := range args {
+		if _, ok := arg.(ExecOption); ok {
+			n++
+		}
+	}
+	if n == 0 {
+		return nil, args, nil
+	}
+
+	var opts execOptions
+	params := make([]interface{}, 0, len(args)-n)
+	for _, arg := range args {
+		opt, ok := arg.(ExecOption)
+		if !ok {
+			params = append(params, arg)
+			continue
+		}
+
+		err := opt(&opts)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &opts, params, nil
+}
+
+// context returns the context used to run a query
+// and the function to call once it is done.
+func (o *execOptions) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o == nil || o.timeout == 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, o.timeout)
+}
+
+var errTxExecOptions = errors.New("exec options cannot be used within a transaction")
@@ -0,0 +1,91 @@
+package genji_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryTimeout(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Update(func(tx *genji.Tx) error {
+		err := tx.Exec("CREATE TABLE test")
+		if err != nil {
+			return err
+		}
+
+		tb, err := tx.GetTable("test")
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < 100000; i++ {
+			_, err = tb.Insert(document.NewFieldBuffer().Add("a", document.NewIntegerValue(int64(i))))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	t.Run("Exceeded", func(t *testing.T) {
+		res, err := db.Query("SELECT a FROM test ORDER BY a DESC", genji.QueryTimeout(time.Millisecond))
+		if err == nil {
+			err = res.Iterate(func(d document.Document) error {
+				return nil
+			})
+			res.Close()
+		}
+		require.True(t, errors.Is(err, context.DeadlineExceeded), err)
+
+		err = db.Exec("DELETE FROM test", genji.QueryTimeout(time.Millisecond))
+		require.True(t, errors.Is(err, context.DeadlineExceeded), err)
+
+		// the deletion was rolled back and the database is still usable
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM test")
+		require.NoError(t, err)
+		v, err := d.GetByField("n")
+		require.NoError(t, err)
+		require.EqualValues(t, 100000, v.V)
+	})
+
+	t.Run("In time", func(t *testing.T) {
+		d, err := db.QueryDocument("SELECT a FROM test WHERE a = ?", genji.QueryTimeout(5*time.Second), 10)
+		require.NoError(t, err)
+		v, err := d.GetByField("a")
+		require.NoError(t, err)
+		require.EqualValues(t, 10, v.V)
+	})
+
+	t.Run("Caller context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cdb := db.WithContext(ctx)
+
+		err := cdb.Exec("SELECT * FROM test WHERE a = 1", genji.QueryTimeout(time.Nanosecond))
+		require.Error(t, err)
+		require.NoError(t, ctx.Err())
+
+		err = cdb.Exec("SELECT * FROM test WHERE a = 1")
+		require.NoError(t, err)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		err := db.Exec("SELECT * FROM test", genji.QueryTimeout(0))
+		require.Error(t, err)
+
+		err = db.View(func(tx *genji.Tx) error {
+			return tx.Exec("SELECT * FROM test", genji.QueryTimeout(time.Second))
+		})
+		require.Error(t, err)
+	})
+}
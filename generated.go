@@ -0,0 +1,29 @@
+package genji
+
+import (
+	"sync"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// generatedExprs caches the parsed expressions of the generated fields, by SQL text.
+var generatedExprs sync.Map
+
+// evalGeneratedField evaluates the expression of a generated field against d
+// within the transaction that writes the document.
+func evalGeneratedField(tx *database.Transaction, fc *database.FieldConstraint, d document.Document) (document.Value, error) {
+	e, ok := generatedExprs.Load(fc.GeneratedExpr)
+	if !ok {
+		pe, err := parser.ParseExprString(fc.GeneratedExpr)
+		if err != nil {
+			return document.Value{}, err
+		}
+
+		e, _ = generatedExprs.LoadOrStore(fc.GeneratedExpr, pe)
+	}
+
+	return e.(expr.Expr).Eval(expr.EvalStack{Tx: tx, Document: d})
+}
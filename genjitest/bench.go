@@ -0,0 +1,112 @@
+package genjitest
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/parser"
+)
+
+// A Benchmark runs queries in benchmarks and reports their throughput and latency.
+// The zero value is ready to use.
+type Benchmark struct {
+	// Latencies of every query execution of the last call to RunQuery, sorted.
+	latencies []time.Duration
+}
+
+// RunQuery parses q once and executes it n times per benchmark iteration, reading all
+// the returned documents. The time and memory spent before the call are not measured.
+// The following metrics are reported, per query execution:
+//
+//	rows/s     documents returned per second
+//	ns/op      average latency
+//	allocs/op  average number of allocations
+//	p50-ns     50th percentile latency
+//	p95-ns     95th percentile latency
+//	p99-ns     99th percentile latency
+//
+// q must contain a single statement. RunQuery stops the benchmark if it fails.
+func (bm *Benchmark) RunQuery(b *testing.B, db *genji.DB, q string, params []interface{}, n int) {
+	b.Helper()
+
+	if n < 1 {
+		b.Fatalf("invalid number of executions %d", n)
+	}
+
+	pq, err := parser.ParseQuery(q)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if len(pq.Statements) != 1 {
+		b.Fatalf("expected a single statement, got %d", len(pq.Statements))
+	}
+	stmt := pq.Statements[0]
+
+	total := b.N * n
+	bm.latencies = make([]time.Duration, total)
+	var rows int
+	count := func(d document.Document) error {
+		rows++
+		return nil
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	b.ResetTimer()
+	begin := time.Now()
+	for i := 0; i < total; i++ {
+		start := time.Now()
+
+		res, err := db.QueryStatement(stmt, params...)
+		if err == nil {
+			err = res.Iterate(count)
+			if cerr := res.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		bm.latencies[i] = time.Since(start)
+	}
+	elapsed := time.Since(begin)
+	b.StopTimer()
+
+	runtime.ReadMemStats(&after)
+
+	sort.Slice(bm.latencies, func(i, j int) bool { return bm.latencies[i] < bm.latencies[j] })
+
+	b.ReportMetric(float64(rows)/elapsed.Seconds(), "rows/s")
+	b.ReportMetric(float64(elapsed.Nanoseconds())/float64(total), "ns/op")
+	b.ReportMetric(float64(after.Mallocs-before.Mallocs)/float64(total), "allocs/op")
+	b.ReportMetric(float64(bm.Percentile(50)), "p50-ns")
+	b.ReportMetric(float64(bm.Percentile(95)), "p95-ns")
+	b.ReportMetric(float64(bm.Percentile(99)), "p99-ns")
+}
+
+// Percentile returns the latency under which p percent of the query executions
+// of the last call to RunQuery completed. It returns 0 if no query was run.
+func (bm *Benchmark) Percentile(p float64) time.Duration {
+	if len(bm.latencies) == 0 {
+		return 0
+	}
+
+	// nearest-rank method
+	i := int(math.Ceil(p/100*float64(len(bm.latencies)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(bm.latencies) {
+		i = len(bm.latencies) - 1
+	}
+
+	return bm.latencies[i]
+}
@@ -0,0 +1,74 @@
+package genjitest_test
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/genjitest"
+	"github.com/stretchr/testify/require"
+)
+
+// setBenchtime makes testing.Benchmark run every benchmark for n iterations.
+func setBenchtime(t *testing.T, n string) {
+	f := flag.Lookup("test.benchtime")
+	require.NotNil(t, f)
+
+	old := f.Value.String()
+	require.NoError(t, f.Value.Set(n))
+	t.Cleanup(func() {
+		f.Value.Set(old)
+	})
+}
+
+func TestBenchmarkRunQuery(t *testing.T) {
+	setBenchtime(t, "10x")
+
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		INSERT INTO test (a) VALUES (1), (2), (3);
+	`)
+	require.NoError(t, err)
+
+	var bm genjitest.Benchmark
+	var sink [][]byte
+	r := testing.Benchmark(func(b *testing.B) {
+		// the setup must not be measured
+		time.Sleep(50 * time.Millisecond)
+		for i := 0; i < 10000; i++ {
+			sink = append(sink, make([]byte, 8))
+		}
+
+		bm.RunQuery(b, db, "SELECT * FROM test WHERE a > ?", []interface{}{1}, 5)
+	})
+	require.NotEmpty(t, sink)
+
+	require.Equal(t, 10, r.N)
+	require.Less(t, int64(r.T), int64(50*time.Millisecond))
+	require.Less(t, r.MemAllocs, uint64(10000))
+
+	require.Greater(t, r.Extra["rows/s"], 0.0)
+	require.Greater(t, r.Extra["ns/op"], 0.0)
+	require.InDelta(t, float64(r.T.Nanoseconds())/50, r.Extra["ns/op"], r.Extra["ns/op"]/2)
+
+	// the statement is parsed once, the allocations are those of its executions
+	require.Greater(t, r.Extra["allocs/op"], 0.0)
+	require.InDelta(t, float64(r.MemAllocs)/50, r.Extra["allocs/op"], 1)
+
+	p50, p95, p99 := r.Extra["p50-ns"], r.Extra["p95-ns"], r.Extra["p99-ns"]
+	require.Greater(t, p50, 0.0)
+	require.LessOrEqual(t, p50, p95)
+	require.LessOrEqual(t, p95, p99)
+	require.Equal(t, float64(bm.Percentile(99)), p99)
+	require.Equal(t, float64(bm.Percentile(100)), p99)
+}
+
+func TestBenchmarkPercentile(t *testing.T) {
+	var bm genjitest.Benchmark
+	require.Zero(t, bm.Percentile(50))
+}
@@ -0,0 +1,68 @@
+// Package genjitest provides utilities to test code that uses Genji.
+package genjitest
+
+import (
+	"sync"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query"
+)
+
+// A QueryRecorder wraps a database and records the SQL of every query
+// run using its Exec, Query and QueryDocument methods, before running them.
+// Queries run in transactions created with Begin, View or Update are not recorded.
+// It is safe for concurrent use.
+type QueryRecorder struct {
+	*genji.DB
+
+	mu         sync.Mutex
+	statements []string
+}
+
+// NewQueryRecorder creates a QueryRecorder that runs queries on db.
+func NewQueryRecorder(db *genji.DB) *QueryRecorder {
+	return &QueryRecorder{DB: db}
+}
+
+// Exec records q and runs it on the database without returning the result.
+func (r *QueryRecorder) Exec(q string, args ...interface{}) error {
+	r.record(q)
+	return r.DB.Exec(q, args...)
+}
+
+// Query records q, runs it on the database and returns the result.
+// The returned result must always be closed after usage.
+func (r *QueryRecorder) Query(q string, args ...interface{}) (*query.Result, error) {
+	r.record(q)
+	return r.DB.Query(q, args...)
+}
+
+// QueryDocument records q, runs it on the database and returns the first document.
+func (r *QueryRecorder) QueryDocument(q string, args ...interface{}) (document.Document, error) {
+	r.record(q)
+	return r.DB.QueryDocument(q, args...)
+}
+
+// Statements returns the recorded queries, in the order they were run.
+func (r *QueryRecorder) Statements() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.statements...)
+}
+
+// Reset clears the recorded queries.
+func (r *QueryRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.statements = nil
+}
+
+func (r *QueryRecorder) record(q string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.statements = append(r.statements, q)
+}
@@ -0,0 +1,47 @@
+package genjitest_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/genjitest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRecorder(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	r := genjitest.NewQueryRecorder(db)
+	require.Empty(t, r.Statements())
+
+	err = r.Exec("CREATE TABLE test")
+	require.NoError(t, err)
+	err = r.Exec("INSERT INTO test (a) VALUES (?)", 1)
+	require.NoError(t, err)
+	res, err := r.Query("SELECT * FROM test")
+	require.NoError(t, err)
+	require.NoError(t, res.Close())
+	_, err = r.QueryDocument("SELECT a FROM test WHERE a = ?", 1)
+	require.NoError(t, err)
+
+	// failing queries are recorded as well.
+	err = r.Exec("SELECT * FROM unknown")
+	require.Error(t, err)
+
+	require.Equal(t, []string{
+		"CREATE TABLE test",
+		"INSERT INTO test (a) VALUES (?)",
+		"SELECT * FROM test",
+		"SELECT a FROM test WHERE a = ?",
+		"SELECT * FROM unknown",
+	}, r.Statements())
+
+	r.Reset()
+	require.Empty(t, r.Statements())
+
+	err = r.Exec("DELETE FROM test")
+	require.NoError(t, err)
+	require.Equal(t, []string{"DELETE FROM test"}, r.Statements())
+}
@@ -0,0 +1,50 @@
+package genji
+
+import (
+	"github.com/genjidb/genji/database"
+)
+
+// A TransactionHook is a set of functions called at the steps of the lifecycle of
+// read/write transactions, including the ones started by Exec and Query.
+// Any of them can be nil.
+// Panics in the functions are recovered: a panic in BeforeCommit aborts the transaction
+// like an error does, and panics and errors in the other functions are logged
+// with the standard logger.
+type TransactionHook struct {
+	// BeforeCommit is called before committing the transaction, which can still be used.
+	// If it returns an error, the transaction is rolled back and Commit returns that error.
+	BeforeCommit func(tx *Tx) error
+	// AfterCommit is called once the transaction is committed with a read-only transaction
+	// started right after the commit. Its context carries the values of the context
+	// of the committed transaction.
+	AfterCommit func(tx *Tx) error
+	// BeforeRollback is called before rolling back the transaction, which can still be used.
+	BeforeRollback func(tx *Tx) error
+	// AfterRollback is called once the transaction is rolled back, even if a BeforeRollback
+	// hook failed.
+	AfterRollback func(tx *Tx) error
+}
+
+// WithTransactionHook registers hook to be called during the lifecycle of every read/write transaction.
+// Hooks are called in the order they were registered.
+func WithTransactionHook(hook TransactionHook) Option {
+	return func(db *DB) error {
+		db.DB.TransactionHooks = append(db.DB.TransactionHooks, database.TransactionHook{
+			BeforeCommit:   wrapTransactionHook(hook.BeforeCommit),
+			AfterCommit:    wrapTransactionHook(hook.AfterCommit),
+			BeforeRollback: wrapTransactionHook(hook.BeforeRollback),
+			AfterRollback:  wrapTransactionHook(hook.AfterRollback),
+		})
+		return nil
+	}
+}
+
+func wrapTransactionHook(fn func(tx *Tx) error) func(tx *database.Transaction) error {
+	if fn == nil {
+		return nil
+	}
+
+	return func(tx *database.Transaction) error {
+		return fn(&Tx{Transaction: tx})
+	}
+}
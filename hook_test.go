@@ -0,0 +1,167 @@
+package genji_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+type authorKey struct{}
+
+func TestTransactionHook(t *testing.T) {
+	t.Run("BeforeCommit", func(t *testing.T) {
+		errNoAuthor := errors.New("no author")
+		var rollbacks int
+
+		db, err := genji.Open(":memory:", genji.WithTransactionHook(genji.TransactionHook{
+			BeforeCommit: func(tx *genji.Tx) error {
+				if tx.Context().Value(authorKey{}) == nil {
+					return errNoAuthor
+				}
+				return nil
+			},
+			AfterRollback: func(tx *genji.Tx) error {
+				rollbacks++
+				return nil
+			},
+		}))
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test")
+		require.Equal(t, errNoAuthor, err)
+		require.Equal(t, 1, rollbacks)
+
+		adb := db.WithContext(context.WithValue(context.Background(), authorKey{}, "foo"))
+		err = adb.Exec("CREATE TABLE test")
+		require.NoError(t, err)
+
+		err = db.Update(func(tx *genji.Tx) error {
+			return tx.Exec("INSERT INTO test (a) VALUES (1)")
+		})
+		require.Equal(t, errNoAuthor, err)
+
+		err = adb.Update(func(tx *genji.Tx) error {
+			return tx.Exec("INSERT INTO test (a) VALUES (2)")
+		})
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n, MAX(a) AS a FROM test")
+		require.NoError(t, err)
+		data, err := document.MarshalJSON(d)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"n": 1, "a": 2}`, string(data))
+
+		// read-only transactions and rollbacks after commits don't call the hooks
+		require.Equal(t, 2, rollbacks)
+	})
+
+	t.Run("AfterCommit", func(t *testing.T) {
+		ch := make(chan int64, 10)
+
+		db, err := genji.Open(":memory:", genji.WithTransactionHook(genji.TransactionHook{
+			AfterCommit: func(tx *genji.Tx) error {
+				if tx.Writable() {
+					return errors.New("writable transaction")
+				}
+
+				d, err := tx.QueryDocument("SELECT COUNT(*) FROM test")
+				if err != nil {
+					return err
+				}
+
+				var n int64
+				err = document.Scan(d, &n)
+				if err != nil {
+					return err
+				}
+
+				ch <- n
+				return nil
+			},
+		}))
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test; INSERT INTO test (a) VALUES (1)")
+		require.NoError(t, err)
+
+		err = db.Update(func(tx *genji.Tx) error {
+			return tx.Exec("INSERT INTO test (a) VALUES (2), (3)")
+		})
+		require.NoError(t, err)
+
+		err = db.Exec("BEGIN; INSERT INTO test (a) VALUES (4); ROLLBACK")
+		require.NoError(t, err)
+
+		close(ch)
+		var counts []int64
+		for n := range ch {
+			counts = append(counts, n)
+		}
+		// the statements of Exec are committed one by one, and rollbacks don't call the hook
+		require.Equal(t, []int64{0, 1, 3}, counts)
+	})
+
+	t.Run("Panics", func(t *testing.T) {
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		defer log.SetOutput(os.Stderr)
+
+		var afterRollback, afterCommit int
+
+		db, err := genji.Open(":memory:",
+			genji.WithTransactionHook(genji.TransactionHook{
+				AfterCommit: func(tx *genji.Tx) error {
+					panic("after commit")
+				},
+				BeforeRollback: func(tx *genji.Tx) error {
+					panic("before rollback")
+				},
+				AfterRollback: func(tx *genji.Tx) error {
+					panic("after rollback")
+				},
+			}),
+			genji.WithTransactionHook(genji.TransactionHook{
+				AfterCommit: func(tx *genji.Tx) error {
+					afterCommit++
+					return nil
+				},
+				AfterRollback: func(tx *genji.Tx) error {
+					afterRollback++
+					return nil
+				},
+			}),
+		)
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test")
+		require.NoError(t, err)
+		require.Equal(t, 1, afterCommit)
+		require.Contains(t, buf.String(), "AfterCommit hook: panic: after commit")
+
+		tx, err := db.Begin(true)
+		require.NoError(t, err)
+		err = tx.Exec("INSERT INTO test (a) VALUES (1)")
+		require.NoError(t, err)
+		err = tx.Rollback()
+		require.NoError(t, err)
+		require.Equal(t, 1, afterRollback)
+		require.Contains(t, buf.String(), "BeforeRollback hook: panic: before rollback")
+		require.Contains(t, buf.String(), "AfterRollback hook: panic: after rollback")
+
+		d, err := db.QueryDocument("SELECT COUNT(*) FROM test")
+		require.NoError(t, err)
+		v, err := d.GetByField("COUNT(*)")
+		require.NoError(t, err)
+		require.Equal(t, document.NewIntegerValue(0), v)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/engine"
@@ -61,6 +62,11 @@ func New(tx engine.Transaction, idxName string, opts Options) *Index {
 
 var errStop = errors.New("stop")
 
+// StoreName returns the name of the store containing the index data.
+func (idx *Index) StoreName() []byte {
+	return idx.storeName
+}
+
 // Set associates a value with a key. If Unique is set to false, it is
 // possible to associate multiple keys for the same value
 // but a key can be associated to only one value.
@@ -125,6 +131,110 @@ func (idx *Index) Set(v document.Value, k []byte) error {
 	return st.Put(buf, k)
 }
 
+// A Batch buffers the entries of an index and writes them in sorted order
+// when flushed, which is faster than calling Set for each value.
+// Entries that are not flushed yet are taken into account by the uniqueness checks of the batch.
+type Batch struct {
+	idx     *Index
+	st      engine.Store
+	entries []batchEntry
+
+	// encoded values set by the batch, in the form used to detect duplicates
+	pending map[string]struct{}
+}
+
+type batchEntry struct {
+	k, v []byte
+}
+
+// NewBatch returns a batch of entries to write to the index.
+func (idx *Index) NewBatch() (*Batch, error) {
+	st, err := getOrCreateStore(idx.tx, idx.storeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Batch{
+		idx:     idx,
+		st:      st,
+		pending: make(map[string]struct{}),
+	}, nil
+}
+
+// Set associates a value with a key, following the same rules as Index.Set.
+// Uniqueness is checked immediately but the entry is only written by Flush.
+func (b *Batch) Set(v document.Value, k []byte) error {
+	if len(k) == 0 {
+		return errors.New("cannot index value without a key")
+	}
+
+	if b.idx.Type != 0 && b.idx.Type != v.Type {
+		return fmt.Errorf("cannot index value of type %s in %s index", v.Type, b.idx.Type)
+	}
+
+	buf, err := b.idx.EncodeValue(v)
+	if err != nil {
+		return err
+	}
+
+	var lookupKey = buf
+	if !b.idx.Unique {
+		lookupKey = append(lookupKey, 0)
+	}
+
+	_, exists := b.pending[string(lookupKey)]
+	if !exists {
+		_, err = b.st.Get(lookupKey)
+		switch err {
+		case nil:
+			exists = true
+		case engine.ErrKeyNotFound:
+		default:
+			return err
+		}
+	}
+
+	if !exists {
+		b.pending[string(lookupKey)] = struct{}{}
+		buf = lookupKey
+	} else {
+		if b.idx.Unique {
+			return ErrDuplicate
+		}
+
+		seq, err := b.st.NextSequence()
+		if err != nil {
+			return err
+		}
+		vbuf := make([]byte, binary.MaxVarintLen64)
+		n := binary.PutUvarint(vbuf, seq)
+		buf = append(buf, vbuf[:n]...)
+		buf = append(buf, byte(n))
+	}
+
+	b.entries = append(b.entries, batchEntry{k: buf, v: append([]byte(nil), k...)})
+	return nil
+}
+
+// Flush writes the buffered entries to the index, sorted by encoded value,
+// and empties the batch.
+func (b *Batch) Flush() error {
+	sort.Slice(b.entries, func(i, j int) bool {
+		return bytes.Compare(b.entries[i].k, b.entries[j].k) < 0
+	})
+
+	for _, e := range b.entries {
+		err := b.st.Put(e.k, e.v)
+		if err != nil {
+			return err
+		}
+	}
+
+	b.entries = b.entries[:0]
+	b.pending = make(map[string]struct{})
+	return nil
+}
+
 // Delete all the references to the key from the index.
 func (idx *Index) Delete(v document.Value, k []byte) error {
 	st, err := getOrCreateStore(idx.tx, idx.storeName)
@@ -68,6 +68,50 @@ func TestIndexSet(t *testing.T) {
 	})
 }
 
+func TestIndexBatch(t *testing.T) {
+	t.Run("Unique: true, Duplicate in batch", func(t *testing.T) {
+		idx, cleanup := getIndex(t, true)
+		defer cleanup()
+
+		require.NoError(t, idx.Set(document.NewIntegerValue(10), []byte("key1")))
+
+		b, err := idx.NewBatch()
+		require.NoError(t, err)
+		require.NoError(t, b.Set(document.NewIntegerValue(11), []byte("key2")))
+		require.Equal(t, index.ErrDuplicate, b.Set(document.NewIntegerValue(11), []byte("key3")))
+		require.Equal(t, index.ErrDuplicate, b.Set(document.NewIntegerValue(10), []byte("key3")))
+	})
+
+	t.Run("Unique: false, Flush writes sorted entries", func(t *testing.T) {
+		idx, cleanup := getIndex(t, false)
+		defer cleanup()
+
+		require.NoError(t, idx.Set(document.NewIntegerValue(2), []byte("key0")))
+
+		b, err := idx.NewBatch()
+		require.NoError(t, err)
+		require.NoError(t, b.Set(document.NewIntegerValue(3), []byte("key1")))
+		require.NoError(t, b.Set(document.NewIntegerValue(1), []byte("key2")))
+		require.NoError(t, b.Set(document.NewIntegerValue(3), []byte("key3")))
+		require.NoError(t, b.Set(document.NewIntegerValue(2), []byte("key4")))
+
+		// nothing is written before Flush
+		var keys []string
+		collect := func(_, k []byte, _ bool) error {
+			keys = append(keys, string(k))
+			return nil
+		}
+		require.NoError(t, idx.AscendGreaterOrEqual(document.Value{}, collect))
+		require.Equal(t, []string{"key0"}, keys)
+
+		require.NoError(t, b.Flush())
+
+		keys = nil
+		require.NoError(t, idx.AscendGreaterOrEqual(document.Value{}, collect))
+		require.Equal(t, []string{"key2", "key0", "key4", "key1", "key3"}, keys)
+	})
+}
+
 func TestIndexDelete(t *testing.T) {
 	t.Run("Unique: false, Delete valid key succeeds", func(t *testing.T) {
 		idx, cleanup := getIndex(t, false)
@@ -273,6 +317,54 @@ func TestIndexAscendGreaterThan(t *testing.T) {
 			require.Equal(t, 10, texts)
 		})
 
+		t.Run(text+"Arrays and documents, should iterate in the order of the comparison operators", func(t *testing.T) {
+			idx, cleanup := getIndex(t, unique)
+			defer cleanup()
+
+			value := func(s string) document.Value {
+				v, err := document.NewFromJSON([]byte(`{"v": ` + s + `}`)).GetByField("v")
+				require.NoError(t, err)
+				return v
+			}
+
+			arrays := []string{`[]`, `[1]`, `[1, 2]`, `[1, "a"]`, `[2]`, `[[1]]`, `[{"a": 1}]`}
+			docs := []string{`{}`, `{"a": 1}`, `{"a": 1, "b": 2}`, `{"a": 2}`, `{"b": 1}`}
+
+			// insert the values in reverse order
+			for _, values := range [][]string{arrays, docs} {
+				for i := len(values) - 1; i >= 0; i-- {
+					require.NoError(t, idx.Set(value(values[i]), []byte(values[i])))
+				}
+			}
+
+			tests := []struct {
+				pivot document.Value
+				want  []string
+			}{
+				{document.Value{Type: document.ArrayValue}, arrays},
+				{document.Value{Type: document.DocumentValue}, docs},
+				{value(`[1, 2]`), arrays[2:]},
+				{value(`{"a": 1, "c": 1}`), docs[3:]},
+			}
+
+			for _, test := range tests {
+				var got []string
+				err := idx.AscendGreaterOrEqual(test.pivot, func(val, rid []byte, isEqual bool) error {
+					requireEqualEncoded(t, value(string(rid)), val)
+					got = append(got, string(rid))
+					return nil
+				})
+				require.NoError(t, err)
+				require.Equal(t, test.want, got)
+
+				for i := 1; i < len(got); i++ {
+					ok, err := value(got[i-1]).IsLesserThan(value(got[i]))
+					require.NoError(t, err)
+					require.True(t, ok, "%s < %s", got[i-1], got[i])
+				}
+			}
+		})
+
 		t.Run(text+"With no pivot and typed index, should iterate over all documents in order", func(t *testing.T) {
 			idx, cleanup := getIndex(t, unique)
 			idx.Type = document.IntegerValue
@@ -0,0 +1,142 @@
+// Package migrate applies versioned schema migrations to a Genji database.
+//
+// The version of the last applied migration is recorded in the internal
+// __genji_migrations store, within the same transaction as the migration itself:
+// a migration that fails is rolled back entirely and its version is not recorded.
+package migrate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/genjidb/genji"
+)
+
+// ErrDatabaseAhead is returned when the version recorded in the database
+// is greater than the version of the last provided migration.
+var ErrDatabaseAhead = errors.New("database version is ahead of the migrations")
+
+// A Migration describes a change of the database schema.
+type Migration struct {
+	// Version of the migration. Versions must be positive
+	// and strictly increasing within a list of migrations.
+	Version int64
+	// Name of the migration, for informational purposes.
+	Name string
+	// Up applies the migration within the given transaction.
+	// If nil, SQL is executed instead.
+	Up func(tx *genji.Tx) error
+	// SQL script applying the migration. It may contain multiple statements
+	// but must not control the transaction.
+	SQL string
+}
+
+// Run applies, in order, every migration whose version is greater than the version
+// recorded in the database. Each migration is applied in its own read-write transaction,
+// which reads the recorded version again before applying it: concurrent calls to Run
+// apply each migration only once.
+// If a migration fails, the previous ones are kept and the error is returned.
+// Run returns ErrDatabaseAhead if the database version is greater than the version
+// of the last migration.
+func Run(db *genji.DB, migrations []Migration) error {
+	err := validate(migrations)
+	if err != nil {
+		return err
+	}
+
+	for i := range migrations {
+		m := migrations[i]
+		err = db.Update(func(tx *genji.Tx) error {
+			pending, err := pending(tx, migrations)
+			if err != nil {
+				return err
+			}
+			// another call to Run already applied it.
+			if len(pending) == 0 || pending[0].Version > m.Version {
+				return nil
+			}
+
+			err = apply(tx, m)
+			if err != nil {
+				return fmt.Errorf("migration %d failed: %w", m.Version, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Pending returns the migrations that Run would apply, without applying them.
+// It can be used to list the changes before running them.
+func Pending(db *genji.DB, migrations []Migration) ([]Migration, error) {
+	err := validate(migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []Migration
+	err = db.View(func(tx *genji.Tx) error {
+		ms, err = pending(tx, migrations)
+		return err
+	})
+	return ms, err
+}
+
+// pending returns the migrations whose version is greater than the version
+// recorded in the database, as seen by tx.
+func pending(tx *genji.Tx, migrations []Migration) ([]Migration, error) {
+	version, err := tx.MigrationVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	var last int64
+	if len(migrations) > 0 {
+		last = migrations[len(migrations)-1].Version
+	}
+	if version > last {
+		return nil, fmt.Errorf("%w: database is at version %d, last migration is %d", ErrDatabaseAhead, version, last)
+	}
+
+	for i, m := range migrations {
+		if m.Version > version {
+			return migrations[i:], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func validate(migrations []Migration) error {
+	var prev int64
+	for _, m := range migrations {
+		if m.Version <= prev {
+			return fmt.Errorf("invalid migration version %d: versions must be positive and strictly increasing", m.Version)
+		}
+		if m.Up == nil && m.SQL == "" {
+			return fmt.Errorf("migration %d has neither an Up function nor an SQL script", m.Version)
+		}
+
+		prev = m.Version
+	}
+
+	return nil
+}
+
+func apply(tx *genji.Tx, m Migration) error {
+	var err error
+	if m.Up != nil {
+		err = m.Up(tx)
+	} else {
+		err = tx.Exec(m.SQL)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.SetMigrationVersion(m.Version)
+}
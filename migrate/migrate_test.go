@@ -0,0 +1,194 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/genjidb/genji/migrate"
+	"github.com/stretchr/testify/require"
+)
+
+func version(t *testing.T, db *genji.DB) int64 {
+	var v int64
+	err := db.View(func(tx *genji.Tx) error {
+		var err error
+		v, err = tx.MigrationVersion()
+		return err
+	})
+	require.NoError(t, err)
+	return v
+}
+
+func TestRun(t *testing.T) {
+	migrations := []migrate.Migration{
+		{Version: 1, Name: "create foo", SQL: "CREATE TABLE foo; CREATE INDEX idx_foo_a ON foo(a)"},
+		{Version: 2, Name: "insert", Up: func(tx *genji.Tx) error {
+			return tx.Exec("INSERT INTO foo (a) VALUES (1), (2)")
+		}},
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		pending, err := migrate.Pending(db, migrations)
+		require.NoError(t, err)
+		require.Len(t, pending, 2)
+
+		err = migrate.Run(db, migrations)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, version(t, db))
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM foo")
+		require.NoError(t, err)
+		v, err := d.GetByField("n")
+		require.NoError(t, err)
+		require.EqualValues(t, 2, v.V)
+
+		// running them again is a no-op.
+		pending, err = migrate.Pending(db, migrations)
+		require.NoError(t, err)
+		require.Empty(t, pending)
+		require.NoError(t, migrate.Run(db, migrations))
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		failing := append(migrations[:2:2], migrate.Migration{
+			Version: 3,
+			SQL:     "CREATE TABLE bar; INSERT INTO bar (a) VALUES (1); INSERT INTO unknown (a) VALUES (1)",
+		})
+
+		err = migrate.Run(db, failing)
+		require.Error(t, err)
+		require.EqualValues(t, 2, version(t, db))
+
+		// the statements that succeeded before the failure were rolled back.
+		err = db.View(func(tx *genji.Tx) error {
+			_, err := tx.GetTable("bar")
+			return err
+		})
+		require.True(t, errors.Is(err, database.ErrTableNotFound))
+
+		pending, err := migrate.Pending(db, failing)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		require.EqualValues(t, 3, pending[0].Version)
+	})
+
+	t.Run("Ahead", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		require.NoError(t, migrate.Run(db, migrations))
+
+		err = migrate.Run(db, migrations[:1])
+		require.True(t, errors.Is(err, migrate.ErrDatabaseAhead))
+		_, err = migrate.Pending(db, migrations[:1])
+		require.True(t, errors.Is(err, migrate.ErrDatabaseAhead))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = migrate.Run(db, []migrate.Migration{{Version: 2, SQL: "CREATE TABLE a"}, {Version: 1, SQL: "CREATE TABLE b"}})
+		require.Error(t, err)
+		err = migrate.Run(db, []migrate.Migration{{Version: 1}})
+		require.Error(t, err)
+		require.Zero(t, version(t, db))
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		ng := &gatedEngine{Engine: memoryengine.NewEngine()}
+		db, err := genji.New(context.Background(), ng)
+		require.NoError(t, err)
+		defer db.Close()
+
+		var applied int64
+		counted := []migrate.Migration{
+			{Version: 1, Up: func(tx *genji.Tx) error {
+				atomic.AddInt64(&applied, 1)
+				return tx.Exec("CREATE TABLE foo")
+			}},
+		}
+
+		// both calls read the version before any of them applies the migration.
+		ng.hold(2)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = migrate.Run(db, counted)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+		require.EqualValues(t, 1, applied)
+		require.EqualValues(t, 1, version(t, db))
+	})
+}
+
+// gatedEngine holds read-only transactions once they are rolled back,
+// until the given number of them are.
+type gatedEngine struct {
+	engine.Engine
+
+	waiting int32
+	gate    chan struct{}
+}
+
+func (ng *gatedEngine) hold(n int32) {
+	ng.gate = make(chan struct{})
+	atomic.StoreInt32(&ng.waiting, n)
+}
+
+func (ng *gatedEngine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	tx, err := ng.Engine.Begin(ctx, opts)
+	if err != nil || opts.Writable {
+		return tx, err
+	}
+
+	return &gatedTransaction{Transaction: tx, ng: ng}, nil
+}
+
+type gatedTransaction struct {
+	engine.Transaction
+
+	ng *gatedEngine
+}
+
+func (tx *gatedTransaction) Rollback() error {
+	err := tx.Transaction.Rollback()
+
+	switch n := atomic.AddInt32(&tx.ng.waiting, -1); {
+	case n == 0:
+		close(tx.ng.gate)
+	case n > 0:
+		select {
+		case <-tx.ng.gate:
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	return err
+}
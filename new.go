@@ -10,15 +10,32 @@ import (
 	"github.com/genjidb/genji/engine"
 )
 
-// New initializes the DB using the given engine.
-func New(ctx context.Context, ng engine.Engine) (*DB, error) {
+// New initializes the DB using the given engine and applies the given options.
+func New(ctx context.Context, ng engine.Engine, opts ...Option) (*DB, error) {
 	db, err := database.New(ctx, ng, database.Options{Codec: msgpack.NewCodec()})
 	if err != nil {
 		return nil, err
 	}
+	db.RunTrigger = runTrigger
+	db.EvalGeneratedField = evalGeneratedField
+	db.SpillEngine = tempSpillEngine
 
-	return &DB{
+	gdb := DB{
 		DB:  db,
 		ctx: context.Background(),
-	}, nil
+	}
+
+	err = applyOptions(&gdb, opts)
+	if err == nil {
+		err = checkEncryption(ctx, &gdb, ng)
+	}
+	if err == nil {
+		err = db.CheckIndexes(ctx)
+	}
+	if err != nil {
+		gdb.Close()
+		return nil, err
+	}
+
+	return &gdb, nil
 }
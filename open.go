@@ -13,7 +13,8 @@ import (
 // Open creates a Genji database at the given path.
 // If path is equal to ":memory:" it will open an in-memory database,
 // otherwise it will create an on-disk database using the BoltDB engine.
-func Open(path string) (*DB, error) {
+// The given options are passed to New.
+func Open(path string, opts ...Option) (*DB, error) {
 	var ng engine.Engine
 	var err error
 
@@ -28,5 +29,5 @@ func Open(path string) (*DB, error) {
 	}
 
 	ctx := context.Background()
-	return New(ctx, ng)
+	return New(ctx, ng, opts...)
 }
@@ -0,0 +1,93 @@
+package genji
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// An Option configures the database when calling New or Open.
+type Option func(db *DB) error
+
+// WithStrictTypes makes comparisons between values of incompatible types,
+// like a text with an integer, return an error instead of evaluating to false.
+// By default, a document with an unexpected type is simply not matched by a WHERE clause.
+// Documents that are skipped by an index are never compared.
+func WithStrictTypes() Option {
+	return func(db *DB) error {
+		db.DB.StrictTypes = true
+		return nil
+	}
+}
+
+// WithCaseInsensitiveFields makes paths match document fields case-insensitively:
+// a path like Name.First matches a document like {"name": {"FIRST": "foo"}}.
+// If multiple fields of a document match, the first one in field order is used.
+// Documents are always stored with their original field names.
+// Since the setting changes the values stored in the indexes, it is recorded in the database,
+// and the indexes are rebuilt when the database is opened with another setting.
+func WithCaseInsensitiveFields() Option {
+	return func(db *DB) error {
+		db.DB.CaseInsensitiveFields = true
+		return nil
+	}
+}
+
+// WithAutoCreateTables makes INSERT statements create the tables that don't exist,
+// without constraints, in the same transaction. Other statements still fail on missing tables.
+func WithAutoCreateTables() Option {
+	return func(db *DB) error {
+		db.DB.AutoCreateTables = true
+		return nil
+	}
+}
+
+// WithMemoryBudget sets the approximate amount of memory, in bytes, that a statement can use
+// to buffer documents when sorting or removing duplicates. Zero means no limit.
+// Defaults to database.DefaultMemoryBudget.
+// Once the budget is exceeded, the documents are moved to the spill engine, see WithSpillEngine.
+func WithMemoryBudget(n int64) Option {
+	return func(db *DB) error {
+		db.DB.MemoryBudget = n
+		return nil
+	}
+}
+
+// WithSpillEngine configures the function used to create the temporary engines
+// where statements move the documents they buffer once their memory budget is exceeded.
+// By default, the engine is a BoltDB database stored in a temporary file, removed
+// once the statement is done. In WebAssembly, or if fn is nil, statements fail
+// with database.ErrMemoryBudgetExceeded instead.
+func WithSpillEngine(fn func() (engine.Engine, error)) Option {
+	return func(db *DB) error {
+		db.DB.SpillEngine = fn
+		return nil
+	}
+}
+
+// WithTransactionRetry makes Update run the transaction again, up to maxAttempts times in total,
+// when it fails with engine.ErrSerializationFailure. The delay between two attempts starts at backoff
+// and doubles after each attempt. Other errors are returned immediately.
+func WithTransactionRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(db *DB) error {
+		if maxAttempts < 1 {
+			return fmt.Errorf("invalid number of attempts %d", maxAttempts)
+		}
+
+		db.retryAttempts = maxAttempts
+		db.retryBackoff = backoff
+		return nil
+	}
+}
+
+func applyOptions(db *DB, opts []Option) error {
+	for _, opt := range opts {
+		err := opt(db)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package genji
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned by Pool.Acquire once the pool is closed.
+var ErrPoolClosed = errors.New("pool closed")
+
+// A Pool manages a set of databases that can be shared between goroutines.
+// Each database is used by at most one goroutine at a time: it is acquired
+// with Acquire and must be returned to the pool with Release.
+// The fields must not be modified after the first call to Acquire.
+type Pool struct {
+	// Open creates a new database when the pool needs one. It is required.
+	Open func(ctx context.Context) (*DB, error)
+
+	// Min is the number of idle databases that are never closed for inactivity.
+	Min int
+
+	// Max is the maximum number of databases acquired at the same time.
+	// Acquire blocks until one is released. Zero means no limit.
+	Max int
+
+	// Idle is the duration after which an idle database is closed,
+	// unless there are only Min idle databases left. Zero means never.
+	Idle time.Duration
+
+	once     sync.Once
+	tokens   chan struct{}
+	done     chan struct{}
+	reaperWg sync.WaitGroup
+
+	mu       sync.Mutex
+	idle     []idleDB
+	acquired map[*DB]struct{}
+	closed   bool
+}
+
+type idleDB struct {
+	db         *DB
+	releasedAt time.Time
+}
+
+func (p *Pool) init() {
+	p.once.Do(func() {
+		if p.Max > 0 {
+			p.tokens = make(chan struct{}, p.Max)
+		}
+		p.done = make(chan struct{})
+		p.acquired = make(map[*DB]struct{})
+
+		if p.Idle > 0 {
+			p.reaperWg.Add(1)
+			go p.reapIdle()
+		}
+	})
+}
+
+// Acquire returns a database of the pool, opening a new one if none is idle.
+// If Max databases are already acquired, it blocks until one is released
+// or the context is done, in which case it returns the error of the context.
+func (p *Pool) Acquire(ctx context.Context) (*DB, error) {
+	p.init()
+
+	if p.tokens != nil {
+		select {
+		case p.tokens <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-p.done:
+			return nil, ErrPoolClosed
+		}
+	}
+
+	db, err := p.get(ctx)
+	if err != nil {
+		p.releaseToken()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// get returns the most recently released idle database, so that
+// the others can expire, or opens a new one.
+func (p *Pool) get(ctx context.Context) (*DB, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
+
+	if n := len(p.idle); n > 0 {
+		db := p.idle[n-1].db
+		p.idle = p.idle[:n-1]
+		p.acquired[db] = struct{}{}
+		p.mu.Unlock()
+		return db, nil
+	}
+	p.mu.Unlock()
+
+	if p.Open == nil {
+		return nil, errors.New("missing pool Open function")
+	}
+
+	db, err := p.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// the pool may have been closed while opening the database.
+	if p.closed {
+		db.Close()
+		return nil, ErrPoolClosed
+	}
+
+	p.acquired[db] = struct{}{}
+	return db, nil
+}
+
+// Release returns a database acquired with Acquire to the pool.
+// Databases that weren't acquired from the pool are ignored.
+// If the pool is closed, the database is closed.
+func (p *Pool) Release(db *DB) {
+	p.init()
+
+	p.mu.Lock()
+	if _, ok := p.acquired[db]; !ok {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.acquired, db)
+
+	if p.closed {
+		p.mu.Unlock()
+		db.Close()
+	} else {
+		p.idle = append(p.idle, idleDB{db: db, releasedAt: time.Now()})
+		p.mu.Unlock()
+	}
+
+	p.releaseToken()
+}
+
+func (p *Pool) releaseToken() {
+	if p.tokens != nil {
+		<-p.tokens
+	}
+}
+
+// Close closes the idle databases of the pool. The databases currently acquired
+// are closed when they are released. Acquire returns ErrPoolClosed once the pool is closed.
+func (p *Pool) Close() error {
+	p.init()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	close(p.done)
+	p.reaperWg.Wait()
+
+	var err error
+	for _, i := range idle {
+		if cerr := i.db.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// reapIdle periodically closes the databases that are idle for more than p.Idle.
+func (p *Pool) reapIdle() {
+	defer p.reaperWg.Done()
+
+	interval := p.Idle / 2
+	if interval <= 0 {
+		interval = p.Idle
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case now := <-ticker.C:
+			for _, db := range p.expired(now) {
+				// errors are not fatal, the database is removed from the pool anyway.
+				_ = db.Close()
+			}
+		}
+	}
+}
+
+// expired removes from the pool the databases idle since more than p.Idle,
+// keeping at least p.Min idle databases, and returns them.
+func (p *Pool) expired(now time.Time) []*DB {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var dbs []*DB
+	// the least recently released databases are first.
+	for len(p.idle) > p.Min && now.Sub(p.idle[0].releasedAt) >= p.Idle {
+		dbs = append(dbs, p.idle[0].db)
+		p.idle = p.idle[1:]
+	}
+
+	return dbs
+}
@@ -0,0 +1,164 @@
+package genji_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPool(min, max int, idle time.Duration) (*genji.Pool, *int32) {
+	var opened int32
+
+	return &genji.Pool{
+		Open: func(ctx context.Context) (*genji.DB, error) {
+			atomic.AddInt32(&opened, 1)
+			return genji.Open(":memory:")
+		},
+		Min:  min,
+		Max:  max,
+		Idle: idle,
+	}, &opened
+}
+
+func TestPool(t *testing.T) {
+	t.Run("Max", func(t *testing.T) {
+		pool, opened := newTestPool(0, 2, 0)
+		defer pool.Close()
+
+		var active, maxActive int32
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				db, err := pool.Acquire(context.Background())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer pool.Release(db)
+
+				n := atomic.AddInt32(&active, 1)
+				for {
+					m := atomic.LoadInt32(&maxActive)
+					if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+						break
+					}
+				}
+
+				err = db.Exec("CREATE TABLE IF NOT EXISTS test; INSERT INTO test (a) VALUES (1)")
+				if err != nil {
+					t.Error(err)
+				}
+				time.Sleep(5 * time.Millisecond)
+
+				atomic.AddInt32(&active, -1)
+			}()
+		}
+
+		wg.Wait()
+		require.LessOrEqual(t, atomic.LoadInt32(&maxActive), int32(2))
+		require.LessOrEqual(t, atomic.LoadInt32(opened), int32(2))
+	})
+
+	t.Run("Reuse", func(t *testing.T) {
+		pool, opened := newTestPool(0, 2, 0)
+		defer pool.Close()
+
+		db1, err := pool.Acquire(context.Background())
+		require.NoError(t, err)
+		pool.Release(db1)
+
+		db2, err := pool.Acquire(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, db1, db2)
+		require.EqualValues(t, 1, atomic.LoadInt32(opened))
+		pool.Release(db2)
+
+		// databases that don't come from the pool are ignored.
+		pool.Release(&genji.DB{})
+	})
+
+	t.Run("Idle", func(t *testing.T) {
+		pool, opened := newTestPool(1, 2, 20*time.Millisecond)
+		defer pool.Close()
+
+		db1, err := pool.Acquire(context.Background())
+		require.NoError(t, err)
+		db2, err := pool.Acquire(context.Background())
+		require.NoError(t, err)
+		pool.Release(db1)
+		pool.Release(db2)
+
+		time.Sleep(100 * time.Millisecond)
+
+		// db1 was closed, db2 is kept because of Min.
+		err = db1.Exec("CREATE TABLE test")
+		require.Error(t, err)
+
+		db, err := pool.Acquire(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, db2, db)
+		err = db.Exec("CREATE TABLE test")
+		require.NoError(t, err)
+		pool.Release(db)
+
+		require.EqualValues(t, 2, atomic.LoadInt32(opened))
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		pool, _ := newTestPool(0, 1, 0)
+		defer pool.Close()
+
+		db, err := pool.Acquire(context.Background())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = pool.Acquire(ctx)
+		require.Equal(t, context.DeadlineExceeded, err)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+		_, err = pool.Acquire(ctx)
+		require.Equal(t, context.Canceled, err)
+
+		// the slot is still available once released.
+		pool.Release(db)
+		db, err = pool.Acquire(context.Background())
+		require.NoError(t, err)
+		pool.Release(db)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		pool, _ := newTestPool(0, 1, 0)
+
+		db, err := pool.Acquire(context.Background())
+		require.NoError(t, err)
+
+		done := make(chan error)
+		go func() {
+			_, err := pool.Acquire(context.Background())
+			done <- err
+		}()
+
+		require.NoError(t, pool.Close())
+		require.Equal(t, genji.ErrPoolClosed, <-done)
+
+		// acquired databases are closed when released.
+		pool.Release(db)
+		err = db.Exec("CREATE TABLE test")
+		require.Error(t, err)
+
+		_, err = pool.Acquire(context.Background())
+		require.Equal(t, genji.ErrPoolClosed, err)
+	})
+}
@@ -0,0 +1,92 @@
+package genji
+
+import (
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// A Statement is a query parsed once by Prepare, which can be run multiple times
+// with different arguments.
+type Statement struct {
+	db       *DB
+	q        query.Query
+	defaults map[string]interface{}
+}
+
+// Prepare parses q and returns a statement that runs it against the database.
+func (db *DB) Prepare(q string) (*Statement, error) {
+	pq, err := parser.ParseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Statement{db: db, q: pq}, nil
+}
+
+// WithDefault sets the value of the named parameter name when it is not passed
+// to Query or Exec. It returns s to allow chaining, and must not be called
+// while the statement is running.
+func (s *Statement) WithDefault(name string, v interface{}) *Statement {
+	if s.defaults == nil {
+		s.defaults = make(map[string]interface{})
+	}
+
+	s.defaults[name] = v
+	return s
+}
+
+// Query runs the statement and returns the result.
+// The named parameters missing from args are set to their default value, if any.
+// The returned result must always be closed after usage.
+// ExecOptions can be passed along with the arguments.
+func (s *Statement) Query(args ...interface{}) (*query.Result, error) {
+	opts, args, err := splitExecOptions(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.db.run(s.q, opts, s.withDefaults(args))
+}
+
+// Exec runs the statement without returning the result.
+func (s *Statement) Exec(args ...interface{}) error {
+	res, err := s.Query(args...)
+	if err != nil {
+		return err
+	}
+
+	return res.Close()
+}
+
+// withDefaults adds the default values of the named parameters of the query
+// missing from args.
+func (s *Statement) withDefaults(args []interface{}) []interface{} {
+	if len(s.defaults) == 0 {
+		return args
+	}
+
+	params := argsToParams(args)
+	// don't modify the array of the caller
+	args = args[:len(args):len(args)]
+	for _, name := range s.q.NamedParams {
+		v, ok := s.defaults[name]
+		if !ok || hasParam(params, name) {
+			continue
+		}
+
+		args = append(args, expr.Param{Name: name, Value: v})
+	}
+
+	return args
+}
+
+func hasParam(params []expr.Param, name string) bool {
+	for _, p := range params {
+		if p.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
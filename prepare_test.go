@@ -0,0 +1,131 @@
+package genji_test
+
+import (
+	"database/sql"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepare(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		INSERT INTO test (name, age) VALUES ('a', 10), ('b', 20), ('c', 30);
+	`)
+	require.NoError(t, err)
+
+	names := func(t *testing.T, stmt *genji.Statement, args ...interface{}) []string {
+		res, err := stmt.Query(args...)
+		require.NoError(t, err)
+		defer res.Close()
+
+		var names []string
+		err = res.Iterate(func(d document.Document) error {
+			var name string
+			err := document.Scan(d, &name)
+			names = append(names, name)
+			return err
+		})
+		require.NoError(t, err)
+		return names
+	}
+
+	t.Run("Invalid query", func(t *testing.T) {
+		_, err := db.Prepare("SELECT * FROM")
+		require.Error(t, err)
+	})
+
+	t.Run("Run multiple times", func(t *testing.T) {
+		stmt, err := db.Prepare("SELECT name FROM test WHERE age > ?")
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"b", "c"}, names(t, stmt, 10))
+		require.Equal(t, []string{"c"}, names(t, stmt, 20))
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		stmt, err := db.Prepare("SELECT name FROM test WHERE age > $min AND age < $max")
+		require.NoError(t, err)
+		stmt.WithDefault("min", 15).WithDefault("unused", 1)
+
+		// omitted params use their default
+		require.Equal(t, []string{"b", "c"}, names(t, stmt, sql.Named("max", 100)))
+
+		// supplied params override their default
+		require.Equal(t, []string{"a", "b"}, names(t, stmt, sql.Named("min", 0), sql.Named("max", 25)))
+
+		// missing params without default still fail
+		_, err = stmt.Query()
+		require.EqualError(t, err, "param $max not found")
+	})
+
+	t.Run("Run concurrently", func(t *testing.T) {
+		stmt, err := db.Prepare(`
+			WITH older AS (SELECT name, age FROM test WHERE age > ?)
+			SELECT name FROM older WHERE EXISTS (SELECT * FROM older o WHERE o.age < older.age)
+		`)
+		require.NoError(t, err)
+
+		want := map[int][]string{0: {"b", "c"}, 10: {"c"}}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(min int) {
+				defer wg.Done()
+
+				for j := 0; j < 20; j++ {
+					res, err := stmt.Query(min)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+
+					var names []string
+					err = res.Iterate(func(d document.Document) error {
+						var name string
+						err := document.Scan(d, &name)
+						names = append(names, name)
+						return err
+					})
+					res.Close()
+					if err != nil {
+						t.Error(err)
+						return
+					}
+
+					if !reflect.DeepEqual(want[min], names) {
+						t.Errorf("expected %v, got %v", want[min], names)
+						return
+					}
+				}
+			}(i % 2 * 10)
+		}
+		wg.Wait()
+	})
+
+	t.Run("Exec", func(t *testing.T) {
+		stmt, err := db.Prepare("UPDATE test SET age = $age WHERE name = $name")
+		require.NoError(t, err)
+		stmt.WithDefault("age", 0)
+
+		err = stmt.Exec(sql.Named("name", "a"))
+		require.NoError(t, err)
+		err = stmt.Exec(sql.Named("name", "b"), sql.Named("age", 21))
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument("SELECT COUNT(*) FROM test WHERE age = 0 OR age = 21")
+		require.NoError(t, err)
+		var n int
+		require.NoError(t, document.Scan(d, &n))
+		require.Equal(t, 2, n)
+	})
+}
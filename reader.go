@@ -0,0 +1,30 @@
+package genji
+
+import (
+	"context"
+	"io"
+
+	"github.com/genjidb/genji/engine/readonly"
+)
+
+// OpenReader opens a read-only database reading the first size bytes of r,
+// which must have been written by DB.WriteReadOnly.
+// The data is read on demand and is never copied to disk, which allows
+// opening databases embedded in the binary.
+// Read/write transactions are rejected with readonly.ErrReadOnly.
+func OpenReader(r io.ReaderAt, size int64, opts ...Option) (*DB, error) {
+	ng, err := readonly.NewEngine(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(context.Background(), ng, opts...)
+}
+
+// WriteReadOnly writes the content of the database to w,
+// in a format that can be opened with OpenReader.
+func (db *DB) WriteReadOnly(w io.Writer) error {
+	return db.View(func(tx *Tx) error {
+		return tx.WriteReadOnly(w)
+	})
+}
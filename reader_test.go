@@ -0,0 +1,48 @@
+package genji_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine/readonly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenReader(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test(a INTEGER PRIMARY KEY);
+		CREATE INDEX idx_b ON test(b);
+		INSERT INTO test (a, b) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz');
+		CREATE TABLE empty;
+	`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = db.WriteReadOnly(&buf)
+	require.NoError(t, err)
+
+	rdb, err := genji.OpenReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	defer rdb.Close()
+
+	d, err := rdb.QueryDocument("SELECT a FROM test WHERE b = 'bar'")
+	require.NoError(t, err)
+	var a int
+	require.NoError(t, document.Scan(d, &a))
+	require.Equal(t, 2, a)
+
+	d, err = rdb.QueryDocument("SELECT COUNT(*) FROM empty")
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, document.Scan(d, &count))
+	require.Equal(t, 0, count)
+
+	err = rdb.Exec("INSERT INTO test (a) VALUES (4)")
+	require.Equal(t, readonly.ErrReadOnly, err)
+}
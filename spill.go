@@ -0,0 +1,51 @@
+// +build !wasm
+
+package genji
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/boltengine"
+	bolt "go.etcd.io/bbolt"
+)
+
+// tempSpillEngine is the default spill engine of the databases.
+// It creates a BoltDB engine in a temporary file, which is removed once the engine is closed.
+// The documents are only needed by the statement that spilled them, so writes are not synced.
+func tempSpillEngine() (engine.Engine, error) {
+	f, err := ioutil.TempFile("", "genji-spill-*.db")
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+
+	err = f.Close()
+	if err == nil {
+		var ng *boltengine.Engine
+		ng, err = boltengine.NewEngine(path, 0600, &bolt.Options{NoSync: true, NoFreelistSync: true})
+		if err == nil {
+			return &tempEngine{Engine: ng, path: path}, nil
+		}
+	}
+
+	_ = os.Remove(path)
+	return nil, err
+}
+
+// tempEngine removes the file of the engine once closed.
+type tempEngine struct {
+	*boltengine.Engine
+
+	path string
+}
+
+func (e *tempEngine) Close() error {
+	err := e.Engine.Close()
+	if rerr := os.Remove(e.path); err == nil {
+		err = rerr
+	}
+
+	return err
+}
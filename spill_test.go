@@ -0,0 +1,57 @@
+package genji_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultSpillEngine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genji")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tmp := os.Getenv("TMPDIR")
+	os.Setenv("TMPDIR", dir)
+	defer os.Setenv("TMPDIR", tmp)
+
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.EqualValues(t, database.DefaultMemoryBudget, db.DB.MemoryBudget)
+	db.DB.MemoryBudget = 1024
+
+	err = db.Exec("CREATE TABLE test")
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		err = db.Exec("INSERT INTO test (a) VALUES (?)", (i*7)%1000)
+		require.NoError(t, err)
+	}
+
+	res, err := db.Query("SELECT a FROM test ORDER BY a")
+	require.NoError(t, err)
+
+	var want int
+	err = res.Iterate(func(d document.Document) error {
+		var a int
+		err := document.Scan(d, &a)
+		require.NoError(t, err)
+		require.Equal(t, want, a)
+		want++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1000, want)
+	require.NoError(t, res.Close())
+
+	// the temporary files are removed once the statement is done
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
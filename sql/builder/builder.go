@@ -0,0 +1,103 @@
+// Package builder provides typed builders for the SELECT, INSERT, UPDATE and DELETE statements.
+// Statements are turned into the same planner trees as the ones created by the parser,
+// without going through any SQL, and can be run using DB.QueryStatement or Tx.QueryStatement.
+// Their String method renders them as SQL, which can be useful for debugging.
+//
+//	stmt := builder.Select().From("users").Where(expr.Gt(builder.Path("age"), expr.IntegerValue(18))).OrderBy("age").Limit(10)
+//	res, err := db.QueryStatement(stmt)
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/planner"
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+// Path returns a path selecting the given field.
+// Additional names select the fields of nested documents: Path("a", "b") selects a.b.
+func Path(fieldName string, nested ...string) expr.Path {
+	p := document.Path{document.PathFragment{FieldName: fieldName}}
+	for _, name := range nested {
+		p = append(p, document.PathFragment{FieldName: name})
+	}
+
+	return expr.Path(p)
+}
+
+// projectedFields returns the fields projected by a statement selecting the given expressions.
+// If exprs is empty, all the fields are selected.
+func projectedFields(exprs []expr.Expr) []planner.ProjectedField {
+	if len(exprs) == 0 {
+		return []planner.ProjectedField{planner.Wildcard{}}
+	}
+
+	fields := make([]planner.ProjectedField, len(exprs))
+	for i, e := range exprs {
+		fields[i] = planner.ProjectedExpr{Expr: e, ExprName: fmt.Sprintf("%v", e)}
+	}
+
+	return fields
+}
+
+// writeIdent writes the given identifier, quoting it if necessary.
+func writeIdent(b *strings.Builder, ident string) {
+	if isBareIdent(ident) && scanner.Lookup(ident) == scanner.IDENT {
+		b.WriteString(ident)
+		return
+	}
+
+	b.WriteByte('`')
+	for _, r := range ident {
+		if r == '`' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('`')
+}
+
+func isBareIdent(ident string) bool {
+	if ident == "" {
+		return false
+	}
+
+	for i, r := range ident {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// writePath writes the given path, quoting its field names if necessary.
+func writePath(b *strings.Builder, p document.Path) {
+	for i, f := range p {
+		if f.FieldName == "" {
+			b.WriteString(document.Path{f}.String())
+			continue
+		}
+
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		writeIdent(b, f.FieldName)
+	}
+}
+
+// writeExprs writes the given expressions, separated by commas.
+func writeExprs(b *strings.Builder, exprs []expr.Expr) {
+	for i, e := range exprs {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(b, "%v", e)
+	}
+}
@@ -0,0 +1,123 @@
+package builder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/builder"
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/planner"
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	doc := document.NewFieldBuffer().Add("a", document.NewIntegerValue(1))
+
+	tests := []struct {
+		name     string
+		stmt     interface{ String() string }
+		expected string
+	}{
+		{"Select", builder.Select(), "SELECT *"},
+		{"Select/Full", builder.Select(builder.Path("a"), builder.Path("b", "c")).Distinct().From("test").
+			Where(expr.Gt(builder.Path("a"), expr.IntegerValue(18))).OrderByDesc("a").Limit(10).Offset(20),
+			"SELECT DISTINCT a, b.c FROM test WHERE a > 18 ORDER BY a DESC LIMIT 10 OFFSET 20"},
+		{"Select/Quoted", builder.Select().From("my table").OrderBy("`order`.b"), "SELECT * FROM `my table` ORDER BY `order`.b"},
+		{"Select/Collate", builder.Select().From("test").OrderByDesc("a").Collate("NOCASE"), "SELECT * FROM test ORDER BY a COLLATE NOCASE DESC"},
+		{"Select/Nulls", builder.Select().From("test").OrderByDesc("a").NullsLast(), "SELECT * FROM test ORDER BY a DESC NULLS LAST"},
+		{"Insert", builder.Insert().Into("test").Values(doc, doc), `INSERT INTO test VALUES {"a": 1}, {"a": 1}`},
+		{"Update/Set", builder.Update("test").Set("a", expr.IntegerValue(1)).Set("b.c", expr.TextValue("foo")).Where(expr.Eq(builder.Path("a"), expr.IntegerValue(2))),
+			`UPDATE test SET a = 1, b.c = "foo" WHERE a = 2`},
+		{"Update/Unset", builder.Update("test").Unset("a", "b.c", "d[2]"), "UPDATE test UNSET a, b.c, d[2]"},
+		{"Delete", builder.Delete().From("test").Where(expr.Lt(builder.Path("a"), expr.IntegerValue(2))), "DELETE FROM test WHERE a < 2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.stmt.String())
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt interface {
+			String() string
+			Tree() (*planner.Tree, error)
+		}
+	}{
+		{"Select", builder.Select(builder.Path("a"), builder.Path("b", "c")).Distinct().From("test").
+			Where(expr.Gt(builder.Path("a"), expr.IntegerValue(18))).OrderBy("a").Limit(10).Offset(20)},
+		{"Select/Desc", builder.Select().From("test").OrderByDesc("a")},
+		{"Select/Collate", builder.Select().From("test").OrderBy("a").Collate("nocase")},
+		{"Select/Nulls", builder.Select().From("test").OrderBy("a").NullsLast()},
+		{"Select/Into", builder.Select(builder.Path("a")).Into("foo").From("test")},
+		{"Update", builder.Update("test").Set("a", expr.IntegerValue(1)).Where(expr.Eq(builder.Path("a"), expr.IntegerValue(2)))},
+		{"Update/Unset", builder.Update("test").Unset("a", "b.`c d`[1]")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree, err := test.stmt.Tree()
+			require.NoError(t, err)
+
+			q, err := parser.ParseQuery(test.stmt.String())
+			require.NoError(t, err)
+			require.Len(t, q.Statements, 1)
+			require.EqualValues(t, tree, q.Statements[0])
+		})
+	}
+
+	t.Run("Delete", func(t *testing.T) {
+		stmt := builder.Delete().From("test").Where(expr.Eq(builder.Path("a"), expr.IntegerValue(2)))
+
+		q, err := parser.ParseQuery(stmt.String())
+		require.NoError(t, err)
+		require.Len(t, q.Statements, 1)
+		require.EqualValues(t, stmt.Tree(), q.Statements[0])
+	})
+}
+
+func TestRun(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE users")
+	require.NoError(t, err)
+
+	for _, age := range []int64{10, 20, 30, 40} {
+		err = db.ExecStatement(builder.Insert().Into("users").Values(
+			document.NewFieldBuffer().Add("age", document.NewIntegerValue(age)),
+		))
+		require.NoError(t, err)
+	}
+
+	err = db.ExecStatement(builder.Update("users").Set("adult", expr.BoolValue(true)).Where(expr.Gte(builder.Path("age"), expr.IntegerValue(18))))
+	require.NoError(t, err)
+
+	err = db.ExecStatement(builder.Delete().From("users").Where(expr.Gt(builder.Path("age"), expr.IntegerValue(35))))
+	require.NoError(t, err)
+
+	err = db.View(func(tx *genji.Tx) error {
+		res, err := tx.QueryStatement(
+			builder.Select(builder.Path("age")).From("users").Where(expr.Eq(builder.Path("adult"), expr.BoolValue(true))).OrderByDesc("age").Limit(10),
+		)
+		require.NoError(t, err)
+		defer res.Close()
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"age": 30}, {"age": 20}]`, buf.String())
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.ExecStatement(builder.Update("users").Set("a", expr.IntegerValue(1)).Unset("b"))
+	require.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/planner"
+	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// DeleteStmt builds a DELETE statement.
+// It implements the query.Statement interface.
+type DeleteStmt struct {
+	cfg parser.DeleteConfig
+}
+
+// Delete creates a statement deleting documents.
+func Delete() *DeleteStmt {
+	return &DeleteStmt{}
+}
+
+// From deletes the documents of the given table.
+func (s *DeleteStmt) From(tableName string) *DeleteStmt {
+	s.cfg.TableName = tableName
+	return s
+}
+
+// Where only deletes the documents matching e.
+func (s *DeleteStmt) Where(e expr.Expr) *DeleteStmt {
+	s.cfg.WhereExpr = e
+	return s
+}
+
+// Tree returns the planner tree of the statement.
+func (s *DeleteStmt) Tree() *planner.Tree {
+	return s.cfg.ToTree()
+}
+
+// Run the statement in the given transaction.
+// It implements the query.Statement interface.
+func (s *DeleteStmt) Run(tx *database.Transaction, args []expr.Param) (query.Result, error) {
+	return s.Tree().Run(tx, args)
+}
+
+// IsReadOnly always returns false. It implements the query.Statement interface.
+func (s *DeleteStmt) IsReadOnly() bool {
+	return false
+}
+
+// Tables returns the name of the table the documents are deleted from.
+// It implements the query.Statement interface.
+func (s *DeleteStmt) Tables() []string {
+	return s.Tree().Tables()
+}
+
+// String renders the statement as SQL.
+func (s *DeleteStmt) String() string {
+	var b strings.Builder
+
+	b.WriteString("DELETE FROM ")
+	writeIdent(&b, s.cfg.TableName)
+
+	if s.cfg.WhereExpr != nil {
+		fmt.Fprintf(&b, " WHERE %v", s.cfg.WhereExpr)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package builder
+
+import (
+	"strings"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// InsertStmt builds an INSERT statement.
+// It implements the query.Statement interface.
+type InsertStmt struct {
+	stmt query.InsertStmt
+}
+
+// Insert creates a statement inserting documents.
+func Insert() *InsertStmt {
+	return &InsertStmt{}
+}
+
+// Into inserts the documents in the given table.
+func (s *InsertStmt) Into(tableName string) *InsertStmt {
+	s.stmt.TableName = tableName
+	return s
+}
+
+// Values adds documents to insert.
+func (s *InsertStmt) Values(docs ...document.Document) *InsertStmt {
+	for _, d := range docs {
+		s.stmt.Values = append(s.stmt.Values, expr.DocumentValue(d))
+	}
+	return s
+}
+
+// Run the statement in the given transaction.
+// It implements the query.Statement interface.
+func (s *InsertStmt) Run(tx *database.Transaction, args []expr.Param) (query.Result, error) {
+	return s.stmt.Run(tx, args)
+}
+
+// IsReadOnly always returns false. It implements the query.Statement interface.
+func (s *InsertStmt) IsReadOnly() bool {
+	return false
+}
+
+// Tables returns the name of the table the documents are inserted in.
+// It implements the query.Statement interface.
+func (s *InsertStmt) Tables() []string {
+	return s.stmt.Tables()
+}
+
+// String renders the statement as SQL.
+func (s *InsertStmt) String() string {
+	var b strings.Builder
+
+	b.WriteString("INSERT INTO ")
+	writeIdent(&b, s.stmt.TableName)
+	b.WriteString(" VALUES ")
+	writeExprs(&b, s.stmt.Values)
+
+	return b.String()
+}
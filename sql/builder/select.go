@@ -0,0 +1,212 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/planner"
+	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+// SelectStmt builds a SELECT statement.
+// It implements the query.Statement interface.
+type SelectStmt struct {
+	cfg    parser.SelectConfig
+	fields []expr.Expr
+	err    error
+}
+
+// Select creates a statement selecting the given expressions.
+// If no expression is given, all the fields are selected.
+func Select(fields ...expr.Expr) *SelectStmt {
+	return &SelectStmt{
+		cfg:    parser.SelectConfig{ProjectionExprs: projectedFields(fields)},
+		fields: fields,
+	}
+}
+
+// Distinct removes duplicate documents from the result.
+func (s *SelectStmt) Distinct() *SelectStmt {
+	s.cfg.Distinct = true
+	return s
+}
+
+// Into creates the given table and inserts the result of the statement in it.
+func (s *SelectStmt) Into(tableName string) *SelectStmt {
+	s.cfg.IntoTable = tableName
+	return s
+}
+
+// From selects the documents of the given table.
+func (s *SelectStmt) From(tableName string) *SelectStmt {
+	s.cfg.TableName = tableName
+	return s
+}
+
+// Where only selects the documents matching e.
+func (s *SelectStmt) Where(e expr.Expr) *SelectStmt {
+	s.cfg.WhereExpr = e
+	return s
+}
+
+// GroupBy groups the documents by the value of e.
+func (s *SelectStmt) GroupBy(e expr.Expr) *SelectStmt {
+	s.cfg.GroupByExpr = e
+	return s
+}
+
+// OrderBy sorts the documents by the given path, in ascending order.
+func (s *SelectStmt) OrderBy(path string) *SelectStmt {
+	return s.orderBy(path, 0)
+}
+
+// OrderByDesc sorts the documents by the given path, in descending order.
+func (s *SelectStmt) OrderByDesc(path string) *SelectStmt {
+	return s.orderBy(path, scanner.DESC)
+}
+
+// Collate sets the collation used by OrderBy and OrderByDesc to sort texts,
+// either BINARY or NOCASE.
+func (s *SelectStmt) Collate(collation string) *SelectStmt {
+	if _, err := planner.GetCollation(collation); err != nil {
+		s.err = err
+		return s
+	}
+
+	s.cfg.OrderByCollation = collation
+	return s
+}
+
+// NullsFirst makes OrderBy and OrderByDesc place null values before the other values.
+func (s *SelectStmt) NullsFirst() *SelectStmt {
+	s.cfg.OrderByNulls = planner.NullsFirst
+	return s
+}
+
+// NullsLast makes OrderBy and OrderByDesc place null values after the other values.
+func (s *SelectStmt) NullsLast() *SelectStmt {
+	s.cfg.OrderByNulls = planner.NullsLast
+	return s
+}
+
+func (s *SelectStmt) orderBy(path string, direction scanner.Token) *SelectStmt {
+	p, err := parser.ParsePath(path)
+	if err != nil {
+		s.err = err
+		return s
+	}
+
+	s.cfg.OrderBy = expr.Path(p)
+	s.cfg.OrderByDirection = direction
+	return s
+}
+
+// Limit returns at most n documents.
+func (s *SelectStmt) Limit(n int) *SelectStmt {
+	s.cfg.LimitExpr = expr.IntegerValue(int64(n))
+	return s
+}
+
+// Offset skips the first n documents.
+func (s *SelectStmt) Offset(n int) *SelectStmt {
+	s.cfg.OffsetExpr = expr.IntegerValue(int64(n))
+	return s
+}
+
+// Tree returns the planner tree of the statement.
+func (s *SelectStmt) Tree() (*planner.Tree, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return s.cfg.ToTree()
+}
+
+// Run the statement in the given transaction.
+// It implements the query.Statement interface.
+func (s *SelectStmt) Run(tx *database.Transaction, args []expr.Param) (query.Result, error) {
+	t, err := s.Tree()
+	if err != nil {
+		return query.Result{}, err
+	}
+
+	return t.Run(tx, args)
+}
+
+// IsReadOnly returns false if the result of the statement is inserted in a table.
+// It implements the query.Statement interface.
+func (s *SelectStmt) IsReadOnly() bool {
+	return s.cfg.IntoTable == ""
+}
+
+// Tables returns the names of the tables read or modified by the statement,
+// or nil if the statement is invalid. It implements the query.Statement interface.
+func (s *SelectStmt) Tables() []string {
+	t, err := s.Tree()
+	if err != nil {
+		return nil
+	}
+
+	return t.Tables()
+}
+
+// String renders the statement as SQL.
+func (s *SelectStmt) String() string {
+	var b strings.Builder
+
+	b.WriteString("SELECT ")
+	if s.cfg.Distinct {
+		b.WriteString("DISTINCT ")
+	}
+	if len(s.fields) == 0 {
+		b.WriteString("*")
+	} else {
+		writeExprs(&b, s.fields)
+	}
+
+	if s.cfg.IntoTable != "" {
+		b.WriteString(" INTO ")
+		writeIdent(&b, s.cfg.IntoTable)
+	}
+
+	if s.cfg.TableName != "" {
+		b.WriteString(" FROM ")
+		writeIdent(&b, s.cfg.TableName)
+	}
+	if s.cfg.WhereExpr != nil {
+		fmt.Fprintf(&b, " WHERE %v", s.cfg.WhereExpr)
+	}
+	if s.cfg.GroupByExpr != nil {
+		fmt.Fprintf(&b, " GROUP BY %v", s.cfg.GroupByExpr)
+	}
+	if s.cfg.OrderBy != nil {
+		b.WriteString(" ORDER BY ")
+		writePath(&b, document.Path(s.cfg.OrderBy))
+		if s.cfg.OrderByCollation != "" {
+			b.WriteString(" COLLATE ")
+			b.WriteString(s.cfg.OrderByCollation)
+		}
+		if s.cfg.OrderByDirection == scanner.DESC {
+			b.WriteString(" DESC")
+		}
+		switch s.cfg.OrderByNulls {
+		case planner.NullsFirst:
+			b.WriteString(" NULLS FIRST")
+		case planner.NullsLast:
+			b.WriteString(" NULLS LAST")
+		}
+	}
+	if s.cfg.LimitExpr != nil {
+		fmt.Fprintf(&b, " LIMIT %v", s.cfg.LimitExpr)
+	}
+	if s.cfg.OffsetExpr != nil {
+		fmt.Fprintf(&b, " OFFSET %v", s.cfg.OffsetExpr)
+	}
+
+	return b.String()
+}
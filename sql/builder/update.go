@@ -0,0 +1,137 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/planner"
+	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// UpdateStmt builds an UPDATE statement.
+// It implements the query.Statement interface.
+type UpdateStmt struct {
+	cfg parser.UpdateConfig
+	err error
+}
+
+// Update creates a statement updating the documents of the given table.
+func Update(tableName string) *UpdateStmt {
+	return &UpdateStmt{cfg: parser.UpdateConfig{TableName: tableName}}
+}
+
+// Set sets the value of the given path to the result of e.
+// It cannot be used along with Unset.
+func (s *UpdateStmt) Set(path string, e expr.Expr) *UpdateStmt {
+	p, err := parser.ParsePath(path)
+	if err != nil {
+		s.err = err
+		return s
+	}
+
+	s.cfg.SetPairs = append(s.cfg.SetPairs, parser.UpdateSetPair{Path: p, E: e})
+	return s
+}
+
+// Unset removes the values at the given paths.
+// It cannot be used along with Set.
+func (s *UpdateStmt) Unset(paths ...string) *UpdateStmt {
+	for _, path := range paths {
+		p, err := parser.ParsePath(path)
+		if err != nil {
+			s.err = err
+			return s
+		}
+
+		s.cfg.UnsetPaths = append(s.cfg.UnsetPaths, p)
+	}
+
+	return s
+}
+
+// Where only updates the documents matching e.
+func (s *UpdateStmt) Where(e expr.Expr) *UpdateStmt {
+	s.cfg.WhereExpr = e
+	return s
+}
+
+// Tree returns the planner tree of the statement.
+func (s *UpdateStmt) Tree() (*planner.Tree, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	if s.cfg.SetPairs == nil && s.cfg.UnsetPaths == nil {
+		return nil, errors.New("missing SET or UNSET clause")
+	}
+
+	if s.cfg.SetPairs != nil && s.cfg.UnsetPaths != nil {
+		return nil, errors.New("cannot use both SET and UNSET clauses")
+	}
+
+	return s.cfg.ToTree(), nil
+}
+
+// Run the statement in the given transaction.
+// It implements the query.Statement interface.
+func (s *UpdateStmt) Run(tx *database.Transaction, args []expr.Param) (query.Result, error) {
+	t, err := s.Tree()
+	if err != nil {
+		return query.Result{}, err
+	}
+
+	return t.Run(tx, args)
+}
+
+// IsReadOnly always returns false. It implements the query.Statement interface.
+func (s *UpdateStmt) IsReadOnly() bool {
+	return false
+}
+
+// Tables returns the name of the updated table, or nil if the statement is invalid.
+// It implements the query.Statement interface.
+func (s *UpdateStmt) Tables() []string {
+	t, err := s.Tree()
+	if err != nil {
+		return nil
+	}
+
+	return t.Tables()
+}
+
+// String renders the statement as SQL.
+func (s *UpdateStmt) String() string {
+	var b strings.Builder
+
+	b.WriteString("UPDATE ")
+	writeIdent(&b, s.cfg.TableName)
+
+	if s.cfg.SetPairs != nil {
+		b.WriteString(" SET ")
+		for i, pair := range s.cfg.SetPairs {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writePath(&b, pair.Path)
+			fmt.Fprintf(&b, " = %v", pair.E)
+		}
+	} else if s.cfg.UnsetPaths != nil {
+		b.WriteString(" UNSET ")
+		for i, p := range s.cfg.UnsetPaths {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writePath(&b, p)
+		}
+	}
+
+	if s.cfg.WhereExpr != nil {
+		fmt.Fprintf(&b, " WHERE %v", s.cfg.WhereExpr)
+	}
+
+	return b.String()
+}
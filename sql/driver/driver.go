@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"errors"
+	"fmt"
 	"io"
+	"net/url"
 	"runtime"
+	"strings"
 	"sync"
 
 	"github.com/genjidb/genji"
@@ -34,8 +37,22 @@ func (d sqlDriver) Open(name string) (driver.Conn, error) {
 	return nil, errors.New("requires go1.10 or greater")
 }
 
+// OpenConnector parses the data source name and opens the database.
+// The data source name is the path of the database, as expected by genji.Open,
+// optionally followed by a list of options in the form of a URL query string:
+//   path?option=value&option2=value2
+// The options follow the last '?' of the name, so the path can contain '?'.
+// Supported options:
+//   wildcard: controls how wildcards (*) are returned.
+//     "document" (default): a wildcard column named "*" returns each document as a document.Document.
+//     "json": a wildcard column named "document" returns the JSON encoding of each document as a string.
 func (d sqlDriver) OpenConnector(name string) (driver.Connector, error) {
-	db, err := genji.Open(name)
+	path, opts, err := parseDSN(name)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := genji.Open(path)
 	if err != nil {
 		return nil, err
 	}
@@ -43,6 +60,7 @@ func (d sqlDriver) OpenConnector(name string) (driver.Connector, error) {
 	c := &connector{
 		db:     db,
 		driver: d,
+		opts:   opts,
 	}
 	runtime.SetFinalizer(c, (*connector).Close)
 
@@ -57,13 +75,14 @@ var (
 type connector struct {
 	driver driver.Driver
 
-	db *genji.DB
+	db   *genji.DB
+	opts options
 
 	closeOnce sync.Once
 }
 
 func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
-	return &conn{db: c.db}, nil
+	return &conn{db: c.db, opts: c.opts}, nil
 }
 
 func (c *connector) Driver() driver.Driver {
@@ -81,8 +100,9 @@ func (c *connector) Close() error {
 // conn represents a connection to the Genji database.
 // It implements the database/sql/driver.Conn interface.
 type conn struct {
-	db *genji.DB
-	tx *genji.Tx
+	db   *genji.DB
+	tx   *genji.Tx
+	opts options
 }
 
 // Prepare returns a prepared statement, bound to this connection.
@@ -98,9 +118,10 @@ func (c *conn) PrepareContext(ctx context.Context, q string) (driver.Stmt, error
 	}
 
 	return stmt{
-		db: c.db,
-		tx: c.tx,
-		q:  pq,
+		db:   c.db,
+		tx:   c.tx,
+		q:    pq,
+		opts: c.opts,
 	}, nil
 }
 
@@ -151,9 +172,10 @@ func (c *conn) Rollback() error {
 // Stmt is a prepared statement. It is bound to a Conn and not
 // used by multiple goroutines concurrently.
 type stmt struct {
-	db *genji.DB
-	tx *genji.Tx
-	q  query.Query
+	db   *genji.DB
+	tx   *genji.Tx
+	q    query.Query
+	opts options
 }
 
 // NumInput returns the number of placeholder parameters.
@@ -262,6 +284,7 @@ func (s stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (drive
 	}
 
 	rs := newRecordStream(res)
+	rs.wildcardAsJSON = s.opts.wildcardAsJSON
 	if len(s.q.Statements) == 0 {
 		return rs, nil
 	}
@@ -273,7 +296,16 @@ func (s stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (drive
 		return rs, nil
 	}
 
-	if pn, ok := tree.Root.(*planner.ProjectionNode); ok && len(pn.Expressions) > 0 {
+	// the projection node is not necessarily the root of the tree,
+	// nodes like Sort or Limit can be placed above it.
+	var pn *planner.ProjectionNode
+	for n := tree.Root; n != nil; n = n.Left() {
+		if pn, ok = n.(*planner.ProjectionNode); ok {
+			break
+		}
+	}
+
+	if pn != nil && len(pn.Expressions) > 0 {
 		rs.fields = make([]string, len(pn.Expressions))
 		for i := range pn.Expressions {
 			rs.fields[i] = pn.Expressions[i].Name()
@@ -301,11 +333,12 @@ func (s stmt) Close() error {
 var errStop = errors.New("stop")
 
 type documentStream struct {
-	res      *query.Result
-	cancelFn func()
-	c        chan doc
-	wg       sync.WaitGroup
-	fields   []string
+	res            *query.Result
+	cancelFn       func()
+	c              chan doc
+	wg             sync.WaitGroup
+	fields         []string
+	wildcardAsJSON bool
 }
 
 type doc struct {
@@ -367,8 +400,21 @@ func (rs *documentStream) iterate(ctx context.Context) {
 }
 
 // Columns returns the fields selected by the SELECT statement.
+// The returned columns are the same for every document of the stream.
 func (rs *documentStream) Columns() []string {
-	return rs.fields
+	if !rs.wildcardAsJSON {
+		return rs.fields
+	}
+
+	columns := make([]string, len(rs.fields))
+	for i, f := range rs.fields {
+		if f == "*" {
+			f = wildcardColumnName
+		}
+		columns[i] = f
+	}
+
+	return columns
 }
 
 // Close closes the rows iterator.
@@ -391,7 +437,16 @@ func (rs *documentStream) Next(dest []driver.Value) error {
 
 	for i := range rs.fields {
 		if rs.fields[i] == "*" {
-			dest[i] = doc.d
+			if !rs.wildcardAsJSON {
+				dest[i] = doc.d
+				continue
+			}
+
+			data, err := document.MarshalJSON(doc.d)
+			if err != nil {
+				return err
+			}
+			dest[i] = string(data)
 
 			continue
 		}
@@ -407,6 +462,62 @@ func (rs *documentStream) Next(dest []driver.Value) error {
 	return nil
 }
 
+// wildcardColumnName is the name of the column returned for wildcards
+// when documents are returned as JSON.
+const wildcardColumnName = "document"
+
+// options of the driver, parsed from the data source name.
+type options struct {
+	wildcardAsJSON bool
+}
+
+// parseDSN splits the data source name into the database path and the driver options.
+// The options follow the last '?' of the name, which is entirely used as the path if
+// what follows isn't a list of key=value pairs, so that paths can contain '?'.
+func parseDSN(name string) (string, options, error) {
+	var opts options
+
+	i := strings.LastIndexByte(name, '?')
+	if i < 0 || !isQueryString(name[i+1:]) {
+		return name, opts, nil
+	}
+
+	path := name[:i]
+	values, err := url.ParseQuery(name[i+1:])
+	if err != nil {
+		return "", opts, fmt.Errorf("invalid data source name %q: %w", name, err)
+	}
+
+	for k, v := range values {
+		switch k {
+		case "wildcard":
+			switch v[len(v)-1] {
+			case "document":
+				opts.wildcardAsJSON = false
+			case "json":
+				opts.wildcardAsJSON = true
+			default:
+				return "", opts, fmt.Errorf("invalid value %q for option %q", v[len(v)-1], k)
+			}
+		default:
+			return "", opts, fmt.Errorf("unknown option %q", k)
+		}
+	}
+
+	return path, opts, nil
+}
+
+// isQueryString reports whether s is a list of key=value pairs separated by '&'.
+func isQueryString(s string) bool {
+	for _, kv := range strings.Split(s, "&") {
+		if strings.IndexByte(kv, '=') <= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
 type valueScanner struct {
 	v interface{}
 }
@@ -3,8 +3,13 @@ package driver
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/engine"
 	"github.com/stretchr/testify/require"
 )
@@ -212,3 +217,186 @@ func TestDriver(t *testing.T) {
 		require.Equal(t, err, engine.ErrTransactionReadOnly)
 	})
 }
+
+func TestDriverConstraintViolation(t *testing.T) {
+	db, err := sql.Open("genji", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE test(a INTEGER NOT NULL)")
+	require.NoError(t, err)
+
+	_, err = db.Exec("INSERT INTO test (a) VALUES (?)", "foo")
+	var cerr *database.ConstraintViolationError
+	require.True(t, errors.As(err, &cerr), "got %v", err)
+	require.Equal(t, database.TypeViolation, cerr.Kind)
+	require.Equal(t, "test", cerr.Table)
+	require.Equal(t, "a", cerr.Path.String())
+	require.Equal(t, "integer", cerr.Constraint)
+
+	_, err = db.Exec("INSERT INTO test (b) VALUES (1)")
+	require.True(t, errors.As(err, &cerr), "got %v", err)
+	require.Equal(t, database.NotNullViolation, cerr.Kind)
+}
+
+func TestDriverWildcardColumns(t *testing.T) {
+	setup := func(t *testing.T, dsn string) *sql.DB {
+		db, err := sql.Open("genji", dsn)
+		require.NoError(t, err)
+
+		_, err = db.Exec(`
+			CREATE TABLE test;
+			INSERT INTO test (a, b) VALUES (1, 'foo');
+			INSERT INTO test (a, c, d) VALUES (2, true, [1, 2]);
+			INSERT INTO test (e) VALUES ('bar');
+		`)
+		require.NoError(t, err)
+
+		return db
+	}
+
+	t.Run("Document mode", func(t *testing.T) {
+		db := setup(t, ":memory:?wildcard=document")
+		defer db.Close()
+
+		rows, err := db.Query("SELECT * FROM test ORDER BY a")
+		require.NoError(t, err)
+		defer rows.Close()
+
+		var count int
+		for rows.Next() {
+			cols, err := rows.Columns()
+			require.NoError(t, err)
+			require.Equal(t, []string{"*"}, cols)
+
+			var f foo
+			err = rows.Scan(Scanner(&f))
+			require.NoError(t, err)
+			count++
+		}
+		require.NoError(t, rows.Err())
+		require.Equal(t, 3, count)
+	})
+
+	t.Run("JSON mode", func(t *testing.T) {
+		db := setup(t, ":memory:?wildcard=json")
+		defer db.Close()
+
+		rows, err := db.Query("SELECT * FROM test ORDER BY a")
+		require.NoError(t, err)
+		defer rows.Close()
+
+		var docs []string
+		for rows.Next() {
+			cols, err := rows.Columns()
+			require.NoError(t, err)
+			require.Equal(t, []string{"document"}, cols)
+
+			var d string
+			err = rows.Scan(&d)
+			require.NoError(t, err)
+			docs = append(docs, d)
+		}
+		require.NoError(t, rows.Err())
+		require.Equal(t, []string{
+			`{"e": "bar"}`,
+			`{"a": 1, "b": "foo"}`,
+			`{"a": 2, "c": true, "d": [1, 2]}`,
+		}, docs)
+	})
+
+	t.Run("Explicit projection", func(t *testing.T) {
+		for _, dsn := range []string{":memory:", ":memory:?wildcard=json"} {
+			func() {
+				db := setup(t, dsn)
+				defer db.Close()
+
+				rows, err := db.Query("SELECT a, b AS bb, c = true FROM test ORDER BY a LIMIT 10")
+				require.NoError(t, err)
+				defer rows.Close()
+
+				var count int
+				for rows.Next() {
+					cols, err := rows.Columns()
+					require.NoError(t, err)
+					require.Equal(t, []string{"a", "bb", "c = true"}, cols)
+
+					var a, b, c interface{}
+					err = rows.Scan(&a, &b, &c)
+					require.NoError(t, err)
+					count++
+				}
+				require.NoError(t, rows.Err())
+				require.Equal(t, 3, count)
+			}()
+		}
+	})
+
+	t.Run("Invalid option", func(t *testing.T) {
+		_, err := sql.Open("genji", ":memory:?wildcard=foo")
+		require.Error(t, err)
+
+		_, err = sql.Open("genji", ":memory:?foo=bar")
+		require.Error(t, err)
+	})
+
+	t.Run("Path with a question mark", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "genji")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "what?", "test.db")
+		err = os.Mkdir(filepath.Dir(path), 0700)
+		require.NoError(t, err)
+
+		for _, dsn := range []string{path, path + "?wildcard=json"} {
+			func() {
+				db := setup(t, dsn)
+				defer db.Close()
+
+				var n int
+				err := db.QueryRow("SELECT COUNT(*) FROM test").Scan(&n)
+				require.NoError(t, err)
+				require.Equal(t, 3, n)
+
+				_, err = db.Exec("DROP TABLE test")
+				require.NoError(t, err)
+			}()
+		}
+
+		_, err = os.Stat(path)
+		require.NoError(t, err)
+	})
+}
+
+func TestParseDSN(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		json  bool
+		fails bool
+	}{
+		{"test.db", "test.db", false, false},
+		{"test.db?wildcard=json", "test.db", true, false},
+		{"what?/test.db", "what?/test.db", false, false},
+		{"what?/test.db?wildcard=json", "what?/test.db", true, false},
+		{"what?", "what?", false, false},
+		{"test.db?wildcard=json&wildcard=document", "test.db", false, false},
+		{"test.db?wildcard=foo", "", false, true},
+		{"test.db?foo=bar", "", false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, opts, err := parseDSN(test.name)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.path, path)
+			require.Equal(t, test.json, opts.wildcardAsJSON)
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package parser
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/sql/query"
 	"github.com/genjidb/genji/sql/query/expr"
 	"github.com/genjidb/genji/sql/scanner"
@@ -24,9 +27,13 @@ func (p *Parser) parseCreateStatement() (query.Statement, error) {
 		return p.parseCreateIndexStatement(true)
 	case scanner.INDEX:
 		return p.parseCreateIndexStatement(false)
+	case scanner.IDENT:
+		if isKeyword(tok, lit, "TRIGGER") {
+			return p.parseCreateTriggerStatement()
+		}
 	}
 
-	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLE", "INDEX"}, pos)
+	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLE", "INDEX", "TRIGGER"}, pos)
 }
 
 // parseCreateTableStatement parses a create table string and returns a Statement AST object.
@@ -76,18 +83,25 @@ func (p *Parser) parseIfNotExists() (bool, error) {
 	return true, nil
 }
 
-func (p *Parser) parseFieldDefinition(fc *database.FieldConstraint) (err error) {
+// parseFieldDefinition parses a path, its optional type and its constraints.
+// If ttl is not nil, the TTL constraint is allowed and *ttl is set if it is present.
+func (p *Parser) parseFieldDefinition(fc *database.FieldConstraint, ttl *bool) (err error) {
 	fc.Path, err = p.parsePath()
 	if err != nil {
 		return err
 	}
 
-	fc.Type, err = p.parseType()
-	if err != nil {
-		return err
+	// The type is optional.
+	tok, _, lit := p.ScanIgnoreWhitespace()
+	p.Unscan()
+	if isType(tok, lit) {
+		fc.Type, err = p.parseType()
+		if err != nil {
+			return err
+		}
 	}
 
-	return p.parseFieldConstraint(fc)
+	return p.parseFieldConstraint(fc, ttl)
 }
 
 func (p *Parser) parseFieldConstraints(info *database.TableInfo) error {
@@ -102,14 +116,22 @@ func (p *Parser) parseFieldConstraints(info *database.TableInfo) error {
 	// Parse constraints.
 	for {
 		var fc database.FieldConstraint
+		var ttl bool
 
-		err = p.parseFieldDefinition(&fc)
+		err = p.parseFieldDefinition(&fc, &ttl)
 		if err != nil {
 			return err
 		}
 
 		info.FieldConstraints = append(info.FieldConstraints, fc)
 
+		if ttl {
+			if len(info.TTLPath) > 0 {
+				return &ParseError{Message: "only one TTL field is allowed"}
+			}
+			info.TTLPath = fc.Path
+		}
+
 		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
 			p.Unscan()
 			break
@@ -135,7 +157,7 @@ func (p *Parser) parseFieldConstraints(info *database.TableInfo) error {
 	return nil
 }
 
-func (p *Parser) parseFieldConstraint(fc *database.FieldConstraint) error {
+func (p *Parser) parseFieldConstraint(fc *database.FieldConstraint, ttl *bool) (err error) {
 	for {
 		tok, pos, lit := p.ScanIgnoreWhitespace()
 		switch tok {
@@ -181,6 +203,29 @@ func (p *Parser) parseFieldConstraint(fc *database.FieldConstraint) error {
 			}
 
 			fc.DefaultValue = d
+		case scanner.AS:
+			// if it's already generated we return an error
+			if fc.IsGenerated() {
+				return newParseError(scanner.Tokstr(tok, lit), []string{"CONSTRAINT", ")"}, pos)
+			}
+
+			fc.GeneratedExpr, err = p.parseGeneratedExpr()
+			if err != nil {
+				return err
+			}
+		case scanner.IDENT:
+			// TTL is not a keyword, to allow using it as a field name.
+			if ttl == nil || !strings.EqualFold(lit, "TTL") {
+				p.Unscan()
+				return nil
+			}
+
+			// if it's already the TTL field we return an error
+			if *ttl {
+				return newParseError(scanner.Tokstr(tok, lit), []string{"CONSTRAINT", ")"}, pos)
+			}
+
+			*ttl = true
 		default:
 			p.Unscan()
 			return nil
@@ -188,6 +233,53 @@ func (p *Parser) parseFieldConstraint(fc *database.FieldConstraint) error {
 	}
 }
 
+// parseGeneratedExpr parses the parenthesized expression of a generated field
+// and returns its SQL text, as written in the statement.
+// This function assumes the AS token has already been consumed.
+func (p *Parser) parseGeneratedExpr() (string, error) {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.LPAREN {
+		return "", newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
+	}
+
+	_, pos, _ := p.ScanIgnoreWhitespace()
+	p.Unscan()
+
+	if p.buf == nil {
+		p.buf = new(bytes.Buffer)
+		defer func() { p.buf = nil }()
+	}
+	start := p.buf.Len()
+
+	e, _, err := p.ParseExpr()
+	if err != nil {
+		return "", err
+	}
+
+	text := strings.TrimSpace(p.buf.String()[start:])
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+		return "", newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+	}
+
+	// the expression is evaluated against each document, on its own
+	expr.Walk(e, func(e expr.Expr) (expr.Expr, bool) {
+		switch e.(type) {
+		case expr.NamedParam, expr.PositionalParam:
+			err = &ParseError{Message: "parameters are not allowed in the expression of a generated field", Pos: pos}
+		case document.AggregatorBuilder:
+			err = &ParseError{Message: "aggregate functions are not allowed in the expression of a generated field", Pos: pos}
+		case expr.Subquery, expr.ExistsExpr:
+			err = &ParseError{Message: "subqueries are not allowed in the expression of a generated field", Pos: pos}
+		}
+		return e, err == nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return text, nil
+}
+
 // parseCreateIndexStatement parses a create index string and returns a Statement AST object.
 // This function assumes the CREATE INDEX or CREATE UNIQUE INDEX tokens have already been consumed.
 func (p *Parser) parseCreateIndexStatement(unique bool) (query.CreateIndexStmt, error) {
@@ -247,3 +339,104 @@ func (p *Parser) parseCreateIndexStatement(unique bool) (query.CreateIndexStmt,
 
 	return stmt, nil
 }
+
+// parseCreateTriggerStatement parses a create trigger string and returns a Statement AST object.
+// This function assumes the CREATE TRIGGER tokens have already been consumed.
+func (p *Parser) parseCreateTriggerStatement() (query.CreateTriggerStmt, error) {
+	var stmt query.CreateTriggerStmt
+	var err error
+
+	// Parse IF NOT EXISTS
+	stmt.IfNotExists, err = p.parseIfNotExists()
+	if err != nil {
+		return stmt, err
+	}
+
+	// Parse trigger name
+	stmt.TriggerName, err = p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"trigger_name"}
+		return stmt, pErr
+	}
+
+	// Parse BEFORE or AFTER
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch {
+	case isKeyword(tok, lit, "BEFORE"):
+		stmt.Timing = database.TriggerBefore
+	case isKeyword(tok, lit, "AFTER"):
+		stmt.Timing = database.TriggerAfter
+	default:
+		return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"BEFORE", "AFTER"}, pos)
+	}
+
+	// Parse the event
+	tok, pos, lit = p.ScanIgnoreWhitespace()
+	switch tok {
+	case scanner.INSERT:
+		stmt.Event = database.TriggerInsert
+	case scanner.UPDATE:
+		stmt.Event = database.TriggerUpdate
+	case scanner.DELETE:
+		stmt.Event = database.TriggerDelete
+	default:
+		return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"INSERT", "UPDATE", "DELETE"}, pos)
+	}
+
+	// Parse "ON"
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.ON {
+		return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"ON"}, pos)
+	}
+
+	// Parse table name
+	stmt.TableName, err = p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"table_name"}
+		return stmt, pErr
+	}
+
+	// Parse optional FOR EACH ROW
+	if tok, _, lit := p.ScanIgnoreWhitespace(); isKeyword(tok, lit, "FOR") {
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); !isKeyword(tok, lit, "EACH") {
+			return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"EACH"}, pos)
+		}
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); !isKeyword(tok, lit, "ROW") {
+			return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"ROW"}, pos)
+		}
+	} else {
+		p.Unscan()
+	}
+
+	stmt.Statement, err = p.parseTriggerStatement()
+	return stmt, err
+}
+
+// parseTriggerStatement parses the statement run by a trigger and returns its SQL.
+// The statement is parsed again every time the trigger fires.
+func (p *Parser) parseTriggerStatement() (string, error) {
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch tok {
+	case scanner.INSERT, scanner.UPDATE, scanner.DELETE:
+	default:
+		return "", newParseError(scanner.Tokstr(tok, lit), []string{"INSERT", "UPDATE", "DELETE"}, pos)
+	}
+	p.Unscan()
+
+	// record the raw tokens of the statement
+	p.buf = new(bytes.Buffer)
+	defer func() { p.buf = nil }()
+
+	params := p.orderedParams + p.namedParams
+	_, err := p.parseStatement()
+	if err != nil {
+		return "", err
+	}
+
+	if p.orderedParams+p.namedParams != params {
+		return "", &ParseError{Message: "trigger statement cannot use parameters", Pos: pos}
+	}
+
+	return strings.TrimSpace(p.buf.String()), nil
+}
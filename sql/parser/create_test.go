@@ -60,6 +60,54 @@ func TestParserCreateTable(t *testing.T) {
 			query.CreateTableStmt{}, true},
 		{"With not null twice", "CREATE TABLE test(foo NOT NULL NOT NULL)",
 			query.CreateTableStmt{}, true},
+		{"With generated", "CREATE TABLE test(price DOUBLE, item.qty INTEGER, total DOUBLE AS (price * item.qty) NOT NULL)",
+			query.CreateTableStmt{
+				TableName: "test",
+				Info: database.TableInfo{
+					FieldConstraints: []database.FieldConstraint{
+						{Path: parsePath(t, "price"), Type: document.DoubleValue},
+						{Path: parsePath(t, "item.qty"), Type: document.IntegerValue},
+						{Path: parsePath(t, "total"), Type: document.DoubleValue, IsNotNull: true, GeneratedExpr: "price * item.qty"},
+					},
+				},
+			}, false},
+		{"With generated quoted field", "CREATE TABLE test(`a b` INTEGER, y AS ( `a b` + 1 ))",
+			query.CreateTableStmt{
+				TableName: "test",
+				Info: database.TableInfo{
+					FieldConstraints: []database.FieldConstraint{
+						{Path: parsePath(t, "`a b`"), Type: document.IntegerValue},
+						{Path: parsePath(t, "y"), GeneratedExpr: "`a b` + 1"},
+					},
+				},
+			}, false},
+		{"With generated twice", "CREATE TABLE test(foo AS (a) AS (b))",
+			query.CreateTableStmt{}, true},
+		{"With generated without parentheses", "CREATE TABLE test(foo AS a + 1)",
+			query.CreateTableStmt{}, true},
+		{"With generated param", "CREATE TABLE test(foo AS (a + ?))",
+			query.CreateTableStmt{}, true},
+		{"With generated aggregate", "CREATE TABLE test(foo AS (COUNT(a)))",
+			query.CreateTableStmt{}, true},
+		{"With generated subquery", "CREATE TABLE test(foo AS (a + (SELECT COUNT(*) FROM bar)))",
+			query.CreateTableStmt{}, true},
+		{"With generated exists", "CREATE TABLE test(foo AS (NOT EXISTS (SELECT * FROM bar)))",
+			query.CreateTableStmt{}, true},
+		{"With TTL", "CREATE TABLE test(ttl TEXT, expires_at INTEGER ttl NOT NULL)",
+			query.CreateTableStmt{
+				TableName: "test",
+				Info: database.TableInfo{
+					FieldConstraints: []database.FieldConstraint{
+						{Path: parsePath(t, "ttl"), Type: document.TextValue},
+						{Path: parsePath(t, "expires_at"), Type: document.IntegerValue, IsNotNull: true},
+					},
+					TTLPath: parsePath(t, "expires_at"),
+				},
+			}, false},
+		{"With TTL twice", "CREATE TABLE test(foo TTL TTL)",
+			query.CreateTableStmt{}, true},
+		{"With two TTL fields", "CREATE TABLE test(foo TTL, bar TTL)",
+			query.CreateTableStmt{}, true},
 		{"With type and not null", "CREATE TABLE test(foo INTEGER NOT NULL)",
 			query.CreateTableStmt{
 				TableName: "test",
@@ -221,3 +269,42 @@ func TestParserCreateIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestParserCreateTrigger(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		expected query.Statement
+		errored  bool
+	}{
+		{"Basic", "CREATE TRIGGER log_update AFTER UPDATE ON users FOR EACH ROW INSERT INTO audit VALUES {new: new_doc, old: old_doc}",
+			query.CreateTriggerStmt{TriggerName: "log_update", TableName: "users", Timing: database.TriggerAfter, Event: database.TriggerUpdate,
+				Statement: "INSERT INTO audit VALUES {new: new_doc, old: old_doc}"}, false},
+		{"If not exists", "CREATE TRIGGER IF NOT EXISTS trg BEFORE INSERT ON users UPDATE stats SET count = count + 1",
+			query.CreateTriggerStmt{TriggerName: "trg", TableName: "users", Timing: database.TriggerBefore, Event: database.TriggerInsert, IfNotExists: true,
+				Statement: "UPDATE stats SET count = count + 1"}, false},
+		{"Delete", "CREATE TRIGGER trg AFTER DELETE ON users FOR EACH ROW DELETE FROM sessions WHERE user_id = old_doc.id; SELECT 1",
+			query.CreateTriggerStmt{TriggerName: "trg", TableName: "users", Timing: database.TriggerAfter, Event: database.TriggerDelete,
+				Statement: "DELETE FROM sessions WHERE user_id = old_doc.id"}, false},
+		{"No timing", "CREATE TRIGGER trg UPDATE ON users DELETE FROM foo", nil, true},
+		{"No table", "CREATE TRIGGER trg AFTER UPDATE DELETE FROM foo", nil, true},
+		{"Incomplete FOR EACH ROW", "CREATE TRIGGER trg AFTER UPDATE ON users FOR ROW DELETE FROM foo", nil, true},
+		{"No statement", "CREATE TRIGGER trg AFTER UPDATE ON users FOR EACH ROW", nil, true},
+		{"Select statement", "CREATE TRIGGER trg AFTER UPDATE ON users SELECT * FROM foo", nil, true},
+		{"Invalid statement", "CREATE TRIGGER trg AFTER UPDATE ON users DELETE foo", nil, true},
+		{"Params", "CREATE TRIGGER trg AFTER UPDATE ON users DELETE FROM foo WHERE a = ?", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := ParseQuery(test.s)
+			if test.errored {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, len(q.Statements), 1)
+			require.EqualValues(t, test.expected, q.Statements[0])
+		})
+	}
+}
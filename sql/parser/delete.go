@@ -9,7 +9,7 @@ import (
 // parseDeleteStatement parses a delete string and returns a Statement AST object.
 // This function assumes the DELETE token has already been consumed.
 func (p *Parser) parseDeleteStatement() (*planner.Tree, error) {
-	var cfg deleteConfig
+	var cfg DeleteConfig
 	var err error
 
 	// Parse "FROM".
@@ -25,23 +25,35 @@ func (p *Parser) parseDeleteStatement() (*planner.Tree, error) {
 		return nil, pErr
 	}
 
+	err = p.parseNoSample("DELETE")
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse condition: "WHERE EXPR".
 	cfg.WhereExpr, err = p.parseCondition()
 	if err != nil {
 		return nil, err
 	}
 
+	// Parse returned fields: "RETURNING fields".
+	cfg.Returning, err = p.parseReturning()
+	if err != nil {
+		return nil, err
+	}
+
 	return cfg.ToTree(), nil
 }
 
 // DeleteConfig holds DELETE configuration.
-type deleteConfig struct {
+type DeleteConfig struct {
 	TableName string
 	WhereExpr expr.Expr
+	Returning []planner.ProjectedField
 }
 
 // ToTree turns the statement into an expression tree.
-func (cfg deleteConfig) ToTree() *planner.Tree {
+func (cfg DeleteConfig) ToTree() *planner.Tree {
 	t := planner.NewTableInputNode(cfg.TableName)
 
 	if cfg.WhereExpr != nil {
@@ -50,5 +62,9 @@ func (cfg deleteConfig) ToTree() *planner.Tree {
 
 	t = planner.NewDeletionNode(t, cfg.TableName)
 
+	if cfg.Returning != nil {
+		t = planner.NewReturningNode(t, cfg.Returning, cfg.TableName)
+	}
+
 	return &planner.Tree{Root: t}
 }
@@ -24,6 +24,18 @@ func TestParserDelete(t *testing.T) {
 					planner.NewTableInputNode("test"),
 					expr.Eq(expr.Path(parsePath(t, "age")), expr.IntegerValue(10))),
 				"test"))},
+		{"WithReturning", "DELETE FROM test WHERE age = 10 RETURNING *, pk() AS id",
+			planner.NewTree(planner.NewReturningNode(
+				planner.NewDeletionNode(
+					planner.NewSelectionNode(
+						planner.NewTableInputNode("test"),
+						expr.Eq(expr.Path(parsePath(t, "age")), expr.IntegerValue(10))),
+					"test"),
+				[]planner.ProjectedField{
+					planner.Wildcard{},
+					planner.ProjectedExpr{Expr: &expr.PKFunc{}, ExprName: "id"},
+				},
+				"test"))},
 	}
 
 	for _, test := range tests {
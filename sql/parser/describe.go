@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+// parseDescribeStatement parses a describe string and returns a Statement AST object.
+// This function assumes the DESCRIBE token has already been consumed.
+func (p *Parser) parseDescribeStatement() (query.DescribeStmt, error) {
+	var stmt query.DescribeStmt
+	var err error
+
+	// Parse table name
+	stmt.TableName, err = p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"table_name"}
+		return stmt, pErr
+	}
+
+	return stmt, nil
+}
+
+// parseShowStatement parses a show string and returns a Statement AST object.
+// This function assumes the SHOW token has already been consumed.
+func (p *Parser) parseShowStatement() (query.Statement, error) {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); !isKeyword(tok, lit, "TABLES") {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLES"}, pos)
+	}
+
+	return query.ShowTablesStmt{}, nil
+}
@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/sql/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserDescribe(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		expected query.Statement
+		errored  bool
+	}{
+		{"Basic", "DESCRIBE test", query.DescribeStmt{TableName: "test"}, false},
+		{"Quoted", "DESCRIBE `my table`", query.DescribeStmt{TableName: "my table"}, false},
+		{"Show tables", "SHOW TABLES", query.ShowTablesStmt{}, false},
+		{"Lowercase", "show tables", query.ShowTablesStmt{}, false},
+		{"No table", "DESCRIBE", nil, true},
+		{"With extra", "DESCRIBE test test", nil, true},
+		{"Show without tables", "SHOW", nil, true},
+		{"Show table", "SHOW TABLE test", nil, true},
+		{"Show tables with extra", "SHOW TABLES test", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := ParseQuery(test.s)
+			if test.errored {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, q.Statements, 1)
+			require.EqualValues(t, test.expected, q.Statements[0])
+		})
+	}
+}
@@ -9,14 +9,16 @@ import (
 // This function assumes the DROP token has already been consumed.
 func (p *Parser) parseDropStatement() (query.Statement, error) {
 	tok, pos, lit := p.ScanIgnoreWhitespace()
-	switch tok {
-	case scanner.TABLE:
+	switch {
+	case tok == scanner.TABLE:
 		return p.parseDropTableStatement()
-	case scanner.INDEX:
+	case tok == scanner.INDEX:
 		return p.parseDropIndexStatement()
+	case isKeyword(tok, lit, "TRIGGER"):
+		return p.parseDropTriggerStatement()
 	}
 
-	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLE", "INDEX"}, pos)
+	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLE", "INDEX", "TRIGGER"}, pos)
 }
 
 // parseDropTableStatement parses a drop table string and returns a Statement AST object.
@@ -74,3 +76,31 @@ func (p *Parser) parseDropIndexStatement() (query.DropIndexStmt, error) {
 
 	return stmt, nil
 }
+
+// parseDropTriggerStatement parses a drop trigger string and returns a Statement AST object.
+// This function assumes the DROP TRIGGER tokens have already been consumed.
+func (p *Parser) parseDropTriggerStatement() (query.DropTriggerStmt, error) {
+	var stmt query.DropTriggerStmt
+	var err error
+
+	// Parse "IF"
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.IF {
+		// Parse "EXISTS"
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.EXISTS {
+			return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"EXISTS"}, pos)
+		}
+		stmt.IfExists = true
+	} else {
+		p.Unscan()
+	}
+
+	// Parse trigger name
+	stmt.TriggerName, err = p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"trigger_name"}
+		return stmt, pErr
+	}
+
+	return stmt, nil
+}
@@ -16,7 +16,7 @@ func (p *Parser) parseExplainStatement() (query.Statement, error) {
 	}
 	p.Unscan()
 
-	innerStmt, err := p.ParseStatement()
+	innerStmt, err := p.parseStatement()
 	if err != nil {
 		return nil, err
 	}
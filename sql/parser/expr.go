@@ -3,6 +3,7 @@ package parser
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -57,10 +58,23 @@ func (p *Parser) ParseExpr() (e expr.Expr, lit string, err error) {
 
 		var rhs expr.Expr
 
+		// patterns of regular expressions are validated when they are literals
+		var rhsPos scanner.Pos
+		if tok == scanner.REGEXP {
+			_, rhsPos, _ = p.ScanIgnoreWhitespace()
+			p.Unscan()
+		}
+
 		if rhs, err = p.parseUnaryExpr(); err != nil {
 			return nil, "", err
 		}
 
+		if tok == scanner.REGEXP {
+			if err := validateRegexp(rhs, rhsPos); err != nil {
+				return nil, "", err
+			}
+		}
+
 		// Find the right spot in the tree to add the new expression by
 		// descending the RHS of the expression tree until we reach the last
 		// BinaryExpr or a BinaryExpr whose RHS has an operator with
@@ -78,16 +92,35 @@ func (p *Parser) ParseExpr() (e expr.Expr, lit string, err error) {
 }
 
 func (p *Parser) parseOperator() (func(lhs, rhs expr.Expr) expr.Expr, scanner.Token, error) {
-	op, _, _ := p.ScanIgnoreWhitespace()
+	op, pos, lit := p.ScanIgnoreWhitespace()
+	// REGEXP is not a keyword, to allow using it as a field name.
+	if isKeyword(op, lit, "REGEXP") {
+		op = scanner.REGEXP
+	}
 	if !op.IsOperator() && op != scanner.NOT {
 		p.Unscan()
 		return nil, 0, nil
 	}
 
-	// Ignore currently unused operators.
-	if op == scanner.EQREGEX || op == scanner.NEQREGEX {
+	if p.restrictExpr {
+		switch op {
+		case scanner.ADD, scanner.SUB, scanner.MUL, scanner.DIV, scanner.MOD,
+			scanner.BITWISEAND, scanner.BITWISEOR, scanner.BITWISEXOR:
+			return nil, 0, &ParseError{Message: fmt.Sprintf("operator %s is not allowed", op), Pos: pos}
+		}
+	}
+
+	switch op {
+	case scanner.EQ, scanner.NEQ, scanner.GT, scanner.GTE, scanner.LT, scanner.LTE:
+		// comparisons can be quantified: a < ANY (b)
+		tok, _, lit := p.ScanIgnoreWhitespace()
+		switch {
+		case isKeyword(tok, lit, "ANY") && p.quantifiesNext():
+			return func(lhs, rhs expr.Expr) expr.Expr { return expr.Any(op, lhs, rhs) }, op, nil
+		case isKeyword(tok, lit, "ALL") && p.quantifiesNext():
+			return func(lhs, rhs expr.Expr) expr.Expr { return expr.All(op, lhs, rhs) }, op, nil
+		}
 		p.Unscan()
-		return nil, 0, nil
 	}
 
 	switch op {
@@ -133,34 +166,115 @@ func (p *Parser) parseOperator() (func(lhs, rhs expr.Expr) expr.Expr, scanner.To
 		return expr.Is, op, nil
 	case scanner.NOT:
 		tok, pos, lit := p.ScanIgnoreWhitespace()
-		switch tok {
-		case scanner.IN:
+		switch {
+		case tok == scanner.IN:
 			return expr.NotIn, op, nil
-		case scanner.LIKE:
+		case tok == scanner.LIKE:
 			return expr.NotLike, op, nil
+		case isKeyword(tok, lit, "REGEXP"):
+			return expr.NotRegexp, scanner.REGEXP, nil
 		}
 
-		return nil, 0, newParseError(scanner.Tokstr(tok, lit), []string{"IN, LIKE"}, pos)
+		return nil, 0, newParseError(scanner.Tokstr(tok, lit), []string{"IN, LIKE, REGEXP"}, pos)
 	case scanner.LIKE:
 		return expr.Like, op, nil
+	case scanner.CONTAINS:
+		return expr.Contains, op, nil
+	case scanner.REGEXP, scanner.EQREGEX:
+		return expr.Regexp, scanner.REGEXP, nil
+	case scanner.NEQREGEX:
+		return expr.NotRegexp, scanner.REGEXP, nil
 	}
 
 	panic(fmt.Sprintf("unknown operator %q", op))
 }
 
+// quantifiesNext reports whether the ANY or ALL identifier that was just scanned
+// quantifies the expression that follows it, which is either parenthesized or
+// an array separated by a whitespace. Otherwise, like in any[0], it is a field name.
+// The tokens scanned after the identifier are pushed back onto the buffer.
+func (p *Parser) quantifiesNext() bool {
+	tok, _, _ := p.Scan()
+	ok := tok == scanner.LPAREN
+	if tok == scanner.WS {
+		tok, _, _ = p.Scan()
+		ok = tok == scanner.LPAREN || tok == scanner.LSBRACKET
+		p.Unscan()
+	}
+	p.Unscan()
+
+	return ok
+}
+
+// validateRegexp returns an error if e is a text literal which is not a valid regular expression.
+func validateRegexp(e expr.Expr, pos scanner.Pos) error {
+	lv, ok := e.(expr.LiteralValue)
+	if !ok || lv.Type != document.TextValue {
+		return nil
+	}
+
+	_, err := regexp.Compile(lv.V.(string))
+	if err != nil {
+		return &ParseError{Message: err.Error(), Pos: pos}
+	}
+
+	return nil
+}
+
+// variableValue returns the value at path p of a document referenced by a variable.
+func variableValue(d document.Document, p document.Path) expr.LiteralValue {
+	if d == nil {
+		return expr.LiteralValue(document.NewNullValue())
+	}
+
+	if len(p) == 0 {
+		return expr.LiteralValue(document.NewDocumentValue(d))
+	}
+
+	v, err := p.GetValue(d)
+	if err != nil {
+		return expr.LiteralValue(document.NewNullValue())
+	}
+
+	return expr.LiteralValue(v)
+}
+
 // parseUnaryExpr parses an non-binary expression.
 func (p *Parser) parseUnaryExpr() (expr.Expr, error) {
 	tok, pos, lit := p.ScanIgnoreWhitespace()
 	switch tok {
 	case scanner.CAST:
+		if p.restrictExpr {
+			return nil, &ParseError{Message: "CAST is not allowed", Pos: pos}
+		}
 		p.Unscan()
 		return p.parseCastExpression()
 	case scanner.IDENT:
+		// quoted identifiers always refer to fields, not to variables
+		quoted := p.isQuotedIdent()
+
 		// if the next token is a left parenthesis, this is a function
 		if tok1, _, _ := p.Scan(); tok1 == scanner.LPAREN {
+			if p.restrictExpr {
+				return nil, &ParseError{Message: fmt.Sprintf("function %s is not allowed", lit), Pos: pos}
+			}
 			p.Unscan()
 			p.Unscan()
-			return p.parseFunction()
+			e, err := p.parseFunction()
+			if err != nil {
+				return nil, err
+			}
+
+			// the result of the function can be followed by a path, like f(x).a
+			path, err := p.parsePathFragments(nil)
+			if err != nil {
+				return nil, err
+			}
+			if len(path) > 0 {
+				return expr.PathAccessExpr{E: e, Path: expr.Path(path)}, nil
+			}
+
+			return e, nil
 		}
 		p.Unscan()
 		p.Unscan()
@@ -168,23 +282,25 @@ func (p *Parser) parseUnaryExpr() (expr.Expr, error) {
 		if err != nil {
 			return nil, err
 		}
+		if d, ok := p.variables[field[0].FieldName]; ok && !quoted {
+			return variableValue(d, field[1:]), nil
+		}
 		fs := expr.Path(field)
 		return fs, nil
 	case scanner.NAMEDPARAM:
 		if len(lit) == 1 {
 			return nil, &ParseError{Message: "missing param name"}
 		}
-		if p.orderedParams > 0 {
-			return nil, &ParseError{Message: "cannot mix positional arguments with named arguments"}
+		if err := p.addNamedParam(lit[1:], pos); err != nil {
+			return nil, err
 		}
-		p.namedParams++
 		return expr.NamedParam(lit[1:]), nil
 	case scanner.POSITIONALPARAM:
-		if p.namedParams > 0 {
-			return nil, &ParseError{Message: "cannot mix positional arguments with named arguments"}
+		n, err := p.addPositionalParam(pos)
+		if err != nil {
+			return nil, err
 		}
-		p.orderedParams++
-		return expr.PositionalParam(p.orderedParams), nil
+		return expr.PositionalParam(n), nil
 	case scanner.STRING:
 		return expr.TextValue(lit), nil
 	case scanner.NUMBER:
@@ -212,9 +328,28 @@ func (p *Parser) parseUnaryExpr() (expr.Expr, error) {
 		e, err := p.parseDocument()
 		return e, err
 	case scanner.LSBRACKET:
-		p.Unscan()
-		return p.parseExprList(scanner.LSBRACKET, scanner.RSBRACKET)
+		return p.parseArrayUntilEnd()
+	case scanner.TYPEARRAY:
+		// ARRAY[...] is the standard SQL syntax for [...]
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.LSBRACKET {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"["}, pos)
+		}
+		return p.parseArrayUntilEnd()
+	case scanner.EXISTS:
+		return p.parseExists(false)
+	case scanner.NOT:
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.EXISTS {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"EXISTS"}, pos)
+		}
+		return p.parseExists(true)
 	case scanner.LPAREN:
+		start := p.buf.Len()
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.SELECT {
+			p.Unscan()
+			return p.parseSubqueryUntilEnd(start, pos)
+		}
+		p.Unscan()
+
 		e, _, err := p.ParseExpr()
 		if err != nil {
 			return nil, err
@@ -231,8 +366,7 @@ func (p *Parser) parseUnaryExpr() (expr.Expr, error) {
 			}
 
 			// prepend first parsed expression
-			exprList = append([]expr.Expr{e}, exprList...)
-			return exprList, nil
+			return expr.TupleExpr{Elements: append([]expr.Expr{e}, exprList...)}, nil
 		}
 
 		return nil, newParseError(scanner.Tokstr(tok, lit), []string{")", ","}, pos)
@@ -251,6 +385,12 @@ func (p *Parser) parseIdent() (string, error) {
 	return lit, nil
 }
 
+// newNullFieldNameError returns the error reported when the NULL keyword
+// is used as a field name, which must be quoted like `null`.
+func newNullFieldNameError(pos scanner.Pos) *ParseError {
+	return &ParseError{Message: "NULL is a reserved word: quote it with backquotes to use it as a field name", Pos: pos}
+}
+
 // parseIdentList parses a comma delimited list of identifiers.
 func (p *Parser) parseIdentList() ([]string, error) {
 	// Parse first (required) identifier.
@@ -277,55 +417,103 @@ func (p *Parser) parseIdentList() ([]string, error) {
 
 // parseParam parses a positional or named param.
 func (p *Parser) parseParam() (expr.Expr, error) {
-	tok, _, lit := p.ScanIgnoreWhitespace()
+	tok, pos, lit := p.ScanIgnoreWhitespace()
 	switch tok {
 	case scanner.NAMEDPARAM:
 		if len(lit) == 1 {
 			return nil, &ParseError{Message: "missing param name"}
 		}
-		if p.orderedParams > 0 {
-			return nil, &ParseError{Message: "cannot mix positional arguments with named arguments"}
+		if err := p.addNamedParam(lit[1:], pos); err != nil {
+			return nil, err
 		}
-		p.namedParams++
 		return expr.NamedParam(lit[1:]), nil
 	case scanner.POSITIONALPARAM:
-		if p.namedParams > 0 {
-			return nil, &ParseError{Message: "cannot mix positional arguments with named arguments"}
+		n, err := p.addPositionalParam(pos)
+		if err != nil {
+			return nil, err
 		}
-		p.orderedParams++
-		return expr.PositionalParam(p.orderedParams), nil
+		return expr.PositionalParam(n), nil
 	default:
 		return nil, nil
 	}
 }
 
+// typeTokens associates the tokens denoting a type with the value type they represent.
+// Types requiring extra tokens, like DOUBLE PRECISION or VARCHAR(n), are further handled by parseType.
+var typeTokens = map[scanner.Token]document.ValueType{
+	scanner.TYPEARRAY:     document.ArrayValue,
+	scanner.TYPEBIGINT:    document.IntegerValue,
+	scanner.TYPEBLOB:      document.BlobValue,
+	scanner.TYPEBOOL:      document.BoolValue,
+	scanner.TYPEBYTES:     document.BlobValue,
+	scanner.TYPECHARACTER: document.TextValue,
+	scanner.TYPEDOCUMENT:  document.DocumentValue,
+	scanner.TYPEDOUBLE:    document.DoubleValue,
+	scanner.TYPEINT:       document.IntegerValue,
+	scanner.TYPEINT2:      document.IntegerValue,
+	scanner.TYPEINT8:      document.IntegerValue,
+	scanner.TYPEINTEGER:   document.IntegerValue,
+	scanner.TYPEMEDIUMINT: document.IntegerValue,
+	scanner.TYPESMALLINT:  document.IntegerValue,
+	scanner.TYPETEXT:      document.TextValue,
+	scanner.TYPETINYINT:   document.IntegerValue,
+	scanner.TYPEREAL:      document.DoubleValue,
+	scanner.TYPEVARCHAR:   document.TextValue,
+}
+
+// integerTypeNames associates the names of the integer types having a width in bits,
+// which are not keywords to allow using them as field names, with that width.
+var integerTypeNames = map[string]int{
+	"INT16": 16,
+	"INT32": 32,
+	"INT64": 64,
+}
+
+// integerBitSizes associates the integer types having an explicit width
+// with their size in bits. Casting to these types fails if the value overflows.
+// Like for INT16, INT32 and INT64, the width of INT8 is in bits.
+// INT2 is an alias of INTEGER, without width, kept for compatibility.
+var integerBitSizes = map[scanner.Token]int{
+	scanner.TYPETINYINT:   8,
+	scanner.TYPESMALLINT:  16,
+	scanner.TYPEMEDIUMINT: 24,
+	scanner.TYPEINT8:      8,
+}
+
+// isType reports whether the token denotes a type.
+func isType(tok scanner.Token, lit string) bool {
+	_, ok := typeTokens[tok]
+	return ok || integerBitSize(tok, lit) != 0
+}
+
+// integerBitSize returns the width in bits of the integer type denoted by the token,
+// or 0 if it doesn't denote an integer type having an explicit width.
+func integerBitSize(tok scanner.Token, lit string) int {
+	if tok == scanner.IDENT {
+		return integerTypeNames[strings.ToUpper(lit)]
+	}
+
+	return integerBitSizes[tok]
+}
+
+// parseType parses a type name and returns the value type it denotes.
+// It returns a ParseError if the next token is not a type.
 func (p *Parser) parseType() (document.ValueType, error) {
-	tok, _, _ := p.ScanIgnoreWhitespace()
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	tp, ok := typeTokens[tok]
+	if !ok && integerBitSize(tok, lit) != 0 {
+		tp, ok = document.IntegerValue, true
+	}
+	if !ok {
+		return 0, newParseError(scanner.Tokstr(tok, lit), []string{"type"}, pos)
+	}
+
 	switch tok {
-	case scanner.TYPEARRAY:
-		return document.ArrayValue, nil
-	case scanner.TYPEBLOB:
-		return document.BlobValue, nil
-	case scanner.TYPEBOOL:
-		return document.BoolValue, nil
-	case scanner.TYPEBYTES:
-		return document.BlobValue, nil
-	case scanner.TYPEDOCUMENT:
-		return document.DocumentValue, nil
-	case scanner.TYPEREAL:
-		return document.DoubleValue, nil
 	case scanner.TYPEDOUBLE:
-		tok, _, _ := p.ScanIgnoreWhitespace()
-		if tok == scanner.PRECISION {
-			return document.DoubleValue, nil
+		// PRECISION is optional.
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.PRECISION {
+			p.Unscan()
 		}
-		p.Unscan()
-		return document.DoubleValue, nil
-	case scanner.TYPEINTEGER, scanner.TYPEINT, scanner.TYPEINT2, scanner.TYPEINT8, scanner.TYPETINYINT,
-		scanner.TYPEBIGINT, scanner.TYPEMEDIUMINT, scanner.TYPESMALLINT:
-		return document.IntegerValue, nil
-	case scanner.TYPETEXT:
-		return document.TextValue, nil
 	case scanner.TYPEVARCHAR, scanner.TYPECHARACTER:
 		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.LPAREN {
 			return 0, newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
@@ -339,29 +527,46 @@ func (p *Parser) parseType() (document.ValueType, error) {
 		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
 			return 0, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
 		}
-
-		return document.TextValue, nil
 	}
 
-	p.Unscan()
-	return 0, nil
+	return tp, nil
 }
 
 // parseDocument parses a document
 func (p *Parser) parseDocument() (expr.Expr, error) {
 	// Parse { token.
-	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.LBRACKET {
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != scanner.LBRACKET {
 		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"{"}, pos)
 	}
 
+	if p.dialect == DialectStandard {
+		return nil, &ParseError{Message: "document literals are not allowed in standard SQL", Pos: pos}
+	}
+
 	var pairs expr.KVPairs
 	var pair expr.KVPair
 	var err error
 
-	// Parse kv pairs.
+	// Parse kv pairs and spread pairs.
 	for {
-		if pair, err = p.parseKV(); err != nil {
+		tok, pos, _ := p.ScanIgnoreWhitespace()
+		switch tok {
+		case scanner.SPREAD:
+			e, _, err := p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+			pair = expr.SpreadPair(e)
+		case scanner.NULL:
+			return nil, newNullFieldNameError(pos)
+		default:
 			p.Unscan()
+			if pair, err = p.parseKV(); err != nil {
+				p.Unscan()
+			}
+		}
+		if err != nil {
 			break
 		}
 
@@ -412,6 +617,11 @@ func (p *Parser) parseKV() (expr.KVPair, error) {
 // parsePath parses a path to a specific value.
 func (p *Parser) parsePath() (document.Path, error) {
 	var path document.Path
+	if tok, pos, _ := p.ScanIgnoreWhitespace(); tok == scanner.NULL {
+		return nil, newNullFieldNameError(pos)
+	}
+	p.Unscan()
+
 	// parse first mandatory ident
 	chunk, err := p.parseIdent()
 	if err != nil {
@@ -421,18 +631,31 @@ func (p *Parser) parsePath() (document.Path, error) {
 		FieldName: chunk,
 	})
 
+	return p.parsePathFragments(path)
+}
+
+// parsePathFragments parses the fragments following a path or a function call,
+// like .a or [1], and appends them to path.
+func (p *Parser) parsePathFragments(path document.Path) (document.Path, error) {
 LOOP:
 	for {
 		// scan the very next token.
 		// if can be either a '.' or a '['
 		// Otherwise, unscan and return the path
-		tok, _, _ := p.Scan()
+		tok, pos, _ := p.Scan()
+		if (tok == scanner.DOT || tok == scanner.LSBRACKET) && p.dialect == DialectStandard {
+			return nil, &ParseError{Message: "nested field references are not allowed in standard SQL", Pos: pos}
+		}
+
 		switch tok {
 		case scanner.DOT:
 			// scan the next token for an ident
 			tok, pos, lit := p.Scan()
+			if tok == scanner.NULL {
+				return nil, newNullFieldNameError(pos)
+			}
 			if tok != scanner.IDENT {
-				return nil, newParseError(lit, []string{"identifier"}, pos)
+				return nil, newParseError(scanner.Tokstr(tok, lit), []string{"identifier"}, pos)
 			}
 			path = append(path, document.PathFragment{
 				FieldName: lit,
@@ -492,6 +715,49 @@ func (p *Parser) parseExprListUntil(rightToken scanner.Token) (expr.LiteralExprL
 	return exprList, nil
 }
 
+// parseArrayUntilEnd parses the elements of an array literal and the closing ] token.
+// Elements prefixed by ... are parsed as spread expressions.
+// This function assumes the [ token has already been consumed.
+func (p *Parser) parseArrayUntilEnd() (expr.LiteralExprList, error) {
+	var exprList expr.LiteralExprList
+
+	// Parse elements.
+	for {
+		spread := false
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.SPREAD {
+			spread = true
+		} else {
+			p.Unscan()
+		}
+
+		e, _, err := p.ParseExpr()
+		if err != nil {
+			if spread {
+				return nil, err
+			}
+			p.Unscan()
+			break
+		}
+
+		if spread {
+			e = expr.SpreadExpr{E: e}
+		}
+		exprList = append(exprList, e)
+
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
+			p.Unscan()
+			break
+		}
+	}
+
+	// Parse required ] token.
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RSBRACKET {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"]"}, pos)
+	}
+
+	return exprList, nil
+}
+
 func (p *Parser) parseExprList(leftToken, rightToken scanner.Token) (expr.LiteralExprList, error) {
 	// Parse ( or [ token.
 	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != leftToken {
@@ -581,21 +847,17 @@ func (p *Parser) parseCastExpression() (expr.Expr, error) {
 	}
 
 	// Parse require typename.
+	tok, _, lit := p.ScanIgnoreWhitespace()
+	p.Unscan()
 	tp, err := p.parseType()
 	if err != nil {
 		return nil, err
 	}
 
-	if tp == 0 {
-		tok, pos, lit := p.ScanIgnoreWhitespace()
-		p.Unscan()
-		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"type"}, pos)
-	}
-
 	// Parse required ) token.
 	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
 		return nil, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
 	}
 
-	return expr.CastFunc{Expr: e, CastAs: tp}, nil
+	return expr.CastFunc{Expr: e, CastAs: tp, BitSize: integerBitSize(tok, lit)}, nil
 }
@@ -1,11 +1,13 @@
 package parser
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/sql/scanner"
 	"github.com/stretchr/testify/require"
 )
 
@@ -75,6 +77,12 @@ func TestParserExpr(t *testing.T) {
 				expr.KVPair{K: "a", V: expr.IntegerValue(3)},
 			},
 			false},
+		{"document with null key", `{"null": 1, ` + "`null`" + `: 2}`,
+			expr.KVPairs{
+				expr.KVPair{K: "null", V: expr.IntegerValue(1)},
+				expr.KVPair{K: "null", V: expr.IntegerValue(2)},
+			}, false},
+		{"bad document keys: null", `{null: 1}`, nil, true},
 		{"bad document keys: param", `{?: 1}`, nil, true},
 		{"bad document keys: dot", `{a.b: 1}`, nil, true},
 		{"bad document keys: space", `{a b: 1}`, nil, true},
@@ -113,6 +121,32 @@ func TestParserExpr(t *testing.T) {
 				expr.LiteralExprList{expr.IntegerValue(-1)},
 			}, false},
 		{"list with brackets: missing bracket", `[1, true, {a: 1}, a.b.c, (-1), [-1]`, nil, true},
+		{"list with spread", "[...a, 4, ...[5, b]]",
+			expr.LiteralExprList{
+				expr.SpreadExpr{E: expr.Path(parsePath(t, "a"))},
+				expr.IntegerValue(4),
+				expr.SpreadExpr{E: expr.LiteralExprList{expr.IntegerValue(5), expr.Path(parsePath(t, "b"))}},
+			}, false},
+		{"list with ARRAY: spread", "ARRAY[1, ...a.b]", expr.LiteralExprList{expr.IntegerValue(1), expr.SpreadExpr{E: expr.Path(parsePath(t, "a.b"))}}, false},
+		{"list with spread: missing expression", "[1, ...]", nil, true},
+		{"spread outside of a list", "(...a)", nil, true},
+		{"document with spread", "{...a, b: 2, ...{c: 3}}",
+			expr.KVPairs{
+				expr.SpreadPair(expr.Path(parsePath(t, "a"))),
+				expr.KVPair{K: "b", V: expr.IntegerValue(2)},
+				expr.SpreadPair(expr.KVPairs{expr.KVPair{K: "c", V: expr.IntegerValue(3)}}),
+			}, false},
+		{"document with spread: missing expression", "{...}", nil, true},
+		{"list with ARRAY: empty", "ARRAY[]", expr.LiteralExprList(nil), false},
+		{"list with ARRAY: values", "ARRAY[1, 2]", expr.LiteralExprList{expr.IntegerValue(1), expr.IntegerValue(2)}, false},
+		{"list with ARRAY: nested", "array[ARRAY[1], [2]]",
+			expr.LiteralExprList{
+				expr.LiteralExprList{expr.IntegerValue(1)},
+				expr.LiteralExprList{expr.IntegerValue(2)},
+			}, false},
+		{"list with ARRAY: missing brackets", "ARRAY 1", nil, true},
+		{"list with ARRAY: parentheses", "ARRAY(1, 2)", nil, true},
+		{"list with ARRAY: alone", "ARRAY", nil, true},
 
 		// operators
 		{"=", "age = 10", expr.Eq(expr.Path(parsePath(t, "age")), expr.IntegerValue(10)), false},
@@ -130,6 +164,23 @@ func TestParserExpr(t *testing.T) {
 		{"IN", "age IN ages", expr.In(expr.Path(parsePath(t, "age")), expr.Path(parsePath(t, "ages"))), false},
 		{"IS", "age IS NULL", expr.Is(expr.Path(parsePath(t, "age")), expr.NullValue()), false},
 		{"IS NOT", "age IS NOT NULL", expr.IsNot(expr.Path(parsePath(t, "age")), expr.NullValue()), false},
+		{"REGEXP", "a REGEXP 'f.o'", expr.Regexp(expr.Path(parsePath(t, "a")), expr.TextValue("f.o")), false},
+		{"NOT REGEXP", "a NOT REGEXP 'f.o'", expr.NotRegexp(expr.Path(parsePath(t, "a")), expr.TextValue("f.o")), false},
+		{"=~", "a =~ 'f.o'", expr.Regexp(expr.Path(parsePath(t, "a")), expr.TextValue("f.o")), false},
+		{"!~", "a !~ 'f.o'", expr.NotRegexp(expr.Path(parsePath(t, "a")), expr.TextValue("f.o")), false},
+		{"REGEXP with AND", "a REGEXP ? AND b NOT REGEXP 'x'",
+			expr.And(
+				expr.Regexp(expr.Path(parsePath(t, "a")), expr.PositionalParam(1)),
+				expr.NotRegexp(expr.Path(parsePath(t, "b")), expr.TextValue("x")),
+			), false},
+		{"REGEXP with invalid pattern", "a REGEXP 'f('", nil, true},
+		{"NOT REGEXP with invalid pattern", "a NOT REGEXP '[a'", nil, true},
+		{"@>", "a @> {b: 1}", expr.Contains(expr.Path(parsePath(t, "a")), expr.KVPairs{expr.KVPair{K: "b", V: expr.IntegerValue(1)}}), false},
+		{"@> with AND", "a @> [1] AND b = 2",
+			expr.And(
+				expr.Contains(expr.Path(parsePath(t, "a")), expr.LiteralExprList{expr.IntegerValue(1)}),
+				expr.Eq(expr.Path(parsePath(t, "b")), expr.IntegerValue(2)),
+			), false},
 		{"precedence", "4 > 1 + 2", expr.Gt(
 			expr.IntegerValue(4),
 			expr.Add(
@@ -159,7 +210,39 @@ func TestParserExpr(t *testing.T) {
 		{"pk() function", "pk()", &expr.PKFunc{}, false},
 		{"count(expr) function", "count(a)", &expr.CountFunc{Expr: expr.Path(parsePath(t, "a"))}, false},
 		{"count(*) function", "count(*)", &expr.CountFunc{Wildcard: true}, false},
+		{"function with index", "json_extract(a, '$.b')[0]",
+			expr.PathAccessExpr{
+				E:    expr.JSONExtractFunc{Expr: expr.Path(parsePath(t, "a")), Path: expr.TextValue("$.b")},
+				Path: expr.Path{document.PathFragment{ArrayIndex: 0}},
+			}, false},
+		{"function with field", "json_extract(a, '$.b').c[1].d",
+			expr.PathAccessExpr{
+				E:    expr.JSONExtractFunc{Expr: expr.Path(parsePath(t, "a")), Path: expr.TextValue("$.b")},
+				Path: expr.Path(parsePath(t, "c[1].d")),
+			}, false},
+		{"function with path in comparison", "typeof(a).b = 1",
+			expr.Eq(
+				expr.PathAccessExpr{E: expr.TypeOfFunc{Expr: expr.Path(parsePath(t, "a"))}, Path: expr.Path(parsePath(t, "b"))},
+				expr.IntegerValue(1),
+			), false},
+		{"function with invalid index", "typeof(a)[-1]", nil, true},
 		{"CAST", "CAST(a.b[1][0] AS TEXT)", expr.CastFunc{Expr: expr.Path(parsePath(t, "a.b[1][0]")), CastAs: document.TextValue}, false},
+		{"ANY", "age < ANY (prices)", expr.Any(scanner.LT, expr.Path(parsePath(t, "age")), expr.Parentheses{E: expr.Path(parsePath(t, "prices"))}), false},
+		{"ALL", "age >= ALL ([1, 2])", expr.All(scanner.GTE, expr.Path(parsePath(t, "age")), expr.Parentheses{E: expr.LiteralExprList{expr.IntegerValue(1), expr.IntegerValue(2)}}), false},
+		{"IN with path", "'admin' IN roles", expr.In(expr.TextValue("admin"), expr.Path(parsePath(t, "roles"))), false},
+		{"IN with tuples", "(a, b) IN ((1, 2), (3, 4))",
+			expr.In(
+				expr.TupleExpr{Elements: []expr.Expr{expr.Path(parsePath(t, "a")), expr.Path(parsePath(t, "b"))}},
+				expr.TupleExpr{Elements: []expr.Expr{
+					expr.TupleExpr{Elements: []expr.Expr{expr.IntegerValue(1), expr.IntegerValue(2)}},
+					expr.TupleExpr{Elements: []expr.Expr{expr.IntegerValue(3), expr.IntegerValue(4)}},
+				}},
+			), false},
+		{"CAST with integer width", "CAST(a AS INT16)", expr.CastFunc{Expr: expr.Path(parsePath(t, "a")), CastAs: document.IntegerValue, BitSize: 16}, false},
+		{"null field", "`null` = 1", expr.Eq(expr.Path(document.Path{document.PathFragment{FieldName: "null"}}), expr.IntegerValue(1)), false},
+		{"nested null field", "a.`null`", expr.Path(document.Path{document.PathFragment{FieldName: "a"}, document.PathFragment{FieldName: "null"}}), false},
+		{"null literal", "null = 1", expr.Eq(expr.NullValue(), expr.IntegerValue(1)), false},
+		{"nested null keyword", "a.null", nil, true},
 	}
 
 	for _, test := range tests {
@@ -176,6 +259,80 @@ func TestParserExpr(t *testing.T) {
 	}
 }
 
+func TestParserNullFieldName(t *testing.T) {
+	for _, s := range []string{"{null: 1}", "{a: 1, null: 2}", "a.null", "a[0].null"} {
+		t.Run(s, func(t *testing.T) {
+			_, _, err := NewParser(strings.NewReader(s)).ParseExpr()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "NULL is a reserved word")
+		})
+	}
+
+	_, err := ParseQuery("UPDATE test UNSET null")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "NULL is a reserved word")
+}
+
+func TestParserCastUnknownType(t *testing.T) {
+	_, _, err := NewParser(strings.NewReader("CAST(a AS foo)")).ParseExpr()
+	require.Error(t, err)
+
+	perr, ok := err.(*ParseError)
+	require.True(t, ok)
+	require.Equal(t, "foo", perr.Found)
+	require.Equal(t, []string{"type"}, perr.Expected)
+	require.Equal(t, scanner.Pos{Line: 0, Char: 10}, perr.Pos)
+}
+
+func TestParserRestrictedExpressions(t *testing.T) {
+	tests := []struct {
+		s     string
+		fails bool
+	}{
+		{"a = 1", false},
+		{"a.b[0] != 'foo' AND b > ?", false},
+		{"a >= $x OR (b < 10 AND c IS NOT NULL)", false},
+		{"a IN [1, 2] AND b NOT LIKE 'foo%'", false},
+		{"a = {b: 1}", false},
+		{"a = 1 + 1", true},
+		{"a - 1 > 0", true},
+		{"a * b = 2", true},
+		{"a / 2 = 1", true},
+		{"a % 2 = 0", true},
+		{"a & 1 = 1", true},
+		{"a | 1 = 1", true},
+		{"a ^ 1 = 1", true},
+		{"a = [1 + 1]", true},
+		{"typeof(a) = 'text'", true},
+		{"pk() = 1", true},
+		{"CAST(a AS TEXT) = '1'", true},
+		{"EXISTS (SELECT 1 FROM foo)", true},
+		{"a = (SELECT b FROM foo)", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			_, _, err := NewParser(strings.NewReader(test.s)).ParseExpr()
+			require.NoError(t, err)
+
+			_, _, err = NewParser(strings.NewReader(test.s), WithRestrictedExpressions()).ParseExpr()
+			if test.fails {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("Statement", func(t *testing.T) {
+		_, err := NewParser(strings.NewReader("SELECT * FROM foo WHERE a = 1 ORDER BY b LIMIT 10"), WithRestrictedExpressions()).ParseQuery()
+		require.NoError(t, err)
+
+		_, err = NewParser(strings.NewReader("DELETE FROM foo WHERE a = b * 2"), WithRestrictedExpressions()).ParseQuery()
+		require.EqualError(t, err, "operator * is not allowed at line 1, char 29")
+	})
+}
+
 func TestParsePath(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -206,6 +363,7 @@ func TestParsePath(t *testing.T) {
 		{"negative index", `a.b[-100].c`, nil, true},
 		{"with spaces", `a.  b[100].  c`, nil, true},
 		{"starting with array", `[10].a`, nil, true},
+		{"trailing tokens", `a.b c`, nil, true},
 	}
 
 	for _, test := range tests {
@@ -256,3 +414,171 @@ func TestParserParams(t *testing.T) {
 		})
 	}
 }
+
+func TestParserMixedParamsPosition(t *testing.T) {
+	tests := []struct {
+		name        string
+		s           string
+		message     string
+		pos         scanner.Pos
+		conflictPos scanner.Pos
+	}{
+		{"named after positional", "SELECT * FROM test WHERE a = ? AND\n b > $foo",
+			"cannot mix named parameter $foo with positional parameter ? (line 1, char 30)",
+			scanner.Pos{Line: 1, Char: 5}, scanner.Pos{Line: 0, Char: 29}},
+		{"positional after named", "SELECT * FROM test WHERE a = $foo AND b > $bar AND c < ?",
+			"cannot mix positional parameter ? with named parameter $foo (line 1, char 30)",
+			scanner.Pos{Line: 0, Char: 55}, scanner.Pos{Line: 0, Char: 29}},
+		{"across statements", "INSERT INTO test VALUES ?; DELETE FROM test WHERE a = $foo",
+			"cannot mix named parameter $foo with positional parameter ? (line 1, char 25)",
+			scanner.Pos{Line: 0, Char: 54}, scanner.Pos{Line: 0, Char: 24}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseQuery(test.s)
+			require.Error(t, err)
+
+			pErr, ok := err.(*ParseError)
+			require.True(t, ok)
+			require.Equal(t, test.message, pErr.Message)
+			require.Equal(t, test.pos, pErr.Pos)
+			require.NotNil(t, pErr.ConflictPos)
+			require.Equal(t, test.conflictPos, *pErr.ConflictPos)
+		})
+	}
+}
+
+func TestParseExprString(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		expected expr.Expr
+		fails    bool
+	}{
+		{"simple", "age = 10", expr.Eq(expr.Path(parsePath(t, "age")), expr.IntegerValue(10)), false},
+		{"trailing spaces", "age = 10  \n", expr.Eq(expr.Path(parsePath(t, "age")), expr.IntegerValue(10)), false},
+		{"trailing tokens", "age = 10 foo", nil, true},
+		{"trailing semicolon", "age = 10;", nil, true},
+		{"empty", "", nil, true},
+		{"invalid", "age = ", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e, err := ParseExprString(test.s)
+			if test.fails {
+				require.Error(t, err)
+				require.Panics(t, func() { MustParseExpr(test.s) })
+				return
+			}
+
+			require.NoError(t, err)
+			require.EqualValues(t, test.expected, e)
+			require.EqualValues(t, test.expected, MustParseExpr(test.s))
+		})
+	}
+
+	t.Run("trailing tokens error", func(t *testing.T) {
+		_, err := ParseExprString("a > 1 b")
+		require.EqualError(t, err, "found b, expected EOF at line 1, char 7")
+	})
+}
+
+func TestParserSingleExprParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		positional int
+		named      []string
+	}{
+		{"none", "a = 1", 0, nil},
+		{"positional", "a = ? AND b > ?", 2, nil},
+		{"named", "a = $foo OR b = $bar OR c = $foo", 0, []string{"foo", "bar"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := NewParser(strings.NewReader(test.s))
+			_, err := p.ParseSingleExpr()
+			require.NoError(t, err)
+
+			positional, named := p.Params()
+			require.Equal(t, test.positional, positional)
+			require.Equal(t, test.named, named)
+		})
+	}
+}
+
+func TestParserSubquery(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		expected string
+		fails    bool
+	}{
+		{"exists", "EXISTS (SELECT 1 FROM foo)", "EXISTS (SELECT 1 FROM foo)", false},
+		{"exists with where", "EXISTS(SELECT * FROM foo WHERE a > 1 AND b = ?)", "EXISTS (SELECT * FROM foo WHERE a > 1 AND b = ?)", false},
+		{"not exists", "NOT EXISTS ( SELECT a FROM foo )", "NOT EXISTS (SELECT a FROM foo)", false},
+		{"nested", "EXISTS (SELECT 1 FROM foo WHERE EXISTS (SELECT 1 FROM bar))", "EXISTS (SELECT 1 FROM foo WHERE EXISTS (SELECT 1 FROM bar))", false},
+		{"operand", "a = (SELECT b FROM foo) AND c", "a = (SELECT b FROM foo) AND c", false},
+		{"exists in expression", "a > 1 OR EXISTS (SELECT 1 FROM foo)", "a > 1 OR EXISTS (SELECT 1 FROM foo)", false},
+		{"missing parenthesis", "EXISTS SELECT 1 FROM foo", "", true},
+		{"missing right parenthesis", "EXISTS (SELECT 1 FROM foo", "", true},
+		{"not a select", "EXISTS (1)", "", true},
+		{"not without exists", "NOT a", "", true},
+		{"into", "EXISTS (SELECT 1 INTO bar FROM foo)", "", true},
+		{"invalid select", "EXISTS (SELECT FROM foo)", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e, err := ParseExprString(test.s)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, fmt.Sprintf("%v", e))
+			require.True(t, expr.Equal(e, MustParseExpr(test.expected)))
+		})
+	}
+
+	t.Run("Positional params", func(t *testing.T) {
+		p := NewParser(strings.NewReader("a = ? AND EXISTS (SELECT 1 FROM foo WHERE b = ?) AND c = ?"))
+		_, err := p.ParseSingleExpr()
+		require.NoError(t, err)
+		positional, _ := p.Params()
+		require.Equal(t, 3, positional)
+	})
+
+	t.Run("Correlated", func(t *testing.T) {
+		where := func(t *testing.T, s string) expr.Expr {
+			p := NewParser(strings.NewReader(s))
+			tok, _, _ := p.ScanIgnoreWhitespace()
+			require.Equal(t, scanner.SELECT, tok)
+			cfg, err := p.parseSelectConfig()
+			require.NoError(t, err)
+
+			c, err := cfg.withCorrelatedSubqueries()
+			require.NoError(t, err)
+			return c.WhereExpr
+		}
+
+		e := where(t, "SELECT * FROM test t WHERE EXISTS (SELECT 1 FROM foo WHERE a = t.b AND c = t.d.e AND t.b > 1)")
+		refs := e.(expr.ExistsExpr).Subquery.Refs
+		require.Equal(t, []expr.Path{expr.Path(parsePath(t, "t.b")), expr.Path(parsePath(t, "t.d.e"))}, refs)
+
+		e = where(t, "SELECT * FROM test WHERE a = (SELECT b FROM foo WHERE c = test.c)")
+		refs = e.(expr.Operator).RightHand().(expr.Subquery).Refs
+		require.Equal(t, []expr.Path{expr.Path(parsePath(t, "test.c"))}, refs)
+
+		// the source of the subquery hides the outer one
+		e = where(t, "SELECT * FROM test t WHERE EXISTS (SELECT 1 FROM foo t WHERE t.a = 1)")
+		require.Empty(t, e.(expr.ExistsExpr).Subquery.Refs)
+
+		e = where(t, "SELECT * FROM test WHERE EXISTS (SELECT 1 FROM foo WHERE a = 1)")
+		require.Empty(t, e.(expr.ExistsExpr).Subquery.Refs)
+	})
+}
@@ -3,14 +3,17 @@ package parser
 import (
 	"fmt"
 
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/planner"
 	"github.com/genjidb/genji/sql/query"
 	"github.com/genjidb/genji/sql/query/expr"
 	"github.com/genjidb/genji/sql/scanner"
 )
 
 // parseInsertStatement parses an insert string and returns a Statement AST object.
+// If the statement has a RETURNING clause, it is returned as a planner tree.
 // This function assumes the INSERT token has already been consumed.
-func (p *Parser) parseInsertStatement() (query.InsertStmt, error) {
+func (p *Parser) parseInsertStatement() (query.Statement, error) {
 	var stmt query.InsertStmt
 	var err error
 
@@ -30,16 +33,27 @@ func (p *Parser) parseInsertStatement() (query.InsertStmt, error) {
 	valueParser := p.parseParamOrDocument
 
 	// Parse path list: (a, b, c)
-	fields, withFields, err := p.parseFieldList()
+	paths, withPaths, err := p.parseFieldList()
 	if err != nil {
 		return stmt, err
 	}
-	if withFields {
+	if withPaths {
 		valueParser = func() (expr.Expr, error) {
-			// expect an expression list
-			return p.parseExprList(scanner.LPAREN, scanner.RPAREN)
+			_, pos, _ := p.ScanIgnoreWhitespace()
+			p.Unscan()
+
+			// expect an expression list of the same length as the path list
+			list, err := p.parseInsertValueList()
+			if err != nil {
+				return nil, err
+			}
+			if len(list) != len(paths) {
+				return nil, &ParseError{Message: fmt.Sprintf("%d values for %d fields", len(list), len(paths)), Pos: pos}
+			}
+
+			return list, nil
 		}
-		stmt.FieldNames = fields
+		stmt.Paths = paths
 	}
 
 	// Parse VALUES (v1, v2, v3)
@@ -48,22 +62,23 @@ func (p *Parser) parseInsertStatement() (query.InsertStmt, error) {
 		return stmt, err
 	}
 
-	// ensure the length of path list is the same as the length of values
-	if withFields {
-		for _, l := range values {
-			el := l.(expr.LiteralExprList)
-			if len(el) != len(stmt.FieldNames) {
-				return stmt, fmt.Errorf("%d values for %d fields", len(el), len(stmt.FieldNames))
-			}
-		}
+	stmt.Values = values
+
+	// Parse returned fields: "RETURNING fields".
+	returning, err := p.parseReturning()
+	if err != nil {
+		return nil, err
+	}
+	if returning != nil {
+		return planner.NewTree(planner.NewReturningNode(planner.NewInsertionInputNode(stmt), returning, stmt.TableName)), nil
 	}
 
-	stmt.Values = values
 	return stmt, nil
 }
 
-// parseFieldList parses a list of fields in the form: (path, path, ...), if exists
-func (p *Parser) parseFieldList() ([]string, bool, error) {
+// parseFieldList parses a list of fields in the form: (path, path, ...), if exists.
+// Paths cannot contain array indexes and must not overlap.
+func (p *Parser) parseFieldList() ([]document.Path, bool, error) {
 	// Parse ( token.
 	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.LPAREN {
 		p.Unscan()
@@ -71,10 +86,36 @@ func (p *Parser) parseFieldList() ([]string, bool, error) {
 	}
 
 	// Parse path list.
-	var fields []string
-	var err error
-	if fields, err = p.parseIdentList(); err != nil {
-		return nil, false, err
+	var paths []document.Path
+	for {
+		_, pos, _ := p.ScanIgnoreWhitespace()
+		p.Unscan()
+
+		path, err := p.parsePath()
+		if err != nil {
+			pErr := err.(*ParseError)
+			pErr.Expected = []string{"path"}
+			return nil, false, pErr
+		}
+
+		for _, f := range path {
+			if f.FieldName == "" {
+				return nil, false, &ParseError{Message: fmt.Sprintf("array index not allowed in field list: %s", path), Pos: pos}
+			}
+		}
+
+		for _, other := range paths {
+			if pathsOverlap(path, other) {
+				return nil, false, &ParseError{Message: fmt.Sprintf("field %s conflicts with field %s", path, other), Pos: pos}
+			}
+		}
+
+		paths = append(paths, path)
+
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
+			p.Unscan()
+			break
+		}
 	}
 
 	// Parse required ) token.
@@ -82,7 +123,16 @@ func (p *Parser) parseFieldList() ([]string, bool, error) {
 		return nil, false, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
 	}
 
-	return fields, true, nil
+	return paths, true, nil
+}
+
+// pathsOverlap returns whether a path is equal to the other or is one of its parents.
+func pathsOverlap(a, b document.Path) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	return a.IsEqual(b[:len(a)])
 }
 
 // parseValues parses the "VALUES" clause of the query, if it exists.
@@ -119,6 +169,42 @@ func (p *Parser) parseValues(valueParser func() (expr.Expr, error)) (expr.Litera
 	return valuesList, nil
 }
 
+// parseInsertValueList parses a list of values in the form: (expr|DEFAULT, expr|DEFAULT, ...).
+// The DEFAULT keyword is returned as an expr.Default marker.
+func (p *Parser) parseInsertValueList() (expr.LiteralExprList, error) {
+	// Parse ( token.
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.LPAREN {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
+	}
+
+	var list expr.LiteralExprList
+	for {
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.DEFAULT {
+			list = append(list, expr.Default{})
+		} else {
+			p.Unscan()
+
+			e, _, err := p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, e)
+		}
+
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
+			p.Unscan()
+			break
+		}
+	}
+
+	// Parse required ) token.
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+	}
+
+	return list, nil
+}
+
 // parseParamOrDocument parses either a parameter or a document.
 func (p *Parser) parseParamOrDocument() (expr.Expr, error) {
 	// Parse a param first
@@ -3,6 +3,8 @@ package parser
 import (
 	"testing"
 
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/planner"
 	"github.com/genjidb/genji/sql/query"
 	"github.com/genjidb/genji/sql/query/expr"
 	"github.com/stretchr/testify/require"
@@ -54,8 +56,8 @@ func TestParserInsert(t *testing.T) {
 			false},
 		{"Values / With fields", "INSERT INTO test (a, b) VALUES ('c', 'd')",
 			query.InsertStmt{
-				TableName:  "test",
-				FieldNames: []string{"a", "b"},
+				TableName: "test",
+				Paths:     []document.Path{parsePath(t, "a"), parsePath(t, "b")},
 				Values: expr.LiteralExprList{
 					expr.LiteralExprList{expr.TextValue("c"), expr.TextValue("d")},
 				},
@@ -64,13 +66,50 @@ func TestParserInsert(t *testing.T) {
 			nil, true},
 		{"Values / Multiple", "INSERT INTO test (a, b) VALUES ('c', 'd'), ('e', 'f')",
 			query.InsertStmt{
-				TableName:  "test",
-				FieldNames: []string{"a", "b"},
+				TableName: "test",
+				Paths:     []document.Path{parsePath(t, "a"), parsePath(t, "b")},
 				Values: expr.LiteralExprList{
 					expr.LiteralExprList{expr.TextValue("c"), expr.TextValue("d")},
 					expr.LiteralExprList{expr.TextValue("e"), expr.TextValue("f")},
 				},
 			}, false},
+		{"Values / Returning", "INSERT INTO test (a) VALUES ('c') RETURNING a",
+			planner.NewTree(
+				planner.NewReturningNode(
+					planner.NewInsertionInputNode(query.InsertStmt{
+						TableName: "test",
+						Paths:     []document.Path{parsePath(t, "a")},
+						Values: expr.LiteralExprList{
+							expr.LiteralExprList{expr.TextValue("c")},
+						},
+					}),
+					[]planner.ProjectedField{
+						planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"},
+					},
+					"test",
+				)), false},
+		{"Values / Nested paths and params", "INSERT INTO test (a, meta.created, meta.`by`) VALUES (?, ?, 'foo')",
+			query.InsertStmt{
+				TableName: "test",
+				Paths:     []document.Path{parsePath(t, "a"), parsePath(t, "meta.created"), parsePath(t, "meta.`by`")},
+				Values: expr.LiteralExprList{
+					expr.LiteralExprList{expr.PositionalParam(1), expr.PositionalParam(2), expr.TextValue("foo")},
+				},
+			}, false},
+		{"Values / Default", "INSERT INTO test (a, b) VALUES (DEFAULT, 'c'), ('d', DEFAULT)",
+			query.InsertStmt{
+				TableName: "test",
+				Paths:     []document.Path{parsePath(t, "a"), parsePath(t, "b")},
+				Values: expr.LiteralExprList{
+					expr.LiteralExprList{expr.Default{}, expr.TextValue("c")},
+					expr.LiteralExprList{expr.TextValue("d"), expr.Default{}},
+				},
+			}, false},
+		{"Values / Default in expression", "INSERT INTO test (a) VALUES (DEFAULT + 1)", nil, true},
+		{"Documents / Default", "INSERT INTO test VALUES {a: DEFAULT}", nil, true},
+		{"Values / Array index", "INSERT INTO test (a[0]) VALUES (1)", nil, true},
+		{"Values / Duplicate field", "INSERT INTO test (a, b, a) VALUES (1, 2, 3)", nil, true},
+		{"Values / Conflicting fields", "INSERT INTO test (a.b, a) VALUES (1, 2)", nil, true},
 		{"Values / With fields / Wrong values", "INSERT INTO test (a, b) VALUES {a: 1}, ('e', 'f')",
 			nil, true},
 		{"Values / Without fields / Wrong values", "INSERT INTO test VALUES {a: 1}, ('e', 'f')",
@@ -90,3 +129,13 @@ func TestParserInsert(t *testing.T) {
 		})
 	}
 }
+
+func TestParserInsertTupleLength(t *testing.T) {
+	_, err := ParseQuery("INSERT INTO test (a, b) VALUES (1, 2), (3), (4, 5)")
+	require.Error(t, err)
+
+	pErr, ok := err.(*ParseError)
+	require.True(t, ok)
+	require.Equal(t, "1 values for 2 fields", pErr.Message)
+	require.Equal(t, 39, pErr.Pos.Char)
+}
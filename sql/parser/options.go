@@ -1,11 +1,41 @@
 package parser
 
-import "github.com/genjidb/genji/sql/query/expr"
+import (
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// A Dialect determines which syntax extensions are accepted by the parser.
+type Dialect int
+
+const (
+	// DialectGenji accepts every Genji extension to SQL. This is the default.
+	DialectGenji Dialect = iota
+	// DialectStandard rejects the Genji extensions to SQL: document literals,
+	// nested field references (a.b, a[0]) and named parameters.
+	// It can be used to detect portability issues in SQL strings.
+	DialectStandard
+)
 
 // Options of the SQL parser.
 type Options struct {
 	// A map of builtin SQL functions.
 	Functions expr.Functions
+
+	// Dialect accepted by the parser.
+	Dialect Dialect
+
+	// If true, expressions can't contain arithmetic or bitwise operators,
+	// function calls or CAST, which limits them to comparisons and logical
+	// operators over paths, literals and parameters.
+	// It can be used to parse expressions coming from untrusted clients.
+	RestrictExpressions bool
+
+	// Documents referenced by name in expressions, like the old_doc and new_doc
+	// pseudo-documents of triggers. A path whose first field is one of the names
+	// is replaced by the value it points to, or NULL if the document is nil or the
+	// value doesn't exist.
+	Variables map[string]document.Document
 }
 
 func defaultOptions() *Options {
@@ -13,3 +43,29 @@ func defaultOptions() *Options {
 		Functions: expr.NewFunctions(),
 	}
 }
+
+// A ParserOption configures the parser created by NewParser.
+type ParserOption func(opts *Options)
+
+// WithDialect sets the dialect accepted by the parser.
+func WithDialect(dialect Dialect) ParserOption {
+	return func(opts *Options) {
+		opts.Dialect = dialect
+	}
+}
+
+// WithRestrictedExpressions rejects the expressions using arithmetic or bitwise operators,
+// function calls or CAST.
+func WithRestrictedExpressions() ParserOption {
+	return func(opts *Options) {
+		opts.RestrictExpressions = true
+	}
+}
+
+// WithVariables sets the documents that can be referenced by name in expressions.
+// Quoted identifiers, like `new_doc`, refer to fields even if a variable has the same name.
+func WithVariables(vars map[string]document.Document) ParserOption {
+	return func(opts *Options) {
+		opts.Variables = vars
+	}
+}
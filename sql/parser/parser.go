@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/planner"
 	"github.com/genjidb/genji/sql/query"
 	"github.com/genjidb/genji/sql/query/expr"
 	"github.com/genjidb/genji/sql/scanner"
@@ -17,13 +18,30 @@ type Parser struct {
 	s             *scanner.BufScanner
 	orderedParams int
 	namedParams   int
+	paramNames    []string
+	firstParam    paramToken
+	dialect       Dialect
 	buf           *bytes.Buffer
 	functions     expr.Functions
+	variables     map[string]document.Document
+	restrictExpr  bool
+	// common table expressions of the statement being parsed, by name.
+	ctes map[string]*SelectConfig
+	// number of references to each common table expression of the statement being parsed.
+	cteRefs map[string]int
+	// number of enclosing subqueries that can refer to the fields of an outer statement,
+	// either lateral or used in an expression.
+	correlated int
 }
 
-// NewParser returns a new instance of Parser.
-func NewParser(r io.Reader) *Parser {
-	return NewParserWithOptions(r, nil)
+// NewParser returns a new instance of Parser configured with the given options.
+func NewParser(r io.Reader, opts ...ParserOption) *Parser {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return NewParserWithOptions(r, o)
 }
 
 // NewParserWithOptions returns a new instance of Parser using given Options.
@@ -32,7 +50,7 @@ func NewParserWithOptions(r io.Reader, opts *Options) *Parser {
 		opts = defaultOptions()
 	}
 
-	return &Parser{s: scanner.NewBufScanner(r), functions: opts.Functions}
+	return &Parser{s: scanner.NewBufScanner(r), functions: opts.Functions, dialect: opts.Dialect, variables: opts.Variables, restrictExpr: opts.RestrictExpressions}
 }
 
 // ParseQuery parses a query string and returns its AST representation.
@@ -41,8 +59,37 @@ func ParseQuery(s string) (query.Query, error) {
 }
 
 // ParsePath parses a path to a value in a document.
+// It returns an error if s contains anything else than the path.
 func ParsePath(s string) (document.Path, error) {
-	return NewParser(strings.NewReader(s)).parsePath()
+	p := NewParser(strings.NewReader(s))
+
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.parseEOF(); err != nil {
+		return nil, err
+	}
+
+	return path, nil
+}
+
+// ParseExprString parses s as a single expression.
+// Unlike Parser.ParseExpr, it returns an error if s contains anything after the expression.
+// Use Parser.ParseSingleExpr and Parser.Params to know which parameters the expression uses.
+func ParseExprString(s string) (expr.Expr, error) {
+	return NewParser(strings.NewReader(s)).ParseSingleExpr()
+}
+
+// MustParseExpr calls ParseExprString and panics if it returns an error.
+func MustParseExpr(s string) expr.Expr {
+	e, err := ParseExprString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return e
 }
 
 // ParseQuery parses a Genji SQL string and returns a Query.
@@ -52,7 +99,10 @@ func (p *Parser) ParseQuery() (query.Query, error) {
 
 	for {
 		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok == scanner.EOF {
-			return query.New(statements...), nil
+			q := query.New(statements...)
+			q.PositionalParams = p.orderedParams
+			q.NamedParams = p.paramNames
+			return q, nil
 		} else if tok == scanner.SEMICOLON {
 			semi = true
 		} else {
@@ -70,8 +120,105 @@ func (p *Parser) ParseQuery() (query.Query, error) {
 	}
 }
 
+// ParseNextStatement parses the next statement of the input and returns it.
+// Statements must be separated by semicolons and empty statements are skipped.
+// The parser doesn't read past the semicolon ending the statement, which makes it
+// suitable for inputs received incrementally, like a network connection:
+// the call only blocks until the whole statement has been received.
+// Parameters are numbered per statement.
+// It returns io.EOF once the input is exhausted.
+func (p *Parser) ParseNextStatement() (query.Statement, error) {
+	// skip empty statements.
+	for {
+		tok, _, _ := p.ScanIgnoreWhitespace()
+		if tok == scanner.EOF {
+			return nil, io.EOF
+		}
+		if tok != scanner.SEMICOLON {
+			p.Unscan()
+			break
+		}
+	}
+
+	p.orderedParams = 0
+	p.namedParams = 0
+	p.paramNames = nil
+	p.firstParam = paramToken{}
+
+	s, err := p.ParseStatement()
+	if err != nil {
+		// make errors caused by a truncated input explicit.
+		if pErr, ok := err.(*ParseError); ok && p.s.Curr().Tok == scanner.EOF {
+			msg := "incomplete statement: unexpected end of input"
+			if len(pErr.Expected) > 0 {
+				msg += ", expected " + strings.Join(pErr.Expected, ", ")
+			}
+			return nil, &ParseError{Message: msg, Found: pErr.Found, Expected: pErr.Expected, Pos: pErr.Pos}
+		}
+		return nil, err
+	}
+
+	// the statement must be followed by a semicolon or the end of the input.
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.SEMICOLON && tok != scanner.EOF {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{";"}, pos)
+	}
+
+	return s, nil
+}
+
+// ParseSingleExpr parses an expression and returns an error
+// if it is followed by anything else than the end of the input.
+func (p *Parser) ParseSingleExpr() (expr.Expr, error) {
+	e, _, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.parseEOF(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Params returns the parameters found so far by the parser: the number of positional
+// parameters and the names of the named parameters, in order of appearance.
+// A query can't use both styles, at most one of them is set.
+func (p *Parser) Params() (positional int, named []string) {
+	return p.orderedParams, p.paramNames
+}
+
+// parseEOF returns an error if the input has not been entirely consumed.
+func (p *Parser) parseEOF() error {
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.EOF {
+		return newParseError(scanner.Tokstr(tok, lit), []string{"EOF"}, pos)
+	}
+
+	return nil
+}
+
 // ParseStatement parses a Genji SQL string and returns a Statement AST object.
+// The statement must be followed by a semicolon or the end of the input,
+// which are not consumed.
 func (p *Parser) ParseStatement() (query.Statement, error) {
+	s, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	// the grammar of the statement is complete, anything else than the end
+	// of the statement is a mistake, like a missing keyword.
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != scanner.SEMICOLON && tok != scanner.EOF {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{";"}, pos)
+	}
+	p.Unscan()
+
+	return s, nil
+}
+
+// parseStatement parses a statement, without checking what follows it.
+func (p *Parser) parseStatement() (query.Statement, error) {
 	tok, pos, lit := p.ScanIgnoreWhitespace()
 	switch tok {
 	case scanner.ALTER:
@@ -98,10 +245,24 @@ func (p *Parser) ParseStatement() (query.Statement, error) {
 		return p.parseReIndexStatement()
 	case scanner.ROLLBACK:
 		return p.parseRollbackStatement()
+	case scanner.VALUES:
+		p.Unscan()
+		return p.parseValuesStatement()
+	case scanner.IDENT:
+		switch {
+		case isKeyword(tok, lit, "DESCRIBE"):
+			return p.parseDescribeStatement()
+		case isKeyword(tok, lit, "SHOW"):
+			return p.parseShowStatement()
+		case isKeyword(tok, lit, "TRUNCATE"):
+			return p.parseTruncateStatement()
+		case isKeyword(tok, lit, "WITH"):
+			return p.parseWithStatement()
+		}
 	}
 
 	return nil, newParseError(scanner.Tokstr(tok, lit), []string{
-		"ALTER", "BEGIN", "COMMIT", "SELECT", "DELETE", "UPDATE", "INSERT", "CREATE", "DROP", "EXPLAIN", "REINDEX", "ROLLBACK",
+		"ALTER", "BEGIN", "COMMIT", "SELECT", "DELETE", "DESCRIBE", "UPDATE", "INSERT", "CREATE", "DROP", "EXPLAIN", "REINDEX", "ROLLBACK", "SHOW", "TRUNCATE", "WITH", "VALUES",
 	}, pos)
 }
 
@@ -122,6 +283,25 @@ func (p *Parser) parseCondition() (expr.Expr, error) {
 	return expr, nil
 }
 
+// parseReturning parses the "RETURNING" clause of a write statement, if it exists.
+func (p *Parser) parseReturning() ([]planner.ProjectedField, error) {
+	// Check if the RETURNING token exists.
+	if tok, _, lit := p.ScanIgnoreWhitespace(); !isKeyword(tok, lit, "RETURNING") {
+		p.Unscan()
+		return nil, nil
+	}
+
+	return p.parseResultFields()
+}
+
+// isKeyword reports whether the token is the identifier kw.
+// Keywords added to the language after its first versions, like RETURNING or TRIGGER,
+// are matched this way where the statement expects them, instead of being reserved
+// by the scanner, so that existing queries can keep using them as field or table names.
+func isKeyword(tok scanner.Token, lit, kw string) bool {
+	return tok == scanner.IDENT && strings.EqualFold(lit, kw)
+}
+
 // parsePathList parses a list of paths in the form: (path, path, ...), if exists
 func (p *Parser) parsePathList() ([]document.Path, error) {
 	// Parse ( token.
@@ -163,6 +343,71 @@ func (p *Parser) parsePathList() ([]document.Path, error) {
 	return paths, nil
 }
 
+// paramToken is the first parameter found in the query.
+type paramToken struct {
+	lit string
+	pos scanner.Pos
+}
+
+// checkParamStyle returns an error if the parameter lit found at pos
+// doesn't use the same style as the first parameter of the query.
+func (p *Parser) checkParamStyle(lit string, pos scanner.Pos, named bool) error {
+	if p.orderedParams == 0 && p.namedParams == 0 {
+		p.firstParam = paramToken{lit: lit, pos: pos}
+		return nil
+	}
+
+	if (p.namedParams > 0) == named {
+		return nil
+	}
+
+	style, firstStyle := "positional", "named"
+	if named {
+		style, firstStyle = firstStyle, style
+	}
+
+	first := p.firstParam.pos
+	return &ParseError{
+		Message:     fmt.Sprintf("cannot mix %s parameter %s with %s parameter %s (line %d, char %d)", style, lit, firstStyle, p.firstParam.lit, first.Line+1, first.Char+1),
+		Pos:         pos,
+		ConflictPos: &first,
+	}
+}
+
+// addPositionalParam records a positional parameter found in the query
+// and returns its index.
+func (p *Parser) addPositionalParam(pos scanner.Pos) (int, error) {
+	if err := p.checkParamStyle("?", pos, false); err != nil {
+		return 0, err
+	}
+
+	p.orderedParams++
+	return p.orderedParams, nil
+}
+
+// addNamedParam records the name of a named parameter found in the query.
+// Named parameters are not allowed in standard SQL.
+func (p *Parser) addNamedParam(name string, pos scanner.Pos) error {
+	if p.dialect == DialectStandard {
+		return &ParseError{Message: "named parameters are not allowed in standard SQL", Pos: pos}
+	}
+
+	if err := p.checkParamStyle("$"+name, pos, true); err != nil {
+		return err
+	}
+
+	p.namedParams++
+
+	for _, n := range p.paramNames {
+		if n == name {
+			return nil
+		}
+	}
+
+	p.paramNames = append(p.paramNames, name)
+	return nil
+}
+
 // Scan returns the next token from the underlying scanner.
 func (p *Parser) Scan() (tok scanner.Token, pos scanner.Pos, lit string) {
 	ti := p.s.Scan()
@@ -185,6 +430,12 @@ func (p *Parser) ScanIgnoreWhitespace() (tok scanner.Token, pos scanner.Pos, lit
 	}
 }
 
+// isQuotedIdent reports whether the last scanned token is an identifier quoted with backquotes.
+func (p *Parser) isQuotedIdent() bool {
+	ti := p.s.Curr()
+	return ti.Tok == scanner.IDENT && strings.HasPrefix(ti.Raw, "`")
+}
+
 // Unscan pushes the previously read token back onto the buffer.
 func (p *Parser) Unscan() {
 	if p.buf != nil {
@@ -200,6 +451,9 @@ type ParseError struct {
 	Found    string
 	Expected []string
 	Pos      scanner.Pos
+	// ConflictPos is the position of an earlier token
+	// conflicting with the one at Pos, if any.
+	ConflictPos *scanner.Pos
 }
 
 // newParseError returns a new instance of ParseError.
@@ -1,12 +1,17 @@
 package parser
 
 import (
+	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/genjidb/genji/sql/planner"
 	"github.com/genjidb/genji/sql/query"
+	"github.com/genjidb/genji/sql/query/expr"
 )
 
 func TestParserMultiStatement(t *testing.T) {
@@ -44,9 +49,215 @@ func TestParserMultiStatement(t *testing.T) {
 	}
 }
 
+func TestParserTrailingTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		message string
+	}{
+		{"SELECT", "SELECT * FROM test garbage here", "found here, expected ; at line 1, char 28"},
+		{"SELECT/Missing WHERE", "SELECT * FROM test AS t a = 1", "found a, expected ; at line 1, char 25"},
+		{"INSERT", "INSERT INTO test (a) VALUES (1) foo", "found foo, expected ; at line 1, char 33"},
+		{"UPDATE/Missing WHERE", "UPDATE test SET a = 1 b = 2", "found b, expected ; at line 1, char 23"},
+		{"UPDATE/Typo in WHERE", "UPDATE test SET a = 1 WERE b = 2", "found WERE, expected ; at line 1, char 23"},
+		{"DELETE/Missing WHERE", "DELETE FROM test a = 1", "found a, expected ; at line 1, char 18"},
+		{"DELETE/Typo in WHERE", "DELETE FROM test WERE a = 1", "found WERE, expected ; at line 1, char 18"},
+		{"CREATE TABLE", "CREATE TABLE test foo", "found foo, expected ; at line 1, char 19"},
+		{"CREATE INDEX", "CREATE INDEX idx ON test (a) foo", "found foo, expected ; at line 1, char 30"},
+		{"DROP TABLE", "DROP TABLE test foo", "found foo, expected ; at line 1, char 17"},
+		{"DROP INDEX", "DROP INDEX idx foo", "found foo, expected ; at line 1, char 16"},
+		{"EXPLAIN", "EXPLAIN DELETE FROM test a = 1", "found a, expected ; at line 1, char 26"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseQuery(test.s)
+			require.EqualError(t, err, test.message)
+
+			_, err = NewParser(strings.NewReader(test.s)).ParseStatement()
+			require.EqualError(t, err, test.message)
+
+			_, err = NewParser(strings.NewReader(test.s)).ParseNextStatement()
+			require.EqualError(t, err, test.message)
+		})
+	}
+
+	t.Run("End of statement", func(t *testing.T) {
+		p := NewParser(strings.NewReader("DELETE FROM test WHERE a = 1; DELETE FROM test"))
+		_, err := p.ParseStatement()
+		require.NoError(t, err)
+
+		// the semicolon is not consumed
+		q, err := p.ParseQuery()
+		require.NoError(t, err)
+		require.Len(t, q.Statements, 1)
+	})
+}
+
+func TestParserContextualKeywords(t *testing.T) {
+	keywords := []string{"ROW", "each", "Before", "after", "for", "trigger", "returning", "truncate", "show", "tables", "describe", "all", "Any", "collate", "join", "Lateral", "left", "percent", "rows", "Sample", "seed", "pivot", "nulls", "first", "Last", "with", "regexp", "int16", "Int32", "INT64"}
+
+	queries := []string{
+		"SELECT %[1]s, a.%[1]s FROM test WHERE %[1]s > 1 ORDER BY %[1]s",
+		"SELECT * FROM test AS %[1]s WHERE %[1]s.a = 1",
+		"SELECT %[1]s FROM test AS %[1]s SAMPLE 10 ROWS SEED 1",
+		"SELECT * FROM %[1]s SAMPLE 10 PERCENT",
+		"SELECT * FROM test AS %[1]s PIVOT (SUM(%[1]s) FOR %[1]s IN (1, 2))",
+		"SELECT * FROM test LEFT JOIN LATERAL (SELECT %[1]s FROM %[1]s) AS %[1]s ON %[1]s.a = 1",
+		"SELECT * FROM test ORDER BY %[1]s COLLATE NOCASE DESC",
+		"SELECT * FROM test ORDER BY %[1]s NULLS FIRST",
+		"SELECT * FROM test ORDER BY %[1]s DESC NULLS LAST",
+		"SELECT * FROM test WHERE %[1]s REGEXP 'a' OR %[1]s NOT REGEXP %[1]s",
+		"SELECT * FROM test WHERE a = %[1]s AND b < %[1]s.c OR d >= %[1]s[0]",
+		"SELECT * FROM %[1]s",
+		"INSERT INTO test (%[1]s) VALUES (1) RETURNING %[1]s",
+		"UPDATE test SET %[1]s = 1 WHERE %[1]s IS NULL RETURNING %[1]s",
+		"DELETE FROM test WHERE %[1]s = 1 RETURNING %[1]s",
+		"CREATE TABLE %[1]s (%[1]s INTEGER)",
+		"CREATE TABLE test (%[1]s INT32, b INT16 NOT NULL)",
+		"SELECT CAST(%[1]s AS int64) AS a FROM test",
+		"CREATE INDEX %[1]s ON test (%[1]s)",
+		"DESCRIBE %[1]s",
+		"WITH %[1]s AS (SELECT %[1]s FROM %[1]s) SELECT * FROM %[1]s",
+		"TRUNCATE TABLE %[1]s",
+		// the statement of a trigger is stored as written
+		"CREATE TRIGGER %[1]s AFTER INSERT ON %[1]s FOR EACH ROW DELETE FROM test",
+	}
+
+	for _, kw := range keywords {
+		for _, q := range queries {
+			s := fmt.Sprintf(q, kw)
+			t.Run(s, func(t *testing.T) {
+				want, err := ParseQuery(fmt.Sprintf(q, "`"+kw+"`"))
+				require.NoError(t, err)
+
+				got, err := ParseQuery(s)
+				require.NoError(t, err)
+				require.EqualValues(t, want, got)
+			})
+		}
+	}
+}
+
+func TestParserSourceAlias(t *testing.T) {
+	for _, kw := range []string{"each", "join", "LEFT", "sample", "Pivot"} {
+		t.Run(kw, func(t *testing.T) {
+			want, err := ParseQuery("SELECT * FROM test AS " + kw)
+			require.NoError(t, err)
+
+			got, err := ParseQuery("SELECT * FROM test `" + kw + "`")
+			require.NoError(t, err)
+			require.EqualValues(t, want, got)
+
+			// unless quoted, the keywords of the clauses following the source are not aliases
+			got, err = ParseQuery("SELECT * FROM test " + kw)
+			if isSourceClause(kw) {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.EqualValues(t, want, got)
+			}
+		})
+	}
+}
+
 func TestParserDivideByZero(t *testing.T) {
 	// See https://github.com/genjidb/genji/issues/268
 	require.NotPanics(t, func() {
 		_, _ = ParseQuery("SELECT * FROM t LIMIT 0 % .5")
 	})
 }
+
+func TestParserDialect(t *testing.T) {
+	tests := []struct {
+		name          string
+		s             string
+		failsStandard bool
+	}{
+		{"Standard", "SELECT a, b FROM foo WHERE a > ? ORDER BY b", false},
+		{"Document literal", "INSERT INTO foo VALUES {a: 1}", true},
+		{"Nested document literal", "SELECT * FROM foo WHERE a = {b: 1}", true},
+		{"Nested field", "SELECT a.b FROM foo", true},
+		{"Array index", "SELECT * FROM foo WHERE a[0] = 1", true},
+		{"Named param", "SELECT * FROM foo WHERE a = $a", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewParser(strings.NewReader(test.s)).ParseQuery()
+			require.NoError(t, err)
+
+			_, err = NewParser(strings.NewReader(test.s), WithDialect(DialectStandard)).ParseQuery()
+			if test.failsStandard {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParserParseNextStatement(t *testing.T) {
+	t.Run("OneByteReader", func(t *testing.T) {
+		r := iotest.OneByteReader(strings.NewReader("SELECT * FROM foo;;\n  DELETE FROM foo WHERE a = ?; CREATE TABLE bar"))
+		p := NewParser(r)
+
+		s, err := p.ParseNextStatement()
+		require.NoError(t, err)
+		require.EqualValues(t, planner.NewTree(
+			planner.NewProjectionNode(
+				planner.NewTableInputNode("foo"),
+				[]planner.ProjectedField{planner.Wildcard{}},
+				"foo",
+			)), s)
+
+		s, err = p.ParseNextStatement()
+		require.NoError(t, err)
+		require.EqualValues(t, planner.NewTree(
+			planner.NewDeletionNode(
+				planner.NewSelectionNode(
+					planner.NewTableInputNode("foo"),
+					expr.Eq(expr.Path(parsePath(t, "a")), expr.PositionalParam(1)),
+				),
+				"foo",
+			)), s)
+
+		s, err = p.ParseNextStatement()
+		require.NoError(t, err)
+		require.EqualValues(t, query.CreateTableStmt{TableName: "bar"}, s)
+
+		_, err = p.ParseNextStatement()
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("Does not read past the statement", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		defer pw.Close()
+
+		go func() {
+			_, _ = pw.Write([]byte("SELECT 1;"))
+		}()
+
+		// the second statement is never sent: the parser must not block.
+		_, err := NewParser(pr).ParseNextStatement()
+		require.NoError(t, err)
+	})
+
+	t.Run("Incomplete statement", func(t *testing.T) {
+		p := NewParser(strings.NewReader("SELECT 1; SELECT * FROM"))
+
+		_, err := p.ParseNextStatement()
+		require.NoError(t, err)
+
+		_, err = p.ParseNextStatement()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "incomplete statement")
+		require.Contains(t, err.Error(), "table_name")
+	})
+
+	t.Run("Missing semicolon", func(t *testing.T) {
+		_, err := NewParser(strings.NewReader("SELECT 1 SELECT 2")).ParseNextStatement()
+		require.Error(t, err)
+		require.NotContains(t, err.Error(), "incomplete statement")
+	})
+}
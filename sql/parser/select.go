@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/sql/planner"
@@ -12,7 +13,115 @@ import (
 // parseSelectStatement parses a select string and returns a Statement AST object.
 // This function assumes the SELECT token has already been consumed.
 func (p *Parser) parseSelectStatement() (*planner.Tree, error) {
-	var cfg selectConfig
+	cfg, err := p.parseSelectConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.ToTree()
+}
+
+// parseWithStatement parses a select statement preceded by a list of common table expressions:
+// "WITH name AS (SELECT ...) [, name AS (SELECT ...)]* SELECT ...".
+// Common table expressions are not recursive. Within the statement, their names take
+// precedence over the names of the tables.
+// A common table expression referenced once is inlined, otherwise it is materialized:
+// its statement is run once per execution and its documents are read by every reference.
+// This function assumes the WITH token has already been consumed.
+func (p *Parser) parseWithStatement() (*planner.Tree, error) {
+	p.ctes = make(map[string]*SelectConfig)
+	p.cteRefs = make(map[string]int)
+	defer func() {
+		p.ctes = nil
+		p.cteRefs = nil
+	}()
+
+	var names []string
+
+	for {
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		if tok != scanner.IDENT {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"cte_name"}, pos)
+		}
+		if _, ok := p.ctes[lit]; ok {
+			return nil, &ParseError{Message: fmt.Sprintf("common table expression %q is defined more than once", lit), Pos: pos}
+		}
+		name := lit
+
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.AS {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"AS"}, pos)
+		}
+
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.LPAREN {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
+		}
+
+		tok, pos, lit = p.ScanIgnoreWhitespace()
+		if tok != scanner.SELECT {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"SELECT"}, pos)
+		}
+
+		cfg, err := p.parseSelectConfig()
+		if err != nil {
+			return nil, err
+		}
+		if cfg.IntoTable != "" {
+			return nil, &ParseError{Message: "a common table expression cannot create a table", Pos: pos}
+		}
+
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+		}
+
+		// registered once parsed, so that it can't refer to itself
+		p.ctes[name] = cfg
+		names = append(names, name)
+
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
+			p.Unscan()
+			break
+		}
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.SELECT {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"SELECT"}, pos)
+	}
+
+	cfg, err := p.parseSelectConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// common table expressions can only refer to the ones defined before them,
+	// which are materialized first.
+	var materialized []*planner.MaterializedCTE
+	for _, name := range names {
+		if p.cteRefs[name] < 2 {
+			continue
+		}
+
+		t, err := p.ctes[name].ToTree()
+		if err != nil {
+			return nil, err
+		}
+
+		p.ctes[name].Materialized = planner.NewMaterializedCTE(name, t)
+		materialized = append(materialized, p.ctes[name].Materialized)
+	}
+
+	t, err := cfg.ToTree()
+	if err != nil {
+		return nil, err
+	}
+	t.Materialized = materialized
+
+	return t, nil
+}
+
+// parseSelectConfig parses a select string and returns its configuration.
+// This function assumes the SELECT token has already been consumed.
+func (p *Parser) parseSelectConfig() (*SelectConfig, error) {
+	var cfg SelectConfig
 	var err error
 
 	cfg.Distinct, err = p.parseDistinct()
@@ -26,14 +135,20 @@ func (p *Parser) parseSelectStatement() (*planner.Tree, error) {
 		return nil, err
 	}
 
+	// Parse target table: "INTO table_name".
+	cfg.IntoTable, err = p.parseInto()
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse "FROM".
 	var found bool
-	cfg.TableName, found, err = p.parseFrom()
+	found, err = p.parseFrom(&cfg)
 	if err != nil {
 		return nil, err
 	}
 	if !found {
-		return cfg.ToTree()
+		return &cfg, nil
 	}
 
 	// Parse condition: "WHERE expr".
@@ -48,8 +163,8 @@ func (p *Parser) parseSelectStatement() (*planner.Tree, error) {
 		return nil, err
 	}
 
-	// Parse order by: "ORDER BY path [ASC|DESC]?"
-	cfg.OrderBy, cfg.OrderByDirection, err = p.parseOrderBy()
+	// Parse order by: "ORDER BY path [COLLATE name] [ASC|DESC]? [NULLS FIRST|LAST]?"
+	err = p.parseOrderBy(&cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +181,7 @@ func (p *Parser) parseSelectStatement() (*planner.Tree, error) {
 		return nil, err
 	}
 
-	return cfg.ToTree()
+	return &cfg, nil
 }
 
 // parseResultFields parses the list of result fields.
@@ -128,6 +243,23 @@ func (p *Parser) parseResultField() (planner.ProjectedField, error) {
 	return rf, nil
 }
 
+// parseInto parses the name of the table created with the result of the query, if it exists.
+func (p *Parser) parseInto() (string, error) {
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.INTO {
+		p.Unscan()
+		return "", nil
+	}
+
+	tableName, err := p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"table_name"}
+		return "", pErr
+	}
+
+	return tableName, nil
+}
+
 func (p *Parser) parseDistinct() (bool, error) {
 	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.DISTINCT {
 		p.Unscan()
@@ -137,21 +269,376 @@ func (p *Parser) parseDistinct() (bool, error) {
 	return true, nil
 }
 
-func (p *Parser) parseFrom() (string, bool, error) {
+// parseFrom parses the source of the documents, either a table name, the name of
+// a common table expression, a table-valued function call or a parenthesized
+// VALUES list or subquery, followed by an optional alias.
+func (p *Parser) parseFrom(cfg *SelectConfig) (bool, error) {
 	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.FROM {
 		p.Unscan()
-		return "", false, nil
+		return false, nil
+	}
+
+	var err error
+
+	// Parse parenthesized source: "(VALUES ...)" or "(SELECT ...)"
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.LPAREN {
+		cfg.Values, cfg.Subquery, err = p.parseParenthesizedSource()
+	} else {
+		p.Unscan()
+		err = p.parseNamedSource(cfg)
+	}
+	if err != nil {
+		return true, err
 	}
 
+	// Parse optional alias: "[AS] alias"
+	cfg.TableAlias, err = p.parseTableAlias()
+	if err != nil {
+		return true, err
+	}
+
+	// Parse optional sample: "SAMPLE expr PERCENT|ROWS [SEED expr]"
+	err = p.parseSample(cfg)
+	if err != nil {
+		return true, err
+	}
+
+	// Parse optional pivot: "PIVOT (agg FOR path IN (expr, ...))"
+	err = p.parsePivot(cfg)
+	if err != nil {
+		return true, err
+	}
+
+	// Parse optional lateral join: ", LATERAL (subquery) alias" or "[LEFT] JOIN LATERAL (subquery) alias ON expr"
+	err = p.parseLateral(cfg)
+	return true, err
+}
+
+// parseNamedSource parses a table name, the name of a common table expression
+// or a table-valued function call.
+func (p *Parser) parseNamedSource(cfg *SelectConfig) error {
 	// Parse table name
 	ident, err := p.parseIdent()
 	if err != nil {
 		pErr := err.(*ParseError)
-		pErr.Expected = []string{"table_name"}
-		return ident, true, pErr
+		pErr.Expected = []string{"table_name", "("}
+		return pErr
+	}
+
+	// Parse optional table-valued function call: "name(expr, ...)"
+	if tok, pos, _ := p.ScanIgnoreWhitespace(); tok == scanner.LPAREN {
+		p.Unscan()
+		args, err := p.parseExprList(scanner.LPAREN, scanner.RPAREN)
+		if err != nil {
+			return err
+		}
+
+		// the arguments are evaluated before reading any document: they can only refer
+		// to the fields of an outer statement, which are replaced by parameters.
+		if p.correlated == 0 {
+			for _, e := range args {
+				expr.Walk(e, func(e expr.Expr) (expr.Expr, bool) {
+					if _, ok := e.(expr.Path); ok {
+						err = &ParseError{Message: fmt.Sprintf("%s() cannot refer to the field %s outside of a subquery", ident, e), Pos: pos}
+					}
+					return e, err == nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		cfg.TableFunction, err = p.functions.GetTableFunc(ident, args...)
+		return err
+	}
+	p.Unscan()
+
+	if cte, ok := p.ctes[ident]; ok {
+		cfg.CTE = cte
+		cfg.CTEName = ident
+		p.cteRefs[ident]++
+	} else {
+		cfg.TableName = ident
+	}
+
+	return nil
+}
+
+// parseParenthesizedSource parses either a VALUES list or a subquery, followed by a closing parenthesis.
+// This function assumes the opening parenthesis has already been consumed.
+func (p *Parser) parseParenthesizedSource() (expr.LiteralExprList, *SelectConfig, error) {
+	var values expr.LiteralExprList
+	var sub *SelectConfig
+	var err error
+
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch tok {
+	case scanner.VALUES:
+		p.Unscan()
+		values, err = p.parseValues(p.parseParamOrDocument)
+	case scanner.SELECT:
+		// the subquery is executed once, it can't refer to the fields of an outer statement
+		correlated := p.correlated
+		p.correlated = 0
+		sub, err = p.parseSelectConfig()
+		p.correlated = correlated
+		if err == nil && sub.IntoTable != "" {
+			err = &ParseError{Message: "a subquery cannot create a table", Pos: pos}
+		}
+	default:
+		err = newParseError(scanner.Tokstr(tok, lit), []string{"VALUES", "SELECT"}, pos)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+		return nil, nil, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+	}
+
+	return values, sub, nil
+}
+
+// parseValuesStatement parses a list of documents used as a statement: "VALUES {...}, {...}".
+// This function assumes the VALUES token has not been consumed.
+func (p *Parser) parseValuesStatement() (*planner.Tree, error) {
+	values, err := p.parseValues(p.parseParamOrDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := SelectConfig{
+		Values:          values,
+		ProjectionExprs: []planner.ProjectedField{planner.Wildcard{}},
 	}
 
-	return ident, true, nil
+	return cfg.ToTree()
+}
+
+// parseLateral parses a lateral join following the source of the documents, if it exists.
+func (p *Parser) parseLateral(cfg *SelectConfig) error {
+	var withOn bool
+
+	switch tok, _, lit := p.ScanIgnoreWhitespace(); {
+	case tok == scanner.COMMA:
+	case isKeyword(tok, lit, "LEFT"):
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); !isKeyword(tok, lit, "JOIN") {
+			return newParseError(scanner.Tokstr(tok, lit), []string{"JOIN"}, pos)
+		}
+		cfg.LateralOuter = true
+		withOn = true
+	case isKeyword(tok, lit, "JOIN"):
+		withOn = true
+	default:
+		p.Unscan()
+		return nil
+	}
+
+	// LATERAL is optional before a list of values, which can't refer to the joined documents.
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	lateral := isKeyword(tok, lit, "LATERAL")
+	if lateral {
+		tok, pos, lit = p.ScanIgnoreWhitespace()
+	}
+	if tok != scanner.LPAREN {
+		if lateral {
+			return newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
+		}
+		return newParseError(scanner.Tokstr(tok, lit), []string{"LATERAL", "("}, pos)
+	}
+
+	tok, pos, lit = p.ScanIgnoreWhitespace()
+	switch {
+	case tok == scanner.SELECT && lateral:
+		p.correlated++
+		sub, err := p.parseSelectConfig()
+		p.correlated--
+		if err != nil {
+			return err
+		}
+		if sub.IntoTable != "" {
+			return &ParseError{Message: "a lateral subquery cannot create a table", Pos: pos}
+		}
+		cfg.Lateral = sub
+	case tok == scanner.VALUES:
+		p.Unscan()
+		values, err := p.parseValues(p.parseParamOrDocument)
+		if err != nil {
+			return err
+		}
+		cfg.Lateral = &SelectConfig{
+			Values:          values,
+			ProjectionExprs: []planner.ProjectedField{planner.Wildcard{}},
+		}
+	case lateral:
+		return newParseError(scanner.Tokstr(tok, lit), []string{"SELECT", "VALUES"}, pos)
+	default:
+		return newParseError(scanner.Tokstr(tok, lit), []string{"VALUES"}, pos)
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+		return newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+	}
+
+	_, pos, _ = p.ScanIgnoreWhitespace()
+	p.Unscan()
+
+	var err error
+
+	cfg.LateralAlias, err = p.parseTableAlias()
+	if err != nil {
+		return err
+	}
+	if cfg.LateralAlias == "" {
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		return newParseError(scanner.Tokstr(tok, lit), []string{"table_alias"}, pos)
+	}
+
+	if !withOn {
+		return nil
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.ON {
+		return newParseError(scanner.Tokstr(tok, lit), []string{"ON"}, pos)
+	}
+
+	cfg.LateralOn, _, err = p.ParseExpr()
+	return err
+}
+
+// parseSample parses the SAMPLE clause following the source of the documents, if it exists.
+func (p *Parser) parseSample(cfg *SelectConfig) error {
+	if tok, _, lit := p.ScanIgnoreWhitespace(); !isKeyword(tok, lit, "SAMPLE") {
+		p.Unscan()
+		return nil
+	}
+
+	var err error
+	cfg.SampleExpr, _, err = p.ParseExpr()
+	if err != nil {
+		return err
+	}
+
+	switch tok, pos, lit := p.ScanIgnoreWhitespace(); {
+	case isKeyword(tok, lit, "ROWS"):
+		cfg.SampleRows = true
+	case isKeyword(tok, lit, "PERCENT"):
+	default:
+		return newParseError(scanner.Tokstr(tok, lit), []string{"PERCENT", "ROWS"}, pos)
+	}
+
+	if tok, _, lit := p.ScanIgnoreWhitespace(); !isKeyword(tok, lit, "SEED") {
+		p.Unscan()
+		return nil
+	}
+
+	cfg.SampleSeedExpr, _, err = p.ParseExpr()
+	return err
+}
+
+// parseNoSample returns an error if the next token is SAMPLE, which can only be used
+// by statements reading documents.
+func (p *Parser) parseNoSample(stmt string) error {
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if isKeyword(tok, lit, "SAMPLE") {
+		return &ParseError{Message: fmt.Sprintf("SAMPLE cannot be used in a %s statement", stmt), Pos: pos}
+	}
+	p.Unscan()
+
+	return nil
+}
+
+// parsePivot parses the PIVOT clause following the source of the documents, if it exists.
+func (p *Parser) parsePivot(cfg *SelectConfig) error {
+	if tok, _, lit := p.ScanIgnoreWhitespace(); !isKeyword(tok, lit, "PIVOT") {
+		p.Unscan()
+		return nil
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.LPAREN {
+		return newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
+	}
+
+	_, pos, _ := p.ScanIgnoreWhitespace()
+	p.Unscan()
+
+	e, _, err := p.ParseExpr()
+	if err != nil {
+		return err
+	}
+	if _, ok := e.(document.AggregatorBuilder); !ok {
+		return &ParseError{Message: fmt.Sprintf("PIVOT expects an aggregate function, got %v", e), Pos: pos}
+	}
+	cfg.PivotAggregator = e
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); !isKeyword(tok, lit, "FOR") {
+		return newParseError(scanner.Tokstr(tok, lit), []string{"FOR"}, pos)
+	}
+
+	path, err := p.parsePath()
+	if err != nil {
+		return err
+	}
+	cfg.PivotPath = expr.Path(path)
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.IN {
+		return newParseError(scanner.Tokstr(tok, lit), []string{"IN"}, pos)
+	}
+
+	_, pos, _ = p.ScanIgnoreWhitespace()
+	p.Unscan()
+
+	cfg.PivotValues, err = p.parseExprList(scanner.LPAREN, scanner.RPAREN)
+	if err != nil {
+		return err
+	}
+	if len(cfg.PivotValues) == 0 {
+		return &ParseError{Message: "PIVOT expects at least one value", Pos: pos}
+	}
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+		return newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+	}
+
+	return nil
+}
+
+// parseTableAlias parses the alias following a table name, if it exists.
+// The AS keyword is optional.
+func (p *Parser) parseTableAlias() (string, error) {
+	tok, _, lit := p.ScanIgnoreWhitespace()
+	switch tok {
+	case scanner.AS:
+		alias, err := p.parseIdent()
+		if err != nil {
+			pErr := err.(*ParseError)
+			pErr.Expected = []string{"table_alias"}
+			return "", pErr
+		}
+		return alias, nil
+	case scanner.IDENT:
+		if !isSourceClause(lit) || p.isQuotedIdent() {
+			return lit, nil
+		}
+	}
+
+	p.Unscan()
+	return "", nil
+}
+
+// sourceClauses are the contextual keywords that can follow the source of the documents.
+// Unless quoted, they are not taken for the alias of the source.
+var sourceClauses = []string{"JOIN", "LEFT", "PIVOT", "SAMPLE"}
+
+func isSourceClause(lit string) bool {
+	for _, kw := range sourceClauses {
+		if strings.EqualFold(lit, kw) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (p *Parser) parseGroupBy() (expr.Expr, error) {
@@ -171,31 +658,64 @@ func (p *Parser) parseGroupBy() (expr.Expr, error) {
 	return e, err
 }
 
-func (p *Parser) parseOrderBy() (expr.Path, scanner.Token, error) {
+func (p *Parser) parseOrderBy(cfg *SelectConfig) error {
 	// parse ORDER token
 	if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.ORDER {
 		p.Unscan()
-		return nil, 0, nil
+		return nil
 	}
 
 	// parse BY token
 	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.BY {
-		return nil, 0, newParseError(scanner.Tokstr(tok, lit), []string{"BY"}, pos)
+		return newParseError(scanner.Tokstr(tok, lit), []string{"BY"}, pos)
 	}
 
 	// parse path
 	path, err := p.parsePath()
 	if err != nil {
-		return nil, 0, err
+		return err
+	}
+	cfg.OrderBy = expr.Path(path)
+
+	// parse optional COLLATE name
+	if tok, _, lit := p.ScanIgnoreWhitespace(); isKeyword(tok, lit, "COLLATE") {
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		if tok != scanner.IDENT {
+			return newParseError(scanner.Tokstr(tok, lit), []string{"collation name"}, pos)
+		}
+		if _, err := planner.GetCollation(lit); err != nil {
+			return &ParseError{Message: err.Error(), Pos: pos}
+		}
+		cfg.OrderByCollation = lit
+	} else {
+		p.Unscan()
 	}
 
 	// parse optional ASC or DESC
 	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.ASC || tok == scanner.DESC {
-		return expr.Path(path), tok, nil
+		cfg.OrderByDirection = tok
+	} else {
+		p.Unscan()
+	}
+
+	// parse optional NULLS FIRST or NULLS LAST.
+	// NULLS, FIRST and LAST are not keywords, to allow using them as field names.
+	if tok, _, lit := p.ScanIgnoreWhitespace(); !isKeyword(tok, lit, "NULLS") {
+		p.Unscan()
+		return nil
+	}
+
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch {
+	case isKeyword(tok, lit, "FIRST"):
+		cfg.OrderByNulls = planner.NullsFirst
+	case isKeyword(tok, lit, "LAST"):
+		cfg.OrderByNulls = planner.NullsLast
+	default:
+		return newParseError(scanner.Tokstr(tok, lit), []string{"FIRST", "LAST"}, pos)
 	}
-	p.Unscan()
 
-	return expr.Path(path), 0, nil
+	return nil
 }
 
 func (p *Parser) parseLimit() (expr.Expr, error) {
@@ -221,24 +741,136 @@ func (p *Parser) parseOffset() (expr.Expr, error) {
 }
 
 // SelectConfig holds SELECT configuration.
-type selectConfig struct {
+type SelectConfig struct {
 	TableName        string
+	TableFunction    expr.TableFunction
+	TableAlias       string
 	Distinct         bool
 	WhereExpr        expr.Expr
 	GroupByExpr      expr.Expr
 	OrderBy          expr.Path
 	OrderByDirection scanner.Token
-	OffsetExpr       expr.Expr
-	LimitExpr        expr.Expr
-	ProjectionExprs  []planner.ProjectedField
+	// OrderByCollation is the name of the collation used to sort texts.
+	// If empty, texts are sorted byte per byte.
+	OrderByCollation string
+	// OrderByNulls determines where null values are placed.
+	OrderByNulls    planner.NullsOrder
+	OffsetExpr      expr.Expr
+	LimitExpr       expr.Expr
+	ProjectionExprs []planner.ProjectedField
+
+	// If SampleExpr is set, only a random subset of the documents of the source is read,
+	// before any filtering. SampleExpr is either a number of documents, if SampleRows is true,
+	// or a percentage of the documents. SampleSeedExpr, if set, makes the sample deterministic.
+	SampleExpr     expr.Expr
+	SampleRows     bool
+	SampleSeedExpr expr.Expr
+
+	// If PivotAggregator is set, the values of PivotPath listed in PivotValues
+	// are turned into fields aggregating the documents of the source.
+	PivotAggregator expr.Expr
+	PivotPath       expr.Path
+	PivotValues     []expr.Expr
+
+	// If Lateral is set, every document of the source is joined with the documents
+	// returned by the Lateral subquery, which is executed once per document and can
+	// refer to its fields using the table name or alias.
+	// The joined documents are stored in the LateralAlias field and filtered by LateralOn.
+	// If LateralOuter is true, documents without any match are kept and the field is null.
+	Lateral      *SelectConfig
+	LateralAlias string
+	LateralOn    expr.Expr
+	LateralOuter bool
+
+	// If CTE is set, the documents are read from the common table expression
+	// named CTEName instead of a table.
+	CTE     *SelectConfig
+	CTEName string
+
+	// If Materialized is set, the statement is a common table expression referenced
+	// more than once, whose documents are shared by every reference.
+	Materialized *planner.MaterializedCTE
+
+	// If Values is set, the documents are the values of the listed expressions,
+	// which must evaluate to documents.
+	Values expr.LiteralExprList
+
+	// If Subquery is set, the documents are read from the result of the subquery.
+	Subquery *SelectConfig
+
+	// IntoTable is the name of the table created with the result
+	// of the statement, if any.
+	IntoTable string
 }
 
 // ToTree turns the statement into an expression tree.
-func (cfg selectConfig) ToTree() (*planner.Tree, error) {
+func (cfg SelectConfig) ToTree() (*planner.Tree, error) {
 	var n planner.Node
 
+	cfg, err := cfg.withCorrelatedSubqueries()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.TableFunction != nil {
+		n = planner.NewTableFunctionInputNode(cfg.TableFunction, cfg.TableAlias)
+	}
+
+	if cfg.Values != nil {
+		n = planner.NewValuesInputNode(cfg.Values, cfg.TableAlias)
+	}
+
+	if cfg.Subquery != nil {
+		t, err := cfg.Subquery.ToTree()
+		if err != nil {
+			return nil, err
+		}
+
+		n = planner.NewSubqueryInputNode(t, cfg.TableAlias)
+	}
+
+	if cfg.CTE != nil && cfg.CTE.Materialized != nil {
+		n = planner.NewMaterializedInputNode(cfg.CTE.Materialized, cfg.TableAlias)
+	} else if cfg.CTE != nil {
+		t, err := cfg.CTE.ToTree()
+		if err != nil {
+			return nil, err
+		}
+
+		n = planner.NewSubqueryInputNode(t, cfg.TableAlias)
+	}
+
 	if cfg.TableName != "" {
-		n = planner.NewTableInputNode(cfg.TableName)
+		if cfg.TableAlias != "" {
+			n = planner.NewAliasedTableInputNode(cfg.TableName, cfg.TableAlias)
+		} else {
+			n = planner.NewTableInputNode(cfg.TableName)
+		}
+	}
+
+	if cfg.SampleExpr != nil {
+		var err error
+		n, err = cfg.sampleNode(n)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.PivotAggregator != nil {
+		n = planner.NewPivotNode(n, cfg.PivotAggregator, cfg.PivotPath, cfg.PivotValues)
+	}
+
+	if cfg.Lateral != nil {
+		sub, refs, err := cfg.Lateral.correlate(cfg.sourceName())
+		if err != nil {
+			return nil, err
+		}
+		t, err := sub.ToTree()
+		if err != nil {
+			return nil, err
+		}
+
+		n = planner.NewLateralJoinNode(n, t, cfg.LateralAlias, refs, cfg.LateralOn, cfg.LateralOuter)
 	}
 
 	if cfg.WhereExpr != nil {
@@ -315,7 +947,7 @@ func (cfg selectConfig) ToTree() (*planner.Tree, error) {
 	}
 
 	if cfg.OrderBy != nil {
-		n = planner.NewSortNode(n, cfg.OrderBy, cfg.OrderByDirection)
+		n = planner.NewSortNodeWithNulls(n, cfg.OrderBy, cfg.OrderByDirection, cfg.OrderByCollation, cfg.OrderByNulls)
 	}
 
 	if cfg.OffsetExpr != nil {
@@ -354,5 +986,196 @@ func (cfg selectConfig) ToTree() (*planner.Tree, error) {
 		n = planner.NewLimitNode(n, int(v.V.(int64)))
 	}
 
+	if cfg.IntoTable != "" {
+		n = planner.NewInsertionNode(n, cfg.IntoTable)
+	}
+
 	return &planner.Tree{Root: n}, nil
 }
+
+// sampleNode evaluates the SAMPLE clause and returns the node sampling the documents of n.
+func (cfg SelectConfig) sampleNode(n planner.Node) (planner.Node, error) {
+	v, err := cfg.SampleExpr.Eval(expr.EvalStack{})
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.Type.IsNumber() {
+		return nil, fmt.Errorf("sample size must evaluate to a number, got %q", v.Type)
+	}
+
+	var seed *int64
+	if cfg.SampleSeedExpr != nil {
+		sv, err := cfg.SampleSeedExpr.Eval(expr.EvalStack{})
+		if err != nil {
+			return nil, err
+		}
+
+		if !sv.Type.IsNumber() {
+			return nil, fmt.Errorf("sample seed must evaluate to a number, got %q", sv.Type)
+		}
+
+		sv, err = sv.CastAsInteger()
+		if err != nil {
+			return nil, err
+		}
+
+		s := sv.V.(int64)
+		seed = &s
+	}
+
+	if cfg.SampleRows {
+		v, err = v.CastAsInteger()
+		if err != nil {
+			return nil, err
+		}
+
+		rows := v.V.(int64)
+		if rows < 0 {
+			return nil, fmt.Errorf("sample size must be positive, got %d", rows)
+		}
+
+		return planner.NewRowsSampleNode(n, int(rows), seed), nil
+	}
+
+	v, err = v.CastAsDouble()
+	if err != nil {
+		return nil, err
+	}
+
+	percent := v.V.(float64)
+	if percent < 0 || percent > 100 {
+		return nil, fmt.Errorf("sample percentage must be between 0 and 100, got %v", percent)
+	}
+
+	return planner.NewPercentSampleNode(n, percent, seed), nil
+}
+
+// sourceName returns the name used to refer to the source of the documents.
+func (cfg SelectConfig) sourceName() string {
+	if cfg.TableAlias != "" {
+		return cfg.TableAlias
+	}
+	if cfg.TableName != "" {
+		return cfg.TableName
+	}
+
+	return cfg.CTEName
+}
+
+// withCorrelatedSubqueries returns a copy of cfg where the subqueries of the WHERE clause
+// and of the projected fields are correlated with the source of the documents.
+func (cfg SelectConfig) withCorrelatedSubqueries() (SelectConfig, error) {
+	name := cfg.sourceName()
+
+	var err error
+	cfg.WhereExpr, err = correlateSubqueries(cfg.WhereExpr, name)
+	if err != nil {
+		return cfg, err
+	}
+
+	fields := make([]planner.ProjectedField, len(cfg.ProjectionExprs))
+	for i, pf := range cfg.ProjectionExprs {
+		if pe, ok := pf.(planner.ProjectedExpr); ok {
+			pe.Expr, err = correlateSubqueries(pe.Expr, name)
+			if err != nil {
+				return cfg, err
+			}
+			pf = pe
+		}
+		fields[i] = pf
+	}
+	cfg.ProjectionExprs = fields
+
+	return cfg, nil
+}
+
+// correlate returns a copy of cfg where every path referring to the outer source name
+// is replaced by a named parameter, along with the list of the replaced paths.
+// The paths of the nested subqueries are replaced as well, and their references
+// are added to the list, so that the parameters are passed down to them.
+// See planner.LateralJoinNode.
+func (cfg SelectConfig) correlate(name string) (SelectConfig, []expr.Path, error) {
+	// the source of the subquery hides the outer one
+	if name == "" || name == cfg.TableAlias || (cfg.TableAlias == "" && (name == cfg.TableName || name == cfg.CTEName)) {
+		return cfg, nil, nil
+	}
+
+	var refs []expr.Path
+	addRef := func(p expr.Path) {
+		for _, ref := range refs {
+			if ref.IsEqual(p) {
+				return
+			}
+		}
+		refs = append(refs, p)
+	}
+
+	var err error
+	replace := func(e expr.Expr) expr.Expr {
+		return expr.Walk(e, func(e expr.Expr) (expr.Expr, bool) {
+			if err != nil {
+				return e, false
+			}
+
+			var subRefs []expr.Path
+			switch t := e.(type) {
+			case expr.Subquery:
+				t, subRefs, err = correlateNestedSubquery(t, name)
+				for _, ref := range subRefs {
+					addRef(ref)
+				}
+				return t, false
+			case expr.ExistsExpr:
+				t.Subquery, subRefs, err = correlateNestedSubquery(t.Subquery, name)
+				for _, ref := range subRefs {
+					addRef(ref)
+				}
+				return t, false
+			case expr.Path:
+				if t[0].FieldName != name {
+					return e, true
+				}
+
+				addRef(t)
+				return expr.NamedParam(t.String()), false
+			}
+
+			return e, true
+		})
+	}
+
+	fields := make([]planner.ProjectedField, len(cfg.ProjectionExprs))
+	for i, pf := range cfg.ProjectionExprs {
+		if pe, ok := pf.(planner.ProjectedExpr); ok {
+			pe.Expr = replace(pe.Expr)
+			pf = pe
+		}
+		fields[i] = pf
+	}
+	cfg.ProjectionExprs = fields
+
+	cfg.WhereExpr = replace(cfg.WhereExpr)
+	cfg.GroupByExpr = replace(cfg.GroupByExpr)
+	cfg.PivotAggregator = replace(cfg.PivotAggregator)
+	values := make([]expr.Expr, len(cfg.PivotValues))
+	for i, e := range cfg.PivotValues {
+		values[i] = replace(e)
+	}
+	cfg.PivotValues = values
+
+	if cfg.Values != nil {
+		cfg.Values = replace(cfg.Values).(expr.LiteralExprList)
+	}
+
+	if cfg.TableFunction != nil {
+		args := cfg.TableFunction.Args()
+		replaced := make([]expr.Expr, len(args))
+		for i, e := range args {
+			replaced[i] = replace(e)
+		}
+		cfg.TableFunction = cfg.TableFunction.WithArgs(replaced...)
+	}
+
+	return cfg, refs, err
+}
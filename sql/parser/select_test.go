@@ -27,10 +27,10 @@ func TestParserSelect(t *testing.T) {
 		{"NoTableWithTuple", "SELECT (1, 2)",
 			planner.NewTree(planner.NewProjectionNode(nil,
 				[]planner.ProjectedField{
-					planner.ProjectedExpr{Expr: expr.LiteralExprList{
+					planner.ProjectedExpr{Expr: expr.TupleExpr{Elements: []expr.Expr{
 						expr.IntegerValue(1),
 						expr.IntegerValue(2),
-					}, ExprName: "(1, 2)"},
+					}}, ExprName: "(1, 2)"},
 				}, "")),
 			false,
 		},
@@ -48,10 +48,10 @@ func TestParserSelect(t *testing.T) {
 			planner.NewTree(planner.NewProjectionNode(nil,
 				[]planner.ProjectedField{
 					planner.ProjectedExpr{
-						Expr: expr.In(expr.IntegerValue(1), expr.LiteralExprList{
+						Expr: expr.In(expr.IntegerValue(1), expr.TupleExpr{Elements: []expr.Expr{
 							expr.IntegerValue(1),
 							expr.IntegerValue(2),
-						}),
+						}}),
 						ExprName: "1 in (1, 2)",
 					},
 					planner.ProjectedExpr{Expr: expr.IntegerValue(3), ExprName: "3"},
@@ -181,6 +181,21 @@ func TestParserSelect(t *testing.T) {
 					scanner.DESC,
 				)),
 			false},
+		{"WithOrderBy COLLATE", "SELECT * FROM test ORDER BY a COLLATE nocase DESC",
+			planner.NewTree(
+				planner.NewCollatedSortNode(
+					planner.NewProjectionNode(
+						planner.NewTableInputNode("test"),
+						[]planner.ProjectedField{planner.Wildcard{}},
+						"test",
+					),
+					expr.Path(parsePath(t, "a")),
+					scanner.DESC,
+					"nocase",
+				)),
+			false},
+		{"WithOrderBy unknown COLLATE", "SELECT * FROM test ORDER BY a COLLATE foo", nil, true},
+		{"WithOrderBy missing COLLATE name", "SELECT * FROM test ORDER BY a COLLATE DESC", nil, true},
 		{"WithLimit", "SELECT * FROM test WHERE age = 10 LIMIT 20",
 			planner.NewTree(
 				planner.NewLimitNode(
@@ -238,6 +253,221 @@ func TestParserSelect(t *testing.T) {
 					"test",
 				)),
 			false},
+		{"WithTableAlias", "SELECT t.a FROM test AS t WHERE t.age = 10",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSelectionNode(
+						planner.NewAliasedTableInputNode("test", "t"),
+						expr.Eq(expr.Path(parsePath(t, "t.age")), expr.IntegerValue(10)),
+					),
+					[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "t.a")), ExprName: "t.a"}},
+					"test",
+				)),
+			false},
+		{"WithTableAliasWithoutAS", "SELECT * FROM test t",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewAliasedTableInputNode("test", "t"),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"test",
+				)),
+			false},
+		{"WithMissingTableAlias", "SELECT * FROM test AS WHERE a = 1", nil, true},
+		{"WithTableFunction", "SELECT value FROM UNNEST(?) WHERE value > 1",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSelectionNode(
+						planner.NewTableFunctionInputNode(&expr.UnnestFunc{Expr: expr.PositionalParam(1)}, ""),
+						expr.Gt(expr.Path(parsePath(t, "value")), expr.IntegerValue(1)),
+					),
+					[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "value")), ExprName: "value"}},
+					"",
+				)),
+			false},
+		{"WithAliasedTableFunction", "SELECT * FROM unnest([1, 2]) AS u",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewTableFunctionInputNode(&expr.UnnestFunc{Expr: expr.LiteralExprList{expr.IntegerValue(1), expr.IntegerValue(2)}}, "u"),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"",
+				)),
+			false},
+		{"WithUnknownTableFunction", "SELECT * FROM foo(a)", nil, true},
+		{"WithInvalidTableFunctionArgs", "SELECT * FROM UNNEST(a, b)", nil, true},
+		{"WithTableFunctionPath", "SELECT * FROM UNNEST(a)", nil, true},
+		{"WithTableFunctionPathInSource", "SELECT * FROM test t, LATERAL (SELECT * FROM (SELECT * FROM UNNEST(t.a)) x) u", nil, true},
+		{"WithPivot", "SELECT * FROM test AS t PIVOT (SUM(a) FOR b IN ('x', 1)) WHERE x > 1",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSelectionNode(
+						planner.NewPivotNode(
+							planner.NewAliasedTableInputNode("test", "t"),
+							&expr.SumFunc{Expr: expr.Path(parsePath(t, "a"))},
+							expr.Path(parsePath(t, "b")),
+							[]expr.Expr{expr.TextValue("x"), expr.IntegerValue(1)},
+						),
+						expr.Gt(expr.Path(parsePath(t, "x")), expr.IntegerValue(1)),
+					),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"test",
+				)),
+			false},
+		{"WithPivotNotAggregate", "SELECT * FROM test PIVOT (a FOR b IN ('x'))", nil, true},
+		{"WithPivotMissingFor", "SELECT * FROM test PIVOT (SUM(a) b IN ('x'))", nil, true},
+		{"WithPivotMissingValues", "SELECT * FROM test PIVOT (SUM(a) FOR b IN ())", nil, true},
+		{"WithSampleRows", "SELECT * FROM test AS t SAMPLE 10 ROWS SEED 42 WHERE a > 1",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSelectionNode(
+						planner.NewRowsSampleNode(planner.NewAliasedTableInputNode("test", "t"), 10, func() *int64 { s := int64(42); return &s }()),
+						expr.Gt(expr.Path(parsePath(t, "a")), expr.IntegerValue(1)),
+					),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"test",
+				)),
+			false},
+		{"WithSamplePercent", "SELECT * FROM test SAMPLE 1.5 PERCENT",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewPercentSampleNode(planner.NewTableInputNode("test"), 1.5, nil),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"test",
+				)),
+			false},
+		{"WithSampleMissingUnit", "SELECT * FROM test SAMPLE 10", nil, true},
+		{"WithSampleInvalidPercent", "SELECT * FROM test SAMPLE 200 PERCENT", nil, true},
+		{"WithLateral", "SELECT * FROM test t, LATERAL (SELECT b FROM foo WHERE c = t.a) AS f",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewLateralJoinNode(
+						planner.NewAliasedTableInputNode("test", "t"),
+						planner.NewTree(
+							planner.NewProjectionNode(
+								planner.NewSelectionNode(
+									planner.NewTableInputNode("foo"),
+									expr.Eq(expr.Path(parsePath(t, "c")), expr.NamedParam("t.a")),
+								),
+								[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "b")), ExprName: "b"}},
+								"foo",
+							)),
+						"f",
+						[]expr.Path{expr.Path(parsePath(t, "t.a"))},
+						nil,
+						false,
+					),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"test",
+				)),
+			false},
+		{"WithLeftJoinLateral", "SELECT * FROM test LEFT JOIN LATERAL (SELECT * FROM foo AS test WHERE c = test.a) f ON f.b > 1",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewLateralJoinNode(
+						planner.NewTableInputNode("test"),
+						planner.NewTree(
+							planner.NewProjectionNode(
+								planner.NewSelectionNode(
+									planner.NewAliasedTableInputNode("foo", "test"),
+									expr.Eq(expr.Path(parsePath(t, "c")), expr.Path(parsePath(t, "test.a"))),
+								),
+								[]planner.ProjectedField{planner.Wildcard{}},
+								"foo",
+							)),
+						"f",
+						nil,
+						expr.Gt(expr.Path(parsePath(t, "f.b")), expr.IntegerValue(1)),
+						true,
+					),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"test",
+				)),
+			false},
+		{"WithLateralMissingAlias", "SELECT * FROM test, LATERAL (SELECT * FROM foo)", nil, true},
+		{"WithLateralMissingSelect", "SELECT * FROM test, LATERAL (foo) f", nil, true},
+		{"WithJoinLateralMissingOn", "SELECT * FROM test JOIN LATERAL (SELECT * FROM foo) f", nil, true},
+		{"WithLateralInto", "SELECT * FROM test, LATERAL (SELECT * INTO bar FROM foo) f", nil, true},
+		{"WithValues", "SELECT * FROM (VALUES {a: 1}, ?) AS v",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewValuesInputNode(expr.LiteralExprList{
+						expr.KVPairs{expr.KVPair{K: "a", V: expr.IntegerValue(1)}},
+						expr.PositionalParam(1),
+					}, "v"),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"",
+				)),
+			false},
+		{"WithSubquerySource", "SELECT a FROM (SELECT * FROM test) t",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSubqueryInputNode(planner.NewTree(
+						planner.NewProjectionNode(
+							planner.NewTableInputNode("test"),
+							[]planner.ProjectedField{planner.Wildcard{}},
+							"test",
+						)), "t"),
+					[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+					"",
+				)),
+			false},
+		{"WithJoinValues", "SELECT * FROM test t JOIN (VALUES {a: 1}) v ON v.a = t.a",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewLateralJoinNode(
+						planner.NewAliasedTableInputNode("test", "t"),
+						planner.NewTree(
+							planner.NewProjectionNode(
+								planner.NewValuesInputNode(expr.LiteralExprList{
+									expr.KVPairs{expr.KVPair{K: "a", V: expr.IntegerValue(1)}},
+								}, ""),
+								[]planner.ProjectedField{planner.Wildcard{}},
+								"",
+							)),
+						"v",
+						nil,
+						expr.Eq(expr.Path(parsePath(t, "v.a")), expr.Path(parsePath(t, "t.a"))),
+						false,
+					),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"test",
+				)),
+			false},
+		{"WithValuesMissingParen", "SELECT * FROM (VALUES {a: 1}", nil, true},
+		{"WithValuesNotDocument", "SELECT * FROM (VALUES 1)", nil, true},
+		{"WithParenthesizedTable", "SELECT * FROM (test)", nil, true},
+		{"WithSubquerySourceInto", "SELECT * FROM (SELECT * INTO bar FROM foo) f", nil, true},
+		{"WithJoinSubqueryNotLateral", "SELECT * FROM test, (SELECT * FROM foo) f", nil, true},
+		{"Values", "VALUES {a: 1}, {a: 2}",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewValuesInputNode(expr.LiteralExprList{
+						expr.KVPairs{expr.KVPair{K: "a", V: expr.IntegerValue(1)}},
+						expr.KVPairs{expr.KVPair{K: "a", V: expr.IntegerValue(2)}},
+					}, ""),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"",
+				)),
+			false},
+		{"ValuesNotDocument", "VALUES (1, 2)", nil, true},
+		{"WithInto", "SELECT a INTO foo FROM test WHERE age = 10 LIMIT 10",
+			planner.NewTree(
+				planner.NewInsertionNode(
+					planner.NewLimitNode(
+						planner.NewProjectionNode(
+							planner.NewSelectionNode(
+								planner.NewTableInputNode("test"),
+								expr.Eq(expr.Path(parsePath(t, "age")), expr.IntegerValue(10)),
+							),
+							[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+							"test",
+						),
+						10,
+					),
+					"foo",
+				)),
+			false},
+		{"WithMissingIntoTable", "SELECT * INTO FROM test", nil, true},
+		{"WithIntoAfterFrom", "SELECT * FROM test INTO foo", nil, true},
 		{"Invalid use of MIN() aggregator", "SELECT * FROM test LIMIT min(0)", nil, true},
 		{"Invalid use of COUNT() aggregator", "SELECT * FROM test OFFSET x(*)", nil, true},
 		{"Invalid use of MAX() aggregator", "SELECT * FROM test LIMIT max(0)", nil, true},
@@ -258,3 +488,154 @@ func TestParserSelect(t *testing.T) {
 		})
 	}
 }
+
+func TestParserWith(t *testing.T) {
+	cte := func(where expr.Expr) *planner.Tree {
+		return planner.NewTree(
+			planner.NewProjectionNode(
+				planner.NewSelectionNode(planner.NewTableInputNode("test"), where),
+				[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"}},
+				"test",
+			))
+	}
+
+	tests := []struct {
+		name     string
+		s        string
+		expected *planner.Tree
+		mustFail bool
+	}{
+		{"Single", "WITH foo AS (SELECT a FROM test WHERE a > 1) SELECT * FROM foo",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSubqueryInputNode(cte(expr.Gt(expr.Path(parsePath(t, "a")), expr.IntegerValue(1))), ""),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"",
+				)),
+			false},
+		{"Alias", "WITH foo AS (SELECT a FROM test WHERE a > 1) SELECT f.a FROM foo AS f WHERE f.a < 10",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSelectionNode(
+						planner.NewSubqueryInputNode(cte(expr.Gt(expr.Path(parsePath(t, "a")), expr.IntegerValue(1))), "f"),
+						expr.Lt(expr.Path(parsePath(t, "f.a")), expr.IntegerValue(10)),
+					),
+					[]planner.ProjectedField{planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "f.a")), ExprName: "f.a"}},
+					"",
+				)),
+			false},
+		{"Multiple", "WITH foo AS (SELECT a FROM test WHERE a > 1), bar AS (SELECT * FROM foo) SELECT * FROM bar",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSubqueryInputNode(
+						planner.NewTree(
+							planner.NewProjectionNode(
+								planner.NewSubqueryInputNode(cte(expr.Gt(expr.Path(parsePath(t, "a")), expr.IntegerValue(1))), ""),
+								[]planner.ProjectedField{planner.Wildcard{}},
+								"",
+							)),
+						""),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"",
+				)),
+			false},
+		{"Shadowing table", "WITH test AS (SELECT a FROM test WHERE a > 1) SELECT * FROM test",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewSubqueryInputNode(cte(expr.Gt(expr.Path(parsePath(t, "a")), expr.IntegerValue(1))), ""),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"",
+				)),
+			false},
+		{"Unused", "WITH foo AS (SELECT a FROM test) SELECT * FROM test",
+			planner.NewTree(
+				planner.NewProjectionNode(
+					planner.NewTableInputNode("test"),
+					[]planner.ProjectedField{planner.Wildcard{}},
+					"test",
+				)),
+			false},
+		{"Missing AS", "WITH foo (SELECT a FROM test) SELECT * FROM foo", nil, true},
+		{"Missing parentheses", "WITH foo AS SELECT a FROM test SELECT * FROM foo", nil, true},
+		{"Not a SELECT", "WITH foo AS (DELETE FROM test) SELECT * FROM foo", nil, true},
+		{"Into", "WITH foo AS (SELECT a INTO bar FROM test) SELECT * FROM foo", nil, true},
+		{"Duplicate", "WITH foo AS (SELECT a FROM test), foo AS (SELECT a FROM test) SELECT * FROM foo", nil, true},
+		{"Missing statement", "WITH foo AS (SELECT a FROM test)", nil, true},
+		{"Followed by DELETE", "WITH foo AS (SELECT a FROM test) DELETE FROM foo", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := ParseQuery(test.s)
+			if !test.mustFail {
+				require.NoError(t, err)
+				require.Len(t, q.Statements, 1)
+				require.EqualValues(t, test.expected, q.Statements[0])
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+
+	t.Run("Scope", func(t *testing.T) {
+		// common table expressions are only visible in their statement
+		q, err := ParseQuery("WITH foo AS (SELECT a FROM test) SELECT * FROM foo; SELECT * FROM foo")
+		require.NoError(t, err)
+		require.Len(t, q.Statements, 2)
+		require.EqualValues(t, planner.NewTree(
+			planner.NewProjectionNode(
+				planner.NewTableInputNode("foo"),
+				[]planner.ProjectedField{planner.Wildcard{}},
+				"foo",
+			)), q.Statements[1])
+	})
+}
+
+func TestParserSelectOrderByNulls(t *testing.T) {
+	tests := []struct {
+		s         string
+		direction scanner.Token
+		nulls     planner.NullsOrder
+	}{
+		{"ORDER BY a NULLS FIRST", scanner.ASC, planner.NullsFirst},
+		{"ORDER BY a NULLS LAST", scanner.ASC, planner.NullsLast},
+		{"ORDER BY a ASC NULLS FIRST", scanner.ASC, planner.NullsFirst},
+		{"ORDER BY a ASC NULLS LAST", scanner.ASC, planner.NullsLast},
+		{"ORDER BY a DESC NULLS FIRST", scanner.DESC, planner.NullsFirst},
+		{"ORDER BY a DESC nulls last", scanner.DESC, planner.NullsLast},
+		{"ORDER BY a DESC", scanner.DESC, planner.NullsDefault},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			q, err := ParseQuery("SELECT * FROM test " + test.s)
+			require.NoError(t, err)
+			require.Len(t, q.Statements, 1)
+
+			expected := planner.NewTree(
+				planner.NewSortNodeWithNulls(
+					planner.NewProjectionNode(
+						planner.NewTableInputNode("test"),
+						[]planner.ProjectedField{planner.Wildcard{}},
+						"test",
+					),
+					expr.Path(parsePath(t, "a")),
+					test.direction,
+					"",
+					test.nulls,
+				))
+			require.EqualValues(t, expected, q.Statements[0])
+		})
+	}
+
+	for _, s := range []string{
+		"ORDER BY a NULLS",
+		"ORDER BY a NULLS MIDDLE",
+		"ORDER BY a NULLS FIRST DESC",
+	} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseQuery("SELECT * FROM test " + s)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/planner"
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+// parseExists parses a subquery following the EXISTS token, which has already been consumed.
+func (p *Parser) parseExists(not bool) (expr.Expr, error) {
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != scanner.LPAREN {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
+	}
+
+	start := p.buf.Len()
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.SELECT {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"SELECT"}, pos)
+	}
+	p.Unscan()
+
+	s, err := p.parseSubqueryUntilEnd(start, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	return expr.ExistsExpr{Subquery: s, Not: not}, nil
+}
+
+// parseSubqueryUntilEnd parses a SELECT statement followed by a right parenthesis.
+// The left parenthesis, found at pos, has already been consumed, and start is the
+// position of the statement in the expression buffer.
+func (p *Parser) parseSubqueryUntilEnd(start int, pos scanner.Pos) (expr.Subquery, error) {
+	if p.restrictExpr {
+		return expr.Subquery{}, &ParseError{Message: "subqueries are not allowed", Pos: pos}
+	}
+
+	// skip the SELECT token
+	p.ScanIgnoreWhitespace()
+
+	p.correlated++
+	cfg, err := p.parseSelectConfig()
+	p.correlated--
+	if err != nil {
+		return expr.Subquery{}, err
+	}
+	if cfg.IntoTable != "" {
+		return expr.Subquery{}, &ParseError{Message: "a subquery cannot create a table", Pos: pos}
+	}
+
+	// within a subquery, the name of the table qualifies its fields like an alias would,
+	// to tell them apart from those of the outer statement.
+	if cfg.TableAlias == "" {
+		cfg.TableAlias = cfg.sourceName()
+	}
+
+	text := strings.TrimSpace(p.buf.String()[start:])
+
+	if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.RPAREN {
+		return expr.Subquery{}, newParseError(scanner.Tokstr(tok, lit), []string{")"}, pos)
+	}
+
+	t, err := cfg.ToTree()
+	if err != nil {
+		return expr.Subquery{}, err
+	}
+
+	return expr.Subquery{Statement: &subqueryStatement{cfg: *cfg, tree: t, text: text}}, nil
+}
+
+// subqueryStatement implements the expr.SubqueryStatement interface.
+// It keeps the configuration of the statement to correlate it with the outer statement
+// once the latter is turned into a tree.
+type subqueryStatement struct {
+	cfg  SelectConfig
+	tree *planner.Tree
+	text string
+}
+
+func (s *subqueryStatement) Run(tx *database.Transaction, params []expr.Param) (document.Stream, error) {
+	res, err := s.tree.Run(tx, params)
+	if err != nil {
+		return document.Stream{}, err
+	}
+
+	return res.Stream, nil
+}
+
+func (s *subqueryStatement) String() string {
+	return s.text
+}
+
+// correlateSubqueries returns a copy of e where the subqueries referring to the outer
+// source name are replaced by correlated subqueries. See expr.Subquery.
+// Subqueries are opaque to expr.Walk: the references to name made by the subqueries
+// of the subqueries are collected by SelectConfig.correlate.
+func correlateSubqueries(e expr.Expr, name string) (expr.Expr, error) {
+	if e == nil || name == "" {
+		return e, nil
+	}
+
+	var err error
+	e = expr.Walk(e, func(e expr.Expr) (expr.Expr, bool) {
+		if err != nil {
+			return e, false
+		}
+
+		switch t := e.(type) {
+		case expr.Subquery:
+			t, err = correlateSubquery(t, name)
+			return t, false
+		case expr.ExistsExpr:
+			t.Subquery, err = correlateSubquery(t.Subquery, name)
+			return t, false
+		}
+
+		return e, true
+	})
+
+	return e, err
+}
+
+func correlateSubquery(s expr.Subquery, name string) (expr.Subquery, error) {
+	st, ok := s.Statement.(*subqueryStatement)
+	if !ok {
+		return s, nil
+	}
+
+	cfg, refs, err := st.cfg.correlate(name)
+	if err != nil || len(refs) == 0 {
+		return s, err
+	}
+
+	t, err := cfg.ToTree()
+	if err != nil {
+		return s, err
+	}
+
+	return expr.Subquery{
+		Statement: &subqueryStatement{cfg: st.cfg, tree: t, text: st.text},
+		Refs:      refs,
+	}, nil
+}
+
+// correlateNestedSubquery returns a copy of s, a subquery nested within the statement
+// being correlated with name, where every path referring to name is replaced by a named
+// parameter, along with the list of the replaced paths.
+// The parameters are passed by the enclosing statement, which receives them from
+// the outer one, and s is correlated with the source of the enclosing statement later on.
+func correlateNestedSubquery(s expr.Subquery, name string) (expr.Subquery, []expr.Path, error) {
+	st, ok := s.Statement.(*subqueryStatement)
+	if !ok {
+		return s, nil, nil
+	}
+
+	cfg, refs, err := st.cfg.correlate(name)
+	if err != nil || len(refs) == 0 {
+		return s, nil, err
+	}
+
+	t, err := cfg.ToTree()
+	if err != nil {
+		return s, nil, err
+	}
+
+	return expr.Subquery{
+		Statement: &subqueryStatement{cfg: cfg, tree: t, text: st.text},
+		Refs:      s.Refs,
+	}, refs, nil
+}
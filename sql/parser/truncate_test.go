@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/sql/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		expected query.Statement
+		errored  bool
+	}{
+		{"Truncate table", "TRUNCATE TABLE test", query.TruncateTableStmt{TableName: "test"}, false},
+		{"Lowercase", "truncate table test", query.TruncateTableStmt{TableName: "test"}, false},
+		{"Missing TABLE", "TRUNCATE test", nil, true},
+		{"Missing table name", "TRUNCATE TABLE", nil, true},
+		{"Extra tokens", "TRUNCATE TABLE test WHERE a = 1", nil, true},
+		{"Multiple tables", "TRUNCATE TABLE test, foo", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := ParseQuery(test.s)
+			if test.errored {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, q.Statements, 1)
+			require.EqualValues(t, test.expected, q.Statements[0])
+		})
+	}
+}
@@ -10,7 +10,7 @@ import (
 // parseUpdateStatement parses a update string and returns a Statement AST object.
 // This function assumes the UPDATE token has already been consumed.
 func (p *Parser) parseUpdateStatement() (*planner.Tree, error) {
-	var cfg updateConfig
+	var cfg UpdateConfig
 	var err error
 
 	// Parse table name
@@ -21,13 +21,18 @@ func (p *Parser) parseUpdateStatement() (*planner.Tree, error) {
 		return nil, pErr
 	}
 
+	err = p.parseNoSample("UPDATE")
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse clause: SET or UNSET.
 	tok, pos, lit := p.ScanIgnoreWhitespace()
 	switch tok {
 	case scanner.SET:
 		cfg.SetPairs, err = p.parseSetClause()
 	case scanner.UNSET:
-		cfg.UnsetFields, err = p.parseUnsetClause()
+		cfg.UnsetPaths, err = p.parseUnsetClause()
 	default:
 		err = newParseError(scanner.Tokstr(tok, lit), []string{"SET", "UNSET"}, pos)
 	}
@@ -41,12 +46,18 @@ func (p *Parser) parseUpdateStatement() (*planner.Tree, error) {
 		return nil, err
 	}
 
+	// Parse returned fields: "RETURNING fields".
+	cfg.Returning, err = p.parseReturning()
+	if err != nil {
+		return nil, err
+	}
+
 	return cfg.ToTree(), nil
 }
 
 // parseSetClause parses the "SET" clause of the query.
-func (p *Parser) parseSetClause() ([]updateSetPair, error) {
-	var pairs []updateSetPair
+func (p *Parser) parseSetClause() ([]UpdateSetPair, error) {
+	var pairs []UpdateSetPair
 
 	firstPair := true
 	for {
@@ -77,7 +88,7 @@ func (p *Parser) parseSetClause() ([]updateSetPair, error) {
 		if err != nil {
 			return nil, err
 		}
-		pairs = append(pairs, updateSetPair{path, expr})
+		pairs = append(pairs, UpdateSetPair{path, expr})
 
 		firstPair = false
 	}
@@ -85,12 +96,13 @@ func (p *Parser) parseSetClause() ([]updateSetPair, error) {
 	return pairs, nil
 }
 
-func (p *Parser) parseUnsetClause() ([]string, error) {
-	var fields []string
+// parseUnsetClause parses the "UNSET" clause of the query.
+func (p *Parser) parseUnsetClause() ([]document.Path, error) {
+	var paths []document.Path
 
-	firstField := true
+	firstPath := true
 	for {
-		if !firstField {
+		if !firstPath {
 			// Scan for a comma.
 			tok, _, _ := p.ScanIgnoreWhitespace()
 			if tok != scanner.COMMA {
@@ -99,41 +111,47 @@ func (p *Parser) parseUnsetClause() ([]string, error) {
 			}
 		}
 
-		// Scan the identifier for the path to unset.
-		tok, pos, lit := p.ScanIgnoreWhitespace()
-		if tok != scanner.IDENT {
-			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"identifier"}, pos)
+		// Scan the path to unset.
+		path, err := p.parsePath()
+		if err != nil {
+			pErr := err.(*ParseError)
+			pErr.Expected = []string{"path"}
+			return nil, pErr
 		}
-		fields = append(fields, lit)
+		paths = append(paths, path)
 
-		firstField = false
+		firstPath = false
 	}
-	return fields, nil
+	return paths, nil
 }
 
 // UpdateConfig holds UPDATE configuration.
-type updateConfig struct {
+type UpdateConfig struct {
 	TableName string
 
 	// SetPairs is used along with the Set clause. It holds
 	// each path with its corresponding value that
 	// should be set in the document.
-	SetPairs []updateSetPair
+	SetPairs []UpdateSetPair
 
-	// UnsetFields is used along with the Unset clause. It holds
+	// UnsetPaths is used along with the Unset clause. It holds
 	// each path that should be unset from the document.
-	UnsetFields []string
+	UnsetPaths []document.Path
 
 	WhereExpr expr.Expr
+
+	// Returning holds the fields of the RETURNING clause, if any.
+	Returning []planner.ProjectedField
 }
 
-type updateSetPair struct {
-	path document.Path
-	e    expr.Expr
+// UpdateSetPair associates a path with the expression of its new value.
+type UpdateSetPair struct {
+	Path document.Path
+	E    expr.Expr
 }
 
 // ToTree turns the statement into an expression tree.
-func (cfg updateConfig) ToTree() *planner.Tree {
+func (cfg UpdateConfig) ToTree() *planner.Tree {
 	t := planner.NewTableInputNode(cfg.TableName)
 
 	if cfg.WhereExpr != nil {
@@ -142,15 +160,19 @@ func (cfg updateConfig) ToTree() *planner.Tree {
 
 	if cfg.SetPairs != nil {
 		for _, pair := range cfg.SetPairs {
-			t = planner.NewSetNode(t, pair.path, pair.e)
+			t = planner.NewSetNode(t, pair.Path, pair.E)
 		}
-	} else if cfg.UnsetFields != nil {
-		for _, name := range cfg.UnsetFields {
-			t = planner.NewUnsetNode(t, name)
+	} else if cfg.UnsetPaths != nil {
+		for _, path := range cfg.UnsetPaths {
+			t = planner.NewUnsetNode(t, path)
 		}
 	}
 
 	t = planner.NewReplacementNode(t, cfg.TableName)
 
+	if cfg.Returning != nil {
+		t = planner.NewReturningNode(t, cfg.Returning, cfg.TableName)
+	}
+
 	return &planner.Tree{Root: t}
 }
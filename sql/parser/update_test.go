@@ -96,7 +96,7 @@ func TestParserUpdate(t *testing.T) {
 				planner.NewReplacementNode(
 					planner.NewUnsetNode(
 						planner.NewTableInputNode("test"),
-						"a",
+						parsePath(t, "a"),
 					),
 					"test",
 				)),
@@ -110,18 +110,53 @@ func TestParserUpdate(t *testing.T) {
 								planner.NewTableInputNode("test"),
 								expr.Eq(expr.Path(parsePath(t, "age")), expr.IntegerValue(10)),
 							),
-							"a",
+							parsePath(t, "a"),
 						),
-						"b",
+						parsePath(t, "b"),
 					),
 					"test",
 				)),
 			false},
+		{"UNSET/Nested path and array index", "UPDATE test UNSET a.b.c, d[2]",
+			planner.NewTree(
+				planner.NewReplacementNode(
+					planner.NewUnsetNode(
+						planner.NewUnsetNode(
+							planner.NewTableInputNode("test"),
+							parsePath(t, "a.b.c"),
+						),
+						parsePath(t, "d[2]"),
+					),
+					"test",
+				)),
+			false},
+		{"SET/With returning", "UPDATE test SET a = 1 WHERE age = 10 RETURNING a, pk()",
+			planner.NewTree(
+				planner.NewReturningNode(
+					planner.NewReplacementNode(
+						planner.NewSetNode(
+							planner.NewSelectionNode(
+								planner.NewTableInputNode("test"),
+								expr.Eq(expr.Path(parsePath(t, "age")), expr.IntegerValue(10)),
+							),
+							parsePath(t, "a"), expr.IntegerValue(1),
+						),
+						"test",
+					),
+					[]planner.ProjectedField{
+						planner.ProjectedExpr{Expr: expr.Path(parsePath(t, "a")), ExprName: "a"},
+						planner.ProjectedExpr{Expr: &expr.PKFunc{}, ExprName: "pk()"},
+					},
+					"test",
+				)),
+			false},
+		{"Empty returning", "UPDATE test SET a = 1 RETURNING", nil, true},
 		{"Trailing comma", "UPDATE test SET a = 1, WHERE age = 10", nil, true},
 		{"No SET", "UPDATE test WHERE age = 10", nil, true},
 		{"No pair", "UPDATE test SET WHERE age = 10", nil, true},
 		{"query.Field only", "UPDATE test SET a WHERE age = 10", nil, true},
 		{"No value", "UPDATE test SET a = WHERE age = 10", nil, true},
+		{"With SAMPLE", "UPDATE test SAMPLE 10 ROWS SET a = 1", nil, true},
 	}
 
 	for _, test := range tests {
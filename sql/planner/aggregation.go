@@ -39,6 +39,31 @@ func (n *AggregationNode) toStream(st document.Stream) (document.Stream, error)
 	return st.Aggregate(n.Aggregators...), nil
 }
 
+// Clone returns a deep copy of the node and its children.
+func (n *AggregationNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.Aggregators = make([]document.AggregatorBuilder, len(n.Aggregators))
+	for i, agg := range n.Aggregators {
+		c.Aggregators[i] = cloneAggregatorBuilder(agg)
+	}
+	return &c
+}
+
+// cloneAggregatorBuilder returns a deep copy of the aggregators that are expressions.
+func cloneAggregatorBuilder(agg document.AggregatorBuilder) document.AggregatorBuilder {
+	switch t := agg.(type) {
+	case *ProjectedGroupAggregatorBuilder:
+		return &ProjectedGroupAggregatorBuilder{Expr: expr.Clone(t.Expr), exprName: t.exprName}
+	case expr.Expr:
+		if c, ok := expr.Clone(t).(document.AggregatorBuilder); ok {
+			return c
+		}
+	}
+
+	return agg
+}
+
 func (n *AggregationNode) String() string {
 	var b strings.Builder
 
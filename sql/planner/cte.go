@@ -0,0 +1,258 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// A MaterializedCTE is a common table expression whose documents are shared by every
+// node that reads it. Its subquery is run the first time one of these nodes is read and
+// its documents are kept until the end of the execution of the tree listing the CTE
+// in its Materialized field.
+// A MaterializedCTE is never modified: the documents of each execution are stored in a cteResult.
+type MaterializedCTE struct {
+	name     string
+	subquery *Tree
+}
+
+// NewMaterializedCTE creates a common table expression named name, returning the documents of subquery.
+func NewMaterializedCTE(name string, subquery *Tree) *MaterializedCTE {
+	return &MaterializedCTE{
+		name:     name,
+		subquery: subquery,
+	}
+}
+
+// cteParamName is the name of the parameter through which Tree.Run passes the results
+// of its materialized common table expressions to the nodes reading them, including
+// the nodes of its subqueries, which receive the parameters of the outer statement.
+// It cannot be written in a query.
+const cteParamName = "\x00ctes"
+
+// cteResults associates the materialized common table expressions of an execution with their documents.
+type cteResults map[*MaterializedCTE]*cteResult
+
+// withCTEResults returns params with a parameter holding the results of ctes,
+// and of the common table expressions of the enclosing executions, if any.
+func withCTEResults(params []expr.Param, ctes []*MaterializedCTE) ([]expr.Param, cteResults) {
+	outer := cteResultsOf(params)
+
+	results := make(cteResults, len(outer)+len(ctes))
+	for cte, r := range outer {
+		results[cte] = r
+	}
+
+	own := make(cteResults, len(ctes))
+	for _, cte := range ctes {
+		own[cte] = &cteResult{cte: cte}
+		results[cte] = own[cte]
+	}
+
+	// don't modify the array of the caller
+	params = append(params[:len(params):len(params)], expr.Param{Name: cteParamName, Value: results})
+	return params, own
+}
+
+// cteResultsOf returns the results passed in params by the innermost enclosing execution.
+func cteResultsOf(params []expr.Param) cteResults {
+	for i := len(params) - 1; i >= 0; i-- {
+		if params[i].Name == cteParamName {
+			return params[i].Value.(cteResults)
+		}
+	}
+
+	return nil
+}
+
+// release discards the documents of every result.
+func (r cteResults) release() {
+	for _, res := range r {
+		res.reset()
+	}
+}
+
+// A cteResult holds the documents returned by the subquery of a materialized
+// common table expression during one execution.
+// Once they exceed the memory budget of the statement, the documents are moved to a spill store.
+type cteResult struct {
+	cte *MaterializedCTE
+
+	loaded   bool
+	docs     []document.Document
+	db       *database.Database
+	mem      *memoryBudget
+	buffered int64
+	spill    *spillStore
+}
+
+// load runs the subquery, unless it was already run.
+func (c *cteResult) load(tx *database.Transaction, params []expr.Param, mem *memoryBudget) error {
+	if c.loaded {
+		return nil
+	}
+
+	c.db = tx.DB()
+	c.mem = mem
+
+	res, err := c.cte.subquery.Run(tx, params)
+	if err != nil {
+		return err
+	}
+
+	err = res.Iterate(c.add)
+	if err != nil {
+		c.reset()
+		return err
+	}
+
+	c.loaded = true
+	return nil
+}
+
+// add stores a copy of d.
+func (c *cteResult) add(d document.Document) error {
+	if c.spill != nil {
+		return c.spill.Put(nil, d)
+	}
+
+	var fb document.FieldBuffer
+	err := fb.Copy(d)
+	if err != nil {
+		return err
+	}
+
+	size := documentSize(&fb)
+	if !c.mem.grow(size) {
+		return c.spillDocuments(&fb)
+	}
+	c.buffered += size
+
+	c.docs = append(c.docs, &fb)
+	return nil
+}
+
+// spillDocuments moves the stored documents and d to a spill store.
+func (c *cteResult) spillDocuments(d document.Document) error {
+	var err error
+
+	c.spill, err = newSpillStore(c.db)
+	if err != nil {
+		return err
+	}
+
+	for _, sd := range c.docs {
+		err = c.spill.Put(nil, sd)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.mem.release(c.buffered)
+	c.buffered = 0
+	c.docs = nil
+
+	return c.spill.Put(nil, d)
+}
+
+// iterate calls fn with every stored document, in the order they were returned by the subquery.
+func (c *cteResult) iterate(fn func(d document.Document) error) error {
+	if c.spill != nil {
+		return c.spill.Iterate(false, fn)
+	}
+
+	for _, d := range c.docs {
+		err := fn(d)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reset discards the stored documents.
+func (c *cteResult) reset() {
+	c.mem.release(c.buffered)
+	c.buffered = 0
+	c.docs = nil
+	c.loaded = false
+
+	if c.spill != nil {
+		c.spill.Close()
+		c.spill = nil
+	}
+}
+
+type materializedInputNode struct {
+	node
+
+	cte   *MaterializedCTE
+	alias string
+
+	result *cteResult
+	tx     *database.Transaction
+	params []expr.Param
+	mem    *memoryBudget
+}
+
+var _ inputNode = (*materializedInputNode)(nil)
+
+// NewMaterializedInputNode creates an input node that reads the documents of a materialized
+// common table expression. If alias is not empty, paths whose first field is the alias
+// are resolved against the returned documents.
+func NewMaterializedInputNode(cte *MaterializedCTE, alias string) Node {
+	return &materializedInputNode{
+		node: node{
+			op: Input,
+		},
+		cte:   cte,
+		alias: alias,
+	}
+}
+
+func (n *materializedInputNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
+	n.result = cteResultsOf(params)[n.cte]
+	return
+}
+
+func (n *materializedInputNode) setMemoryBudget(m *memoryBudget) {
+	n.mem = m
+}
+
+// Clone returns a copy of the node which shares the documents of the CTE
+// with n during the execution n is bound to.
+func (n *materializedInputNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	return &c
+}
+
+func (n *materializedInputNode) String() string {
+	if n.alias != "" {
+		return fmt.Sprintf("Materialized(%s AS %s)", n.cte.name, n.alias)
+	}
+
+	return fmt.Sprintf("Materialized(%s)", n.cte.name)
+}
+
+func (n *materializedInputNode) buildStream() (document.Stream, error) {
+	st := document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		if n.result == nil {
+			return fmt.Errorf("common table expression %s is read outside of its statement", n.cte.name)
+		}
+
+		err := n.result.load(n.tx, n.params, n.mem)
+		if err != nil {
+			return err
+		}
+
+		return n.result.iterate(fn)
+	}))
+
+	return aliasStream(st, n.alias), nil
+}
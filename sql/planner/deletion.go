@@ -17,6 +17,7 @@ type deletionNode struct {
 
 	tableName string
 	table     *database.Table
+	returning bool
 }
 
 var _ operationNode = (*deletionNode)(nil)
@@ -45,10 +46,12 @@ func (n *deletionNode) Bind(tx *database.Transaction, params []expr.Param) (err
 // to a buffer and delete them after the iteration is complete, and it will do that until there is no document
 // left to delete.
 // Increasing deleteBufferSize will occasionate less key searches (O(log n) for most engines) but will take more memory.
+// If the node is returning, the deleted documents are returned as a stream.
 func (n *deletionNode) toStream(st document.Stream) (document.Stream, error) {
 	st = st.Limit(deleteBufferSize)
 
 	keys := make([][]byte, deleteBufferSize)
+	var deleted []document.Document
 
 	for {
 		var i int
@@ -61,6 +64,10 @@ func (n *deletionNode) toStream(st document.Stream) (document.Stream, error) {
 			// copy the key and reuse the buffer
 			keys[i] = append(keys[i][0:0], k.Key()...)
 			i++
+
+			if n.returning {
+				return appendDocumentWithKey(&deleted, k.Key(), d)
+			}
 			return nil
 		})
 		if err != nil {
@@ -81,9 +88,23 @@ func (n *deletionNode) toStream(st document.Stream) (document.Stream, error) {
 		}
 	}
 
+	if n.returning {
+		return document.NewStream(document.NewIterator(deleted...)), nil
+	}
+
 	return document.Stream{}, nil
 }
 
+func (n *deletionNode) setReturning() {
+	n.returning = true
+}
+
+func (n *deletionNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	return &c
+}
+
 func (n *deletionNode) String() string {
 	return fmt.Sprintf("Delete(%s)", n.tableName)
 }
@@ -11,6 +11,8 @@ type dedupNode struct {
 
 	tableName string
 	indexes   map[string]database.Index
+	db        *database.Database
+	mem       *memoryBudget
 }
 
 func NewDedupNode(n Node, tableName string) Node {
@@ -24,6 +26,12 @@ func NewDedupNode(n Node, tableName string) Node {
 }
 
 func (n *dedupNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.db = tx.DB()
+
+	if n.tableName == "" {
+		return
+	}
+
 	table, err := tx.GetTable(n.tableName)
 	if err != nil {
 		return
@@ -33,9 +41,23 @@ func (n *dedupNode) Bind(tx *database.Transaction, params []expr.Param) (err err
 	return
 }
 
+func (n *dedupNode) setMemoryBudget(m *memoryBudget) {
+	n.mem = m
+}
+
 func (n *dedupNode) toStream(st document.Stream) (document.Stream, error) {
-	set := newDocumentHashSet(nil) // use default hashing algorithm
-	return st.Filter(set.Filter), nil
+	return document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		set := newDocumentHashSet(n.db, n.mem)
+		defer set.Close()
+
+		return st.Filter(set.Filter).Iterate(fn)
+	})), nil
+}
+
+func (n *dedupNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	return &c
 }
 
 func (n *dedupNode) String() string {
@@ -53,3 +53,8 @@ func (s *ExplainStmt) createResult(text string) (query.Result, error) {
 func (s *ExplainStmt) IsReadOnly() bool {
 	return true
 }
+
+// Tables returns the tables of the explained statement.
+func (s *ExplainStmt) Tables() []string {
+	return s.Statement.Tables()
+}
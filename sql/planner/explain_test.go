@@ -18,11 +18,13 @@ func TestExplainStmt(t *testing.T) {
 		{"EXPLAIN SELECT * FROM test", false, `"Table(test) -> ∏(*)"`},
 		{"EXPLAIN SELECT a + 1 FROM test", false, `"Table(test) -> ∏(a + 1)"`},
 		{"EXPLAIN SELECT a + 1 FROM test WHERE c > 10", false, `"Table(test) -> σ(cond: c > 10) -> ∏(a + 1)"`},
-		{"EXPLAIN SELECT a + 1 FROM test WHERE c > 10 AND d > 20", false, `"Table(test) -> σ(cond: d > 20) -> σ(cond: c > 10) -> ∏(a + 1)"`},
+		{"EXPLAIN SELECT a + 1 FROM test WHERE c > 10 AND d > 20", false, `"Table(test) -> σ(cond: c > 10) -> σ(cond: d > 20) -> ∏(a + 1)"`},
 		{"EXPLAIN SELECT a + 1 FROM test WHERE c > 10 OR d > 20", false, `"Table(test) -> σ(cond: c > 10 OR d > 20) -> ∏(a + 1)"`},
 		{"EXPLAIN SELECT a + 1 FROM test WHERE c IN [1 + 1, 2 + 2]", false, `"Table(test) -> σ(cond: c IN [2, 4]) -> ∏(a + 1)"`},
 		{"EXPLAIN SELECT a + 1 FROM test WHERE a > 10", false, `"Index(idx_a) -> ∏(a + 1)"`},
-		{"EXPLAIN SELECT a + 1 FROM test WHERE a > 10 AND b > 20 AND c > 30", false, `"Index(idx_b) -> σ(cond: c > 30) -> σ(cond: a > 10) -> ∏(a + 1)"`},
+		{"EXPLAIN SELECT a + 1 FROM test WHERE a > 10 AND b > 20 AND c > 30", false, `"Index(idx_b) -> σ(cond: a > 10) -> σ(cond: c > 30) -> ∏(a + 1)"`},
+		{"EXPLAIN SELECT t.a FROM test t WHERE t.a > 10", false, `"Index(idx_a) -> ∏(t.a)"`},
+		{"EXPLAIN SELECT * FROM test a WHERE a > 10", false, `"Table(test AS a) -> σ(cond: a > 10) -> ∏(*)"`},
 		{"EXPLAIN SELECT a + 1 FROM test WHERE c > 30 ORDER BY a DESC LIMIT 10 OFFSET 20", false, `"Table(test) -> σ(cond: c > 30) -> ∏(a + 1) -> Sort(a DESC) -> Offset(20) -> Limit(10)"`},
 		{"EXPLAIN SELECT a + 1 FROM test WHERE c > 30 GROUP BY a + 1 ORDER BY a DESC LIMIT 10 OFFSET 20", false, `"Table(test) -> σ(cond: c > 30) -> Group(a + 1) -> Aggregate(a + 1) -> ∏(a + 1) -> Sort(a DESC) -> Offset(20) -> Limit(10)"`},
 		{"EXPLAIN UPDATE test SET a = 10", false, `"Table(test) -> Set(a = 10) -> Replace(test)"`},
@@ -31,6 +33,10 @@ func TestExplainStmt(t *testing.T) {
 		{"EXPLAIN DELETE FROM test", false, `"Table(test) -> Delete(test)"`},
 		{"EXPLAIN DELETE FROM test WHERE c > 10", false, `"Table(test) -> σ(cond: c > 10) -> Delete(test)"`},
 		{"EXPLAIN DELETE FROM test WHERE a > 10", false, `"Index(idx_a) -> Delete(test)"`},
+		{"EXPLAIN VALUES {a: 1}, {a: 2}", false, `"Values({\"a\": 1}, {\"a\": 2}) -> ∏(*)"`},
+		{"EXPLAIN SELECT * FROM (VALUES {a: 1}) v", false, `"Values({\"a\": 1} AS v) -> ∏(*)"`},
+		{"EXPLAIN WITH x AS (SELECT * FROM test) SELECT * FROM x", false, `"Subquery(Table(test) -> ∏(*)) -> ∏(*)"`},
+		{"EXPLAIN WITH x AS (SELECT * FROM test) SELECT * FROM x WHERE EXISTS (SELECT * FROM x y WHERE y.a > x.a)", false, `"Materialized(x) -> σ(cond: EXISTS (SELECT * FROM x y WHERE y.a > x.a)) -> ∏(*)"`},
 	}
 
 	for _, test := range tests {
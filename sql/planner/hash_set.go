@@ -1,64 +1,90 @@
 package planner
 
 import (
-	"hash"
-	"hash/maphash"
-
+	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
 )
 
+// documentHashSet filters out documents that were already seen,
+// using their canonical key to detect duplicates.
+// Once the keys exceed the memory budget, they are moved to a spill store.
 type documentHashSet struct {
-	hash hash.Hash64
-	set  map[uint64]struct{}
-}
+	buf []byte
+	set map[string]struct{}
 
-func newDocumentHashSet(hash hash.Hash64) *documentHashSet {
-	if hash == nil {
-		hash = &maphash.Hash{}
-	}
+	db       *database.Database
+	mem      *memoryBudget
+	buffered int64
+	spill    *spillStore
+}
 
+func newDocumentHashSet(db *database.Database, mem *memoryBudget) *documentHashSet {
 	return &documentHashSet{
-		hash: hash,
-		set:  map[uint64]struct{}{},
+		set: map[string]struct{}{},
+		db:  db,
+		mem: mem,
 	}
 }
 
-func (s documentHashSet) generateKey(d document.Document) (uint64, error) {
-	defer s.hash.Reset()
+func (s *documentHashSet) Filter(d document.Document) (bool, error) {
+	var err error
 
-	fields, err := document.Fields(d)
+	s.buf, err = document.AppendKey(s.buf[:0], document.NewDocumentValue(d))
 	if err != nil {
-		return 0, err
+		return false, err
 	}
 
-	enc := document.NewValueEncoder(s.hash)
+	if s.spill != nil {
+		return s.spill.Add(s.buf)
+	}
 
-	for _, field := range fields {
-		value, err := d.GetByField(field)
-		if err != nil {
-			return 0, err
-		}
+	// using string(s.buf) as a map index doesn't allocate.
+	if _, ok := s.set[string(s.buf)]; ok {
+		return false, nil
+	}
 
-		err = enc.Encode(value)
-		if err != nil {
-			return 0, err
-		}
+	size := int64(len(s.buf)) + valueOverhead
+	if !s.mem.grow(size) {
+		return s.spillSet()
 	}
+	s.buffered += size
 
-	return s.hash.Sum64(), nil
+	s.set[string(s.buf)] = struct{}{}
+	return true, nil
 }
 
-func (s documentHashSet) Filter(d document.Document) (bool, error) {
-	k, err := s.generateKey(d)
+// spillSet moves the keys of the set and the current key to a spill store.
+func (s *documentHashSet) spillSet() (bool, error) {
+	var err error
+
+	s.spill, err = newSpillStore(s.db)
 	if err != nil {
 		return false, err
 	}
 
-	_, ok := s.set[k]
-	if ok {
-		return false, nil
+	for k := range s.set {
+		_, err = s.spill.Add([]byte(k))
+		if err != nil {
+			return false, err
+		}
 	}
 
-	s.set[k] = struct{}{}
-	return true, nil
+	s.set = nil
+	s.mem.release(s.buffered)
+	s.buffered = 0
+
+	return s.spill.Add(s.buf)
+}
+
+// Close releases the memory used by the set.
+func (s *documentHashSet) Close() error {
+	s.set = nil
+	s.mem.release(s.buffered)
+	s.buffered = 0
+
+	if s.spill != nil {
+		return s.spill.Close()
+	}
+
+	return nil
 }
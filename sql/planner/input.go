@@ -1,12 +1,16 @@
 package planner
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query"
 	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/sql/query/glob"
 	"github.com/genjidb/genji/sql/scanner"
 )
 
@@ -14,6 +18,7 @@ type tableInputNode struct {
 	node
 
 	tableName string
+	alias     string
 	table     *database.Table
 	indexes   map[string]database.Index
 	tx        *database.Transaction
@@ -33,10 +38,27 @@ func NewTableInputNode(tableName string) Node {
 	}
 }
 
+// NewAliasedTableInputNode creates an input node that can be used to read documents
+// from a table referred to by the given alias.
+// Paths whose first field is the alias, like alias.a.b, are resolved against
+// the documents of the table.
+func NewAliasedTableInputNode(tableName, alias string) Node {
+	return &tableInputNode{
+		node: node{
+			op: Input,
+		},
+		tableName: tableName,
+		alias:     alias,
+	}
+}
+
 func (n *tableInputNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
 	n.tx = tx
 	n.params = params
 	n.table, err = tx.GetTable(n.tableName)
+	if errors.Is(err, database.ErrTableNotFound) {
+		return fmt.Errorf("%w: %q", err, n.tableName)
+	}
 	if err != nil {
 		return err
 	}
@@ -44,12 +66,277 @@ func (n *tableInputNode) Bind(tx *database.Transaction, params []expr.Param) (er
 	return
 }
 
+func (n *tableInputNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	return &c
+}
+
 func (n *tableInputNode) String() string {
+	if n.alias != "" {
+		return fmt.Sprintf("Table(%s AS %s)", n.tableName, n.alias)
+	}
+
 	return fmt.Sprintf("Table(%s)", n.tableName)
 }
 
 func (n *tableInputNode) buildStream() (document.Stream, error) {
-	return document.NewStream(n.table), nil
+	return aliasStream(document.NewStream(n.table), n.alias), nil
+}
+
+type tableFunctionInputNode struct {
+	node
+
+	fn    expr.TableFunction
+	alias string
+
+	tx     *database.Transaction
+	params []expr.Param
+}
+
+var _ inputNode = (*tableFunctionInputNode)(nil)
+
+// NewTableFunctionInputNode creates an input node that reads the documents
+// produced by a table-valued function.
+// If alias is not empty, paths whose first field is the alias are resolved
+// against the produced documents.
+func NewTableFunctionInputNode(fn expr.TableFunction, alias string) Node {
+	return &tableFunctionInputNode{
+		node: node{
+			op: Input,
+		},
+		fn:    fn,
+		alias: alias,
+	}
+}
+
+func (n *tableFunctionInputNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
+	return
+}
+
+func (n *tableFunctionInputNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.fn = n.fn.Clone()
+	return &c
+}
+
+func (n *tableFunctionInputNode) String() string {
+	if n.alias != "" {
+		return fmt.Sprintf("TableFunction(%s AS %s)", n.fn, n.alias)
+	}
+
+	return fmt.Sprintf("TableFunction(%s)", n.fn)
+}
+
+func (n *tableFunctionInputNode) buildStream() (document.Stream, error) {
+	stack := expr.EvalStack{
+		Tx:     n.tx,
+		Params: n.params,
+	}
+
+	return aliasStream(document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		return n.fn.Iterate(stack, fn)
+	})), n.alias), nil
+}
+
+type valuesInputNode struct {
+	node
+
+	values expr.LiteralExprList
+	alias  string
+
+	tx     *database.Transaction
+	params []expr.Param
+}
+
+var _ inputNode = (*valuesInputNode)(nil)
+
+// NewValuesInputNode creates an input node that returns the documents listed by a VALUES clause.
+// Every expression must evaluate to a document.
+// If alias is not empty, paths whose first field is the alias are resolved
+// against the returned documents.
+func NewValuesInputNode(values expr.LiteralExprList, alias string) Node {
+	return &valuesInputNode{
+		node: node{
+			op: Input,
+		},
+		values: values,
+		alias:  alias,
+	}
+}
+
+func (n *valuesInputNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
+	return
+}
+
+func (n *valuesInputNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.values = expr.Clone(n.values).(expr.LiteralExprList)
+	return &c
+}
+
+func (n *valuesInputNode) String() string {
+	values := make([]string, len(n.values))
+	for i, e := range n.values {
+		values[i] = fmt.Sprintf("%v", e)
+	}
+
+	if n.alias != "" {
+		return fmt.Sprintf("Values(%s AS %s)", strings.Join(values, ", "), n.alias)
+	}
+
+	return fmt.Sprintf("Values(%s)", strings.Join(values, ", "))
+}
+
+func (n *valuesInputNode) buildStream() (document.Stream, error) {
+	stack := expr.EvalStack{
+		Tx:     n.tx,
+		Params: n.params,
+	}
+
+	return aliasStream(document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		for _, e := range n.values {
+			v, err := e.Eval(stack)
+			if err != nil {
+				return err
+			}
+
+			if v.Type != document.DocumentValue {
+				return fmt.Errorf("expected document, got %s", v.Type)
+			}
+
+			err = fn(v.V.(document.Document))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})), n.alias), nil
+}
+
+type subqueryInputNode struct {
+	node
+
+	subquery *Tree
+	alias    string
+
+	tx     *database.Transaction
+	params []expr.Param
+}
+
+var _ inputNode = (*subqueryInputNode)(nil)
+
+// NewSubqueryInputNode creates an input node that reads the documents returned by a subquery.
+// The subquery is run with the parameters of the statement.
+// If alias is not empty, paths whose first field is the alias are resolved
+// against the returned documents.
+func NewSubqueryInputNode(subquery *Tree, alias string) Node {
+	return &subqueryInputNode{
+		node: node{
+			op: Input,
+		},
+		subquery: subquery,
+		alias:    alias,
+	}
+}
+
+func (n *subqueryInputNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
+	return
+}
+
+func (n *subqueryInputNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.subquery = n.subquery.Clone()
+	return &c
+}
+
+func (n *subqueryInputNode) String() string {
+	if n.alias != "" {
+		return fmt.Sprintf("Subquery((%v) AS %s)", n.subquery, n.alias)
+	}
+
+	return fmt.Sprintf("Subquery(%v)", n.subquery)
+}
+
+func (n *subqueryInputNode) buildStream() (document.Stream, error) {
+	res, err := n.subquery.Run(n.tx, n.params)
+	if err != nil {
+		return document.Stream{}, err
+	}
+
+	return aliasStream(res.Stream, n.alias), nil
+}
+
+type insertionInputNode struct {
+	node
+
+	stmt      query.InsertStmt
+	returning bool
+
+	tx     *database.Transaction
+	params []expr.Param
+}
+
+var _ inputNode = (*insertionInputNode)(nil)
+
+// NewInsertionInputNode creates an input node that runs the given INSERT statement.
+// If the node is returning, the inserted documents are returned as a stream.
+func NewInsertionInputNode(stmt query.InsertStmt) Node {
+	return &insertionInputNode{
+		node: node{
+			op: Input,
+		},
+		stmt: stmt,
+	}
+}
+
+func (n *insertionInputNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
+	return
+}
+
+func (n *insertionInputNode) setReturning() {
+	n.returning = true
+}
+
+func (n *insertionInputNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.stmt.Paths = append([]document.Path(nil), n.stmt.Paths...)
+	c.stmt.Values = expr.Clone(n.stmt.Values).(expr.LiteralExprList)
+	return &c
+}
+
+func (n *insertionInputNode) String() string {
+	return fmt.Sprintf("Insert(%s)", n.stmt.TableName)
+}
+
+func (n *insertionInputNode) buildStream() (document.Stream, error) {
+	if !n.returning {
+		_, err := n.stmt.Run(n.tx, n.params)
+		return document.Stream{}, err
+	}
+
+	var inserted []document.Document
+	_, err := n.stmt.Insert(n.tx, n.params, func(key []byte, d document.Document) error {
+		return appendDocumentWithKey(&inserted, key, d)
+	})
+	if err != nil {
+		return document.Stream{}, err
+	}
+
+	return document.NewStream(document.NewIterator(inserted...)), nil
 }
 
 type indexInputNode struct {
@@ -57,6 +344,7 @@ type indexInputNode struct {
 
 	tableName string
 	indexName string
+	alias     string
 
 	tx               *database.Transaction
 	params           []expr.Param
@@ -114,32 +402,58 @@ func (n *indexInputNode) Bind(tx *database.Transaction, params []expr.Param) (er
 	}
 
 	// if the indexed field has no constraint and the filter is an int, cast that int to a double.
-	if n.evaluatedFilter.Type == document.IntegerValue {
-		info, err := n.table.Info()
-		if err != nil {
-			return err
-		}
+	// The values of arrays used by the IN operator are converted the same way.
+	if n.evaluatedFilter.Type != document.IntegerValue && n.evaluatedFilter.Type != document.ArrayValue {
+		return
+	}
 
-		shouldBeConverted := true
-		for _, fc := range info.FieldConstraints {
-			if fc.Path.IsEqual(n.path) && fc.Type != 0 {
-				shouldBeConverted = false
-				break
-			}
+	info, err := n.table.Info()
+	if err != nil {
+		return err
+	}
+
+	for _, fc := range info.FieldConstraints {
+		if fc.Path.IsEqual(n.path) && fc.Type != 0 {
+			return
 		}
+	}
 
-		if shouldBeConverted {
-			n.evaluatedFilter, err = n.evaluatedFilter.CastAsDouble()
-			if err != nil {
-				return err
+	n.evaluatedFilter, err = integersToDoubles(n.evaluatedFilter)
+	return
+}
+
+// integersToDoubles converts v to a double if it is an integer,
+// or the integers of v if it is an array.
+func integersToDoubles(v document.Value) (document.Value, error) {
+	switch v.Type {
+	case document.IntegerValue:
+		return v.CastAsDouble()
+	case document.ArrayValue:
+		var vb document.ValueBuffer
+		err := v.V.(document.Array).Iterate(func(i int, v document.Value) error {
+			if v.Type == document.IntegerValue {
+				var err error
+				v, err = v.CastAsDouble()
+				if err != nil {
+					return err
+				}
 			}
+
+			vb = vb.Append(v)
+			return nil
+		})
+		if err != nil {
+			return v, err
 		}
+
+		return document.NewArrayValue(vb), nil
 	}
-	return
+
+	return v, nil
 }
 
 func (n *indexInputNode) buildStream() (document.Stream, error) {
-	return document.NewStream(&indexIterator{
+	return aliasStream(document.NewStream(&indexIterator{
 		tx:     n.tx,
 		tb:     n.table,
 		params: n.params,
@@ -147,7 +461,15 @@ func (n *indexInputNode) buildStream() (document.Stream, error) {
 		path:   n.path,
 		filter: n.evaluatedFilter,
 		iop:    n.iop,
-	}), nil
+	}), n.alias), nil
+}
+
+func (n *indexInputNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.path = append(document.Path(nil), n.path...)
+	c.filter = expr.Clone(n.filter)
+	return &c
 }
 
 func (n *indexInputNode) String() string {
@@ -160,6 +482,53 @@ type IndexIteratorOperator interface {
 	IterateIndex(idx *database.Index, tb *database.Table, v document.Value, fn func(d document.Document) error) error
 }
 
+// likeIndexIterator reads the documents whose indexed text is in the range
+// of texts that can match a LIKE pattern. Since the range can contain texts
+// that don't match the pattern, the documents must still be filtered by the LIKE condition.
+// Patterns starting with a wildcard read all the texts of the index.
+type likeIndexIterator struct{}
+
+func (likeIndexIterator) IterateIndex(idx *database.Index, tb *database.Table, v document.Value, fn func(d document.Document) error) error {
+	if v.Type != document.TextValue {
+		return nil
+	}
+
+	pivot := document.Value{Type: document.TextValue}
+	var end []byte
+
+	lo, hi, ok := glob.LikeRange(v.V.(string))
+	if ok {
+		pivot = document.NewTextValue(lo)
+
+		if hi != "" {
+			var err error
+			end, err = idx.EncodeValue(document.NewTextValue(hi))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := idx.AscendGreaterOrEqual(pivot, func(val, key []byte, isEqual bool) error {
+		if end != nil && bytes.Compare(val, end) >= 0 {
+			return errStop
+		}
+
+		d, err := tb.GetDocument(key)
+		if err != nil {
+			return err
+		}
+
+		return fn(d)
+	})
+
+	if err != nil && err != errStop {
+		return err
+	}
+
+	return nil
+}
+
 type indexIterator struct {
 	tx               *database.Transaction
 	tb               *database.Table
@@ -202,3 +571,41 @@ func (it indexIterator) Iterate(fn func(d document.Document) error) error {
 
 	return it.iop.IterateIndex(it.index, it.tb, it.filter, fn)
 }
+
+// aliasStream makes every document of the stream resolve paths
+// qualified by the given alias. If alias is empty, st is returned unchanged.
+func aliasStream(st document.Stream, alias string) document.Stream {
+	if alias == "" {
+		return st
+	}
+
+	return st.Map(func(d document.Document) (document.Document, error) {
+		return aliasedDocument{Document: d, alias: alias}, nil
+	})
+}
+
+// aliasedDocument is a document that returns itself when the alias
+// of its table is used as a field name. The alias takes precedence over
+// a field with the same name.
+type aliasedDocument struct {
+	document.Document
+
+	alias string
+}
+
+func (d aliasedDocument) GetByField(field string) (document.Value, error) {
+	if field == d.alias {
+		return document.NewDocumentValue(d.Document), nil
+	}
+
+	return d.Document.GetByField(field)
+}
+
+func (d aliasedDocument) Key() []byte {
+	// documents produced by table functions have no key.
+	if k, ok := d.Document.(document.Keyer); ok {
+		return k.Key()
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+type insertionNode struct {
+	node
+
+	tableName string
+	tx        *database.Transaction
+}
+
+var _ operationNode = (*insertionNode)(nil)
+
+// NewInsertionNode creates a node that creates the given table
+// and inserts every document of a stream in it.
+// The table must not exist.
+func NewInsertionNode(n Node, tableName string) Node {
+	return &insertionNode{
+		node: node{
+			op:   Insertion,
+			left: n,
+		},
+		tableName: tableName,
+	}
+}
+
+func (n *insertionNode) Bind(tx *database.Transaction, params []expr.Param) error {
+	n.tx = tx
+	return nil
+}
+
+// toStream creates the table and inserts the documents of the stream.
+// The table is created when the stream is consumed so that binding
+// the tree, when explaining it for example, has no side effect.
+func (n *insertionNode) toStream(st document.Stream) (document.Stream, error) {
+	err := n.tx.CreateTable(n.tableName, nil)
+	if err != nil {
+		return document.Stream{}, err
+	}
+
+	t, err := n.tx.GetTable(n.tableName)
+	if err != nil {
+		return document.Stream{}, err
+	}
+
+	err = st.Iterate(func(d document.Document) error {
+		_, err := t.Insert(d)
+		return err
+	})
+
+	return document.Stream{}, err
+}
+
+func (n *insertionNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	return &c
+}
+
+func (n *insertionNode) String() string {
+	return fmt.Sprintf("Insert(%s)", n.tableName)
+}
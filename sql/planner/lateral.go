@@ -0,0 +1,186 @@
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// A LateralJoinNode joins every document of the stream with the documents
+// returned by a subquery. The subquery is executed once per document of the stream
+// and can refer to its fields: every outer reference of the subquery must have been
+// replaced by a named parameter whose name is the string representation of the path,
+// which is set to the value of the path in the current document.
+// The documents of the subquery are added to the current document under the given alias.
+// If Outer is true, documents of the stream without matching documents in the subquery
+// are kept and the alias field is set to null.
+type LateralJoinNode struct {
+	node
+
+	Subquery *Tree
+	Alias    string
+	// Refs are the paths of the stream referenced by the subquery.
+	// Their first fragment is the name of the stream, either the table name or its alias.
+	Refs []expr.Path
+	// On filters the joined documents. If nil, all joined documents are kept.
+	On    expr.Expr
+	Outer bool
+
+	tx     *database.Transaction
+	params []expr.Param
+}
+
+var _ operationNode = (*LateralJoinNode)(nil)
+
+// NewLateralJoinNode creates a LateralJoinNode.
+func NewLateralJoinNode(n Node, subquery *Tree, alias string, refs []expr.Path, on expr.Expr, outer bool) Node {
+	return &LateralJoinNode{
+		node: node{
+			op:   Join,
+			left: n,
+		},
+		Subquery: subquery,
+		Alias:    alias,
+		Refs:     refs,
+		On:       on,
+		Outer:    outer,
+	}
+}
+
+// Bind database resources to this node.
+func (n *LateralJoinNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
+	return
+}
+
+func (n *LateralJoinNode) toStream(st document.Stream) (document.Stream, error) {
+	return document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		return st.Iterate(func(d document.Document) error {
+			params, err := n.subqueryParams(d)
+			if err != nil {
+				return err
+			}
+
+			res, err := n.Subquery.Run(n.tx, params)
+			if err != nil {
+				return err
+			}
+
+			var matched bool
+			err = res.Iterate(func(r document.Document) error {
+				jd := joinedDocument{Document: d, alias: n.Alias, value: document.NewDocumentValue(r)}
+
+				if n.On != nil {
+					v, err := n.On.Eval(expr.EvalStack{Tx: n.tx, Document: jd, Params: n.params})
+					if err != nil {
+						return err
+					}
+
+					ok, err := v.IsTruthy()
+					if err != nil || !ok {
+						return err
+					}
+				}
+
+				matched = true
+				return fn(jd)
+			})
+			if err != nil {
+				return err
+			}
+
+			if !matched && n.Outer {
+				return fn(joinedDocument{Document: d, alias: n.Alias, value: document.NewNullValue()})
+			}
+
+			return nil
+		})
+	})), nil
+}
+
+// subqueryParams returns the parameters of the statement, followed by
+// the values of the outer references of the subquery in d.
+func (n *LateralJoinNode) subqueryParams(d document.Document) ([]expr.Param, error) {
+	params := make([]expr.Param, 0, len(n.params)+len(n.Refs))
+	params = append(params, n.params...)
+
+	for _, ref := range n.Refs {
+		// the first fragment is the name of the stream
+		v := document.NewDocumentValue(d)
+		if len(ref) > 1 {
+			var err error
+			v, err = ref[1:].Eval(expr.EvalStack{Tx: n.tx, Document: d, Params: n.params})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		params = append(params, expr.Param{Name: ref.String(), Value: v})
+	}
+
+	return params, nil
+}
+
+// Clone returns a deep copy of the node and its children.
+func (n *LateralJoinNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.Subquery = n.Subquery.Clone()
+	c.Refs = make([]expr.Path, len(n.Refs))
+	for i, ref := range n.Refs {
+		c.Refs[i] = append(expr.Path(nil), ref...)
+	}
+	c.On = expr.Clone(n.On)
+	return &c
+}
+
+func (n *LateralJoinNode) String() string {
+	var b strings.Builder
+
+	if n.Outer {
+		b.WriteString("Left")
+	}
+	fmt.Fprintf(&b, "LateralJoin((%v) AS %s", n.Subquery, n.Alias)
+	if n.On != nil {
+		fmt.Fprintf(&b, " ON %v", n.On)
+	}
+	b.WriteString(")")
+
+	return b.String()
+}
+
+// joinedDocument adds the result of a join to a document, under the given alias.
+// The alias takes precedence over any field of the document with the same name.
+type joinedDocument struct {
+	document.Document
+
+	alias string
+	value document.Value
+}
+
+func (d joinedDocument) GetByField(field string) (document.Value, error) {
+	if field == d.alias {
+		return d.value, nil
+	}
+
+	return d.Document.GetByField(field)
+}
+
+func (d joinedDocument) Iterate(fn func(field string, value document.Value) error) error {
+	err := d.Document.Iterate(func(field string, value document.Value) error {
+		if field == d.alias {
+			return nil
+		}
+
+		return fn(field, value)
+	})
+	if err != nil {
+		return err
+	}
+
+	return fn(d.alias, d.value)
+}
@@ -0,0 +1,207 @@
+package planner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+)
+
+// valueOverhead is the approximate size of a value in memory, without its content.
+const valueOverhead = 32
+
+// memoryBudget tracks the approximate memory used by the nodes of a statement
+// that buffer documents. A nil memoryBudget has no limit.
+type memoryBudget struct {
+	limit int64
+	used  int64
+}
+
+func newMemoryBudget(limit int64) *memoryBudget {
+	return &memoryBudget{limit: limit}
+}
+
+// grow records n more bytes and reports whether the budget is still respected.
+// If it is not, the n bytes are not recorded.
+func (m *memoryBudget) grow(n int64) bool {
+	if m == nil {
+		return true
+	}
+
+	if m.limit > 0 && m.used+n > m.limit {
+		return false
+	}
+
+	m.used += n
+	return true
+}
+
+// release records that n bytes were freed.
+func (m *memoryBudget) release(n int64) {
+	if m != nil {
+		m.used -= n
+	}
+}
+
+// A memoryBudgetUser is a node that buffers documents
+// and accounts for them in the memory budget of the statement.
+type memoryBudgetUser interface {
+	setMemoryBudget(m *memoryBudget)
+}
+
+// setMemoryBudget shares m among every node of the tree that buffers documents.
+func setMemoryBudget(n Node, m *memoryBudget) {
+	if n == nil {
+		return
+	}
+
+	if u, ok := n.(memoryBudgetUser); ok {
+		u.setMemoryBudget(m)
+	}
+
+	setMemoryBudget(n.Left(), m)
+	setMemoryBudget(n.Right(), m)
+}
+
+// documentSize returns the approximate size of d in memory.
+func documentSize(d document.Document) int64 {
+	var size int64
+
+	_ = d.Iterate(func(f string, v document.Value) error {
+		size += int64(len(f)) + valueSize(v)
+		return nil
+	})
+
+	return size
+}
+
+func valueSize(v document.Value) int64 {
+	switch v.Type {
+	case document.TextValue:
+		return valueOverhead + int64(len(v.V.(string)))
+	case document.BlobValue:
+		return valueOverhead + int64(len(v.V.([]byte)))
+	case document.DocumentValue:
+		return valueOverhead + documentSize(v.V.(document.Document))
+	case document.ArrayValue:
+		size := int64(valueOverhead)
+		_ = v.V.(document.Array).Iterate(func(i int, v document.Value) error {
+			size += valueSize(v)
+			return nil
+		})
+		return size
+	}
+
+	return valueOverhead
+}
+
+// spillStore is a temporary store where nodes move their buffered documents
+// once the memory budget of the statement is exceeded.
+// Keys are kept ordered by the engine, which sorts the documents without
+// having to load them in memory.
+type spillStore struct {
+	db  *database.Database
+	ng  engine.Engine
+	tx  engine.Transaction
+	st  engine.Store
+	seq uint64
+}
+
+// newSpillStore creates a store using the spill engine of the database.
+// If the database has no spill engine, it returns ErrMemoryBudgetExceeded.
+func newSpillStore(db *database.Database) (*spillStore, error) {
+	if db.SpillEngine == nil {
+		return nil, database.ErrMemoryBudgetExceeded
+	}
+
+	ng, err := db.SpillEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	s := spillStore{db: db, ng: ng}
+
+	s.tx, err = ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	if err != nil {
+		ng.Close()
+		return nil, err
+	}
+
+	err = s.tx.CreateStore([]byte("spill"))
+	if err == nil {
+		s.st, err = s.tx.GetStore([]byte("spill"))
+	}
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Put stores d under the given key. A sequence number is appended to the key
+// so that documents with the same key are all stored, in insertion order.
+// Some engines keep references to the keys and values they store,
+// so new buffers are allocated for each call.
+func (s *spillStore) Put(key []byte, d document.Document) error {
+	var buf bytes.Buffer
+	err := s.db.Codec.NewEncoder(&buf).EncodeDocument(d)
+	if err != nil {
+		return err
+	}
+
+	s.seq++
+	k := make([]byte, len(key)+8)
+	copy(k, key)
+	binary.BigEndian.PutUint64(k[len(key):], s.seq)
+
+	return s.st.Put(k, buf.Bytes())
+}
+
+// Add stores the given key, without any document, and reports whether it was absent.
+func (s *spillStore) Add(key []byte) (bool, error) {
+	_, err := s.st.Get(key)
+	if err == nil {
+		return false, nil
+	}
+	if err != engine.ErrKeyNotFound {
+		return false, err
+	}
+
+	return true, s.st.Put(append([]byte(nil), key...), nil)
+}
+
+// Iterate calls fn with every document of the store, ordered by key.
+func (s *spillStore) Iterate(reverse bool, fn func(d document.Document) error) error {
+	it := s.st.Iterator(engine.IteratorOptions{Reverse: reverse})
+	defer it.Close()
+
+	var buf []byte
+	var err error
+	for it.Seek(nil); it.Valid(); it.Next() {
+		buf, err = it.Item().ValueCopy(buf)
+		if err != nil {
+			return err
+		}
+
+		err = fn(s.db.Codec.NewDocument(buf))
+		if err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// Close discards the content of the store and closes the spill engine.
+func (s *spillStore) Close() error {
+	err := s.tx.Rollback()
+	if cerr := s.ng.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
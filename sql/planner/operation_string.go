@@ -19,11 +19,18 @@ func _() {
 	_ = x[Sort-8]
 	_ = x[Set-9]
 	_ = x[Unset-10]
+	_ = x[Group-11]
+	_ = x[Aggregation-12]
+	_ = x[Dedup-13]
+	_ = x[Insertion-14]
+	_ = x[Pivot-15]
+	_ = x[Join-16]
+	_ = x[Sample-17]
 }
 
-const _Operation_name = "InputSelectionProjectionRenameDeletionReplacementLimitSkipSortSetUnset"
+const _Operation_name = "InputSelectionProjectionRenameDeletionReplacementLimitSkipSortSetUnsetGroupAggregationDedupInsertionPivotJoinSample"
 
-var _Operation_index = [...]uint8{0, 5, 14, 24, 30, 38, 49, 54, 58, 62, 65, 70}
+var _Operation_index = [...]uint8{0, 5, 14, 24, 30, 38, 49, 54, 58, 62, 65, 70, 75, 86, 91, 100, 105, 109, 115}
 
 func (i Operation) String() string {
 	if i < 0 || i >= Operation(len(_Operation_index)-1) {
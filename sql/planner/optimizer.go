@@ -1,15 +1,19 @@
 package planner
 
 import (
+	"strings"
+
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/sql/query/glob"
 	"github.com/genjidb/genji/sql/scanner"
 )
 
 var optimizerRules = []func(t *Tree) (*Tree, error){
 	SplitANDConditionRule,
 	PrecalculateExprRule,
+	NormaliseComparisonsRule,
 	RemoveUnnecessarySelectionNodesRule,
 	RemoveUnnecessaryDedupNodeRule,
 	UseIndexBasedOnSelectionNodeRule,
@@ -36,13 +40,15 @@ func Optimize(t *Tree) (*Tree, error) {
 // is one or more AND operators into one or more selection nodes.
 // The condition won't be split if the expression tree contains an OR
 // operation.
+// The first condition is the closest to the input, which preserves the short-circuit
+// evaluation of AND: a condition is only evaluated for documents matching the previous ones.
 // Example:
 //   this:
 //     σ(a > 2 AND b != 3 AND c < 2)
 //   becomes this:
-//     σ(a > 2)
-//     σ(b != 3)
 //     σ(c < 2)
+//     σ(b != 3)
+//     σ(a > 2)
 func SplitANDConditionRule(t *Tree) (*Tree, error) {
 	n := t.Root
 	var prev Node
@@ -60,17 +66,14 @@ func SplitANDConditionRule(t *Tree) (*Tree, error) {
 					exprs := splitANDExpr(cond)
 
 					cur := n.Left()
-					i := len(exprs) - 1
 					var newNode Node
-					for i >= 0 {
-						newNode = NewSelectionNode(cur, exprs[i])
+					for _, e := range exprs {
+						newNode = NewSelectionNode(cur, e)
 						err := newNode.Bind(sn.tx, sn.params)
 						if err != nil {
 							return nil, err
 						}
 						cur = newNode
-
-						i--
 					}
 
 					if prev != nil {
@@ -124,6 +127,23 @@ func PrecalculateExprRule(t *Tree) (*Tree, error) {
 	return t, nil
 }
 
+// NormaliseComparisonsRule flips the comparisons of selection nodes that have a constant
+// on the left and a path on the right, so that the path is on the left.
+// This makes the conditions easier to match with an index.
+// Examples:
+//   1 = a --> a = 1
+//   10 > a --> a < 10
+func NormaliseComparisonsRule(t *Tree) (*Tree, error) {
+	for n := t.Root; n != nil; n = n.Left() {
+		if n.Operation() == Selection {
+			sn := n.(*selectionNode)
+			sn.cond = expr.Normalise(sn.cond)
+		}
+	}
+
+	return t, nil
+}
+
 // precalculateExpr is a recursive function that tries to precalculate
 // expression nodes when possible.
 // it returns a new expression with simplified nodes.
@@ -153,6 +173,12 @@ func precalculateExpr(e expr.Expr) expr.Expr {
 			return expr.ArrayValue(&vb)
 		}
 
+	case expr.TupleExpr:
+		// tuples are compared element by element,
+		// so they are kept even if they only contain literals.
+		for i, te := range t.Elements {
+			t.Elements[i] = precalculateExpr(te)
+		}
 	case expr.KVPairs:
 		// we assume that the list of kvpairs contains only literals
 		// until proven wrong.
@@ -190,6 +216,13 @@ func precalculateExpr(e expr.Expr) expr.Expr {
 
 		lh := precalculateExpr(t.LeftHand())
 		rh := precalculateExpr(t.RightHand())
+		// a value IN a tuple is the same as the value IN an array,
+		// which can be precalculated and used to select an index.
+		if tu, ok := rh.(expr.TupleExpr); ok && expr.IsInOperator(t) {
+			if _, ok := lh.(expr.TupleExpr); !ok {
+				rh = precalculateExpr(expr.LiteralExprList(tu.Elements))
+			}
+		}
 		t.SetLeftHandExpr(lh)
 		t.SetRightHandExpr(rh)
 
@@ -224,7 +257,7 @@ func RemoveUnnecessarySelectionNodesRule(t *Tree) (*Tree, error) {
 			if sn.cond != nil {
 				if lit, ok := sn.cond.(expr.LiteralValue); ok {
 					// if the expr is falsy, we return an empty tree
-					ok, err := document.Value(lit).IsTruthy()
+					ok, err := document.IsTruthy(document.Value(lit))
 					if err != nil {
 						return nil, err
 					}
@@ -285,6 +318,11 @@ func RemoveUnnecessaryDedupNodeRule(t *Tree) (*Tree, error) {
 }
 
 func isProjectionUnique(indexes map[string]database.Index, pn *ProjectionNode) bool {
+	// documents that don't come from a table have no primary key or indexes.
+	if pn.info == nil {
+		return false
+	}
+
 	pk := pn.info.GetPrimaryKey()
 	for _, field := range pn.Expressions {
 		e, ok := field.(ProjectedExpr)
@@ -317,6 +355,9 @@ func isProjectionUnique(indexes map[string]database.Index, pn *ProjectionNode) b
 // - one of its operands is a path expression that is indexed
 // - the other operand is a literal value or a parameter
 // If found, it will replace the input node by an indexInputNode using this index.
+// A LIKE operator on an indexed path whose pattern is a text that doesn't start with a wildcard,
+// like 'abc%', can also use the index: the input node only reads the range of texts
+// starting with the prefix of the pattern, and the selection node is kept to filter them.
 func UseIndexBasedOnSelectionNodeRule(t *Tree) (*Tree, error) {
 	n := t.Root
 	var prev Node
@@ -336,13 +377,18 @@ func UseIndexBasedOnSelectionNodeRule(t *Tree) (*Tree, error) {
 		return t, nil
 	}
 
-	// Here we will assume that at this point
-	// inputNodes can only be instances of tableInputNode.
-	inpn := inputNode.(*tableInputNode)
+	// only tables can be read using an index.
+	inpn, ok := inputNode.(*tableInputNode)
+	if !ok {
+		return t, nil
+	}
 
 	type candidate struct {
 		prevNode, nextNode Node
 		in                 *indexInputNode
+		// if true, the index only returns a superset of the documents
+		// matching the selection node, which must be kept.
+		keepSelection bool
 	}
 
 	var candidates []candidate
@@ -350,14 +396,20 @@ func UseIndexBasedOnSelectionNodeRule(t *Tree) (*Tree, error) {
 	n = t.Root
 	// look for all selection nodes that satisfy our requirements
 	for n != nil {
+		// conditions on the result of a pivot, a join or a sample don't apply to the documents of the table
+		if n.Operation() == Pivot || n.Operation() == Join || n.Operation() == Sample {
+			candidates = nil
+		}
+
 		if n.Operation() == Selection {
 			sn := n.(*selectionNode)
-			indexedNode := selectionNodeValidForIndex(sn, inpn.tableName, inpn.indexes)
+			indexedNode := selectionNodeValidForIndex(sn, inpn.tableName, inpn.alias, inpn.indexes, inpn.tx.DB().CaseInsensitiveFields)
 			if indexedNode != nil {
 				candidates = append(candidates, candidate{
-					prevNode: prev,
-					nextNode: n.Left(),
-					in:       indexedNode,
+					prevNode:      prev,
+					nextNode:      n.Left(),
+					in:            indexedNode,
+					keepSelection: expr.IsLikeOperator(sn.cond),
 				})
 			}
 		}
@@ -368,15 +420,20 @@ func UseIndexBasedOnSelectionNodeRule(t *Tree) (*Tree, error) {
 
 	// determine which index is the most interesting and replace it in the tree.
 	// we will assume that unique indexes are more interesting than list indexes
-	// because they usually have less elements.
+	// because they usually have less elements, and that LIKE ranges are
+	// the least interesting.
 	var selectedCandidate *candidate
 
 	for i, candidate := range candidates {
-		if selectedCandidate == nil {
+		if selectedCandidate == nil || (selectedCandidate.keepSelection && !candidate.keepSelection) {
 			selectedCandidate = &candidates[i]
 			continue
 		}
 
+		if candidate.keepSelection {
+			continue
+		}
+
 		// if the candidate's related index is a unique index,
 		// select it.
 		idx := candidate.in.index
@@ -394,11 +451,14 @@ func UseIndexBasedOnSelectionNodeRule(t *Tree) (*Tree, error) {
 		return nil, err
 	}
 
-	// we remove the selection node from the tree
-	if selectedCandidate.prevNode == nil {
-		t.Root = selectedCandidate.nextNode
-	} else {
-		selectedCandidate.prevNode.SetLeft(selectedCandidate.nextNode)
+	// we remove the selection node from the tree,
+	// unless it must filter the documents read from the index
+	if !selectedCandidate.keepSelection {
+		if selectedCandidate.prevNode == nil {
+			t.Root = selectedCandidate.nextNode
+		} else {
+			selectedCandidate.prevNode.SetLeft(selectedCandidate.nextNode)
+		}
 	}
 
 	n = t.Root
@@ -423,7 +483,7 @@ func UseIndexBasedOnSelectionNodeRule(t *Tree) (*Tree, error) {
 	return t, nil
 }
 
-func selectionNodeValidForIndex(sn *selectionNode, tableName string, indexes map[string]database.Index) *indexInputNode {
+func selectionNodeValidForIndex(sn *selectionNode, tableName, alias string, indexes map[string]database.Index, foldFields bool) *indexInputNode {
 	if sn.cond == nil {
 		return nil
 	}
@@ -434,9 +494,13 @@ func selectionNodeValidForIndex(sn *selectionNode, tableName string, indexes map
 		return nil
 	}
 
+	if expr.IsLikeOperator(op) {
+		return likeSelectionNodeValidForIndex(op, tableName, alias, indexes, foldFields)
+	}
+
 	// determine if the operator can read from the index
 	iop, ok := op.(IndexIteratorOperator)
-	if !ok {
+	if !ok || expr.IsNotInOperator(op) {
 		return nil
 	}
 
@@ -451,18 +515,94 @@ func selectionNodeValidForIndex(sn *selectionNode, tableName string, indexes map
 		return nil
 	}
 
+	path, ok = unaliasPath(path, alias)
+	if !ok {
+		return nil
+	}
+
 	// now, we look if an index exists for that path
-	idx, ok := indexes[path.String()]
+	idx, ok := lookupIndex(indexes, path, foldFields)
 	if !ok {
 		return nil
 	}
 
 	in := NewIndexInputNode(tableName, idx.Opts.IndexName, iop, path, e, scanner.ASC).(*indexInputNode)
 	in.index = &idx
+	in.alias = alias
 
 	return in
 }
 
+// likeSelectionNodeValidForIndex returns an index input node reading the range of an index
+// that can match the pattern of a LIKE operator, if:
+// - its left operand is an indexed path
+// - its right operand is a text literal that doesn't start with a wildcard
+func likeSelectionNodeValidForIndex(op expr.Operator, tableName, alias string, indexes map[string]database.Index, foldFields bool) *indexInputNode {
+	path, ok := op.LeftHand().(expr.Path)
+	if !ok {
+		return nil
+	}
+
+	lv, ok := op.RightHand().(expr.LiteralValue)
+	if !ok || lv.Type != document.TextValue {
+		return nil
+	}
+
+	if _, _, ok := glob.LikeRange(lv.V.(string)); !ok {
+		return nil
+	}
+
+	path, ok = unaliasPath(path, alias)
+	if !ok {
+		return nil
+	}
+
+	idx, ok := lookupIndex(indexes, path, foldFields)
+	if !ok {
+		return nil
+	}
+
+	in := NewIndexInputNode(tableName, idx.Opts.IndexName, likeIndexIterator{}, path, lv, scanner.ASC).(*indexInputNode)
+	in.index = &idx
+	in.alias = alias
+
+	return in
+}
+
+// unaliasPath removes the alias of the table from the beginning of the path,
+// which turns alias.a.b into a.b. Since the alias alone denotes the whole document,
+// it returns false if the path is only made of the alias.
+func unaliasPath(path expr.Path, alias string) (expr.Path, bool) {
+	if alias == "" || path[0].FieldName != alias {
+		return path, true
+	}
+
+	return path[1:], len(path) > 1
+}
+
+// lookupIndex returns the index of the given path. If foldFields is true,
+// index paths are matched case-insensitively and, if several indexes match,
+// the one with the lowest name is returned.
+func lookupIndex(indexes map[string]database.Index, path expr.Path, foldFields bool) (database.Index, bool) {
+	idx, ok := indexes[path.String()]
+	if ok || !foldFields {
+		return idx, ok
+	}
+
+	var found database.Index
+	for p, idx := range indexes {
+		if !strings.EqualFold(p, path.String()) {
+			continue
+		}
+
+		if !ok || idx.Opts.IndexName < found.Opts.IndexName {
+			found, ok = idx, true
+		}
+	}
+
+	return found, ok
+}
+
 func opCanUseIndex(op expr.Operator) (bool, expr.Path, expr.Expr) {
 	lf, leftIsField := op.LeftHand().(expr.Path)
 	rf, rightIsField := op.RightHand().(expr.Path)
@@ -39,8 +39,8 @@ func TestSplitANDConditionRule(t *testing.T) {
 			planner.NewSelectionNode(
 				planner.NewSelectionNode(
 					planner.NewTableInputNode("foo"),
-					expr.BoolValue(false)),
-				expr.BoolValue(true)),
+					expr.BoolValue(true)),
+				expr.BoolValue(false)),
 		},
 		{
 			"and / middle-level selection node",
@@ -55,8 +55,8 @@ func TestSplitANDConditionRule(t *testing.T) {
 				planner.NewSelectionNode(
 					planner.NewSelectionNode(
 						planner.NewTableInputNode("foo"),
-						expr.BoolValue(false)),
-					expr.BoolValue(true),
+						expr.BoolValue(true)),
+					expr.BoolValue(false),
 				), 1),
 		},
 		{
@@ -80,10 +80,10 @@ func TestSplitANDConditionRule(t *testing.T) {
 						planner.NewSelectionNode(
 							planner.NewSelectionNode(
 								planner.NewTableInputNode("foo"),
-								expr.IntegerValue(4)),
-							expr.IntegerValue(3)),
-						expr.IntegerValue(2)),
-					expr.IntegerValue(1)),
+								expr.IntegerValue(1)),
+							expr.IntegerValue(2)),
+						expr.IntegerValue(3)),
+					expr.IntegerValue(4)),
 				10,
 			),
 		},
@@ -166,6 +166,28 @@ func TestPrecalculateExprRule(t *testing.T) {
 				Add("b", document.NewDoubleValue(-39)),
 			)),
 		},
+		{
+			"value in constant tuple: a IN (1, 1 + 1) -> a IN array([1, 2])",
+			expr.In(
+				expr.Path(parsePath(t, "a")),
+				expr.TupleExpr{Elements: []expr.Expr{expr.IntegerValue(1), expr.Add(expr.IntegerValue(1), expr.IntegerValue(1))}},
+			),
+			expr.In(
+				expr.Path(parsePath(t, "a")),
+				expr.ArrayValue(document.NewValueBuffer(document.NewIntegerValue(1), document.NewIntegerValue(2))),
+			),
+		},
+		{
+			"constant tuples are kept: (a, 1 + 1) = (1, 2) -> (a, 2) = (1, 2)",
+			expr.Eq(
+				expr.TupleExpr{Elements: []expr.Expr{expr.Path(parsePath(t, "a")), expr.Add(expr.IntegerValue(1), expr.IntegerValue(1))}},
+				expr.TupleExpr{Elements: []expr.Expr{expr.IntegerValue(1), expr.IntegerValue(2)}},
+			),
+			expr.Eq(
+				expr.TupleExpr{Elements: []expr.Expr{expr.Path(parsePath(t, "a")), expr.IntegerValue(2)}},
+				expr.TupleExpr{Elements: []expr.Expr{expr.IntegerValue(1), expr.IntegerValue(2)}},
+			),
+		},
 	}
 
 	for _, test := range tests {
@@ -177,6 +199,42 @@ func TestPrecalculateExprRule(t *testing.T) {
 	}
 }
 
+func TestNormaliseComparisonsRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		e, expected expr.Expr
+	}{
+		{
+			"1 = age -> age = 1",
+			expr.Eq(expr.IntegerValue(1), expr.Path(parsePath(t, "age"))),
+			expr.Eq(expr.Path(parsePath(t, "age")), expr.IntegerValue(1)),
+		},
+		{
+			"10 > price -> price < 10",
+			expr.Gt(expr.IntegerValue(10), expr.Path(parsePath(t, "price"))),
+			expr.Lt(expr.Path(parsePath(t, "price")), expr.IntegerValue(10)),
+		},
+		{
+			"a = b -> a = b",
+			expr.Eq(expr.Path(parsePath(t, "a")), expr.Path(parsePath(t, "b"))),
+			expr.Eq(expr.Path(parsePath(t, "a")), expr.Path(parsePath(t, "b"))),
+		},
+		{
+			"1 = 1 -> 1 = 1",
+			expr.Eq(expr.IntegerValue(1), expr.IntegerValue(1)),
+			expr.Eq(expr.IntegerValue(1), expr.IntegerValue(1)),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := planner.NormaliseComparisonsRule(planner.NewTree(planner.NewSelectionNode(planner.NewTableInputNode("foo"), test.e)))
+			require.NoError(t, err)
+			require.Equal(t, planner.NewTree(planner.NewSelectionNode(planner.NewTableInputNode("foo"), test.expected)).String(), res.String())
+		})
+	}
+}
+
 func TestRemoveUnnecessarySelectionNodesRule(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -476,6 +534,61 @@ func TestUseIndexBasedOnSelectionNodeRule(t *testing.T) {
 				scanner.ASC,
 			),
 		},
+		{
+			"FROM foo WHERE a LIKE 'abc%'",
+			planner.NewSelectionNode(planner.NewTableInputNode("foo"),
+				expr.Like(expr.Path(parsePath(t, "a")), expr.TextValue("abc%")),
+			),
+			planner.NewSelectionNode(
+				planner.NewIndexInputNode(
+					"foo",
+					"idx_foo_a",
+					nil,
+					expr.Path(parsePath(t, "a")),
+					expr.TextValue("abc%"),
+					scanner.ASC,
+				),
+				expr.Like(expr.Path(parsePath(t, "a")), expr.TextValue("abc%")),
+			),
+		},
+		{
+			"FROM foo WHERE a LIKE '%abc'",
+			planner.NewSelectionNode(planner.NewTableInputNode("foo"),
+				expr.Like(expr.Path(parsePath(t, "a")), expr.TextValue("%abc")),
+			),
+			planner.NewSelectionNode(planner.NewTableInputNode("foo"),
+				expr.Like(expr.Path(parsePath(t, "a")), expr.TextValue("%abc")),
+			),
+		},
+		{
+			"FROM foo WHERE a NOT LIKE 'abc%'",
+			planner.NewSelectionNode(planner.NewTableInputNode("foo"),
+				expr.NotLike(expr.Path(parsePath(t, "a")), expr.TextValue("abc%")),
+			),
+			planner.NewSelectionNode(planner.NewTableInputNode("foo"),
+				expr.NotLike(expr.Path(parsePath(t, "a")), expr.TextValue("abc%")),
+			),
+		},
+		{
+			"FROM foo WHERE a LIKE 'abc%' AND b = 2",
+			planner.NewSelectionNode(
+				planner.NewSelectionNode(planner.NewTableInputNode("foo"),
+					expr.Like(expr.Path(parsePath(t, "a")), expr.TextValue("abc%")),
+				),
+				expr.Eq(expr.Path(parsePath(t, "b")), expr.IntegerValue(2)),
+			),
+			planner.NewSelectionNode(
+				planner.NewIndexInputNode(
+					"foo",
+					"idx_foo_b",
+					expr.Eq(nil, nil).(planner.IndexIteratorOperator),
+					expr.Path(parsePath(t, "b")),
+					expr.IntegerValue(2),
+					scanner.ASC,
+				),
+				expr.Like(expr.Path(parsePath(t, "a")), expr.TextValue("abc%")),
+			),
+		},
 		{
 			"FROM foo WHERE 1 IN a",
 			planner.NewSelectionNode(planner.NewTableInputNode("foo"),
@@ -0,0 +1,244 @@
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// A PivotNode turns the values of a field into fields.
+// Documents of the stream are grouped by all their fields except the pivoted one and the ones
+// used by the aggregate function. For each group, it creates one document containing the fields
+// of the group and one field per pivot value, which aggregates the documents of the group
+// where the pivoted field has that value. If there are no such documents, the field is null.
+// Documents where the pivoted field is missing or doesn't have any of the pivot values
+// aren't aggregated, but their group is still returned.
+type PivotNode struct {
+	node
+
+	Aggregator expr.Expr
+	Path       expr.Path
+	Values     []expr.Expr
+
+	tx     *database.Transaction
+	params []expr.Param
+}
+
+var _ operationNode = (*PivotNode)(nil)
+
+// NewPivotNode creates a PivotNode. The aggregator must implement the document.AggregatorBuilder interface.
+func NewPivotNode(n Node, aggregator expr.Expr, path expr.Path, values []expr.Expr) Node {
+	return &PivotNode{
+		node: node{
+			op:   Pivot,
+			left: n,
+		},
+		Aggregator: aggregator,
+		Path:       path,
+		Values:     values,
+	}
+}
+
+// Bind database resources to this node.
+func (n *PivotNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.tx = tx
+	n.params = params
+	return
+}
+
+// pivotGroup holds the fields shared by the documents of a group
+// and one aggregator per pivot value, created on first use.
+type pivotGroup struct {
+	fields *document.FieldBuffer
+	aggs   []document.Aggregator
+}
+
+func (n *PivotNode) toStream(st document.Stream) (document.Stream, error) {
+	builder, ok := n.Aggregator.(document.AggregatorBuilder)
+	if !ok {
+		return st, fmt.Errorf("%v is not an aggregate function", n.Aggregator)
+	}
+
+	// fields that don't identify a group
+	excluded := map[string]bool{
+		n.Path[0].FieldName: true,
+	}
+	expr.Walk(n.Aggregator, func(e expr.Expr) (expr.Expr, bool) {
+		if p, ok := e.(expr.Path); ok {
+			excluded[p[0].FieldName] = true
+		}
+		return e, true
+	})
+
+	return document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		values, names, err := n.evalValues()
+		if err != nil {
+			return err
+		}
+
+		groups := make(map[string]*pivotGroup)
+		var groupKeys []string
+		var key []byte
+
+		err = st.Iterate(func(d document.Document) error {
+			i := -1
+			v, err := document.Path(n.Path).GetValue(d)
+			switch err {
+			case nil:
+				i = pivotValueIndex(values, v)
+			case document.ErrFieldNotFound, document.ErrValueNotFound:
+			default:
+				return err
+			}
+
+			var fb document.FieldBuffer
+			err = d.Iterate(func(f string, v document.Value) error {
+				if !excluded[f] {
+					fb.Add(f, v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			key, err = document.AppendKey(key[:0], document.NewDocumentValue(&fb))
+			if err != nil {
+				return err
+			}
+
+			// using string(key) as a map index doesn't allocate.
+			g, ok := groups[string(key)]
+			if !ok {
+				// the values of the document may be reused by the stream
+				fields := document.NewFieldBuffer()
+				err = fields.Copy(&fb)
+				if err != nil {
+					return err
+				}
+
+				g = &pivotGroup{fields: fields, aggs: make([]document.Aggregator, len(values))}
+				groups[string(key)] = g
+				groupKeys = append(groupKeys, string(key))
+			}
+
+			if i < 0 {
+				return nil
+			}
+
+			if g.aggs[i] == nil {
+				g.aggs[i] = builder.Aggregator(document.NewNullValue())
+			}
+
+			return g.aggs[i].Add(d)
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range groupKeys {
+			g := groups[k]
+
+			for i, agg := range g.aggs {
+				v := document.NewNullValue()
+
+				if agg != nil {
+					// the aggregator adds a single field named after the aggregate function
+					var res document.FieldBuffer
+					err = agg.Aggregate(&res)
+					if err != nil {
+						return err
+					}
+
+					err = res.Iterate(func(_ string, value document.Value) error {
+						v = value
+						return nil
+					})
+					if err != nil {
+						return err
+					}
+				}
+
+				err = g.fields.Set(document.Path{document.PathFragment{FieldName: names[i]}}, v)
+				if err != nil {
+					return err
+				}
+			}
+
+			err = fn(g.fields)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})), nil
+}
+
+// evalValues evaluates the pivot values and returns them with the names of their fields.
+// Text values are used as is, other values use their string representation.
+func (n *PivotNode) evalValues() ([]document.Value, []string, error) {
+	stack := expr.EvalStack{Tx: n.tx, Params: n.params}
+
+	values := make([]document.Value, len(n.Values))
+	names := make([]string, len(n.Values))
+	for i, e := range n.Values {
+		v, err := e.Eval(stack)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if pivotValueIndex(values[:i], v) >= 0 {
+			return nil, nil, fmt.Errorf("duplicate pivot value %s", v)
+		}
+
+		values[i] = v
+		if v.Type == document.TextValue {
+			names[i] = v.V.(string)
+		} else {
+			names[i] = v.String()
+		}
+	}
+
+	return values, names, nil
+}
+
+// pivotValueIndex returns the position of v in values, or -1.
+func pivotValueIndex(values []document.Value, v document.Value) int {
+	for i := range values {
+		ok, err := values[i].IsEqual(v)
+		if err == nil && ok {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Clone returns a deep copy of the node and its children.
+func (n *PivotNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.Aggregator = expr.Clone(n.Aggregator)
+	c.Values = make([]expr.Expr, len(n.Values))
+	for i, e := range n.Values {
+		c.Values[i] = expr.Clone(e)
+	}
+	return &c
+}
+
+func (n *PivotNode) String() string {
+	var b strings.Builder
+
+	for i, e := range n.Values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprintf("%v", e))
+	}
+
+	return fmt.Sprintf("Pivot(%v FOR %v IN (%s))", n.Aggregator, n.Path, b.String())
+}
@@ -19,8 +19,9 @@ type ProjectionNode struct {
 	Expressions []ProjectedField
 	tableName   string
 
-	info *database.TableInfo
-	tx   *database.Transaction
+	info   *database.TableInfo
+	tx     *database.Transaction
+	params []expr.Param
 }
 
 var _ operationNode = (*ProjectionNode)(nil)
@@ -37,14 +38,33 @@ func NewProjectionNode(n Node, expressions []ProjectedField, tableName string) N
 	}
 }
 
+// NewReturningNode creates a ProjectionNode that projects the documents written
+// by n, which must be a deletion or replacement node, or an insertion input node.
+// It is used to implement the RETURNING clause of write statements.
+func NewReturningNode(n Node, expressions []ProjectedField, tableName string) Node {
+	if w, ok := n.(interface{ setReturning() }); ok {
+		w.setReturning()
+	}
+
+	return NewProjectionNode(n, expressions, tableName)
+}
+
 // Bind database resources to this node.
 func (n *ProjectionNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
 	n.tx = tx
+	n.params = params
 	if n.tableName == "" {
 		return
 	}
 
 	table, err := tx.GetTable(n.tableName)
+	if errors.Is(err, database.ErrTableNotFound) && tx.DB().AutoCreateTables {
+		// the table will be created by the insertion, without constraints.
+		if _, ok := n.left.(*insertionInputNode); ok {
+			n.info = new(database.TableInfo)
+			return nil
+		}
+	}
 	if err != nil {
 		return err
 	}
@@ -54,9 +74,15 @@ func (n *ProjectionNode) Bind(tx *database.Transaction, params []expr.Param) (er
 }
 
 func (n *ProjectionNode) toStream(st document.Stream) (document.Stream, error) {
+	fields, err := n.prepareFields()
+	if err != nil {
+		return st, err
+	}
+
 	if st.IsEmpty() {
 		d := documentMask{
-			resultFields: n.Expressions,
+			resultFields: fields,
+			tx:           n.tx,
 		}
 		var fb document.FieldBuffer
 		err := fb.ScanDocument(d)
@@ -70,7 +96,8 @@ func (n *ProjectionNode) toStream(st document.Stream) (document.Stream, error) {
 		st = st.Map(func(d document.Document) (document.Document, error) {
 			dm.info = n.info
 			dm.d = d
-			dm.resultFields = n.Expressions
+			dm.resultFields = fields
+			dm.tx = n.tx
 
 			return &dm, nil
 		})
@@ -79,6 +106,45 @@ func (n *ProjectionNode) toStream(st document.Stream) (document.Stream, error) {
 	return st, nil
 }
 
+// prepareFields prepares the projected expressions for the execution of the statement.
+func (n *ProjectionNode) prepareFields() ([]ProjectedField, error) {
+	env := expr.StatementEnv{
+		Tx:     n.tx,
+		Params: n.params,
+		Info:   n.info,
+	}
+
+	fields := make([]ProjectedField, len(n.Expressions))
+	for i, f := range n.Expressions {
+		if pe, ok := f.(ProjectedExpr); ok {
+			e, err := expr.Prepare(pe.Expr, &env)
+			if err != nil {
+				return nil, err
+			}
+
+			f = preparedExpr{ProjectedExpr: pe, eval: e}
+		}
+
+		fields[i] = f
+	}
+
+	return fields, nil
+}
+
+// Clone returns a deep copy of the node and its children.
+func (n *ProjectionNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.Expressions = make([]ProjectedField, len(n.Expressions))
+	for i, f := range n.Expressions {
+		if pe, ok := f.(ProjectedExpr); ok {
+			f = ProjectedExpr{Expr: expr.Clone(pe.Expr), ExprName: pe.ExprName}
+		}
+		c.Expressions[i] = f
+	}
+	return &c
+}
+
 func (n *ProjectionNode) String() string {
 	var b strings.Builder
 
@@ -96,6 +162,7 @@ type documentMask struct {
 	info         *database.TableInfo
 	d            document.Document
 	resultFields []ProjectedField
+	tx           *database.Transaction
 }
 
 var _ document.Document = documentMask{}
@@ -103,7 +170,7 @@ var _ document.Document = documentMask{}
 func (r documentMask) GetByField(field string) (v document.Value, err error) {
 	for _, rf := range r.resultFields {
 		if rf.Name() == field || rf.Name() == "*" {
-			v, err = r.d.GetByField(field)
+			v, err = r.getByField(field)
 			if err != document.ErrFieldNotFound {
 				return
 			}
@@ -111,6 +178,7 @@ func (r documentMask) GetByField(field string) (v document.Value, err error) {
 			stack := expr.EvalStack{
 				Document: r.d,
 				Info:     r.info,
+				Tx:       r.tx,
 			}
 			var found bool
 			err = rf.Iterate(stack, func(f string, value document.Value) error {
@@ -131,10 +199,19 @@ func (r documentMask) GetByField(field string) (v document.Value, err error) {
 	return
 }
 
+func (r documentMask) getByField(field string) (document.Value, error) {
+	if r.tx != nil && r.tx.DB().CaseInsensitiveFields {
+		return document.FoldDocument(r.d).GetByField(field)
+	}
+
+	return r.d.GetByField(field)
+}
+
 func (r documentMask) Iterate(fn func(field string, value document.Value) error) error {
 	stack := expr.EvalStack{
 		Document: r.d,
 		Info:     r.info,
+		Tx:       r.tx,
 	}
 
 	for _, rf := range r.resultFields {
@@ -184,6 +261,23 @@ func (r ProjectedExpr) String() string {
 	return fmt.Sprintf("%s", r.Expr)
 }
 
+// preparedExpr is a ProjectedExpr prepared for the execution of a statement.
+type preparedExpr struct {
+	ProjectedExpr
+
+	eval expr.CompiledExpr
+}
+
+// Iterate evaluates the prepared expression and calls fn once with the result.
+func (r preparedExpr) Iterate(stack expr.EvalStack, fn func(field string, value document.Value) error) error {
+	v, err := r.eval(stack.Document)
+	if err != nil {
+		return err
+	}
+
+	return fn(r.ExprName, v)
+}
+
 // A Wildcard is a ResultField that iterates over all the fields of a document.
 type Wildcard struct{}
 
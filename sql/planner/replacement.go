@@ -20,6 +20,11 @@ type replacementNode struct {
 	tableName string
 	table     *database.Table
 	codec     encoding.Codec
+	returning bool
+	// if true, documents left unchanged by the SET and UNSET clauses are not replaced.
+	skipUnchanged bool
+	// paths modified by the SET and UNSET clauses, nil if unknown.
+	paths []document.Path
 }
 
 var _ operationNode = (*replacementNode)(nil)
@@ -38,15 +43,56 @@ func NewReplacementNode(n Node, tableName string) Node {
 
 func (n *replacementNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
 	n.table, err = tx.GetTable(n.tableName)
+	if err != nil {
+		return
+	}
+
+	// UPDATE triggers fire even if the document is unchanged
+	hasTriggers, err := n.table.HasTriggers(database.TriggerUpdate)
+	n.skipUnchanged = !hasTriggers
+	n.paths = modifiedPaths(n.left)
 	return
 }
 
+// modifiedPaths returns the paths modified by the set and unset nodes of the tree,
+// or nil if any other node may modify the documents.
+func modifiedPaths(n Node) []document.Path {
+	paths := []document.Path{}
+
+	for ; n != nil; n = n.Left() {
+		switch t := n.(type) {
+		case *setNode:
+			paths = append(paths, t.path)
+		case *unsetNode:
+			p := t.path
+			// removing an array element shifts the next ones
+			if len(p) > 0 && p[len(p)-1].FieldName == "" {
+				p = p[:len(p)-1]
+			}
+			paths = append(paths, p)
+		default:
+			switch n.Operation() {
+			case Input, Selection, Limit, Skip, Sort:
+			default:
+				return nil
+			}
+		}
+	}
+
+	return paths
+}
+
 // toResult replaces matching documents by batches of replaceBufferSize documents.
 // Some engines can't create more than one iterator per read-write transaction (https://github.com/dgraph-io/badger/issues/1093).
 // To deal with these limitations, Run will iterate on a limited number of documents, copy the keys
 // to a buffer and replace them after the iteration is complete, and it will do that until there is no document
 // left to replace.
 // Increasing replaceBufferSize will occasionate less key searches (O(log n) for most engines) but will take more memory.
+// Documents left unchanged by the SET and UNSET clauses are neither encoded nor written,
+// and their indexes are not updated, unless the table has UPDATE triggers.
+// Otherwise, only the indexes on the paths modified by these clauses are updated.
+// They are still returned if the node is returning.
+// If the node is returning, the new documents are returned as a stream.
 func (n *replacementNode) toStream(st document.Stream) (document.Stream, error) {
 	// replace store implementation by a resumable store, temporarily.
 	rit := resumableIterator{
@@ -58,6 +104,8 @@ func (n *replacementNode) toStream(st document.Stream) (document.Stream, error)
 
 	keys := make([][]byte, replaceBufferSize)
 	docs := make([]document.FieldBuffer, replaceBufferSize)
+	unchanged := make([]bool, replaceBufferSize)
+	var replaced []document.Document
 
 	var err error
 	for {
@@ -77,15 +125,26 @@ func (n *replacementNode) toStream(st document.Stream) (document.Stream, error)
 
 			// copy the key and reuse the buffer
 			keys[i] = append(keys[i][0:0], rk.Key()...)
+			ud, ok := d.(*updatedDocument)
+			unchanged[i] = ok && !ud.changed
 			i++
 
 			return nil
 		})
 
 		for j := 0; j < i; j++ {
-			err = n.table.Replace(keys[j], docs[j])
-			if err != nil {
-				return document.Stream{}, err
+			if !unchanged[j] || !n.skipUnchanged {
+				err = n.table.ReplaceChanged(keys[j], docs[j], n.paths)
+				if err != nil {
+					return document.Stream{}, err
+				}
+			}
+
+			if n.returning {
+				err = appendDocumentWithKey(&replaced, keys[j], docs[j])
+				if err != nil {
+					return document.Stream{}, err
+				}
 			}
 		}
 
@@ -96,9 +155,23 @@ func (n *replacementNode) toStream(st document.Stream) (document.Stream, error)
 		rit.curKey = keys[i-1]
 	}
 
+	if err == nil && n.returning {
+		return document.NewStream(document.NewIterator(replaced...)), nil
+	}
+
 	return document.Stream{}, err
 }
 
+func (n *replacementNode) setReturning() {
+	n.returning = true
+}
+
+func (n *replacementNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	return &c
+}
+
 func (n *replacementNode) String() string {
 	return fmt.Sprintf("Replace(%s)", n.tableName)
 }
@@ -152,3 +225,19 @@ type encodedDocumentWithKey struct {
 func (e encodedDocumentWithKey) Key() []byte {
 	return e.key
 }
+
+// appendDocumentWithKey appends a copy of d and its key to docs.
+func appendDocumentWithKey(docs *[]document.Document, key []byte, d document.Document) error {
+	var fb document.FieldBuffer
+
+	err := fb.Copy(d)
+	if err != nil {
+		return err
+	}
+
+	*docs = append(*docs, encodedDocumentWithKey{
+		Document: &fb,
+		key:      append([]byte(nil), key...),
+	})
+	return nil
+}
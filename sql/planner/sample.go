@@ -0,0 +1,187 @@
+package planner
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// A SampleNode selects a random subset of the documents of a stream.
+// It either selects a fixed number of documents, using reservoir sampling,
+// or selects each document with a given probability.
+// Selected documents are returned in the order of the stream.
+type SampleNode struct {
+	node
+
+	// Rows is the number of documents to select, if ByRows is true.
+	Rows   int
+	ByRows bool
+	// Percent is the probability, from 0 to 100, for each document to be selected,
+	// if ByRows is false.
+	Percent float64
+	// Seed initializes the random number generator.
+	// If nil, every execution uses a different seed.
+	Seed *int64
+
+	mem *memoryBudget
+}
+
+var _ operationNode = (*SampleNode)(nil)
+
+// NewRowsSampleNode creates a node that selects at most rows documents of the stream.
+func NewRowsSampleNode(n Node, rows int, seed *int64) Node {
+	return &SampleNode{
+		node: node{
+			op:   Sample,
+			left: n,
+		},
+		Rows:   rows,
+		ByRows: true,
+		Seed:   seed,
+	}
+}
+
+// NewPercentSampleNode creates a node that selects each document of the stream
+// with a probability of percent / 100.
+func NewPercentSampleNode(n Node, percent float64, seed *int64) Node {
+	return &SampleNode{
+		node: node{
+			op:   Sample,
+			left: n,
+		},
+		Percent: percent,
+		Seed:    seed,
+	}
+}
+
+// Bind database resources to this node.
+func (n *SampleNode) Bind(tx *database.Transaction, params []expr.Param) error {
+	return nil
+}
+
+func (n *SampleNode) setMemoryBudget(m *memoryBudget) {
+	n.mem = m
+}
+
+func (n *SampleNode) toStream(st document.Stream) (document.Stream, error) {
+	return document.NewStream(document.IteratorFunc(func(fn func(d document.Document) error) error {
+		seed := time.Now().UnixNano()
+		if n.Seed != nil {
+			seed = *n.Seed
+		}
+		rnd := rand.New(rand.NewSource(seed))
+
+		if !n.ByRows {
+			return st.Iterate(func(d document.Document) error {
+				if rnd.Float64()*100 >= n.Percent {
+					return nil
+				}
+
+				return fn(d)
+			})
+		}
+
+		return n.iterateReservoir(st, rnd, fn)
+	})), nil
+}
+
+// sampledDocument is a document of the reservoir,
+// with its position in the stream.
+type sampledDocument struct {
+	pos  int
+	doc  *document.FieldBuffer
+	size int64
+}
+
+// iterateReservoir selects n.Rows documents with reservoir sampling.
+// Every document of the stream must be read before returning any of them,
+// which are copied and accounted for in the memory budget.
+func (n *SampleNode) iterateReservoir(st document.Stream, rnd *rand.Rand, fn func(d document.Document) error) error {
+	if n.Rows <= 0 {
+		return nil
+	}
+
+	var reservoir []sampledDocument
+	var buffered int64
+	defer func() {
+		n.mem.release(buffered)
+	}()
+
+	var pos int
+	err := st.Iterate(func(d document.Document) error {
+		i := pos
+		pos++
+
+		if len(reservoir) >= n.Rows {
+			i = rnd.Intn(pos)
+			if i >= n.Rows {
+				return nil
+			}
+		}
+
+		fb := document.NewFieldBuffer()
+		err := fb.Copy(d)
+		if err != nil {
+			return err
+		}
+
+		size := documentSize(fb)
+		if !n.mem.grow(size) {
+			return database.ErrMemoryBudgetExceeded
+		}
+		buffered += size
+
+		if len(reservoir) < n.Rows {
+			reservoir = append(reservoir, sampledDocument{pos: pos, doc: fb, size: size})
+			return nil
+		}
+
+		n.mem.release(reservoir[i].size)
+		buffered -= reservoir[i].size
+		reservoir[i] = sampledDocument{pos: pos, doc: fb, size: size}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(reservoir, func(i, j int) bool {
+		return reservoir[i].pos < reservoir[j].pos
+	})
+
+	for _, s := range reservoir {
+		err = fn(s.doc)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Clone returns a deep copy of the node and its children.
+func (n *SampleNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	return &c
+}
+
+func (n *SampleNode) String() string {
+	var s string
+	if n.ByRows {
+		s = fmt.Sprintf("Sample(%d ROWS", n.Rows)
+	} else {
+		s = fmt.Sprintf("Sample(%v PERCENT", n.Percent)
+	}
+
+	if n.Seed != nil {
+		s += fmt.Sprintf(" SEED %d", *n.Seed)
+	}
+
+	return s + ")"
+}
@@ -3,7 +3,9 @@ package planner
 import (
 	"bytes"
 	"container/heap"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
@@ -11,11 +13,56 @@ import (
 	"github.com/genjidb/genji/sql/scanner"
 )
 
+// A Collation returns the sort key of a text.
+// Texts are sorted by comparing their sort keys byte per byte.
+type Collation func(s string) string
+
+// collations lists the collations that can be used to sort texts, by name.
+var collations = map[string]Collation{
+	"BINARY": func(s string) string { return s },
+	"NOCASE": strings.ToLower,
+}
+
+// GetCollation returns the collation with the given name.
+// Names are case insensitive and the empty name refers to the BINARY collation.
+func GetCollation(name string) (Collation, error) {
+	if name == "" {
+		name = "BINARY"
+	}
+
+	c, ok := collations[strings.ToUpper(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown collation %q", name)
+	}
+
+	return c, nil
+}
+
+// NullsOrder determines where a sort node places null values,
+// which includes documents without the sorted field.
+type NullsOrder int
+
+const (
+	// NullsDefault follows the ordering of values: nulls come first
+	// in ascending order and last in descending order.
+	NullsDefault NullsOrder = iota
+	// NullsFirst places nulls before any other value.
+	NullsFirst
+	// NullsLast places nulls after any other value.
+	NullsLast
+)
+
 type sortNode struct {
 	node
 
 	sortField expr.Path
 	direction scanner.Token
+	collation string
+	nulls     NullsOrder
+
+	foldFields bool
+	db         *database.Database
+	mem        *memoryBudget
 }
 
 var _ operationNode = (*sortNode)(nil)
@@ -23,6 +70,18 @@ var _ operationNode = (*sortNode)(nil)
 // NewSortNode creates a node that sorts a stream according to a given
 // document path and a sort direction.
 func NewSortNode(n Node, sortField expr.Path, direction scanner.Token) Node {
+	return NewCollatedSortNode(n, sortField, direction, "")
+}
+
+// NewCollatedSortNode creates a sort node that compares texts using the named collation.
+// The collation must be one of the names accepted by GetCollation.
+func NewCollatedSortNode(n Node, sortField expr.Path, direction scanner.Token, collation string) Node {
+	return NewSortNodeWithNulls(n, sortField, direction, collation, NullsDefault)
+}
+
+// NewSortNodeWithNulls creates a sort node that compares texts using the named collation
+// and places null values according to nulls.
+func NewSortNodeWithNulls(n Node, sortField expr.Path, direction scanner.Token, collation string, nulls NullsOrder) Node {
 	if direction == 0 {
 		direction = scanner.ASC
 	}
@@ -34,42 +93,106 @@ func NewSortNode(n Node, sortField expr.Path, direction scanner.Token) Node {
 		},
 		sortField: sortField,
 		direction: direction,
+		collation: collation,
+		nulls:     nulls,
 	}
 }
 
 func (n *sortNode) Bind(tx *database.Transaction, params []expr.Param) (err error) {
+	n.db = tx.DB()
+	n.foldFields = n.db.CaseInsensitiveFields
 	return
 }
 
+func (n *sortNode) setMemoryBudget(m *memoryBudget) {
+	n.mem = m
+}
+
 func (n *sortNode) toStream(st document.Stream) (document.Stream, error) {
+	collate, err := GetCollation(n.collation)
+	if err != nil {
+		return st, err
+	}
+
 	return document.NewStream(&sortIterator{
-		st:        st,
-		sortField: n.sortField,
-		direction: n.direction,
+		st:         st,
+		sortField:  n.sortField,
+		direction:  n.direction,
+		collate:    collate,
+		nulls:      n.nulls,
+		foldFields: n.foldFields,
+		db:         n.db,
+		mem:        n.mem,
 	}), nil
 }
 
+func (n *sortNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.sortField = append(expr.Path(nil), n.sortField...)
+	return &c
+}
+
 func (n *sortNode) String() string {
 	dir := "ASC"
 	if n.direction == scanner.DESC {
 		dir = "DESC"
 	}
 
+	switch n.nulls {
+	case NullsFirst:
+		dir += " NULLS FIRST"
+	case NullsLast:
+		dir += " NULLS LAST"
+	}
+
+	if n.collation != "" {
+		return fmt.Sprintf("Sort(%s COLLATE %s %s)", n.sortField, strings.ToUpper(n.collation), dir)
+	}
+
 	return fmt.Sprintf("Sort(%s %s)", n.sortField, dir)
 }
 
+var errSortCustomSpill = errors.New("cannot sort values compared by a registered comparator beyond the memory budget")
+
 type sortIterator struct {
-	st        document.Stream
-	sortField expr.Path
-	direction scanner.Token
+	st         document.Stream
+	sortField  expr.Path
+	direction  scanner.Token
+	collate    Collation
+	nulls      NullsOrder
+	foldFields bool
+	db         *database.Database
+	mem        *memoryBudget
+
+	// size of the documents buffered in the heap.
+	buffered int64
+	// store used once the memory budget is exceeded.
+	spill *spillStore
+	// whether a sorted value is compared by a registered comparator.
+	custom bool
 }
 
 func (it *sortIterator) Iterate(fn func(d document.Document) error) error {
+	defer func() {
+		it.mem.release(it.buffered)
+		it.buffered = 0
+
+		if it.spill != nil {
+			it.spill.Close()
+			it.spill = nil
+		}
+	}()
+
 	h, err := it.sortStream(it.st)
 	if err != nil {
 		return err
 	}
 
+	if it.spill != nil {
+		return it.spill.Iterate(it.direction == scanner.DESC, fn)
+	}
+
 	for h.Len() > 0 {
 		node := heap.Pop(h).(heapNode)
 		err := fn(&(node.data))
@@ -86,6 +209,8 @@ func (it *sortIterator) Iterate(fn func(d document.Document) error) error {
 // OFFSET + LIMIT clauses, if provided, otherwise k = n.
 // If the sorting is in ascending order, a min-heap will be used
 // otherwise a max-heap will be used instead.
+// If the heap exceeds the memory budget of the statement, its content is moved
+// to a spill store, ordered by the engine, and so are the remaining documents.
 // Once the heap is filled entirely with the content of the table a stream is returned.
 // During iteration, the stream will pop the k-smallest or k-largest elements, depending on
 // the chosen sorting order (ASC or DESC).
@@ -106,7 +231,7 @@ func (it *sortIterator) sortStream(st document.Stream) (heap.Interface, error) {
 	return h, st.Iterate(func(d document.Document) error {
 		// It is possible to sort by any projected field
 		// or field of the original document.
-		v, err := path.GetValue(d)
+		v, err := it.getValue(path, d)
 		if err != nil && err != document.ErrFieldNotFound {
 			return err
 		}
@@ -115,7 +240,7 @@ func (it *sortIterator) sortStream(st document.Stream) (heap.Interface, error) {
 		// Look for fields in the original document.
 		if err == document.ErrFieldNotFound {
 			if dm, ok := d.(*documentMask); ok {
-				v, err = path.GetValue(dm.d)
+				v, err = it.getValue(path, dm.d)
 				if err != nil && err != document.ErrFieldNotFound {
 					return err
 				}
@@ -127,19 +252,61 @@ func (it *sortIterator) sortStream(st document.Stream) (heap.Interface, error) {
 			}
 		}
 
+		if v.Type == document.TextValue {
+			v = document.NewTextValue(it.collate(v.V.(string)))
+		}
+
 		// We need to make sure sort behaviour
 		// if the same with or without indexes.
 		// To achieve that, the value must be encoded using the same method
 		// as what the index package would do.
 		var buf bytes.Buffer
 
-		err = document.NewValueEncoder(&buf).Encode(v)
-		if err != nil {
-			return err
+		// if the placement of nulls is not the default one, every value
+		// is prefixed by a byte that places nulls before or after the others.
+		if it.nulls != NullsDefault {
+			buf.WriteByte(it.nullsPrefix(v))
+		}
+
+		// values of a type with a registered comparator cannot be encoded:
+		// they are grouped by type and ordered by the comparator, see heapNode.
+		var custom document.Value
+		if document.HasComparator(v.Type) {
+			custom = v
+			buf.WriteByte(byte(v.Type))
+		} else {
+			err = document.NewValueEncoder(&buf).Encode(v)
+			if err != nil {
+				return err
+			}
 		}
 
+		// the spill store orders documents by their encoded value only
+		if custom.Type != 0 {
+			it.custom = true
+		}
+
+		if it.spill != nil {
+			if it.custom {
+				return errSortCustomSpill
+			}
+
+			return it.spill.Put(buf.Bytes(), d)
+		}
+
+		size := int64(buf.Len()) + documentSize(d)
+		if !it.mem.grow(size) {
+			if it.custom {
+				return errSortCustomSpill
+			}
+
+			return it.spillHeap(h, buf.Bytes(), d)
+		}
+		it.buffered += size
+
 		node := heapNode{
-			value: buf.Bytes(),
+			value:  buf.Bytes(),
+			custom: custom,
 		}
 		err = node.data.Copy(d)
 		if err != nil {
@@ -152,15 +319,80 @@ func (it *sortIterator) sortStream(st document.Stream) (heap.Interface, error) {
 	})
 }
 
+// spillHeap moves the content of the heap and d to a spill store.
+func (it *sortIterator) spillHeap(h heap.Interface, value []byte, d document.Document) error {
+	var err error
+
+	it.spill, err = newSpillStore(it.db)
+	if err != nil {
+		return err
+	}
+
+	for h.Len() > 0 {
+		node := heap.Pop(h).(heapNode)
+		err = it.spill.Put(node.value, &node.data)
+		if err != nil {
+			return err
+		}
+	}
+
+	it.mem.release(it.buffered)
+	it.buffered = 0
+
+	return it.spill.Put(value, d)
+}
+
+// nullsPrefix returns the byte prefixing the encoded value v so that
+// nulls are placed as required, taking into account the direction of the sort.
+func (it *sortIterator) nullsPrefix(v document.Value) byte {
+	if v.Type != document.NullValue {
+		return 1
+	}
+
+	if (it.nulls == NullsFirst) == (it.direction == scanner.ASC) {
+		return 0
+	}
+
+	return 2
+}
+
+func (it *sortIterator) getValue(path document.Path, d document.Document) (document.Value, error) {
+	if it.foldFields {
+		path = path.Fold(d)
+	}
+
+	return path.GetValue(d)
+}
+
+// heapNode is a document of the heap, sorted by its encoded value.
+// Values compared by a registered comparator are encoded as their type only
+// and kept in custom, which orders the nodes whose encoded values are equal.
 type heapNode struct {
-	value []byte
-	data  document.FieldBuffer
+	value  []byte
+	custom document.Value
+	data   document.FieldBuffer
+}
+
+func (n *heapNode) compare(other *heapNode) int {
+	c := bytes.Compare(n.value, other.value)
+	if c != 0 || n.custom.Type == 0 {
+		return c
+	}
+
+	if ok, _ := n.custom.IsLesserThan(other.custom); ok {
+		return -1
+	}
+	if ok, _ := n.custom.IsGreaterThan(other.custom); ok {
+		return 1
+	}
+
+	return 0
 }
 
 type minHeap []heapNode
 
 func (h minHeap) Len() int           { return len(h) }
-func (h minHeap) Less(i, j int) bool { return bytes.Compare(h[i].value, h[j].value) < 0 }
+func (h minHeap) Less(i, j int) bool { return h[i].compare(&h[j]) < 0 }
 func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
 
 func (h *minHeap) Push(x interface{}) {
@@ -180,5 +412,5 @@ type maxHeap struct {
 }
 
 func (h maxHeap) Less(i, j int) bool {
-	return bytes.Compare(h.minHeap[i].value, h.minHeap[j].value) > 0
+	return h.minHeap[i].compare(&h.minHeap[j]) > 0
 }
@@ -6,7 +6,9 @@
 package planner
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
@@ -47,12 +49,24 @@ const (
 	Aggregation
 	// Dedup is an operation that removes duplicate documents from a stream
 	Dedup
+	// Insertion is an operation that inserts all of the documents of a stream in a table.
+	Insertion
+	// Pivot is an operation that turns the values of a field into fields, aggregating documents.
+	Pivot
+	// Join is an operation that combines every document of a stream with the documents of another stream.
+	Join
+	// Sample is an operation that selects a random subset of the documents of a stream.
+	Sample
 )
 
 // A Tree describes the flow of a stream of documents.
 // Each node will manipulate the stream using relational algebra operations.
 type Tree struct {
 	Root Node
+
+	// Materialized lists the common table expressions read by the nodes of the tree
+	// or of its subqueries which are run once per execution of the tree.
+	Materialized []*MaterializedCTE
 }
 
 // NewTree creates a new tree with n as root.
@@ -61,8 +75,19 @@ func NewTree(n Node) *Tree {
 }
 
 // Run implements the query.Statement interface.
-// It binds the tree to the database resources and executes it.
+// It binds a copy of the tree to the database resources and executes it,
+// so that a tree can be run concurrently.
+// The documents of the materialized common table expressions of the tree
+// are kept for the duration of the execution and discarded once the result is closed.
 func (t *Tree) Run(tx *database.Transaction, params []expr.Param) (query.Result, error) {
+	// binding and optimizing modify the tree
+	t = t.Clone()
+
+	var ctes cteResults
+	if len(t.Materialized) > 0 {
+		params, ctes = withCTEResults(params, t.Materialized)
+	}
+
 	err := Bind(t, tx, params)
 	if err != nil {
 		return query.Result{}, err
@@ -73,7 +98,19 @@ func (t *Tree) Run(tx *database.Transaction, params []expr.Param) (query.Result,
 		return query.Result{}, err
 	}
 
-	return t.execute()
+	setMemoryBudget(t.Root, newMemoryBudget(tx.DB().MemoryBudget))
+
+	res, err := t.execute()
+	if err != nil {
+		ctes.release()
+		return res, err
+	}
+
+	if ctes != nil {
+		res.OnClose(ctes.release)
+	}
+
+	return res, nil
 }
 
 func (t *Tree) execute() (query.Result, error) {
@@ -100,6 +137,14 @@ func (t *Tree) execute() (query.Result, error) {
 	}, nil
 }
 
+// Clone returns a deep copy of the tree. Nodes and the expressions they contain
+// are copied, so that the copy can be modified without affecting t.
+// Database resources bound to the nodes are shared, and so are the materialized
+// common table expressions, which are never modified.
+func (t *Tree) Clone() *Tree {
+	return &Tree{Root: cloneNode(t.Root), Materialized: t.Materialized}
+}
+
 func (t *Tree) String() string {
 	n := t.Root
 
@@ -125,8 +170,93 @@ func nodeToString(n Node) string {
 }
 
 // IsReadOnly implements the query.Statement interface.
+// A tree is read-only if none of its nodes, on either side, and none of its subqueries
+// inserts, deletes or replaces documents.
 func (t *Tree) IsReadOnly() bool {
-	return false
+	return isReadOnly(t.Root)
+}
+
+func isReadOnly(n Node) bool {
+	if n == nil {
+		return true
+	}
+
+	switch n.Operation() {
+	case Deletion, Replacement, Insertion:
+		return false
+	}
+
+	switch n := n.(type) {
+	case *insertionInputNode:
+		return false
+	case *subqueryInputNode:
+		if !n.subquery.IsReadOnly() {
+			return false
+		}
+	case *materializedInputNode:
+		if !n.cte.subquery.IsReadOnly() {
+			return false
+		}
+	case *LateralJoinNode:
+		if !n.Subquery.IsReadOnly() {
+			return false
+		}
+	}
+
+	return isReadOnly(n.Left()) && isReadOnly(n.Right())
+}
+
+// Tables implements the query.Statement interface.
+// It returns the names of the tables read or modified by the nodes of the tree
+// and by its subqueries, sorted and without duplicates.
+func (t *Tree) Tables() []string {
+	set := make(map[string]struct{})
+	t.addTables(set)
+	if len(set) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (t *Tree) addTables(set map[string]struct{}) {
+	addTables(t.Root, set)
+}
+
+func addTables(n Node, set map[string]struct{}) {
+	if n == nil {
+		return
+	}
+
+	switch n := n.(type) {
+	case *tableInputNode:
+		set[n.tableName] = struct{}{}
+	case *indexInputNode:
+		set[n.tableName] = struct{}{}
+	case *insertionInputNode:
+		set[n.stmt.TableName] = struct{}{}
+	case *insertionNode:
+		set[n.tableName] = struct{}{}
+	case *replacementNode:
+		set[n.tableName] = struct{}{}
+	case *deletionNode:
+		set[n.tableName] = struct{}{}
+	case *subqueryInputNode:
+		n.subquery.addTables(set)
+	case *materializedInputNode:
+		n.cte.subquery.addTables(set)
+	case *LateralJoinNode:
+		n.Subquery.addTables(set)
+	}
+
+	addTables(n.Left(), set)
+	addTables(n.Right(), set)
 }
 
 func nodeToStream(n Node) (st document.Stream, err error) {
@@ -158,6 +288,8 @@ type Node interface {
 	SetLeft(Node)
 	SetRight(Node)
 	Bind(tx *database.Transaction, params []expr.Param) error
+	// Clone returns a deep copy of the node and its children.
+	Clone() Node
 }
 
 type inputNode interface {
@@ -197,6 +329,23 @@ func (n *node) SetRight(rn Node) {
 	n.right = rn
 }
 
+// clone returns a copy of n with cloned children.
+func (n *node) clone() node {
+	return node{
+		op:    n.op,
+		left:  cloneNode(n.left),
+		right: cloneNode(n.right),
+	}
+}
+
+func cloneNode(n Node) Node {
+	if n == nil {
+		return nil
+	}
+
+	return n.Clone()
+}
+
 type selectionNode struct {
 	node
 
@@ -230,19 +379,21 @@ func (n *selectionNode) toStream(st document.Stream) (document.Stream, error) {
 		return st, nil
 	}
 
-	stack := expr.EvalStack{
+	cond, err := expr.Prepare(n.cond, &expr.StatementEnv{
 		Tx:     n.tx,
 		Params: n.params,
+	})
+	if err != nil {
+		return st, err
 	}
 
 	return st.Filter(func(d document.Document) (bool, error) {
-		stack.Document = d
-		v, err := n.cond.Eval(stack)
+		v, err := cond(d)
 		if err != nil {
 			return false, err
 		}
 
-		ok, err := v.IsTruthy()
+		ok, err := document.IsTruthy(v)
 		if err != nil {
 			return false, err
 		}
@@ -250,6 +401,13 @@ func (n *selectionNode) toStream(st document.Stream) (document.Stream, error) {
 	}), nil
 }
 
+func (n *selectionNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.cond = expr.Clone(n.cond)
+	return &c
+}
+
 func (n *selectionNode) String() string {
 	return fmt.Sprintf("σ(cond: %s)", n.cond)
 }
@@ -285,6 +443,12 @@ func (n *limitNode) toStream(st document.Stream) (document.Stream, error) {
 	return st.Limit(n.limit), nil
 }
 
+func (n *limitNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	return &c
+}
+
 func (n *limitNode) String() string {
 	return fmt.Sprintf("Limit(%d)", n.limit)
 }
@@ -310,6 +474,12 @@ func NewOffsetNode(n Node, offset int) Node {
 	}
 }
 
+func (n *offsetNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	return &c
+}
+
 func (n *offsetNode) String() string {
 	return fmt.Sprintf("Offset(%d)", n.offset)
 }
@@ -354,25 +524,53 @@ func (n *setNode) Bind(tx *database.Transaction, params []expr.Param) (err error
 	return
 }
 
+func (n *setNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.path = append(document.Path(nil), n.path...)
+	c.e = expr.Clone(n.e)
+	return &c
+}
+
 func (n *setNode) String() string {
 	return fmt.Sprintf("Set(%s = %s)", n.path, n.e)
 }
 
 func (n *setNode) toStream(st document.Stream) (document.Stream, error) {
 	var fb document.FieldBuffer
+	var ud updatedDocument
 
-	stack := expr.EvalStack{
+	e, err := expr.Prepare(n.e, &expr.StatementEnv{
 		Tx:     n.tx,
 		Params: n.params,
+	})
+	if err != nil {
+		return st, err
 	}
 
 	return st.Map(func(d document.Document) (document.Document, error) {
-		stack.Document = d
-		ev, err := n.e.Eval(stack)
+		ev, err := e(d)
 		if err != nil && err != document.ErrFieldNotFound {
 			return nil, err
 		}
 
+		path := n.path
+		if n.tx.DB().CaseInsensitiveFields {
+			path = path.Fold(d)
+		}
+
+		// documents whose value is already ev are left untouched
+		old, err := path.GetValue(d)
+		if err == nil {
+			same, err := sameValue(old, ev)
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				return ud.set(d, false), nil
+			}
+		}
+
 		fb.Reset()
 
 		err = fb.ScanDocument(d)
@@ -380,51 +578,111 @@ func (n *setNode) toStream(st document.Stream) (document.Stream, error) {
 			return nil, err
 		}
 
-		err = fb.Set(n.path, ev)
+		err = fb.Set(path, ev)
 		if err != nil {
 			return nil, err
 		}
 
-		return &fb, nil
+		return ud.set(&fb, true), nil
 	}), nil
 }
 
+// sameValue returns true if replacing old by v leaves the document unchanged.
+// Values are compared by type and encoded value rather than with IsEqual, which follows
+// the comparators registered for their type: values equal for a comparator can still differ.
+func sameValue(old, v document.Value) (bool, error) {
+	if old.Type != v.Type {
+		return false, nil
+	}
+
+	a, err := old.MarshalBinary()
+	if err != nil {
+		return false, err
+	}
+
+	b, err := v.MarshalBinary()
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(a, b), nil
+}
+
+// updatedDocument is a document returned by the nodes of the SET and UNSET clauses.
+// It records whether any of these nodes changed the document, so that unchanged
+// documents are not written again by the replacement node.
+type updatedDocument struct {
+	document.Document
+
+	changed bool
+}
+
+// set d as the document and return ud. d is changed if changed is true
+// or if it was changed by a previous node.
+func (ud *updatedDocument) set(d document.Document, changed bool) document.Document {
+	if prev, ok := d.(*updatedDocument); ok {
+		changed = changed || prev.changed
+		d = prev.Document
+	}
+
+	ud.Document = d
+	ud.changed = changed
+	return ud
+}
+
+func (ud *updatedDocument) Key() []byte {
+	if k, ok := ud.Document.(document.Keyer); ok {
+		return k.Key()
+	}
+
+	return nil
+}
+
 type unsetNode struct {
 	node
 
-	field string
+	path document.Path
+	tx   *database.Transaction
 }
 
 var _ operationNode = (*unsetNode)(nil)
 
 // NewUnsetNode creates a node that removes a value at a given path for every document of the stream.
-func NewUnsetNode(n Node, field string) Node {
+// Documents where the path doesn't resolve to a value are left untouched.
+func NewUnsetNode(n Node, path document.Path) Node {
 	return &unsetNode{
 		node: node{
 			op:   Unset,
 			left: n,
 		},
-		field: field,
+		path: path,
 	}
 }
 
 func (n *unsetNode) Bind(tx *database.Transaction, params []expr.Param) error {
+	n.tx = tx
 	return nil
 }
 
 func (n *unsetNode) toStream(st document.Stream) (document.Stream, error) {
 	var fb document.FieldBuffer
+	var ud updatedDocument
 
 	return st.Map(func(d document.Document) (document.Document, error) {
 		fb.Reset()
 
-		_, err := d.GetByField(n.field)
+		path := n.path
+		if n.tx.DB().CaseInsensitiveFields {
+			path = path.Fold(d)
+		}
+
+		_, err := path.GetValue(d)
 		if err != nil {
-			if err != document.ErrFieldNotFound {
+			if err != document.ErrFieldNotFound && err != document.ErrValueNotFound {
 				return nil, err
 			}
 
-			return d, nil
+			return ud.set(d, false), nil
 		}
 
 		err = fb.ScanDocument(d)
@@ -432,17 +690,23 @@ func (n *unsetNode) toStream(st document.Stream) (document.Stream, error) {
 			return nil, err
 		}
 
-		err = fb.Delete(n.field)
+		err = fb.DeletePath(path)
 		if err != nil {
 			return nil, err
 		}
 
-		return &fb, nil
+		return ud.set(&fb, true), nil
 	}), nil
 }
 
+func (n *unsetNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	return &c
+}
+
 func (n *unsetNode) String() string {
-	return fmt.Sprintf("Unset(%s)", n.field)
+	return fmt.Sprintf("Unset(%s)", n.path)
 }
 
 // A GroupingNode is a node that groups documents by value.
@@ -486,6 +750,14 @@ func (n *GroupingNode) toStream(st document.Stream) (document.Stream, error) {
 	}), nil
 }
 
+// Clone returns a deep copy of the node and its children.
+func (n *GroupingNode) Clone() Node {
+	c := *n
+	c.node = n.node.clone()
+	c.Expr = expr.Clone(n.Expr)
+	return &c
+}
+
 func (n *GroupingNode) String() string {
 	return fmt.Sprintf("Group(%s)", n.Expr)
 }
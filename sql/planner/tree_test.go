@@ -0,0 +1,132 @@
+package planner_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/planner"
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/sql/scanner"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreeClone(t *testing.T) {
+	parse := func(t *testing.T, s string) *planner.Tree {
+		q, err := parser.ParseQuery(s)
+		require.NoError(t, err)
+		require.Len(t, q.Statements, 1)
+		return q.Statements[0].(*planner.Tree)
+	}
+
+	queries := []string{
+		"SELECT a + 1 AS b, COUNT(*) FROM test WHERE a > 1 AND c IN [1, 2] GROUP BY a + 1 ORDER BY b LIMIT 10 OFFSET 2",
+		"SELECT DISTINCT CAST(a AS TEXT), {x: [a, b]} FROM UNNEST([1, 2]) AS u WHERE value NOT LIKE 'a%'",
+		"UPDATE test SET a = b * 2, c.d = 1 WHERE e IS NOT NULL",
+		"UPDATE test UNSET f",
+		"DELETE FROM test WHERE a >= ANY [1, 2]",
+	}
+
+	for _, q := range queries {
+		t.Run(q, func(t *testing.T) {
+			tree := parse(t, q)
+			clone := tree.Clone()
+			require.Equal(t, tree, clone)
+
+			// modify every operator and every node of the clone.
+			for n := clone.Root; n != nil; n = n.Left() {
+				if pn, ok := n.(*planner.ProjectionNode); ok {
+					for _, f := range pn.Expressions {
+						if pe, ok := f.(planner.ProjectedExpr); ok {
+							if op, ok := pe.Expr.(expr.Operator); ok {
+								op.SetLeftHandExpr(expr.TextValue("mutated"))
+							}
+						}
+					}
+				}
+				if gn, ok := n.(*planner.GroupingNode); ok {
+					gn.Expr.(expr.Operator).SetRightHandExpr(expr.TextValue("mutated"))
+				}
+				if n.Left() != nil && n.Left().Left() == nil {
+					n.SetLeft(nil)
+				}
+			}
+
+			require.Equal(t, parse(t, q), tree)
+		})
+	}
+}
+
+func TestTreeIsReadOnly(t *testing.T) {
+	root := planner.NewSelectionNode(planner.NewTableInputNode("foo"), expr.BoolValue(true))
+	tree := planner.NewTree(root)
+	require.True(t, tree.IsReadOnly())
+	require.Equal(t, []string{"foo"}, tree.Tables())
+
+	// a write on the right side of a node
+	root.SetRight(planner.NewDeletionNode(planner.NewTableInputNode("bar"), "bar"))
+	require.False(t, tree.IsReadOnly())
+	require.Equal(t, []string{"bar", "foo"}, tree.Tables())
+}
+
+func TestTreeSortRegisteredComparator(t *testing.T) {
+	// values of this type are version strings like "1.10.2"
+	const versionType document.ValueType = 0x12
+	document.RegisterComparator(versionType, func(a, b document.Value) int {
+		var av, bv [3]int
+		fmt.Sscanf(a.V.(string), "%d.%d.%d", &av[0], &av[1], &av[2])
+		fmt.Sscanf(b.V.(string), "%d.%d.%d", &bv[0], &bv[1], &bv[2])
+
+		for i := range av {
+			if av[i] != bv[i] {
+				return av[i] - bv[i]
+			}
+		}
+		return 0
+	})
+	defer document.RegisterComparator(versionType, nil)
+
+	var values expr.LiteralExprList
+	for _, v := range []document.Value{
+		{Type: versionType, V: "1.10.0"},
+		document.NewIntegerValue(1),
+		{Type: versionType, V: "1.2.3"},
+		{Type: versionType, V: "0.9.12"},
+		{Type: versionType, V: "1.9.0"},
+	} {
+		d := document.NewFieldBuffer().Add("v", v)
+		values = append(values, expr.LiteralValue(document.NewDocumentValue(d)))
+	}
+
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	tx, err := db.Begin(false)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	// values are grouped by type, custom types coming first
+	tests := map[scanner.Token][]string{
+		scanner.ASC:  {"0.9.12", "1.2.3", "1.9.0", "1.10.0", "1"},
+		scanner.DESC: {"1", "1.10.0", "1.9.0", "1.2.3", "0.9.12"},
+	}
+
+	for dir, want := range tests {
+		tree := planner.NewTree(planner.NewSortNode(planner.NewValuesInputNode(values, ""), expr.Path(parsePath(t, "v")), dir))
+		res, err := tree.Run(tx.Transaction, nil)
+		require.NoError(t, err)
+
+		var got []string
+		err = res.Iterate(func(d document.Document) error {
+			v, err := d.GetByField("v")
+			got = append(got, fmt.Sprintf("%v", v.V))
+			return err
+		})
+		require.NoError(t, err)
+		require.NoError(t, res.Close())
+		require.Equal(t, want, got, dir.String())
+	}
+}
@@ -18,6 +18,12 @@ func (stmt AlterStmt) IsReadOnly() bool {
 	return false
 }
 
+// Tables returns the current and the new name of the table.
+// It implements the Statement interface.
+func (stmt AlterStmt) Tables() []string {
+	return []string{stmt.TableName, stmt.NewTableName}
+}
+
 // Run runs the ALTER TABLE statement in the given transaction.
 // It implements the Statement interface.
 func (stmt AlterStmt) Run(tx *database.Transaction, _ []expr.Param) (Result, error) {
@@ -49,6 +55,11 @@ func (stmt AlterTableAddField) IsReadOnly() bool {
 	return false
 }
 
+// Tables returns the name of the altered table. It implements the Statement interface.
+func (stmt AlterTableAddField) Tables() []string {
+	return []string{stmt.TableName}
+}
+
 // Run runs the ALTER TABLE ADD FIELD statement in the given transaction.
 // It implements the Statement interface.
 func (stmt AlterTableAddField) Run(tx *database.Transaction, _ []expr.Param) (Result, error) {
@@ -143,6 +143,10 @@ func checkConstraints(constraints []database.FieldConstraint) error {
 			continue
 		}
 
+		if fc.IsGenerated() {
+			return fmt.Errorf("generated field %q cannot have a default value", fc.Path)
+		}
+
 		targetType := fc.Type
 
 		// if there is no type constraint, numbers must be converted to double
@@ -20,6 +20,11 @@ func (stmt CreateTableStmt) IsReadOnly() bool {
 	return false
 }
 
+// Tables returns the name of the created table. It implements the Statement interface.
+func (stmt CreateTableStmt) Tables() []string {
+	return []string{stmt.TableName}
+}
+
 // Run runs the Create table statement in the given transaction.
 // It implements the Statement interface.
 func (stmt CreateTableStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
@@ -57,6 +62,11 @@ func (stmt CreateIndexStmt) IsReadOnly() bool {
 	return false
 }
 
+// Tables returns the name of the indexed table. It implements the Statement interface.
+func (stmt CreateIndexStmt) Tables() []string {
+	return []string{stmt.TableName}
+}
+
 // Run runs the Create index statement in the given transaction.
 // It implements the Statement interface.
 func (stmt CreateIndexStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
@@ -86,3 +96,57 @@ func (stmt CreateIndexStmt) Run(tx *database.Transaction, args []expr.Param) (Re
 
 	return res, err
 }
+
+// CreateTriggerStmt is a DSL that allows creating a full CREATE TRIGGER statement.
+type CreateTriggerStmt struct {
+	TriggerName string
+	TableName   string
+	Timing      database.TriggerTiming
+	Event       database.TriggerEvent
+	IfNotExists bool
+
+	// SQL of the statement run by the trigger.
+	Statement string
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt CreateTriggerStmt) IsReadOnly() bool {
+	return false
+}
+
+// Tables returns the name of the table the trigger is created on.
+// It implements the Statement interface.
+func (stmt CreateTriggerStmt) Tables() []string {
+	return []string{stmt.TableName}
+}
+
+// Run runs the Create trigger statement in the given transaction.
+// It implements the Statement interface.
+func (stmt CreateTriggerStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
+	var res Result
+
+	if stmt.TriggerName == "" {
+		return res, errors.New("missing trigger name")
+	}
+
+	if stmt.TableName == "" {
+		return res, errors.New("missing table name")
+	}
+
+	if stmt.Statement == "" {
+		return res, errors.New("missing trigger statement")
+	}
+
+	err := tx.CreateTrigger(database.TriggerConfig{
+		TriggerName: stmt.TriggerName,
+		TableName:   stmt.TableName,
+		Timing:      stmt.Timing,
+		Event:       stmt.Event,
+		Statement:   stmt.Statement,
+	})
+	if stmt.IfNotExists && err == database.ErrTriggerAlreadyExists {
+		err = nil
+	}
+
+	return res, err
+}
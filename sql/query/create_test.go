@@ -1,6 +1,8 @@
 package query_test
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/genjidb/genji"
@@ -39,6 +41,8 @@ func TestCreateTable(t *testing.T) {
 		{"With incoherent constraint(document)", "CREATE TABLE test(a INTEGER, a.b TEXT);", true},
 		{"With incoherent constraint(array)", "CREATE TABLE test(a INTEGER, a[0] TEXT);", true},
 		{"With duplicate constraints", "CREATE TABLE test(a INTEGER, a TEXT);", true},
+		{"With generated field", "CREATE TABLE test(a INTEGER, b INTEGER AS (a * 2));", false},
+		{"With generated field and default", "CREATE TABLE test(a INTEGER, b INTEGER AS (a * 2) DEFAULT 1);", true},
 	}
 
 	for _, test := range tests {
@@ -204,6 +208,118 @@ func TestCreateTable(t *testing.T) {
 	})
 }
 
+func TestCreateTableGenerated(t *testing.T) {
+	queryJSON := func(t *testing.T, db *genji.DB, q string) string {
+		res, err := db.Query(q)
+		require.NoError(t, err)
+		defer res.Close()
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	setup := func(t *testing.T) *genji.DB {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+
+		err = db.Exec(`
+			CREATE TABLE orders(
+				id INTEGER PRIMARY KEY,
+				item.price DOUBLE,
+				total DOUBLE AS (item.price * quantity),
+				large BOOL AS (total > 15)
+			);
+			CREATE INDEX idx_total ON orders (total);
+			INSERT INTO orders (id, name, item, quantity) VALUES (1, "foo", {price: 10}, 2), (2, "bar", {price: 2.5}, 4);
+		`)
+		require.NoError(t, err)
+		return db
+	}
+
+	t.Run("Insert", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		require.JSONEq(t, `[{"id": 1, "total": 20.0, "large": true}, {"id": 2, "total": 10.0, "large": false}]`,
+			queryJSON(t, db, "SELECT id, total, large FROM orders"))
+
+	})
+
+	t.Run("Update a dependency", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		err := db.Exec(`UPDATE orders SET item.price = 30 WHERE id = 1`)
+		require.NoError(t, err)
+		err = db.Exec(`UPDATE orders SET quantity = 1 WHERE id = 2`)
+		require.NoError(t, err)
+
+		require.JSONEq(t, `[{"id": 1, "total": 60.0, "large": true}, {"id": 2, "total": 2.5, "large": false}]`,
+			queryJSON(t, db, "SELECT id, total, large FROM orders"))
+
+		// the index is updated with the generated values
+		require.JSONEq(t, `[{"id": 1}]`, queryJSON(t, db, "SELECT id FROM orders WHERE total = 60.0"))
+		require.JSONEq(t, `[]`, queryJSON(t, db, "SELECT id FROM orders WHERE total = 20.0"))
+		require.JSONEq(t, `[{"plan": "Index(idx_total) -> ∏(id)"}]`, queryJSON(t, db, "EXPLAIN SELECT id FROM orders WHERE total = 60.0"))
+	})
+
+	t.Run("Direct writes", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		for _, q := range []string{
+			`INSERT INTO orders (id, total) VALUES (3, 10)`,
+			`UPDATE orders SET total = 10`,
+			`UPDATE orders UNSET large`,
+		} {
+			err := db.Exec(q)
+			var cerr *database.ConstraintViolationError
+			require.True(t, errors.As(err, &cerr), q)
+			require.Equal(t, database.GeneratedViolation, cerr.Kind)
+			require.Equal(t, "orders", cerr.Table)
+		}
+
+		require.JSONEq(t, `[{"id": 1, "total": 20.0}, {"id": 2, "total": 10.0}]`, queryJSON(t, db, "SELECT id, total FROM orders"))
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`CREATE TABLE test(a, b INTEGER AS (a), c AS (a) NOT NULL, d AS (a.b))`)
+		require.NoError(t, err)
+
+		err = db.Exec(`INSERT INTO test (a) VALUES (1)`)
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"a": 1.0, "b": 1, "c": 1.0, "d": null}]`, queryJSON(t, db, "SELECT * FROM test"))
+
+		err = db.Exec(`INSERT INTO test (a) VALUES ('foo')`)
+		var cerr *database.ConstraintViolationError
+		require.True(t, errors.As(err, &cerr))
+		require.Equal(t, database.TypeViolation, cerr.Kind)
+
+		err = db.Exec(`INSERT INTO test (a) VALUES (NULL)`)
+		require.True(t, errors.As(err, &cerr))
+		require.Equal(t, database.NotNullViolation, cerr.Kind)
+	})
+
+	t.Run("Quoted field", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test(`a b` INTEGER, y AS (`a b` + 1))")
+		require.NoError(t, err)
+
+		err = db.Exec("INSERT INTO test (`a b`) VALUES (1)")
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"a b": 1, "y": 2}]`, queryJSON(t, db, "SELECT * FROM test"))
+	})
+}
+
 func TestCreateIndex(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -234,4 +350,164 @@ func TestCreateIndex(t *testing.T) {
 			require.NoError(t, err)
 		})
 	}
+
+	t.Run("Existing documents", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE test(a ARRAY);
+			INSERT INTO test (a, b) VALUES ([1.5], 1), (['s'], {c: 'x'}), ([1.5], 2);
+			CREATE INDEX idx_a ON test(a);
+			CREATE INDEX idx_b ON test(b);
+		`)
+		require.NoError(t, err)
+
+		tests := []struct {
+			query    string
+			expected string
+		}{
+			{"SELECT b FROM test WHERE a = [1.5]", `[{"b": 1}, {"b": 2}]`},
+			{"SELECT b FROM test WHERE a = ['s']", `[{"b": {"c": "x"}}]`},
+			{"SELECT a FROM test WHERE b = {c: 'x'}", `[{"a": ["s"]}]`},
+			{"SELECT a FROM test WHERE b > 1", `[{"a": [1.5]}]`},
+		}
+
+		for _, test := range tests {
+			st, err := db.Query(test.query)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = document.IteratorToJSONArray(&buf, st)
+			st.Close()
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String(), test.query)
+		}
+
+		err = db.Exec("CREATE UNIQUE INDEX idx_unique_a ON test(a)")
+		require.Error(t, err)
+	})
+}
+
+func TestCreateTrigger(t *testing.T) {
+	queryJSON := func(t *testing.T, db *genji.DB, q string) string {
+		res, err := db.Query(q)
+		require.NoError(t, err)
+		defer res.Close()
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	setup := func(t *testing.T) *genji.DB {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+
+		err = db.Exec(`
+			CREATE TABLE users(id INTEGER PRIMARY KEY, name TEXT);
+			CREATE TABLE audit_log;
+			INSERT INTO users (id, name) VALUES (1, "foo"), (2, "bar");
+		`)
+		require.NoError(t, err)
+		return db
+	}
+
+	t.Run("After update", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		err := db.Exec(`CREATE TRIGGER log_update AFTER UPDATE ON users FOR EACH ROW
+			INSERT INTO audit_log VALUES {tbl: "users", new: new_doc, old: old_doc, name: old_doc.name}`)
+		require.NoError(t, err)
+
+		err = db.Exec(`UPDATE users SET name = "baz" WHERE id = 1`)
+		require.NoError(t, err)
+
+		require.JSONEq(t, `[{"tbl": "users", "new": {"id": 1, "name": "baz"}, "old": {"id": 1, "name": "foo"}, "name": "foo"}]`,
+			queryJSON(t, db, "SELECT tbl, new, old, name FROM audit_log"))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		err := db.Exec(`
+			CREATE TRIGGER before_delete BEFORE DELETE ON users INSERT INTO audit_log VALUES {deleted: old_doc.id, new: new_doc};
+			CREATE TRIGGER after_delete AFTER DELETE ON users INSERT INTO audit_log VALUES {deleted_after: old_doc.id};
+		`)
+		require.NoError(t, err)
+
+		err = db.Exec(`DELETE FROM users WHERE id = 2`)
+		require.NoError(t, err)
+
+		require.JSONEq(t, `[{"deleted": 2, "new": null}, {"deleted_after": 2}]`, queryJSON(t, db, "SELECT * FROM audit_log"))
+	})
+
+	t.Run("Before insert error", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		err := db.Exec(`
+			CREATE TABLE guard(name TEXT NOT NULL);
+			CREATE TRIGGER check_name BEFORE INSERT ON users INSERT INTO guard VALUES {name: new_doc.name};
+		`)
+		require.NoError(t, err)
+
+		err = db.Exec(`INSERT INTO users (id) VALUES (3)`)
+		require.Error(t, err)
+
+		err = db.Exec(`INSERT INTO users (id, name) VALUES (4, "baz")`)
+		require.NoError(t, err)
+
+		require.JSONEq(t, `[{"id": 1}, {"id": 2}, {"id": 4}]`, queryJSON(t, db, "SELECT id FROM users"))
+		require.JSONEq(t, `[{"name": "baz"}]`, queryJSON(t, db, "SELECT name FROM guard"))
+	})
+
+	t.Run("Quoted field", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		err := db.Exec(`
+			CREATE TABLE docs;
+			INSERT INTO docs (new_doc) VALUES ('field');
+			CREATE TRIGGER copy AFTER INSERT ON users UPDATE docs SET id = new_doc.id WHERE ` + "`new_doc`" + ` = 'field';
+		`)
+		require.NoError(t, err)
+
+		err = db.Exec(`INSERT INTO users (id, name) VALUES (3, "baz")`)
+		require.NoError(t, err)
+
+		require.JSONEq(t, `[{"id": 3, "new_doc": "field"}]`, queryJSON(t, db, "SELECT id, new_doc FROM docs"))
+	})
+
+	t.Run("Recursion", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		err := db.Exec(`CREATE TRIGGER loop AFTER INSERT ON audit_log INSERT INTO audit_log VALUES {}`)
+		require.NoError(t, err)
+
+		err = db.Exec(`INSERT INTO audit_log VALUES {}`)
+		require.Error(t, err)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		err := db.Exec(`CREATE TRIGGER trg AFTER INSERT ON unknown DELETE FROM users`)
+		require.True(t, errors.Is(err, database.ErrTableNotFound))
+
+		err = db.Exec(`CREATE TRIGGER trg AFTER INSERT ON users DELETE FROM audit_log`)
+		require.NoError(t, err)
+
+		err = db.Exec(`CREATE TRIGGER trg AFTER INSERT ON users DELETE FROM audit_log`)
+		require.Equal(t, database.ErrTriggerAlreadyExists, err)
+
+		err = db.Exec(`CREATE TRIGGER IF NOT EXISTS trg AFTER INSERT ON users DELETE FROM audit_log`)
+		require.NoError(t, err)
+	})
 }
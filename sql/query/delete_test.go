@@ -21,6 +21,8 @@ func TestDeleteStmt(t *testing.T) {
 		{"With cond", "DELETE FROM test WHERE b = 'bar1'", false, `{"d": "foo3", "b": "bar2", "e": "bar3"}`, nil},
 		{"Table not found", "DELETE FROM foo WHERE b = 'bar1'", true, "", nil},
 		{"Read-only table", "DELETE FROM __genji_tables", true, "", nil},
+		{"Missing WHERE", "DELETE FROM test b = 'bar1'", true, "", nil},
+		{"Typo in WHERE", "DELETE FROM test WERE b = 'bar1'", true, "", nil},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,150 @@
+package query
+
+import (
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// DescribeStmt is a DSL that allows creating a full DESCRIBE statement.
+// It returns one document per field of the table that has a constraint or an index,
+// in the order of declaration of the constraints, followed by the fields that are only indexed:
+//
+//	{"field": "a.b", "type": "integer", "primary_key": false, "not_null": true,
+//	 "default": null, "generated": null, "indexes": [{"name": "idx_a_b", "unique": false}]}
+//
+// The type, default and generated fields are null if the field has no such constraint.
+type DescribeStmt struct {
+	TableName string
+}
+
+// IsReadOnly always returns true. It implements the Statement interface.
+func (stmt DescribeStmt) IsReadOnly() bool {
+	return true
+}
+
+// Tables returns the name of the described table. It implements the Statement interface.
+func (stmt DescribeStmt) Tables() []string {
+	return []string{stmt.TableName}
+}
+
+// Run returns the fields of the table. It returns database.ErrTableNotFound
+// if the table doesn't exist. It implements the Statement interface.
+func (stmt DescribeStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
+	var res Result
+
+	t, err := tx.GetTable(stmt.TableName)
+	if err != nil {
+		return res, err
+	}
+
+	info, err := t.Info()
+	if err != nil {
+		return res, err
+	}
+
+	idxs, err := tx.ListIndexes()
+	if err != nil {
+		return res, err
+	}
+
+	fields := make([]*document.FieldBuffer, 0, len(info.FieldConstraints))
+	paths := make([]document.Path, 0, len(info.FieldConstraints))
+	for _, fc := range info.FieldConstraints {
+		fields = append(fields, describeField(fc))
+		paths = append(paths, fc.Path)
+	}
+
+	// indexes are listed with the field they index, which may have no constraint.
+	indexes := make([]document.ValueBuffer, len(fields))
+	for _, idx := range idxs {
+		if idx.TableName != stmt.TableName {
+			continue
+		}
+
+		i := 0
+		for i < len(paths) && !paths[i].IsEqual(idx.Path) {
+			i++
+		}
+		if i == len(paths) {
+			fields = append(fields, describeField(database.FieldConstraint{Path: idx.Path}))
+			paths = append(paths, idx.Path)
+			indexes = append(indexes, nil)
+		}
+
+		indexes[i] = indexes[i].Append(document.NewDocumentValue(document.NewFieldBuffer().
+			Add("name", document.NewTextValue(idx.IndexName)).
+			Add("unique", document.NewBoolValue(idx.Unique))))
+	}
+
+	docs := make([]document.Document, len(fields))
+	for i, fb := range fields {
+		docs[i] = fb.Add("indexes", document.NewArrayValue(indexes[i]))
+	}
+
+	res.Stream = document.NewStream(document.NewIterator(docs...))
+	return res, nil
+}
+
+// describeField returns the description of the field constrained by fc, without its indexes.
+func describeField(fc database.FieldConstraint) *document.FieldBuffer {
+	null := document.NewNullValue()
+
+	fb := document.NewFieldBuffer().Add("field", document.NewTextValue(fc.Path.String()))
+
+	if fc.Type != 0 {
+		fb.Add("type", document.NewTextValue(fc.Type.String()))
+	} else {
+		fb.Add("type", null)
+	}
+
+	fb.Add("primary_key", document.NewBoolValue(fc.IsPrimaryKey))
+	fb.Add("not_null", document.NewBoolValue(fc.IsNotNull))
+
+	if fc.HasDefaultValue() {
+		fb.Add("default", fc.DefaultValue)
+	} else {
+		fb.Add("default", null)
+	}
+
+	if fc.IsGenerated() {
+		fb.Add("generated", document.NewTextValue(fc.GeneratedExpr))
+	} else {
+		fb.Add("generated", null)
+	}
+
+	return fb
+}
+
+// ShowTablesStmt is a DSL that allows creating a full SHOW TABLES statement.
+// It returns one document per table, sorted by name: {"name": "foo"}.
+type ShowTablesStmt struct{}
+
+// IsReadOnly always returns true. It implements the Statement interface.
+func (stmt ShowTablesStmt) IsReadOnly() bool {
+	return true
+}
+
+// Tables returns nil, the listed tables are only known when the statement runs.
+// It implements the Statement interface.
+func (stmt ShowTablesStmt) Tables() []string {
+	return nil
+}
+
+// Run returns the names of the tables. It implements the Statement interface.
+func (stmt ShowTablesStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
+	var res Result
+
+	names, err := tx.ListTables()
+	if err != nil {
+		return res, err
+	}
+
+	docs := make([]document.Document, len(names))
+	for i, name := range names {
+		docs[i] = document.NewFieldBuffer().Add("name", document.NewTextValue(name))
+	}
+
+	res.Stream = document.NewStream(document.NewIterator(docs...))
+	return res, nil
+}
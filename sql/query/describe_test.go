@@ -0,0 +1,69 @@
+package query_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test(id INTEGER PRIMARY KEY, a.b TEXT NOT NULL, c DEFAULT 10, d DOUBLE AS (c * 2));
+		CREATE TABLE other;
+		CREATE UNIQUE INDEX idx_a_b ON test (a.b);
+		CREATE INDEX idx_e ON test (e);
+		CREATE INDEX idx_e2 ON test (e);
+		CREATE INDEX idx_other ON other (a.b);
+	`)
+	require.NoError(t, err)
+
+	res, err := db.Query("DESCRIBE test")
+	require.NoError(t, err)
+	defer res.Close()
+
+	var buf bytes.Buffer
+	err = document.IteratorToJSONArray(&buf, res)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"field": "id", "type": "integer", "primary_key": true, "not_null": false, "default": null, "generated": null, "indexes": []},
+		{"field": "a.b", "type": "text", "primary_key": false, "not_null": true, "default": null, "generated": null, "indexes": [{"name": "idx_a_b", "unique": true}]},
+		{"field": "c", "type": null, "primary_key": false, "not_null": false, "default": 10.0, "generated": null, "indexes": []},
+		{"field": "d", "type": "double", "primary_key": false, "not_null": false, "default": null, "generated": "c * 2", "indexes": []},
+		{"field": "e", "type": null, "primary_key": false, "not_null": false, "default": null, "generated": null, "indexes": [{"name": "idx_e", "unique": false}, {"name": "idx_e2", "unique": false}]}
+	]`, buf.String())
+
+	_, err = db.Query("DESCRIBE unknown")
+	require.True(t, errors.Is(err, database.ErrTableNotFound))
+}
+
+func TestShowTables(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	showTables := func() string {
+		res, err := db.Query("SHOW TABLES")
+		require.NoError(t, err)
+		defer res.Close()
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	require.JSONEq(t, `[]`, showTables())
+
+	err = db.Exec("CREATE TABLE foo; CREATE TABLE bar; CREATE INDEX idx_foo ON foo (a)")
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"name": "bar"}, {"name": "foo"}]`, showTables())
+}
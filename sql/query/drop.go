@@ -18,6 +18,11 @@ func (stmt DropTableStmt) IsReadOnly() bool {
 	return false
 }
 
+// Tables returns the name of the dropped table. It implements the Statement interface.
+func (stmt DropTableStmt) Tables() []string {
+	return []string{stmt.TableName}
+}
+
 // Run runs the DropTable statement in the given transaction.
 // It implements the Statement interface.
 func (stmt DropTableStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
@@ -46,6 +51,12 @@ func (stmt DropIndexStmt) IsReadOnly() bool {
 	return false
 }
 
+// Tables returns nil, the table of the index is only known when the statement runs.
+// It implements the Statement interface.
+func (stmt DropIndexStmt) Tables() []string {
+	return nil
+}
+
 // Run runs the DropIndex statement in the given transaction.
 // It implements the Statement interface.
 func (stmt DropIndexStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
@@ -62,3 +73,37 @@ func (stmt DropIndexStmt) Run(tx *database.Transaction, args []expr.Param) (Resu
 
 	return res, err
 }
+
+// DropTriggerStmt is a DSL that allows creating a DROP TRIGGER query.
+type DropTriggerStmt struct {
+	TriggerName string
+	IfExists    bool
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt DropTriggerStmt) IsReadOnly() bool {
+	return false
+}
+
+// Tables returns nil, the table of the trigger is only known when the statement runs.
+// It implements the Statement interface.
+func (stmt DropTriggerStmt) Tables() []string {
+	return nil
+}
+
+// Run runs the DropTrigger statement in the given transaction.
+// It implements the Statement interface.
+func (stmt DropTriggerStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
+	var res Result
+
+	if stmt.TriggerName == "" {
+		return res, errors.New("missing trigger name")
+	}
+
+	err := tx.DropTrigger(stmt.TriggerName)
+	if err == database.ErrTriggerNotFound && stmt.IfExists {
+		err = nil
+	}
+
+	return res, err
+}
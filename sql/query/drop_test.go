@@ -76,3 +76,43 @@ func TestDropIndex(t *testing.T) {
 	require.Equal(t, "idx_test1_foo", indexes[0].IndexName)
 	require.Equal(t, false, indexes[0].Unique)
 }
+
+func TestDropTrigger(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		CREATE TABLE counter;
+		CREATE TRIGGER trg1 AFTER INSERT ON test INSERT INTO counter VALUES {};
+	`)
+	require.NoError(t, err)
+
+	err = db.Exec("DROP TRIGGER trg1")
+	require.NoError(t, err)
+
+	err = db.Exec("DROP TRIGGER IF EXISTS trg1")
+	require.NoError(t, err)
+
+	err = db.Exec("DROP TRIGGER trg1")
+	require.Equal(t, database.ErrTriggerNotFound, err)
+
+	// Dropping a table drops its triggers.
+	err = db.Exec(`
+		CREATE TRIGGER trg2 AFTER INSERT ON test INSERT INTO counter VALUES {};
+		DROP TABLE test;
+		CREATE TABLE test;
+		INSERT INTO test VALUES {};
+	`)
+	require.NoError(t, err)
+
+	d, err := db.QueryDocument("SELECT COUNT(*) FROM counter")
+	require.NoError(t, err)
+	v, err := d.GetByField("COUNT(*)")
+	require.NoError(t, err)
+	require.Equal(t, document.NewIntegerValue(0), v)
+
+	err = db.Exec("DROP TRIGGER trg2")
+	require.Equal(t, database.ErrTriggerNotFound, err)
+}
@@ -0,0 +1,154 @@
+package expr
+
+// Clone returns a deep copy of e: operators, lists, documents and function calls
+// are copied recursively so that modifying the copy, for example by calling
+// SetLeftHandExpr on one of its operators, doesn't affect e.
+// Literal values and parameters are immutable and returned as is, as well as
+// expressions unknown to this package, like user-defined functions.
+func Clone(e Expr) Expr {
+	switch t := e.(type) {
+	case nil:
+		return nil
+	case LiteralValue, NamedParam, PositionalParam, PKFunc, Default:
+		return t
+	case Path:
+		return append(Path(nil), t...)
+	case LiteralExprList:
+		l := make(LiteralExprList, len(t))
+		for i := range t {
+			l[i] = Clone(t[i])
+		}
+		return l
+	case TupleExpr:
+		l := make([]Expr, len(t.Elements))
+		for i := range t.Elements {
+			l[i] = Clone(t.Elements[i])
+		}
+		return TupleExpr{Elements: l}
+	case KVPairs:
+		kvp := make(KVPairs, len(t))
+		for i := range t {
+			kvp[i] = KVPair{K: t[i].K, V: Clone(t[i].V)}
+		}
+		return kvp
+	case Parentheses:
+		return Parentheses{E: Clone(t.E)}
+	case SpreadExpr:
+		return SpreadExpr{E: Clone(t.E)}
+	case PathAccessExpr:
+		return PathAccessExpr{E: Clone(t.E), Path: append(Path(nil), t.Path...)}
+	case CastFunc:
+		t.Expr = Clone(t.Expr)
+		return t
+	case TypeOfFunc:
+		return TypeOfFunc{Expr: Clone(t.Expr)}
+	case FieldExistsFunc:
+		return FieldExistsFunc{Path: append(Path(nil), t.Path...)}
+	case ContainsKeyExpr:
+		return ContainsKeyExpr{Doc: Clone(t.Doc), Key: Clone(t.Key)}
+	case JSONExtractFunc:
+		return JSONExtractFunc{Expr: Clone(t.Expr), Path: Clone(t.Path)}
+	case SplitFunc:
+		return SplitFunc{Expr: Clone(t.Expr), Sep: Clone(t.Sep)}
+	case Subquery:
+		return cloneSubquery(t)
+	case ExistsExpr:
+		return ExistsExpr{Subquery: cloneSubquery(t.Subquery), Not: t.Not}
+	case *CountFunc:
+		c := *t
+		c.Expr = Clone(c.Expr)
+		return &c
+	case *MinFunc:
+		c := *t
+		c.Expr = Clone(c.Expr)
+		return &c
+	case *MaxFunc:
+		c := *t
+		c.Expr = Clone(c.Expr)
+		return &c
+	case *SumFunc:
+		c := *t
+		c.Expr = Clone(c.Expr)
+		return &c
+	case *AvgFunc:
+		c := *t
+		c.Expr = Clone(c.Expr)
+		return &c
+	case Operator:
+		return rebuildOperator(t, Clone(t.LeftHand()), Clone(t.RightHand()))
+	}
+
+	return e
+}
+
+// rebuildOperator returns a new operator of the same kind as op
+// using a and b as operands. Operators unknown to this package are returned as is.
+func rebuildOperator(op Operator, a, b Expr) Expr {
+	switch t := op.(type) {
+	case *addOp:
+		return Add(a, b)
+	case *subOp:
+		return Sub(a, b)
+	case *mulOp:
+		return Mul(a, b)
+	case *divOp:
+		return Div(a, b)
+	case *modOp:
+		return Mod(a, b)
+	case *bitwiseAndOp:
+		return BitwiseAnd(a, b)
+	case *bitwiseOrOp:
+		return BitwiseOr(a, b)
+	case *bitwiseXorOp:
+		return BitwiseXor(a, b)
+	case eqOp:
+		return Eq(a, b)
+	case neqOp:
+		return Neq(a, b)
+	case gtOp:
+		return Gt(a, b)
+	case gteOp:
+		return Gte(a, b)
+	case ltOp:
+		return Lt(a, b)
+	case lteOp:
+		return Lte(a, b)
+	case quantifiedOp:
+		return quantifiedOp{cmpOp: newCmpOp(a, b, t.Tok), all: t.all}
+	case inOp:
+		return In(a, b)
+	case *notInOp:
+		return NotIn(a, b)
+	case *isOp:
+		return Is(a, b)
+	case *isNotOp:
+		return IsNot(a, b)
+	case *likeOp:
+		return Like(a, b)
+	case *notLikeOp:
+		return NotLike(a, b)
+	case *containsOp:
+		return Contains(a, b)
+	case *regexpOp:
+		return Regexp(a, b)
+	case *notRegexpOp:
+		return NotRegexp(a, b)
+	case *AndOp:
+		return And(a, b)
+	case *OrOp:
+		return Or(a, b)
+	}
+
+	return op
+}
+
+// cloneSubquery copies the references of s. The statement is shared,
+// it is not modified when the subquery is evaluated.
+func cloneSubquery(s Subquery) Subquery {
+	var refs []Path
+	for _, ref := range s.Refs {
+		refs = append(refs, append(Path(nil), ref...))
+	}
+
+	return Subquery{Statement: s.Statement, Refs: refs}
+}
@@ -387,6 +387,9 @@ func (op lteOp) String() string {
 // Eval compares a and b together using the operator specified when constructing the CmpOp
 // and returns the result of the comparison.
 // Comparing with NULL always evaluates to NULL.
+// Comparing values of incompatible types evaluates to false, unless
+// strict typing is enabled on the database, in which case it returns an error.
+// If a or b is a tuple, both are compared as tuples.
 func (op cmpOp) Eval(ctx EvalStack) (document.Value, error) {
 	v1, v2, err := op.simpleOperator.eval(ctx)
 	if err != nil {
@@ -397,6 +400,15 @@ func (op cmpOp) Eval(ctx EvalStack) (document.Value, error) {
 		return nullLitteral, nil
 	}
 
+	if op.hasTupleOperand() {
+		return op.compareTuples(ctx.strictTypes(), v1, v2)
+	}
+
+	err = checkComparable(ctx, v1, v2)
+	if err != nil {
+		return falseLitteral, err
+	}
+
 	ok, err := op.compare(v1, v2)
 	if ok {
 		return trueLitteral, err
@@ -405,6 +417,20 @@ func (op cmpOp) Eval(ctx EvalStack) (document.Value, error) {
 	return falseLitteral, err
 }
 
+// checkComparable returns an error if strict typing is enabled
+// and l and r are of incompatible types.
+func checkComparable(ctx EvalStack, l, r document.Value) error {
+	return checkComparableTypes(ctx.strictTypes(), l, r)
+}
+
+func checkComparableTypes(strict bool, l, r document.Value) error {
+	if l.Type == r.Type || (l.Type.IsNumber() && r.Type.IsNumber()) || !strict {
+		return nil
+	}
+
+	return fmt.Errorf("cannot compare %s with %s", l.Type, r.Type)
+}
+
 func (op cmpOp) compare(l, r document.Value) (bool, error) {
 	switch op.Tok {
 	case scanner.EQ:
@@ -424,6 +450,201 @@ func (op cmpOp) compare(l, r document.Value) (bool, error) {
 	}
 }
 
+func (op cmpOp) hasTupleOperand() bool {
+	return isTuple(op.a) || isTuple(op.b)
+}
+
+// compareTuples compares the tuples l and r element by element, from left to right,
+// like the equivalent combination of AND and OR operators would:
+// (a, b) = (1, 2) is the same as a = 1 AND b = 2, and (a, b) < (1, 2) is the same
+// as a < 1 OR (a = 1 AND b < 2).
+// Following the three-valued logic of SQL, NULL elements make the result NULL unless
+// other elements decide it: (1, NULL) = (1, 2) is NULL while (1, NULL) = (2, 2) is false.
+// Comparing tuples of different lengths, or a tuple with another type, returns an error.
+func (op cmpOp) compareTuples(strict bool, l, r document.Value) (document.Value, error) {
+	lt, err := tupleValues(l)
+	if err != nil {
+		return nullLitteral, err
+	}
+	rt, err := tupleValues(r)
+	if err != nil {
+		return nullLitteral, err
+	}
+	if len(lt) != len(rt) {
+		return nullLitteral, fmt.Errorf("cannot compare tuples of different lengths (%d and %d)", len(lt), len(rt))
+	}
+
+	var hasNull bool
+	for i := range lt {
+		if lt[i].Type == document.NullValue || rt[i].Type == document.NullValue {
+			// for = and !=, another element can still make the tuples different
+			if op.Tok == scanner.EQ || op.Tok == scanner.NEQ {
+				hasNull = true
+				continue
+			}
+			return nullLitteral, nil
+		}
+
+		err := checkComparableTypes(strict, lt[i], rt[i])
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		eq, err := lt[i].IsEqual(rt[i])
+		if err != nil {
+			return nullLitteral, err
+		}
+		if eq {
+			continue
+		}
+
+		switch op.Tok {
+		case scanner.EQ:
+			return falseLitteral, nil
+		case scanner.NEQ:
+			return trueLitteral, nil
+		}
+
+		ok, err := op.compare(lt[i], rt[i])
+		if err != nil {
+			return nullLitteral, err
+		}
+		return document.NewBoolValue(ok), nil
+	}
+
+	if hasNull {
+		return nullLitteral, nil
+	}
+
+	// all the elements are equal
+	switch op.Tok {
+	case scanner.EQ, scanner.GTE, scanner.LTE:
+		return trueLitteral, nil
+	}
+
+	return falseLitteral, nil
+}
+
+// tupleValues returns the elements of a tuple evaluated as an array.
+func tupleValues(v document.Value) ([]document.Value, error) {
+	if v.Type != document.ArrayValue {
+		return nil, fmt.Errorf("cannot compare a tuple with %s", v.Type)
+	}
+
+	var values []document.Value
+	err := v.V.(document.Array).Iterate(func(i int, v document.Value) error {
+		values = append(values, v)
+		return nil
+	})
+
+	return values, err
+}
+
+type quantifiedOp struct {
+	*cmpOp
+	all bool
+}
+
+// Any creates an expression that compares a with every element of the array b
+// using the comparison operator tok, and returns true if at least one of the comparisons is true.
+// If b is empty, it returns false.
+func Any(tok scanner.Token, a, b Expr) Expr {
+	return quantifiedOp{cmpOp: newCmpOp(a, b, tok)}
+}
+
+// All creates an expression that compares a with every element of the array b
+// using the comparison operator tok, and returns true if all of the comparisons are true.
+// If b is empty, it returns true.
+func All(tok scanner.Token, a, b Expr) Expr {
+	return quantifiedOp{cmpOp: newCmpOp(a, b, tok), all: true}
+}
+
+// Eval follows the three-valued logic: comparisons involving NULL are unknown.
+// If no comparison decides the result but at least one is unknown, it returns NULL.
+func (op quantifiedOp) Eval(ctx EvalStack) (document.Value, error) {
+	a, err := op.a.Eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	b, err := evalList(ctx, op.b, false)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	if b.Type == document.NullValue {
+		return nullLitteral, nil
+	}
+
+	if b.Type != document.ArrayValue {
+		if ctx.strictTypes() {
+			return nullLitteral, fmt.Errorf("quantified comparison expects an array, got %s", b.Type)
+		}
+		return falseLitteral, nil
+	}
+
+	var decided, unknown bool
+	err = b.V.(document.Array).Iterate(func(i int, v document.Value) error {
+		if a.Type == document.NullValue || v.Type == document.NullValue {
+			unknown = true
+			return nil
+		}
+
+		err := checkComparable(ctx, a, v)
+		if err != nil {
+			return err
+		}
+
+		ok, err := op.compare(a, v)
+		if err != nil {
+			return err
+		}
+
+		// ANY is decided by the first true comparison, ALL by the first false one.
+		if ok != op.all {
+			decided = true
+			return errStop
+		}
+
+		return nil
+	})
+	if err != nil && err != errStop {
+		return nullLitteral, err
+	}
+
+	switch {
+	case decided:
+		return document.NewBoolValue(!op.all), nil
+	case unknown:
+		return nullLitteral, nil
+	}
+
+	return document.NewBoolValue(op.all), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (op quantifiedOp) IsEqual(other Expr) bool {
+	o, ok := other.(quantifiedOp)
+	if !ok {
+		return false
+	}
+
+	return op.all == o.all &&
+		op.Tok == o.Tok &&
+		Equal(op.a, o.a) &&
+		Equal(op.b, o.b)
+}
+
+func (op quantifiedOp) String() string {
+	q := "ANY"
+	if op.all {
+		q = "ALL"
+	}
+
+	return fmt.Sprintf("%v %v %s %v", op.a, op.Tok, q, op.b)
+}
+
 // IsComparisonOperator returns true if e is one of
 // =, !=, >, >=, <, <=, IS, IS NOT, IN, or NOT IN operators.
 func IsComparisonOperator(op Operator) bool {
@@ -448,6 +669,62 @@ func IsOrOperator(e Expr) bool {
 	return ok
 }
 
+// Normalise returns a copy of e where every comparison with a literal or a parameter
+// on the left and a path on the right is flipped, so that the path is on the left.
+// The direction of the operator is adjusted accordingly:
+//   1 = a  --> a = 1
+//   10 > a --> a < 10
+// Other expressions, including comparisons of two paths or of two constants, are left unchanged.
+func Normalise(e Expr) Expr {
+	return Walk(e, func(e Expr) (Expr, bool) {
+		op, ok := e.(Operator)
+		if !ok {
+			return e, true
+		}
+
+		if _, ok := op.RightHand().(Path); !ok || !isConstant(op.LeftHand()) {
+			return e, true
+		}
+
+		a, b := op.RightHand(), op.LeftHand()
+		switch op.(type) {
+		case eqOp:
+			return Eq(a, b), true
+		case neqOp:
+			return Neq(a, b), true
+		case gtOp:
+			return Lt(a, b), true
+		case gteOp:
+			return Lte(a, b), true
+		case ltOp:
+			return Gt(a, b), true
+		case lteOp:
+			return Gte(a, b), true
+		}
+
+		return e, true
+	})
+}
+
+func isConstant(e Expr) bool {
+	switch e.(type) {
+	case LiteralValue, NamedParam, PositionalParam, boundParam:
+		return true
+	}
+
+	return false
+}
+
+// evalList evaluates e, the right side of IN, ANY or ALL, to an array.
+// Subqueries return the list of their values instead of a single value.
+func evalList(ctx EvalStack, e Expr, tuple bool) (document.Value, error) {
+	if s, ok := e.(Subquery); ok {
+		return s.evalList(ctx, tuple)
+	}
+
+	return e.Eval(ctx)
+}
+
 // IsInOperator reports if e is the IN operator.
 func IsInOperator(e Expr) bool {
 	_, ok := e.(inOp)
@@ -463,8 +740,20 @@ func In(a, b Expr) Expr {
 	return inOp{&simpleOperator{a, b, scanner.IN}}
 }
 
+// Eval returns whether a is one of the elements of b.
+// If a is a tuple, the elements of b must be tuples of the same length,
+// and b can be a single tuple between parentheses, like in (a, b) IN ((1, 2)).
 func (op inOp) Eval(ctx EvalStack) (document.Value, error) {
-	a, b, err := op.simpleOperator.eval(ctx)
+	if isTuple(op.a) {
+		return op.evalTuple(ctx)
+	}
+
+	a, err := op.a.Eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	b, err := evalList(ctx, op.b, false)
 	if err != nil {
 		return nullLitteral, err
 	}
@@ -474,20 +763,122 @@ func (op inOp) Eval(ctx EvalStack) (document.Value, error) {
 	}
 
 	if b.Type != document.ArrayValue {
+		// a parameter must be bound to the list of values, like in a IN ?
+		if isParam(op.b) {
+			return nullLitteral, fmt.Errorf("IN operator takes an array, got %s for parameter %s", b.Type, op.b)
+		}
+
 		return falseLitteral, nil
 	}
 
-	ok, err := document.ArrayContains(b.V.(document.Array), a)
+	// values compared with a registered comparator cannot be hashed
+	if document.HasComparator(a.Type) {
+		return op.evalWithComparator(a, b.V.(document.Array))
+	}
+
+	key, err := document.AppendKey(nil, a)
 	if err != nil {
 		return nullLitteral, err
 	}
 
-	if ok {
+	var found bool
+	var buf []byte
+	err = b.V.(document.Array).Iterate(func(i int, v document.Value) error {
+		buf, err = document.AppendKey(buf[:0], v)
+		if err != nil {
+			return err
+		}
+
+		if bytes.Equal(key, buf) {
+			found = true
+			return errStop
+		}
+
+		return nil
+	})
+	if err != nil && err != errStop {
+		return nullLitteral, err
+	}
+
+	if found {
+		return trueLitteral, nil
+	}
+	return falseLitteral, nil
+}
+
+// evalWithComparator looks for a in the array by comparing it with every element.
+func (op inOp) evalWithComparator(a document.Value, arr document.Array) (document.Value, error) {
+	var found bool
+	err := arr.Iterate(func(i int, v document.Value) error {
+		ok, err := a.IsEqual(v)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			found = true
+			return errStop
+		}
+
+		return nil
+	})
+	if err != nil && err != errStop {
+		return nullLitteral, err
+	}
+
+	if found {
 		return trueLitteral, nil
 	}
 	return falseLitteral, nil
 }
 
+func (op inOp) evalTuple(ctx EvalStack) (document.Value, error) {
+	a, err := op.a.Eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	var candidates []document.Value
+	if p, ok := op.b.(Parentheses); ok && isTuple(p.E) {
+		v, err := p.E.Eval(ctx)
+		if err != nil {
+			return nullLitteral, err
+		}
+		candidates = []document.Value{v}
+	} else {
+		b, err := evalList(ctx, op.b, true)
+		if err != nil {
+			return nullLitteral, err
+		}
+		if b.Type == document.NullValue {
+			return nullLitteral, nil
+		}
+
+		candidates, err = tupleValues(b)
+		if err != nil {
+			return nullLitteral, err
+		}
+	}
+
+	eq := cmpOp{&simpleOperator{Tok: scanner.EQ}}
+
+	for _, c := range candidates {
+		if c.Type == document.NullValue {
+			continue
+		}
+
+		v, err := eq.compareTuples(ctx.strictTypes(), a, c)
+		if err != nil {
+			return nullLitteral, err
+		}
+		if v == trueLitteral {
+			return trueLitteral, nil
+		}
+	}
+
+	return falseLitteral, nil
+}
+
 func (op inOp) IterateIndex(idx *database.Index, tb *database.Table, v document.Value, fn func(d document.Document) error) error {
 	if v.Type != document.ArrayValue {
 		return errors.New("IN operator takes an array")
@@ -544,6 +935,13 @@ func NotIn(a, b Expr) Expr {
 	return &notInOp{inOp{&simpleOperator{a, b, scanner.IN}}}
 }
 
+// IsNotInOperator returns true if e is the NOT IN operator.
+// NOT IN inherits the methods reading indexes of IN, which must not be used.
+func IsNotInOperator(e Expr) bool {
+	_, ok := e.(*notInOp)
+	return ok
+}
+
 func (op notInOp) Eval(ctx EvalStack) (document.Value, error) {
 	return invertBoolResult(op.inOp.Eval)(ctx)
 }
@@ -1,12 +1,16 @@
 package expr_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/parser"
 	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/genjidb/genji/sql/scanner"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,6 +63,12 @@ func TestComparisonINExpr(t *testing.T) {
 		{"[1, 2] IN 1", document.NewBoolValue(false), false},
 		{"1 IN NULL", nullLitteral, false},
 		{"NULL IN [1, 2, NULL]", nullLitteral, false},
+		{"1 IN c", document.NewBoolValue(true), false},
+		{"2 IN c", document.NewBoolValue(false), false},
+		{"[1, 2] IN c", document.NewBoolValue(true), false},
+		{"2 IN b.`foo bar`", document.NewBoolValue(true), false},
+		{"1 IN a", document.NewBoolValue(false), false},
+		{"1 IN notFound", nullLitteral, false},
 	}
 
 	for _, test := range tests {
@@ -68,6 +78,38 @@ func TestComparisonINExpr(t *testing.T) {
 	}
 }
 
+func TestComparisonINParam(t *testing.T) {
+	tests := []struct {
+		expr  string
+		param interface{}
+		res   document.Value
+		fails bool
+	}{
+		{"1 IN ?", []int{1, 2, 3}, document.NewBoolValue(true), false},
+		{"4 IN ?", []int{1, 2, 3}, document.NewBoolValue(false), false},
+		{"4 NOT IN ?", []int{1, 2, 3}, document.NewBoolValue(true), false},
+		{"'a' IN ?", []string{"a", "b"}, document.NewBoolValue(true), false},
+		{"1 IN ?", []int{}, document.NewBoolValue(false), false},
+		{"1 IN ?", nil, nullLitteral, false},
+		{"1 IN ?", 1, nullLitteral, true},
+		{"1 NOT IN ?", "a", nullLitteral, true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s/%v", test.expr, test.param), func(t *testing.T) {
+			testExpr(t, test.expr, expr.EvalStack{Params: []expr.Param{{Value: test.param}}}, test.res, test.fails)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		e, err := parser.ParseExprString("1 IN $ages")
+		require.NoError(t, err)
+
+		_, err = e.Eval(expr.EvalStack{Params: []expr.Param{{Name: "ages", Value: 1}}})
+		require.EqualError(t, err, "IN operator takes an array, got integer for parameter $ages")
+	})
+}
+
 func TestComparisonNOTINExpr(t *testing.T) {
 	tests := []struct {
 		expr  string
@@ -236,3 +278,207 @@ func TestIndexedComparisonExpr(t *testing.T) {
 		})
 	}
 }
+
+func TestComparisonQuantifiedExpr(t *testing.T) {
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"1 < ANY ([0, 2])", document.NewBoolValue(true), false},
+		{"1 < ANY ([0, 1])", document.NewBoolValue(false), false},
+		{"1 < ALL ([2, 3])", document.NewBoolValue(true), false},
+		{"1 < ALL ([0, 2])", document.NewBoolValue(false), false},
+		{"1 = ANY (b.`foo bar`)", document.NewBoolValue(true), false},
+		{"1 <= ALL (b.`foo bar`)", document.NewBoolValue(true), false},
+		{"2 != ALL (b.`foo bar`)", document.NewBoolValue(false), false},
+		{"1 = ANY ([])", document.NewBoolValue(false), false},
+		{"1 = ALL ([])", document.NewBoolValue(true), false},
+		{"NULL = ANY ([])", document.NewBoolValue(false), false},
+		{"NULL = ALL ([])", document.NewBoolValue(true), false},
+		{"1 = ANY ([NULL, 1])", document.NewBoolValue(true), false},
+		{"1 = ANY ([NULL, 2])", nullLitteral, false},
+		{"1 = ALL ([NULL, 2])", document.NewBoolValue(false), false},
+		{"1 = ALL ([NULL, 1])", nullLitteral, false},
+		{"NULL = ANY ([1])", nullLitteral, false},
+		{"1 = ANY (NULL)", nullLitteral, false},
+		{"1 = ANY (notFound)", nullLitteral, false},
+		{"1 = ANY (a)", document.NewBoolValue(false), false},
+		{"1 + 1 > ANY ([1]) AND a = 1", document.NewBoolValue(true), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, stackWithDoc, test.res, test.fails)
+		})
+	}
+}
+
+func TestComparisonQuantifiedIsEqual(t *testing.T) {
+	a := expr.Path{document.PathFragment{FieldName: "a"}}
+	b := expr.Path{document.PathFragment{FieldName: "b"}}
+
+	tests := []struct {
+		x, y  expr.Expr
+		equal bool
+	}{
+		{expr.Any(scanner.LT, a, b), expr.Any(scanner.LT, a, b), true},
+		{expr.All(scanner.LT, a, b), expr.All(scanner.LT, a, b), true},
+		{expr.Any(scanner.LT, a, b), expr.All(scanner.LT, a, b), false},
+		{expr.Any(scanner.LT, a, b), expr.Any(scanner.GT, a, b), false},
+		{expr.Any(scanner.LT, a, b), expr.Any(scanner.LT, b, a), false},
+		{expr.Any(scanner.LT, a, b), expr.Lt(a, b), false},
+		{expr.Lt(a, b), expr.Any(scanner.LT, a, b), false},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.equal, expr.Equal(test.x, test.y), "%v, %v", test.x, test.y)
+	}
+}
+
+func TestComparisonTupleExpr(t *testing.T) {
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"(1, 2) IN ((1, 2), (3, 4))", document.NewBoolValue(true), false},
+		{"(1, 2) IN ((3, 4))", document.NewBoolValue(false), false},
+		{"(3, 4) IN ((3, 4))", document.NewBoolValue(true), false},
+		{"(1, 2) NOT IN ((3, 4))", document.NewBoolValue(true), false},
+		{"(a, 2) IN ((1, 2))", document.NewBoolValue(true), false},
+		{"(1, 2) IN ((1, NULL), (3, 4))", document.NewBoolValue(false), false},
+		{"(1, 2) IN ((1, NULL), (1, 2))", document.NewBoolValue(true), false},
+		{"(1, 2) IN ((1, 2, 3), (1, 2))", nullLitteral, true},
+		{"(1, 2) IN (1, 2)", nullLitteral, true},
+		{"(a, 2) = (1, 2)", document.NewBoolValue(true), false},
+		{"(a, 2) = (1, 3)", document.NewBoolValue(false), false},
+		{"(a, NULL) = (2, 3)", document.NewBoolValue(false), false},
+		{"(a, NULL) = (1, 3)", nullLitteral, false},
+		{"(1, NULL) = (1, 2)", nullLitteral, false},
+		{"(a, NULL) != (1, 3)", nullLitteral, false},
+		{"(a, NULL) != (2, 3)", document.NewBoolValue(true), false},
+		{"(1, 2) != (1, 2)", document.NewBoolValue(false), false},
+		{"(1, 2) = [1, 2]", document.NewBoolValue(true), false},
+		{"(1, 2) = (1, 2, 3)", nullLitteral, true},
+		{"(1, 2) = 1", nullLitteral, true},
+		{"(1, 2) < (1, 3)", document.NewBoolValue(true), false},
+		{"(1, 2) < (1, 2)", document.NewBoolValue(false), false},
+		{"(1, 2) <= (1, 2)", document.NewBoolValue(true), false},
+		{"(2, 1) > (1, 5)", document.NewBoolValue(true), false},
+		{"(1, NULL) < (2, 0)", document.NewBoolValue(true), false},
+		{"(NULL, 1) < (2, 0)", nullLitteral, false},
+		{"(1, NULL) <= (1, 0)", nullLitteral, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, stackWithDoc, test.res, test.fails)
+		})
+	}
+
+	t.Run("Same as AND and OR", func(t *testing.T) {
+		pairs := [][2]string{
+			{"(a, b) = (1, 2)", "a = 1 AND b = 2"},
+			{"(a, b) != (1, 2)", "a != 1 OR b != 2"},
+			{"(a, b) < (1, 2)", "a < 1 OR (a = 1 AND b < 2)"},
+			{"(a, b) >= (1, 2)", "a > 1 OR (a = 1 AND b >= 2)"},
+			{"(a, NULL) = (1, 2)", "a = 1 AND NULL = 2"},
+			{"(a, NULL) = (2, 2)", "a = 2 AND NULL = 2"},
+			{"(a, NULL) != (1, 2)", "a != 1 OR NULL != 2"},
+			{"(a, NULL) < (1, 2)", "a < 1 OR (a = 1 AND NULL < 2)"},
+		}
+
+		for _, pair := range pairs {
+			tuple, _, err := parser.NewParser(strings.NewReader(pair[0])).ParseExpr()
+			require.NoError(t, err)
+			equivalent, _, err := parser.NewParser(strings.NewReader(pair[1])).ParseExpr()
+			require.NoError(t, err)
+
+			for _, d := range []string{`{"a": 1, "b": 2}`, `{"a": 1, "b": 3}`, `{"a": 0, "b": 3}`, `{"a": 2, "b": 2}`, `{"a": 1}`, `{"a": 2}`, `{"b": 2}`, `{}`} {
+				stack := expr.EvalStack{Document: document.NewFromJSON([]byte(d))}
+
+				// AND and OR evaluate NULL operands as false,
+				// the tuples must select the same documents.
+				v, err := equivalent.Eval(stack)
+				require.NoError(t, err)
+				want, err := document.IsTruthy(v)
+				require.NoError(t, err)
+				v, err = tuple.Eval(stack)
+				require.NoError(t, err)
+				got, err := document.IsTruthy(v)
+				require.NoError(t, err)
+				require.Equal(t, want, got, "%s with %s", pair[0], d)
+			}
+		}
+	})
+}
+
+func TestNormalise(t *testing.T) {
+	tests := []struct {
+		expr, expected string
+	}{
+		{"1 = age", "age = 1"},
+		{"10 > price", "price < 10"},
+		{"10 >= price", "price <= 10"},
+		{"10 < price", "price > 10"},
+		{"10 <= price", "price >= 10"},
+		{"'foo' != a.b", "a.b != 'foo'"},
+		{"? = a", "a = ?"},
+		{"a = b", "a = b"},
+		{"1 = 1", "1 = 1"},
+		{"a > 1", "a > 1"},
+		{"1 IN a", "1 IN a"},
+		{"1 + a > 2", "1 + a > 2"},
+		{"1 < a AND (2 = b OR c = 3)", "a > 1 AND (b = 2 OR c = 3)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			e, _, err := parser.NewParser(strings.NewReader(test.expr)).ParseExpr()
+			require.NoError(t, err)
+			want, _, err := parser.NewParser(strings.NewReader(test.expected)).ParseExpr()
+			require.NoError(t, err)
+
+			require.Equal(t, want, expr.Normalise(e))
+		})
+	}
+}
+
+func TestComparisonRegisteredComparator(t *testing.T) {
+	// values of this type are compared by length
+	const lengthType document.ValueType = 0x11
+	document.RegisterComparator(lengthType, func(a, b document.Value) int {
+		return len(a.V.(string)) - len(b.V.(string))
+	})
+	defer document.RegisterComparator(lengthType, nil)
+
+	env := document.NewFieldBuffer().
+		Add("a", document.Value{Type: lengthType, V: "zz"}).
+		Add("b", document.Value{Type: lengthType, V: "aaa"}).
+		Add("c", document.Value{Type: lengthType, V: "yy"})
+
+	tests := []struct {
+		expr string
+		res  bool
+	}{
+		{"a < b", true},
+		{"a > b", false},
+		{"a = c", true},
+		{"a != c", false},
+		{"b >= c", true},
+		{"a IN [b, c]", true},
+		{"a NOT IN [b, c]", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			e, _, err := parser.NewParser(strings.NewReader(test.expr)).ParseExpr()
+			require.NoError(t, err)
+
+			v, err := expr.Eval(e, env, nil)
+			require.NoError(t, err)
+			require.Equal(t, document.NewBoolValue(test.res), v)
+		})
+	}
+}
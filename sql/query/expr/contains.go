@@ -0,0 +1,122 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+type containsOp struct {
+	*simpleOperator
+}
+
+// Contains creates an expression that evaluates to the result of a @> b.
+func Contains(a, b Expr) Expr {
+	return &containsOp{&simpleOperator{a, b, scanner.CONTAINS}}
+}
+
+// Eval returns true if a contains b:
+// a document contains another one if every field of the other is present in the document
+// with a value that contains the value of the field, and an array contains another one if every
+// value of the other is contained by one of the values of the array. Other values contain
+// the values they are equal to.
+// It evaluates to NULL if one of the operands is NULL or if they are neither
+// documents nor arrays, unless strict typing is enabled on the database, in which case it returns an error.
+func (op containsOp) Eval(ctx EvalStack) (document.Value, error) {
+	a, b, err := op.simpleOperator.eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	if !isContainer(a) || !isContainer(b) {
+		if ctx.strictTypes() && a.Type != document.NullValue && b.Type != document.NullValue {
+			return nullLitteral, errors.New("@> operator takes documents or arrays")
+		}
+		return nullLitteral, nil
+	}
+
+	ok, err := contains(a, b)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return document.NewBoolValue(ok), nil
+}
+
+func (op containsOp) String() string {
+	return fmt.Sprintf("%v @> %v", op.a, op.b)
+}
+
+func isContainer(v document.Value) bool {
+	return v.Type == document.DocumentValue || v.Type == document.ArrayValue
+}
+
+// contains reports whether a structurally contains b.
+func contains(a, b document.Value) (bool, error) {
+	if a.Type != b.Type && (isContainer(a) || isContainer(b)) {
+		return false, nil
+	}
+
+	switch a.Type {
+	case document.DocumentValue:
+		da, db := a.V.(document.Document), b.V.(document.Document)
+		found := true
+		err := db.Iterate(func(field string, vb document.Value) error {
+			va, err := da.GetByField(field)
+			if err == document.ErrFieldNotFound {
+				found = false
+				return errStop
+			}
+			if err != nil {
+				return err
+			}
+
+			found, err = contains(va, vb)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return errStop
+			}
+			return nil
+		})
+		if err != nil && err != errStop {
+			return false, err
+		}
+
+		return found, nil
+	case document.ArrayValue:
+		aa, ab := a.V.(document.Array), b.V.(document.Array)
+		found := true
+		err := ab.Iterate(func(_ int, vb document.Value) error {
+			found = false
+			err := aa.Iterate(func(_ int, va document.Value) error {
+				var err error
+				found, err = contains(va, vb)
+				if err != nil {
+					return err
+				}
+				if found {
+					return errStop
+				}
+				return nil
+			})
+			if err != nil && err != errStop {
+				return err
+			}
+			if !found {
+				return errStop
+			}
+			return nil
+		})
+		if err != nil && err != errStop {
+			return false, err
+		}
+
+		return found, nil
+	}
+
+	return a.IsEqual(b)
+}
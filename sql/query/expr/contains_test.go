@@ -0,0 +1,50 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+)
+
+func TestContainsExpr(t *testing.T) {
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"{a: 1, b: 2} @> {a: 1}", document.NewBoolValue(true), false},
+		{"{a: 1, b: 2} @> {}", document.NewBoolValue(true), false},
+		{"{a: 1} @> {a: 1, b: 2}", document.NewBoolValue(false), false},
+		{"{a: 1} @> {a: 2}", document.NewBoolValue(false), false},
+		{"{a: 1, b: {c: 2, d: 3}} @> {b: {c: 2}}", document.NewBoolValue(true), false},
+		{"{a: 1, b: {c: 2, d: 3}} @> {b: {c: 3}}", document.NewBoolValue(false), false},
+		{"{a: {b: [1, 2, 3]}} @> {a: {b: [3, 1]}}", document.NewBoolValue(true), false},
+		{"{a: 1} @> {a: [1]}", document.NewBoolValue(false), false},
+		{"{a: [1]} @> {a: 1}", document.NewBoolValue(false), false},
+		{"[1, 2, 3] @> [3, 1]", document.NewBoolValue(true), false},
+		{"[1, 2, 3] @> []", document.NewBoolValue(true), false},
+		{"[1, 2] @> [1, 4]", document.NewBoolValue(false), false},
+		{"[1, 2.0] @> [2]", document.NewBoolValue(true), false},
+		{"[1, [2, 3]] @> [[3]]", document.NewBoolValue(true), false},
+		{"[1, [2, 3]] @> [3]", document.NewBoolValue(false), false},
+		{"[{a: 1, b: 2}, {c: 3}] @> [{a: 1}]", document.NewBoolValue(true), false},
+		{"[{a: 1, b: 2}, {c: 3}] @> [{a: 1, c: 3}]", document.NewBoolValue(false), false},
+		{"{a: 1} @> [1]", document.NewBoolValue(false), false},
+		{"[1] @> {a: 1}", document.NewBoolValue(false), false},
+		{"b @> {`foo bar`: [2]}", document.NewBoolValue(true), false},
+		{"c @> [{foo: 'bar'}, [2]]", document.NewBoolValue(true), false},
+		{"c @> [{foo: 'baz'}]", document.NewBoolValue(false), false},
+		{"{a: 1} @> NULL", nullLitteral, false},
+		{"NULL @> [1]", nullLitteral, false},
+		{"notFound @> [1]", nullLitteral, false},
+		{"[1] @> 1", nullLitteral, false},
+		{"1 @> 1", nullLitteral, false},
+		{"'foo' @> 'f'", nullLitteral, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, stackWithDoc, test.res, test.fails)
+		})
+	}
+}
@@ -47,6 +47,26 @@ type EvalStack struct {
 	Info     *database.TableInfo
 }
 
+// strictTypes reports whether comparing values of incompatible types must fail.
+func (s EvalStack) strictTypes() bool {
+	return s.Tx != nil && s.Tx.DB().StrictTypes
+}
+
+// caseInsensitiveFields reports whether paths must match field names case-insensitively.
+func (s EvalStack) caseInsensitiveFields() bool {
+	return s.Tx != nil && s.Tx.DB().CaseInsensitiveFields
+}
+
+// Eval evaluates e using env to resolve paths and params to resolve
+// named and positional parameters. env and params can be nil.
+// Expressions requiring a transaction or a table, like pk(), return an error.
+func Eval(e Expr, env document.Document, params []Param) (document.Value, error) {
+	return e.Eval(EvalStack{
+		Document: env,
+		Params:   params,
+	})
+}
+
 type simpleOperator struct {
 	a, b Expr
 	Tok  scanner.Token
@@ -102,6 +122,11 @@ func (op *simpleOperator) IsEqual(other Expr) bool {
 		return false
 	}
 
+	// quantified comparisons share the token of the comparison they quantify
+	if _, ok := other.(quantifiedOp); ok {
+		return false
+	}
+
 	return op.Tok == oop.Token() &&
 		Equal(op.a, oop.LeftHand()) &&
 		Equal(op.b, oop.RightHand())
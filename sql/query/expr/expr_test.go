@@ -59,6 +59,7 @@ func TestString(t *testing.T) {
 		`foo.bar[1]`,
 		`"hello"`,
 		`[1, 2, "foo"]`,
+		`(1, foo.bar)`,
 		`{"a": "foo", "b": 10}`,
 		"pk()",
 		"CAST(10 AS integer)",
@@ -67,7 +68,7 @@ func TestString(t *testing.T) {
 	var operators = []string{
 		"=", ">", ">=", "<", "<=",
 		"+", "-", "*", "/", "%", "&", "|", "^",
-		"AND", "OR",
+		"AND", "OR", "@>", "REGEXP", "NOT REGEXP",
 	}
 
 	testFn := func(s string, want string) {
@@ -85,3 +86,218 @@ func TestString(t *testing.T) {
 		testFn(want, want)
 	}
 }
+
+func TestLiteralValueGoString(t *testing.T) {
+	tests := []struct {
+		value    expr.LiteralValue
+		expected string
+	}{
+		{expr.NullValue(), "null"},
+		{expr.BoolValue(false), "bool(false)"},
+		{expr.IntegerValue(10), "int64(10)"},
+		{expr.DoubleValue(10.5), "float64(10.5)"},
+		{expr.TextValue("hi"), `text("hi")`},
+		{expr.BlobValue([]byte{1, 255}), "blob(0x01ff)"},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, fmt.Sprintf("%#v", test.value))
+	}
+
+	// values nested in expressions are rendered the same way
+	require.Contains(t, fmt.Sprintf("%#v", expr.LiteralExprList{expr.IntegerValue(1), expr.TextValue("a")}), `int64(1), text("a")`)
+}
+
+func TestEval(t *testing.T) {
+	env := document.NewFromJSON([]byte(`{"a": 1, "b": 2}`))
+
+	tests := []struct {
+		expr   string
+		params []expr.Param
+		res    document.Value
+		fails  bool
+	}{
+		{"a + 1 > b", nil, document.NewBoolValue(false), false},
+		{"a + 1 >= b", nil, document.NewBoolValue(true), false},
+		{"a + ? > b", []expr.Param{{Value: 2}}, document.NewBoolValue(true), false},
+		{"a + $x > b", []expr.Param{{Name: "x", Value: 0.5}}, document.NewBoolValue(false), false},
+		{"a + $x > b", nil, nullLitteral, true},
+		{"c + 1 > b", nil, nullLitteral, false},
+		{"pk()", nil, nullLitteral, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			e, _, err := parser.NewParser(strings.NewReader(test.expr)).ParseExpr()
+			require.NoError(t, err)
+
+			res, err := expr.Eval(e, env, test.params)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.res, res)
+		})
+	}
+}
+
+func TestSpread(t *testing.T) {
+	env := document.NewFromJSON([]byte(`{"a": [1, 2, 3], "b": [2, 3, 4], "c": 1, "d": {"a": 1}, "m": {"b": 1, "c": 3}}`))
+
+	tests := []struct {
+		expr  string
+		res   string
+		fails bool
+	}{
+		{"[...a, 4]", `[1, 2, 3, 4]`, false},
+		{"[1, ...b, 5]", `[1, 2, 3, 4, 5]`, false},
+		{"[...a, ...b]", `[1, 2, 3, 2, 3, 4]`, false},
+		{"[...[], ...[[1]]]", `[[1]]`, false},
+		{"{tags: [...a, 'new']}", `{"tags": [1, 2, 3, "new"]}`, false},
+		{"[...c]", ``, true},
+		{"[...d]", ``, true},
+		{"[...e]", ``, true},
+		{"{...m, b: 2}", `{"b": 2, "c": 3}`, false},
+		{"{b: 2, ...m}", `{"b": 1, "c": 3}`, false},
+		{"{a: 1, ...d, ...m, c: 4}", `{"a": 1, "b": 1, "c": 4}`, false},
+		{"{...{}}", `{}`, false},
+		{"{...{}, a: 1}", `{"a": 1}`, false},
+		{"{a: [...a], ...{b: 1}}", `{"a": [1, 2, 3], "b": 1}`, false},
+		{"{...c}", ``, true},
+		{"{...a}", ``, true},
+		{"{...e}", ``, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			e, _, err := parser.NewParser(strings.NewReader(test.expr)).ParseExpr()
+			require.NoError(t, err)
+
+			res, err := expr.Eval(e, env, nil)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := res.MarshalJSON()
+			require.NoError(t, err)
+			require.JSONEq(t, test.res, string(data))
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	exprs := []string{
+		"a + 1 > 2 AND (b IN [1, {c: d * 2}] OR e LIKE 'f%')",
+		"CAST(a - 1 AS TEXT) = ALL [COUNT(b), MIN(c), MAX(d), SUM(e), AVG(f)]",
+		"a.b[0] IS NOT NULL",
+		"pk() != $a | $b",
+		"(a + 1, b) IN ((1, 2), (3, c))",
+	}
+
+	for _, s := range exprs {
+		t.Run(s, func(t *testing.T) {
+			e, _, err := parser.NewParser(strings.NewReader(s)).ParseExpr()
+			require.NoError(t, err)
+			want := fmt.Sprintf("%v", e)
+
+			c := expr.Clone(e)
+			require.Equal(t, e, c)
+			require.Equal(t, want, fmt.Sprintf("%v", c))
+
+			// modify the deepest operator of the clone.
+			op := c.(expr.Operator)
+			for {
+				l, ok := op.LeftHand().(expr.Operator)
+				if !ok {
+					break
+				}
+				op = l
+			}
+			op.SetLeftHandExpr(expr.IntegerValue(0))
+			require.Equal(t, want, fmt.Sprintf("%v", e))
+			require.NotEqual(t, e, c)
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	parse := func(t *testing.T, s string) expr.Expr {
+		e, _, err := parser.NewParser(strings.NewReader(s)).ParseExpr()
+		require.NoError(t, err)
+		return e
+	}
+
+	t.Run("Collect paths", func(t *testing.T) {
+		e := parse(t, "a + 1 > 2 AND (b.c IN [1, {d: e * 2}] OR CAST(f AS TEXT) LIKE 'g%') AND COUNT(h) = 0")
+
+		var paths []string
+		expr.Walk(e, func(e expr.Expr) (expr.Expr, bool) {
+			if p, ok := e.(expr.Path); ok {
+				paths = append(paths, p.String())
+			}
+			return e, true
+		})
+		require.Equal(t, []string{"a", "b.c", "e", "f", "h"}, paths)
+	})
+
+	t.Run("Builtin functions", func(t *testing.T) {
+		for name, fn := range expr.BuiltinFunctions() {
+			// find the first number of arguments accepted by the function
+			var e expr.Expr
+			var args []expr.Expr
+			for n := 0; n <= 3 && e == nil; n++ {
+				args = make([]expr.Expr, n)
+				for i := range args {
+					args[i] = expr.Path{document.PathFragment{FieldName: fmt.Sprintf("arg%d", i)}}
+				}
+				e, _ = fn(args...)
+			}
+			require.NotNil(t, e, name)
+
+			visited := []expr.Expr{}
+			got := expr.Walk(e, func(e expr.Expr) (expr.Expr, bool) {
+				if p, ok := e.(expr.Path); ok {
+					visited = append(visited, p)
+					return append(expr.Path{document.PathFragment{FieldName: "new"}}, p...), true
+				}
+				return e, true
+			})
+			require.Equal(t, args, visited, name)
+
+			for _, arg := range args {
+				require.Contains(t, fmt.Sprintf("%v", got), "new."+arg.(expr.Path).String(), name)
+			}
+		}
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		e := parse(t, "a = 0 OR b IN [0, 1, {c: 0}]")
+		want := e.(fmt.Stringer).String()
+
+		got := expr.Walk(e, func(e expr.Expr) (expr.Expr, bool) {
+			if v, ok := e.(expr.LiteralValue); ok && document.Value(v).Type == document.IntegerValue && document.Value(v).V.(int64) == 0 {
+				return expr.NullValue(), true
+			}
+			return e, true
+		})
+		require.Equal(t, parse(t, "a = NULL OR b IN [NULL, 1, {c: NULL}]"), got)
+		// the original expression is left untouched.
+		require.Equal(t, want, e.(fmt.Stringer).String())
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		e := parse(t, "(a + 1) * 2 > 3")
+
+		var visited []expr.Expr
+		got := expr.Walk(e, func(e expr.Expr) (expr.Expr, bool) {
+			visited = append(visited, e)
+			_, ok := e.(expr.Operator)
+			return e, !ok
+		})
+		require.Equal(t, []expr.Expr{e}, visited)
+		require.Equal(t, e, got)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/genjidb/genji/document"
@@ -11,7 +12,8 @@ import (
 
 // Functions represents a map of builtin SQL functions.
 type Functions struct {
-	m map[string]func(args ...Expr) (Expr, error)
+	m  map[string]func(args ...Expr) (Expr, error)
+	tm map[string]func(args ...Expr) (TableFunction, error)
 }
 
 // BuiltinFunctions returns default map of builtin functions.
@@ -23,6 +25,50 @@ func BuiltinFunctions() map[string]func(args ...Expr) (Expr, error) {
 			}
 			return new(PKFunc), nil
 		},
+		"typeof": func(args ...Expr) (Expr, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("typeof() takes 1 argument")
+			}
+			return TypeOfFunc{Expr: args[0]}, nil
+		},
+		"field_exists": func(args ...Expr) (Expr, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("field_exists() takes 1 argument")
+			}
+			p, ok := args[0].(Path)
+			if !ok {
+				return nil, fmt.Errorf("field_exists() expects a path, got %v", args[0])
+			}
+			return FieldExistsFunc{Path: p}, nil
+		},
+		"contains_key": func(args ...Expr) (Expr, error) {
+			switch len(args) {
+			case 1:
+				return ContainsKeyExpr{Key: args[0]}, nil
+			case 2:
+				return ContainsKeyExpr{Doc: args[0], Key: args[1]}, nil
+			}
+			return nil, fmt.Errorf("CONTAINS_KEY() takes 1 or 2 arguments")
+		},
+		"json_extract": func(args ...Expr) (Expr, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("json_extract() takes 2 arguments")
+			}
+			// literal paths are validated when the statement is parsed
+			if l, ok := args[1].(LiteralValue); ok && l.Type == document.TextValue {
+				_, err := ParseJSONPath(l.V.(string))
+				if err != nil {
+					return nil, err
+				}
+			}
+			return JSONExtractFunc{Expr: args[0], Path: args[1]}, nil
+		},
+		"split": func(args ...Expr) (Expr, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("SPLIT() takes 2 arguments")
+			}
+			return SplitFunc{Expr: args[0], Sep: args[1]}, nil
+		},
 		"count": func(args ...Expr) (Expr, error) {
 			if len(args) != 1 {
 				return nil, fmt.Errorf("COUNT() takes 1 argument")
@@ -56,9 +102,22 @@ func BuiltinFunctions() map[string]func(args ...Expr) (Expr, error) {
 	}
 }
 
+// BuiltinTableFunctions returns default map of builtin table-valued functions.
+func BuiltinTableFunctions() map[string]func(args ...Expr) (TableFunction, error) {
+	return map[string]func(args ...Expr) (TableFunction, error){
+		"unnest": func(args ...Expr) (TableFunction, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("UNNEST() takes 1 argument")
+			}
+			return &UnnestFunc{Expr: args[0]}, nil
+		},
+	}
+}
+
 func NewFunctions() Functions {
 	return Functions{
-		m: BuiltinFunctions(),
+		m:  BuiltinFunctions(),
+		tm: BuiltinTableFunctions(),
 	}
 }
 
@@ -77,6 +136,85 @@ func (f Functions) GetFunc(name string, args ...Expr) (Expr, error) {
 	return fn(args...)
 }
 
+// AddTableFunc adds a table-valued function to the map.
+func (f Functions) AddTableFunc(name string, fn func(args ...Expr) (TableFunction, error)) {
+	f.tm[name] = fn
+}
+
+// GetTableFunc returns a table-valued function by name.
+func (f Functions) GetTableFunc(name string, args ...Expr) (TableFunction, error) {
+	fn, ok := f.tm[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("no such table function: %q", name)
+	}
+
+	return fn(args...)
+}
+
+// A TableFunction is a function that produces a stream of documents
+// instead of a single value. Unlike scalar functions, table-valued functions
+// can only be used as the source of a query, in the FROM clause.
+// Their arguments are evaluated without a document: within a lateral join or
+// a subquery, the fields of the outer statement they refer to are replaced by parameters.
+type TableFunction interface {
+	// Iterate evaluates the function and calls fn for every document it produces.
+	Iterate(stack EvalStack, fn func(d document.Document) error) error
+	// Args returns the arguments of the function.
+	Args() []Expr
+	// WithArgs returns a copy of the function whose arguments are replaced by args.
+	WithArgs(args ...Expr) TableFunction
+	// Clone returns a deep copy of the function.
+	Clone() TableFunction
+	String() string
+}
+
+// UnnestFunc represents the UNNEST table-valued function.
+// It evaluates its argument, which must be an array, and produces one document
+// per element of the array. Each document contains a single field, named "value",
+// holding the element.
+type UnnestFunc struct {
+	Expr Expr
+}
+
+// Iterate calls fn for every element of the array returned by the expression.
+// A NULL array produces no documents.
+func (u *UnnestFunc) Iterate(stack EvalStack, fn func(d document.Document) error) error {
+	v, err := u.Expr.Eval(stack)
+	if err != nil {
+		return err
+	}
+
+	if v.Type == document.NullValue {
+		return nil
+	}
+	if v.Type != document.ArrayValue {
+		return fmt.Errorf("UNNEST() expects an array, got %s", v.Type)
+	}
+
+	return v.V.(document.Array).Iterate(func(i int, value document.Value) error {
+		return fn(document.NewFieldBuffer().Add("value", value))
+	})
+}
+
+// Args returns the array expression.
+func (u *UnnestFunc) Args() []Expr {
+	return []Expr{u.Expr}
+}
+
+// WithArgs returns a copy of u whose array expression is the only argument.
+func (u *UnnestFunc) WithArgs(args ...Expr) TableFunction {
+	return &UnnestFunc{Expr: args[0]}
+}
+
+// Clone returns a deep copy of u.
+func (u *UnnestFunc) Clone() TableFunction {
+	return &UnnestFunc{Expr: Clone(u.Expr)}
+}
+
+func (u *UnnestFunc) String() string {
+	return fmt.Sprintf("UNNEST(%v)", u.Expr)
+}
+
 // PKFunc represents the pk() function.
 // It returns the primary key of the current document.
 type PKFunc struct{}
@@ -108,20 +246,338 @@ func (k PKFunc) String() string {
 	return "pk()"
 }
 
+// TypeOfFunc represents the typeof() function.
+// It returns the name of the type of the value of its argument.
+type TypeOfFunc struct {
+	Expr Expr
+}
+
+// Eval returns the type of the value of the expression, as a text.
+func (t TypeOfFunc) Eval(ctx EvalStack) (document.Value, error) {
+	v, err := t.Expr.Eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return document.NewTextValue(v.Type.String()), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (t TypeOfFunc) IsEqual(other Expr) bool {
+	o, ok := other.(TypeOfFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(t.Expr, o.Expr)
+}
+
+func (t TypeOfFunc) String() string {
+	return fmt.Sprintf("typeof(%v)", t.Expr)
+}
+
+// FieldExistsFunc represents the field_exists() function.
+// It returns true if its path exists in the current document, even if its value is NULL,
+// and false otherwise. Unlike IS NULL, it tells apart absent fields from explicit nulls.
+type FieldExistsFunc struct {
+	Path Path
+}
+
+// Eval returns whether the path exists in the current document.
+func (f FieldExistsFunc) Eval(ctx EvalStack) (document.Value, error) {
+	if ctx.Document == nil {
+		return falseLitteral, document.ErrFieldNotFound
+	}
+
+	p := document.Path(f.Path)
+	if ctx.caseInsensitiveFields() {
+		p = p.Fold(ctx.Document)
+	}
+
+	_, err := p.GetValue(ctx.Document)
+	if err == document.ErrFieldNotFound || err == document.ErrValueNotFound {
+		return falseLitteral, nil
+	}
+	if err != nil {
+		return falseLitteral, err
+	}
+
+	return trueLitteral, nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (f FieldExistsFunc) IsEqual(other Expr) bool {
+	o, ok := other.(FieldExistsFunc)
+	if !ok {
+		return false
+	}
+
+	return f.Path.IsEqual(o.Path)
+}
+
+func (f FieldExistsFunc) String() string {
+	return fmt.Sprintf("field_exists(%v)", f.Path)
+}
+
+// ContainsKeyExpr represents the CONTAINS_KEY() function.
+// It returns true if the value of Doc is a document that has a field named
+// after the value of Key, whatever the value of that field, even NULL.
+// If Doc is nil, the field is looked up in the current document.
+// It returns false if the value of Doc is not a document, and NULL if Key is NULL.
+type ContainsKeyExpr struct {
+	Doc Expr
+	Key Expr
+}
+
+// Eval returns whether the document has the key.
+func (c ContainsKeyExpr) Eval(ctx EvalStack) (document.Value, error) {
+	k, err := c.Key.Eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+	if k.Type == document.NullValue {
+		return nullLitteral, nil
+	}
+	if k.Type != document.TextValue {
+		return nullLitteral, fmt.Errorf("CONTAINS_KEY() expects a text key, got %s", k.Type)
+	}
+
+	d := ctx.Document
+	if c.Doc != nil {
+		v, err := c.Doc.Eval(ctx)
+		if err != nil {
+			return nullLitteral, err
+		}
+		if v.Type != document.DocumentValue {
+			return falseLitteral, nil
+		}
+		d = v.V.(document.Document)
+	}
+	if d == nil {
+		return falseLitteral, nil
+	}
+
+	p := document.Path{document.PathFragment{FieldName: k.V.(string)}}
+	if ctx.caseInsensitiveFields() {
+		p = p.Fold(d)
+	}
+
+	_, err = d.GetByField(p[0].FieldName)
+	if err == document.ErrFieldNotFound {
+		return falseLitteral, nil
+	}
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return trueLitteral, nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (c ContainsKeyExpr) IsEqual(other Expr) bool {
+	o, ok := other.(ContainsKeyExpr)
+	if !ok {
+		return false
+	}
+
+	return Equal(c.Doc, o.Doc) && Equal(c.Key, o.Key)
+}
+
+func (c ContainsKeyExpr) String() string {
+	if c.Doc == nil {
+		return fmt.Sprintf("CONTAINS_KEY(%v)", c.Key)
+	}
+
+	return fmt.Sprintf("CONTAINS_KEY(%v, %v)", c.Doc, c.Key)
+}
+
+// JSONExtractFunc represents the json_extract() function.
+// It returns the value located at a JSONPath-like path, given as a text, within the value
+// of its first argument. Missing paths evaluate to NULL.
+type JSONExtractFunc struct {
+	Expr Expr
+	Path Expr
+}
+
+// Eval extracts the value at the path from the value of the expression.
+func (j JSONExtractFunc) Eval(ctx EvalStack) (document.Value, error) {
+	pv, err := j.Path.Eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+	if pv.Type != document.TextValue {
+		return nullLitteral, fmt.Errorf("json_extract() expects a text path, got %s", pv.Type)
+	}
+
+	p, err := ParseJSONPath(pv.V.(string))
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	v, err := j.Expr.Eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	if len(p) == 0 {
+		return v, nil
+	}
+
+	return resolvePath(p, v, ctx.caseInsensitiveFields())
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (j JSONExtractFunc) IsEqual(other Expr) bool {
+	o, ok := other.(JSONExtractFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(j.Expr, o.Expr) && Equal(j.Path, o.Path)
+}
+
+func (j JSONExtractFunc) String() string {
+	return fmt.Sprintf("json_extract(%v, %v)", j.Expr, j.Path)
+}
+
+// SplitFunc represents the SPLIT() function.
+// It splits a text around each occurrence of a separator and returns the substrings as an array of texts.
+// An empty separator splits the text after each UTF-8 character, and an empty text
+// is split into an empty array. If any of the arguments is NULL, it returns NULL.
+type SplitFunc struct {
+	Expr Expr
+	Sep  Expr
+}
+
+// Eval splits the text returned by the expression.
+func (s SplitFunc) Eval(ctx EvalStack) (document.Value, error) {
+	v, err := s.Expr.Eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	sep, err := s.Sep.Eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	if v.Type == document.NullValue || sep.Type == document.NullValue {
+		return nullLitteral, nil
+	}
+	if v.Type != document.TextValue || sep.Type != document.TextValue {
+		return nullLitteral, fmt.Errorf("SPLIT() expects text arguments, got %s and %s", v.Type, sep.Type)
+	}
+
+	vb := document.NewValueBuffer()
+	if text := v.V.(string); text != "" {
+		for _, part := range strings.Split(text, sep.V.(string)) {
+			vb = vb.Append(document.NewTextValue(part))
+		}
+	}
+
+	return document.NewArrayValue(vb), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (s SplitFunc) IsEqual(other Expr) bool {
+	o, ok := other.(SplitFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(s.Expr, o.Expr) && Equal(s.Sep, o.Sep)
+}
+
+func (s SplitFunc) String() string {
+	return fmt.Sprintf("SPLIT(%v, %v)", s.Expr, s.Sep)
+}
+
+// ParseJSONPath parses a path like $.a.b[0]["c d"] into a document path.
+// The path must start with $, which denotes the value itself, followed by any number
+// of .field, ["field"] and [index] segments.
+func ParseJSONPath(s string) (document.Path, error) {
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("invalid JSON path %q: must start with $", s)
+	}
+
+	var p document.Path
+	i := 1
+	for i < len(s) {
+		switch s[i] {
+		case '.':
+			j := i + 1
+			for j < len(s) && s[j] != '.' && s[j] != '[' {
+				j++
+			}
+			if j == i+1 {
+				return nil, fmt.Errorf("invalid JSON path %q: empty field name at position %d", s, i)
+			}
+			p = append(p, document.PathFragment{FieldName: s[i+1 : j]})
+			i = j
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid JSON path %q: missing ] at position %d", s, i)
+			}
+			seg := s[i+1 : i+end]
+			i += end + 1
+
+			if len(seg) >= 2 && seg[0] == '"' && seg[len(seg)-1] == '"' {
+				p = append(p, document.PathFragment{FieldName: seg[1 : len(seg)-1]})
+				continue
+			}
+
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: invalid array index %q", s, seg)
+			}
+			p = append(p, document.PathFragment{ArrayIndex: idx})
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q: unexpected %q at position %d", s, s[i], i)
+		}
+	}
+
+	return p, nil
+}
+
 // CastFunc represents the CAST expression.
 type CastFunc struct {
 	Expr   Expr
 	CastAs document.ValueType
+	// BitSize is the width of the integer type to cast to.
+	// If set, the cast fails if the result overflows it.
+	BitSize int
 }
 
-// Eval returns the primary key of the current document.
+// Eval returns the value of the expression converted to the target type.
 func (c CastFunc) Eval(ctx EvalStack) (document.Value, error) {
 	v, err := c.Expr.Eval(ctx)
 	if err != nil {
 		return v, err
 	}
 
-	return v.CastAs(c.CastAs)
+	return c.cast(v)
+}
+
+// cast converts v to the target type.
+func (c CastFunc) cast(v document.Value) (document.Value, error) {
+	v, err := v.CastAs(c.CastAs)
+	if err != nil || c.BitSize == 0 || v.Type != document.IntegerValue {
+		return v, err
+	}
+
+	x := v.V.(int64)
+	min, max := int64(-1)<<(c.BitSize-1), int64(1)<<(c.BitSize-1)-1
+	if x < min || x > max {
+		return nullLitteral, fmt.Errorf("cannot cast %d as %s: out of range", x, c.intTypeName())
+	}
+
+	return v, nil
 }
 
 // IsEqual compares this expression with the other expression and returns
@@ -136,7 +592,7 @@ func (c CastFunc) IsEqual(other Expr) bool {
 		return false
 	}
 
-	if c.CastAs != o.CastAs {
+	if c.CastAs != o.CastAs || c.BitSize != o.BitSize {
 		return false
 	}
 
@@ -148,9 +604,22 @@ func (c CastFunc) IsEqual(other Expr) bool {
 }
 
 func (c CastFunc) String() string {
+	if c.BitSize != 0 {
+		return fmt.Sprintf("CAST(%v AS %s)", c.Expr, c.intTypeName())
+	}
+
 	return fmt.Sprintf("CAST(%v AS %v)", c.Expr, c.CastAs)
 }
 
+// intTypeName returns the name of the integer type of width BitSize.
+func (c CastFunc) intTypeName() string {
+	if c.BitSize == 24 {
+		return "MEDIUMINT"
+	}
+
+	return fmt.Sprintf("INT%d", c.BitSize)
+}
+
 // CountFunc is the COUNT aggregator function. It aggregates documents
 type CountFunc struct {
 	Expr     Expr
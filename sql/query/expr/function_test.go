@@ -1,10 +1,13 @@
 package expr_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/parser"
 	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPkExpr(t *testing.T) {
@@ -25,3 +28,280 @@ func TestPkExpr(t *testing.T) {
 		})
 	}
 }
+
+func TestCastIntegerWidth(t *testing.T) {
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"CAST(127 AS INT8)", document.NewIntegerValue(127), false},
+		{"CAST(-128 AS INT8)", document.NewIntegerValue(-128), false},
+		{"CAST(-1 AS INT8)", document.NewIntegerValue(-1), false},
+		{"CAST(300 AS INT8)", nullLitteral, true},
+		{"CAST(128 AS INT8)", nullLitteral, true},
+		{"CAST(-129 AS INT8)", nullLitteral, true},
+		{"CAST(127 AS TINYINT)", document.NewIntegerValue(127), false},
+		{"CAST(-128 AS TINYINT)", document.NewIntegerValue(-128), false},
+		{"CAST(-1 AS TINYINT)", document.NewIntegerValue(-1), false},
+		{"CAST(128 AS TINYINT)", nullLitteral, true},
+		{"CAST(-129 AS TINYINT)", nullLitteral, true},
+		{"CAST(32767 AS INT16)", document.NewIntegerValue(32767), false},
+		{"CAST(-32768 AS INT16)", document.NewIntegerValue(-32768), false},
+		{"CAST(-1 AS INT16)", document.NewIntegerValue(-1), false},
+		{"CAST(32768 AS INT16)", nullLitteral, true},
+		{"CAST(-32769 AS INT16)", nullLitteral, true},
+		{"CAST(32767 AS SMALLINT)", document.NewIntegerValue(32767), false},
+		{"CAST(-32768 AS SMALLINT)", document.NewIntegerValue(-32768), false},
+		{"CAST(-1 AS SMALLINT)", document.NewIntegerValue(-1), false},
+		{"CAST(32768 AS SMALLINT)", nullLitteral, true},
+		{"CAST(-32769 AS SMALLINT)", nullLitteral, true},
+		{"CAST(8388607 AS MEDIUMINT)", document.NewIntegerValue(8388607), false},
+		{"CAST(-8388608 AS MEDIUMINT)", document.NewIntegerValue(-8388608), false},
+		{"CAST(-1 AS MEDIUMINT)", document.NewIntegerValue(-1), false},
+		{"CAST(8388608 AS MEDIUMINT)", nullLitteral, true},
+		{"CAST(-8388609 AS MEDIUMINT)", nullLitteral, true},
+		{"CAST(2147483647 AS INT32)", document.NewIntegerValue(2147483647), false},
+		{"CAST(-2147483648 AS INT32)", document.NewIntegerValue(-2147483648), false},
+		{"CAST(-1 AS INT32)", document.NewIntegerValue(-1), false},
+		{"CAST(2147483648 AS INT32)", nullLitteral, true},
+		{"CAST(-2147483649 AS INT32)", nullLitteral, true},
+		{"CAST(9223372036854775807 AS INT64)", document.NewIntegerValue(9223372036854775807), false},
+		{"CAST(-9223372036854775808 AS INT64)", document.NewIntegerValue(-9223372036854775808), false},
+		{"CAST(-1 AS INT64)", document.NewIntegerValue(-1), false},
+		{"CAST('40000' AS INT16)", nullLitteral, true},
+		{"CAST(10.5 AS INT16)", document.NewIntegerValue(10), false},
+		{"CAST(300 AS int32)", document.NewIntegerValue(300), false},
+		{"CAST(40000 AS INT2)", document.NewIntegerValue(40000), false},
+		{"CAST(300 AS INTEGER)", document.NewIntegerValue(300), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, expr.EvalStack{}, test.res, test.fails)
+		})
+	}
+}
+
+func TestTypeOf(t *testing.T) {
+	tests := []struct {
+		expr string
+		res  document.Value
+	}{
+		{"typeof(a)", document.NewTextValue("integer")},
+		{"typeof(c[1])", document.NewTextValue("document")},
+		{"typeof(b.`foo bar`)", document.NewTextValue("array")},
+		{"typeof(z)", document.NewTextValue("null")},
+		{"typeof('a' + 1)", document.NewTextValue("null")},
+		{"typeof(1.5)", document.NewTextValue("double")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, stackWithDoc, test.res, false)
+		})
+	}
+}
+
+func TestFieldExists(t *testing.T) {
+	d := document.NewFromJSON([]byte(`{
+		"a": null,
+		"b": {"c": null, "d": [1, null]},
+		"e": 0
+	}`))
+
+	tests := []struct {
+		expr string
+		res  document.Value
+	}{
+		{"field_exists(a)", document.NewBoolValue(true)},
+		{"field_exists(e)", document.NewBoolValue(true)},
+		{"field_exists(z)", document.NewBoolValue(false)},
+		{"field_exists(b.c)", document.NewBoolValue(true)},
+		{"field_exists(b.z)", document.NewBoolValue(false)},
+		{"field_exists(b.d[1])", document.NewBoolValue(true)},
+		{"field_exists(b.d[2])", document.NewBoolValue(false)},
+		{"field_exists(a.c)", document.NewBoolValue(false)},
+		{"field_exists(e[0])", document.NewBoolValue(false)},
+		{"field_exists(a) AND a IS NULL", document.NewBoolValue(true)},
+		{"field_exists(z) = false AND z IS NULL", document.NewBoolValue(true)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, expr.EvalStack{Document: d}, test.res, false)
+		})
+	}
+
+	t.Run("No document", func(t *testing.T) {
+		testExpr(t, "field_exists(a)", expr.EvalStack{}, document.Value{}, true)
+	})
+
+	for _, s := range []string{"field_exists()", "field_exists(a, b)", "field_exists(1)", "field_exists(typeof(a))"} {
+		t.Run(s, func(t *testing.T) {
+			_, _, err := parser.NewParser(strings.NewReader(s)).ParseExpr()
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestJSONExtract(t *testing.T) {
+	d := document.NewFromJSON([]byte(`{
+		"a": {"b": [10, {"c": "foo"}], "d e": true},
+		"f": [[1, 2], 3],
+		"g": null
+	}`))
+
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{`json_extract(a, '$.b[0]')`, document.NewIntegerValue(10), false},
+		{`json_extract(a, '$.b[1].c')`, document.NewTextValue("foo"), false},
+		{`json_extract(a, '$["d e"]')`, document.NewBoolValue(true), false},
+		{`json_extract(f, '$[0][1]')`, document.NewIntegerValue(2), false},
+		{`json_extract(a.b[0], '$')`, document.NewIntegerValue(10), false},
+		{`json_extract({x: {y: 1}}, '$.x.y')`, document.NewIntegerValue(1), false},
+		{`JSON_EXTRACT(a, '$.b[1].c') = 'foo'`, document.NewBoolValue(true), false},
+		{`json_extract(a, '$.z')`, nullLitteral, false},
+		{`json_extract(a, '$.b[5]')`, nullLitteral, false},
+		{`json_extract(a, '$.b.c')`, nullLitteral, false},
+		{`json_extract(g, '$.a')`, nullLitteral, false},
+		{`json_extract(z, '$.a')`, nullLitteral, false},
+		{`json_extract(a, 1)`, nullLitteral, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, expr.EvalStack{Document: d}, test.res, test.fails)
+		})
+	}
+
+	t.Run("Param", func(t *testing.T) {
+		stack := expr.EvalStack{Document: d, Params: []expr.Param{{Value: "$.b[1].c"}}}
+		testExpr(t, `json_extract(a, ?)`, stack, document.NewTextValue("foo"), false)
+
+		stack.Params[0].Value = "$.b["
+		testExpr(t, `json_extract(a, ?)`, stack, nullLitteral, true)
+	})
+
+	for _, s := range []string{
+		`json_extract(a)`,
+		`json_extract(a, '$.b', '$.c')`,
+		`json_extract(a, 'b')`,
+		`json_extract(a, '$.')`,
+		`json_extract(a, '$.b[')`,
+		`json_extract(a, '$.b[-1]')`,
+		`json_extract(a, '$.b[x]')`,
+		`json_extract(a, '$b')`,
+	} {
+		t.Run(s, func(t *testing.T) {
+			_, _, err := parser.NewParser(strings.NewReader(s)).ParseExpr()
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	d := document.NewFromJSON([]byte(`{"a": "x;y", "b": null, "c": 10}`))
+
+	texts := func(s ...string) document.Value {
+		vb := document.NewValueBuffer()
+		for _, s := range s {
+			vb = vb.Append(document.NewTextValue(s))
+		}
+		return document.NewArrayValue(vb)
+	}
+
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{`SPLIT('a,b,c', ',')`, texts("a", "b", "c"), false},
+		{`split('a, b', ', ')`, texts("a", "b"), false},
+		{`SPLIT('a,,b,', ',')`, texts("a", "", "b", ""), false},
+		{`SPLIT('abc', ',')`, texts("abc"), false},
+		{`SPLIT(a, ';')`, texts("x", "y"), false},
+		{`SPLIT('', ',')`, texts(), false},
+		{`SPLIT('', '')`, texts(), false},
+		{`SPLIT('日本', '')`, texts("日", "本"), false},
+		{`SPLIT(NULL, ',')`, nullLitteral, false},
+		{`SPLIT('a,b', NULL)`, nullLitteral, false},
+		{`SPLIT(b, ',')`, nullLitteral, false},
+		{`SPLIT(notFound, ',')`, nullLitteral, false},
+		{`SPLIT('a,b,c', ',')[1]`, document.NewTextValue("b"), false},
+		{`SPLIT(c, ',')`, nullLitteral, true},
+		{`SPLIT('a', 1)`, nullLitteral, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, expr.EvalStack{Document: d}, test.res, test.fails)
+		})
+	}
+
+	for _, s := range []string{`SPLIT('a')`, `SPLIT('a', ',', ',')`} {
+		t.Run(s, func(t *testing.T) {
+			_, _, err := parser.NewParser(strings.NewReader(s)).ParseExpr()
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestContainsKey(t *testing.T) {
+	d := document.NewFromJSON([]byte(`{
+		"metadata": {"source": null, "tags": {"lang": "en"}},
+		"a": 10,
+		"b": [{"source": 1}]
+	}`))
+
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{`CONTAINS_KEY(metadata, 'source')`, document.NewBoolValue(true), false},
+		{`contains_key(metadata, 'tags')`, document.NewBoolValue(true), false},
+		{`CONTAINS_KEY(metadata, 'version')`, document.NewBoolValue(false), false},
+		{`CONTAINS_KEY(metadata.tags, 'lang')`, document.NewBoolValue(true), false},
+		{`CONTAINS_KEY(metadata.tags, 'source')`, document.NewBoolValue(false), false},
+		{`CONTAINS_KEY(b[0], 'source')`, document.NewBoolValue(true), false},
+		{`CONTAINS_KEY({"x y": 1}, 'x y')`, document.NewBoolValue(true), false},
+		{`CONTAINS_KEY('metadata')`, document.NewBoolValue(true), false},
+		{`CONTAINS_KEY('source')`, document.NewBoolValue(false), false},
+		{`CONTAINS_KEY(a, 'source')`, document.NewBoolValue(false), false},
+		{`CONTAINS_KEY(b, 'source')`, document.NewBoolValue(false), false},
+		{`CONTAINS_KEY(notFound, 'source')`, document.NewBoolValue(false), false},
+		{`CONTAINS_KEY(NULL, 'source')`, document.NewBoolValue(false), false},
+		{`CONTAINS_KEY(metadata, NULL)`, nullLitteral, false},
+		{`CONTAINS_KEY(metadata, 1)`, nullLitteral, true},
+		{`CONTAINS_KEY(metadata, 'source') AND metadata.source IS NULL`, document.NewBoolValue(true), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, expr.EvalStack{Document: d}, test.res, test.fails)
+		})
+	}
+
+	t.Run("Named param", func(t *testing.T) {
+		stack := expr.EvalStack{Document: d, Params: []expr.Param{{Name: "key", Value: "tags"}}}
+		testExpr(t, `CONTAINS_KEY(metadata, $key)`, stack, document.NewBoolValue(true), false)
+
+		stack.Params[0].Value = "version"
+		testExpr(t, `CONTAINS_KEY(metadata, $key)`, stack, document.NewBoolValue(false), false)
+	})
+
+	t.Run("No document", func(t *testing.T) {
+		testExpr(t, `CONTAINS_KEY('source')`, expr.EvalStack{}, document.NewBoolValue(false), false)
+	})
+
+	for _, s := range []string{`CONTAINS_KEY()`, `CONTAINS_KEY(a, 'b', 'c')`} {
+		t.Run(s, func(t *testing.T) {
+			_, _, err := parser.NewParser(strings.NewReader(s)).ParseExpr()
+			require.Error(t, err)
+		})
+	}
+}
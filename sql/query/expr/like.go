@@ -21,6 +21,8 @@ func Like(a, b Expr) Expr {
 	return &likeOp{&simpleOperator{a, b, scanner.LIKE}}
 }
 
+// Eval evaluates to NULL if one of the operands is not a text,
+// unless strict typing is enabled on the database, in which case it returns an error.
 func (op likeOp) Eval(ctx EvalStack) (document.Value, error) {
 	a, b, err := op.simpleOperator.eval(ctx)
 	if err != nil {
@@ -28,7 +30,10 @@ func (op likeOp) Eval(ctx EvalStack) (document.Value, error) {
 	}
 
 	if a.Type != document.TextValue || b.Type != document.TextValue {
-		return nullLitteral, errors.New("LIKE operator takes a text")
+		if ctx.strictTypes() {
+			return nullLitteral, errors.New("LIKE operator takes a text")
+		}
+		return nullLitteral, nil
 	}
 
 	if like(b.V.(string), a.V.(string)) {
@@ -38,6 +43,13 @@ func (op likeOp) Eval(ctx EvalStack) (document.Value, error) {
 	return falseLitteral, nil
 }
 
+// IsLikeOperator returns true if e is the LIKE operator.
+// NOT LIKE is not a LIKE operator.
+func IsLikeOperator(e Expr) bool {
+	_, ok := e.(*likeOp)
+	return ok
+}
+
 func (op likeOp) String() string {
 	return fmt.Sprintf("%v LIKE %v", op.a, op.b)
 }
@@ -1,6 +1,7 @@
 package expr
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -26,6 +27,12 @@ func (v LiteralValue) String() string {
 	return document.Value(v).String()
 }
 
+// GoString implements the fmt.GoStringer interface.
+// See document.Value.GoString.
+func (v LiteralValue) GoString() string {
+	return document.Value(v).GoString()
+}
+
 // BlobValue creates a litteral value of type Blob.
 func BlobValue(v []byte) LiteralValue {
 	return LiteralValue(document.NewBlobValue(v))
@@ -111,11 +118,24 @@ func (l LiteralExprList) String() string {
 }
 
 // Eval evaluates all the expressions and returns a litteralValueList. It implements the Expr interface.
+// The elements of the arrays returned by spread expressions are added to the list one by one.
 func (l LiteralExprList) Eval(stack EvalStack) (document.Value, error) {
-	var err error
-	values := make(document.ValueBuffer, len(l))
-	for i, e := range l {
-		values[i], err = e.Eval(stack)
+	values := make(document.ValueBuffer, 0, len(l))
+	for _, e := range l {
+		v, err := e.Eval(stack)
+		if err != nil {
+			return nullLitteral, err
+		}
+
+		if _, ok := e.(SpreadExpr); !ok {
+			values = append(values, v)
+			continue
+		}
+
+		err = v.V.(document.Array).Iterate(func(i int, v document.Value) error {
+			values = append(values, v)
+			return nil
+		})
 		if err != nil {
 			return nullLitteral, err
 		}
@@ -124,14 +144,117 @@ func (l LiteralExprList) Eval(stack EvalStack) (document.Value, error) {
 	return document.NewArrayValue(values), nil
 }
 
+// SpreadExpr is an expression prefixed by ..., whose elements are added to
+// the array literal containing it, like [...a, 4].
+type SpreadExpr struct {
+	E Expr
+}
+
+// Eval evaluates the expression, which must return an array.
+func (s SpreadExpr) Eval(stack EvalStack) (document.Value, error) {
+	v, err := s.E.Eval(stack)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	if v.Type != document.ArrayValue {
+		return nullLitteral, fmt.Errorf("cannot spread value of type %s, expected array", v.Type)
+	}
+
+	return v, nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (s SpreadExpr) IsEqual(other Expr) bool {
+	o, ok := other.(SpreadExpr)
+	return ok && Equal(s.E, o.E)
+}
+
+// String implements the fmt.Stringer interface.
+func (s SpreadExpr) String() string {
+	return fmt.Sprintf("...%v", s.E)
+}
+
+// TupleExpr is a list of expressions written between parentheses, like (a, b).
+// It evaluates to an array, but comparison operators and the IN operator
+// compare tuples element by element.
+type TupleExpr struct {
+	Elements []Expr
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (t TupleExpr) IsEqual(other Expr) bool {
+	o, ok := other.(TupleExpr)
+	if !ok {
+		return false
+	}
+
+	return LiteralExprList(t.Elements).IsEqual(LiteralExprList(o.Elements))
+}
+
+// String implements the fmt.Stringer interface.
+func (t TupleExpr) String() string {
+	var b strings.Builder
+
+	b.WriteRune('(')
+	for i, e := range t.Elements {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprintf("%v", e))
+	}
+	b.WriteRune(')')
+
+	return b.String()
+}
+
+// Eval evaluates all the elements and returns them as an array. It implements the Expr interface.
+func (t TupleExpr) Eval(stack EvalStack) (document.Value, error) {
+	return LiteralExprList(t.Elements).Eval(stack)
+}
+
+// isTuple returns whether e is a tuple, ignoring parentheses.
+func isTuple(e Expr) bool {
+	for {
+		p, ok := e.(Parentheses)
+		if !ok {
+			break
+		}
+		e = p.E
+	}
+
+	_, ok := e.(TupleExpr)
+	return ok
+}
+
 // KVPair associates an identifier with an expression.
+// A pair without identifier whose expression is a SpreadExpr is a spread pair,
+// created with SpreadPair.
 type KVPair struct {
 	K string
 	V Expr
 }
 
+// SpreadPair returns a pair that adds the fields of the document
+// returned by e to the document.
+func SpreadPair(e Expr) KVPair {
+	return KVPair{V: SpreadExpr{E: e}}
+}
+
+// IsSpread returns true if p is a spread pair.
+func (p KVPair) IsSpread() bool {
+	_, ok := p.V.(SpreadExpr)
+	return ok && p.K == ""
+}
+
 // String implements the fmt.Stringer interface.
 func (p KVPair) String() string {
+	if p.IsSpread() {
+		return fmt.Sprintf("%v", p.V)
+	}
+
 	return fmt.Sprintf("%q: %v", p.K, p.V)
 }
 
@@ -162,19 +285,51 @@ func (kvp KVPairs) IsEqual(other Expr) bool {
 }
 
 // Eval turns a list of KVPairs into a document.
+// The fields of the documents returned by spread pairs are added to the document,
+// and any field set after a spread pair overrides the field with the same name.
 func (kvp KVPairs) Eval(ctx EvalStack) (document.Value, error) {
 	var fb document.FieldBuffer
 	if ctx.Document == nil {
 		ctx.Document = &fb
 	}
 
+	var spread bool
+	set := func(k string, v document.Value) {
+		if spread && fb.Replace(k, v) == nil {
+			return
+		}
+
+		fb.Add(k, v)
+	}
+
 	for _, kv := range kvp {
-		v, err := kv.V.Eval(ctx)
+		if !kv.IsSpread() {
+			v, err := kv.V.Eval(ctx)
+			if err != nil {
+				return document.Value{}, err
+			}
+
+			set(kv.K, v)
+			continue
+		}
+
+		v, err := kv.V.(SpreadExpr).E.Eval(ctx)
 		if err != nil {
 			return document.Value{}, err
 		}
 
-		fb.Add(kv.K, v)
+		if v.Type != document.DocumentValue {
+			return document.Value{}, fmt.Errorf("cannot spread value of type %s, expected document", v.Type)
+		}
+
+		spread = true
+		err = v.V.(document.Document).Iterate(func(field string, value document.Value) error {
+			set(field, value)
+			return nil
+		})
+		if err != nil {
+			return document.Value{}, err
+		}
 	}
 
 	return document.NewDocumentValue(&fb), nil
@@ -195,3 +350,24 @@ func (kvp KVPairs) String() string {
 
 	return b.String()
 }
+
+// Default is a marker used in place of a value to use the default value
+// of a field. It is resolved by the statement using it and cannot be evaluated.
+type Default struct{}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (Default) IsEqual(other Expr) bool {
+	_, ok := other.(Default)
+	return ok
+}
+
+// Eval returns an error. It implements the Expr interface.
+func (Default) Eval(EvalStack) (document.Value, error) {
+	return nullLitteral, errors.New("DEFAULT cannot be evaluated")
+}
+
+// String implements the fmt.Stringer interface.
+func (Default) String() string {
+	return "DEFAULT"
+}
@@ -24,7 +24,7 @@ func (op *AndOp) Eval(ctx EvalStack) (document.Value, error) {
 	if err != nil {
 		return falseLitteral, err
 	}
-	isTruthy, err := s.IsTruthy()
+	isTruthy, err := document.IsTruthy(s)
 	if !isTruthy || err != nil {
 		return falseLitteral, err
 	}
@@ -33,7 +33,7 @@ func (op *AndOp) Eval(ctx EvalStack) (document.Value, error) {
 	if err != nil {
 		return falseLitteral, err
 	}
-	isTruthy, err = s.IsTruthy()
+	isTruthy, err = document.IsTruthy(s)
 	if !isTruthy || err != nil {
 		return falseLitteral, err
 	}
@@ -63,7 +63,7 @@ func (op *OrOp) Eval(ctx EvalStack) (document.Value, error) {
 	if err != nil {
 		return falseLitteral, err
 	}
-	isTruthy, err := s.IsTruthy()
+	isTruthy, err := document.IsTruthy(s)
 	if err != nil {
 		return falseLitteral, err
 	}
@@ -75,7 +75,7 @@ func (op *OrOp) Eval(ctx EvalStack) (document.Value, error) {
 	if err != nil {
 		return falseLitteral, err
 	}
-	isTruthy, err = s.IsTruthy()
+	isTruthy, err = document.IsTruthy(s)
 	if err != nil {
 		return falseLitteral, err
 	}
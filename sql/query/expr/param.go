@@ -85,3 +85,36 @@ func (p PositionalParam) extract(params []Param) (interface{}, error) {
 
 	return params[idx].Value, nil
 }
+
+// A boundParam is a named or positional parameter whose value was resolved by Prepare.
+type boundParam struct {
+	param Expr
+	v     document.Value
+}
+
+// Eval returns the value of the parameter.
+func (p boundParam) Eval(EvalStack) (document.Value, error) {
+	return p.v, nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (p boundParam) IsEqual(other Expr) bool {
+	o, ok := other.(boundParam)
+	return ok && Equal(p.param, o.param)
+}
+
+// String implements the fmt.Stringer interface.
+func (p boundParam) String() string {
+	return fmt.Sprintf("%v", p.param)
+}
+
+// isParam returns whether e is a named or a positional parameter.
+func isParam(e Expr) bool {
+	switch e.(type) {
+	case NamedParam, PositionalParam, boundParam:
+		return true
+	}
+
+	return false
+}
@@ -1,6 +1,8 @@
 package expr
 
 import (
+	"fmt"
+
 	"github.com/genjidb/genji/document"
 )
 
@@ -14,7 +16,38 @@ func (p Path) Eval(stack EvalStack) (document.Value, error) {
 		return nullLitteral, document.ErrFieldNotFound
 	}
 
-	v, err := document.Path(p).GetValue(stack.Document)
+	return resolvePath(document.Path(p), document.NewDocumentValue(stack.Document), stack.caseInsensitiveFields())
+}
+
+// Resolve returns the value at path p of d, walking through nested documents and arrays.
+// Missing fields, out of bounds array indices and paths going through values that are
+// neither documents nor arrays resolve to NULL.
+// It is the way expressions, functions and planner nodes resolve paths.
+func (p Path) Resolve(d document.Document) (document.Value, error) {
+	if d == nil {
+		return nullLitteral, nil
+	}
+
+	return resolvePath(document.Path(p), document.NewDocumentValue(d), false)
+}
+
+// resolvePath returns the value at path p of v, or NULL if there is none.
+// If fold is true, field names are matched case insensitively.
+func resolvePath(p document.Path, v document.Value, fold bool) (document.Value, error) {
+	var err error
+
+	switch v.Type {
+	case document.DocumentValue:
+		d := v.V.(document.Document)
+		if fold {
+			p = p.Fold(d)
+		}
+		v, err = p.GetValue(d)
+	case document.ArrayValue:
+		v, err = p.GetValueFromArray(v.V.(document.Array))
+	default:
+		return nullLitteral, nil
+	}
 	if err == document.ErrFieldNotFound || err == document.ErrValueNotFound {
 		return nullLitteral, nil
 	}
@@ -22,6 +55,92 @@ func (p Path) Eval(stack EvalStack) (document.Value, error) {
 	return v, err
 }
 
+// Set returns a copy of d in which the value at path p is replaced by v, or created if
+// it doesn't exist. The other fields of d are left untouched, and d itself is not modified.
+// Missing intermediate documents are created, but array indices must already exist.
+// It returns an error if the path goes through a value that is neither a document nor an array.
+func (p Path) Set(d document.Document, v document.Value) (document.Document, error) {
+	if len(p) == 0 || p[0].FieldName == "" {
+		return nil, fmt.Errorf("cannot set %s: path must start with a field", p)
+	}
+
+	if d == nil {
+		d = document.NewFieldBuffer()
+	}
+
+	nv, err := setPath(document.Path(p), 0, document.NewDocumentValue(d), v)
+	if err != nil {
+		return nil, err
+	}
+
+	return nv.V.(document.Document), nil
+}
+
+// setPath returns a copy of cur, the value at p[:i], in which the value at p[i:]
+// is replaced by v.
+func setPath(p document.Path, i int, cur, v document.Value) (document.Value, error) {
+	if p[i].FieldName != "" {
+		if cur.Type != document.DocumentValue {
+			return cur, fmt.Errorf("cannot set %s: %s is not a document", p, p[:i])
+		}
+
+		var fb document.FieldBuffer
+		err := fb.ScanDocument(cur.V.(document.Document))
+		if err != nil {
+			return cur, err
+		}
+
+		if i+1 < len(p) {
+			child, err := fb.GetByField(p[i].FieldName)
+			if err == document.ErrFieldNotFound && p[i+1].FieldName != "" {
+				child, err = document.NewDocumentValue(document.NewFieldBuffer()), nil
+			}
+			if err == document.ErrFieldNotFound {
+				return cur, fmt.Errorf("cannot set %s: %s not found", p, p[:i+1])
+			}
+			if err != nil {
+				return cur, err
+			}
+
+			v, err = setPath(p, i+1, child, v)
+			if err != nil {
+				return cur, err
+			}
+		}
+
+		err = fb.Set(document.Path{p[i]}, v)
+		return document.NewDocumentValue(&fb), err
+	}
+
+	if cur.Type != document.ArrayValue {
+		return cur, fmt.Errorf("cannot set %s: %s is not an array", p, p[:i])
+	}
+
+	var vb document.ValueBuffer
+	err := vb.ScanArray(cur.V.(document.Array))
+	if err != nil {
+		return cur, err
+	}
+
+	child, err := vb.GetByIndex(p[i].ArrayIndex)
+	if err == document.ErrFieldNotFound {
+		return cur, fmt.Errorf("cannot set %s: index %d of %s out of range", p, p[i].ArrayIndex, p[:i])
+	}
+	if err != nil {
+		return cur, err
+	}
+
+	if i+1 < len(p) {
+		v, err = setPath(p, i+1, child, v)
+		if err != nil {
+			return cur, err
+		}
+	}
+
+	err = vb.Replace(p[i].ArrayIndex, v)
+	return document.NewArrayValue(&vb), err
+}
+
 // IsEqual compares this expression with the other expression and returns
 // true if they are equal.
 func (p Path) IsEqual(other Expr) bool {
@@ -40,3 +159,38 @@ func (p Path) IsEqual(other Expr) bool {
 func (p Path) String() string {
 	return document.Path(p).String()
 }
+
+// A PathAccessExpr selects a value at a given path of the value returned by an expression,
+// like the first element of the array returned by a function call in SPLIT(s, ',')[0].
+// If the value has no such path, it evaluates to NULL.
+type PathAccessExpr struct {
+	E    Expr
+	Path Path
+}
+
+// Eval evaluates the expression and selects the value at the path.
+func (p PathAccessExpr) Eval(stack EvalStack) (document.Value, error) {
+	v, err := p.E.Eval(stack)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return resolvePath(document.Path(p.Path), v, stack.caseInsensitiveFields())
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (p PathAccessExpr) IsEqual(other Expr) bool {
+	o, ok := other.(PathAccessExpr)
+	return ok && Equal(p.E, o.E) && p.Path.IsEqual(o.Path)
+}
+
+// String implements the fmt.Stringer interface.
+func (p PathAccessExpr) String() string {
+	s := p.Path.String()
+	if len(p.Path) > 0 && p.Path[0].FieldName != "" {
+		s = "." + s
+	}
+
+	return fmt.Sprintf("%v%s", p.E, s)
+}
@@ -3,6 +3,7 @@ package expr_test
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/genjidb/genji/document"
@@ -52,6 +53,140 @@ func TestPathExpr(t *testing.T) {
 	})
 }
 
+func TestPathResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		res  document.Value
+	}{
+		{"4-level nested path", "a.b.c.d", document.NewIntegerValue(1)},
+		{"missing intermediate", "a.x.c.d", nullLitteral},
+		{"missing field", "a.b.c.x", nullLitteral},
+		{"array index", "e[1].f", document.NewTextValue("g")},
+		{"nested array index", "e[2][0]", document.NewIntegerValue(3)},
+		{"out of bounds array index", "e[10]", nullLitteral},
+		{"field of an array", "e.f", nullLitteral},
+		{"index of a document", "a[0]", nullLitteral},
+		{"path on non-document value", "h.i", nullLitteral},
+		{"index of non-array value", "h[0]", nullLitteral},
+	}
+
+	d := document.NewFromJSON([]byte(`{
+		"a": {"b": {"c": {"d": 1}}},
+		"e": [1, {"f": "g"}, [3]],
+		"h": 10
+	}`))
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.ParsePath(test.path)
+			require.NoError(t, err)
+
+			v, err := expr.Path(p).Resolve(d)
+			require.NoError(t, err)
+			require.Equal(t, test.res, v)
+		})
+	}
+
+	t.Run("nil document", func(t *testing.T) {
+		v, err := expr.Path{document.PathFragment{FieldName: "a"}}.Resolve(nil)
+		require.NoError(t, err)
+		require.Equal(t, nullLitteral, v)
+	})
+}
+
+func TestPathSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		value document.Value
+		want  string
+		fails bool
+	}{
+		{"root field", "a", document.NewIntegerValue(2), `{"a": 2, "e": [1, {"f": "g"}, [3]], "h": 10}`, false},
+		{"new root field", "z", document.NewIntegerValue(2), `{"a": {"b": {"c": 1, "x": true}, "y": "foo"}, "e": [1, {"f": "g"}, [3]], "h": 10, "z": 2}`, false},
+		{"3-level nested path", "a.b.c", document.NewIntegerValue(2), `{"a": {"b": {"c": 2, "x": true}, "y": "foo"}, "e": [1, {"f": "g"}, [3]], "h": 10}`, false},
+		{"new nested field", "a.b.z", document.NewIntegerValue(2), `{"a": {"b": {"c": 1, "x": true, "z": 2}, "y": "foo"}, "e": [1, {"f": "g"}, [3]], "h": 10}`, false},
+		{"missing intermediates", "a.x.y.z", document.NewIntegerValue(2), `{"a": {"b": {"c": 1, "x": true}, "y": "foo", "x": {"y": {"z": 2}}}, "e": [1, {"f": "g"}, [3]], "h": 10}`, false},
+		{"array index", "e[0]", document.NewIntegerValue(2), `{"a": {"b": {"c": 1, "x": true}, "y": "foo"}, "e": [2, {"f": "g"}, [3]], "h": 10}`, false},
+		{"document in array", "e[1].f", document.NewIntegerValue(2), `{"a": {"b": {"c": 1, "x": true}, "y": "foo"}, "e": [1, {"f": 2}, [3]], "h": 10}`, false},
+		{"nested array", "e[2][0]", document.NewIntegerValue(2), `{"a": {"b": {"c": 1, "x": true}, "y": "foo"}, "e": [1, {"f": "g"}, [2]], "h": 10}`, false},
+		{"scalar intermediate", "h.i", document.NewIntegerValue(2), ``, true},
+		{"scalar intermediate in array", "e[0].f", document.NewIntegerValue(2), ``, true},
+		{"index of a document", "a[0]", document.NewIntegerValue(2), ``, true},
+		{"out of range index", "e[10]", document.NewIntegerValue(2), ``, true},
+		{"index of a missing field", "x[0]", document.NewIntegerValue(2), ``, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := document.NewFromJSON([]byte(`{
+				"a": {"b": {"c": 1, "x": true}, "y": "foo"},
+				"e": [1, {"f": "g"}, [3]],
+				"h": 10
+			}`))
+			before, err := document.MarshalJSON(d)
+			require.NoError(t, err)
+
+			p, err := parser.ParsePath(test.path)
+			require.NoError(t, err)
+
+			res, err := expr.Path(p).Set(d, test.value)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := document.MarshalJSON(res)
+			require.NoError(t, err)
+			require.JSONEq(t, test.want, string(data))
+
+			// the original document is not modified
+			after, err := document.MarshalJSON(d)
+			require.NoError(t, err)
+			require.Equal(t, before, after)
+		})
+	}
+
+	t.Run("nil document", func(t *testing.T) {
+		res, err := expr.Path{document.PathFragment{FieldName: "a"}}.Set(nil, document.NewIntegerValue(1))
+		require.NoError(t, err)
+		data, err := document.MarshalJSON(res)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"a": 1}`, string(data))
+	})
+}
+
+func TestPathAccessExpr(t *testing.T) {
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"json_extract(a, '$.b')[0]", document.NewIntegerValue(1), false},
+		{"json_extract(a, '$.b')[1].c", document.NewTextValue("d"), false},
+		{"json_extract(a, '$.b')[2]", nullLitteral, false},
+		{"json_extract(a, '$.b').c", nullLitteral, false},
+		{"json_extract(a, '$.b[1]').c", document.NewTextValue("d"), false},
+		{"typeof(a)[0]", nullLitteral, false},
+	}
+
+	d := document.NewFromJSON([]byte(`{"a": {"b": [1, {"c": "d"}]}}`))
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, expr.EvalStack{Document: d}, test.res, test.fails)
+		})
+	}
+
+	t.Run("String", func(t *testing.T) {
+		e, _, err := parser.NewParser(strings.NewReader("json_extract(a, '$.b')[1].c")).ParseExpr()
+		require.NoError(t, err)
+		require.Equal(t, `json_extract(a, "$.b")[1].c`, fmt.Sprintf("%v", e))
+	})
+}
+
 func TestPathIsEqual(t *testing.T) {
 	tests := []struct {
 		a, b    string
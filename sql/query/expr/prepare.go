@@ -0,0 +1,65 @@
+package expr
+
+import (
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+)
+
+// StatementEnv contains what doesn't change during the execution of a statement.
+// Any of the members can be nil.
+type StatementEnv struct {
+	Tx     *database.Transaction
+	Params []Param
+	Info   *database.TableInfo
+}
+
+// A CompiledExpr evaluates a prepared expression against a document.
+type CompiledExpr func(d document.Document) (document.Value, error)
+
+// Prepare analyses e once for the whole execution of a statement and returns
+// a function that only deals with the document it is evaluated against.
+// Parameters are resolved only once, so a missing parameter is reported by Prepare
+// even if the expression is never evaluated, and REGEXP patterns passed as parameters
+// are compiled once as well.
+// The returned function evaluates the prepared expression using its Eval method,
+// so prepared expressions follow the same rules as the other ones.
+func Prepare(e Expr, env *StatementEnv) (CompiledExpr, error) {
+	if env == nil {
+		env = &StatementEnv{}
+	}
+
+	stack := EvalStack{
+		Tx:     env.Tx,
+		Params: env.Params,
+		Info:   env.Info,
+	}
+
+	var err error
+	e = Walk(e, func(e Expr) (Expr, bool) {
+		if err != nil {
+			return e, false
+		}
+
+		switch t := e.(type) {
+		case NamedParam, PositionalParam:
+			var v document.Value
+			v, err = t.Eval(stack)
+			return boundParam{param: t, v: v}, false
+		case *regexpOp:
+			err = checkRegexpParam(t.b, stack)
+		case *notRegexpOp:
+			err = checkRegexpParam(t.b, stack)
+		}
+
+		return e, true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(d document.Document) (document.Value, error) {
+		s := stack
+		s.Document = d
+		return e.Eval(s)
+	}, nil
+}
@@ -0,0 +1,146 @@
+package expr_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/genjidb/genji/sql/query/expr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepare(t *testing.T) {
+	docs := []document.Document{
+		document.NewFromJSON([]byte(`{"a": 1, "b": 2, "c": "foo", "d": {"e": [1, 2]}}`)),
+		document.NewFromJSON([]byte(`{"a": 2.5, "b": null}`)),
+		nil,
+	}
+
+	params := []expr.Param{{Value: 2}, {Name: "x", Value: "foo"}}
+
+	tests := []string{
+		`1`,
+		`?`,
+		`$x`,
+		`a`,
+		`d.e[1]`,
+		`(a)`,
+		`a = 1`,
+		`a != 1`,
+		`a > ?`,
+		`a >= 2.5`,
+		`b < 3`,
+		`b <= ?`,
+		`c = $x`,
+		`c > 1`,
+		`a + 1 > b`,
+		`a - b * 2 / 4 % 3`,
+		`a & 1 | 2 ^ 3`,
+		`a > 1 AND b < 3`,
+		`a > 1 OR b < 3`,
+		`a = 1 AND b = 2 AND c = "foo"`,
+		`b > 1 OR c`,
+		`CAST(a AS TEXT)`,
+		`CAST(a AS INT8)`,
+		`a IN (1, 2)`,
+		`(a, b) = (1, 2)`,
+		`(a, b) < (2, ?)`,
+		`c LIKE "f%"`,
+		`c REGEXP "^f"`,
+		`c NOT REGEXP $x`,
+		`c REGEXP a`,
+		`typeof(a)`,
+		`json_extract(d, '$.e[1]')`,
+		`CONTAINS_KEY(d, 'e')`,
+		`CONTAINS_KEY('a')`,
+		`a IS NULL`,
+	}
+
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			e, _, err := parser.NewParser(strings.NewReader(test)).ParseExpr()
+			require.NoError(t, err)
+
+			c, err := expr.Prepare(e, &expr.StatementEnv{Params: params})
+			require.NoError(t, err)
+
+			for _, d := range docs {
+				want, wantErr := e.Eval(expr.EvalStack{Document: d, Params: params})
+
+				got, err := c(d)
+				if wantErr != nil {
+					require.Equal(t, wantErr, err)
+					continue
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, want, got)
+			}
+		})
+	}
+
+	t.Run("Missing param", func(t *testing.T) {
+		_, err := expr.Prepare(expr.Eq(expr.Path{document.PathFragment{FieldName: "a"}}, expr.NamedParam("y")), &expr.StatementEnv{Params: params})
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid pattern param", func(t *testing.T) {
+		_, err := expr.Prepare(expr.Regexp(expr.Path{document.PathFragment{FieldName: "c"}}, expr.PositionalParam(1)), &expr.StatementEnv{
+			Params: []expr.Param{{Value: "a("}},
+		})
+		require.Error(t, err)
+	})
+}
+
+// BenchmarkWhere compares the evaluation of a WHERE clause with 5 conjuncts
+// over 1M documents, using Eval or a prepared expression.
+func BenchmarkWhere(b *testing.B) {
+	const size = 1000000
+
+	docs := make([]document.Document, 1000)
+	for i := range docs {
+		docs[i] = document.NewFieldBuffer().
+			Add("a", document.NewIntegerValue(int64(i))).
+			Add("b", document.NewDoubleValue(float64(i)/2)).
+			Add("c", document.NewTextValue(fmt.Sprintf("foo%d", i%10))).
+			Add("d", document.NewBoolValue(i%2 == 0)).
+			Add("e", document.NewIntegerValue(int64(i%100)))
+	}
+
+	e, _, err := parser.NewParser(strings.NewReader(`a > 10 AND b < $max AND c != "foo5" AND d = $flag AND e >= 0`)).ParseExpr()
+	require.NoError(b, err)
+
+	params := []expr.Param{{Name: "flag", Value: true}, {Name: "max", Value: 400}}
+
+	b.Run("Eval", func(b *testing.B) {
+		stack := expr.EvalStack{Params: params}
+
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < size; j++ {
+				stack.Document = docs[j%len(docs)]
+				_, err := e.Eval(stack)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("Prepare", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c, err := expr.Prepare(e, &expr.StatementEnv{Params: params})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			for j := 0; j < size; j++ {
+				_, err := c(docs[j%len(docs)])
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+type regexpOp struct {
+	*simpleOperator
+
+	// compiled pattern, if the right operand is a text literal
+	re *regexp.Regexp
+}
+
+// Regexp creates an expression that evaluates to the result of a REGEXP b.
+// The pattern is compiled once if b is a text literal or a parameter resolved
+// by Prepare, otherwise it is compiled on each evaluation.
+func Regexp(a, b Expr) Expr {
+	return &regexpOp{simpleOperator: &simpleOperator{a, b, scanner.REGEXP}, re: compileLiteralRegexp(b)}
+}
+
+func compileLiteralRegexp(e Expr) *regexp.Regexp {
+	var v document.Value
+	switch t := e.(type) {
+	case LiteralValue:
+		v = document.Value(t)
+	case boundParam:
+		v = t.v
+	}
+
+	if v.Type != document.TextValue {
+		return nil
+	}
+
+	// invalid patterns are reported by Eval
+	re, _ := regexp.Compile(v.V.(string))
+	return re
+}
+
+// Eval returns true if a matches the regular expression b, using the syntax of the regexp package.
+// A pattern can be made case-insensitive with the i flag: a REGEXP '(?i)foo'.
+// It evaluates to NULL if one of the operands is NULL or not a text,
+// unless strict typing is enabled on the database, in which case non-text operands return an error.
+func (op regexpOp) Eval(ctx EvalStack) (document.Value, error) {
+	a, b, err := op.simpleOperator.eval(ctx)
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return op.match(ctx.strictTypes(), a, b, op.re)
+}
+
+// match returns whether a matches the pattern b, using re if it is not nil.
+func (op regexpOp) match(strict bool, a, b document.Value, re *regexp.Regexp) (document.Value, error) {
+	if a.Type != document.TextValue || b.Type != document.TextValue {
+		if strict && a.Type != document.NullValue && b.Type != document.NullValue {
+			return nullLitteral, errors.New("REGEXP operator takes a text")
+		}
+		return nullLitteral, nil
+	}
+
+	if re == nil {
+		var err error
+		re, err = regexp.Compile(b.V.(string))
+		if err != nil {
+			return nullLitteral, err
+		}
+	}
+
+	if re.MatchString(a.V.(string)) {
+		return trueLitteral, nil
+	}
+
+	return falseLitteral, nil
+}
+
+// SetRightHandExpr sets the pattern and compiles it if it is a text literal.
+func (op *regexpOp) SetRightHandExpr(b Expr) {
+	op.b = b
+	op.re = compileLiteralRegexp(b)
+}
+
+func (op regexpOp) String() string {
+	return fmt.Sprintf("%v REGEXP %v", op.a, op.b)
+}
+
+type notRegexpOp struct {
+	regexpOp
+}
+
+// NotRegexp creates an expression that evaluates to the result of a NOT REGEXP b.
+func NotRegexp(a, b Expr) Expr {
+	return &notRegexpOp{regexpOp{simpleOperator: &simpleOperator{a, b, scanner.NEQREGEX}, re: compileLiteralRegexp(b)}}
+}
+
+func (op notRegexpOp) Eval(ctx EvalStack) (document.Value, error) {
+	return invertBoolResult(op.regexpOp.Eval)(ctx)
+}
+
+func (op notRegexpOp) String() string {
+	return fmt.Sprintf("%v NOT REGEXP %v", op.a, op.b)
+}
+
+// checkRegexpParam reports an invalid pattern passed as the parameter b,
+// which would otherwise only be reported when the operator is evaluated.
+func checkRegexpParam(b Expr, stack EvalStack) error {
+	if !isParam(b) {
+		return nil
+	}
+
+	v, err := b.Eval(stack)
+	if err != nil || v.Type != document.TextValue {
+		return err
+	}
+
+	_, err = regexp.Compile(v.V.(string))
+	return err
+}
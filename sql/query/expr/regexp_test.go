@@ -0,0 +1,47 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+func TestRegexpExpr(t *testing.T) {
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"'foo bar' REGEXP 'o+ b'", document.NewBoolValue(true), false},
+		{"'foo bar' REGEXP '^bar'", document.NewBoolValue(false), false},
+		{"'FOO' REGEXP '^foo$'", document.NewBoolValue(false), false},
+		{"'FOO' REGEXP '(?i)^foo$'", document.NewBoolValue(true), false},
+		{"'foo' NOT REGEXP '^b'", document.NewBoolValue(true), false},
+		{"'foo' NOT REGEXP '^f'", document.NewBoolValue(false), false},
+		{"'foo' =~ 'o$'", document.NewBoolValue(true), false},
+		{"'foo' !~ 'o$'", document.NewBoolValue(false), false},
+		{"'foo' REGEXP 'f' + 'o'", nullLitteral, false},
+		{"NULL REGEXP 'a'", nullLitteral, false},
+		{"'a' REGEXP NULL", nullLitteral, false},
+		{"NULL NOT REGEXP 'a'", nullLitteral, false},
+		{"notFound REGEXP 'a'", nullLitteral, false},
+		{"a REGEXP '1'", nullLitteral, false},
+		{"'1' REGEXP a", nullLitteral, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, stackWithDoc, test.res, test.fails)
+		})
+	}
+
+	t.Run("Param", func(t *testing.T) {
+		stack := expr.EvalStack{Params: []expr.Param{{Value: "^f"}}}
+		testExpr(t, "'foo' REGEXP ?", stack, document.NewBoolValue(true), false)
+
+		// invalid patterns are reported when evaluated
+		stack.Params[0].Value = "f("
+		testExpr(t, "'foo' REGEXP ?", stack, nullLitteral, true)
+	})
+}
@@ -0,0 +1,193 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+)
+
+// A SubqueryStatement is the SELECT statement of a subquery.
+type SubqueryStatement interface {
+	// Run executes the statement within tx and returns the selected documents.
+	Run(tx *database.Transaction, params []Param) (document.Stream, error)
+
+	// String returns the SQL representation of the statement.
+	String() string
+}
+
+// A Subquery is a SELECT statement used as an expression, like in EXISTS (SELECT ...).
+// It evaluates to the first value of the document returned by the statement,
+// or NULL if the statement doesn't return any document. It is an error for the statement
+// to return more than one document.
+// On the right side of IN, ANY and ALL, it evaluates to the list of the returned values instead.
+//
+// A correlated subquery refers to the documents of the outer statement: these references
+// are replaced by named parameters in the statement, and Refs lists the original paths,
+// whose first fragment is the name of the outer table. Every time the subquery is evaluated,
+// the paths are evaluated against the current document and passed to the statement along
+// with the parameters of the outer statement, each path using its string representation as name.
+type Subquery struct {
+	Statement SubqueryStatement
+	Refs      []Path
+}
+
+// Eval runs the statement and returns the first value of the returned document.
+func (s Subquery) Eval(stack EvalStack) (document.Value, error) {
+	v := nullLitteral
+
+	var found bool
+	err := s.Iterate(stack, func(d document.Document) error {
+		if found {
+			return fmt.Errorf("subquery %s returned more than one document", s)
+		}
+		found = true
+
+		err := d.Iterate(func(_ string, fv document.Value) error {
+			v = fv
+			return errStop
+		})
+		if err != nil && err != errStop {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return v, nil
+}
+
+// evalList runs the statement and returns an array containing a value for every
+// returned document. If tuple is true, that value is the array of the values of the
+// document, to be compared with a tuple. Otherwise, the documents must have exactly one field,
+// whose value is used.
+func (s Subquery) evalList(stack EvalStack, tuple bool) (document.Value, error) {
+	var list document.ValueBuffer
+
+	err := s.Iterate(stack, func(d document.Document) error {
+		var values document.ValueBuffer
+		err := d.Iterate(func(_ string, v document.Value) error {
+			values = values.Append(v)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if tuple {
+			list = list.Append(document.NewArrayValue(values))
+			return nil
+		}
+
+		if len(values) != 1 {
+			return fmt.Errorf("subquery %s must return one field, got %d", s, len(values))
+		}
+		list = list.Append(values[0])
+		return nil
+	})
+	if err != nil {
+		return nullLitteral, err
+	}
+
+	return document.NewArrayValue(list), nil
+}
+
+// Iterate runs the statement and calls fn for every returned document.
+func (s Subquery) Iterate(stack EvalStack, fn func(d document.Document) error) error {
+	if stack.Tx == nil {
+		return errors.New("subqueries can only be evaluated within a transaction")
+	}
+
+	params := make([]Param, 0, len(stack.Params)+len(s.Refs))
+	params = append(params, stack.Params...)
+
+	for _, ref := range s.Refs {
+		// the first fragment is the name of the outer table
+		v := nullLitteral
+		if stack.Document != nil {
+			v = document.NewDocumentValue(stack.Document)
+		}
+		if len(ref) > 1 {
+			var err error
+			v, err = ref[1:].Eval(stack)
+			if err != nil && err != document.ErrFieldNotFound {
+				return err
+			}
+		}
+
+		params = append(params, Param{Name: ref.String(), Value: v})
+	}
+
+	st, err := s.Statement.Run(stack.Tx, params)
+	if err != nil {
+		return err
+	}
+
+	return st.Iterate(fn)
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (s Subquery) IsEqual(other Expr) bool {
+	o, ok := other.(Subquery)
+	if !ok || s.Statement.String() != o.Statement.String() || len(s.Refs) != len(o.Refs) {
+		return false
+	}
+
+	for i := range s.Refs {
+		if !s.Refs[i].IsEqual(o.Refs[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s Subquery) String() string {
+	return "(" + s.Statement.String() + ")"
+}
+
+// ExistsExpr evaluates to true if its subquery returns at least one document,
+// or to false if it doesn't. If Not is true, the result is inverted.
+type ExistsExpr struct {
+	Subquery Subquery
+	Not      bool
+}
+
+// Eval runs the subquery until it returns a document.
+func (e ExistsExpr) Eval(stack EvalStack) (document.Value, error) {
+	var found bool
+
+	err := e.Subquery.Iterate(stack, func(d document.Document) error {
+		found = true
+		return errStop
+	})
+	if err != nil && err != errStop {
+		return nullLitteral, err
+	}
+
+	if found != e.Not {
+		return trueLitteral, nil
+	}
+
+	return falseLitteral, nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (e ExistsExpr) IsEqual(other Expr) bool {
+	o, ok := other.(ExistsExpr)
+	return ok && e.Not == o.Not && e.Subquery.IsEqual(o.Subquery)
+}
+
+func (e ExistsExpr) String() string {
+	if e.Not {
+		return "NOT EXISTS " + e.Subquery.String()
+	}
+
+	return "EXISTS " + e.Subquery.String()
+}
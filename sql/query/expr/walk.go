@@ -0,0 +1,89 @@
+package expr
+
+// Walk traverses e top-down and calls fn for each node of the tree.
+// The expression returned by fn replaces the visited node, and its children
+// are visited only if fn returns true.
+// Walk doesn't modify e: nodes whose children are visited are rebuilt,
+// and the resulting tree is returned.
+// Expressions unknown to this package, like user-defined functions,
+// are visited but their children are not. Subqueries are visited as well,
+// but their statement is opaque and isn't traversed.
+func Walk(e Expr, fn func(Expr) (Expr, bool)) Expr {
+	if e == nil {
+		return nil
+	}
+
+	e, ok := fn(e)
+	if !ok || e == nil {
+		return e
+	}
+
+	switch t := e.(type) {
+	case LiteralExprList:
+		l := make(LiteralExprList, len(t))
+		for i := range t {
+			l[i] = Walk(t[i], fn)
+		}
+		return l
+	case TupleExpr:
+		l := make([]Expr, len(t.Elements))
+		for i := range t.Elements {
+			l[i] = Walk(t.Elements[i], fn)
+		}
+		return TupleExpr{Elements: l}
+	case KVPairs:
+		kvp := make(KVPairs, len(t))
+		for i := range t {
+			kvp[i] = KVPair{K: t[i].K, V: Walk(t[i].V, fn)}
+		}
+		return kvp
+	case Parentheses:
+		return Parentheses{E: Walk(t.E, fn)}
+	case SpreadExpr:
+		return SpreadExpr{E: Walk(t.E, fn)}
+	case PathAccessExpr:
+		return PathAccessExpr{E: Walk(t.E, fn), Path: t.Path}
+	case CastFunc:
+		t.Expr = Walk(t.Expr, fn)
+		return t
+	case TypeOfFunc:
+		return TypeOfFunc{Expr: Walk(t.Expr, fn)}
+	case FieldExistsFunc:
+		// field_exists() only accepts a path: if the path is replaced
+		// by another kind of expression, the function is left as is.
+		if p, ok := Walk(t.Path, fn).(Path); ok {
+			return FieldExistsFunc{Path: p}
+		}
+		return t
+	case JSONExtractFunc:
+		return JSONExtractFunc{Expr: Walk(t.Expr, fn), Path: Walk(t.Path, fn)}
+	case SplitFunc:
+		return SplitFunc{Expr: Walk(t.Expr, fn), Sep: Walk(t.Sep, fn)}
+	case ContainsKeyExpr:
+		return ContainsKeyExpr{Doc: Walk(t.Doc, fn), Key: Walk(t.Key, fn)}
+	case *CountFunc:
+		c := *t
+		c.Expr = Walk(c.Expr, fn)
+		return &c
+	case *MinFunc:
+		c := *t
+		c.Expr = Walk(c.Expr, fn)
+		return &c
+	case *MaxFunc:
+		c := *t
+		c.Expr = Walk(c.Expr, fn)
+		return &c
+	case *SumFunc:
+		c := *t
+		c.Expr = Walk(c.Expr, fn)
+		return &c
+	case *AvgFunc:
+		c := *t
+		c.Expr = Walk(c.Expr, fn)
+		return &c
+	case Operator:
+		return rebuildOperator(t, Walk(t.LeftHand(), fn), Walk(t.RightHand(), fn))
+	}
+
+	return e
+}
@@ -200,3 +200,48 @@ func TestMatchLike(t *testing.T) {
 		}
 	}
 }
+
+func TestLikeRange(t *testing.T) {
+	tests := []struct {
+		pattern string
+		lo, hi  string
+		ok      bool
+	}{
+		{"abc%", "ABC", "abd", true},
+		{"abc", "ABC", "abd", true},
+		{"a1_c%", "A1", "a2", true},
+		{"12%", "12", "13", true},
+		{`a\%b%`, "A%B", "a%c", true},
+		{"日本%", "日本", "日札", true},
+		{"ask%", "A", "b", true},
+		{"%abc", "", "", false},
+		{"_abc", "", "", false},
+		{"", "", "", false},
+		{"k%", "", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			lo, hi, ok := LikeRange(test.pattern)
+			if lo != test.lo || hi != test.hi || ok != test.ok {
+				t.Errorf("LikeRange(%q) = %q, %q, %v; want %q, %q, %v", test.pattern, lo, hi, ok, test.lo, test.hi, test.ok)
+			}
+		})
+	}
+
+	// every string matching the pattern must be in the range.
+	texts := []string{"abc", "ABC", "aBcdef", "abd", "abb", "ab", "xyz", "a1xc", "A1B", "日本語", "ask", "ASK", "a%b", "a%bc"}
+	patterns := []string{"abc%", "a1_c%", "日本%", `a\%b%`, "ask%", "ab%c"}
+	for _, p := range patterns {
+		lo, hi, ok := LikeRange(p)
+		if !ok {
+			t.Fatalf("LikeRange(%q) should be ok", p)
+		}
+
+		for _, s := range texts {
+			if MatchLike(p, s) && (s < lo || hi != "" && s >= hi) {
+				t.Errorf("%q matches %q but is not in the range [%q, %q)", s, p, lo, hi)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package glob
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// LikeRange returns a range of strings containing every string that
+// matches the LIKE pattern: such a string is greater than or equal to lo
+// and, if hi is not empty, lower than hi. The range is computed from the
+// characters of the pattern preceding its first wildcard and can contain
+// strings that don't match the pattern.
+// Since matching is case-insensitive, lo uses the upper-case and hi the
+// lower-case version of these characters. The prefix stops at the first
+// character whose case variants can't be expressed that way.
+// ok is false if the pattern starts with a wildcard.
+func LikeRange(pattern string) (lo, hi string, ok bool) {
+	var l, h []byte
+	var prevEscape bool
+
+	for len(pattern) > 0 {
+		r, size := utf8.DecodeRuneInString(pattern)
+		if r == utf8.RuneError && size == 1 {
+			break
+		}
+
+		if !prevEscape {
+			if r == matchAll || r == matchOne {
+				break
+			}
+
+			if r == matchEsc {
+				prevEscape = true
+				pattern = pattern[size:]
+				continue
+			}
+		}
+
+		min, max, ok := foldRange(r)
+		if !ok {
+			break
+		}
+
+		l = append(l, string(min)...)
+		h = append(h, string(max)...)
+		prevEscape = false
+		pattern = pattern[size:]
+	}
+
+	if len(l) == 0 {
+		return "", "", false
+	}
+
+	// hi is the smallest string greater than all the strings starting with h.
+	for len(h) > 0 && h[len(h)-1] == 0xFF {
+		h = h[:len(h)-1]
+	}
+	if len(h) > 0 {
+		h[len(h)-1]++
+	}
+
+	return string(l), string(h), true
+}
+
+// foldRange returns the smallest and the greatest of the runes matching r
+// case-insensitively. ok is false if some of them are not ASCII, in which case
+// strings starting with them can't be ordered like strings starting with r.
+func foldRange(r rune) (min, max rune, ok bool) {
+	min, max = r, r
+
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f >= utf8.RuneSelf || r >= utf8.RuneSelf {
+			return 0, 0, false
+		}
+
+		if f < min {
+			min = f
+		}
+		if f > max {
+			max = f
+		}
+	}
+
+	return min, max, true
+}
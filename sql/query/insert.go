@@ -11,9 +11,13 @@ import (
 
 // InsertStmt is a DSL that allows creating a full Insert query.
 type InsertStmt struct {
-	TableName  string
-	FieldNames []string
-	Values     expr.LiteralExprList
+	TableName string
+
+	// Paths is the optional field list of the statement.
+	// If set, each value is a list of expressions assigned
+	// to the paths in the same order.
+	Paths  []document.Path
+	Values expr.LiteralExprList
 }
 
 // IsReadOnly always returns false. It implements the Statement interface.
@@ -21,9 +25,21 @@ func (stmt InsertStmt) IsReadOnly() bool {
 	return false
 }
 
+// Tables returns the name of the table the documents are inserted in.
+// It implements the Statement interface.
+func (stmt InsertStmt) Tables() []string {
+	return []string{stmt.TableName}
+}
+
 // Run the Insert statement in the given transaction.
 // It implements the Statement interface.
 func (stmt InsertStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
+	return stmt.Insert(tx, args, nil)
+}
+
+// Insert runs the statement like Run and, if fn is not nil, calls it
+// with the key and the stored content of every inserted document.
+func (stmt InsertStmt) Insert(tx *database.Transaction, args []expr.Param, fn func(key []byte, d document.Document) error) (Result, error) {
 	var res Result
 
 	if stmt.TableName == "" {
@@ -35,6 +51,9 @@ func (stmt InsertStmt) Run(tx *database.Transaction, args []expr.Param) (Result,
 	}
 
 	t, err := tx.GetTable(stmt.TableName)
+	if errors.Is(err, database.ErrTableNotFound) && tx.DB().AutoCreateTables {
+		t, err = autoCreateTable(tx, stmt.TableName)
+	}
 	if err != nil {
 		return res, err
 	}
@@ -44,73 +63,180 @@ func (stmt InsertStmt) Run(tx *database.Transaction, args []expr.Param) (Result,
 		Params: args,
 	}
 
-	if len(stmt.FieldNames) > 0 {
-		return stmt.insertExprList(t, stack)
+	if len(stmt.Paths) > 0 {
+		return stmt.insertExprList(t, stack, fn)
 	}
 
-	return stmt.insertDocuments(t, stack)
+	return stmt.insertDocuments(t, stack, fn)
 }
 
-func (stmt InsertStmt) insertDocuments(t *database.Table, stack expr.EvalStack) (Result, error) {
-	var res Result
+// autoCreateTable creates a table without constraints and returns it.
+// If the table was created in the meantime, it is returned as is.
+func autoCreateTable(tx *database.Transaction, tableName string) (*database.Table, error) {
+	err := tx.CreateTable(tableName, nil)
+	if err != nil && err != database.ErrTableAlreadyExists {
+		return nil, err
+	}
+
+	return tx.GetTable(tableName)
+}
+
+func (stmt InsertStmt) insertDocuments(t *database.Table, stack expr.EvalStack, fn func(key []byte, d document.Document) error) (Result, error) {
+	docs := make([]document.Document, 0, len(stmt.Values))
 
 	for _, e := range stmt.Values {
 		v, err := e.Eval(stack)
 		if err != nil {
-			return res, err
+			return Result{}, err
 		}
 
 		if v.Type != document.DocumentValue {
-			return res, fmt.Errorf("expected document, got %s", v.Type)
+			return Result{}, fmt.Errorf("expected document, got %s", v.Type)
 		}
 
-		res.LastInsertKey, err = t.Insert(v.V.(document.Document))
-		if err != nil {
-			return res, err
-		}
-
-		res.RowsAffected++
+		docs = append(docs, v.V.(document.Document))
 	}
 
-	return res, nil
+	return bulkInsert(t, docs, fn)
 }
 
-func (stmt InsertStmt) insertExprList(t *database.Table, stack expr.EvalStack) (Result, error) {
-	var res Result
+func (stmt InsertStmt) insertExprList(t *database.Table, stack expr.EvalStack, fn func(key []byte, d document.Document) error) (Result, error) {
+	docs := make([]document.Document, 0, len(stmt.Values))
 
 	// iterate over all of the documents (r1, r2, r3, ...)
 	for _, e := range stmt.Values {
 		var fb document.FieldBuffer
 
-		v, err := e.Eval(stack)
+		v, err := stmt.evalExprList(t, e, stack)
 		if err != nil {
-			return res, err
+			return Result{}, err
 		}
 
 		// each document must be a list of expressions
 		// (e1, e2, e3, ...) or [e1, e2, e2, ....]
 		if v.Type != document.ArrayValue {
-			return res, fmt.Errorf("expected array, got %s", v.Type)
+			return Result{}, fmt.Errorf("expected array, got %s", v.Type)
 		}
 
-		// iterate over each value
-		v.V.(document.Array).Iterate(func(i int, v document.Value) error {
-			// get the field name
-			fieldName := stmt.FieldNames[i]
-
-			// Assign the value to the field and add it to the document
-			fb.Add(fieldName, v)
+		a := v.V.(document.Array)
+		n, err := document.ArrayLength(a)
+		if err != nil {
+			return Result{}, err
+		}
+		if n != len(stmt.Paths) {
+			return Result{}, fmt.Errorf("%d values for %d fields", n, len(stmt.Paths))
+		}
 
+		// assign each value to its path and add it to the document
+		err = a.Iterate(func(i int, v document.Value) error {
+			setInsertedValue(&fb, stmt.Paths[i], v)
 			return nil
 		})
+		if err != nil {
+			return Result{}, err
+		}
+
+		docs = append(docs, &fb)
+	}
+
+	return bulkInsert(t, docs, fn)
+}
+
+// bulkInsert inserts docs in one batch, so that index entries are written together,
+// then calls fn, if not nil, with every inserted document.
+func bulkInsert(t *database.Table, docs []document.Document, fn func(key []byte, d document.Document) error) (Result, error) {
+	var res Result
 
-		res.LastInsertKey, err = t.Insert(&fb)
+	keys, err := t.BulkInsert(docs)
+	if err != nil {
+		return res, err
+	}
+
+	for _, key := range keys {
+		err = callWithInsertedDocument(t, key, fn)
 		if err != nil {
 			return res, err
 		}
 
+		res.LastInsertKey = key
 		res.RowsAffected++
 	}
 
 	return res, nil
 }
+
+// evalExprList evaluates a list of values to insert.
+// DEFAULT markers are replaced by the default value of their field.
+func (stmt InsertStmt) evalExprList(t *database.Table, e expr.Expr, stack expr.EvalStack) (document.Value, error) {
+	list, ok := e.(expr.LiteralExprList)
+	if !ok {
+		return e.Eval(stack)
+	}
+
+	values := make(document.ValueBuffer, len(list))
+	for i, e := range list {
+		var err error
+
+		if _, ok := e.(expr.Default); ok && i < len(stmt.Paths) {
+			values[i], err = defaultValue(t, stmt.Paths[i])
+		} else {
+			values[i], err = e.Eval(stack)
+		}
+		if err != nil {
+			return document.Value{}, err
+		}
+	}
+
+	return document.NewArrayValue(values), nil
+}
+
+// defaultValue returns the default value of the field at path p.
+func defaultValue(t *database.Table, p document.Path) (document.Value, error) {
+	info, err := t.Info()
+	if err != nil {
+		return document.Value{}, err
+	}
+
+	for _, fc := range info.FieldConstraints {
+		if fc.Path.IsEqual(p) && fc.HasDefaultValue() {
+			return fc.DefaultValue, nil
+		}
+	}
+
+	return document.Value{}, fmt.Errorf("field %q has no default value", p)
+}
+
+// setInsertedValue sets v at path p of fb, creating the intermediate documents.
+// The paths of the field list never overlap, so intermediate values are always
+// documents created by this function.
+func setInsertedValue(fb *document.FieldBuffer, p document.Path, v document.Value) {
+	if len(p) == 1 {
+		fb.Add(p[0].FieldName, v)
+		return
+	}
+
+	var child *document.FieldBuffer
+	cur, err := fb.GetByField(p[0].FieldName)
+	if err == nil {
+		child = cur.V.(*document.FieldBuffer)
+	} else {
+		child = document.NewFieldBuffer()
+		fb.Add(p[0].FieldName, document.NewDocumentValue(child))
+	}
+
+	setInsertedValue(child, p[1:], v)
+}
+
+// callWithInsertedDocument calls fn, if not nil, with the document stored under key.
+func callWithInsertedDocument(t *database.Table, key []byte, fn func(key []byte, d document.Document) error) error {
+	if fn == nil {
+		return nil
+	}
+
+	d, err := t.GetDocument(key)
+	if err != nil {
+		return err
+	}
+
+	return fn(key, d)
+}
@@ -3,7 +3,9 @@ package query_test
 import (
 	"bytes"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/genjidb/genji"
@@ -30,6 +32,9 @@ func TestInsertStmt(t *testing.T) {
 		{"Values / Invalid params", "INSERT INTO test (a, b, c) VALUES ('d', ?)", true, "", []interface{}{'e'}},
 		{"Values / List", `INSERT INTO test (a, b, c) VALUES ("a", 'b', [1, 2, 3])`, false, `{"pk()":1,"a":"a","b":"b","c":[1,2,3]}`, nil},
 		{"Values / Document", `INSERT INTO test (a, b, c) VALUES ("a", 'b', {c: 1, d: c + 1})`, false, `{"pk()":1,"a":"a","b":"b","c":{"c":1,"d":2}}`, nil},
+		{"Values / Nested paths", `INSERT INTO test (a, meta.created, meta.author) VALUES ('a', 10, 'foo')`, false, `{"pk()":1,"a":"a","meta":{"created":10,"author":"foo"}}`, nil},
+		{"Values / Nested paths with params", `INSERT INTO test (a, b.c.d, b.e) VALUES (?, ?, ?)`, false, `{"pk()":1,"a":"a","b":{"c":{"d":1},"e":true}}`, []interface{}{"a", 1, true}},
+		{"Values / Too few values", `INSERT INTO test (a, b) VALUES ('a')`, true, ``, nil},
 		{"Documents", "INSERT INTO test VALUES {a: 'a', b: 2.3, c: 1 = 1}", false, `{"pk()":1,"a":"a","b":2.3,"c":true}`, nil},
 		{"Documents / Positional Params", "INSERT INTO test VALUES {a: ?, b: 2.3, c: ?}", false, `{"pk()":1,"a":"a","b":2.3,"c":true}`, []interface{}{"a", true}},
 		{"Documents / Named Params", "INSERT INTO test VALUES {a: $a, b: 2.3, c: $c}", false, `{"pk()":1,"a":1,"b":2.3,"c":true}`, []interface{}{sql.Named("c", true), sql.Named("a", 1)}},
@@ -133,6 +138,32 @@ func TestInsertStmt(t *testing.T) {
 		require.JSONEq(t, `{"a": "a", "b-b": "b"}`, buf.String())
 	})
 
+	t.Run("with default values", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test (a INTEGER, b TEXT DEFAULT 'foo', c.d DOUBLE DEFAULT 10)")
+		require.NoError(t, err)
+
+		err = db.Exec(`INSERT INTO test (a, b, c.d) VALUES (1, DEFAULT, DEFAULT), (2, 'bar', 1.5)`)
+		require.NoError(t, err)
+
+		res, err := db.Query("SELECT * FROM test")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		err = res.Close()
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"a": 1, "b": "foo", "c": {"d": 10.0}}, {"a": 2, "b": "bar", "c": {"d": 1.5}}]`, buf.String())
+
+		// a has no default value
+		err = db.Exec(`INSERT INTO test (a, b) VALUES (DEFAULT, 'bar')`)
+		require.EqualError(t, err, `field "a" has no default value`)
+	})
+
 	t.Run("with types constraints", func(t *testing.T) {
 		// This test ensures that we can insert data into every supported types.
 		db, err := genji.Open(":memory:")
@@ -261,3 +292,85 @@ func TestInsertStmt(t *testing.T) {
 		}
 	})
 }
+
+func TestInsertAutoCreateTables(t *testing.T) {
+	count := func(t *testing.T, db *genji.DB, table string) int64 {
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM " + table)
+		require.NoError(t, err)
+		v, err := d.GetByField("n")
+		require.NoError(t, err)
+		return v.V.(int64)
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("INSERT INTO test (a) VALUES (1)")
+		require.True(t, errors.Is(err, database.ErrTableNotFound))
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		db, err := genji.Open(":memory:", genji.WithAutoCreateTables())
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("INSERT INTO test (a) VALUES (1); INSERT INTO test VALUES {a: 2}")
+		require.NoError(t, err)
+		require.EqualValues(t, 2, count(t, db, "test"))
+
+		d, err := db.QueryDocument("INSERT INTO other (a) VALUES (3) RETURNING a")
+		require.NoError(t, err)
+		v, err := d.GetByField("a")
+		require.NoError(t, err)
+		require.EqualValues(t, 3, v.V)
+
+		for _, q := range []string{
+			"SELECT * FROM missing",
+			"UPDATE missing SET a = 1",
+			"DELETE FROM missing",
+		} {
+			err = db.Exec(q)
+			require.True(t, errors.Is(err, database.ErrTableNotFound), q)
+		}
+	})
+
+	t.Run("Explicit transaction", func(t *testing.T) {
+		db, err := genji.Open(":memory:", genji.WithAutoCreateTables())
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("BEGIN; INSERT INTO test (a) VALUES (1); ROLLBACK")
+		require.NoError(t, err)
+		err = db.Exec("SELECT * FROM test")
+		require.True(t, errors.Is(err, database.ErrTableNotFound))
+
+		err = db.Exec("BEGIN; INSERT INTO test (a) VALUES (1); INSERT INTO test (a) VALUES (2); COMMIT")
+		require.NoError(t, err)
+		require.EqualValues(t, 2, count(t, db, "test"))
+	})
+
+	t.Run("Concurrent creators", func(t *testing.T) {
+		db, err := genji.Open(":memory:", genji.WithAutoCreateTables())
+		require.NoError(t, err)
+		defer db.Close()
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- db.Exec("INSERT INTO test (a) VALUES (?)", i)
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			require.NoError(t, err)
+		}
+		require.EqualValues(t, 10, count(t, db, "test"))
+	})
+}
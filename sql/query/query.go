@@ -3,6 +3,9 @@ package query
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
@@ -17,6 +20,13 @@ var ErrResultClosed = errors.New("result already closed")
 // Results are returned as streams.
 type Query struct {
 	Statements []Statement
+
+	// PositionalParams is the number of positional parameters referenced by the statements.
+	PositionalParams int
+	// NamedParams lists the names of the named parameters referenced by the statements,
+	// in order of appearance.
+	NamedParams []string
+
 	tx         *database.Transaction
 	autoCommit bool
 }
@@ -114,15 +124,141 @@ func (q Query) Exec(tx *database.Transaction, args []expr.Param) (*Result, error
 	return &res, nil
 }
 
+// BindParams validates that args match the positional parameters of the query
+// and returns them as a list of params that can be passed to Run or Exec.
+func (q Query) BindParams(args ...document.Value) ([]expr.Param, error) {
+	if len(q.NamedParams) > 0 {
+		return nil, fmt.Errorf("query expects named parameters %s", formatNamedParams(q.NamedParams))
+	}
+
+	if len(args) < q.PositionalParams {
+		return nil, fmt.Errorf("missing positional parameter %d: query expects %d parameters, got %d", len(args)+1, q.PositionalParams, len(args))
+	}
+
+	if len(args) > q.PositionalParams {
+		return nil, fmt.Errorf("too many parameters: query expects %d parameters, got %d", q.PositionalParams, len(args))
+	}
+
+	params := make([]expr.Param, len(args))
+	for i, v := range args {
+		params[i].Value = v.V
+	}
+
+	return params, nil
+}
+
+// BindNamedParams validates that params contain every named parameter of the query,
+// and nothing else, and returns them as a list of params that can be passed to Run or Exec.
+func (q Query) BindNamedParams(params map[string]document.Value) ([]expr.Param, error) {
+	if q.PositionalParams > 0 {
+		return nil, fmt.Errorf("query expects %d positional parameters", q.PositionalParams)
+	}
+
+	var missing []string
+	for _, name := range q.NamedParams {
+		if _, ok := params[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing named parameters %s", formatNamedParams(missing))
+	}
+
+	if len(params) > len(q.NamedParams) {
+		var extra []string
+		for name := range params {
+			if !containsString(q.NamedParams, name) {
+				extra = append(extra, name)
+			}
+		}
+		sort.Strings(extra)
+		return nil, fmt.Errorf("unexpected named parameters %s", formatNamedParams(extra))
+	}
+
+	ps := make([]expr.Param, 0, len(q.NamedParams))
+	for _, name := range q.NamedParams {
+		ps = append(ps, expr.Param{Name: name, Value: params[name].V})
+	}
+
+	return ps, nil
+}
+
+func formatNamedParams(names []string) string {
+	var sb strings.Builder
+
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(expr.NamedParam(name).String())
+	}
+
+	return sb.String()
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
+
 // New creates a new query with the given statements.
 func New(statements ...Statement) Query {
 	return Query{Statements: statements}
 }
 
+// IsReadOnly returns true if none of the statements of the query writes to the database.
+func (q Query) IsReadOnly() bool {
+	for _, stmt := range q.Statements {
+		if !stmt.IsReadOnly() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Tables returns the names of the tables read or modified by the statements of the query,
+// sorted and without duplicates. Tables only known when statements run are not returned.
+func (q Query) Tables() []string {
+	var names []string
+	for _, stmt := range q.Statements {
+		names = append(names, stmt.Tables()...)
+	}
+
+	return uniqueSorted(names)
+}
+
+// uniqueSorted sorts names and removes the duplicates.
+func uniqueSorted(names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+
+	sort.Strings(names)
+
+	j := 0
+	for i := 1; i < len(names); i++ {
+		if names[i] != names[j] {
+			j++
+			names[j] = names[i]
+		}
+	}
+
+	return names[:j+1]
+}
+
 // A Statement represents a unique action that can be executed against the database.
 type Statement interface {
 	Run(*database.Transaction, []expr.Param) (Result, error)
 	IsReadOnly() bool
+	// Tables returns the names of the tables read or modified by the statement,
+	// without duplicates. It returns nil if they are only known when the statement runs.
+	Tables() []string
 }
 
 // Result of a query.
@@ -132,6 +268,14 @@ type Result struct {
 	LastInsertKey []byte
 	Tx            *database.Transaction
 	closed        bool
+
+	// functions called once the result is closed.
+	onClose []func()
+}
+
+// OnClose registers fn to be called once the result is closed.
+func (r *Result) OnClose(fn func()) {
+	r.onClose = append(r.onClose, fn)
 }
 
 // Close the result stream.
@@ -157,6 +301,10 @@ func (r *Result) Close() (err error) {
 		}
 	}
 
+	for _, fn := range r.onClose {
+		fn()
+	}
+
 	return err
 }
 
@@ -174,6 +322,6 @@ func whereClause(e expr.Expr, stack expr.EvalStack) func(d document.Document) (b
 			return false, err
 		}
 
-		return v.IsTruthy()
+		return document.IsTruthy(v)
 	}
 }
@@ -0,0 +1,194 @@
+package query_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryBindParams(t *testing.T) {
+	q, err := parser.ParseQuery("SELECT * FROM test WHERE a = ? AND b > ?; SELECT ?")
+	require.NoError(t, err)
+	require.Equal(t, 3, q.PositionalParams)
+
+	t.Run("OK", func(t *testing.T) {
+		params, err := q.BindParams(document.NewIntegerValue(1), document.NewTextValue("foo"), document.NewBoolValue(true))
+		require.NoError(t, err)
+		require.Len(t, params, 3)
+	})
+
+	t.Run("Too few", func(t *testing.T) {
+		_, err := q.BindParams(document.NewIntegerValue(1))
+		require.EqualError(t, err, "missing positional parameter 2: query expects 3 parameters, got 1")
+	})
+
+	t.Run("Too many", func(t *testing.T) {
+		_, err := q.BindParams(document.NewIntegerValue(1), document.NewIntegerValue(2), document.NewIntegerValue(3), document.NewIntegerValue(4))
+		require.EqualError(t, err, "too many parameters: query expects 3 parameters, got 4")
+	})
+
+	t.Run("Named", func(t *testing.T) {
+		_, err := q.BindNamedParams(map[string]document.Value{"a": document.NewIntegerValue(1)})
+		require.EqualError(t, err, "query expects 3 positional parameters")
+	})
+}
+
+func TestQueryBindNamedParams(t *testing.T) {
+	q, err := parser.ParseQuery("SELECT * FROM test WHERE a = $a AND b > $b OR a = $a")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, q.NamedParams)
+
+	t.Run("OK", func(t *testing.T) {
+		params, err := q.BindNamedParams(map[string]document.Value{
+			"a": document.NewIntegerValue(1),
+			"b": document.NewTextValue("foo"),
+		})
+		require.NoError(t, err)
+		require.Len(t, params, 2)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := q.BindNamedParams(map[string]document.Value{
+			"a": document.NewIntegerValue(1),
+		})
+		require.EqualError(t, err, "missing named parameters $b")
+	})
+
+	t.Run("Extra", func(t *testing.T) {
+		_, err := q.BindNamedParams(map[string]document.Value{
+			"a": document.NewIntegerValue(1),
+			"b": document.NewIntegerValue(2),
+			"d": document.NewIntegerValue(3),
+			"c": document.NewIntegerValue(4),
+		})
+		require.EqualError(t, err, "unexpected named parameters $c, $d")
+	})
+
+	t.Run("Positional", func(t *testing.T) {
+		_, err := q.BindParams(document.NewIntegerValue(1))
+		require.EqualError(t, err, "query expects named parameters $a, $b")
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE test; INSERT INTO test (a, b) VALUES (1, 'foo'), (2, 'bar')")
+		require.NoError(t, err)
+
+		params, err := q.BindNamedParams(map[string]document.Value{
+			"a": document.NewIntegerValue(1),
+			"b": document.NewTextValue("a"),
+		})
+		require.NoError(t, err)
+
+		res, err := q.Run(context.Background(), db.DB, params)
+		require.NoError(t, err)
+		defer res.Close()
+
+		n, err := res.Count()
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+	})
+}
+
+func TestQueryReturning(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		table    string
+	}{
+		{"Insert", "INSERT INTO test (a, b) VALUES (4, 'd'), (5, 'e') RETURNING pk(), a",
+			`[{"pk()": 4, "a": 4.0}, {"pk()": 5, "a": 5.0}]`,
+			`[{"a": 1.0, "b": "a"}, {"a": 2.0, "b": "b"}, {"a": 3.0, "b": "c"}, {"a": 4.0, "b": "d"}, {"a": 5.0, "b": "e"}]`},
+		{"Update", "UPDATE test SET a = a + 10 WHERE a > 1 RETURNING pk() AS id, a",
+			`[{"id": 2, "a": 12.0}, {"id": 3, "a": 13.0}]`,
+			`[{"a": 1.0, "b": "a"}, {"a": 12.0, "b": "b"}, {"a": 13.0, "b": "c"}]`},
+		{"Delete", "DELETE FROM test WHERE b != 'b' RETURNING *",
+			`[{"a": 1.0, "b": "a"}, {"a": 3.0, "b": "c"}]`,
+			`[{"a": 2.0, "b": "b"}]`},
+		{"No match", "DELETE FROM test WHERE a > 10 RETURNING *",
+			`[]`,
+			`[{"a": 1.0, "b": "a"}, {"a": 2.0, "b": "b"}, {"a": 3.0, "b": "c"}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := genji.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			err = db.Exec("CREATE TABLE test; INSERT INTO test (a, b) VALUES (1, 'a'), (2, 'b'), (3, 'c')")
+			require.NoError(t, err)
+
+			res, err := db.Query(test.query)
+			require.NoError(t, err)
+			var buf bytes.Buffer
+			err = document.IteratorToJSONArray(&buf, res)
+			require.NoError(t, err)
+			require.NoError(t, res.Close())
+			require.JSONEq(t, test.expected, buf.String())
+
+			res, err = db.Query("SELECT * FROM test")
+			require.NoError(t, err)
+			defer res.Close()
+			buf.Reset()
+			err = document.IteratorToJSONArray(&buf, res)
+			require.NoError(t, err)
+			require.JSONEq(t, test.table, buf.String())
+		})
+	}
+}
+
+func TestQueryIntrospection(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		readOnly bool
+		tables   []string
+	}{
+		{"SELECT", "SELECT * FROM foo WHERE a > 1", true, []string{"foo"}},
+		{"SELECT/No table", "SELECT 1 + 1", true, nil},
+		{"SELECT/Index", "SELECT * FROM foo WHERE a = 1 ORDER BY a", true, []string{"foo"}},
+		{"SELECT/INTO", "SELECT * INTO bar FROM foo", false, []string{"bar", "foo"}},
+		{"SELECT/CTE", "WITH t AS (SELECT * FROM foo) SELECT * FROM t", true, []string{"foo"}},
+		{"SELECT/LATERAL", "SELECT * FROM foo, LATERAL (SELECT * FROM bar WHERE bar.a = foo.a) AS b", true, []string{"bar", "foo"}},
+		{"INSERT", "INSERT INTO foo (a) VALUES (1)", false, []string{"foo"}},
+		{"INSERT/RETURNING", "INSERT INTO foo (a) VALUES (1) RETURNING a", false, []string{"foo"}},
+		{"UPDATE", "UPDATE foo SET a = 1 WHERE b = 2", false, []string{"foo"}},
+		{"DELETE", "DELETE FROM foo WHERE b = 2", false, []string{"foo"}},
+		{"CREATE TABLE", "CREATE TABLE foo", false, []string{"foo"}},
+		{"CREATE INDEX", "CREATE INDEX idx ON foo (a)", false, []string{"foo"}},
+		{"DROP TABLE", "DROP TABLE foo", false, []string{"foo"}},
+		{"DROP INDEX", "DROP INDEX idx", false, nil},
+		{"ALTER TABLE", "ALTER TABLE foo RENAME TO bar", false, []string{"bar", "foo"}},
+		{"TRUNCATE", "TRUNCATE TABLE foo", false, []string{"foo"}},
+		{"REINDEX", "REINDEX foo", false, nil},
+		{"EXPLAIN", "EXPLAIN DELETE FROM foo", true, []string{"foo"}},
+		{"DESCRIBE", "DESCRIBE foo", true, []string{"foo"}},
+		{"SHOW TABLES", "SHOW TABLES", true, nil},
+		{"Multiple statements", "SELECT * FROM foo; DELETE FROM bar; SELECT * FROM bar", false, []string{"bar", "foo"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := parser.ParseQuery(test.query)
+			require.NoError(t, err)
+
+			require.Equal(t, test.readOnly, q.IsReadOnly())
+			require.Equal(t, test.tables, q.Tables())
+
+			if len(q.Statements) == 1 {
+				require.Equal(t, test.readOnly, q.Statements[0].IsReadOnly())
+				require.ElementsMatch(t, test.tables, q.Statements[0].Tables())
+			}
+		})
+	}
+}
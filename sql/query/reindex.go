@@ -17,6 +17,12 @@ func (stmt ReIndexStmt) IsReadOnly() bool {
 	return false
 }
 
+// Tables returns nil, whether the statement reindexes a table, an index or
+// all the tables is only known when it runs. It implements the Statement interface.
+func (stmt ReIndexStmt) Tables() []string {
+	return nil
+}
+
 // Run runs the Reindex statement in the given transaction.
 // It implements the Statement interface.
 func (stmt ReIndexStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
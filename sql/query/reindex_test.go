@@ -42,6 +42,22 @@ func TestReIndex(t *testing.T) {
 			`)
 			require.NoError(t, err)
 
+			// empty the indexes, which are filled when created
+			err = db.Update(func(tx *genji.Tx) error {
+				idxList, err := tx.ListIndexes()
+				require.NoError(t, err)
+
+				for _, cfg := range idxList {
+					idx, err := tx.GetIndex(cfg.IndexName)
+					require.NoError(t, err)
+					err = idx.Truncate()
+					require.NoError(t, err)
+				}
+
+				return nil
+			})
+			require.NoError(t, err)
+
 			err = db.Exec(test.query)
 			if test.fails {
 				require.Error(t, err)
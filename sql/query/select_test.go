@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
 	"testing"
 
 	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/database"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/memoryengine"
 	"github.com/stretchr/testify/require"
 )
 
@@ -49,6 +54,12 @@ func TestSelectStmt(t *testing.T) {
 		{"With IN op", "SELECT color FROM test WHERE color IN ['red', 'purple'] ORDER BY k", false, `[{"color":"red"}]`, nil},
 		{"With IN op on PK", "SELECT color FROM test WHERE k IN [1.1, 1.0] ORDER BY k", false, `[{"color":"red"}]`, nil},
 		{"With NOT IN op", "SELECT color FROM test WHERE color NOT IN ['red', 'purple'] ORDER BY k", false, `[{"color":"blue"}]`, nil},
+		{"With IN op and tuple", "SELECT color FROM test WHERE color IN ('red', 'purple') ORDER BY k", false, `[{"color":"red"}]`, nil},
+		{"With IN op and tuples", "SELECT k FROM test WHERE (color, size) IN (('red', 10), ('blue', 20)) ORDER BY k", false, `[{"k":1}]`, nil},
+		{"With IN op on integers", "SELECT k FROM test WHERE weight IN (100, 200) ORDER BY k", false, `[{"k":2},{"k":3}]`, nil},
+		{"With CONTAINS_KEY", "SELECT k FROM test WHERE CONTAINS_KEY('weight') ORDER BY k", false, `[{"k":2},{"k":3}]`, nil},
+		{"With CONTAINS_KEY and param", "SELECT k FROM test WHERE CONTAINS_KEY(?) = false ORDER BY k", false, `[{"k":1}]`, []interface{}{"weight"}},
+		{"With tuple comparison", "SELECT k FROM test WHERE (size, k) > (10, 1) ORDER BY k", false, `[{"k":2}]`, nil},
 		{"With field comparison", "SELECT * FROM test WHERE color < shape", false, `[{"k":1,"color":"red","size":10,"shape":"square"}]`, nil},
 		{"With group by", "SELECT color FROM test GROUP BY color", false, `[{"color":"red"},{"color":"blue"},{"color":null}]`, nil},
 		{"With group by and count", "SELECT COUNT(k) FROM test GROUP BY size", false, `[{"COUNT(k)":2},{"COUNT(k)":1}]`, nil},
@@ -77,6 +88,9 @@ func TestSelectStmt(t *testing.T) {
 		{"With pk()", "SELECT pk(), color FROM test", false, `[{"pk()":1,"color":"red"},{"pk()":2,"color":"blue"},{"pk()":3,"color":null}]`, []interface{}{sql.Named("a", "red"), sql.Named("d", 100)}},
 		{"With pk in cond, gt", "SELECT * FROM test WHERE k > 0 AND weight = 100", false, `[{"k":2,"color":"blue","size":10,"weight":100,"k":2}]`, nil},
 		{"With pk in cond, =", "SELECT * FROM test WHERE k = 2.0 AND weight = 100", false, `[{"k":2,"color":"blue","size":10,"weight":100,"k":2}]`, nil},
+		{"With table alias", "SELECT t.color FROM test AS t WHERE t.size = 10 ORDER BY k", false, `[{"t.color":"red"},{"t.color":"blue"}]`, nil},
+		{"With table alias shadowing a field", "SELECT color.color FROM test AS color WHERE color.size = 10 ORDER BY k", false, `[{"color.color":"red"},{"color.color":"blue"}]`, nil},
+		{"With table alias without AS", "SELECT t.k, pk() FROM test t WHERE t.height = 100", false, `[{"t.k":3,"pk()":3}]`, nil},
 		{"With count", "SELECT COUNT(k) FROM test", false, `[{"COUNT(k)": 3}]`, nil},
 		{"With count wildcard", "SELECT COUNT(*) FROM test", false, `[{"COUNT(*)": 3}]`, nil},
 		{"With multiple counts", "SELECT COUNT(k), COUNT(color) FROM test", false, `[{"COUNT(k)": 3, "COUNT(color)": 2}]`, nil},
@@ -261,6 +275,267 @@ func TestSelectStmt(t *testing.T) {
 	})
 }
 
+func TestSelectTypeMismatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		strict   bool
+	}{
+		{"Comparison", "SELECT a FROM test WHERE a > 10", `[{"a":20},{"a":15.5}]`, false},
+		{"Equality", "SELECT a FROM test WHERE a = 'x'", `[{"a":"x"}]`, false},
+		{"Quantified comparison", "SELECT a FROM test WHERE a > ANY [10, 100]", `[{"a":20},{"a":15.5}]`, false},
+		{"Like", "SELECT a FROM test WHERE a LIKE 'x%'", `[{"a":"x"}]`, false},
+		{"Not like", "SELECT a FROM test WHERE a NOT LIKE 'x%'", `[]`, false},
+		{"Regexp", "SELECT a FROM test WHERE a REGEXP '^X$' OR a REGEXP '(?i)^X$'", `[{"a":"x"}]`, false},
+		{"Not regexp", "SELECT a FROM test WHERE a NOT REGEXP 'x'", `[]`, false},
+		// numbers inserted in a field without type constraint are stored as doubles.
+		{"Short-circuit", "SELECT a FROM test WHERE typeof(a) = 'double' AND a > 10", `[{"a":20},{"a":15.5}]`, true},
+		{"Short-circuit with OR", "SELECT a FROM test WHERE typeof(a) != 'double' OR a > 10", `[{"a":"x"},{"a":20},{"a":[1]},{"a":15.5},{"a":true},{"a":null}]`, true},
+		{"Unknown function", "SELECT a FROM test WHERE foo(a) > 10", ``, false},
+	}
+
+	for _, strict := range []bool{false, true} {
+		var opts []genji.Option
+		if strict {
+			opts = append(opts, genji.WithStrictTypes())
+		}
+
+		db, err := genji.Open(":memory:", opts...)
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE test;
+			INSERT INTO test (a) VALUES (1), ('x'), (20), ([1]), (15.5), (true), (null);
+		`)
+		require.NoError(t, err)
+
+		for _, test := range tests {
+			t.Run(fmt.Sprintf("strict=%v/%s", strict, test.name), func(t *testing.T) {
+				var buf bytes.Buffer
+				st, err := db.Query(test.query)
+				if err == nil {
+					defer st.Close()
+					err = document.IteratorToJSONArray(&buf, st)
+				}
+				if test.expected == "" || (strict && !test.strict) {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				require.JSONEq(t, test.expected, buf.String())
+			})
+		}
+	}
+}
+
+func TestSelectUnnest(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		INSERT INTO test (k, a) VALUES (1, [1, 'foo', [2], {b: 3}]), (2, []);
+	`)
+	require.NoError(t, err)
+
+	arrayField := func(k int) document.Array {
+		d, err := db.QueryDocument("SELECT a FROM test WHERE k = ?", k)
+		require.NoError(t, err)
+		v, err := d.GetByField("a")
+		require.NoError(t, err)
+		return v.V.(document.Array)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+		params   []interface{}
+	}{
+		{"Array field", "SELECT * FROM UNNEST(?)", false, `[{"value":1},{"value":"foo"},{"value":[2]},{"value":{"b":3}}]`, []interface{}{arrayField(1)}},
+		{"Empty array field", "SELECT * FROM UNNEST(?)", false, `[]`, []interface{}{arrayField(2)}},
+		{"Empty array", "SELECT * FROM UNNEST([])", false, `[]`, nil},
+		{"Null", "SELECT * FROM UNNEST(NULL)", false, `[]`, nil},
+		{"With where and order by", "SELECT value * 2 AS v FROM UNNEST([3, 1, 2]) WHERE value > 1 ORDER BY value", false, `[{"v":4},{"v":6}]`, nil},
+		{"With alias", "SELECT u.value FROM UNNEST([1, 2]) AS u", false, `[{"u.value":1},{"u.value":2}]`, nil},
+		{"With distinct", "SELECT DISTINCT value FROM UNNEST([1, 2, 1])", false, `[{"value":1},{"value":2}]`, nil},
+		{"With aggregation", "SELECT COUNT(*), SUM(value) FROM UNNEST([1, 2, 3])", false, `[{"COUNT(*)":3,"SUM(value)":6}]`, nil},
+		{"Not an array", "SELECT * FROM UNNEST(1)", true, ``, nil},
+		{"Lateral", "SELECT k, u.value FROM test t, LATERAL (SELECT value FROM UNNEST(t.a) WHERE typeof(value) != 'document') u", false,
+			`[{"k":1,"u.value":1},{"k":1,"u.value":"foo"},{"k":1,"u.value":[2]}]`, nil},
+		{"Left join lateral", "SELECT k, u FROM test t LEFT JOIN LATERAL (SELECT * FROM UNNEST(t.a) WHERE value = 1) u ON true", false,
+			`[{"k":1,"u":{"value":1}},{"k":2,"u":null}]`, nil},
+		{"Correlated subquery", "SELECT k, (SELECT COUNT(*) FROM UNNEST(test.a)) AS n FROM test", false, `[{"k":1,"n":4},{"k":2,"n":0}]`, nil},
+		{"Field outside of a subquery", "SELECT * FROM UNNEST(a)", true, ``, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st, err := db.Query(test.query, test.params...)
+			if err == nil {
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				if !test.fails {
+					require.NoError(t, err)
+					require.JSONEq(t, test.expected, buf.String())
+					return
+				}
+			}
+
+			require.True(t, test.fails, "unexpected error: %v", err)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSelectPivot(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE orders;
+		CREATE INDEX idx_cnt ON orders(cnt);
+		INSERT INTO orders (category, status, cnt) VALUES
+			('books', 'pending', 2), ('books', 'shipped', 5), ('books', 'pending', 1),
+			('games', 'shipped', 3), ('games', 'cancelled', 4), ('games', 'returned', 7),
+			('toys', 'returned', 1), ('toys', 'returned', 2);
+		INSERT INTO orders (category, cnt) VALUES ('tools', 1);
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+		params   []interface{}
+	}{
+		{"Sum", "SELECT * FROM orders PIVOT (SUM(cnt) FOR status IN ('pending', 'shipped', 'cancelled'))", false,
+			`[{"category":"books","pending":3,"shipped":5,"cancelled":null},{"category":"games","pending":null,"shipped":3,"cancelled":4},{"category":"toys","pending":null,"shipped":null,"cancelled":null},{"category":"tools","pending":null,"shipped":null,"cancelled":null}]`, nil},
+		{"Count", "SELECT * FROM orders PIVOT (COUNT(*) FOR status IN ('pending', 'shipped'))", false,
+			`[{"category":"books","cnt":2,"pending":1,"shipped":null},{"category":"books","cnt":5,"pending":null,"shipped":1},{"category":"books","cnt":1,"pending":1,"shipped":null},{"category":"games","cnt":3,"pending":null,"shipped":1},{"category":"games","cnt":4,"pending":null,"shipped":null},{"category":"games","cnt":7,"pending":null,"shipped":null},{"category":"toys","cnt":1,"pending":null,"shipped":null},{"category":"toys","cnt":2,"pending":null,"shipped":null},{"category":"tools","cnt":1,"pending":null,"shipped":null}]`, nil},
+		{"With params", "SELECT * FROM orders PIVOT (MAX(cnt) FOR status IN (?, ?))", false,
+			`[{"category":"books","pending":2,"shipped":5},{"category":"games","pending":null,"shipped":3},{"category":"toys","pending":null,"shipped":null},{"category":"tools","pending":null,"shipped":null}]`, []interface{}{"pending", "shipped"}},
+		{"With projection and where", "SELECT category, shipped FROM orders AS o PIVOT (SUM(cnt) FOR status IN ('shipped', 'cancelled')) WHERE cancelled > 0", false,
+			`[{"category":"games","shipped":3}]`, nil},
+		{"Where on an indexed field", "SELECT * FROM orders PIVOT (SUM(cnt) FOR status IN ('shipped')) WHERE cnt > 0", false,
+			`[]`, nil},
+		{"Not an aggregate function", "SELECT * FROM orders PIVOT (cnt FOR status IN ('pending'))", true, ``, nil},
+		{"Duplicate values", "SELECT * FROM orders PIVOT (SUM(cnt) FOR status IN ('pending', 'pending'))", true, ``, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st, err := db.Query(test.query, test.params...)
+			if err == nil {
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				if !test.fails {
+					require.NoError(t, err)
+					require.JSONEq(t, test.expected, buf.String())
+					return
+				}
+			}
+
+			require.True(t, test.fails, "unexpected error: %v", err)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSelectOrderByCollate(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		INSERT INTO test (k, name) VALUES (1, 'b'), (2, 'Z'), (3, 'a '), (4, 'B'), (5, 10), (6, 'A');
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+	}{
+		{"Binary", "SELECT k FROM test ORDER BY name", false, `[{"k":5},{"k":6},{"k":4},{"k":2},{"k":3},{"k":1}]`},
+		{"Explicit binary", "SELECT k FROM test ORDER BY name COLLATE BINARY", false, `[{"k":5},{"k":6},{"k":4},{"k":2},{"k":3},{"k":1}]`},
+		{"Nocase", "SELECT name FROM test WHERE k != 4 ORDER BY name COLLATE NOCASE", false, `[{"name":10},{"name":"A"},{"name":"a "},{"name":"b"},{"name":"Z"}]`},
+		{"Nocase desc", "SELECT name FROM test WHERE k < 3 OR k = 6 ORDER BY name COLLATE nocase DESC", false, `[{"name":"Z"},{"name":"b"},{"name":"A"}]`},
+		{"Unknown", "SELECT k FROM test ORDER BY name COLLATE foo", true, ``},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st, err := db.Query(test.query)
+			if err == nil {
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				if !test.fails {
+					require.NoError(t, err)
+					require.JSONEq(t, test.expected, buf.String())
+					return
+				}
+			}
+
+			require.True(t, test.fails, "unexpected error: %v", err)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSelectOrderByNulls(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		INSERT INTO test (k, a) VALUES (1, 2), (2, null), (3, 1), (4, 3);
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"Asc", "SELECT k FROM test ORDER BY a", `[{"k":2},{"k":3},{"k":1},{"k":4}]`},
+		{"Desc", "SELECT k FROM test ORDER BY a DESC", `[{"k":4},{"k":1},{"k":3},{"k":2}]`},
+		{"Asc nulls first", "SELECT k FROM test ORDER BY a ASC NULLS FIRST", `[{"k":2},{"k":3},{"k":1},{"k":4}]`},
+		{"Asc nulls last", "SELECT k FROM test ORDER BY a NULLS LAST", `[{"k":3},{"k":1},{"k":4},{"k":2}]`},
+		{"Desc nulls first", "SELECT k FROM test ORDER BY a DESC NULLS FIRST", `[{"k":2},{"k":4},{"k":1},{"k":3}]`},
+		{"Desc nulls last", "SELECT k FROM test ORDER BY a DESC NULLS LAST", `[{"k":4},{"k":1},{"k":3},{"k":2}]`},
+		{"With limit", "SELECT k FROM test ORDER BY a NULLS LAST LIMIT 2", `[{"k":3},{"k":1}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st, err := db.Query(test.query)
+			require.NoError(t, err)
+			defer st.Close()
+
+			var buf bytes.Buffer
+			err = document.IteratorToJSONArray(&buf, st)
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String())
+		})
+	}
+}
+
 func TestDistinct(t *testing.T) {
 	types := []struct {
 		name          string
@@ -335,3 +610,715 @@ func TestDistinct(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectMemoryBudget(t *testing.T) {
+	const n = 100000
+
+	db, err := genji.Open(":memory:", genji.WithMemoryBudget(64*1024), genji.WithSpillEngine(func() (engine.Engine, error) {
+		return memoryengine.NewEngine(), nil
+	}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Update(func(tx *genji.Tx) error {
+		err := tx.Exec("CREATE TABLE test")
+		if err != nil {
+			return err
+		}
+
+		tb, err := tx.GetTable("test")
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < n; i++ {
+			_, err = tb.Insert(document.NewFieldBuffer().
+				Add("a", document.NewIntegerValue(int64(i*7919%n))).
+				Add("b", document.NewTextValue("foo")))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	t.Run("Spill/ORDER BY", func(t *testing.T) {
+		for _, dir := range []string{"ASC", "DESC"} {
+			res, err := db.Query("SELECT a FROM test ORDER BY a " + dir)
+			require.NoError(t, err)
+
+			var count int64
+			err = res.Iterate(func(d document.Document) error {
+				var a int64
+				err := document.Scan(d, &a)
+				if err != nil {
+					return err
+				}
+
+				want := count
+				if dir == "DESC" {
+					want = n - count - 1
+				}
+				require.Equal(t, want, a)
+
+				count++
+				return nil
+			})
+			require.NoError(t, err)
+			require.NoError(t, res.Close())
+			require.EqualValues(t, n, count)
+		}
+	})
+
+	t.Run("Spill/DISTINCT", func(t *testing.T) {
+		res, err := db.Query("SELECT DISTINCT a FROM test")
+		require.NoError(t, err)
+		defer res.Close()
+
+		count, err := res.Count()
+		require.NoError(t, err)
+		require.Equal(t, n, count)
+	})
+
+	t.Run("Spill/WITH", func(t *testing.T) {
+		d, err := db.QueryDocument("WITH t AS (SELECT a FROM test) SELECT COUNT(*) FROM t WHERE a < 10 AND EXISTS (SELECT * FROM t u WHERE u.a = t.a + 1)")
+		require.NoError(t, err)
+
+		var count int
+		err = document.Scan(d, &count)
+		require.NoError(t, err)
+		require.Equal(t, 10, count)
+	})
+
+	t.Run("No spill engine", func(t *testing.T) {
+		spill := db.DB.SpillEngine
+		db.DB.SpillEngine = nil
+		defer func() { db.DB.SpillEngine = spill }()
+
+		for _, q := range []string{
+			"SELECT a FROM test ORDER BY a",
+			"SELECT DISTINCT a FROM test",
+			"WITH t AS (SELECT a FROM test) SELECT * FROM t WHERE EXISTS (SELECT * FROM t u WHERE u.a = t.a)",
+		} {
+			res, err := db.Query(q)
+			require.NoError(t, err)
+
+			_, err = res.Count()
+			require.Equal(t, database.ErrMemoryBudgetExceeded, err)
+			require.NoError(t, res.Close())
+		}
+
+		// small results fit in the budget.
+		res, err := db.Query("SELECT DISTINCT b FROM test ORDER BY b")
+		require.NoError(t, err)
+		defer res.Close()
+		count, err := res.Count()
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("No budget", func(t *testing.T) {
+		budget, spill := db.DB.MemoryBudget, db.DB.SpillEngine
+		db.DB.MemoryBudget, db.DB.SpillEngine = 0, nil
+		defer func() { db.DB.MemoryBudget, db.DB.SpillEngine = budget, spill }()
+
+		res, err := db.Query("SELECT DISTINCT a FROM test ORDER BY a")
+		require.NoError(t, err)
+		defer res.Close()
+		count, err := res.Count()
+		require.NoError(t, err)
+		require.Equal(t, n, count)
+	})
+}
+
+func TestSelectInto(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		INSERT INTO test (a, b) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz');
+	`)
+	require.NoError(t, err)
+
+	err = db.Exec("SELECT a, b AS c INTO copy FROM test WHERE a > 1 ORDER BY a DESC")
+	require.NoError(t, err)
+
+	st, err := db.Query("SELECT * FROM copy")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = document.IteratorToJSONArray(&buf, st)
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"a": 3, "c": "baz"}, {"a": 2, "c": "bar"}]`, buf.String())
+	require.NoError(t, st.Close())
+
+	// the target table must not exist
+	err = db.Exec("SELECT * INTO copy FROM test")
+	require.Equal(t, database.ErrTableAlreadyExists, err)
+}
+
+func TestSelectLikeWithIndex(t *testing.T) {
+	patterns := []string{"abc%", "ABC%", "ab_%", "abc", "%abc", "a%c", "日本%", "ask%", "z%"}
+
+	run := func(t *testing.T, db *genji.DB, pattern string) string {
+		res, err := db.Query("SELECT k FROM test WHERE a LIKE ? ORDER BY k", pattern)
+		require.NoError(t, err)
+		defer res.Close()
+
+		var buf bytes.Buffer
+		err = document.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	open := func(t *testing.T, schema string) *genji.DB {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+
+		err = db.Exec("CREATE TABLE test" + schema)
+		require.NoError(t, err)
+		if schema != "" {
+			err = db.Exec("CREATE INDEX idx_a ON test(a)")
+			require.NoError(t, err)
+		}
+
+		for i, a := range []interface{}{"abc", "ABCD", "aBcdef", "abd", "abb", "ab", "xabc", "日本語", "ASK", "ſk", 10, nil} {
+			// typed indexes only contain texts
+			if _, ok := a.(string); !ok && schema == "(a TEXT)" {
+				continue
+			}
+
+			err = db.Exec("INSERT INTO test (k, a) VALUES (?, ?)", i, a)
+			require.NoError(t, err)
+		}
+
+		return db
+	}
+
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			db := open(t, "")
+			defer db.Close()
+
+			want := run(t, db, pattern)
+
+			// with an untyped and a typed index on a.
+			// the pattern must be a literal to be used by the optimizer.
+			for _, schema := range []string{"(k INTEGER)", "(a TEXT)"} {
+				idb := open(t, schema)
+				defer idb.Close()
+
+				res, err := idb.Query(fmt.Sprintf("SELECT k FROM test WHERE a LIKE %q ORDER BY k", pattern))
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, res)
+				require.NoError(t, err)
+				require.NoError(t, res.Close())
+
+				require.JSONEq(t, want, buf.String())
+			}
+		})
+	}
+}
+
+func TestSelectInParam(t *testing.T) {
+	for _, index := range []bool{false, true} {
+		t.Run(fmt.Sprintf("index %v", index), func(t *testing.T) {
+			db, err := genji.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			err = db.Exec("CREATE TABLE test")
+			require.NoError(t, err)
+			if index {
+				err = db.Exec("CREATE INDEX idx_a ON test(a)")
+				require.NoError(t, err)
+			}
+			err = db.Exec("INSERT INTO test (a) VALUES (1), (2), (5)")
+			require.NoError(t, err)
+
+			query := func(q string, args ...interface{}) (string, error) {
+				res, err := db.Query(q, args...)
+				if err != nil {
+					return "", err
+				}
+				defer res.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, res)
+				return buf.String(), err
+			}
+
+			res, err := query("SELECT a FROM test WHERE a IN ? ORDER BY a", []int{1, 2, 3})
+			require.NoError(t, err)
+			require.JSONEq(t, `[{"a": 1}, {"a": 2}]`, res)
+
+			res, err = query("SELECT a FROM test WHERE a IN $ages ORDER BY a", sql.Named("ages", []int64{5}))
+			require.NoError(t, err)
+			require.JSONEq(t, `[{"a": 5}]`, res)
+
+			res, err = query("SELECT a FROM test WHERE a NOT IN ? ORDER BY a", []int{1, 2, 3})
+			require.NoError(t, err)
+			require.JSONEq(t, `[{"a": 5}]`, res)
+
+			_, err = query("SELECT a FROM test WHERE a IN ?", 1)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "IN operator takes an array")
+		})
+	}
+}
+
+func TestSelectTruthiness(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test(k INTEGER PRIMARY KEY);
+		INSERT INTO test (k, a) VALUES
+			(1, true), (2, false),
+			(3, 0), (4, 1),
+			(5, 0.0), (6, 1.5),
+			(7, ''), (8, 'x'),
+			(11, []), (12, [0]),
+			(13, {}), (14, {a: 1}),
+			(15, NULL);
+		INSERT INTO test (k) VALUES (16);
+	`)
+	require.NoError(t, err)
+	err = db.Exec("INSERT INTO test (k, a) VALUES (9, ?), (10, ?)", []byte{}, []byte{0})
+	require.NoError(t, err)
+
+	all := "[1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16]"
+	tests := []struct {
+		where    string
+		expected string
+	}{
+		{"a", "[1,4,6,8,10,12,14]"},
+		{"a AND k > 5", "[6,8,10,12,14]"},
+		{"a OR k = 2", "[1,2,4,6,8,10,12,14]"},
+		{"missing", "[]"},
+		{"true", all},
+		{"false", "[]"},
+		{"1", all},
+		{"0", "[]"},
+		{"0.5", all},
+		{"'x'", all},
+		{"''", "[]"},
+		{"[0]", all},
+		{"[]", "[]"},
+		{"{a: 0}", all},
+		{"{}", "[]"},
+		{"NULL", "[]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.where, func(t *testing.T) {
+			res, err := db.Query("SELECT k FROM test WHERE " + test.where)
+			require.NoError(t, err)
+			defer res.Close()
+
+			var keys []int64
+			err = res.Iterate(func(d document.Document) error {
+				v, err := d.GetByField("k")
+				if err != nil {
+					return err
+				}
+				keys = append(keys, v.V.(int64))
+				return nil
+			})
+			require.NoError(t, err)
+
+			got, err := json.Marshal(keys)
+			require.NoError(t, err)
+			if keys == nil {
+				got = []byte("[]")
+			}
+			require.JSONEq(t, test.expected, string(got))
+		})
+	}
+
+	t.Run("DELETE", func(t *testing.T) {
+		err := db.Exec("DELETE FROM test WHERE a")
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM test")
+		require.NoError(t, err)
+		n, err := d.GetByField("n")
+		require.NoError(t, err)
+		require.EqualValues(t, 9, n.V)
+	})
+}
+
+func TestSelectLateral(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE users;
+		CREATE TABLE orders;
+		CREATE INDEX idx_user_id ON orders(user_id);
+		INSERT INTO users (id, name) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz');
+		INSERT INTO orders (user_id, amount) VALUES (1, 10), (1, 5), (2, 7), (1, 1);
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+		params   []interface{}
+	}{
+		{"Aggregate", "SELECT u.name, o.total FROM users u, LATERAL (SELECT SUM(amount) AS total FROM orders WHERE user_id = u.id) o", false,
+			`[{"u.name":"foo","o.total":16},{"u.name":"bar","o.total":7},{"u.name":"baz","o.total":null}]`, nil},
+		{"Without alias", "SELECT name, o.amount FROM users, LATERAL (SELECT amount FROM orders WHERE user_id = users.id AND amount > ?) o", false,
+			`[{"name":"foo","o.amount":10},{"name":"foo","o.amount":5},{"name":"bar","o.amount":7}]`, []interface{}{2}},
+		{"Join", "SELECT u.name, o.amount FROM users u JOIN LATERAL (SELECT amount FROM orders WHERE user_id = u.id) o ON o.amount < 10", false,
+			`[{"u.name":"foo","o.amount":5},{"u.name":"foo","o.amount":1},{"u.name":"bar","o.amount":7}]`, nil},
+		{"Left join", "SELECT u.name, o.amount FROM users u LEFT JOIN LATERAL (SELECT amount FROM orders WHERE user_id = u.id AND amount > 5) o ON true", false,
+			`[{"u.name":"foo","o.amount":10},{"u.name":"bar","o.amount":7},{"u.name":"baz","o.amount":null}]`, nil},
+		{"Left join with where", "SELECT u.name, o FROM users u LEFT JOIN LATERAL (SELECT amount FROM orders WHERE user_id = u.id) o ON o.amount > 7 WHERE o IS NULL", false,
+			`[{"u.name":"bar","o":null},{"u.name":"baz","o":null}]`, nil},
+		{"Missing alias", "SELECT * FROM users u, LATERAL (SELECT * FROM orders WHERE user_id = u.id)", true, ``, nil},
+		{"Missing ON", "SELECT * FROM users u LEFT JOIN LATERAL (SELECT * FROM orders WHERE user_id = u.id) o", true, ``, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st, err := db.Query(test.query, test.params...)
+			if err == nil {
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				if !test.fails {
+					require.NoError(t, err)
+					require.JSONEq(t, test.expected, buf.String())
+					return
+				}
+			}
+
+			require.True(t, test.fails, "unexpected error: %v", err)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSelectExists(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE users;
+		CREATE TABLE orders;
+		CREATE TABLE empty;
+		CREATE INDEX idx_user_id ON orders(user_id);
+		INSERT INTO users (id, name) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz');
+		INSERT INTO orders (user_id, amount) VALUES (1, 10), (1, 5), (2, 7), (1, 1);
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+		params   []interface{}
+	}{
+		{"Uncorrelated", "SELECT name FROM users WHERE EXISTS (SELECT 1 FROM orders WHERE amount > 5)", false,
+			`[{"name":"foo"},{"name":"bar"},{"name":"baz"}]`, nil},
+		{"Uncorrelated empty", "SELECT name FROM users WHERE EXISTS (SELECT * FROM empty)", false,
+			`[]`, nil},
+		{"Not exists", "SELECT name FROM users WHERE NOT EXISTS (SELECT * FROM empty)", false,
+			`[{"name":"foo"},{"name":"bar"},{"name":"baz"}]`, nil},
+		{"Correlated", "SELECT u.name FROM users u WHERE EXISTS (SELECT 1 FROM orders o WHERE o.user_id = u.id)", false,
+			`[{"u.name":"foo"},{"u.name":"bar"}]`, nil},
+		{"Correlated with table names", "SELECT name FROM users WHERE EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id)", false,
+			`[{"name":"foo"},{"name":"bar"}]`, nil},
+		{"Correlated without alias", "SELECT name FROM users WHERE EXISTS (SELECT 1 FROM orders WHERE user_id = users.id AND amount > ?)", false,
+			`[{"name":"foo"},{"name":"bar"}]`, []interface{}{6}},
+		{"Correlated not exists", "SELECT name FROM users WHERE name != 'foo' AND NOT EXISTS (SELECT 1 FROM orders WHERE user_id = users.id)", false,
+			`[{"name":"baz"}]`, nil},
+		{"Projection", "SELECT name, EXISTS (SELECT 1 FROM orders WHERE user_id = users.id AND amount > 5) AS big FROM users", false,
+			`[{"name":"foo","big":true},{"name":"bar","big":true},{"name":"baz","big":false}]`, nil},
+		{"Correlated with json_extract", "SELECT name FROM users WHERE EXISTS (SELECT 1 FROM orders WHERE user_id = json_extract(users.id, '$') AND amount < 6)", false,
+			`[{"name":"foo"}]`, nil},
+		{"Correlated nested", "SELECT name FROM users WHERE EXISTS (SELECT 1 FROM users u WHERE u.id = 3 AND EXISTS (SELECT 1 FROM orders WHERE user_id = users.id AND amount = 7))", false,
+			`[{"name":"bar"}]`, nil},
+		{"Correlated nested scalar", "SELECT name FROM users WHERE (SELECT COUNT(*) FROM users u WHERE EXISTS (SELECT 1 FROM orders o WHERE o.user_id = users.id AND o.amount < u.id)) > 0", false,
+			`[{"name":"foo"}]`, nil},
+		{"Scalar subquery", "SELECT name FROM users WHERE id = (SELECT user_id FROM orders WHERE amount = 7)", false,
+			`[{"name":"bar"}]`, nil},
+		{"Scalar subquery with several documents", "SELECT name FROM users WHERE id = (SELECT user_id FROM orders)", true, ``, nil},
+		{"In subquery", "SELECT name FROM users WHERE id IN (SELECT user_id FROM orders WHERE amount > 5)", false,
+			`[{"name":"foo"},{"name":"bar"}]`, nil},
+		{"Not in subquery", "SELECT name FROM users WHERE id NOT IN (SELECT user_id FROM orders)", false,
+			`[{"name":"baz"}]`, nil},
+		{"In correlated subquery", "SELECT name FROM users WHERE 10 IN (SELECT amount FROM orders WHERE orders.user_id = users.id)", false,
+			`[{"name":"foo"}]`, nil},
+		{"In subquery with tuple", "SELECT name FROM users WHERE (id, 7) IN (SELECT user_id, amount FROM orders)", false,
+			`[{"name":"bar"}]`, nil},
+		{"In subquery with several fields", "SELECT name FROM users WHERE id IN (SELECT user_id, amount FROM orders)", true, ``, nil},
+		{"Any subquery", "SELECT name FROM users WHERE id = ANY (SELECT user_id FROM orders WHERE amount < 6)", false,
+			`[{"name":"foo"}]`, nil},
+		{"Unknown table", "SELECT name FROM users WHERE EXISTS (SELECT 1 FROM unknown)", true, ``, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st, err := db.Query(test.query, test.params...)
+			if err == nil {
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				if !test.fails {
+					require.NoError(t, err)
+					require.JSONEq(t, test.expected, buf.String())
+					return
+				}
+			}
+
+			require.True(t, test.fails, "unexpected error: %v", err)
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("Delete", func(t *testing.T) {
+		err := db.Exec("DELETE FROM users WHERE EXISTS (SELECT 1 FROM empty)")
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM users")
+		require.NoError(t, err)
+		var n int
+		require.NoError(t, document.Scan(d, &n))
+		require.Equal(t, 3, n)
+	})
+}
+
+func TestSelectWith(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE users;
+		CREATE TABLE orders;
+		CREATE INDEX idx_user_id ON orders(user_id);
+		INSERT INTO users (id, name) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz');
+		INSERT INTO orders (user_id, amount) VALUES (1, 10), (1, 5), (2, 7), (1, 1);
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+		params   []interface{}
+	}{
+		{"Single", "WITH big AS (SELECT user_id, amount FROM orders WHERE amount > 5) SELECT * FROM big", false,
+			`[{"user_id":1,"amount":10},{"user_id":2,"amount":7}]`, nil},
+		{"Filter and sort", "WITH big AS (SELECT user_id, amount FROM orders WHERE amount > ?) SELECT b.amount FROM big b WHERE b.user_id = ? ORDER BY amount", false,
+			`[{"b.amount":5},{"b.amount":10}]`, []interface{}{2, 1}},
+		{"Aggregate", "WITH totals AS (SELECT user_id, SUM(amount) AS total FROM orders GROUP BY user_id) SELECT COUNT(*) FROM totals WHERE total > 10", false,
+			`[{"COUNT(*)":1}]`, nil},
+		{"Chained", "WITH a AS (SELECT * FROM orders WHERE user_id = 1), b AS (SELECT amount FROM a WHERE amount < 10) SELECT * FROM b", false,
+			`[{"amount":5},{"amount":1}]`, nil},
+		{"Shadowing table", "WITH users AS (SELECT name FROM users WHERE id = 2) SELECT * FROM users", false,
+			`[{"name":"bar"}]`, nil},
+		{"Lateral", "WITH u AS (SELECT * FROM users WHERE id < 3) SELECT name, o.n FROM u, LATERAL (SELECT COUNT(*) AS n FROM orders WHERE user_id = u.id) o", false,
+			`[{"name":"foo","o.n":3},{"name":"bar","o.n":1}]`, nil},
+		{"Unknown table", "WITH a AS (SELECT * FROM unknown) SELECT * FROM a", true, ``, nil},
+		{"Referenced twice", "WITH a AS (SELECT * FROM orders WHERE user_id = ?) SELECT x.amount, y.n FROM a x, LATERAL (SELECT COUNT(*) AS n FROM a WHERE amount >= x.amount) y", false,
+			`[{"x.amount":10,"y.n":1},{"x.amount":5,"y.n":2},{"x.amount":1,"y.n":3}]`, []interface{}{1}},
+		{"Referenced by a subquery", "WITH a AS (SELECT * FROM orders), b AS (SELECT * FROM users WHERE EXISTS (SELECT * FROM a WHERE user_id = users.id)) SELECT name FROM b WHERE NOT EXISTS (SELECT * FROM a WHERE user_id = b.id AND amount < 5)", false,
+			`[{"name":"bar"}]`, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st, err := db.Query(test.query, test.params...)
+			if err == nil {
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				if !test.fails {
+					require.NoError(t, err)
+					require.JSONEq(t, test.expected, buf.String())
+					return
+				}
+			}
+
+			require.True(t, test.fails, "unexpected error: %v", err)
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("Undefined name", func(t *testing.T) {
+		_, err := db.Query("WITH a AS (SELECT * FROM users) SELECT * FROM b")
+		require.True(t, errors.Is(err, database.ErrTableNotFound))
+		require.Contains(t, err.Error(), `"b"`)
+	})
+}
+
+func TestSelectValues(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE users;
+		INSERT INTO users (id, name) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz');
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+		params   []interface{}
+	}{
+		{"Statement", "VALUES {a: 1}, {a: 2, b: [1]}", false,
+			`[{"a":1},{"a":2,"b":[1]}]`, nil},
+		{"Source", "SELECT * FROM (VALUES {a: 1}, {a: 2}) AS v", false,
+			`[{"a":1},{"a":2}]`, nil},
+		{"Params", "SELECT v.a FROM (VALUES {a: ?}, ?) v WHERE v.a > 1 ORDER BY a DESC", false,
+			`[{"v.a":3},{"v.a":2}]`, []interface{}{2, map[string]int{"a": 3}}},
+		{"Aggregate", "SELECT COUNT(*), SUM(a) FROM (VALUES {a: 1}, {a: 2}, {a: 3})", false,
+			`[{"COUNT(*)":3,"SUM(a)":6}]`, nil},
+		{"Subquery", "SELECT name FROM (SELECT * FROM users WHERE id > 1) u ORDER BY name", false,
+			`[{"name":"bar"},{"name":"baz"}]`, nil},
+		{"Join", "SELECT u.name, r.role FROM users u JOIN (VALUES {id: 1, role: 'admin'}, {id: 3, role: 'guest'}) r ON r.id = u.id", false,
+			`[{"u.name":"foo","r.role":"admin"},{"u.name":"baz","r.role":"guest"}]`, nil},
+		{"Left join", "SELECT u.name, r.role FROM users u LEFT JOIN (VALUES {id: 1, role: 'admin'}) r ON r.id = u.id", false,
+			`[{"u.name":"foo","r.role":"admin"},{"u.name":"bar","r.role":null},{"u.name":"baz","r.role":null}]`, nil},
+		{"Lateral", "SELECT u.name, r.n FROM users u, LATERAL (VALUES {n: u.id * 10}) r WHERE u.id < 3", false,
+			`[{"u.name":"foo","r.n":10},{"u.name":"bar","r.n":20}]`, nil},
+		{"Not a document", "VALUES ?", true, ``, []interface{}{1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st, err := db.Query(test.query, test.params...)
+			if err == nil {
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				if !test.fails {
+					require.NoError(t, err)
+					require.JSONEq(t, test.expected, buf.String())
+					return
+				}
+			}
+
+			require.True(t, test.fails, "unexpected error: %v", err)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSelectSample(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`CREATE TABLE test; CREATE INDEX idx_a ON test(a)`)
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		err = db.Exec(`INSERT INTO test (a) VALUES (?)`, i)
+		require.NoError(t, err)
+	}
+
+	query := func(t *testing.T, q string) []int64 {
+		st, err := db.Query(q)
+		require.NoError(t, err)
+		defer st.Close()
+
+		var res []int64
+		err = st.Iterate(func(d document.Document) error {
+			v, err := d.GetByField("a")
+			if err != nil {
+				return err
+			}
+			v, err = v.CastAsInteger()
+			if err != nil {
+				return err
+			}
+			res = append(res, v.V.(int64))
+			return nil
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("Rows", func(t *testing.T) {
+		res := query(t, "SELECT a FROM test SAMPLE 10 ROWS")
+		require.Len(t, res, 10)
+
+		// documents are returned in the order of the table
+		for i := 1; i < len(res); i++ {
+			require.Less(t, res[i-1], res[i])
+		}
+
+		require.Len(t, query(t, "SELECT a FROM test SAMPLE 1000 ROWS"), 100)
+		require.Empty(t, query(t, "SELECT a FROM test SAMPLE 0 ROWS"))
+	})
+
+	t.Run("Percent", func(t *testing.T) {
+		require.Len(t, query(t, "SELECT a FROM test SAMPLE 100 PERCENT"), 100)
+		require.Empty(t, query(t, "SELECT a FROM test SAMPLE 0 PERCENT"))
+
+		res := query(t, "SELECT a FROM test SAMPLE 50 PERCENT SEED 1")
+		require.NotEmpty(t, res)
+		require.Less(t, len(res), 100)
+	})
+
+	t.Run("Seed", func(t *testing.T) {
+		require.Equal(t,
+			query(t, "SELECT a FROM test SAMPLE 10 ROWS SEED 42"),
+			query(t, "SELECT a FROM test SAMPLE 10 ROWS SEED 42"))
+		require.Equal(t,
+			query(t, "SELECT a FROM test SAMPLE 30 PERCENT SEED 42"),
+			query(t, "SELECT a FROM test SAMPLE 30 PERCENT SEED 42"))
+	})
+
+	t.Run("Sampling before filtering", func(t *testing.T) {
+		// the index on a must not be used, otherwise the sample would be taken from the filtered documents
+		all := query(t, "SELECT a FROM test SAMPLE 10 ROWS SEED 42")
+		res := query(t, "SELECT a FROM test SAMPLE 10 ROWS SEED 42 WHERE a >= 50")
+
+		var expected []int64
+		for _, a := range all {
+			if a >= 50 {
+				expected = append(expected, a)
+			}
+		}
+		require.Equal(t, expected, res)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, q := range []string{
+			"SELECT a FROM test SAMPLE 101 PERCENT",
+			"SELECT a FROM test SAMPLE -1 ROWS",
+			"SELECT a FROM test SAMPLE 'a' ROWS",
+			"SELECT a FROM test SAMPLE 10",
+			"UPDATE test SAMPLE 10 ROWS SET a = 1",
+			"DELETE FROM test SAMPLE 10 ROWS",
+		} {
+			_, err := db.Query(q)
+			require.Error(t, err, q)
+		}
+	})
+}
@@ -31,6 +31,11 @@ func (stmt BeginStmt) IsReadOnly() bool {
 	return !stmt.Writable
 }
 
+// Tables always returns nil. It implements the Statement interface.
+func (stmt BeginStmt) Tables() []string {
+	return nil
+}
+
 func (stmt BeginStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
 	return Result{}, errors.New("cannot begin a transaction within a transaction")
 }
@@ -55,6 +60,11 @@ func (stmt RollbackStmt) IsReadOnly() bool {
 	return false
 }
 
+// Tables always returns nil. It implements the Statement interface.
+func (stmt RollbackStmt) Tables() []string {
+	return nil
+}
+
 func (stmt RollbackStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
 	return Result{}, errors.New("cannot rollback with no active transaction")
 }
@@ -79,6 +89,11 @@ func (stmt CommitStmt) IsReadOnly() bool {
 	return false
 }
 
+// Tables always returns nil. It implements the Statement interface.
+func (stmt CommitStmt) Tables() []string {
+	return nil
+}
+
 func (stmt CommitStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
 	return Result{}, errors.New("cannot commit with no active transaction")
 }
@@ -0,0 +1,40 @@
+package query
+
+import (
+	"errors"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/sql/query/expr"
+)
+
+// TruncateTableStmt is a DSL that allows creating a TRUNCATE TABLE query.
+type TruncateTableStmt struct {
+	TableName string
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt TruncateTableStmt) IsReadOnly() bool {
+	return false
+}
+
+// Tables returns the name of the truncated table. It implements the Statement interface.
+func (stmt TruncateTableStmt) Tables() []string {
+	return []string{stmt.TableName}
+}
+
+// Run deletes all the documents of the table, without firing its triggers.
+// It implements the Statement interface.
+func (stmt TruncateTableStmt) Run(tx *database.Transaction, args []expr.Param) (Result, error) {
+	var res Result
+
+	if stmt.TableName == "" {
+		return res, errors.New("missing table name")
+	}
+
+	tb, err := tx.GetTable(stmt.TableName)
+	if err != nil {
+		return res, err
+	}
+
+	return res, tb.Truncate()
+}
@@ -0,0 +1,63 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		fails bool
+	}{
+		{"Truncate table", `TRUNCATE TABLE test`, false},
+		{"Truncate unknown table", `TRUNCATE TABLE unknown`, true},
+		{"Truncate read-only table", `TRUNCATE TABLE __genji_tables`, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := genji.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			err = db.Exec(`
+				CREATE TABLE test;
+				CREATE TABLE other;
+				CREATE INDEX idx_test_a ON test(a);
+				INSERT INTO test (a) VALUES (1), (2), (3);
+				INSERT INTO other (a) VALUES (1);
+			`)
+			require.NoError(t, err)
+
+			err = db.Exec(test.query)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			count := func(q string) int64 {
+				d, err := db.QueryDocument(q)
+				require.NoError(t, err)
+				var n int64
+				err = document.Scan(d, &n)
+				require.NoError(t, err)
+				return n
+			}
+
+			require.EqualValues(t, 0, count("SELECT COUNT(*) FROM test"))
+			// the index is emptied too
+			require.EqualValues(t, 0, count("SELECT COUNT(*) FROM test WHERE a = 1"))
+			require.EqualValues(t, 1, count("SELECT COUNT(*) FROM other"))
+
+			err = db.Exec("INSERT INTO test (a) VALUES (1)")
+			require.NoError(t, err)
+			require.EqualValues(t, 1, count("SELECT COUNT(*) FROM test WHERE a = 1"))
+		})
+	}
+}
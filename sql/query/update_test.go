@@ -32,6 +32,13 @@ func TestUpdateStmt(t *testing.T) {
 		{"SET / With cond / with missing field", "UPDATE test SET f = 'boo' WHERE d = 'bar3'", false, `[{"a":"foo1","b":"bar1","c":"baz1"},{"a":"foo2","b":"bar2"},{"a":"foo3","d":"bar3","e":"baz3","f":"boo"}]`, nil},
 		{"SET / Field not found", "UPDATE test SET a = 1, b = 2 WHERE a = f", false, `[{"a":"foo1","b":"bar1","c":"baz1"},{"a":"foo2","b":"bar2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, nil},
 		{"SET / Positional params", "UPDATE test SET a = ?, b = ? WHERE a = ?", false, `[{"a":"a","b":"b","c":"baz1"},{"a":"foo2","b":"bar2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, []interface{}{"a", "b", "foo1"}},
+		{"SET / Spread", "UPDATE test SET b = [...[b], 'x'] WHERE a = 'foo1'", false, `[{"a":"foo1","b":["bar1","x"],"c":"baz1"},{"a":"foo2","b":"bar2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, nil},
+		{"SET / Spread non-array", "UPDATE test SET b = [...b, 'x']", true, ``, nil},
+		{"SET / Document spread", "UPDATE test SET b = {...{b: b, v: 1}, v: 2} WHERE a = 'foo1'", false, `[{"a":"foo1","b":{"b":"bar1","v":2},"c":"baz1"},{"a":"foo2","b":"bar2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, nil},
+		{"SET / Document spread non-document", "UPDATE test SET b = {...b, v: 2}", true, ``, nil},
+		{"SET / Unchanged value", "UPDATE test SET a = a, b = 'bar2' WHERE b = 'bar2'", false, `[{"a":"foo1","b":"bar1","c":"baz1"},{"a":"foo2","b":"bar2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, nil},
+		{"SET / Missing WHERE", "UPDATE test SET a = 'boo' b = 'bar2'", true, ``, nil},
+		{"SET / Typo in WHERE", "UPDATE test SET a = 'boo' WERE b = 'bar2'", true, ``, nil},
 		{"SET / Named params", "UPDATE test SET a = $a, b = $b WHERE a = $c", false, `[{"a":"a","b":"b","c":"baz1"},{"a":"foo2","b":"bar2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, []interface{}{sql.Named("b", "b"), sql.Named("a", "a"), sql.Named("c", "foo1")}},
 
 		// UNSET tests.
@@ -40,6 +47,7 @@ func TestUpdateStmt(t *testing.T) {
 		{"UNSET / No cond / with missing field", "UPDATE test UNSET f", false, `[{"a":"foo1","b":"bar1","c":"baz1"},{"a":"foo2","b":"bar2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, nil},
 		{"UNSET / No cond / with string", `UPDATE test UNSET 'a'`, true, "", nil},
 		{"UNSET / With cond", `UPDATE test UNSET b WHERE a = 'foo2'`, false, `[{"a":"foo1","b":"bar1","c":"baz1"},{"a":"foo2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, nil},
+		{"UNSET / With field_exists", `UPDATE test UNSET b WHERE field_exists(c)`, false, `[{"a":"foo1","c":"baz1"},{"a":"foo2","b":"bar2"},{"a":"foo3","d":"bar3","e":"baz3"}]`, nil},
 	}
 
 	for _, test := range tests {
@@ -93,6 +101,9 @@ func TestUpdateStmt(t *testing.T) {
 			{"SET / No cond / Nested array", `UPDATE foo SET a[1] = [1, 0, 0]`, false, `[{"a": [1, [1, 0, 0], 0]}, {"a": [2, [1, 0, 0]]}]`, nil},
 			{"SET / No cond / with multiple idents", `UPDATE foo SET a[1] = [1, 0, 0], a[1][2] = 9`, false, `[{"a": [1, [1, 0, 9], 0]}, {"a": [2, [1, 0, 9]]}]`, nil},
 			{"SET / No cond / add doc / with multiple idents with multiple indexes", `UPDATE foo SET a[1] = [1, 0, 0], a[1][2] = {"b": "foo"}`, false, `[{"a": [1, [1, 0, {"b":"foo"}], 0]}, {"a": [2, [1, 0, {"b":"foo"}]]}]`, nil},
+			{"UNSET / No cond / index array", `UPDATE foo UNSET a[0]`, false, `[{"a": [0, 0]}, {"a": [0]}]`, nil},
+			{"UNSET / No cond / index out of range", `UPDATE foo UNSET a[2]`, false, `[{"a": [1, 0]}, {"a": [2, 0]}]`, nil},
+			{"UNSET / No cond / with path on non existing field", `UPDATE foo UNSET a.foo, b[0]`, false, `[{"a": [1, 0, 0]}, {"a": [2, 0]}]`, nil},
 		}
 
 		for _, tt := range tests {
@@ -123,4 +134,240 @@ func TestUpdateStmt(t *testing.T) {
 			require.JSONEq(t, tt.expected, buf.String())
 		}
 	})
+
+	t.Run("with nested paths", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			query    string
+			fails    bool
+			expected string
+		}{
+			{"UNSET / Nested field", `UPDATE foo UNSET b.c`, false, `[{"a": 1, "b": {"d": [1, 2, 3]}}, {"a": 2, "b": {}}]`},
+			{"UNSET / Nested array element", `UPDATE foo UNSET b.d[1]`, false, `[{"a": 1, "b": {"c": 10, "d": [1, 3]}}, {"a": 2, "b": {"c": 20}}]`},
+			{"UNSET / Missing nested field", `UPDATE foo UNSET b.e, a.b`, false, `[{"a": 1, "b": {"c": 10, "d": [1, 2, 3]}}, {"a": 2, "b": {"c": 20}}]`},
+			{"UNSET / Not null field", `UPDATE foo UNSET a WHERE a = 2`, true, ``},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				db, err := genji.Open(":memory:")
+				require.NoError(t, err)
+				defer db.Close()
+
+				err = db.Exec(`CREATE TABLE foo (a INTEGER NOT NULL)`)
+				require.NoError(t, err)
+				err = db.Exec(`INSERT INTO foo (a, b) VALUES (1, {c: 10, d: [1, 2, 3]}), (2, {c: 20})`)
+				require.NoError(t, err)
+
+				err = db.Exec(tt.query)
+				if tt.fails {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				st, err := db.Query("SELECT * FROM foo")
+				require.NoError(t, err)
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				require.NoError(t, err)
+				require.JSONEq(t, tt.expected, buf.String())
+			})
+		}
+	})
+
+	t.Run("with index", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE foo;
+			CREATE INDEX idx_foo_b_c ON foo(b.c);
+			INSERT INTO foo (a, b) VALUES (1, {c: 10}), (2, {c: 20});
+		`)
+		require.NoError(t, err)
+
+		err = db.Exec(`UPDATE foo UNSET b.c WHERE a = 1`)
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument(`SELECT COUNT(*) AS n FROM foo WHERE b.c = 10`)
+		require.NoError(t, err)
+		v, err := d.GetByField("n")
+		require.NoError(t, err)
+		require.Equal(t, document.NewIntegerValue(0), v)
+
+		// the document can still be updated and deleted once the field is removed
+		err = db.Exec(`UPDATE foo SET e = 1 WHERE a = 1`)
+		require.NoError(t, err)
+		err = db.Exec(`DELETE FROM foo WHERE a = 1`)
+		require.NoError(t, err)
+
+		d, err = db.QueryDocument(`SELECT COUNT(*) AS n FROM foo`)
+		require.NoError(t, err)
+		v, err = d.GetByField("n")
+		require.NoError(t, err)
+		require.Equal(t, document.NewIntegerValue(1), v)
+	})
+	t.Run("with indexes on modified paths", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			query    string
+			where    string
+			expected string
+		}{
+			{"SET parent document", `UPDATE foo SET b = {c: 30} WHERE a = 1`, `b.c = 30`, `[{"a": 1, "b": {"c": 30}, "d": [1, 2]}]`},
+			{"SET parent document / old value", `UPDATE foo SET b = {c: 30} WHERE a = 1`, `b.c = 10`, `[]`},
+			{"UNSET parent document", `UPDATE foo UNSET b WHERE a = 1`, `b.c = 10`, `[]`},
+			{"UNSET array element", `UPDATE foo UNSET d[0]`, `d[1] = 2`, `[]`},
+			{"UNSET array element / shifted value", `UPDATE foo UNSET d[0]`, `d[0] = 2`, `[{"a": 1, "b": {"c": 10}, "d": [2]}]`},
+			{"SET other field", `UPDATE foo SET e = 1`, `b.c = 20`, `[{"a": 2, "b": {"c": 20}, "d": [3, 4], "e": 1}]`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				db, err := genji.Open(":memory:")
+				require.NoError(t, err)
+				defer db.Close()
+
+				err = db.Exec(`
+					CREATE TABLE foo;
+					CREATE INDEX idx_foo_b_c ON foo(b.c);
+					CREATE INDEX idx_foo_d_0 ON foo(d[0]);
+					CREATE INDEX idx_foo_d_1 ON foo(d[1]);
+					INSERT INTO foo (a, b, d) VALUES (1, {c: 10}, [1, 2]), (2, {c: 20}, [3, 4]);
+				`)
+				require.NoError(t, err)
+
+				err = db.Exec(tt.query)
+				require.NoError(t, err)
+
+				st, err := db.Query("SELECT * FROM foo WHERE " + tt.where)
+				require.NoError(t, err)
+				defer st.Close()
+
+				var buf bytes.Buffer
+				err = document.IteratorToJSONArray(&buf, st)
+				require.NoError(t, err)
+				require.JSONEq(t, tt.expected, buf.String())
+			})
+		}
+	})
+
+	t.Run("unchanged documents", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE foo;
+			CREATE INDEX idx_foo_a ON foo(a);
+			INSERT INTO foo (a, b) VALUES (1, 'x'), (2.0, 'y');
+		`)
+		require.NoError(t, err)
+
+		// documents left unchanged are not written,
+		// which is allowed by a read-only transaction.
+		err = db.View(func(tx *genji.Tx) error {
+			err := tx.Exec(`UPDATE foo SET a = a, b = b`)
+			if err != nil {
+				return err
+			}
+			err = tx.Exec(`UPDATE foo SET a = 2.0 WHERE a = 2`)
+			if err != nil {
+				return err
+			}
+			return tx.Exec(`UPDATE foo UNSET c`)
+		})
+		require.NoError(t, err)
+
+		// changed values are written, including equal values of another type.
+		for _, q := range []string{`UPDATE foo SET b = 'z' WHERE a = 1`, `UPDATE foo SET a = 2 WHERE a = 2`} {
+			err = db.View(func(tx *genji.Tx) error {
+				return tx.Exec(q)
+			})
+			require.Error(t, err, q)
+		}
+	})
+
+	t.Run("unchanged documents with triggers", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE foo;
+			CREATE TABLE audit_log;
+			CREATE TRIGGER log_update AFTER UPDATE ON foo INSERT INTO audit_log VALUES {a: new_doc.a};
+			INSERT INTO foo (a) VALUES (1), (2);
+		`)
+		require.NoError(t, err)
+
+		// UPDATE triggers fire even if the documents are unchanged
+		err = db.Exec(`UPDATE foo SET a = a`)
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument(`SELECT COUNT(*) AS n FROM audit_log`)
+		require.NoError(t, err)
+		v, err := d.GetByField("n")
+		require.NoError(t, err)
+		require.Equal(t, document.NewIntegerValue(2), v)
+	})
+}
+
+func BenchmarkUpdateUnchanged(b *testing.B) {
+	db, err := genji.Open(":memory:")
+	require.NoError(b, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE foo;
+		CREATE INDEX idx_foo_a ON foo(a);
+		CREATE INDEX idx_foo_b ON foo(b);
+	`)
+	require.NoError(b, err)
+
+	for i := 0; i < 50; i++ {
+		err = db.Exec(`INSERT INTO foo (a, b, c) VALUES (?, ?, "bar")`, i, i*10)
+		require.NoError(b, err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err = db.Exec(`UPDATE foo SET c = "bar"`)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUpdateIndexed(b *testing.B) {
+	db, err := genji.Open(":memory:")
+	require.NoError(b, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE foo;
+		CREATE INDEX idx_foo_a ON foo(a);
+		CREATE INDEX idx_foo_b ON foo(b);
+		CREATE INDEX idx_foo_c ON foo(c);
+		CREATE INDEX idx_foo_d ON foo(d);
+		CREATE INDEX idx_foo_e ON foo(e);
+	`)
+	require.NoError(b, err)
+
+	for i := 0; i < 50; i++ {
+		err = db.Exec(`INSERT INTO foo (a, b, c, d, e, f) VALUES (?, ?, ?, ?, ?, 0)`, i, i*2, i*3, i*4, i*5)
+		require.NoError(b, err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err = db.Exec(`UPDATE foo SET f = ?`, i)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
 }
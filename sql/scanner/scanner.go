@@ -81,6 +81,12 @@ func (s *Scanner) Scan() TokenInfo {
 		return s.scanString()
 	case '.':
 		ch1, _ := s.read()
+		if ch1 == '.' {
+			if ch2, _ := s.read(); ch2 == '.' {
+				return TokenInfo{SPREAD, pos, "", s.unbuffer()}
+			}
+			s.unread()
+		}
 		s.unread()
 		if isDigit(ch1) {
 			return s.scanNumber()
@@ -146,6 +152,11 @@ func (s *Scanner) Scan() TokenInfo {
 			return TokenInfo{NEQREGEX, pos, "", s.unbuffer()}
 		}
 		s.unread()
+	case '@':
+		if ch1, _ := s.read(); ch1 == '>' {
+			return TokenInfo{CONTAINS, pos, "", s.unbuffer()}
+		}
+		s.unread()
 	case '>':
 		if ch1, _ := s.read(); ch1 == '=' {
 			return TokenInfo{GTE, pos, "", s.unbuffer()}
@@ -280,8 +291,9 @@ func (s *Scanner) scanIdent(lookup bool) TokenInfo {
 // scanString consumes a contiguous string of non-quote characters.
 // Quote characters can be consumed if they're first escaped with a backslash.
 func (s *Scanner) scanString() TokenInfo {
-	s.unread()
+	// the opening quote was just read
 	_, pos := s.r.curr()
+	s.unread()
 
 	lit, err := ScanString(s)
 
@@ -395,7 +407,7 @@ type BufScanner struct {
 	s   *Scanner
 	i   int // buffer index
 	n   int // buffer size
-	buf [3]TokenInfo
+	buf [4]TokenInfo
 }
 
 // NewBufScanner returns a new buffered scanner for a reader.
@@ -2,6 +2,7 @@ package scanner_test
 
 import (
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -55,6 +56,8 @@ func TestScanner_Scan(t *testing.T) {
 		{s: `IN`, tok: scanner.IN, raw: `IN`},
 		{s: `IS`, tok: scanner.IS, raw: `IS`},
 		{s: `LIKE`, tok: scanner.LIKE, raw: `LIKE`},
+		{s: `@>`, tok: scanner.CONTAINS, raw: `@>`},
+		{s: `@ `, tok: scanner.ILLEGAL, lit: "@", raw: `@`},
 
 		// Misc tokens
 		{s: `(`, tok: scanner.LPAREN, raw: `(`},
@@ -66,6 +69,8 @@ func TestScanner_Scan(t *testing.T) {
 		{s: `,`, tok: scanner.COMMA, raw: `,`},
 		{s: `;`, tok: scanner.SEMICOLON, raw: `;`},
 		{s: `.`, tok: scanner.DOT, raw: `.`},
+		{s: `...`, tok: scanner.SPREAD, raw: `...`},
+		{s: `..`, tok: scanner.DOT, raw: `.`},
 		{s: `=~`, tok: scanner.EQREGEX, raw: `=~`},
 		{s: `!~`, tok: scanner.NEQREGEX, raw: `!~`},
 		{s: `:`, tok: scanner.COLON, raw: `:`},
@@ -81,7 +86,7 @@ func TestScanner_Scan(t *testing.T) {
 		{s: "`foo\bar`", tok: scanner.IDENT, lit: "foo\bar", raw: "`foo\bar`"},
 		{s: "`foo\\bar`", tok: scanner.BADESCAPE, lit: `\b`, pos: scanner.Pos{Line: 0, Char: 5}, raw: "`foo\\b"},
 		{s: "`foo\\`bar\\``", tok: scanner.IDENT, lit: "foo`bar`", raw: "`foo\\`bar\\``"},
-		{s: "test`", tok: scanner.BADSTRING, lit: "", pos: scanner.Pos{Line: 0, Char: 3}, raw: "test`"},
+		{s: "test`", tok: scanner.BADSTRING, lit: "", pos: scanner.Pos{Line: 0, Char: 4}, raw: "test`"},
 		{s: "`test", tok: scanner.BADSTRING, lit: "test", raw: "`test"},
 		{s: "$host", tok: scanner.NAMEDPARAM, lit: "$host", raw: "$host"},
 		{s: "$`host param`", tok: scanner.NAMEDPARAM, lit: "$host param", raw: "$`host param`"},
@@ -198,7 +203,7 @@ func TestScanner_Scan_Multi(t *testing.T) {
 		{Tok: scanner.WS, Pos: scanner.Pos{Line: 0, Char: 34}, Lit: " ", Raw: " "},
 		{Tok: scanner.EQ, Pos: scanner.Pos{Line: 0, Char: 35}, Lit: "", Raw: "="},
 		{Tok: scanner.WS, Pos: scanner.Pos{Line: 0, Char: 36}, Lit: " ", Raw: " "},
-		{Tok: scanner.STRING, Pos: scanner.Pos{Line: 0, Char: 36}, Lit: "b", Raw: "'b'"},
+		{Tok: scanner.STRING, Pos: scanner.Pos{Line: 0, Char: 37}, Lit: "b", Raw: "'b'"},
 		{Tok: scanner.EOF, Pos: scanner.Pos{Line: 0, Char: 40}, Lit: "", Raw: ""},
 	}
 
@@ -293,3 +298,28 @@ func TestScanRegex(t *testing.T) {
 		}
 	}
 }
+
+func TestKeywords(t *testing.T) {
+	kws := scanner.Keywords()
+	if !sort.StringsAreSorted(kws) {
+		t.Errorf("keywords are not sorted: %v", kws)
+	}
+
+	for _, kw := range []string{"SELECT", "LIKE", "NULL"} {
+		if tok := scanner.Lookup(kw); tok == scanner.IDENT {
+			t.Errorf("%q is not a keyword", kw)
+		}
+	}
+
+	// contextual keywords are listed but scanned as identifiers
+	if tok := scanner.Lookup("RETURNING"); tok != scanner.IDENT {
+		t.Errorf("expected RETURNING to be scanned as an identifier, got %s", tok)
+	}
+
+	for _, kw := range []string{"SELECT", "LIKE", "NULL", "RETURNING"} {
+		i := sort.SearchStrings(kws, kw)
+		if i == len(kws) || kws[i] != kw {
+			t.Errorf("missing keyword %q", kw)
+		}
+	}
+}
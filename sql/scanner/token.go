@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"sort"
 	"strings"
 )
 
@@ -57,6 +58,8 @@ const (
 	IN       // IN
 	IS       // IS
 	LIKE     // LIKE
+	CONTAINS // @>
+	REGEXP   // REGEXP
 	operatorEnd
 
 	LPAREN      // (
@@ -70,9 +73,10 @@ const (
 	DOUBLECOLON // ::
 	SEMICOLON   // ;
 	DOT         // .
+	SPREAD      // ...
 
 	keywordBeg
-	// ALL and the following are Genji SQL Keywords
+	// ADD and the following are Genji SQL Keywords
 	ADD_KEYWORD
 	ALTER
 	AS
@@ -183,6 +187,8 @@ var tokens = [...]string{
 	IN:       "IN",
 	IS:       "IS",
 	LIKE:     "LIKE",
+	CONTAINS: "@>",
+	REGEXP:   "REGEXP",
 
 	LPAREN:      "(",
 	RPAREN:      ")",
@@ -195,6 +201,7 @@ var tokens = [...]string{
 	DOUBLECOLON: "::",
 	SEMICOLON:   ";",
 	DOT:         ".",
+	SPREAD:      "...",
 
 	ADD_KEYWORD: "ADD",
 	ALTER:       "ALTER",
@@ -293,7 +300,7 @@ func (tok Token) Precedence() int {
 		return 2
 	case IN:
 		return 3
-	case EQ, NEQ, EQREGEX, NEQREGEX, LT, LTE, GT, GTE, IS, LIKE:
+	case EQ, NEQ, EQREGEX, NEQREGEX, LT, LTE, GT, GTE, IS, LIKE, CONTAINS, REGEXP:
 		return 4
 	case ADD, SUB, BITWISEOR, BITWISEXOR:
 		return 5
@@ -322,6 +329,55 @@ func Lookup(ident string) Token {
 	return IDENT
 }
 
+// contextualKeywords are recognized by the parser only where they are expected.
+// They are scanned as identifiers, to allow using them as field or table names.
+var contextualKeywords = []string{
+	"AFTER",
+	"ALL",
+	"ANY",
+	"BEFORE",
+	"COLLATE",
+	"DESCRIBE",
+	"EACH",
+	"FIRST",
+	"FOR",
+	"INT16",
+	"INT32",
+	"INT64",
+	"JOIN",
+	"LAST",
+	"LATERAL",
+	"LEFT",
+	"NULLS",
+	"PERCENT",
+	"PIVOT",
+	"REGEXP",
+	"RETURNING",
+	"ROW",
+	"ROWS",
+	"SAMPLE",
+	"SEED",
+	"SHOW",
+	"TABLES",
+	"TRIGGER",
+	"TRUNCATE",
+	"TTL",
+	"WITH",
+}
+
+// Keywords returns the keywords of the language, sorted alphabetically.
+// It includes contextual keywords, for which Lookup returns IDENT.
+func Keywords() []string {
+	kws := make([]string, 0, len(keywords)+len(contextualKeywords))
+	for _, tok := range keywords {
+		kws = append(kws, tok.String())
+	}
+	kws = append(kws, contextualKeywords...)
+	sort.Strings(kws)
+
+	return kws
+}
+
 // Pos specifies the line and character position of a token.
 // The Char and Line are both zero-based indexes.
 type Pos struct {
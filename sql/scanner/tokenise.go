@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tokenise returns every token of the given SQL string, except whitespaces and EOF.
+// Comments are included. It is meant to be used by external tools like linters or formatters.
+// Invalid tokens, such as ILLEGAL or BADSTRING, don't stop the scanning: they are added to the
+// list and the returned error describes the first of them.
+func Tokenise(sql string) ([]TokenInfo, error) {
+	s := NewScanner(strings.NewReader(sql))
+
+	var tokens []TokenInfo
+	var err error
+	for {
+		ti := s.Scan()
+		switch ti.Tok {
+		case EOF:
+			return tokens, err
+		case WS:
+			continue
+		case ILLEGAL, BADSTRING, BADESCAPE, BADREGEX:
+			if err == nil {
+				err = fmt.Errorf("found %s %q at line %d, char %d", ti.Tok, ti.Raw, ti.Pos.Line+1, ti.Pos.Char+1)
+			}
+		}
+
+		tokens = append(tokens, ti)
+	}
+}
+
+// Format reconstructs an SQL string from the given tokens, with normalized whitespaces:
+// tokens are separated by a single space, except around dots, before commas,
+// colons, semicolons and closing brackets, and after opening brackets.
+// Line comments are followed by a new line.
+func Format(tokens []TokenInfo) string {
+	var sb strings.Builder
+
+	for i, ti := range tokens {
+		if i > 0 && needsSpace(tokens[i-1], ti) {
+			sb.WriteByte(' ')
+		}
+
+		raw := ti.Raw
+		if ti.Tok == COMMENT && strings.HasPrefix(raw, "--") {
+			raw = strings.TrimRight(raw, "\n") + "\n"
+		}
+		sb.WriteString(raw)
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// needsSpace reports whether a space must be written between prev and next.
+func needsSpace(prev, next TokenInfo) bool {
+	if prev.Tok == COMMENT && strings.HasPrefix(prev.Raw, "--") {
+		return false
+	}
+
+	switch prev.Tok {
+	case DOT, LPAREN, LSBRACKET, LBRACKET:
+		return false
+	}
+
+	switch next.Tok {
+	case DOT, COMMA, COLON, SEMICOLON, RPAREN, RSBRACKET, RBRACKET:
+		return false
+	case LSBRACKET:
+		// array indexes: a[1], a[1][2]
+		return prev.Tok != IDENT && prev.Tok != RSBRACKET
+	}
+
+	return true
+}
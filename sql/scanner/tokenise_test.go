@@ -0,0 +1,98 @@
+package scanner_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/genjidb/genji/sql/scanner"
+)
+
+func TestTokenise(t *testing.T) {
+	tokens, err := scanner.Tokenise("SELECT a.b[1], `c` FROM foo\nWHERE d >= $e; -- done")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp := []scanner.TokenInfo{
+		{Tok: scanner.SELECT, Pos: scanner.Pos{Line: 0, Char: 0}, Raw: "SELECT"},
+		{Tok: scanner.IDENT, Pos: scanner.Pos{Line: 0, Char: 7}, Lit: "a", Raw: "a"},
+		{Tok: scanner.DOT, Pos: scanner.Pos{Line: 0, Char: 8}, Raw: "."},
+		{Tok: scanner.IDENT, Pos: scanner.Pos{Line: 0, Char: 9}, Lit: "b", Raw: "b"},
+		{Tok: scanner.LSBRACKET, Pos: scanner.Pos{Line: 0, Char: 10}, Raw: "["},
+		{Tok: scanner.INTEGER, Pos: scanner.Pos{Line: 0, Char: 11}, Lit: "1", Raw: "1"},
+		{Tok: scanner.RSBRACKET, Pos: scanner.Pos{Line: 0, Char: 12}, Raw: "]"},
+		{Tok: scanner.COMMA, Pos: scanner.Pos{Line: 0, Char: 13}, Raw: ","},
+		{Tok: scanner.IDENT, Pos: scanner.Pos{Line: 0, Char: 15}, Lit: "c", Raw: "`c`"},
+		{Tok: scanner.FROM, Pos: scanner.Pos{Line: 0, Char: 19}, Raw: "FROM"},
+		{Tok: scanner.IDENT, Pos: scanner.Pos{Line: 0, Char: 24}, Lit: "foo", Raw: "foo"},
+		{Tok: scanner.WHERE, Pos: scanner.Pos{Line: 1, Char: 0}, Raw: "WHERE"},
+		{Tok: scanner.IDENT, Pos: scanner.Pos{Line: 1, Char: 6}, Lit: "d", Raw: "d"},
+		{Tok: scanner.GTE, Pos: scanner.Pos{Line: 1, Char: 8}, Raw: ">="},
+		{Tok: scanner.NAMEDPARAM, Pos: scanner.Pos{Line: 1, Char: 11}, Lit: "$e", Raw: "$e"},
+		{Tok: scanner.SEMICOLON, Pos: scanner.Pos{Line: 1, Char: 13}, Raw: ";"},
+		{Tok: scanner.COMMENT, Pos: scanner.Pos{Line: 1, Char: 15}, Raw: "-- done"},
+	}
+
+	if !reflect.DeepEqual(exp, tokens) {
+		t.Fatalf("unexpected tokens:\nexp=%#v\ngot=%#v", exp, tokens)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp string
+	}{
+		{"SELECT   a.b[1][2],`c`\n\tFROM foo WHERE (d>=$e)  ;", "SELECT a.b[1][2], `c` FROM foo WHERE (d >= $e);"},
+		{"INSERT INTO foo(a) VALUES ([1,2], {a:'b'})", "INSERT INTO foo (a) VALUES ([1, 2], {a: 'b'})"},
+		{"SELECT a -- comment\nFROM foo", "SELECT a -- comment\nFROM foo"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			tokens, err := scanner.Tokenise(test.s)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := scanner.Format(tokens)
+			if got != test.exp {
+				t.Fatalf("unexpected output:\nexp=%q\ngot=%q", test.exp, got)
+			}
+
+			// formatting must not alter the token sequence.
+			formatted, err := scanner.Tokenise(got)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(formatted) != len(tokens) {
+				t.Fatalf("expected %d tokens, got %d", len(tokens), len(formatted))
+			}
+			for i := range tokens {
+				if tokens[i].Tok != formatted[i].Tok || tokens[i].Lit != formatted[i].Lit {
+					t.Fatalf("token %d: expected %v, got %v", i, tokens[i], formatted[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTokeniseError(t *testing.T) {
+	tokens, err := scanner.Tokenise("SELECT 'foo FROM bar")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if len(tokens) != 2 || tokens[1].Tok != scanner.BADSTRING {
+		t.Fatalf("expected a BADSTRING token, got %v", tokens)
+	}
+
+	tokens, err = scanner.Tokenise("SELECT # FROM bar")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if len(tokens) != 4 || tokens[1].Tok != scanner.ILLEGAL {
+		t.Fatalf("expected an ILLEGAL token, got %v", tokens)
+	}
+}
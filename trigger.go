@@ -0,0 +1,32 @@
+package genji
+
+import (
+	"strings"
+
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/sql/parser"
+)
+
+// runTrigger runs the statement of a trigger within the transaction that fired it.
+// The old_doc and new_doc pseudo-documents are replaced by the documents
+// before and after the change when the statement is parsed. Quoted, like `new_doc`,
+// they refer to the fields of the documents read by the statement instead.
+func runTrigger(tx *database.Transaction, trigger *database.TriggerConfig, old, new document.Document) error {
+	p := parser.NewParser(strings.NewReader(trigger.Statement), parser.WithVariables(map[string]document.Document{
+		"old_doc": old,
+		"new_doc": new,
+	}))
+
+	stmt, err := p.ParseStatement()
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.Run(tx, nil)
+	if err != nil {
+		return err
+	}
+
+	return res.Close()
+}
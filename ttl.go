@@ -0,0 +1,133 @@
+package genji
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/genjidb/genji/database"
+)
+
+// ttlBatchSize is the maximum number of documents deleted by the TTL reaper
+// within a single transaction.
+const ttlBatchSize = 100
+
+// WithTTLReaper starts a background goroutine that scans, every interval,
+// the tables having a TTL path and deletes their expired documents.
+// Documents are deleted by batches, each batch using its own read-write transaction
+// to let other writers through.
+// Expired documents are read from the index of the TTL path if there is one.
+// Otherwise, each batch scans the table from the beginning: large tables
+// should index their TTL path.
+// The reaper is stopped by calling StopTTLReaper or Close.
+func WithTTLReaper(interval time.Duration) Option {
+	return func(db *DB) error {
+		if interval <= 0 {
+			return errors.New("ttl reaper interval must be positive")
+		}
+
+		db.reaper = &ttlReaper{
+			db:       db.DB,
+			interval: interval,
+			stop:     make(chan struct{}),
+			done:     make(chan struct{}),
+		}
+		go db.reaper.run()
+		return nil
+	}
+}
+
+// StopTTLReaper stops the TTL reaper and waits for it to return.
+// It is safe to call it multiple times or if the reaper was never started.
+func (db *DB) StopTTLReaper() {
+	if db.reaper != nil {
+		db.reaper.close()
+	}
+}
+
+type ttlReaper struct {
+	db       *database.Database
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+func (r *ttlReaper) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			// errors are not fatal, the next tick will try again.
+			_ = r.reap()
+		}
+	}
+}
+
+func (r *ttlReaper) close() {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+	<-r.done
+}
+
+// reap deletes the expired documents of every table having a TTL path.
+func (r *ttlReaper) reap() error {
+	tx, err := r.db.BeginTx(context.Background(), &database.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	tables, err := tx.ListTTLTables()
+	tx.Rollback()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, name := range tables {
+		for {
+			select {
+			case <-r.stop:
+				return nil
+			default:
+			}
+
+			n, err := r.reapBatch(name, now)
+			if err != nil {
+				return err
+			}
+			if n < ttlBatchSize {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *ttlReaper) reapBatch(tableName string, now time.Time) (int, error) {
+	tx, err := r.db.Begin(true)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	tb, err := tx.GetTable(tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := tb.DeleteExpired(now, ttlBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	return n, tx.Commit()
+}
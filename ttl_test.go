@@ -0,0 +1,49 @@
+package genji_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTLReaper(t *testing.T) {
+	interval := 50 * time.Millisecond
+
+	db, err := genji.Open(":memory:", genji.WithTTLReaper(interval))
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test(expires_at TTL);
+		CREATE INDEX idx_test_expires_at ON test(expires_at);
+	`)
+	require.NoError(t, err)
+
+	expiresAt := time.Now().Add(100 * time.Millisecond).UnixNano()
+	for i := 0; i < 10; i++ {
+		err = db.Exec("INSERT INTO test (a, expires_at) VALUES (?, ?)", i, expiresAt)
+		require.NoError(t, err)
+	}
+	// this one never expires
+	err = db.Exec("INSERT INTO test (a) VALUES (10)")
+	require.NoError(t, err)
+
+	time.Sleep(500 * time.Millisecond)
+
+	d, err := db.QueryDocument("SELECT COUNT(*) FROM test")
+	require.NoError(t, err)
+	var count int
+	err = document.Scan(d, &count)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	start := time.Now()
+	db.StopTTLReaper()
+	require.Less(t, int64(time.Since(start)), int64(2*interval))
+
+	// stopping twice must not block or panic.
+	db.StopTTLReaper()
+}
@@ -0,0 +1,158 @@
+package genji
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/genjidb/genji/document"
+)
+
+// DefaultWatchInterval is the minimum interval between two executions
+// of a query watched with Watch, unless the WatchInterval option is passed.
+const DefaultWatchInterval = time.Second
+
+// WatchInterval sets the minimum interval between two executions of a query
+// watched with Watch. It is ignored by the other methods.
+// d must be positive.
+func WatchInterval(d time.Duration) ExecOption {
+	return func(o *execOptions) error {
+		if d <= 0 {
+			return errors.New("watch interval must be positive")
+		}
+
+		o.watchInterval = d
+		return nil
+	}
+}
+
+// Watch runs the read-only query q and sends its result set on the returned channel,
+// then runs it again after every committed read/write transaction and sends the
+// new result set if it is different from the previous one.
+// Each result set is sent as a document with one field, "result", holding the array
+// of the documents returned by the query.
+// The query runs at most once per interval, DefaultWatchInterval by default,
+// which can be changed by passing the WatchInterval option along with the arguments.
+// The channel is closed once ctx is done, or if running the query fails.
+// If the first execution of the query fails, Watch returns the error.
+func Watch(ctx context.Context, db *DB, q string, args ...interface{}) (<-chan document.Document, error) {
+	opts, _, err := splitExecOptions(args)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := db.WithContext(ctx).Prepare(q)
+	if err != nil {
+		return nil, err
+	}
+
+	if !stmt.q.IsReadOnly() {
+		return nil, errors.New("only read-only queries can be watched")
+	}
+
+	w := watcher{
+		ctx:      ctx,
+		stmt:     stmt,
+		args:     args,
+		interval: DefaultWatchInterval,
+		ch:       make(chan document.Document),
+	}
+	if opts != nil && opts.watchInterval != 0 {
+		w.interval = opts.watchInterval
+	}
+
+	// get the channel before running the query
+	// to be notified of the commits made in the meantime.
+	committed := db.DB.Committed()
+	v, err := w.result()
+	if err != nil {
+		return nil, err
+	}
+
+	go w.run(v, committed)
+
+	return w.ch, nil
+}
+
+type watcher struct {
+	ctx      context.Context
+	stmt     *Statement
+	args     []interface{}
+	interval time.Duration
+	ch       chan document.Document
+}
+
+// run sends the result set v, then waits for commits to run the query again.
+func (w *watcher) run(v document.Value, committed <-chan struct{}) {
+	defer close(w.ch)
+
+	lastRun := time.Now()
+	last := v
+	send := true
+
+	for {
+		if send {
+			select {
+			case w.ch <- document.NewFieldBuffer().Add("result", last):
+			case <-w.ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-committed:
+		case <-w.ctx.Done():
+			return
+		}
+
+		// wait until the end of the interval, to run the query
+		// only once for all the transactions committed meanwhile.
+		timer := time.NewTimer(time.Until(lastRun.Add(w.interval)))
+		select {
+		case <-timer.C:
+		case <-w.ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		committed = w.stmt.db.DB.Committed()
+		lastRun = time.Now()
+		v, err := w.result()
+		if err != nil {
+			return
+		}
+
+		same, err := v.IsEqual(last)
+		if err != nil {
+			return
+		}
+		send = !same
+		last = v
+	}
+}
+
+// result runs the query and returns its documents as an array.
+func (w *watcher) result() (document.Value, error) {
+	res, err := w.stmt.Query(w.args...)
+	if err != nil {
+		return document.Value{}, err
+	}
+	defer res.Close()
+
+	var vb document.ValueBuffer
+	err = res.Iterate(func(d document.Document) error {
+		var fb document.FieldBuffer
+		err := fb.Copy(d)
+		if err != nil {
+			return err
+		}
+
+		vb = vb.Append(document.NewDocumentValue(&fb))
+		return nil
+	})
+	if err != nil {
+		return document.Value{}, err
+	}
+
+	return document.NewArrayValue(vb), nil
+}
@@ -0,0 +1,113 @@
+package genji_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		CREATE TABLE other;
+		INSERT INTO test (a) VALUES (1);
+	`)
+	require.NoError(t, err)
+
+	const interval = 20 * time.Millisecond
+
+	next := func(t *testing.T, ch <-chan document.Document) string {
+		t.Helper()
+
+		select {
+		case d, ok := <-ch:
+			require.True(t, ok, "channel closed")
+			data, err := document.MarshalJSON(d)
+			require.NoError(t, err)
+			return string(data)
+		case <-time.After(time.Second):
+			require.FailNow(t, "no result set received")
+		}
+
+		return ""
+	}
+
+	noNext := func(t *testing.T, ch <-chan document.Document) {
+		t.Helper()
+
+		select {
+		case d := <-ch:
+			require.FailNow(t, "unexpected result set", "%v", d)
+		case <-time.After(5 * interval):
+		}
+	}
+
+	t.Run("Invalid query", func(t *testing.T) {
+		_, err := genji.Watch(context.Background(), db, "SELECT * FROM")
+		require.Error(t, err)
+
+		_, err = genji.Watch(context.Background(), db, "SELECT * FROM unknown")
+		require.Error(t, err)
+
+		_, err = genji.Watch(context.Background(), db, "DELETE FROM test")
+		require.Error(t, err)
+
+		_, err = genji.Watch(context.Background(), db, "SELECT * FROM test", genji.WatchInterval(0))
+		require.Error(t, err)
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ch, err := genji.Watch(ctx, db, "SELECT a FROM test WHERE a > ? ORDER BY a DESC LIMIT 2", 0, genji.WatchInterval(interval))
+		require.NoError(t, err)
+
+		require.JSONEq(t, `{"result": [{"a": 1}]}`, next(t, ch))
+
+		err = db.Exec("INSERT INTO test (a) VALUES (2)")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"result": [{"a": 2}, {"a": 1}]}`, next(t, ch))
+
+		// writes that don't change the result set
+		err = db.Exec("INSERT INTO other (a) VALUES (3)")
+		require.NoError(t, err)
+		err = db.Exec("INSERT INTO test (a) VALUES (0)")
+		require.NoError(t, err)
+		err = db.Exec("UPDATE test SET a = 2 WHERE a = 2")
+		require.NoError(t, err)
+		noNext(t, ch)
+
+		err = db.Update(func(tx *genji.Tx) error {
+			return tx.Exec("INSERT INTO test (a) VALUES (3), (4)")
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"result": [{"a": 4}, {"a": 3}]}`, next(t, ch))
+		noNext(t, ch)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		ch, err := genji.Watch(ctx, db, "SELECT COUNT(*) FROM test", genji.WatchInterval(interval))
+		require.NoError(t, err)
+		next(t, ch)
+
+		cancel()
+
+		select {
+		case _, ok := <-ch:
+			require.False(t, ok)
+		case <-time.After(interval):
+			require.FailNow(t, "channel not closed")
+		}
+	})
+}